@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// placeholderRegexp matches the ${NAME} placeholders operators replace in manifests before applying them.
+var placeholderRegexp = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// ManifestCheck describes manifests that must be valid.
+type ManifestCheck struct {
+	// Files are the names of the manifests, all of them must exist.
+	Files []string
+	// TemplateData, if set, renders the manifests as text/template with it before parsing. All fields the templates
+	// refer to must exist.
+	TemplateData interface{}
+	// Placeholders are sample values of the ${NAME} placeholders the operator replaces at runtime. Every placeholder of
+	// the manifests must have one.
+	Placeholders map[string]string
+	// CustomKinds are the kinds which are valid although they are not known to the scheme of resourceread, e.g. of
+	// custom resources.
+	CustomKinds []schema.GroupVersionKind
+}
+
+// ValidateManifests validates all manifests of the checks: they must exist, render, have all placeholders resolvable
+// and parse as objects of known kinds. Operators should call it on start for their embedded manifests, to fail
+// fast instead of in every sync. The error lists all invalid manifests.
+func ValidateManifests(manifests func(name string) ([]byte, error), checks ...ManifestCheck) error {
+	var errs []error
+	for _, check := range checks {
+		for _, file := range check.Files {
+			if err := check.validate(manifests, file); err != nil {
+				errs = append(errs, fmt.Errorf("%q: %v", file, err))
+			}
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// MustValidateManifests is like ValidateManifests, but panics on invalid manifests.
+func MustValidateManifests(manifests func(name string) ([]byte, error), checks ...ManifestCheck) {
+	if err := ValidateManifests(manifests, checks...); err != nil {
+		panic(fmt.Sprintf("invalid manifests: %v", err))
+	}
+}
+
+func (c ManifestCheck) validate(manifests func(name string) ([]byte, error), file string) error {
+	manifest, err := manifests(file)
+	if err != nil {
+		return fmt.Errorf("missing: %v", err)
+	}
+
+	if c.TemplateData != nil {
+		tmpl, err := template.New(file).Funcs(templateFuncs).Option("missingkey=error").Parse(string(manifest))
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, c.TemplateData); err != nil {
+			return err
+		}
+		manifest = buf.Bytes()
+	}
+
+	unresolved := sets.NewString()
+	manifest = placeholderRegexp.ReplaceAllFunc(manifest, func(placeholder []byte) []byte {
+		name := string(placeholder[2 : len(placeholder)-1])
+		value, ok := c.Placeholders[name]
+		if !ok {
+			unresolved.Insert(string(placeholder))
+		}
+		return []byte(value)
+	})
+	if unresolved.Len() > 0 {
+		return fmt.Errorf("unresolvable placeholders %s", strings.Join(unresolved.List(), ", "))
+	}
+
+	obj, err := resourceread.ReadGenericWithUnstructured(manifest)
+	if err != nil {
+		return err
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		gvk := u.GroupVersionKind()
+		if !c.isCustomKind(gvk) {
+			return fmt.Errorf("unknown kind %s", gvk)
+		}
+	}
+	return nil
+}
+
+func (c ManifestCheck) isCustomKind(gvk schema.GroupVersionKind) bool {
+	for _, kind := range c.CustomKinds {
+		if kind == gvk {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package assets
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestValidateManifests(t *testing.T) {
+	files := map[string]string{
+		"configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+  namespace: ${NAMESPACE}
+`,
+		"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+spec:
+  replicas: ${REPLICAS}
+`,
+		"servicemonitor.yaml": `apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: foo
+`,
+		"broken.yaml": "kind: [",
+	}
+	manifests := func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return []byte(data), nil
+	}
+	serviceMonitor := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+	valid := []ManifestCheck{
+		{Files: []string{"configmap.yaml"}, Placeholders: map[string]string{"NAMESPACE": "ns"}},
+		{Files: []string{"deployment.yaml"}, TemplateData: struct{ Name string }{Name: "foo"}, Placeholders: map[string]string{"REPLICAS": "3"}},
+		{Files: []string{"servicemonitor.yaml"}, CustomKinds: []schema.GroupVersionKind{serviceMonitor}},
+	}
+	if err := ValidateManifests(manifests, valid...); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := []ManifestCheck{
+		{Files: []string{"missing.yaml", "broken.yaml", "servicemonitor.yaml"}},
+		{Files: []string{"configmap.yaml"}},
+		{Files: []string{"deployment.yaml"}, TemplateData: struct{ Other string }{}, Placeholders: map[string]string{"REPLICAS": "3"}},
+	}
+	err := ValidateManifests(manifests, invalid...)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, expected := range []string{
+		`"missing.yaml": missing`,
+		`"broken.yaml"`,
+		`"servicemonitor.yaml": unknown kind monitoring.coreos.com/v1, Kind=ServiceMonitor`,
+		`"configmap.yaml": unresolvable placeholders ${NAMESPACE}`,
+		`"deployment.yaml"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in the error, got: %v", expected, err)
+		}
+	}
+}
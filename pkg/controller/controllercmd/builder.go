@@ -19,6 +19,7 @@ import (
 	"github.com/openshift/library-go/pkg/config/serving"
 	"github.com/openshift/library-go/pkg/controller/fileobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/retryafter"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -86,6 +87,10 @@ type ControllerBuilder struct {
 
 	versionInfo *version.Info
 
+	// retryAfterOptions, if set, make the clients of the controllers retry throttled requests, see
+	// WithThrottledRequestRetries.
+	retryAfterOptions *retryafter.Options
+
 	// nonZeroExitFn takes a function that exit the process with non-zero code.
 	// This stub exists for unit test where we can check if the graceful termination work properly.
 	// Default function will klog.Warning(args) and os.Exit(1).
@@ -206,6 +211,14 @@ func (b *ControllerBuilder) WithComponentOwnerReference(reference *corev1.Object
 	return b
 }
 
+// WithThrottledRequestRetries makes the clients created from ControllerContext.KubeConfig and ProtoKubeConfig retry
+// requests throttled by the kube-apiserver after the requested time, with a backoff per controller, see
+// retryafter.Wrap. Leader election keeps its own deadlines and is not retried.
+func (b *ControllerBuilder) WithThrottledRequestRetries(options retryafter.Options) *ControllerBuilder {
+	b.retryAfterOptions = &options
+	return b
+}
+
 // Run starts your controller for you.  It uses leader election if you asked, otherwise it directly calls you
 func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstructured) error {
 	clientConfig, err := b.getClientConfig()
@@ -298,10 +311,16 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 	protoConfig.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 	protoConfig.ContentType = "application/vnd.kubernetes.protobuf"
 
+	controllerKubeConfig, controllerProtoKubeConfig := clientConfig, protoConfig
+	if b.retryAfterOptions != nil {
+		controllerKubeConfig = retryafter.Wrap(clientConfig, *b.retryAfterOptions)
+		controllerProtoKubeConfig = retryafter.Wrap(protoConfig, *b.retryAfterOptions)
+	}
+
 	controllerContext := &ControllerContext{
 		ComponentConfig:   config,
-		KubeConfig:        clientConfig,
-		ProtoKubeConfig:   protoConfig,
+		KubeConfig:        controllerKubeConfig,
+		ProtoKubeConfig:   controllerProtoKubeConfig,
 		EventRecorder:     eventRecorder,
 		Server:            server,
 		OperatorNamespace: namespace,
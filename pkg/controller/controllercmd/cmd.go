@@ -26,6 +26,7 @@ import (
 	"github.com/openshift/library-go/pkg/controller/fileobserver"
 	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/retryafter"
 	"github.com/openshift/library-go/pkg/serviceability"
 
 	// load all the prometheus client-go metrics
@@ -141,6 +142,7 @@ func (c *ControllerCommandConfig) NewCommandWithContext(ctx context.Context) *co
 	}
 
 	c.basicFlags.AddFlags(cmd)
+	events.AddSamplingFlags(cmd.Flags())
 
 	return cmd
 }
@@ -285,7 +287,8 @@ func (c *ControllerCommandConfig) StartController(ctx context.Context) error {
 		WithVersion(c.version).
 		WithEventRecorderOptions(events.RecommendedClusterSingletonCorrelatorOptions()).
 		WithRestartOnChange(exitOnChangeReactorCh, startingFileContent, observedFiles...).
-		WithComponentOwnerReference(c.ComponentOwnerReference)
+		WithComponentOwnerReference(c.ComponentOwnerReference).
+		WithThrottledRequestRetries(retryafter.Options{})
 
 	if !c.DisableServing {
 		builder = builder.WithServer(config.ServingInfo, config.Authentication, config.Authorization)
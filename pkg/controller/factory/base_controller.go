@@ -17,6 +17,7 @@ import (
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/reasons"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
@@ -35,9 +36,12 @@ type baseController struct {
 	syncContext        SyncContext
 	syncDegradedClient operatorv1helpers.OperatorClient
 	resyncEvery        time.Duration
+	resyncEveryFunc    func() time.Duration
+	syncTimeout        func() time.Duration
 	resyncSchedules    []cron.Schedule
 	postStartHooks     []PostStartHook
 	cacheSyncTimeout   time.Duration
+	slowStartRamp      time.Duration
 }
 
 var _ Controller = &baseController{}
@@ -76,6 +80,7 @@ func waitForNamedCacheSync(controllerName string, stopCh <-chan struct{}, cacheS
 }
 
 func (c *baseController) Run(ctx context.Context, workers int) {
+	started := time.Now()
 	// HandleCrash recovers panics
 	defer utilruntime.HandleCrash(c.degradedPanicHandler)
 
@@ -97,6 +102,15 @@ func (c *baseController) Run(ctx context.Context, workers int) {
 		}
 	}
 
+	if delay := slowStartDelay(c.syncContext.Recorder().ComponentName(), c.name, c.slowStartRamp, started, time.Now()); delay > 0 {
+		klog.Infof("Delaying start of %s controller by %v", c.name, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
 	var workerWg sync.WaitGroup
 	defer func() {
 		defer klog.Infof("All %s workers have been terminated", c.name)
@@ -136,6 +150,13 @@ func (c *baseController) Run(ctx context.Context, workers int) {
 			c.runPeriodicalResync(ctx, c.resyncEvery)
 		}()
 	}
+	if c.resyncEveryFunc != nil {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			c.runDynamicPeriodicalResync(ctx, c.resyncEveryFunc)
+		}()
+	}
 
 	// run post-start hooks (custom triggers, etc.)
 	if len(c.postStartHooks) > 0 {
@@ -180,6 +201,25 @@ func (c *baseController) runPeriodicalResync(ctx context.Context, interval time.
 	}, interval)
 }
 
+// runDynamicPeriodicalResync queues a resync after every interval, reading the interval again after each resync.
+// A non-positive interval is checked again after a minute.
+func (c *baseController) runDynamicPeriodicalResync(ctx context.Context, interval func() time.Duration) {
+	for {
+		next, resync := interval(), true
+		if next <= 0 {
+			next, resync = time.Minute, false
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next):
+			if resync {
+				c.syncContext.Queue().Add(DefaultQueueKey)
+			}
+		}
+	}
+}
+
 // runWorker runs a single worker
 // The worker is asked to terminate when the passed context is cancelled and is given terminationGraceDuration time
 // to complete its shutdown.
@@ -202,7 +242,8 @@ func (c *baseController) runWorker(queueCtx context.Context) {
 
 // reconcile wraps the sync() call and if operator client is set, it handle the degraded condition if sync() returns an error.
 func (c *baseController) reconcile(ctx context.Context, syncCtx SyncContext) error {
-	err := c.sync(ctx, syncCtx)
+	ctx = WithControllerName(ctx, c.name)
+	err := c.syncWithTimeout(ctx, syncCtx)
 	degradedErr := c.reportDegraded(ctx, err)
 	if apierrors.IsNotFound(degradedErr) && management.IsOperatorRemovable() {
 		// The operator tolerates missing CR, therefore don't report it up.
@@ -211,6 +252,19 @@ func (c *baseController) reconcile(ctx context.Context, syncCtx SyncContext) err
 	return degradedErr
 }
 
+// syncWithTimeout calls sync() with a context cancelled after the sync timeout, if set. The degraded condition is still
+// reported with the parent context.
+func (c *baseController) syncWithTimeout(ctx context.Context, syncCtx SyncContext) error {
+	if c.syncTimeout != nil {
+		if timeout := c.syncTimeout(); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	return c.sync(ctx, syncCtx)
+}
+
 // degradedPanicHandler will go degraded on failures, then we should catch potential panics and covert them into bad status.
 func (c *baseController) degradedPanicHandler(panicVal interface{}) {
 	if c.syncDegradedClient == nil {
@@ -229,7 +283,7 @@ func (c *baseController) reportDegraded(ctx context.Context, reportedError error
 		_, _, updateErr := v1helpers.UpdateStatus(ctx, c.syncDegradedClient, v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
 			Type:    c.name + "Degraded",
 			Status:  operatorv1.ConditionTrue,
-			Reason:  "SyncError",
+			Reason:  string(reasons.SyncError),
 			Message: reportedError.Error(),
 		}))
 		if updateErr != nil {
@@ -241,7 +295,7 @@ func (c *baseController) reportDegraded(ctx context.Context, reportedError error
 		v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
 			Type:   c.name + "Degraded",
 			Status: operatorv1.ConditionFalse,
-			Reason: "AsExpected",
+			Reason: string(reasons.AsExpected),
 		}))
 	return updateErr
 }
@@ -260,8 +314,14 @@ func (c *baseController) processNextWorkItem(queueCtx context.Context) {
 		utilruntime.HandleError(fmt.Errorf("%q controller failed to process key %q (not a string)", c.name, key))
 		return
 	}
+	if syncCtx.pendingEvents != nil {
+		syncCtx.syncEvents = syncCtx.pendingEvents.pop(syncCtx.queueKey)
+	}
 
 	if err := c.reconcile(queueCtx, syncCtx); err != nil {
+		if syncCtx.pendingEvents != nil {
+			syncCtx.pendingEvents.restore(syncCtx.queueKey, syncCtx.syncEvents)
+		}
 		if err == SyntheticRequeueError {
 			// logging this helps detecting wedged controllers with missing pre-requirements
 			klog.V(5).Infof("%q controller requested synthetic requeue with key %q", c.name, key)
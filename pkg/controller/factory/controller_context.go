@@ -20,14 +20,28 @@ type syncContext struct {
 	eventRecorder events.Recorder
 	queue         workqueue.RateLimitingInterface
 	queueKey      string
+
+	// pendingEvents collects informer events until their queue key is processed, if enabled with
+	// Factory.WithSyncEvents.
+	pendingEvents *pendingSyncEvents
+	// syncEvents are the informer events that triggered the current sync.
+	syncEvents []SyncEvent
 }
 
 var _ SyncContext = syncContext{}
 
 // NewSyncContext gives new sync context.
 func NewSyncContext(name string, recorder events.Recorder) SyncContext {
+	return newSyncContext(name, recorder, nil)
+}
+
+// newSyncContext gives new sync context with a queue using the rate limiter, or the default controller rate limiter if nil.
+func newSyncContext(name string, recorder events.Recorder, rateLimiter workqueue.RateLimiter) syncContext {
+	if rateLimiter == nil {
+		rateLimiter = workqueue.DefaultControllerRateLimiter()
+	}
 	return syncContext{
-		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		queue:         workqueue.NewNamedRateLimitingQueue(rateLimiter, name),
 		eventRecorder: recorder.WithComponentSuffix(strings.ToLower(name)),
 	}
 }
@@ -44,6 +58,10 @@ func (c syncContext) Recorder() events.Recorder {
 	return c.eventRecorder
 }
 
+func (c syncContext) SyncEvents() []SyncEvent {
+	return c.syncEvents
+}
+
 // eventHandler provides default event handler that is added to an informers passed to controller factory.
 func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter EventFilterFunc) cache.ResourceEventHandler {
 	resourceEventHandler := cache.ResourceEventHandlerFuncs{
@@ -53,7 +71,7 @@ func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter Even
 				utilruntime.HandleError(fmt.Errorf("added object %+v is not runtime Object", obj))
 				return
 			}
-			c.enqueueKeys(queueKeysFunc(runtimeObj)...)
+			c.enqueueEvent(SyncEventAdd, runtimeObj, queueKeysFunc(runtimeObj)...)
 		},
 		UpdateFunc: func(old, new interface{}) {
 			runtimeObj, ok := new.(runtime.Object)
@@ -61,20 +79,21 @@ func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter Even
 				utilruntime.HandleError(fmt.Errorf("updated object %+v is not runtime Object", runtimeObj))
 				return
 			}
-			c.enqueueKeys(queueKeysFunc(runtimeObj)...)
+			c.enqueueEvent(SyncEventUpdate, runtimeObj, queueKeysFunc(runtimeObj)...)
 		},
 		DeleteFunc: func(obj interface{}) {
 			runtimeObj, ok := obj.(runtime.Object)
 			if !ok {
 				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
-					c.enqueueKeys(queueKeysFunc(tombstone.Obj.(runtime.Object))...)
+					tombstoneObj := tombstone.Obj.(runtime.Object)
+					c.enqueueEvent(SyncEventDelete, tombstoneObj, queueKeysFunc(tombstoneObj)...)
 
 					return
 				}
 				utilruntime.HandleError(fmt.Errorf("updated object %+v is not runtime Object", runtimeObj))
 				return
 			}
-			c.enqueueKeys(queueKeysFunc(runtimeObj)...)
+			c.enqueueEvent(SyncEventDelete, runtimeObj, queueKeysFunc(runtimeObj)...)
 		},
 	}
 	if filter == nil {
@@ -86,8 +105,16 @@ func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter Even
 	}
 }
 
-func (c syncContext) enqueueKeys(keys ...string) {
+// enqueueEvent records the event for the keys before adding them to the queue, so it is visible to the sync.
+func (c syncContext) enqueueEvent(eventType SyncEventType, obj runtime.Object, keys ...string) {
+	var event SyncEvent
+	if c.pendingEvents != nil && len(keys) > 0 {
+		event = newSyncEvent(eventType, obj)
+	}
 	for _, qKey := range keys {
+		if c.pendingEvents != nil {
+			c.pendingEvents.add(qKey, event)
+		}
 		c.queue.Add(qKey)
 	}
 }
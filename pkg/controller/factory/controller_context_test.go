@@ -265,3 +265,58 @@ func TestSyncContext_isInterestingNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncContext_syncEvents(t *testing.T) {
+	ctx := NewSyncContext("test", eventstesting.NewTestingEventRecorder(t)).(syncContext)
+	if ctx.pendingEvents != nil {
+		t.Fatalf("expected sync events to be disabled by default")
+	}
+	ctx.pendingEvents = newPendingSyncEvents()
+	var syncCtx SyncContext = ctx
+	handler := syncCtx.(syncContext).eventHandler(DefaultQueueKeysFunc, nil)
+
+	var received [][]SyncEvent
+	failures := 1
+	c := &baseController{
+		name:        "test",
+		syncContext: syncCtx,
+		sync: func(ctx context.Context, controllerContext SyncContext) error {
+			received = append(received, TriggeringEvents(controllerContext))
+			if failures > 0 {
+				failures--
+				return fmt.Errorf("failure")
+			}
+			return nil
+		},
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar", UID: "uid", ResourceVersion: "1"}}
+	handler.OnAdd(secret)
+	handler.OnUpdate(secret, secret)
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "foo/bar", Obj: secret})
+
+	// the failed sync and its retry see all events that were coalesced into the single key
+	c.processNextWorkItem(context.TODO())
+	c.processNextWorkItem(context.TODO())
+	if len(received) != 2 {
+		t.Fatalf("expected 2 syncs, got %d", len(received))
+	}
+	for _, events := range received {
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %#v", events)
+		}
+		for i, expected := range []SyncEventType{SyncEventAdd, SyncEventUpdate, SyncEventDelete} {
+			want := SyncEvent{Type: expected, Kind: "Secret", Namespace: "foo", Name: "bar", UID: "uid", ResourceVersion: "1"}
+			if events[i] != want {
+				t.Errorf("expected event %d to be %s of the secret, got %#v", i, expected, events[i])
+			}
+		}
+	}
+
+	// a manual requeue carries no events
+	syncCtx.Queue().Add(DefaultQueueKey)
+	c.processNextWorkItem(context.TODO())
+	if len(received) != 3 || received[2] != nil {
+		t.Errorf("expected sync without events, got %#v", received)
+	}
+}
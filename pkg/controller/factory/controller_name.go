@@ -0,0 +1,17 @@
+package factory
+
+import "context"
+
+type controllerNameKey struct{}
+
+// ControllerNameFrom returns the name of the controller whose sync the context belongs to, or an empty string outside
+// of controller syncs. Clients can use it to attribute API requests to controllers.
+func ControllerNameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(controllerNameKey{}).(string)
+	return name
+}
+
+// WithControllerName returns a context attributed to the named controller, as passed to the sync of the controller.
+func WithControllerName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, controllerNameKey{}, name)
+}
@@ -10,6 +10,7 @@ import (
 	errorutil "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -29,7 +30,12 @@ type Factory struct {
 	sync                  SyncFunc
 	syncContext           SyncContext
 	syncDegradedClient    operatorv1helpers.OperatorClient
+	readOnlyClient        operatorv1helpers.OperatorClient
+	readOnlySync          SyncFunc
 	resyncInterval        time.Duration
+	resyncIntervalFunc    func() time.Duration
+	syncTimeout           func() time.Duration
+	rateLimiter           workqueue.RateLimiter
 	resyncSchedules       []string
 	informers             []filteredInformers
 	informerQueueKeys     []informersWithQueueKey
@@ -38,6 +44,10 @@ type Factory struct {
 	namespaceInformers    []*namespaceInformer
 	cachesToSync          []cache.InformerSynced
 	interestingNamespaces sets.String
+	resyncStormWindow     time.Duration
+	maxResyncsPerKey      int
+	slowStartRamp         time.Duration
+	syncEvents            bool
 }
 
 // Informer represents any structure that allow to register event handlers and informs if caches are synced.
@@ -207,6 +217,13 @@ func (f *Factory) ResyncEvery(interval time.Duration) *Factory {
 	return f
 }
 
+// ResyncEveryFunc is like ResyncEvery, but reads the interval before every periodical resync, so it can change while
+// the controller runs, e.g. through tuningconfig knobs. A non-positive interval pauses the periodical resync.
+func (f *Factory) ResyncEveryFunc(interval func() time.Duration) *Factory {
+	f.resyncIntervalFunc = interval
+	return f
+}
+
 // ResyncSchedule allows to supply a Cron syntax schedule that will be used to schedule the sync() call runs.
 // This allows more fine-tuned controller scheduling than ResyncEvery.
 // Examples:
@@ -230,6 +247,20 @@ func (f *Factory) WithSyncContext(ctx SyncContext) *Factory {
 	return f
 }
 
+// WithRateLimiter sets the rate limiter of the controller queue, which delays the retries of failed syncs. By default,
+// workqueue.DefaultControllerRateLimiter is used. It is ignored when the sync context is set with WithSyncContext.
+func (f *Factory) WithRateLimiter(rateLimiter workqueue.RateLimiter) *Factory {
+	f.rateLimiter = rateLimiter
+	return f
+}
+
+// WithSyncTimeout cancels the context passed to the sync function when the sync takes longer than the timeout. The
+// timeout is read before every sync, a non-positive timeout disables it.
+func (f *Factory) WithSyncTimeout(timeout func() time.Duration) *Factory {
+	f.syncTimeout = timeout
+	return f
+}
+
 // WithSyncDegradedOnError encapsulate the controller sync() function, so when this function return an error, the operator client
 // is used to set the degraded condition to (eg. "ControllerFooDegraded"). The degraded condition name is set based on the controller name.
 func (f *Factory) WithSyncDegradedOnError(operatorClient operatorv1helpers.OperatorClient) *Factory {
@@ -237,6 +268,49 @@ func (f *Factory) WithSyncDegradedOnError(operatorClient operatorv1helpers.Opera
 	return f
 }
 
+// WithReadOnlySyncWhenUnmanaged runs the readOnlySync function instead of the sync function when the management state of
+// the operator is Unmanaged or Removed (see management.IsOperatorReadOnly). The readOnlySync function must not change the
+// operand, but can keep reporting its observed state. It can be nil to skip the sync. The operator client is used to set
+// the read-only condition (eg. "ControllerFooReadOnly") named after the controller.
+func (f *Factory) WithReadOnlySyncWhenUnmanaged(operatorClient operatorv1helpers.OperatorClient, readOnlySync SyncFunc) *Factory {
+	f.readOnlyClient = operatorClient
+	f.readOnlySync = readOnlySync
+	return f
+}
+
+// WithResyncStormProtection enables the protection against informers resyncing all their objects over and over, e.g.
+// when they flap. When the objects of an informer are resynced more than maxResyncsPerKey times within the window, the
+// resyncs of unchanged objects are dropped until the window ends and a ControllerResyncStorm warning event names the
+// informer. Changes of objects always trigger a sync. A window or maxResyncsPerKey of 0 uses the defaults of one
+// minute and 5 resyncs. Without it, all resyncs trigger a sync.
+func (f *Factory) WithResyncStormProtection(window time.Duration, maxResyncsPerKey int) *Factory {
+	if window <= 0 {
+		window = defaultResyncStormWindow
+	}
+	if maxResyncsPerKey <= 0 {
+		maxResyncsPerKey = defaultMaxResyncsPerKey
+	}
+	f.resyncStormWindow = window
+	f.maxResyncsPerKey = maxResyncsPerKey
+	return f
+}
+
+// WithSlowStart staggers the first sync of the controller after it starts to avoid a burst of writes when all
+// controllers of a restarted operator start at once. Every controller waits a fixed offset within the ramp derived from
+// its name and the component of its event recorder, measured from the call of Run, before its workers start, so the
+// controllers started together spread evenly over the ramp. The time spent waiting for the informer caches counts towards the offset.
+func (f *Factory) WithSlowStart(ramp time.Duration) *Factory {
+	f.slowStartRamp = ramp
+	return f
+}
+
+// WithSyncEvents records the informer events that enqueued a key, so the sync can read them with
+// TriggeringEvents(syncCtx). Only the kind, namespace, name, UID and resource version of the objects are kept.
+func (f *Factory) WithSyncEvents() *Factory {
+	f.syncEvents = true
+	return f
+}
+
 // Controller produce a runnable controller.
 func (f *Factory) ToController(name string, eventRecorder events.Recorder) Controller {
 	if f.sync == nil {
@@ -247,7 +321,11 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 	if f.syncContext != nil {
 		ctx = f.syncContext
 	} else {
-		ctx = NewSyncContext(name, eventRecorder)
+		ctx = newSyncContext(name, eventRecorder, f.rateLimiter)
+	}
+	if sc, ok := ctx.(syncContext); ok && f.syncEvents {
+		sc.pendingEvents = newPendingSyncEvents()
+		ctx = sc
 	}
 
 	var cronSchedules []cron.Schedule
@@ -265,16 +343,24 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 		}
 	}
 
+	sync := f.sync
+	if f.readOnlyClient != nil {
+		sync = readOnlyAwareSync(name, f.readOnlyClient, f.sync, f.readOnlySync)
+	}
+
 	c := &baseController{
 		name:               name,
 		syncDegradedClient: f.syncDegradedClient,
-		sync:               f.sync,
+		sync:               sync,
 		resyncEvery:        f.resyncInterval,
+		resyncEveryFunc:    f.resyncIntervalFunc,
+		syncTimeout:        f.syncTimeout,
 		resyncSchedules:    cronSchedules,
 		cachesToSync:       append([]cache.InformerSynced{}, f.cachesToSync...),
 		syncContext:        ctx,
 		postStartHooks:     f.postStartHooks,
 		cacheSyncTimeout:   defaultCacheSyncTimeout,
+		slowStartRamp:      f.slowStartRamp,
 	}
 
 	// Warn about too fast resyncs as they might drain the operators QPS.
@@ -283,11 +369,14 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 		ctx.Recorder().Warningf("FastControllerResync", "Controller %q resync interval is set to %s which might lead to client request throttling", name, c.resyncEvery)
 	}
 
+	// informers are numbered to identify them in resync storm warnings, together with their object type
+	informerIndex := 0
 	for i := range f.informerQueueKeys {
 		for d := range f.informerQueueKeys[i].informers {
 			informer := f.informerQueueKeys[i].informers[d]
 			queueKeyFn := f.informerQueueKeys[i].queueKeyFn
-			informer.AddEventHandler(c.syncContext.(syncContext).eventHandler(queueKeyFn, f.informerQueueKeys[i].filter))
+			informer.AddEventHandler(f.withResyncStormProtection(name, informerIndex, ctx.Recorder(), c.syncContext.(syncContext).eventHandler(queueKeyFn, f.informerQueueKeys[i].filter)))
+			informerIndex++
 			c.cachesToSync = append(c.cachesToSync, informer.HasSynced)
 		}
 	}
@@ -295,7 +384,8 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 	for i := range f.informers {
 		for d := range f.informers[i].informers {
 			informer := f.informers[i].informers[d]
-			informer.AddEventHandler(c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.informers[i].filter))
+			informer.AddEventHandler(f.withResyncStormProtection(name, informerIndex, ctx.Recorder(), c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.informers[i].filter)))
+			informerIndex++
 			c.cachesToSync = append(c.cachesToSync, informer.HasSynced)
 		}
 	}
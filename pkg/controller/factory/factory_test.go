@@ -121,6 +121,51 @@ func TestResyncController(t *testing.T) {
 	}
 }
 
+func TestResyncControllerIntervalFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	intervalCalls := make(chan struct{}, 100)
+	factory := New().ResyncEveryFunc(func() time.Duration {
+		intervalCalls <- struct{}{}
+		return 100 * time.Millisecond
+	})
+
+	controllerSynced := make(chan struct{})
+	syncCallCount := 0
+	controller := factory.WithSync(func(ctx context.Context, controllerContext SyncContext) error {
+		syncCallCount++
+		if syncCallCount == 3 {
+			defer close(controllerSynced)
+		}
+		return nil
+	}).ToController("PeriodicController", events.NewInMemoryRecorder("periodic-controller"))
+
+	go controller.Run(ctx, 1)
+
+	select {
+	case <-controllerSynced:
+	case <-time.After(10 * time.Second):
+		t.Fatal("failed to resync at least three times")
+	}
+	if len(intervalCalls) < 3 {
+		t.Errorf("expected the interval to be read before every resync, got %d reads", len(intervalCalls))
+	}
+}
+
+func TestSyncTimeout(t *testing.T) {
+	c := &baseController{
+		name:        "TestController",
+		syncTimeout: func() time.Duration { return 10 * time.Millisecond },
+		sync: func(ctx context.Context, controllerContext SyncContext) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	if err := c.reconcile(context.TODO(), NewSyncContext("TestController", events.NewInMemoryRecorder("test"))); err != context.DeadlineExceeded {
+		t.Errorf("expected the sync to time out, got %v", err)
+	}
+}
+
 func TestMultiWorkerControllerShutdown(t *testing.T) {
 	controllerCtx, shutdown := context.WithCancel(context.TODO())
 	factory := New().ResyncEvery(10 * time.Minute) // make sure we only call 1 sync manually
@@ -0,0 +1,47 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// readOnlyAwareSync returns a sync function calling sync when the operator is managed and readOnlySync otherwise,
+// reporting which of them runs in the <name>ReadOnly condition.
+func readOnlyAwareSync(name string, operatorClient v1helpers.OperatorClient, sync, readOnlySync SyncFunc) SyncFunc {
+	return func(ctx context.Context, syncCtx SyncContext) error {
+		spec, _, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			return err
+		}
+
+		condition := operatorv1.OperatorCondition{
+			Type:   name + "ReadOnly",
+			Status: operatorv1.ConditionFalse,
+			Reason: "Managed",
+		}
+		var syncErr error
+		if management.IsOperatorReadOnly(spec.ManagementState) {
+			condition.Status = operatorv1.ConditionTrue
+			condition.Reason = string(spec.ManagementState)
+			condition.Message = fmt.Sprintf("The management state is %s, the operand is observed but not changed", spec.ManagementState)
+			if readOnlySync != nil {
+				syncErr = readOnlySync(ctx, syncCtx)
+			}
+		} else {
+			syncErr = sync(ctx, syncCtx)
+		}
+
+		if _, _, err := v1helpers.UpdateStatus(ctx, operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+			if syncErr != nil {
+				return syncErr
+			}
+			return err
+		}
+		return syncErr
+	}
+}
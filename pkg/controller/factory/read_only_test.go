@@ -0,0 +1,60 @@
+package factory
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestReadOnlyAwareSync(t *testing.T) {
+	tests := []struct {
+		state             operatorv1.ManagementState
+		expectedSync      string
+		expectedCondition operatorv1.ConditionStatus
+	}{
+		{state: operatorv1.Managed, expectedSync: "sync", expectedCondition: operatorv1.ConditionFalse},
+		{state: operatorv1.Unmanaged, expectedSync: "readOnlySync", expectedCondition: operatorv1.ConditionTrue},
+		{state: operatorv1.Removed, expectedSync: "readOnlySync", expectedCondition: operatorv1.ConditionTrue},
+	}
+	for _, test := range tests {
+		t.Run(string(test.state), func(t *testing.T) {
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: test.state}, &operatorv1.OperatorStatus{}, nil)
+			var called string
+			sync := readOnlyAwareSync("Test", operatorClient,
+				func(ctx context.Context, syncCtx SyncContext) error {
+					called = "sync"
+					return nil
+				},
+				func(ctx context.Context, syncCtx SyncContext) error {
+					called = "readOnlySync"
+					return nil
+				},
+			)
+			if err := sync(context.TODO(), NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+				t.Fatal(err)
+			}
+			if called != test.expectedSync {
+				t.Errorf("expected %s to be called, got %q", test.expectedSync, called)
+			}
+			_, status, _, _ := operatorClient.GetOperatorState()
+			condition := v1helpers.FindOperatorCondition(status.Conditions, "TestReadOnly")
+			if condition == nil || condition.Status != test.expectedCondition {
+				t.Errorf("expected TestReadOnly=%s, got %v", test.expectedCondition, condition)
+			}
+		})
+	}
+
+	// a nil readOnlySync skips the sync
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Unmanaged}, &operatorv1.OperatorStatus{}, nil)
+	sync := readOnlyAwareSync("Test", operatorClient, func(ctx context.Context, syncCtx SyncContext) error {
+		t.Errorf("unexpected sync")
+		return nil
+	}, nil)
+	if err := sync(context.TODO(), NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,132 @@
+package factory
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// defaultResyncStormWindow is the window in which resyncs of an informer are counted.
+	defaultResyncStormWindow = time.Minute
+	// defaultMaxResyncsPerKey is how often every object of an informer may be resynced within the window. Informers
+	// resync every couple of minutes, so anything above is most likely a flapping informer relisting over and over.
+	defaultMaxResyncsPerKey = 5
+)
+
+// resyncStormDetector counts the resyncs of a single informer, i.e. update events of unchanged objects. Once the
+// objects are resynced more than maxResyncsPerKey times on average within the window, further resyncs are throttled
+// until the window ends.
+type resyncStormDetector struct {
+	window           time.Duration
+	maxResyncsPerKey int
+	now              func() time.Time
+
+	lock        sync.Mutex
+	windowStart time.Time
+	resyncs     int
+	keys        sets.String
+	throttled   bool
+}
+
+func newResyncStormDetector(window time.Duration, maxResyncsPerKey int) *resyncStormDetector {
+	return &resyncStormDetector{
+		window:           window,
+		maxResyncsPerKey: maxResyncsPerKey,
+		now:              time.Now,
+		keys:             sets.NewString(),
+	}
+}
+
+// observe records a resync of the key. It returns whether the resync is throttled, and whether the storm was just
+// detected, which happens at most once per window.
+func (d *resyncStormDetector) observe(key string) (throttled bool, detected bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	if now.Sub(d.windowStart) > d.window {
+		d.windowStart = now
+		d.resyncs = 0
+		d.keys = sets.NewString()
+		d.throttled = false
+	}
+	d.resyncs++
+	d.keys.Insert(key)
+	if d.throttled {
+		return true, false
+	}
+	if d.resyncs > d.maxResyncsPerKey*d.keys.Len() {
+		d.throttled = true
+		return true, true
+	}
+	return false, false
+}
+
+// resyncStormProtectingHandler drops the resyncs of a storming informer. Updates of changed objects, adds and deletes
+// always pass, so the controller does not miss any change.
+type resyncStormProtectingHandler struct {
+	controllerName string
+	informerIndex  int
+	handler        cache.ResourceEventHandler
+	detector       *resyncStormDetector
+	recorder       events.Recorder
+}
+
+func (h *resyncStormProtectingHandler) OnAdd(obj interface{}) {
+	h.handler.OnAdd(obj)
+}
+
+func (h *resyncStormProtectingHandler) OnUpdate(oldObj, newObj interface{}) {
+	if isResync(oldObj, newObj) {
+		key, err := cache.MetaNamespaceKeyFunc(newObj)
+		if err == nil {
+			throttled, detected := h.detector.observe(key)
+			if detected {
+				klog.Warningf("Controller %q is throttling resyncs of informer #%d of %T objects, they were resynced more than %d times within %s", h.controllerName, h.informerIndex, newObj, h.detector.maxResyncsPerKey, h.detector.window)
+				h.recorder.Warningf("ControllerResyncStorm", "Controller %q is throttling resyncs of informer #%d of %T objects, they were resynced more than %d times within %s", h.controllerName, h.informerIndex, newObj, h.detector.maxResyncsPerKey, h.detector.window)
+			}
+			if throttled {
+				return
+			}
+		}
+	}
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h *resyncStormProtectingHandler) OnDelete(obj interface{}) {
+	h.handler.OnDelete(obj)
+}
+
+// isResync returns true if the update event is caused by a resync or relist of the informer, i.e. the object did not change.
+func isResync(oldObj, newObj interface{}) bool {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return len(newMeta.GetResourceVersion()) > 0 && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
+
+// withResyncStormProtection wraps the handler of an informer, unless the protection is disabled.
+func (f *Factory) withResyncStormProtection(name string, informerIndex int, recorder events.Recorder, handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	if f.maxResyncsPerKey <= 0 {
+		return handler
+	}
+	return &resyncStormProtectingHandler{
+		controllerName: name,
+		informerIndex:  informerIndex,
+		handler:        handler,
+		detector:       newResyncStormDetector(f.resyncStormWindow, f.maxResyncsPerKey),
+		recorder:       recorder,
+	}
+}
@@ -0,0 +1,64 @@
+package factory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestResyncStormProtection(t *testing.T) {
+	secret := func(name, resourceVersion string) *v1.Secret {
+		return &v1.Secret{ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: name, ResourceVersion: resourceVersion}}
+	}
+
+	noopSync := func(context.Context, SyncContext) error { return nil }
+	updates := 0
+	recorder := events.NewInMemoryRecorder("test")
+	informer := &fakeInformer{}
+	New().WithSync(noopSync).WithInformers(informer).WithResyncStormProtection(0, 0).ToController("test", events.NewInMemoryRecorder("test"))
+	handler := informer.eventHandler.(*resyncStormProtectingHandler)
+	handler.recorder = recorder
+	handler.handler = cache.ResourceEventHandlerFuncs{UpdateFunc: func(_, _ interface{}) { updates++ }}
+	now := time.Now()
+	handler.detector.now = func() time.Time { return now }
+
+	// two objects may be resynced 10 times in total
+	for i := 0; i < 5; i++ {
+		handler.OnUpdate(secret("a", "1"), secret("a", "1"))
+		handler.OnUpdate(secret("b", "1"), secret("b", "1"))
+	}
+	if updates != 10 || len(recorder.Events()) != 0 {
+		t.Fatalf("expected 10 updates and no events, got %d updates and %d events", updates, len(recorder.Events()))
+	}
+
+	// the storm is detected and resyncs are dropped, but changes still pass
+	handler.OnUpdate(secret("a", "1"), secret("a", "1"))
+	handler.OnUpdate(secret("b", "1"), secret("b", "1"))
+	handler.OnUpdate(secret("a", "1"), secret("a", "2"))
+	if updates != 11 {
+		t.Errorf("expected resyncs to be throttled, got %d updates", updates)
+	}
+	if events := recorder.Events(); len(events) != 1 || events[0].Reason != "ControllerResyncStorm" || events[0].Type != "Warning" {
+		t.Errorf("expected a single ControllerResyncStorm warning, got %#v", events)
+	}
+
+	// resyncs pass again in the next window
+	now = now.Add(2 * time.Minute)
+	handler.OnUpdate(secret("a", "2"), secret("a", "2"))
+	if updates != 12 {
+		t.Errorf("expected resyncs to pass in the next window, got %d updates", updates)
+	}
+
+	// the protection is disabled by default
+	informer = &fakeInformer{}
+	New().WithSync(noopSync).WithInformers(informer).ToController("test", recorder)
+	if _, ok := informer.eventHandler.(*resyncStormProtectingHandler); ok {
+		t.Errorf("expected the resync storm protection to be disabled")
+	}
+}
@@ -0,0 +1,20 @@
+package factory
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// slowStartDelay returns how long the named controller has to wait until its offset within the ramp after the controller
+// started is reached. The offset is derived from the operator identity, e.g. the component of its event recorder, and
+// the controller name, so it stays the same across restarts while the same controllers of different operators, which
+// write to the same API server, spread over the ramp too.
+func slowStartDelay(identity, name string, ramp time.Duration, started, now time.Time) time.Duration {
+	if ramp <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(identity + "/" + name))
+	offset := time.Duration(hash.Sum64() % uint64(ramp))
+	return started.Add(offset).Sub(now)
+}
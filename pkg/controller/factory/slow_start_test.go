@@ -0,0 +1,47 @@
+package factory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSlowStartDelay(t *testing.T) {
+	ramp := 10 * time.Second
+	started := time.Now()
+	if delay := slowStartDelay("operator", "foo", 0, started, started); delay != 0 {
+		t.Errorf("expected no delay without ramp, got %v", delay)
+	}
+	if delay := slowStartDelay("operator", "foo", ramp, started, started.Add(ramp)); delay > 0 {
+		t.Errorf("expected no delay after the ramp, got %v", delay)
+	}
+
+	// the controllers spread over the ramp
+	offsets := map[time.Duration]bool{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("Controller%d", i)
+		delay := slowStartDelay("operator", name, ramp, started, started)
+		if delay < 0 || delay >= ramp {
+			t.Errorf("expected %s delay within the ramp, got %v", name, delay)
+		}
+		if again := slowStartDelay("operator", name, ramp, started, started); again != delay {
+			t.Errorf("expected %s delay to be stable, got %v and %v", name, delay, again)
+		}
+		if later := slowStartDelay("operator", name, ramp, started, started.Add(time.Second)); later != delay-time.Second {
+			t.Errorf("expected %s delay to account for the time since the start, got %v and %v", name, delay, later)
+		}
+		offsets[delay.Truncate(time.Second)] = true
+	}
+	if len(offsets) < 5 {
+		t.Errorf("expected the controllers to spread over the ramp, got offsets %v", offsets)
+	}
+
+	// the same controller of different operators spreads over the ramp too
+	offsets = map[time.Duration]bool{}
+	for i := 0; i < 20; i++ {
+		offsets[slowStartDelay(fmt.Sprintf("operator%d", i), "Controller", ramp, started, started).Truncate(time.Second)] = true
+	}
+	if len(offsets) < 5 {
+		t.Errorf("expected the operators to spread over the ramp, got offsets %v", offsets)
+	}
+}
@@ -0,0 +1,113 @@
+package factory
+
+import (
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SyncEventType is the type of the informer event that triggered a sync.
+type SyncEventType string
+
+const (
+	SyncEventAdd    SyncEventType = "Add"
+	SyncEventUpdate SyncEventType = "Update"
+	SyncEventDelete SyncEventType = "Delete"
+)
+
+// SyncEvent describes an informer event that caused the queue key to be enqueued. Only the identity of the object is
+// kept, the sync reads the current state from the listers.
+type SyncEvent struct {
+	Type SyncEventType
+	// Kind is the kind of the object, or its Go type name if the informer does not set the kind, e.g. "Secret".
+	Kind            string
+	Namespace       string
+	Name            string
+	UID             types.UID
+	ResourceVersion string
+}
+
+// newSyncEvent returns the event of the given type for the object as observed by the informer.
+func newSyncEvent(eventType SyncEventType, obj runtime.Object) SyncEvent {
+	event := SyncEvent{Type: eventType, Kind: obj.GetObjectKind().GroupVersionKind().Kind}
+	if len(event.Kind) == 0 {
+		t := reflect.TypeOf(obj)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		event.Kind = t.Name()
+	}
+	if metaObj, err := meta.Accessor(obj); err == nil {
+		event.Namespace = metaObj.GetNamespace()
+		event.Name = metaObj.GetName()
+		event.UID = metaObj.GetUID()
+		event.ResourceVersion = metaObj.GetResourceVersion()
+	}
+	return event
+}
+
+// maxSyncEventsPerKey bounds the number of events kept for a single queue key, older events are dropped first.
+const maxSyncEventsPerKey = 32
+
+// SyncEventsProvider is implemented by sync contexts that know which informer events triggered the sync.
+type SyncEventsProvider interface {
+	// SyncEvents returns the informer events observed for the queue key since the last successful sync, oldest first.
+	SyncEvents() []SyncEvent
+}
+
+// TriggeringEvents returns the informer events that caused the current sync, oldest first. The events are only
+// collected for controllers built with Factory.WithSyncEvents.
+// It returns nil when the sync was not triggered by informers (e.g. periodic resync or a manual requeue),
+// or the events are not known. Controllers must still be able to do a full re-evaluation in that case.
+func TriggeringEvents(syncCtx SyncContext) []SyncEvent {
+	provider, ok := syncCtx.(SyncEventsProvider)
+	if !ok {
+		return nil
+	}
+	return provider.SyncEvents()
+}
+
+// pendingSyncEvents collects informer events per queue key until the key is processed.
+type pendingSyncEvents struct {
+	lock   sync.Mutex
+	events map[string][]SyncEvent
+}
+
+func newPendingSyncEvents() *pendingSyncEvents {
+	return &pendingSyncEvents{events: map[string][]SyncEvent{}}
+}
+
+func (p *pendingSyncEvents) add(key string, event SyncEvent) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.events[key] = truncateSyncEvents(append(p.events[key], event))
+}
+
+// pop returns and forgets the events collected for the key.
+func (p *pendingSyncEvents) pop(key string) []SyncEvent {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	events := p.events[key]
+	delete(p.events, key)
+	return events
+}
+
+// restore puts back events of a failed sync in front of the events collected since, so the retry sees them all.
+func (p *pendingSyncEvents) restore(key string, events []SyncEvent) {
+	if len(events) == 0 {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.events[key] = truncateSyncEvents(append(append([]SyncEvent{}, events...), p.events[key]...))
+}
+
+func truncateSyncEvents(events []SyncEvent) []SyncEvent {
+	if len(events) <= maxSyncEventsPerKey {
+		return events
+	}
+	return events[len(events)-maxSyncEventsPerKey:]
+}
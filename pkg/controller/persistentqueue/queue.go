@@ -0,0 +1,200 @@
+// Package persistentqueue provides a workqueue for long-running migrations which remembers the processed keys in a
+// config map, so that a migration interrupted by a restart of the operator resumes instead of starting over.
+package persistentqueue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ProcessedKeysKey is the key of the config map listing the processed keys, one per line.
+	ProcessedKeysKey = "processed"
+
+	// maxCheckpointBytes is the size the processed keys may take in the config map. Objects are limited to 1MiB,
+	// some room is left for the metadata.
+	maxCheckpointBytes = 1024*1024 - 16*1024
+)
+
+// Queue is a rate limiting workqueue skipping the keys which were completed before, by this process or an earlier one.
+// Keys are completed with Complete. The completed keys are checkpointed to the config map by Checkpoint, which is
+// called periodically by Run. A key completed after the last checkpoint is processed again after a restart, so the
+// processing must be idempotent.
+//
+// The config map is limited to 1MiB, so the keys should be short and their number in the order of tens of thousands
+// at most, e.g. resources and namespaces rather than single objects. Checkpoint fails when the keys do not fit.
+type Queue struct {
+	workqueue.RateLimitingInterface
+
+	namespace string
+	name      string
+	client    corev1client.ConfigMapsGetter
+
+	lock      sync.Mutex
+	completed sets.String
+	// dirty is true if keys were completed or the progress was reset since the last checkpoint.
+	dirty bool
+}
+
+// New returns a queue resuming from the keys checkpointed in the config map namespace/name, if it exists.
+func New(ctx context.Context, queueName string, rateLimiter workqueue.RateLimiter, client corev1client.ConfigMapsGetter, namespace, name string) (*Queue, error) {
+	q := &Queue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(rateLimiter, queueName),
+		namespace:             namespace,
+		name:                  name,
+		client:                client,
+		completed:             sets.NewString(),
+	}
+	configMap, err := client.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range strings.Split(configMap.Data[ProcessedKeysKey], "\n") {
+		if len(key) > 0 {
+			q.completed.Insert(key)
+		}
+	}
+	klog.V(2).Infof("Resuming queue %s with %d processed keys from configmap %s/%s", queueName, q.completed.Len(), namespace, name)
+	return q, nil
+}
+
+// Add adds the key unless it was completed.
+func (q *Queue) Add(item interface{}) {
+	if q.IsCompleted(item) {
+		return
+	}
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter adds the key after the duration unless it was completed.
+func (q *Queue) AddAfter(item interface{}, duration time.Duration) {
+	if q.IsCompleted(item) {
+		return
+	}
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited adds the key after the rate limiter says it is ok unless it was completed.
+func (q *Queue) AddRateLimited(item interface{}) {
+	if q.IsCompleted(item) {
+		return
+	}
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+// Complete marks the key as processed, it is not queued again. It has to be called before Done.
+func (q *Queue) Complete(item interface{}) {
+	key, ok := item.(string)
+	if !ok {
+		return
+	}
+	q.RateLimitingInterface.Forget(item)
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if !q.completed.Has(key) {
+		q.completed.Insert(key)
+		q.dirty = true
+	}
+}
+
+// IsCompleted returns true if the key was completed.
+func (q *Queue) IsCompleted(item interface{}) bool {
+	key, ok := item.(string)
+	if !ok {
+		return false
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.completed.Has(key)
+}
+
+// Completed returns the sorted completed keys.
+func (q *Queue) Completed() []string {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.completed.List()
+}
+
+// Reset forgets all completed keys, e.g. to start a new migration. The progress is cleared with the next checkpoint.
+func (q *Queue) Reset() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.completed = sets.NewString()
+	q.dirty = true
+}
+
+// Checkpoint writes the completed keys to the config map if they changed since the last checkpoint. It fails without
+// writing if the keys do not fit into the config map.
+func (q *Queue) Checkpoint(ctx context.Context) error {
+	q.lock.Lock()
+	if !q.dirty {
+		q.lock.Unlock()
+		return nil
+	}
+	processed := strings.Join(q.completed.List(), "\n")
+	if len(processed) > maxCheckpointBytes {
+		count := q.completed.Len()
+		q.lock.Unlock()
+		return fmt.Errorf("%d processed keys take %d bytes, more than the %d bytes that fit into configmap %s/%s, use fewer or shorter keys", count, len(processed), maxCheckpointBytes, q.namespace, q.name)
+	}
+	data := map[string]string{ProcessedKeysKey: processed}
+	q.dirty = false
+	q.lock.Unlock()
+
+	if err := q.write(ctx, data); err != nil {
+		q.lock.Lock()
+		q.dirty = true
+		q.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (q *Queue) write(ctx context.Context, data map[string]string) error {
+	configMap, err := q.client.ConfigMaps(q.namespace).Get(ctx, q.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = q.client.ConfigMaps(q.namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: q.namespace, Name: q.name},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	configMap = configMap.DeepCopy()
+	configMap.Data = data
+	_, err = q.client.ConfigMaps(q.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// Run checkpoints the completed keys every interval until the context is done, and a last time before it returns.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := q.Checkpoint(ctx); err != nil {
+			klog.Warningf("Failed to checkpoint processed keys to configmap %s/%s: %v", q.namespace, q.name, err)
+		}
+	}, interval)
+
+	// the context is done, use a fresh one for the final checkpoint
+	finalCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := q.Checkpoint(finalCtx); err != nil {
+		klog.Warningf("Failed to checkpoint processed keys to configmap %s/%s: %v", q.namespace, q.name, err)
+	}
+}
@@ -0,0 +1,90 @@
+package persistentqueue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestQueueResumes(t *testing.T) {
+	ctx := context.TODO()
+	client := fake.NewSimpleClientset()
+
+	q, err := New(ctx, "migration", workqueue.DefaultControllerRateLimiter(), client.CoreV1(), "ns", "migration-progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		q.Add(key)
+	}
+	for _, expected := range []string{"a", "b"} {
+		key, _ := q.Get()
+		if key != expected {
+			t.Fatalf("expected %q, got %q", expected, key)
+		}
+		q.Complete(key)
+		q.Done(key)
+	}
+	q.Add("a")
+	if q.Len() != 1 {
+		t.Errorf("expected completed keys not to be queued again, got %d queued keys", q.Len())
+	}
+	if err := q.Checkpoint(ctx); err != nil {
+		t.Fatal(err)
+	}
+	q.ShutDown()
+
+	// the restarted operator only processes the remaining key
+	q, err = New(ctx, "migration", workqueue.DefaultControllerRateLimiter(), client.CoreV1(), "ns", "migration-progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.ShutDown()
+	for _, key := range []string{"a", "b", "c"} {
+		q.Add(key)
+	}
+	if key, _ := q.Get(); key != "c" || q.Len() != 0 {
+		t.Errorf("expected only c to be queued, got %q and %d more", key, q.Len())
+	}
+	if completed := q.Completed(); len(completed) != 2 {
+		t.Errorf("expected 2 completed keys, got %v", completed)
+	}
+
+	// a reset starts over
+	q.Reset()
+	if err := q.Checkpoint(ctx); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "migration-progress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed := configMap.Data[ProcessedKeysKey]; len(processed) != 0 {
+		t.Errorf("expected the progress to be reset, got %q", processed)
+	}
+}
+
+func TestCheckpointTooLarge(t *testing.T) {
+	ctx := context.TODO()
+	client := fake.NewSimpleClientset()
+	q, err := New(ctx, "migration", workqueue.DefaultControllerRateLimiter(), client.CoreV1(), "ns", "migration-progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.ShutDown()
+
+	for i := 0; i < maxCheckpointBytes/32+1; i++ {
+		q.Complete(fmt.Sprintf("%032d", i))
+	}
+	if err := q.Checkpoint(ctx); err == nil || !strings.Contains(err.Error(), "use fewer or shorter keys") {
+		t.Fatalf("expected the checkpoint to be rejected, got %v", err)
+	}
+	if len(client.Actions()) != 1 {
+		t.Errorf("expected no write, got %v", client.Actions())
+	}
+}
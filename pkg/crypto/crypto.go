@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
@@ -17,6 +18,7 @@ import (
 	"math/big"
 	mathrand "math/rand"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/util/cert"
+	"k8s.io/utils/clock"
 )
 
 // TLS versions that are known to golang. Go 1.13 adds support for
@@ -442,6 +445,17 @@ type CA struct {
 	Config *TLSCertificateConfig
 
 	SerialGenerator SerialGenerator
+
+	// Clock is the time the validity of issued certificates starts at, the real time if nil. Tests can set a fake
+	// clock to issue certificates at any time, e.g. close to their expiry.
+	Clock clock.PassiveClock
+}
+
+func (ca *CA) now() time.Time {
+	if ca.Clock == nil {
+		return time.Now()
+	}
+	return ca.Clock.Now()
 }
 
 // SerialGenerator is an interface for getting a serial number for the cert.  It MUST be thread-safe.
@@ -643,24 +657,37 @@ func MakeSelfSignedCAConfigForSubject(subject pkix.Name, expireDays int) (*TLSCe
 	}
 
 	caLifetime := time.Duration(caLifetimeInDays) * 24 * time.Hour
-	return makeSelfSignedCAConfigForSubjectAndDuration(subject, caLifetime)
+	return makeSelfSignedCAConfigForSubjectAndDuration(subject, caLifetime, DefaultKeyAlgorithm, time.Now)
 }
 
 func MakeSelfSignedCAConfigForDuration(name string, caLifetime time.Duration) (*TLSCertificateConfig, error) {
+	return MakeSelfSignedCAConfigForDurationWithKeyAlgorithm(name, caLifetime, DefaultKeyAlgorithm)
+}
+
+// MakeSelfSignedCAConfigForDurationWithClock is MakeSelfSignedCAConfigForDurationWithKeyAlgorithm for a CA valid from
+// the time of the clock.
+func MakeSelfSignedCAConfigForDurationWithClock(name string, caLifetime time.Duration, keyAlgorithm KeyAlgorithm, clock clock.PassiveClock) (*TLSCertificateConfig, error) {
 	subject := pkix.Name{CommonName: name}
-	return makeSelfSignedCAConfigForSubjectAndDuration(subject, caLifetime)
+	return makeSelfSignedCAConfigForSubjectAndDuration(subject, caLifetime, keyAlgorithm, clock.Now)
 }
 
-func makeSelfSignedCAConfigForSubjectAndDuration(subject pkix.Name, caLifetime time.Duration) (*TLSCertificateConfig, error) {
+// MakeSelfSignedCAConfigForDurationWithKeyAlgorithm is MakeSelfSignedCAConfigForDuration with a key of the given
+// algorithm.
+func MakeSelfSignedCAConfigForDurationWithKeyAlgorithm(name string, caLifetime time.Duration, keyAlgorithm KeyAlgorithm) (*TLSCertificateConfig, error) {
+	subject := pkix.Name{CommonName: name}
+	return makeSelfSignedCAConfigForSubjectAndDuration(subject, caLifetime, keyAlgorithm, time.Now)
+}
+
+func makeSelfSignedCAConfigForSubjectAndDuration(subject pkix.Name, caLifetime time.Duration, keyAlgorithm KeyAlgorithm, currentTime func() time.Time) (*TLSCertificateConfig, error) {
 	// Create CA cert
-	rootcaPublicKey, rootcaPrivateKey, publicKeyHash, err := newKeyPairWithHash()
+	rootcaPublicKey, rootcaPrivateKey, publicKeyHash, err := newKeyPairWithHashForAlgorithm(keyAlgorithm)
 	if err != nil {
 		return nil, err
 	}
 	// AuthorityKeyId and SubjectKeyId should match for a self-signed CA
 	authorityKeyId := publicKeyHash
 	subjectKeyId := publicKeyHash
-	rootcaTemplate := newSigningCertificateTemplateForDuration(subject, caLifetime, time.Now, authorityKeyId, subjectKeyId)
+	rootcaTemplate := newSigningCertificateTemplateForDuration(subject, caLifetime, currentTime, authorityKeyId, subjectKeyId)
 	rootcaCert, err := signCertificate(rootcaTemplate, rootcaPublicKey, rootcaTemplate, rootcaPrivateKey)
 	if err != nil {
 		return nil, err
@@ -673,14 +700,24 @@ func makeSelfSignedCAConfigForSubjectAndDuration(subject pkix.Name, caLifetime t
 }
 
 func MakeCAConfigForDuration(name string, caLifetime time.Duration, issuer *CA) (*TLSCertificateConfig, error) {
+	return MakeCAConfigForDurationWithKeyAlgorithm(name, caLifetime, issuer, DefaultKeyAlgorithm)
+}
+
+// MakeCAConfigForDurationWithKeyAlgorithm is MakeCAConfigForDuration with a key of the given algorithm.
+func MakeCAConfigForDurationWithKeyAlgorithm(name string, caLifetime time.Duration, issuer *CA, keyAlgorithm KeyAlgorithm, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
 	// Create CA cert
-	signerPublicKey, signerPrivateKey, publicKeyHash, err := newKeyPairWithHash()
+	signerPublicKey, signerPrivateKey, publicKeyHash, err := newKeyPairWithHashForAlgorithm(keyAlgorithm)
 	if err != nil {
 		return nil, err
 	}
 	authorityKeyId := issuer.Config.Certs[0].SubjectKeyId
 	subjectKeyId := publicKeyHash
-	signerTemplate := newSigningCertificateTemplateForDuration(pkix.Name{CommonName: name}, caLifetime, time.Now, authorityKeyId, subjectKeyId)
+	signerTemplate := newSigningCertificateTemplateForDuration(pkix.Name{CommonName: name}, caLifetime, issuer.now, authorityKeyId, subjectKeyId)
+	for _, fn := range fns {
+		if err := fn(signerTemplate); err != nil {
+			return nil, err
+		}
+	}
 	signerCert, err := issuer.signCertificate(signerTemplate, signerPublicKey)
 	if err != nil {
 		return nil, err
@@ -737,6 +774,7 @@ func (ca *CA) MakeAndWriteSubCA(certFile, keyFile, serialFile, name string, expi
 	return &CA{
 		Config:          subCAConfig,
 		SerialGenerator: serialGenerator,
+		Clock:           ca.Clock,
 	}, nil
 }
 
@@ -785,11 +823,26 @@ func (ca *CA) MakeAndWriteServerCert(certFile, keyFile string, hostnames sets.St
 // if the extension attempt failed.
 type CertificateExtensionFunc func(*x509.Certificate) error
 
+// WithAuthorityInfoAccess adds the Authority Information Access extension to the certificate, pointing relying
+// parties to the OCSP responders and the certificate of the issuer, e.g. for OCSP stapling. The URLs must be absolute.
+func WithAuthorityInfoAccess(ocspServers, issuingCertificateURLs []string) CertificateExtensionFunc {
+	return func(certificate *x509.Certificate) error {
+		for _, rawURL := range append(append([]string{}, ocspServers...), issuingCertificateURLs...) {
+			if parsed, err := url.Parse(rawURL); err != nil || !parsed.IsAbs() {
+				return fmt.Errorf("invalid authority information access URL %q", rawURL)
+			}
+		}
+		certificate.OCSPServer = append(certificate.OCSPServer, ocspServers...)
+		certificate.IssuingCertificateURL = append(certificate.IssuingCertificateURL, issuingCertificateURLs...)
+		return nil
+	}
+}
+
 func (ca *CA) MakeServerCert(hostnames sets.String, expireDays int, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
 	serverPublicKey, serverPrivateKey, publicKeyHash, _ := newKeyPairWithHash()
 	authorityKeyId := ca.Config.Certs[0].SubjectKeyId
 	subjectKeyId := publicKeyHash
-	serverTemplate := newServerCertificateTemplate(pkix.Name{CommonName: hostnames.List()[0]}, hostnames.List(), expireDays, time.Now, authorityKeyId, subjectKeyId)
+	serverTemplate := newServerCertificateTemplate(pkix.Name{CommonName: hostnames.List()[0]}, hostnames.List(), expireDays, ca.now, authorityKeyId, subjectKeyId)
 	for _, fn := range fns {
 		if err := fn(serverTemplate); err != nil {
 			return nil, err
@@ -807,10 +860,18 @@ func (ca *CA) MakeServerCert(hostnames sets.String, expireDays int, fns ...Certi
 }
 
 func (ca *CA) MakeServerCertForDuration(hostnames sets.String, lifetime time.Duration, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
-	serverPublicKey, serverPrivateKey, publicKeyHash, _ := newKeyPairWithHash()
+	return ca.MakeServerCertForDurationWithKeyAlgorithm(hostnames, lifetime, DefaultKeyAlgorithm, fns...)
+}
+
+// MakeServerCertForDurationWithKeyAlgorithm is MakeServerCertForDuration with a key of the given algorithm.
+func (ca *CA) MakeServerCertForDurationWithKeyAlgorithm(hostnames sets.String, lifetime time.Duration, keyAlgorithm KeyAlgorithm, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
+	serverPublicKey, serverPrivateKey, publicKeyHash, err := newKeyPairWithHashForAlgorithm(keyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
 	authorityKeyId := ca.Config.Certs[0].SubjectKeyId
 	subjectKeyId := publicKeyHash
-	serverTemplate := newServerCertificateTemplateForDuration(pkix.Name{CommonName: hostnames.List()[0]}, hostnames.List(), lifetime, time.Now, authorityKeyId, subjectKeyId)
+	serverTemplate := newServerCertificateTemplateForDuration(pkix.Name{CommonName: hostnames.List()[0]}, hostnames.List(), lifetime, ca.now, authorityKeyId, subjectKeyId)
 	for _, fn := range fns {
 		if err := fn(serverTemplate); err != nil {
 			return nil, err
@@ -848,7 +909,7 @@ func (ca *CA) MakeClientCertificate(certFile, keyFile string, u user.Info, expir
 	}
 
 	clientPublicKey, clientPrivateKey, _ := NewKeyPair()
-	clientTemplate := newClientCertificateTemplate(userToSubject(u), expireDays, time.Now)
+	clientTemplate := newClientCertificateTemplate(userToSubject(u), expireDays, ca.now)
 	clientCrt, err := ca.signCertificate(clientTemplate, clientPublicKey)
 	if err != nil {
 		return nil, err
@@ -874,8 +935,22 @@ func (ca *CA) MakeClientCertificate(certFile, keyFile string, u user.Info, expir
 }
 
 func (ca *CA) MakeClientCertificateForDuration(u user.Info, lifetime time.Duration) (*TLSCertificateConfig, error) {
-	clientPublicKey, clientPrivateKey, _ := NewKeyPair()
-	clientTemplate := newClientCertificateTemplateForDuration(userToSubject(u), lifetime, time.Now)
+	return ca.MakeClientCertificateForDurationWithKeyAlgorithm(u, lifetime, DefaultKeyAlgorithm)
+}
+
+// MakeClientCertificateForDurationWithKeyAlgorithm is MakeClientCertificateForDuration with a key of the given
+// algorithm.
+func (ca *CA) MakeClientCertificateForDurationWithKeyAlgorithm(u user.Info, lifetime time.Duration, keyAlgorithm KeyAlgorithm, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
+	clientPublicKey, clientPrivateKey, err := NewKeyPairForAlgorithm(keyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	clientTemplate := newClientCertificateTemplateForDuration(userToSubject(u), lifetime, ca.now)
+	for _, fn := range fns {
+		if err := fn(clientTemplate); err != nil {
+			return nil, err
+		}
+	}
 	clientCrt, err := ca.signCertificate(clientTemplate, clientPublicKey)
 	if err != nil {
 		return nil, err
@@ -1122,6 +1197,16 @@ func warnAboutCertificateLifeTime(name string, defaultLifetimeInDays int) {
 }
 
 func signCertificate(template *x509.Certificate, requestKey crypto.PublicKey, issuer *x509.Certificate, issuerKey crypto.PrivateKey) (*x509.Certificate, error) {
+	if signer, ok := issuerKey.(crypto.Signer); ok {
+		if _, isRSA := signer.Public().(*rsa.PublicKey); !isRSA {
+			// the templates default to SHA256WithRSA, let x509 pick the algorithm for other issuer keys
+			template.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+		}
+	}
+	if _, isRSA := requestKey.(*rsa.PublicKey); !isRSA {
+		// key encipherment is only defined for RSA keys
+		template.KeyUsage &^= x509.KeyUsageKeyEncipherment
+	}
 	derBytes, err := x509.CreateCertificate(rand.Reader, template, issuer, requestKey, issuerKey)
 	if err != nil {
 		return nil, err
@@ -1160,6 +1245,14 @@ func encodeKey(key crypto.PrivateKey) ([]byte, error) {
 		if err := pem.Encode(&b, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}); err != nil {
 			return []byte{}, err
 		}
+	case ed25519.PrivateKey:
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return []byte{}, err
+		}
+		if err := pem.Encode(&b, &pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}); err != nil {
+			return []byte{}, err
+		}
 	default:
 		return []byte{}, errors.New("Unrecognized key type")
 
@@ -459,3 +459,29 @@ func TestCertGeneration(t *testing.T) {
 	require.Equal(t, []string{"testclients"}, clientCert.Certs[0].Subject.Organization)
 	require.Equal(t, ca.Config.Certs[0].SubjectKeyId, clientCert.Certs[0].AuthorityKeyId)
 }
+
+func TestWithAuthorityInfoAccess(t *testing.T) {
+	caConfig, err := MakeSelfSignedCAConfigForDuration("signer", time.Hour)
+	require.NoError(t, err)
+	ca := &CA{Config: caConfig, SerialGenerator: &RandomSerialGenerator{}}
+	aia := WithAuthorityInfoAccess([]string{"http://ocsp.example.com"}, []string{"http://pki.example.com/signer.crt"})
+
+	server, err := ca.MakeServerCertForDuration(sets.NewString("foo.svc"), time.Hour, aia)
+	require.NoError(t, err)
+	client, err := ca.MakeClientCertificateForDurationWithKeyAlgorithm(&user.DefaultInfo{Name: "foo"}, time.Hour, DefaultKeyAlgorithm, aia)
+	require.NoError(t, err)
+	intermediate, err := MakeCAConfigForDurationWithKeyAlgorithm("intermediate", time.Hour, ca, DefaultKeyAlgorithm, aia)
+	require.NoError(t, err)
+	for _, certKeyPair := range []*TLSCertificateConfig{server, client, intermediate} {
+		// the extension survives encoding
+		certBytes, _, err := certKeyPair.GetPEMBytes()
+		require.NoError(t, err)
+		certs, err := CertsFromPEM(certBytes)
+		require.NoError(t, err)
+		require.Equal(t, []string{"http://ocsp.example.com"}, certs[0].OCSPServer)
+		require.Equal(t, []string{"http://pki.example.com/signer.crt"}, certs[0].IssuingCertificateURL)
+	}
+
+	_, err = ca.MakeServerCertForDuration(sets.NewString("foo.svc"), time.Hour, WithAuthorityInfoAccess([]string{"ocsp.example.com"}, nil))
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+
+// KeyAlgorithm is the algorithm and size of a generated private key.
+type KeyAlgorithm string
+
+const (
+	RSA2048KeyAlgorithm   KeyAlgorithm = "RSA-2048"
+	RSA4096KeyAlgorithm   KeyAlgorithm = "RSA-4096"
+	ECDSAP256KeyAlgorithm KeyAlgorithm = "ECDSA-P256"
+	ECDSAP384KeyAlgorithm KeyAlgorithm = "ECDSA-P384"
+	// Ed25519KeyAlgorithm keys are stored PKCS#8 encoded. Certificates for them require TLS 1.3 or TLS 1.2 peers
+	// supporting Ed25519 signatures, and are signed with Ed25519 by Ed25519 CAs.
+	Ed25519KeyAlgorithm KeyAlgorithm = "Ed25519"
+
+	// DefaultKeyAlgorithm is the algorithm of the keys generated by the functions without a KeyAlgorithm parameter.
+	DefaultKeyAlgorithm = RSA2048KeyAlgorithm
+)
+
+// NewKeyPairForAlgorithm generates a key pair of the algorithm. The empty algorithm is the DefaultKeyAlgorithm.
+func NewKeyPairForAlgorithm(keyAlgorithm KeyAlgorithm) (crypto.PublicKey, crypto.PrivateKey, error) {
+	switch keyAlgorithm {
+	case "", RSA2048KeyAlgorithm:
+		return newRSAKeyPair()
+	case RSA4096KeyAlgorithm:
+		privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &privateKey.PublicKey, privateKey, nil
+	case ECDSAP256KeyAlgorithm, ECDSAP384KeyAlgorithm:
+		curve := elliptic.P256()
+		if keyAlgorithm == ECDSAP384KeyAlgorithm {
+			curve = elliptic.P384()
+		}
+		privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &privateKey.PublicKey, privateKey, nil
+	case Ed25519KeyAlgorithm:
+		return ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unsupported key algorithm %q", keyAlgorithm)
+	}
+}
+
+// KeyAlgorithmOf returns the algorithm of the public key, or an error if it is none of the supported algorithms.
+func KeyAlgorithmOf(publicKey crypto.PublicKey) (KeyAlgorithm, error) {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch publicKey.N.BitLen() {
+		case 2048:
+			return RSA2048KeyAlgorithm, nil
+		case 4096:
+			return RSA4096KeyAlgorithm, nil
+		}
+		return "", fmt.Errorf("unsupported RSA key size %d", publicKey.N.BitLen())
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			return ECDSAP256KeyAlgorithm, nil
+		case elliptic.P384():
+			return ECDSAP384KeyAlgorithm, nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", publicKey.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return Ed25519KeyAlgorithm, nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// newKeyPairWithHashForAlgorithm is newKeyPairWithHash for the key algorithm.
+func newKeyPairWithHashForAlgorithm(keyAlgorithm KeyAlgorithm) (crypto.PublicKey, crypto.PrivateKey, []byte, error) {
+	publicKey, privateKey, err := NewKeyPairForAlgorithm(keyAlgorithm)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	publicKeyHash, err := hashPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return publicKey, privateKey, publicKeyHash, nil
+}
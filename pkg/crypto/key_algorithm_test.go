@@ -0,0 +1,124 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestKeyAlgorithms(t *testing.T) {
+	for _, keyAlgorithm := range []KeyAlgorithm{RSA2048KeyAlgorithm, ECDSAP256KeyAlgorithm, ECDSAP384KeyAlgorithm, Ed25519KeyAlgorithm} {
+		t.Run(string(keyAlgorithm), func(t *testing.T) {
+			caConfig, err := MakeSelfSignedCAConfigForDurationWithKeyAlgorithm("signer", time.Hour, keyAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual, err := KeyAlgorithmOf(caConfig.Certs[0].PublicKey); err != nil || actual != keyAlgorithm {
+				t.Errorf("expected a CA key of algorithm %s, got %s: %v", keyAlgorithm, actual, err)
+			}
+			certBytes, keyBytes, err := caConfig.GetPEMBytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ca, err := GetCAFromBytes(certBytes, keyBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.Config.Certs[0])
+
+			serverCert, err := ca.MakeServerCertForDurationWithKeyAlgorithm(sets.NewString("localhost"), time.Hour, keyAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := serverCert.Certs[0].Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots}); err != nil {
+				t.Errorf("expected the server certificate to verify: %v", err)
+			}
+			if actual, _ := KeyAlgorithmOf(serverCert.Certs[0].PublicKey); actual != keyAlgorithm {
+				t.Errorf("expected a server key of algorithm %s, got %s", keyAlgorithm, actual)
+			}
+			if keyAlgorithm != RSA2048KeyAlgorithm && serverCert.Certs[0].KeyUsage&x509.KeyUsageKeyEncipherment != 0 {
+				t.Errorf("expected no key encipherment usage for a %s key", keyAlgorithm)
+			}
+
+			clientCert, err := ca.MakeClientCertificateForDurationWithKeyAlgorithm(&user.DefaultInfo{Name: "foo"}, time.Hour, keyAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := clientCert.Certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+				t.Errorf("expected the client certificate to verify: %v", err)
+			}
+		})
+	}
+
+	if _, _, err := NewKeyPairForAlgorithm("DSA-1024"); err == nil {
+		t.Error("expected an unsupported key algorithm to fail")
+	}
+}
+
+func TestEd25519TLSHandshake(t *testing.T) {
+	caConfig, err := MakeSelfSignedCAConfigForDurationWithKeyAlgorithm("signer", time.Hour, Ed25519KeyAlgorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &CA{Config: caConfig, SerialGenerator: &RandomSerialGenerator{}}
+	serverCert, err := ca.MakeServerCertForDurationWithKeyAlgorithm(sets.NewString("localhost"), time.Hour, Ed25519KeyAlgorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := ca.MakeClientCertificateForDurationWithKeyAlgorithm(&user.DefaultInfo{Name: "foo"}, time.Hour, Ed25519KeyAlgorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the keys are used as loaded from their PEM encoding
+	keyPair := func(certKeyPair *TLSCertificateConfig) tls.Certificate {
+		t.Helper()
+		certBytes, keyBytes, err := certKeyPair.GetPEMBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block, _ := pem.Decode(keyBytes); block == nil || block.Type != "PRIVATE KEY" {
+			t.Fatalf("expected a PKCS#8 key, got %q", keyBytes)
+		}
+		pair, err := tls.X509KeyPair(certBytes, keyBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pair
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caConfig.Certs[0])
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	server := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{keyPair(serverCert)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    roots,
+	})
+	client := tls.Client(clientConn, &tls.Config{
+		Certificates: []tls.Certificate{keyPair(clientCert)},
+		RootCAs:      roots,
+		ServerName:   "localhost",
+	})
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Handshake()
+	}()
+	if err := client.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-serverErr; err != nil {
+		t.Fatal(err)
+	}
+	if peer := server.ConnectionState().PeerCertificates[0].Subject.CommonName; peer != "foo" {
+		t.Errorf("expected the client foo, got %q", peer)
+	}
+}
@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrPEMTooManyCertificates is wrapped by the PEMBlockError of the first certificate exceeding
+	// PEMLimits.MaxCertificates.
+	ErrPEMTooManyCertificates = errors.New("too many certificates")
+	// ErrPEMTooLarge is wrapped by the PEMBlockError of the block exceeding PEMLimits.MaxBytes.
+	ErrPEMTooLarge = errors.New("PEM data too large")
+)
+
+// maxPEMLineLength bounds the length of the lines of a PEM block, encoded lines are 64 characters.
+const maxPEMLineLength = 64 * 1024
+
+// PEMLimits bound the PEM data read by a CertificateReader. Zero values do not limit.
+type PEMLimits struct {
+	// MaxCertificates is the maximum number of certificates.
+	MaxCertificates int
+	// MaxBytes is the maximum size of the PEM data in bytes.
+	MaxBytes int64
+}
+
+// PEMBlockError is an error reading a block of PEM data.
+type PEMBlockError struct {
+	// Index is the zero-based index of the block among all blocks, including the skipped ones.
+	Index int
+	// Line is the line the block starts at, starting with 1.
+	Line int
+	Err  error
+}
+
+func (e *PEMBlockError) Error() string {
+	return fmt.Sprintf("PEM block %d at line %d: %v", e.Index, e.Line, e.Err)
+}
+
+func (e *PEMBlockError) Unwrap() error {
+	return e.Err
+}
+
+// CertificateReader iterates the certificates of PEM data read from an io.Reader, holding a single block in memory
+// at a time, e.g. for trust bundles of several megabytes. Like CertsFromPEM, it skips blocks that are no
+// certificates and certificate blocks with headers, and ignores text between blocks.
+type CertificateReader struct {
+	reader *bufio.Reader
+	limits PEMLimits
+
+	bytesRead    int64
+	line         int
+	blocks       int
+	certificates int
+	err          error
+}
+
+// NewCertificateReader returns a CertificateReader reading from r within the limits.
+func NewCertificateReader(r io.Reader, limits PEMLimits) *CertificateReader {
+	return &CertificateReader{reader: bufio.NewReaderSize(r, maxPEMLineLength), limits: limits}
+}
+
+// Next returns the next certificate, io.EOF after the last one. Malformed blocks, certificates that cannot be parsed
+// and exceeded limits are reported as *PEMBlockError, and returned again by subsequent calls.
+func (r *CertificateReader) Next() (*x509.Certificate, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for {
+		block, err := r.nextBlock()
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		index := r.blocks
+		r.blocks++
+
+		decoded, _ := pem.Decode(block.data)
+		if decoded == nil {
+			r.err = &PEMBlockError{Index: index, Line: block.line, Err: errors.New("malformed PEM block")}
+			return nil, r.err
+		}
+		if decoded.Type != "CERTIFICATE" || len(decoded.Headers) != 0 {
+			continue
+		}
+		if r.limits.MaxCertificates > 0 && r.certificates >= r.limits.MaxCertificates {
+			r.err = &PEMBlockError{Index: index, Line: block.line, Err: fmt.Errorf("%w: more than %d", ErrPEMTooManyCertificates, r.limits.MaxCertificates)}
+			return nil, r.err
+		}
+		certificate, err := x509.ParseCertificate(decoded.Bytes)
+		if err != nil {
+			r.err = &PEMBlockError{Index: index, Line: block.line, Err: err}
+			return nil, r.err
+		}
+		r.certificates++
+		return certificate, nil
+	}
+}
+
+type pemBlock struct {
+	// line is the line of the BEGIN line.
+	line int
+	// data is the block from the BEGIN to the END line.
+	data []byte
+}
+
+// nextBlock returns the lines of the next PEM block, io.EOF if there is none.
+func (r *CertificateReader) nextBlock() (*pemBlock, error) {
+	var block *pemBlock
+	var endLine []byte
+	for {
+		line, err := r.readLine(block != nil)
+		if err == io.EOF {
+			if block != nil {
+				return nil, &PEMBlockError{Index: r.blocks, Line: block.line, Err: errors.New("missing END line")}
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			index, blockLine := r.blocks, r.line+1
+			if block != nil {
+				blockLine = block.line
+			}
+			return nil, &PEMBlockError{Index: index, Line: blockLine, Err: err}
+		}
+
+		trimmed := bytes.TrimRight(line, " \t\r\n")
+		if block == nil {
+			if bytes.HasPrefix(trimmed, []byte("-----BEGIN ")) && bytes.HasSuffix(trimmed, []byte("-----")) {
+				blockType := trimmed[len("-----BEGIN ") : len(trimmed)-len("-----")]
+				endLine = append(append([]byte("-----END "), blockType...), "-----"...)
+				block = &pemBlock{line: r.line, data: append([]byte{}, line...)}
+			}
+			continue
+		}
+		block.data = append(block.data, line...)
+		if bytes.Equal(trimmed, endLine) {
+			if !bytes.HasSuffix(block.data, []byte("\n")) {
+				block.data = append(block.data, '\n')
+			}
+			return block, nil
+		}
+	}
+}
+
+// readLine returns the next line including its line break. Long lines fail within blocks and are returned empty
+// between blocks.
+func (r *CertificateReader) readLine(inBlock bool) ([]byte, error) {
+	discarded := false
+	for {
+		fragment, err := r.reader.ReadSlice('\n')
+		r.bytesRead += int64(len(fragment))
+		if r.limits.MaxBytes > 0 && r.bytesRead > r.limits.MaxBytes {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrPEMTooLarge, r.limits.MaxBytes)
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			if inBlock {
+				return nil, fmt.Errorf("line %d is longer than %d bytes", r.line+1, maxPEMLineLength)
+			}
+			discarded = true
+			continue
+		case err == io.EOF && len(fragment) == 0 && !discarded:
+			return nil, io.EOF
+		case err != nil && err != io.EOF:
+			return nil, err
+		}
+		r.line++
+		if discarded {
+			return []byte{}, nil
+		}
+		return append([]byte{}, fragment...), nil
+	}
+}
+
+// CertsFromPEMReader is CertsFromPEM for PEM data read from an io.Reader within the limits.
+func CertsFromPEMReader(r io.Reader, limits PEMLimits) ([]*x509.Certificate, error) {
+	certificateReader := NewCertificateReader(r, limits)
+	certs := []*x509.Certificate{}
+	for {
+		certificate, err := certificateReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return certs, err
+		}
+		certs = append(certs, certificate)
+	}
+	if len(certs) == 0 {
+		return certs, errors.New("Could not read any certificates")
+	}
+	return certs, nil
+}
@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertificateReader(t *testing.T) {
+	var bundle []byte
+	var expected []string
+	for _, name := range []string{"first", "second", "third"} {
+		caConfig, err := MakeSelfSignedCAConfigForDuration(name, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certBytes, keyBytes, err := caConfig.GetPEMBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// keys and text between blocks are skipped, CRLF line breaks are accepted
+		bundle = append(bundle, "# "+name+"\r\n"...)
+		bundle = append(bundle, bytes.ReplaceAll(certBytes, []byte("\n"), []byte("\r\n"))...)
+		bundle = append(bundle, keyBytes...)
+		expected = append(expected, name)
+	}
+
+	readNames := func(r *CertificateReader) ([]string, error) {
+		var names []string
+		for {
+			certificate, err := r.Next()
+			if err != nil {
+				return names, err
+			}
+			names = append(names, certificate.Subject.CommonName)
+		}
+	}
+
+	t.Run("all certificates", func(t *testing.T) {
+		names, err := readNames(NewCertificateReader(bytes.NewReader(bundle), PEMLimits{}))
+		if err != io.EOF {
+			t.Fatal(err)
+		}
+		if strings.Join(names, ",") != strings.Join(expected, ",") {
+			t.Errorf("expected %v, got %v", expected, names)
+		}
+		certs, err := CertsFromPEMReader(bytes.NewReader(bundle), PEMLimits{})
+		if err != nil || len(certs) != 3 {
+			t.Errorf("expected 3 certificates, got %d: %v", len(certs), err)
+		}
+		// compatible with CertsFromPEM
+		certsFromPEM, err := CertsFromPEM(bundle)
+		if err != nil || len(certsFromPEM) != 3 || !certsFromPEM[2].Equal(certs[2]) {
+			t.Errorf("expected the same certificates as CertsFromPEM, got %v", err)
+		}
+	})
+
+	t.Run("too many certificates", func(t *testing.T) {
+		names, err := readNames(NewCertificateReader(bytes.NewReader(bundle), PEMLimits{MaxCertificates: 2}))
+		var blockErr *PEMBlockError
+		if !errors.As(err, &blockErr) || !errors.Is(err, ErrPEMTooManyCertificates) {
+			t.Fatalf("expected a block error for too many certificates, got %v", err)
+		}
+		// the blocks are certificate, key, certificate, key, certificate
+		if blockErr.Index != 4 || len(names) != 2 {
+			t.Errorf("expected the fifth block to fail after two certificates, got %v after %v", err, names)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := CertsFromPEMReader(bytes.NewReader(bundle), PEMLimits{MaxBytes: int64(len(bundle) / 2)})
+		if !errors.Is(err, ErrPEMTooLarge) {
+			t.Errorf("expected an error for too much data, got %v", err)
+		}
+		if _, err := CertsFromPEMReader(bytes.NewReader(bundle), PEMLimits{MaxBytes: int64(len(bundle))}); err != nil {
+			t.Errorf("expected a bundle of the maximum size to be read, got %v", err)
+		}
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		invalid := append(append([]byte{}, bundle...), "-----BEGIN CERTIFICATE-----\nZm9v\n-----END CERTIFICATE-----\n"...)
+		names, err := readNames(NewCertificateReader(bytes.NewReader(invalid), PEMLimits{}))
+		var blockErr *PEMBlockError
+		if !errors.As(err, &blockErr) || blockErr.Index != 6 || len(names) != 3 {
+			t.Fatalf("expected the seventh block to fail after three certificates, got %v after %v", err, names)
+		}
+		if expectedLine := bytes.Count(bundle, []byte("\n")) + 1; blockErr.Line != expectedLine {
+			t.Errorf("expected the error at line %d, got %d", expectedLine, blockErr.Line)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		truncated := bundle[:bytes.LastIndex(bundle, []byte("-----END CERTIFICATE-----"))]
+		_, err := CertsFromPEMReader(bytes.NewReader(truncated), PEMLimits{})
+		var blockErr *PEMBlockError
+		if !errors.As(err, &blockErr) || blockErr.Index != 4 || !strings.Contains(err.Error(), "missing END line") {
+			t.Errorf("expected the fifth block to miss its END line, got %v", err)
+		}
+	})
+
+	t.Run("long lines", func(t *testing.T) {
+		garbage := strings.Repeat("x", 2*maxPEMLineLength) + "\n"
+		if _, err := CertsFromPEMReader(strings.NewReader(garbage+string(bundle)), PEMLimits{}); err != nil {
+			t.Errorf("expected long lines between blocks to be skipped, got %v", err)
+		}
+		_, err := CertsFromPEMReader(strings.NewReader("-----BEGIN CERTIFICATE-----\n"+garbage), PEMLimits{})
+		if !errors.As(err, new(*PEMBlockError)) {
+			t.Errorf("expected a block error for a long line in a block, got %v", err)
+		}
+	})
+
+	t.Run("no certificates", func(t *testing.T) {
+		if _, err := CertsFromPEMReader(strings.NewReader("no PEM"), PEMLimits{}); err == nil {
+			t.Error("expected an error without certificates")
+		}
+	})
+}
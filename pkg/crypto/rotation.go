@@ -2,13 +2,19 @@ package crypto
 
 import (
 	"crypto/x509"
-	"time"
+
+	"k8s.io/utils/clock"
 )
 
 // FilterExpiredCerts checks are all certificates in the bundle valid, i.e. they have not expired.
 // The function returns new bundle with only valid certificates or error if no valid certificate is found.
 func FilterExpiredCerts(certs ...*x509.Certificate) []*x509.Certificate {
-	currentTime := time.Now()
+	return FilterExpiredCertsWithClock(clock.RealClock{}, certs...)
+}
+
+// FilterExpiredCertsWithClock is FilterExpiredCerts at the time of the clock.
+func FilterExpiredCertsWithClock(clock clock.PassiveClock, certs ...*x509.Certificate) []*x509.Certificate {
+	currentTime := clock.Now()
 	var validCerts []*x509.Certificate
 	for _, c := range certs {
 		if c.NotAfter.After(currentTime) {
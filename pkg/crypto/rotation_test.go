@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/cert"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -58,6 +60,43 @@ func TestValidateCertificatesExpired(t *testing.T) {
 	}
 }
 
+func TestValidateCertificatesWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(start)
+	caConfig, err := MakeSelfSignedCAConfigForDurationWithClock("signer", 48*time.Hour, DefaultKeyAlgorithm, fakeClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &CA{Config: caConfig, SerialGenerator: &RandomSerialGenerator{}, Clock: fakeClock}
+
+	// the certificate is issued a day after the CA
+	fakeClock.SetTime(start.Add(24 * time.Hour))
+	server, err := ca.MakeServerCertForDuration(sets.NewString("localhost"), 12*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notAfter := server.Certs[0].NotAfter; !notAfter.Equal(start.Add(36 * time.Hour)) {
+		t.Errorf("expected the certificate to expire at %s, got %s", start.Add(36*time.Hour), notAfter)
+	}
+	if notAfter := caConfig.Certs[0].NotAfter; !notAfter.Equal(start.Add(48 * time.Hour)) {
+		t.Errorf("expected the CA to expire at %s, got %s", start.Add(48*time.Hour), notAfter)
+	}
+
+	for _, test := range []struct {
+		at       time.Duration
+		expected int
+	}{
+		{at: 36*time.Hour - time.Second, expected: 3},
+		{at: 36 * time.Hour, expected: 1},
+		{at: 48 * time.Hour, expected: 0},
+	} {
+		fakeClock.SetTime(start.Add(test.at))
+		if validCerts := FilterExpiredCertsWithClock(fakeClock, server.Certs[0], caConfig.Certs[0], server.Certs[0]); len(validCerts) != test.expected {
+			t.Errorf("expected %d valid certificates after %s, got %d", test.expected, test.at, len(validCerts))
+		}
+	}
+}
+
 // NewCACertificate generates and signs new CA certificate and key.
 func newTestCACertificate(subject pkix.Name, serialNumber int64, validity metav1.Duration, currentTime func() time.Time) (*CA, error) {
 	caPublicKey, caPrivateKey, err := NewKeyPair()
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The functions in this file issue certificates with a CA whose private key is only available as crypto.Signer, e.g.
+// a key in an HSM accessed through a PKCS#11 library. The TLSCertificateConfig of such a CA has the signer as Key,
+// so it can sign, but its key cannot be written with WriteCertConfig or GetPEMBytes. Use EncodeCertificates to store
+// the public certificates.
+
+// MakeSelfSignedCAConfigForSigner returns a self-signed CA certificate for the public key of the signer, with the
+// signer as key.
+func MakeSelfSignedCAConfigForSigner(name string, caLifetime time.Duration, signer crypto.Signer) (*TLSCertificateConfig, error) {
+	if signer == nil {
+		return nil, errors.New("missing signer")
+	}
+	publicKeyHash, err := hashPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	// AuthorityKeyId and SubjectKeyId should match for a self-signed CA
+	template := newSigningCertificateTemplateForDuration(pkix.Name{CommonName: name}, caLifetime, time.Now, publicKeyHash, publicKeyHash)
+	caCert, err := signCertificate(template, signer.Public(), template, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &TLSCertificateConfig{
+		Certs: []*x509.Certificate{caCert},
+		Key:   signer,
+	}, nil
+}
+
+// GetCAFromCertsAndSigner returns a CA for the PEM encoded certificates, the first being the CA certificate, signing
+// with the signer. It fails if the signer does not hold the private key of the CA certificate.
+func GetCAFromCertsAndSigner(certBytes []byte, signer crypto.Signer) (*CA, error) {
+	if signer == nil {
+		return nil, errors.New("missing signer")
+	}
+	certs, err := CertsFromPEM(certBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA certificates: %s", err)
+	}
+	if !SignerMatchesCertificate(signer, certs[0]) {
+		return nil, errors.New("signer does not match the public key of the CA certificate")
+	}
+	return &CA{
+		SerialGenerator: &RandomSerialGenerator{},
+		Config:          &TLSCertificateConfig{Certs: certs, Key: signer},
+	}, nil
+}
+
+// SignerMatchesCertificate returns true if the certificate is for the public key of the signer.
+func SignerMatchesCertificate(signer crypto.Signer, cert *x509.Certificate) bool {
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(cert.PublicKey)
+}
+
+// hashPublicKey returns the subject key id of the public key. RSA keys are hashed like the keys of newKeyPairWithHash.
+func hashPublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	hash := sha1.New()
+	if rsaKey, ok := publicKey.(*rsa.PublicKey); ok {
+		hash.Write(rsaKey.N.Bytes())
+		return hash.Sum(nil), nil
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	hash.Write(der)
+	return hash.Sum(nil), nil
+}
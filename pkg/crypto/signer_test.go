@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// opaqueSigner hides the private key like an HSM does.
+type opaqueSigner struct {
+	crypto.Signer
+}
+
+func TestCAForSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := opaqueSigner{Signer: key}
+
+	caConfig, err := MakeSelfSignedCAConfigForSigner("hsm-signer", time.Hour, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := caConfig.GetPEMBytes(); err == nil {
+		t.Errorf("expected the key of the signer not to be encodable")
+	}
+	certBytes, err := EncodeCertificates(caConfig.Certs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := GetCAFromCertsAndSigner(certBytes, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := ca.MakeServerCertForDuration(sets.NewString("localhost"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Config.Certs[0])
+	if _, err := serverCert.Certs[0].Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots}); err != nil {
+		t.Errorf("expected the server certificate to verify: %v", err)
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetCAFromCertsAndSigner(certBytes, otherKey); err == nil {
+		t.Errorf("expected an error for a signer of another key")
+	}
+}
@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ParseSPIFFEID parses and validates the SPIFFE ID of a workload, i.e. spiffe://<trust domain>/<path>.
+func ParseSPIFFEID(id string) (*url.URL, error) {
+	spiffeID, err := url.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", id, err)
+	}
+	switch {
+	case spiffeID.Scheme != "spiffe":
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: scheme must be spiffe", id)
+	case len(spiffeID.Host) == 0:
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: trust domain is missing", id)
+	case len(spiffeID.Port()) > 0 || spiffeID.User != nil:
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: trust domain must not have a port or user info", id)
+	case len(spiffeID.Path) <= 1:
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: the path of the workload is missing", id)
+	case len(spiffeID.RawQuery) > 0 || len(spiffeID.Fragment) > 0:
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: query and fragment are not allowed", id)
+	}
+	return spiffeID, nil
+}
+
+// MakeSPIFFECertificateForDurationWithKeyAlgorithm creates an X509-SVID, a certificate identifying a workload by the
+// SPIFFE ID as only URI SAN, for mutual TLS. It can be used as client and as serving certificate.
+func (ca *CA) MakeSPIFFECertificateForDurationWithKeyAlgorithm(spiffeID string, lifetime time.Duration, keyAlgorithm KeyAlgorithm, fns ...CertificateExtensionFunc) (*TLSCertificateConfig, error) {
+	uri, err := ParseSPIFFEID(spiffeID)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, privateKey, publicKeyHash, err := newKeyPairWithHashForAlgorithm(keyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	// the SPIFFE ID is the identity, the subject is empty and the SAN extension critical as of RFC 5280
+	template := newClientCertificateTemplateForDuration(pkix.Name{}, lifetime, ca.now)
+	template.URIs = []*url.URL{uri}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+	template.AuthorityKeyId = ca.Config.Certs[0].SubjectKeyId
+	template.SubjectKeyId = publicKeyHash
+	for _, fn := range fns {
+		if err := fn(template); err != nil {
+			return nil, err
+		}
+	}
+	certificate, err := ca.signCertificate(template, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certData, err := EncodeCertificates(certificate)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := encodeKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return GetTLSCertificateConfigFromBytes(certData, keyData)
+}
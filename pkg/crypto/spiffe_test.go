@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestParseSPIFFEID(t *testing.T) {
+	for _, valid := range []string{"spiffe://cluster.local/ns/foo/sa/bar", "spiffe://example.org/workload"} {
+		if _, err := ParseSPIFFEID(valid); err != nil {
+			t.Errorf("expected %q to be valid: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{
+		"https://cluster.local/ns/foo",
+		"spiffe:///ns/foo",
+		"spiffe://cluster.local",
+		"spiffe://cluster.local/",
+		"spiffe://cluster.local:8443/ns/foo",
+		"spiffe://user@cluster.local/ns/foo",
+		"spiffe://cluster.local/ns/foo?x=y",
+		"spiffe://cluster.local/ns/foo#x",
+	} {
+		if _, err := ParseSPIFFEID(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestMakeSPIFFECertificate(t *testing.T) {
+	caConfig, err := MakeSelfSignedCAConfigForDuration("signer", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &CA{Config: caConfig, SerialGenerator: &RandomSerialGenerator{}}
+
+	svid, err := ca.MakeSPIFFECertificateForDurationWithKeyAlgorithm("spiffe://cluster.local/ns/foo/sa/bar", time.Hour, ECDSAP256KeyAlgorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := svid.Certs[0]
+	if len(certificate.URIs) != 1 || certificate.URIs[0].String() != "spiffe://cluster.local/ns/foo/sa/bar" {
+		t.Errorf("expected the SPIFFE ID as only URI SAN, got %v", certificate.URIs)
+	}
+	if len(certificate.Subject.String()) != 0 || len(certificate.DNSNames) != 0 {
+		t.Errorf("expected no other identity, got subject %q and DNS names %v", certificate.Subject, certificate.DNSNames)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caConfig.Certs[0])
+	for _, usage := range []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth} {
+		if _, err := certificate.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Errorf("expected the certificate to verify for usage %v: %v", usage, err)
+		}
+	}
+
+	if _, err := ca.MakeSPIFFECertificateForDurationWithKeyAlgorithm("spiffe://cluster.local", time.Hour, DefaultKeyAlgorithm); err == nil {
+		t.Error("expected an error for a SPIFFE ID without workload path")
+	}
+}
@@ -0,0 +1,190 @@
+package networkutils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// IPFamily is the address family of an IP address or CIDR.
+type IPFamily string
+
+const (
+	IPv4 IPFamily = "IPv4"
+	IPv6 IPFamily = "IPv6"
+)
+
+// IPFamilyOf returns the family of the IP address or CIDR, or an empty family if it is neither. IPv4-mapped IPv6
+// addresses are IPv4.
+func IPFamilyOf(address string) IPFamily {
+	ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(address, "["), "]"))
+	if ip == nil {
+		var err error
+		if ip, _, err = net.ParseCIDR(address); err != nil {
+			return ""
+		}
+	}
+	if ip.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
+
+// URLHost returns the host as used in URLs and host:port strings: IPv6 addresses in brackets, IPv4 addresses and
+// hostnames as they are. Hosts already in brackets are returned unchanged.
+func URLHost(host string) string {
+	if IPFamilyOf(host) == IPv6 && !strings.HasPrefix(host, "[") {
+		return "[" + host + "]"
+	}
+	return host
+}
+
+// NormalizeIP returns the canonical form of the IP address, e.g. "fd00::1" for "FD00:0:0::1". The address may be in
+// brackets.
+func NormalizeIP(fldPath *field.Path, address string) (string, *field.Error) {
+	trimmed := address
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		trimmed = trimmed[1 : len(trimmed)-1]
+	}
+	ip := net.ParseIP(trimmed)
+	if ip == nil {
+		return "", field.Invalid(fldPath, address, "must be a valid IPv4 or IPv6 address")
+	}
+	return ip.String(), nil
+}
+
+// NormalizeCIDR returns the canonical form of the CIDR, e.g. "fd00::/48" for "FD00:0::/48". CIDRs with bits set beyond
+// the mask are invalid, compare ParseCIDRMask.
+func NormalizeCIDR(fldPath *field.Path, cidr string) (string, *field.Error) {
+	ipNet, err := ParseCIDRMask(cidr)
+	if err != nil {
+		return "", field.Invalid(fldPath, cidr, err.Error())
+	}
+	return ipNet.String(), nil
+}
+
+// NormalizeHostPort returns the canonical form of the host:port, with the IPv6 address in brackets and the IP address
+// in canonical form. Hostnames are kept as they are.
+func NormalizeHostPort(fldPath *field.Path, hostPort string) (string, *field.Error) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		if IPFamilyOf(hostPort) == IPv6 && !strings.HasPrefix(hostPort, "[") {
+			return "", field.Invalid(fldPath, hostPort, "IPv6 addresses must be in brackets, e.g. [fd00::1]:443")
+		}
+		return "", field.Invalid(fldPath, hostPort, err.Error())
+	}
+	if len(host) == 0 {
+		return "", field.Invalid(fldPath, hostPort, "missing host")
+	}
+	if len(port) == 0 {
+		return "", field.Invalid(fldPath, hostPort, "missing port")
+	}
+	if IPFamilyOf(host) != "" {
+		var fieldErr *field.Error
+		if host, fieldErr = NormalizeIP(fldPath, host); fieldErr != nil {
+			return "", fieldErr
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// NormalizeDualStackIPs normalizes the IP addresses of a single-stack or dual-stack field: one address, or one IPv4
+// and one IPv6 address. The order is kept, the first address is of the primary family.
+func NormalizeDualStackIPs(fldPath *field.Path, addresses []string) ([]string, field.ErrorList) {
+	return normalizeDualStack(fldPath, addresses, NormalizeIP)
+}
+
+// NormalizeDualStackCIDRs normalizes the CIDRs of a single-stack or dual-stack field, e.g. the service network: one
+// CIDR, or one IPv4 and one IPv6 CIDR. The order is kept, the first CIDR is of the primary family.
+func NormalizeDualStackCIDRs(fldPath *field.Path, cidrs []string) ([]string, field.ErrorList) {
+	return normalizeDualStack(fldPath, cidrs, NormalizeCIDR)
+}
+
+func normalizeDualStack(fldPath *field.Path, values []string, normalize func(*field.Path, string) (string, *field.Error)) ([]string, field.ErrorList) {
+	switch len(values) {
+	case 0:
+		return nil, field.ErrorList{field.Required(fldPath, "")}
+	case 1, 2:
+	default:
+		return nil, field.ErrorList{field.TooMany(fldPath, len(values), 2)}
+	}
+
+	var errs field.ErrorList
+	normalized := make([]string, 0, len(values))
+	for i, value := range values {
+		normalizedValue, err := normalize(fldPath.Index(i), value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		normalized = append(normalized, normalizedValue)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(normalized) == 2 && IPFamilyOf(normalized[0]) == IPFamilyOf(normalized[1]) {
+		return nil, field.ErrorList{field.Invalid(fldPath, values, fmt.Sprintf("dual-stack requires one IPv4 and one IPv6 value, got two %s values", IPFamilyOf(normalized[0])))}
+	}
+	return normalized, nil
+}
+
+// IPFamilies returns the families of the IP addresses or CIDRs in order of their first appearance, e.g. [IPv6, IPv4]
+// for a dual-stack cluster with IPv6 as primary family.
+func IPFamilies(values []string) []IPFamily {
+	var families []IPFamily
+	for _, value := range values {
+		family := IPFamilyOf(value)
+		if len(family) == 0 {
+			continue
+		}
+		found := false
+		for _, existing := range families {
+			if existing == family {
+				found = true
+				break
+			}
+		}
+		if !found {
+			families = append(families, family)
+		}
+	}
+	return families
+}
+
+// PreferIPFamily returns the IP addresses or CIDRs with the values of the preferred family first, keeping the order
+// within each family, e.g. to list the addresses of the primary family of the cluster first.
+func PreferIPFamily(values []string, preferred IPFamily) []string {
+	ordered := make([]string, 0, len(values))
+	for _, value := range values {
+		if IPFamilyOf(value) == preferred {
+			ordered = append(ordered, value)
+		}
+	}
+	for _, value := range values {
+		if IPFamilyOf(value) != preferred {
+			ordered = append(ordered, value)
+		}
+	}
+	return ordered
+}
+
+// ValidateServiceCIDRPairing checks that the service CIDRs match the stack of the cluster CIDRs: a single-stack
+// cluster has a service CIDR of the same family, a dual-stack cluster has a service CIDR of each family, and the
+// primary family, the family of the first CIDR, is the same for both.
+func ValidateServiceCIDRPairing(clusterPath *field.Path, clusterCIDRs []string, servicePath *field.Path, serviceCIDRs []string) field.ErrorList {
+	clusterFamilies := IPFamilies(clusterCIDRs)
+	serviceFamilies := IPFamilies(serviceCIDRs)
+	if len(clusterFamilies) == 0 || len(serviceFamilies) == 0 {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if len(clusterFamilies) != len(serviceFamilies) {
+		errs = append(errs, field.Invalid(servicePath, serviceCIDRs, fmt.Sprintf("must have a CIDR of each family of %s %v, got %v", clusterPath, clusterFamilies, serviceFamilies)))
+	} else if clusterFamilies[0] != serviceFamilies[0] {
+		errs = append(errs, field.Invalid(servicePath.Index(0), serviceCIDRs[0], fmt.Sprintf("must be of the primary family %s of %s", clusterFamilies[0], clusterPath)))
+	}
+	return errs
+}
@@ -0,0 +1,138 @@
+package networkutils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestURLHost(t *testing.T) {
+	for host, expected := range map[string]string{
+		"10.0.0.1":          "10.0.0.1",
+		"fd00::1":           "[fd00::1]",
+		"[fd00::1]":         "[fd00::1]",
+		"api.example.com":   "api.example.com",
+		"::ffff:10.0.0.1":   "::ffff:10.0.0.1",
+		"2001:db8::2:1":     "[2001:db8::2:1]",
+		"not an ip address": "not an ip address",
+	} {
+		if actual := URLHost(host); actual != expected {
+			t.Errorf("expected %q for %q, got %q", expected, host, actual)
+		}
+	}
+}
+
+func TestNormalizeHostPort(t *testing.T) {
+	tests := []struct {
+		hostPort      string
+		expected      string
+		expectedError string
+	}{
+		{hostPort: "10.0.0.1:443", expected: "10.0.0.1:443"},
+		{hostPort: "[FD00:0::1]:443", expected: "[fd00::1]:443"},
+		{hostPort: "api.example.com:6443", expected: "api.example.com:6443"},
+		{hostPort: "fd00::1", expectedError: "IPv6 addresses must be in brackets"},
+		{hostPort: "10.0.0.1", expectedError: "missing port"},
+		{hostPort: ":443", expectedError: "missing host"},
+	}
+	for _, test := range tests {
+		t.Run(test.hostPort, func(t *testing.T) {
+			actual, err := NormalizeHostPort(field.NewPath("endpoint"), test.hostPort)
+			switch {
+			case err != nil && len(test.expectedError) == 0:
+				t.Fatal(err)
+			case err != nil && !strings.Contains(err.Error(), test.expectedError):
+				t.Fatalf("expected error %q, got %v", test.expectedError, err)
+			case err == nil && len(test.expectedError) > 0:
+				t.Fatalf("expected error %q", test.expectedError)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNormalizeDualStackCIDRs(t *testing.T) {
+	tests := []struct {
+		name          string
+		cidrs         []string
+		expected      []string
+		expectedError string
+	}{
+		{name: "single-stack IPv4", cidrs: []string{"172.30.0.0/16"}, expected: []string{"172.30.0.0/16"}},
+		{name: "single-stack IPv6", cidrs: []string{"FD02:0::/112"}, expected: []string{"fd02::/112"}},
+		{name: "dual-stack keeps order", cidrs: []string{"fd02::/112", "172.30.0.0/16"}, expected: []string{"fd02::/112", "172.30.0.0/16"}},
+		{name: "empty", expectedError: "serviceNetwork: Required value"},
+		{name: "too many", cidrs: []string{"172.30.0.0/16", "fd02::/112", "172.31.0.0/16"}, expectedError: "must have at most 2 items"},
+		{name: "same family", cidrs: []string{"172.30.0.0/16", "172.31.0.0/16"}, expectedError: "got two IPv4 values"},
+		{name: "host bits", cidrs: []string{"172.30.0.1/16"}, expectedError: "serviceNetwork[0]: Invalid value: \"172.30.0.1/16\": CIDR network specification \"172.30.0.1/16\" is not in canonical form"},
+		{name: "not a CIDR", cidrs: []string{"172.30.0.0/16", "fd02::"}, expectedError: "serviceNetwork[1]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, errs := NormalizeDualStackCIDRs(field.NewPath("serviceNetwork"), test.cidrs)
+			switch {
+			case len(errs) > 0 && len(test.expectedError) == 0:
+				t.Fatal(errs.ToAggregate())
+			case len(errs) > 0 && !strings.Contains(errs.ToAggregate().Error(), test.expectedError):
+				t.Fatalf("expected error %q, got %v", test.expectedError, errs.ToAggregate())
+			case len(errs) == 0 && len(test.expectedError) > 0:
+				t.Fatalf("expected error %q", test.expectedError)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNormalizeDualStackIPs(t *testing.T) {
+	actual, errs := NormalizeDualStackIPs(field.NewPath("ips"), []string{"[FD00::0:1]", "10.0.0.1"})
+	if len(errs) > 0 {
+		t.Fatal(errs.ToAggregate())
+	}
+	if expected := []string{"fd00::1", "10.0.0.1"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPreferIPFamily(t *testing.T) {
+	values := []string{"10.0.0.1", "fd00::1", "10.0.0.2", "fd00::2"}
+	if actual, expected := PreferIPFamily(values, IPv6), []string{"fd00::1", "fd00::2", "10.0.0.1", "10.0.0.2"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := PreferIPFamily(values, IPv4); !reflect.DeepEqual(actual, []string{"10.0.0.1", "10.0.0.2", "fd00::1", "fd00::2"}) {
+		t.Errorf("unexpected order %v", actual)
+	}
+}
+
+func TestValidateServiceCIDRPairing(t *testing.T) {
+	tests := []struct {
+		name          string
+		clusterCIDRs  []string
+		serviceCIDRs  []string
+		expectedError string
+	}{
+		{name: "single-stack", clusterCIDRs: []string{"10.128.0.0/14"}, serviceCIDRs: []string{"172.30.0.0/16"}},
+		{name: "dual-stack with several cluster CIDRs", clusterCIDRs: []string{"fd01::/48", "fd03::/48", "10.128.0.0/14"}, serviceCIDRs: []string{"fd02::/112", "172.30.0.0/16"}},
+		{name: "missing family", clusterCIDRs: []string{"10.128.0.0/14", "fd01::/48"}, serviceCIDRs: []string{"172.30.0.0/16"}, expectedError: "must have a CIDR of each family of clusterNetwork [IPv4 IPv6], got [IPv4]"},
+		{name: "other family", clusterCIDRs: []string{"fd01::/48"}, serviceCIDRs: []string{"172.30.0.0/16"}, expectedError: "serviceNetwork[0]: Invalid value: \"172.30.0.0/16\": must be of the primary family IPv6 of clusterNetwork"},
+		{name: "swapped primary family", clusterCIDRs: []string{"10.128.0.0/14", "fd01::/48"}, serviceCIDRs: []string{"fd02::/112", "172.30.0.0/16"}, expectedError: "must be of the primary family IPv4"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ValidateServiceCIDRPairing(field.NewPath("clusterNetwork"), test.clusterCIDRs, field.NewPath("serviceNetwork"), test.serviceCIDRs)
+			switch {
+			case len(errs) > 0 && len(test.expectedError) == 0:
+				t.Fatal(errs.ToAggregate())
+			case len(errs) > 0 && !strings.Contains(errs.ToAggregate().Error(), test.expectedError):
+				t.Fatalf("expected error %q, got %v", test.expectedError, errs.ToAggregate())
+			case len(errs) == 0 && len(test.expectedError) > 0:
+				t.Fatalf("expected error %q", test.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package annotationmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/persistentqueue"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// Rule migrates a deprecated annotation key.
+type Rule struct {
+	// Deprecated is the annotation key to migrate.
+	Deprecated string
+	// Replacement is the annotation key the value is moved to. An existing value of the replacement wins. Empty
+	// means the deprecated annotation is removed.
+	Replacement string
+}
+
+// Progress reports how far a migration got.
+type Progress struct {
+	// Scanned is the number of objects scanned by this run.
+	Scanned int
+	// Migrated is the number of objects rewritten by this run.
+	Migrated int
+	// Completed are the resource/namespace pairs which are done, including those of earlier runs.
+	Completed []string
+	// Remaining are the resource/namespace pairs which are not done yet.
+	Remaining []string
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("scanned %d objects, migrated %d, %d of %d resources and namespaces completed", p.Scanned, p.Migrated, len(p.Completed), len(p.Completed)+len(p.Remaining))
+}
+
+// Migrator rewrites deprecated annotations of the objects of the given resources in the managed namespaces. Writes
+// are rate limited. The completed resources and namespaces are checkpointed to a progress config map by a
+// persistentqueue.Queue, so that a migration interrupted e.g. by a restart of the operator resumes where it stopped.
+type Migrator struct {
+	Namespaces []string
+	Resources  []schema.GroupVersionResource
+	Rules      []Rule
+
+	// ProgressNamespace and ProgressName locate the config map storing the progress.
+	ProgressNamespace string
+	ProgressName      string
+
+	// RateLimiter limits the writes, defaults to 5 per second.
+	RateLimiter flowcontrol.RateLimiter
+	// PageSize is the number of objects listed at once, defaults to 500.
+	PageSize int64
+
+	// Plumbing:
+	DynamicClient   dynamic.Interface
+	ConfigMapClient corev1client.ConfigMapsGetter
+	EventRecorder   events.Recorder
+}
+
+// Run migrates all resources and namespaces not completed yet. It stops on the first error, which the next run
+// resumes from.
+func (m Migrator) Run(ctx context.Context) (Progress, error) {
+	rateLimiter := m.RateLimiter
+	if rateLimiter == nil {
+		rateLimiter = flowcontrol.NewTokenBucketRateLimiter(5, 1)
+	}
+	pageSize := m.PageSize
+	if pageSize == 0 {
+		pageSize = 500
+	}
+
+	queue, err := persistentqueue.New(ctx, "AnnotationMigration", workqueue.DefaultControllerRateLimiter(), m.ConfigMapClient, m.ProgressNamespace, m.ProgressName)
+	if err != nil {
+		return Progress{}, err
+	}
+	defer queue.ShutDown()
+
+	type target struct {
+		resource  schema.GroupVersionResource
+		namespace string
+	}
+	progress := Progress{}
+	var units []string
+	targets := map[string]target{}
+	for _, resource := range m.Resources {
+		for _, namespace := range m.Namespaces {
+			unit := unitName(resource, namespace)
+			units = append(units, unit)
+			targets[unit] = target{resource: resource, namespace: namespace}
+			// completed units are skipped
+			queue.Add(unit)
+		}
+	}
+
+	for queue.Len() > 0 {
+		item, _ := queue.Get()
+		unit := item.(string)
+		err := m.migrate(ctx, targets[unit].resource, targets[unit].namespace, pageSize, rateLimiter, &progress)
+		if err == nil {
+			queue.Complete(unit)
+			err = queue.Checkpoint(ctx)
+		}
+		queue.Done(unit)
+		if err != nil {
+			progress.Completed, progress.Remaining = splitUnits(units, queue)
+			return progress, fmt.Errorf("failed to migrate annotations of %s: %w", unit, err)
+		}
+		klog.V(2).Infof("Migrated deprecated annotations of %s", unit)
+	}
+
+	progress.Completed, progress.Remaining = splitUnits(units, queue)
+	if progress.Migrated > 0 {
+		m.EventRecorder.Eventf("DeprecatedAnnotationsMigrated", "Migrated deprecated annotations: %s", progress)
+	}
+	return progress, nil
+}
+
+func (m Migrator) migrate(ctx context.Context, resource schema.GroupVersionResource, namespace string, pageSize int64, rateLimiter flowcontrol.RateLimiter, progress *Progress) error {
+	client := m.DynamicClient.Resource(resource).Namespace(namespace)
+	continueToken := ""
+	for {
+		list, err := client.List(ctx, metav1.ListOptions{Limit: pageSize, Continue: continueToken})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			progress.Scanned++
+			patch, ok := m.annotationsPatch(obj.GetAnnotations())
+			if !ok {
+				continue
+			}
+			if err := rateLimiter.Wait(ctx); err != nil {
+				return err
+			}
+			if _, err := client.Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("%s: %w", obj.GetName(), err)
+			}
+			progress.Migrated++
+		}
+		continueToken = list.GetContinue()
+		if len(continueToken) == 0 {
+			return nil
+		}
+	}
+}
+
+// annotationsPatch returns a merge patch migrating the annotations, false if there is nothing to migrate.
+func (m Migrator) annotationsPatch(annotations map[string]string) ([]byte, bool) {
+	changes := map[string]interface{}{}
+	for _, rule := range m.Rules {
+		value, ok := annotations[rule.Deprecated]
+		if !ok {
+			continue
+		}
+		changes[rule.Deprecated] = nil
+		if _, exists := annotations[rule.Replacement]; len(rule.Replacement) > 0 && !exists {
+			changes[rule.Replacement] = value
+		}
+	}
+	if len(changes) == 0 {
+		return nil, false
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": changes}})
+	if err != nil {
+		// coding error, maps of strings always marshal
+		panic(err)
+	}
+	return patch, true
+}
+
+func unitName(resource schema.GroupVersionResource, namespace string) string {
+	return fmt.Sprintf("%s/%s", resource.GroupResource(), namespace)
+}
+
+func splitUnits(units []string, queue *persistentqueue.Queue) ([]string, []string) {
+	var done, remaining []string
+	for _, unit := range units {
+		if queue.IsCompleted(unit) {
+			done = append(done, unit)
+		} else {
+			remaining = append(remaining, unit)
+		}
+	}
+	sort.Strings(done)
+	sort.Strings(remaining)
+	return done, remaining
+}
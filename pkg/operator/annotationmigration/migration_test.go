@@ -0,0 +1,98 @@
+package annotationmigration
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/openshift/library-go/pkg/controller/persistentqueue"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestMigrator(t *testing.T) {
+	configMapsResource := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := func(namespace, name string, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme,
+		configMap("a", "renamed", map[string]string{"old.openshift.io/owner": "me"}),
+		configMap("a", "both", map[string]string{"old.openshift.io/owner": "old", "new.openshift.io/owner": "new"}),
+		configMap("b", "removed", map[string]string{"legacy.openshift.io/rotation": "x", "keep": "y"}),
+		configMap("b", "untouched", map[string]string{"keep": "y"}),
+	)
+	kubeClient := fake.NewSimpleClientset()
+
+	m := Migrator{
+		Namespaces: []string{"a", "b"},
+		Resources:  []schema.GroupVersionResource{configMapsResource},
+		Rules: []Rule{
+			{Deprecated: "old.openshift.io/owner", Replacement: "new.openshift.io/owner"},
+			{Deprecated: "legacy.openshift.io/rotation"},
+		},
+		ProgressNamespace: "operator",
+		ProgressName:      "annotation-migration",
+		RateLimiter:       flowcontrol.NewFakeAlwaysRateLimiter(),
+		DynamicClient:     dynamicClient,
+		ConfigMapClient:   kubeClient.CoreV1(),
+		EventRecorder:     events.NewInMemoryRecorder("test"),
+	}
+
+	progress, err := m.Run(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Progress{Scanned: 4, Migrated: 3, Completed: []string{"configmaps/a", "configmaps/b"}}
+	if !reflect.DeepEqual(progress, expected) {
+		t.Errorf("expected progress %#v, got %#v", expected, progress)
+	}
+
+	expectedAnnotations := map[string]map[string]string{
+		"a/renamed":   {"new.openshift.io/owner": "me"},
+		"a/both":      {"new.openshift.io/owner": "new"},
+		"b/removed":   {"keep": "y"},
+		"b/untouched": {"keep": "y"},
+	}
+	for key, expected := range expectedAnnotations {
+		namespace, name := key[:1], key[2:]
+		obj, err := dynamicClient.Resource(configMapsResource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := obj.GetAnnotations(); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected annotations %v, got %v", key, expected, actual)
+		}
+	}
+
+	progressConfigMap, err := kubeClient.CoreV1().ConfigMaps("operator").Get(context.TODO(), "annotation-migration", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := progressConfigMap.Data[persistentqueue.ProcessedKeysKey]; actual != "configmaps/a\nconfigmaps/b" {
+		t.Errorf("expected the completed units to be checkpointed, got %q", actual)
+	}
+
+	// completed namespaces are not scanned again
+	progress, err = m.Run(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Scanned != 0 || len(progress.Remaining) != 0 {
+		t.Errorf("expected the migration to be completed, got %#v", progress)
+	}
+}
@@ -0,0 +1,152 @@
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/reasons"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// DisabledCapabilities returns the required capabilities which are disabled in the cluster. Capabilities unknown to
+// the cluster version are never disabled, e.g. on clusters predating them.
+func DisabledCapabilities(clusterVersion *configv1.ClusterVersion, required ...configv1.ClusterVersionCapability) []configv1.ClusterVersionCapability {
+	known := sets.NewString()
+	for _, capability := range clusterVersion.Status.Capabilities.KnownCapabilities {
+		known.Insert(string(capability))
+	}
+	enabled := sets.NewString()
+	for _, capability := range clusterVersion.Status.Capabilities.EnabledCapabilities {
+		enabled.Insert(string(capability))
+	}
+
+	var disabled []configv1.ClusterVersionCapability
+	for _, capability := range required {
+		if known.Has(string(capability)) && !enabled.Has(string(capability)) {
+			disabled = append(disabled, capability)
+		}
+	}
+	return disabled
+}
+
+// gatedController starts the delegate controller once all required capabilities are enabled. Capabilities cannot be
+// disabled once enabled, so the delegate is never stopped.
+//
+// It produces the condition <name>CapabilityGated, which is True with reason CapabilityDisabled while the delegate
+// waits for capabilities.
+type gatedController struct {
+	factory.Controller
+
+	name                 string
+	required             []configv1.ClusterVersionCapability
+	delegate             factory.Controller
+	operatorClient       v1helpers.OperatorClient
+	clusterVersionLister configv1listers.ClusterVersionLister
+
+	lock    sync.Mutex
+	runCtx  context.Context
+	workers int
+	started bool
+}
+
+// NewCapabilityGatedController returns a controller running the delegate only when all required capabilities are
+// enabled, so that controllers of disabled capabilities do not fail on missing APIs or crash-loop the operator.
+func NewCapabilityGatedController(
+	delegate factory.Controller,
+	required []configv1.ClusterVersionCapability,
+	operatorClient v1helpers.OperatorClient,
+	clusterVersionInformer configv1informers.ClusterVersionInformer,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &gatedController{
+		name:                 delegate.Name(),
+		required:             required,
+		delegate:             delegate,
+		operatorClient:       operatorClient,
+		clusterVersionLister: clusterVersionInformer.Lister(),
+	}
+	c.Controller = factory.New().
+		WithInformers(clusterVersionInformer.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		ToController(delegate.Name()+"CapabilityGate", recorder.WithComponentSuffix("capability-gate"))
+	return c
+}
+
+func (c *gatedController) Name() string {
+	return c.name
+}
+
+// Run runs the gate, which starts the delegate with the given workers once the capabilities are enabled.
+func (c *gatedController) Run(ctx context.Context, workers int) {
+	c.lock.Lock()
+	c.runCtx, c.workers = ctx, workers
+	c.lock.Unlock()
+
+	c.Controller.Run(ctx, 1)
+}
+
+func (c *gatedController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	c.lock.Lock()
+	started := c.started
+	c.lock.Unlock()
+	if started {
+		return nil
+	}
+
+	clusterVersion, err := c.clusterVersionLister.Get("version")
+	if apierrors.IsNotFound(err) {
+		// no cluster version, e.g. outside of OpenShift, so there are no capabilities to disable
+		clusterVersion, err = &configv1.ClusterVersion{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	condition := operatorv1.OperatorCondition{
+		Type:   c.name + "CapabilityGated",
+		Status: operatorv1.ConditionFalse,
+		Reason: string(reasons.CapabilitiesEnabled),
+	}
+	disabled := DisabledCapabilities(clusterVersion, c.required...)
+	if len(disabled) > 0 {
+		var names []string
+		for _, capability := range disabled {
+			names = append(names, string(capability))
+		}
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = string(reasons.CapabilityDisabled)
+		condition.Message = fmt.Sprintf("The controller is not started because the capabilities %s are disabled", strings.Join(names, ", "))
+	}
+	if _, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+		return err
+	}
+	if len(disabled) > 0 {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.started || c.runCtx == nil {
+		return nil
+	}
+	c.started = true
+	klog.Infof("Starting %s, all required capabilities are enabled", c.name)
+	syncCtx.Recorder().Eventf(string(reasons.CapabilitiesEnabled), "Starting controller %s, all required capabilities are enabled", c.name)
+	go c.delegate.Run(c.runCtx, c.workers)
+	return nil
+}
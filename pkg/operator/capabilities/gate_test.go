@@ -0,0 +1,100 @@
+package capabilities
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func clusterVersion(known, enabled []configv1.ClusterVersionCapability) *configv1.ClusterVersion {
+	return &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status: configv1.ClusterVersionStatus{Capabilities: configv1.ClusterVersionCapabilitiesStatus{
+			KnownCapabilities:   known,
+			EnabledCapabilities: enabled,
+		}},
+	}
+}
+
+func TestDisabledCapabilities(t *testing.T) {
+	cv := clusterVersion(
+		[]configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole, configv1.ClusterVersionCapabilityInsights},
+		[]configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityInsights},
+	)
+	actual := DisabledCapabilities(cv, configv1.ClusterVersionCapabilityConsole, configv1.ClusterVersionCapabilityInsights, "Unknown")
+	if expected := []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+type fakeController struct {
+	runs int32
+}
+
+func (c *fakeController) Run(ctx context.Context, workers int) { atomic.AddInt32(&c.runs, 1) }
+func (c *fakeController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	return nil
+}
+func (c *fakeController) Name() string { return "Console" }
+
+func TestCapabilityGatedController(t *testing.T) {
+	known := []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole}
+	informers := configinformers.NewSharedInformerFactory(configfake.NewSimpleClientset(), 0)
+	indexer := informers.Config().V1().ClusterVersions().Informer().GetIndexer()
+	if err := indexer.Add(clusterVersion(known, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("test")
+	delegate := &fakeController{}
+	c := NewCapabilityGatedController(delegate, known, operatorClient, informers.Config().V1().ClusterVersions(), recorder).(*gatedController)
+	c.runCtx, c.workers = context.Background(), 1
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	_, status, _, _ := operatorClient.GetOperatorState()
+	condition := v1helpers.FindOperatorCondition(status.Conditions, "ConsoleCapabilityGated")
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "CapabilityDisabled" {
+		t.Fatalf("expected gated condition, got %#v", condition)
+	}
+
+	if err := indexer.Update(clusterVersion(known, known)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return atomic.LoadInt32(&delegate.runs) > 0, nil
+	}); err != nil {
+		t.Fatal("expected the delegate to run")
+	}
+	if runs := atomic.LoadInt32(&delegate.runs); runs != 1 {
+		t.Errorf("expected the delegate to run once, got %d", runs)
+	}
+	_, status, _, _ = operatorClient.GetOperatorState()
+	condition = v1helpers.FindOperatorCondition(status.Conditions, "ConsoleCapabilityGated")
+	if condition == nil || condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected gated condition to be false, got %#v", condition)
+	}
+}
@@ -0,0 +1,136 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+// LegacySecretAdoption hands over the lifecycle of an existing cert/key pair secret, typically created by an older
+// version of an operator without certrotation metadata, to the certrotation controller.
+// The secret is validated and stamped with the annotations and labels the controller expects, so the existing
+// certificate keeps being used until its regular refresh time instead of being rotated right away.
+type LegacySecretAdoption struct {
+	// Namespace is the namespace of the secret to adopt.
+	Namespace string
+	// Name is the name of the secret to adopt.
+	Name string
+	// CertificateType is the type the secret is managed as, CertificateTypeSigner or CertificateTypeTarget.
+	CertificateType CertificateType
+	// Signer, when set, must have signed the adopted target certificate.
+	Signer *x509.Certificate
+	// CertKey and PrivateKeyKey are the secret data keys of the cert/key pair, as configured for the certrotation
+	// controller. Default to tls.crt and tls.key.
+	CertKey       string
+	PrivateKeyKey string
+
+	// Plumbing:
+	Client        corev1client.SecretsGetter
+	EventRecorder events.Recorder
+}
+
+// Adopt adopts the secret. It returns false without an error when the secret does not exist or is already managed.
+// Secrets that fail the validation are left untouched and an error is returned; the certrotation controller
+// replaces them on its next sync.
+func (c LegacySecretAdoption) Adopt(ctx context.Context) (bool, error) {
+	secret, err := c.Client.Secrets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, _, reason := getValidityFromAnnotations(secret.Annotations); len(reason) == 0 {
+		return false, nil
+	}
+
+	adopted := secret.DeepCopy()
+	if err := AdoptLegacySecretWithKeys(adopted, c.CertificateType, c.Signer, c.CertKey, c.PrivateKeyKey); err != nil {
+		c.EventRecorder.Warningf("LegacySecretAdoptionFailed", "%q in %q cannot be adopted: %v", c.Name, c.Namespace, err)
+		return false, err
+	}
+	if _, _, err := resourceapply.ApplySecret(ctx, c.Client, c.EventRecorder, adopted); err != nil {
+		return false, err
+	}
+	c.EventRecorder.Eventf("LegacySecretAdopted", "%q in %q is now managed by certrotation", c.Name, c.Namespace)
+	return true, nil
+}
+
+// AdoptLegacySecret validates the cert/key pair in the secret and stamps it with the certrotation metadata.
+func AdoptLegacySecret(secret *corev1.Secret, certificateType CertificateType, signer *x509.Certificate) error {
+	return AdoptLegacySecretWithKeys(secret, certificateType, signer, "", "")
+}
+
+// AdoptLegacySecretWithKeys is AdoptLegacySecret for a cert/key pair stored under the given secret data keys, which
+// default to tls.crt and tls.key.
+func AdoptLegacySecretWithKeys(secret *corev1.Secret, certificateType CertificateType, signer *x509.Certificate, certKey, privateKeyKey string) error {
+	keys := newSecretDataKeys(certKey, privateKeyKey)
+	certificate, err := validateLegacyCertKeyPair(secret.Data[keys.cert], secret.Data[keys.privateKey], keys, certificateType, signer)
+	if err != nil {
+		return err
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[CertificateNotAfterAnnotation] = certificate.NotAfter.Format(time.RFC3339)
+	secret.Annotations[CertificateNotBeforeAnnotation] = certificate.NotBefore.Format(time.RFC3339)
+	secret.Annotations[CertificateIssuer] = certificate.Issuer.CommonName
+	if certificateType == CertificateTypeTarget && (len(certificate.DNSNames) > 0 || len(certificate.IPAddresses) > 0) {
+		// mirror ServingRotation.SetAnnotations so the hostnames check does not force a rotation
+		hostnames := sets.NewString(certificate.DNSNames...)
+		for _, ip := range certificate.IPAddresses {
+			hostnames.Insert(ip.String())
+		}
+		secret.Annotations[CertificateHostnames] = strings.Join(hostnames.List(), ",")
+	}
+	secret.Type = keys.secretType(secret.Type)
+	LabelAsManagedSecret(secret, certificateType)
+	return nil
+}
+
+func validateLegacyCertKeyPair(certPEM, keyPEM []byte, keys secretDataKeys, certificateType CertificateType, signer *x509.Certificate) (*x509.Certificate, error) {
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("missing %q or %q", keys.cert, keys.privateKey)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, fmt.Errorf("invalid cert/key pair: %v", err)
+	}
+	certificates, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	certificate := certificates[0]
+	if time.Now().After(certificate.NotAfter) {
+		return nil, fmt.Errorf("certificate expired at %v", certificate.NotAfter)
+	}
+
+	switch certificateType {
+	case CertificateTypeSigner:
+		if !certificate.IsCA {
+			return nil, fmt.Errorf("certificate %q is not a CA", certificate.Subject.CommonName)
+		}
+	case CertificateTypeTarget:
+		if signer != nil {
+			if err := certificate.CheckSignatureFrom(signer); err != nil {
+				return nil, fmt.Errorf("certificate %q is not signed by %q: %v", certificate.Subject.CommonName, signer.Subject.CommonName, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported certificate type %q", certificateType)
+	}
+	return certificate, nil
+}
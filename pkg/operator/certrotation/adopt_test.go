@@ -0,0 +1,192 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestAdoptLegacySecret(t *testing.T) {
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA, err := newTestCACertificate(pkix.Name{CommonName: "other-signer"}, int64(2), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serving, err := ca.MakeServerCert(sets.NewString("foo.svc", "127.0.0.1"), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servingCert, servingKey, err := serving.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, caKey, err := ca.Config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		data            map[string][]byte
+		certificateType CertificateType
+		signer          *x509.Certificate
+		certKey         string
+		privateKeyKey   string
+		expectedErr     bool
+		expectedType    corev1.SecretType
+	}{
+		{
+			name:            "target",
+			data:            map[string][]byte{"tls.crt": servingCert, "tls.key": servingKey},
+			certificateType: CertificateTypeTarget,
+			signer:          ca.Config.Certs[0],
+		},
+		{
+			name:            "signer",
+			data:            map[string][]byte{"tls.crt": caCert, "tls.key": caKey},
+			certificateType: CertificateTypeSigner,
+		},
+		{
+			name:            "target is not a signer",
+			data:            map[string][]byte{"tls.crt": servingCert, "tls.key": servingKey},
+			certificateType: CertificateTypeSigner,
+			expectedErr:     true,
+		},
+		{
+			name:            "signed by another signer",
+			data:            map[string][]byte{"tls.crt": servingCert, "tls.key": servingKey},
+			certificateType: CertificateTypeTarget,
+			signer:          otherCA.Config.Certs[0],
+			expectedErr:     true,
+		},
+		{
+			name:            "mismatched key",
+			data:            map[string][]byte{"tls.crt": servingCert, "tls.key": caKey},
+			certificateType: CertificateTypeTarget,
+			expectedErr:     true,
+		},
+		{
+			name:            "target with custom keys",
+			data:            map[string][]byte{"server.crt": servingCert, "server.key": servingKey},
+			certificateType: CertificateTypeTarget,
+			signer:          ca.Config.Certs[0],
+			certKey:         "server.crt",
+			privateKeyKey:   "server.key",
+			expectedType:    corev1.SecretTypeOpaque,
+		},
+		{
+			name:            "custom keys missing",
+			data:            map[string][]byte{"tls.crt": servingCert, "tls.key": servingKey},
+			certificateType: CertificateTypeTarget,
+			certKey:         "server.crt",
+			privateKeyKey:   "server.key",
+			expectedErr:     true,
+		},
+		{
+			name:            "missing key",
+			data:            map[string][]byte{"tls.crt": servingCert},
+			certificateType: CertificateTypeTarget,
+			expectedErr:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secret := &corev1.Secret{Data: test.data, Type: corev1.SecretTypeOpaque}
+			err := AdoptLegacySecretWithKeys(secret, test.certificateType, test.signer, test.certKey, test.privateKeyKey)
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if test.expectedErr {
+				if len(secret.Annotations) != 0 {
+					t.Errorf("expected no annotations on failure, got %v", secret.Annotations)
+				}
+				return
+			}
+			expectedType := test.expectedType
+			if len(expectedType) == 0 {
+				expectedType = corev1.SecretTypeTLS
+			}
+			if secret.Type != expectedType {
+				t.Errorf("expected %q secret type, got %q", expectedType, secret.Type)
+			}
+			if secret.Labels[ManagedCertificateTypeLabelName] != string(test.certificateType) {
+				t.Errorf("unexpected labels: %v", secret.Labels)
+			}
+			if _, _, reason := getValidityFromAnnotations(secret.Annotations); len(reason) > 0 {
+				t.Error(reason)
+			}
+			if secret.Annotations[CertificateIssuer] != "signer-tests" {
+				t.Errorf("unexpected issuer: %q", secret.Annotations[CertificateIssuer])
+			}
+			if test.certificateType == CertificateTypeTarget {
+				if secret.Annotations[CertificateHostnames] != "127.0.0.1,foo.svc" {
+					t.Errorf("unexpected hostnames: %q", secret.Annotations[CertificateHostnames])
+				}
+				rotation := &ServingRotation{Hostnames: func() []string { return []string{"foo.svc", "127.0.0.1"} }}
+				if reason := rotation.NeedNewTargetCertKeyPair(secret.Annotations, ca, []*x509.Certificate{ca.Config.Certs[0]}, 24*time.Hour, false); len(reason) > 0 {
+					t.Errorf("adopted secret should not require rotation: %s", reason)
+				}
+			}
+		})
+	}
+}
+
+func TestLegacySecretAdoption(t *testing.T) {
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, caKey, err := ca.Config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "signer"},
+		Data:       map[string][]byte{"tls.crt": caCert, "tls.key": caKey},
+		Type:       corev1.SecretTypeTLS,
+	})
+	adoption := LegacySecretAdoption{
+		Namespace:       "ns",
+		Name:            "signer",
+		CertificateType: CertificateTypeSigner,
+		Client:          client.CoreV1(),
+		EventRecorder:   events.NewInMemoryRecorder("test"),
+	}
+
+	adopted, err := adoption.Adopt(context.TODO())
+	if err != nil || !adopted {
+		t.Fatalf("expected the secret to be adopted, got %v: %v", adopted, err)
+	}
+	updated := false
+	for _, action := range client.Actions() {
+		if action.Matches("update", "secrets") {
+			updated = true
+			secret := action.(clienttesting.UpdateAction).GetObject().(*corev1.Secret)
+			if len(secret.Annotations[CertificateNotAfterAnnotation]) == 0 {
+				t.Errorf("missing annotations: %v", secret.Annotations)
+			}
+		}
+	}
+	if !updated {
+		t.Errorf("expected the secret to be updated: %v", client.Actions())
+	}
+
+	// already managed secrets are left alone
+	adopted, err = adoption.Adopt(context.TODO())
+	if err != nil || adopted {
+		t.Errorf("expected no adoption of a managed secret, got %v: %v", adopted, err)
+	}
+}
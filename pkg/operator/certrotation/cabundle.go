@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"reflect"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -19,7 +20,7 @@ import (
 	"github.com/openshift/library-go/pkg/certs"
 	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
-	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
 // CABundleConfigMap maintains a CA bundle config map, by adding new CA certs coming from RotatedSigningCASecret, and by removing expired old ones.
@@ -28,18 +29,54 @@ type CABundleConfigMap struct {
 	Namespace string
 	// Name is the name of the ConfigMap to maintain.
 	Name string
+	// AdditionalFormats, if set, publishes the bundle in these formats too, for runtimes that cannot consume PEM bundles.
+	AdditionalFormats []CABundleFormat
+	// TruststorePassword protects the integrity of the JKS truststore. Defaults to DefaultTruststorePassword.
+	TruststorePassword string
+	// TrustSources, if set, provide additional trust anchors merged into the bundle, e.g. enterprise root CAs. A source
+	// failing to provide its certificates keeps the certificates merged from it before and is reported by a warning
+	// event, it does not block the rotation.
+	TrustSources []CABundleTrustSource
+	// MaxBundleSize, if set, limits the number of signers in the bundle, including the current signer. Previous signers
+	// beyond the limit are removed oldest first before they expire, but only once they were superseded for
+	// MinimumSignerOverlap. Certificates of TrustSources are not counted.
+	MaxBundleSize int
+	// MinimumSignerOverlap is how long a previous signer is trusted at least after it was superseded by a newer signer,
+	// so that certificates issued by it are still trusted while their consumers pick up the new ones. It only applies
+	// to signers beyond MaxBundleSize, the other signers are trusted until they expire.
+	MinimumSignerOverlap time.Duration
 
 	// Plumbing:
 	Informer      corev1informers.ConfigMapInformer
 	Lister        corev1listers.ConfigMapLister
 	Client        corev1client.ConfigMapsGetter
 	EventRecorder events.Recorder
+
+	// liveReadFallback reads the object from the server when it is missing in the informer cache, see
+	// WithLiveReadFallback.
+	liveReadFallback bool
+	// fieldManager, if set, writes the config map with server-side apply, see WithServerSideApply.
+	fieldManager string
+	// controllerLabel, if set, is the value of ManagedByControllerLabelName the config map is labeled with, see
+	// WithOrphanedResourceDeletion.
+	controllerLabel string
+	// now is the clock of the controller, time.Now if not set.
+	now func() time.Time
+}
+
+// liveReadClient returns the client to read the config map from the server when it is missing in the informer cache,
+// nil unless enabled with WithLiveReadFallback.
+func (c CABundleConfigMap) liveReadClient() corev1client.ConfigMapsGetter {
+	if !c.liveReadFallback {
+		return nil
+	}
+	return c.Client
 }
 
 func (c CABundleConfigMap) ensureConfigMapCABundle(ctx context.Context, signingCertKeyPair *crypto.CA) ([]*x509.Certificate, error) {
 	// by this point we have current signing cert/key pair.  We now need to make sure that the ca-bundle configmap has this cert and
 	// doesn't have any expired certs
-	originalCABundleConfigMap, err := c.Lister.ConfigMaps(c.Namespace).Get(c.Name)
+	originalCABundleConfigMap, err := v1helpers.GetConfigMap(ctx, c.Lister, c.liveReadClient(), c.Namespace, c.Name)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
@@ -52,11 +89,28 @@ func (c CABundleConfigMap) ensureConfigMapCABundle(ctx context.Context, signingC
 	if err != nil {
 		return nil, err
 	}
-	if originalCABundleConfigMap == nil || originalCABundleConfigMap.Data == nil || !equality.Semantic.DeepEqual(originalCABundleConfigMap.Data, caBundleConfigMap.Data) {
+	updatedCerts, err = c.pruneSupersededSigners(caBundleConfigMap, updatedCerts)
+	if err != nil {
+		return nil, err
+	}
+	updatedCerts, trustSourcesErr := c.mergeTrustSources(ctx, caBundleConfigMap, updatedCerts)
+	if trustSourcesErr != nil {
+		if updatedCerts == nil {
+			return nil, trustSourcesErr
+		}
+		c.EventRecorder.Warningf("CABundleTrustSourceFailed", "%q in %q keeps the previous certificates of failing trust sources: %v", c.Name, c.Namespace, trustSourcesErr)
+	}
+	if err := setAdditionalCABundleFormats(caBundleConfigMap, updatedCerts, c.AdditionalFormats, c.TruststorePassword); err != nil {
+		return nil, err
+	}
+	if originalCABundleConfigMap == nil || originalCABundleConfigMap.Data == nil || !equality.Semantic.DeepEqual(originalCABundleConfigMap.Data, caBundleConfigMap.Data) ||
+		!equality.Semantic.DeepEqual(originalCABundleConfigMap.BinaryData, caBundleConfigMap.BinaryData) ||
+		originalCABundleConfigMap.Annotations[CABundleTrustSourcesAnnotation] != caBundleConfigMap.Annotations[CABundleTrustSourcesAnnotation] {
 		c.EventRecorder.Eventf("CABundleUpdateRequired", "%q in %q requires a new cert", c.Name, c.Namespace)
 		LabelAsManagedConfigMap(caBundleConfigMap, CertificateTypeCABundle)
+		labelAsManagedByController(caBundleConfigMap, c.controllerLabel)
 
-		actualCABundleConfigMap, modified, err := resourceapply.ApplyConfigMap(ctx, c.Client, c.EventRecorder, caBundleConfigMap)
+		actualCABundleConfigMap, modified, err := applyConfigMap(ctx, c.Client, c.EventRecorder, c.fieldManager, caBundleConfigMap)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,135 @@
+package certrotation
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CABundleFormat is an additional serialization of the CA bundle published next to the PEM encoded ca-bundle.crt.
+type CABundleFormat string
+
+const (
+	// CABundleFormatDER publishes the DER encoded certificates concatenated under CABundleDERKey.
+	CABundleFormatDER CABundleFormat = "DER"
+	// CABundleFormatJKS publishes a Java KeyStore truststore holding the certificates under CABundleJKSKey.
+	CABundleFormatJKS CABundleFormat = "JKS"
+
+	// CABundleDERKey is the binary data key holding the DER encoded CA bundle.
+	CABundleDERKey = "ca-bundle.der"
+	// CABundleJKSKey is the binary data key holding the CA bundle as Java truststore.
+	CABundleJKSKey = "ca-bundle.jks"
+
+	// DefaultTruststorePassword is the password protecting the integrity of the Java truststore, it is the JDK default.
+	DefaultTruststorePassword = "changeit"
+)
+
+// caBundleFormatKeys maps the additional formats to the binary data keys holding them.
+var caBundleFormatKeys = map[CABundleFormat]string{
+	CABundleFormatDER: CABundleDERKey,
+	CABundleFormatJKS: CABundleJKSKey,
+}
+
+// setAdditionalCABundleFormats sets the requested additional serializations of the certificates in the config map binary data.
+// The serializations of formats that are no longer requested are removed, so consumers do not trust stale certificates.
+func setAdditionalCABundleFormats(caBundleConfigMap *corev1.ConfigMap, certificates []*x509.Certificate, formats []CABundleFormat, truststorePassword string) error {
+	for format, key := range caBundleFormatKeys {
+		if _, ok := caBundleConfigMap.BinaryData[key]; ok && !hasCABundleFormat(formats, format) {
+			delete(caBundleConfigMap.BinaryData, key)
+		}
+	}
+
+	for _, format := range formats {
+		var key string
+		var data []byte
+		switch format {
+		case CABundleFormatDER:
+			key = CABundleDERKey
+			for _, certificate := range certificates {
+				data = append(data, certificate.Raw...)
+			}
+		case CABundleFormatJKS:
+			if len(truststorePassword) == 0 {
+				truststorePassword = DefaultTruststorePassword
+			}
+			var err error
+			key = CABundleJKSKey
+			data, err = encodeJKSTruststore(certificates, truststorePassword)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported CA bundle format %q", format)
+		}
+		if caBundleConfigMap.BinaryData == nil {
+			caBundleConfigMap.BinaryData = map[string][]byte{}
+		}
+		caBundleConfigMap.BinaryData[key] = data
+	}
+	return nil
+}
+
+func hasCABundleFormat(formats []CABundleFormat, format CABundleFormat) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	jksMagic               = 0xfeedfeed
+	jksVersion             = 2
+	jksTrustedCertEntryTag = 2
+)
+
+// encodeJKSTruststore encodes the certificates as trusted certificate entries of a Java KeyStore (version 2).
+// The creation date of every entry is the certificate NotBefore, so the same certificates always produce the same bytes.
+func encodeJKSTruststore(certificates []*x509.Certificate, password string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	write := func(v interface{}) {
+		// writes to a bytes.Buffer do not fail
+		_ = binary.Write(buf, binary.BigEndian, v)
+	}
+	writeUTF := func(s string) error {
+		if len(s) > 0xffff {
+			return fmt.Errorf("string too long: %d", len(s))
+		}
+		write(uint16(len(s)))
+		buf.WriteString(s)
+		return nil
+	}
+
+	write(uint32(jksMagic))
+	write(uint32(jksVersion))
+	write(uint32(len(certificates)))
+	for i, certificate := range certificates {
+		write(uint32(jksTrustedCertEntryTag))
+		if err := writeUTF(fmt.Sprintf("ca-%d", i)); err != nil {
+			return nil, err
+		}
+		write(uint64(certificate.NotBefore.UnixNano() / 1e6))
+		if err := writeUTF("X.509"); err != nil {
+			return nil, err
+		}
+		write(uint32(len(certificate.Raw)))
+		buf.Write(certificate.Raw)
+	}
+
+	// the integrity digest is SHA-1 over the UTF-16 password, the "Mighty Aphrodite" whitener and the keystore content
+	digest := sha1.New()
+	for _, c := range utf16.Encode([]rune(password)) {
+		digest.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	digest.Write([]byte("Mighty Aphrodite"))
+	digest.Write(buf.Bytes())
+	buf.Write(digest.Sum(nil))
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,222 @@
+package certrotation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestEnsureConfigMapCABundleAdditionalFormats(t *testing.T) {
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := kubefake.NewSimpleClientset()
+	c := &CABundleConfigMap{
+		Namespace:         "ns",
+		Name:              "trust-bundle",
+		AdditionalFormats: []CABundleFormat{CABundleFormatDER, CABundleFormatJKS},
+
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	if _, err := c.ensureConfigMapCABundle(context.TODO(), ca); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual *corev1.ConfigMap
+	for _, action := range client.Actions() {
+		if action.Matches("create", "configmaps") {
+			actual = action.(clienttesting.CreateAction).GetObject().(*corev1.ConfigMap)
+		}
+	}
+	if actual == nil {
+		t.Fatalf("expected config map to be created: %v", client.Actions())
+	}
+
+	derCerts, err := x509.ParseCertificates(actual.BinaryData[CABundleDERKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(derCerts) != 1 || !derCerts[0].Equal(ca.Config.Certs[0]) {
+		t.Errorf("unexpected DER bundle: %v", derCerts)
+	}
+
+	jksCerts := decodeJKSTruststore(t, actual.BinaryData[CABundleJKSKey], DefaultTruststorePassword)
+	if len(jksCerts) != 1 || !jksCerts[0].Equal(ca.Config.Certs[0]) {
+		t.Errorf("unexpected JKS truststore: %v", jksCerts)
+	}
+}
+
+func TestSetAdditionalCABundleFormatsPrunesRemovedFormats(t *testing.T) {
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMap := &corev1.ConfigMap{
+		BinaryData: map[string][]byte{
+			CABundleDERKey: []byte("stale"),
+			CABundleJKSKey: []byte("stale"),
+			"other":        []byte("kept"),
+		},
+	}
+	if err := setAdditionalCABundleFormats(configMap, ca.Config.Certs, []CABundleFormat{CABundleFormatDER}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := configMap.BinaryData[CABundleJKSKey]; ok {
+		t.Errorf("expected the JKS truststore of the removed format to be pruned")
+	}
+	if !bytes.Equal(configMap.BinaryData[CABundleDERKey], ca.Config.Certs[0].Raw) {
+		t.Errorf("expected the DER bundle to be updated")
+	}
+	if string(configMap.BinaryData["other"]) != "kept" {
+		t.Errorf("expected unrelated binary data to be kept, got %v", configMap.BinaryData)
+	}
+
+	if err := setAdditionalCABundleFormats(configMap, ca.Config.Certs, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(configMap.BinaryData) != 1 {
+		t.Errorf("expected only unrelated binary data, got %v", configMap.BinaryData)
+	}
+}
+
+// TestJKSTruststoreKeytool checks the truststore interoperates with the JDK: keytool must read the truststore we encode,
+// and our reader must read a truststore produced by keytool. It is skipped when keytool is not installed.
+func TestJKSTruststoreKeytool(t *testing.T) {
+	keytool, err := exec.LookPath("keytool")
+	if err != nil {
+		t.Skip("keytool not found")
+	}
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := ca.Config.Certs[0]
+	dir := t.TempDir()
+	fingerprint := fmt.Sprintf("%X", sha256.Sum256(certificate.Raw))
+
+	encoded, err := encodeJKSTruststore([]*x509.Certificate{certificate}, DefaultTruststorePassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedPath := filepath.Join(dir, "encoded.jks")
+	if err := os.WriteFile(encodedPath, encoded, 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(keytool, "-list", "-rfc", "-storetype", "JKS", "-keystore", encodedPath, "-storepass", DefaultTruststorePassword).CombinedOutput()
+	if err != nil {
+		t.Fatalf("keytool failed to read the truststore: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "ca-0") || !strings.Contains(string(out), "trustedCertEntry") {
+		t.Errorf("expected keytool to list the trusted certificate ca-0, got:\n%s", out)
+	}
+
+	certPath := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	importedPath := filepath.Join(dir, "imported.jks")
+	out, err = exec.Command(keytool, "-importcert", "-noprompt", "-storetype", "JKS", "-alias", "ca", "-file", certPath, "-keystore", importedPath, "-storepass", DefaultTruststorePassword).CombinedOutput()
+	if err != nil {
+		t.Fatalf("keytool failed to create a truststore: %v\n%s", err, out)
+	}
+	imported, err := os.ReadFile(importedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificates := decodeJKSTruststore(t, imported, DefaultTruststorePassword)
+	if len(certificates) != 1 || fmt.Sprintf("%X", sha256.Sum256(certificates[0].Raw)) != fingerprint {
+		t.Errorf("unexpected certificates in the keytool truststore: %v", certificates)
+	}
+}
+
+func TestSetAdditionalCABundleFormatsUnsupported(t *testing.T) {
+	if err := setAdditionalCABundleFormats(&corev1.ConfigMap{}, nil, []CABundleFormat{"P7B"}, ""); err == nil {
+		t.Errorf("expected unsupported format to be rejected")
+	}
+}
+
+// decodeJKSTruststore reads the trusted certificate entries of a Java KeyStore and checks its integrity digest.
+func decodeJKSTruststore(t *testing.T, data []byte, password string) []*x509.Certificate {
+	if len(data) < sha1.Size {
+		t.Fatalf("truststore too short: %d", len(data))
+	}
+	content, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	h := sha1.New()
+	for _, c := range password {
+		h.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), digest) {
+		t.Fatalf("truststore digest mismatch")
+	}
+
+	r := bytes.NewReader(content)
+	read := func(v interface{}) {
+		if err := binary.Read(r, binary.BigEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readUTF := func() string {
+		var length uint16
+		read(&length)
+		s := make([]byte, length)
+		read(s)
+		return string(s)
+	}
+	var magic, version, count uint32
+	read(&magic)
+	read(&version)
+	read(&count)
+	if magic != jksMagic || version != jksVersion {
+		t.Fatalf("unexpected magic %x or version %d", magic, version)
+	}
+	certificates := []*x509.Certificate{}
+	for i := uint32(0); i < count; i++ {
+		var tag uint32
+		var timestamp uint64
+		var length uint32
+		read(&tag)
+		if tag != jksTrustedCertEntryTag {
+			t.Fatalf("unexpected entry tag %d", tag)
+		}
+		readUTF()
+		read(&timestamp)
+		if certType := readUTF(); certType != "X.509" {
+			t.Fatalf("unexpected certificate type %q", certType)
+		}
+		read(&length)
+		raw := make([]byte, length)
+		read(raw)
+		certificate, err := x509.ParseCertificate(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates
+}
@@ -0,0 +1,77 @@
+package certrotation
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// pruneSupersededSigners limits the signers in the ca-bundle.crt of the config map to MaxBundleSize. The current
+// signer, the first certificate, is always kept. The previous signers are kept newest first, signers beyond the limit
+// are removed once they were superseded by their successor for MinimumSignerOverlap. Certificates merged from trust
+// sources are neither counted nor removed.
+func (c CABundleConfigMap) pruneSupersededSigners(caBundleConfigMap *corev1.ConfigMap, certificates []*x509.Certificate) ([]*x509.Certificate, error) {
+	if c.MaxBundleSize <= 0 || len(certificates) <= c.MaxBundleSize {
+		return certificates, nil
+	}
+
+	trustSourceCertificates := map[string]bool{}
+	if annotation, ok := caBundleConfigMap.Annotations[CABundleTrustSourcesAnnotation]; ok {
+		merged := map[string][]string{}
+		// an invalid annotation is reported and replaced by mergeTrustSources
+		_ = json.Unmarshal([]byte(annotation), &merged)
+		for _, fingerprints := range merged {
+			for _, fingerprint := range fingerprints {
+				trustSourceCertificates[fingerprint] = true
+			}
+		}
+	}
+
+	previousSigners := []*x509.Certificate{}
+	for _, certificate := range certificates[1:] {
+		if !trustSourceCertificates[certificateFingerprint(certificate)] {
+			previousSigners = append(previousSigners, certificate)
+		}
+	}
+	sort.SliceStable(previousSigners, func(i, j int) bool {
+		return previousSigners[i].NotBefore.After(previousSigners[j].NotBefore)
+	})
+
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	pruned := map[string]bool{}
+	successor := certificates[0]
+	for i, signer := range previousSigners {
+		supersededFor := now().Sub(successor.NotBefore)
+		if i+1 >= c.MaxBundleSize && supersededFor >= c.MinimumSignerOverlap {
+			klog.V(2).Infof("Removing signer %q superseded for %v from ca-bundle.crt configmap %s/%s of at most %d signers", signer.Subject.CommonName, supersededFor, caBundleConfigMap.Namespace, caBundleConfigMap.Name, c.MaxBundleSize)
+			pruned[certificateFingerprint(signer)] = true
+		}
+		successor = signer
+	}
+	if len(pruned) == 0 {
+		return certificates, nil
+	}
+
+	finalCertificates := []*x509.Certificate{}
+	for _, certificate := range certificates {
+		if !pruned[certificateFingerprint(certificate)] {
+			finalCertificates = append(finalCertificates, certificate)
+		}
+	}
+	caBytes, err := crypto.EncodeCertificates(finalCertificates...)
+	if err != nil {
+		return nil, err
+	}
+	caBundleConfigMap.Data["ca-bundle.crt"] = string(caBytes)
+
+	return finalCertificates, nil
+}
@@ -0,0 +1,118 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestCABundleSignerOverlap(t *testing.T) {
+	signerIssuedAgo := func(name string, ago time.Duration) *x509.Certificate {
+		t.Helper()
+		ca, err := newTestCACertificate(pkix.Name{CommonName: name}, 1, metav1.Duration{Duration: 30 * 24 * time.Hour}, func() time.Time { return time.Now().Add(-ago) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ca.Config.Certs[0]
+	}
+	// previous signers issued 10 days, 2 days and 1 hour ago, and a trust anchor merged from a trust source
+	oldest := signerIssuedAgo("oldest", 10*24*time.Hour)
+	older := signerIssuedAgo("older", 2*24*time.Hour)
+	previous := signerIssuedAgo("previous", time.Hour)
+	enterpriseRoot := signerIssuedAgo("enterprise-root", 20*24*time.Hour)
+	current, err := newTestCACertificate(pkix.Name{CommonName: "current"}, 1, metav1.Duration{Duration: 30 * 24 * time.Hour}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                 string
+		maxBundleSize        int
+		minimumSignerOverlap time.Duration
+		clockOffset          time.Duration
+		expectedSigners      []string
+	}{
+		{
+			name:            "signers are kept until they expire by default",
+			expectedSigners: []string{"current", "previous", "oldest", "older", "enterprise-root"},
+		},
+		{
+			name:            "signers beyond the maximum are removed",
+			maxBundleSize:   2,
+			expectedSigners: []string{"current", "previous", "enterprise-root"},
+		},
+		{
+			name:                 "signers beyond the maximum are kept during the overlap",
+			maxBundleSize:        2,
+			minimumSignerOverlap: 24 * time.Hour,
+			expectedSigners:      []string{"current", "previous", "older", "enterprise-root"},
+		},
+		{
+			name:                 "the overlap is measured with the clock of the controller",
+			maxBundleSize:        2,
+			minimumSignerOverlap: 24 * time.Hour,
+			clockOffset:          48 * time.Hour,
+			expectedSigners:      []string{"current", "previous", "enterprise-root"},
+		},
+		{
+			name:                 "the overlap applies to a bundle of the current signer only",
+			maxBundleSize:        1,
+			minimumSignerOverlap: 30 * time.Minute,
+			expectedSigners:      []string{"current", "previous", "enterprise-root"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			caBundle, err := crypto.EncodeCertificates(previous, oldest, enterpriseRoot, older)
+			if err != nil {
+				t.Fatal(err)
+			}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns",
+					Name:        "trust-bundle",
+					Annotations: map[string]string{CABundleTrustSourcesAnnotation: `{"enterprise":["` + certificateFingerprint(enterpriseRoot) + `"]}`},
+				},
+				Data: map[string]string{"ca-bundle.crt": string(caBundle)},
+			})
+			c := &CABundleConfigMap{
+				Namespace:            "ns",
+				Name:                 "trust-bundle",
+				MaxBundleSize:        tc.maxBundleSize,
+				MinimumSignerOverlap: tc.minimumSignerOverlap,
+				Client:               kubefake.NewSimpleClientset().CoreV1(),
+				Lister:               corev1listers.NewConfigMapLister(indexer),
+				EventRecorder:        events.NewInMemoryRecorder("test"),
+			}
+			c.TrustSources = []CABundleTrustSource{&fakeTrustSource{name: "enterprise", certificates: []*x509.Certificate{enterpriseRoot}}}
+			if tc.clockOffset != 0 {
+				c.now = func() time.Time { return time.Now().Add(tc.clockOffset) }
+			}
+
+			certificates, err := c.ensureConfigMapCABundle(context.TODO(), current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, certificate := range certificates {
+				actual = append(actual, certificate.Subject.CommonName)
+			}
+			if !reflect.DeepEqual(actual, tc.expectedSigners) {
+				t.Errorf("expected %v, got %v", tc.expectedSigners, actual)
+			}
+		})
+	}
+}
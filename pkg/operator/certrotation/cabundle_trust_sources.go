@@ -0,0 +1,370 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/controller/fileobserver"
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// CABundleTrustSourcesAnnotation records which certificates of the CA bundle were merged from which trust source, as
+// JSON object of the source names to the SHA-256 fingerprints of their certificates. It allows removing certificates
+// from the bundle once they are removed from their source.
+const CABundleTrustSourcesAnnotation = "auth.openshift.io/ca-bundle-trust-sources"
+
+const (
+	// defaultFileTrustSourcePollInterval is how often a FileTrustSource checks the file for changes by default.
+	defaultFileTrustSourcePollInterval = 10 * time.Second
+	// defaultURLTrustSourceRefreshInterval is how long a URLTrustSource reuses fetched certificates by default.
+	defaultURLTrustSourceRefreshInterval = 10 * time.Minute
+	// maxURLTrustSourceBytes bounds the size of the bundle fetched by a URLTrustSource.
+	maxURLTrustSourceBytes = 1024 * 1024
+)
+
+// CABundleTrustSource provides additional trust anchors, e.g. enterprise root CAs, merged into the CA bundle
+// maintained by CABundleConfigMap.
+type CABundleTrustSource interface {
+	// Name identifies the source in the CABundleTrustSourcesAnnotation, events and errors. It must be unique within
+	// the trust sources of a CA bundle and stable across restarts.
+	Name() string
+	// Certificates returns the current certificates of the source.
+	Certificates(ctx context.Context) ([]*x509.Certificate, error)
+}
+
+// CABundleTrustSourceWatcher is an optional interface to be implemented by a CABundleTrustSource to merge changes of
+// its certificates right away. Other sources are merged on the next sync of the controller, at the latest on its
+// periodical resync.
+type CABundleTrustSourceWatcher interface {
+	// Watch calls changed whenever the certificates of the source might have changed, until the context is done.
+	Watch(ctx context.Context, changed func())
+}
+
+// mergeTrustSources merges the certificates of the trust sources into the ca-bundle.crt of the config map, after the
+// given certificates of the signers. Certificates merged before are replaced by the current certificates of their
+// source. A source failing to provide its certificates keeps the certificates merged before, its error is returned
+// together with the merged certificates.
+func (c CABundleConfigMap) mergeTrustSources(ctx context.Context, caBundleConfigMap *corev1.ConfigMap, certificates []*x509.Certificate) ([]*x509.Certificate, error) {
+	previousAnnotation, hasPrevious := caBundleConfigMap.Annotations[CABundleTrustSourcesAnnotation]
+	if len(c.TrustSources) == 0 && !hasPrevious {
+		return certificates, nil
+	}
+	previous := map[string][]string{}
+	if hasPrevious {
+		if err := json.Unmarshal([]byte(previousAnnotation), &previous); err != nil {
+			klog.Warningf("Ignoring invalid %s annotation of configmap %s/%s: %v", CABundleTrustSourcesAnnotation, caBundleConfigMap.Namespace, caBundleConfigMap.Name, err)
+			previous = map[string][]string{}
+		}
+	}
+	previouslyMerged := map[string]bool{}
+	for _, fingerprints := range previous {
+		for _, fingerprint := range fingerprints {
+			previouslyMerged[fingerprint] = true
+		}
+	}
+
+	finalCertificates := []*x509.Certificate{}
+	existing := map[string]*x509.Certificate{}
+	for _, certificate := range certificates {
+		fingerprint := certificateFingerprint(certificate)
+		existing[fingerprint] = certificate
+		if !previouslyMerged[fingerprint] {
+			finalCertificates = append(finalCertificates, certificate)
+		}
+	}
+	included := map[string]bool{}
+	for _, certificate := range finalCertificates {
+		included[certificateFingerprint(certificate)] = true
+	}
+
+	merged := map[string][]string{}
+	var errs []error
+	for _, source := range c.TrustSources {
+		sourceCertificates, err := source.Certificates(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trust source %q: %w", source.Name(), err))
+			sourceCertificates = nil
+			for _, fingerprint := range previous[source.Name()] {
+				if certificate, ok := existing[fingerprint]; ok {
+					sourceCertificates = append(sourceCertificates, certificate)
+				}
+			}
+		}
+		fingerprints := []string{}
+		for _, certificate := range crypto.FilterExpiredCerts(sourceCertificates...) {
+			fingerprint := certificateFingerprint(certificate)
+			fingerprints = append(fingerprints, fingerprint)
+			if !included[fingerprint] {
+				included[fingerprint] = true
+				finalCertificates = append(finalCertificates, certificate)
+			}
+		}
+		merged[source.Name()] = fingerprints
+	}
+
+	caBytes, err := crypto.EncodeCertificates(finalCertificates...)
+	if err != nil {
+		return nil, err
+	}
+	mergedAnnotation, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	caBundleConfigMap.Data["ca-bundle.crt"] = string(caBytes)
+	if caBundleConfigMap.Annotations == nil {
+		caBundleConfigMap.Annotations = map[string]string{}
+	}
+	caBundleConfigMap.Annotations[CABundleTrustSourcesAnnotation] = string(mergedAnnotation)
+
+	return finalCertificates, utilerrors.NewAggregate(errs)
+}
+
+// withTrustSources makes the controller built by the factory sync on changes of the trust sources of the CA bundle.
+func (c CABundleConfigMap) withTrustSources(f *factory.Factory) *factory.Factory {
+	for _, source := range c.TrustSources {
+		if configMapSource, ok := source.(*ConfigMapTrustSource); ok {
+			f = f.WithFilteredEventsInformers(configMapSource.eventFilter, configMapSource.Informer.Informer())
+		}
+	}
+	return f.WithPostStartHooks(c.trustSourcesWatcherPostRunHook)
+}
+
+func (c CABundleConfigMap) trustSourcesWatcherPostRunHook(ctx context.Context, syncCtx factory.SyncContext) error {
+	for _, source := range c.TrustSources {
+		watcher, ok := source.(CABundleTrustSourceWatcher)
+		if !ok {
+			continue
+		}
+		go watcher.Watch(ctx, func() {
+			syncCtx.Queue().Add(factory.DefaultQueueKey)
+		})
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// certificateFingerprint returns the hex encoded SHA-256 fingerprint of the certificate.
+func certificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// FileTrustSource reads trust anchors from a PEM file, e.g. mounted from a config map or a host path.
+type FileTrustSource struct {
+	// Path is the path of the PEM file.
+	Path string
+	// PollInterval is how often the file is checked for changes. Defaults to 10 seconds.
+	PollInterval time.Duration
+}
+
+var _ CABundleTrustSourceWatcher = &FileTrustSource{}
+
+func (s *FileTrustSource) Name() string {
+	return "file:" + s.Path
+}
+
+func (s *FileTrustSource) Certificates(_ context.Context) ([]*x509.Certificate, error) {
+	content, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return cert.ParseCertsPEM(content)
+}
+
+// Watch polls the file for changes.
+func (s *FileTrustSource) Watch(ctx context.Context, changed func()) {
+	interval := s.PollInterval
+	if interval == 0 {
+		interval = defaultFileTrustSourcePollInterval
+	}
+	observer, err := fileobserver.NewObserver(interval)
+	if err != nil {
+		klog.Warningf("Unable to watch trust source %q: %v", s.Name(), err)
+		return
+	}
+	startingContent := map[string][]byte{}
+	if content, err := ioutil.ReadFile(s.Path); err == nil {
+		startingContent[s.Path] = content
+	}
+	observer.AddReactor(func(file string, action fileobserver.ActionType) error {
+		klog.V(2).Infof("Trust source %q changed: %s", s.Name(), action.String(file))
+		changed()
+		return nil
+	}, startingContent, s.Path)
+	observer.Run(ctx.Done())
+}
+
+// ConfigMapTrustSource reads trust anchors from a PEM bundle in a config map not managed by the controller.
+type ConfigMapTrustSource struct {
+	// Namespace is the namespace of the config map.
+	Namespace string
+	// ConfigMapName is the name of the config map.
+	ConfigMapName string
+	// Key is the data key of the PEM bundle. Defaults to ca-bundle.crt.
+	Key string
+
+	// Plumbing:
+	Informer corev1informers.ConfigMapInformer
+	Lister   corev1listers.ConfigMapLister
+}
+
+func (s *ConfigMapTrustSource) Name() string {
+	return fmt.Sprintf("configmap:%s/%s", s.Namespace, s.ConfigMapName)
+}
+
+func (s *ConfigMapTrustSource) key() string {
+	if len(s.Key) == 0 {
+		return "ca-bundle.crt"
+	}
+	return s.Key
+}
+
+func (s *ConfigMapTrustSource) Certificates(_ context.Context) ([]*x509.Certificate, error) {
+	configMap, err := s.Lister.ConfigMaps(s.Namespace).Get(s.ConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := configMap.Data[s.key()]
+	if !ok {
+		return nil, fmt.Errorf("configmap/%s -n%s missing %s", s.ConfigMapName, s.Namespace, s.key())
+	}
+	return cert.ParseCertsPEM([]byte(content))
+}
+
+func (s *ConfigMapTrustSource) eventFilter(obj interface{}) bool {
+	return objectFilter(s.Namespace, s.ConfigMapName)(obj)
+}
+
+// URLTrustSource fetches trust anchors as PEM bundle from an HTTPS URL. The server is not verified against the system
+// roots, which usually do not trust the enterprise CA serving the bundle, but by pinning the public key of its serving
+// certificate.
+type URLTrustSource struct {
+	// URL is the HTTPS URL of the PEM bundle.
+	URL string
+	// PinnedPublicKeySHA256 are the base64 encoded SHA-256 digests of the DER encoded subject public key info the
+	// serving certificate of the server must match one of, like the pin-sha256 of HTTP public key pinning, e.g. the
+	// output of "openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64".
+	PinnedPublicKeySHA256 []string
+	// RefreshInterval is how long fetched certificates are reused before fetching them again. Defaults to 10 minutes.
+	RefreshInterval time.Duration
+
+	lock        sync.Mutex
+	fetched     []*x509.Certificate
+	lastFetched time.Time
+	now         func() time.Time
+}
+
+func (s *URLTrustSource) Name() string {
+	return s.URL
+}
+
+func (s *URLTrustSource) Certificates(ctx context.Context) ([]*x509.Certificate, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	refreshInterval := s.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = defaultURLTrustSourceRefreshInterval
+	}
+	if s.fetched != nil && now().Sub(s.lastFetched) < refreshInterval {
+		return s.fetched, nil
+	}
+
+	certificates, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.fetched = certificates
+	s.lastFetched = now()
+	return certificates, nil
+}
+
+func (s *URLTrustSource) fetch(ctx context.Context) ([]*x509.Certificate, error) {
+	parsed, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "https" {
+		return nil, fmt.Errorf("only https URLs are supported")
+	}
+	if len(s.PinnedPublicKeySHA256) == 0 {
+		return nil, fmt.Errorf("no pinned public keys")
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				// the serving certificate is verified by its pinned public key in VerifyPeerCertificate instead
+				InsecureSkipVerify:    true,
+				VerifyPeerCertificate: s.verifyPinnedPublicKey,
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxURLTrustSourceBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxURLTrustSourceBytes {
+		return nil, fmt.Errorf("bundle is larger than %d bytes", maxURLTrustSourceBytes)
+	}
+	return cert.ParseCertsPEM(content)
+}
+
+// verifyPinnedPublicKey accepts the connection only if the serving certificate has one of the pinned public keys. The
+// TLS handshake proves the server holds the private key of the serving certificate, so only the serving certificate
+// is checked, never the other certificates sent by the server.
+func (s *URLTrustSource) verifyPinnedPublicKey(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no serving certificate")
+	}
+	serving, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(serving.RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(sum[:])
+	for _, pinned := range s.PinnedPublicKeySHA256 {
+		if pin == pinned {
+			return nil
+		}
+	}
+	return fmt.Errorf("public key of the serving certificate %q does not match any pinned public key, its pin is %s", serving.Subject.CommonName, pin)
+}
@@ -0,0 +1,241 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type fakeTrustSource struct {
+	name         string
+	certificates []*x509.Certificate
+	err          error
+}
+
+func (s *fakeTrustSource) Name() string {
+	return s.name
+}
+
+func (s *fakeTrustSource) Certificates(_ context.Context) ([]*x509.Certificate, error) {
+	return s.certificates, s.err
+}
+
+func newTestTrustAnchor(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	ca, err := newTestCACertificate(pkix.Name{CommonName: name}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca.Config.Certs[0]
+}
+
+func bundleCommonNames(t *testing.T, configMap *corev1.ConfigMap) []string {
+	t.Helper()
+	certificates, err := cert.ParseCertsPEM([]byte(configMap.Data["ca-bundle.crt"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, certificate := range certificates {
+		names = append(names, certificate.Subject.CommonName)
+	}
+	return names
+}
+
+func TestEnsureConfigMapCABundleTrustSources(t *testing.T) {
+	signer, err := newTestCACertificate(pkix.Name{CommonName: "signer"}, int64(1), metav1.Duration{Duration: time.Hour * 24 * 60}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterprise := &fakeTrustSource{name: "enterprise", certificates: []*x509.Certificate{newTestTrustAnchor(t, "enterprise-root"), newTestTrustAnchor(t, "enterprise-old-root")}}
+	partner := &fakeTrustSource{name: "partner", certificates: []*x509.Certificate{newTestTrustAnchor(t, "partner-root")}}
+
+	client := kubefake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c := &CABundleConfigMap{
+		Namespace:     "ns",
+		Name:          "trust-bundle",
+		TrustSources:  []CABundleTrustSource{enterprise, partner},
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewConfigMapLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	sync := func(expectedCommonNames ...string) {
+		t.Helper()
+		if _, err := c.ensureConfigMapCABundle(context.TODO(), signer); err != nil {
+			t.Fatal(err)
+		}
+		configMap, err := client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "trust-bundle", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.Update(configMap); err != nil {
+			t.Fatal(err)
+		}
+		if actual := strings.Join(bundleCommonNames(t, configMap), ","); actual != strings.Join(expectedCommonNames, ",") {
+			t.Errorf("expected bundle %v, got %v", expectedCommonNames, actual)
+		}
+	}
+
+	sync("signer", "enterprise-root", "enterprise-old-root", "partner-root")
+
+	// a failing source keeps the certificates merged before
+	partner.err = fmt.Errorf("unreachable")
+	sync("signer", "enterprise-root", "enterprise-old-root", "partner-root")
+
+	// certificates removed from their source are removed from the bundle, the signer stays
+	enterprise.certificates = enterprise.certificates[:1]
+	partner.err = nil
+	partner.certificates = nil
+	sync("signer", "enterprise-root")
+
+	// removed sources are removed from the bundle
+	c.TrustSources = nil
+	sync("signer")
+}
+
+func TestFileTrustSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca-bundle.crt")
+	anchor := newTestTrustAnchor(t, "enterprise-root")
+	pemBytes, err := crypto.EncodeCertificates(anchor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &FileTrustSource{Path: path, PollInterval: 10 * time.Millisecond}
+
+	if _, err := source.Certificates(context.TODO()); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go source.Watch(ctx, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	// give the observer time to record the missing file before creating it
+	time.Sleep(100 * time.Millisecond)
+	if err := ioutil.WriteFile(path, pemBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("expected the creation of the file to be observed")
+	}
+
+	certificates, err := source.Certificates(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certificates) != 1 || certificates[0].Subject.CommonName != "enterprise-root" {
+		t.Errorf("unexpected certificates %v", certificates)
+	}
+}
+
+func TestConfigMapTrustSource(t *testing.T) {
+	pemBytes, err := crypto.EncodeCertificates(newTestTrustAnchor(t, "enterprise-root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca-bundle"},
+		Data:       map[string]string{"ca-bundle.crt": string(pemBytes)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	source := &ConfigMapTrustSource{Namespace: "openshift-config", ConfigMapName: "user-ca-bundle", Lister: corev1listers.NewConfigMapLister(indexer)}
+
+	certificates, err := source.Certificates(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certificates) != 1 || certificates[0].Subject.CommonName != "enterprise-root" {
+		t.Errorf("unexpected certificates %v", certificates)
+	}
+
+	if !source.eventFilter(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca-bundle"}}) {
+		t.Error("expected events of the source config map to pass the filter")
+	}
+	if source.eventFilter(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "other"}}) {
+		t.Error("expected events of other config maps to be filtered")
+	}
+
+	source.Key = "missing.crt"
+	if _, err := source.Certificates(context.TODO()); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+func TestURLTrustSource(t *testing.T) {
+	pemBytes, err := crypto.EncodeCertificates(newTestTrustAnchor(t, "enterprise-root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(pemBytes)
+	}))
+	defer server.Close()
+	sum := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(sum[:])
+
+	now := time.Now()
+	source := &URLTrustSource{URL: server.URL, PinnedPublicKeySHA256: []string{pin}, now: func() time.Time { return now }}
+	for i := 0; i < 2; i++ {
+		certificates, err := source.Certificates(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(certificates) != 1 || certificates[0].Subject.CommonName != "enterprise-root" {
+			t.Errorf("unexpected certificates %v", certificates)
+		}
+	}
+	if actual := atomic.LoadInt32(&requests); actual != 1 {
+		t.Errorf("expected the fetched certificates to be reused, got %d requests", actual)
+	}
+	now = now.Add(defaultURLTrustSourceRefreshInterval)
+	if _, err := source.Certificates(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if actual := atomic.LoadInt32(&requests); actual != 2 {
+		t.Errorf("expected the certificates to be fetched again after the refresh interval, got %d requests", actual)
+	}
+
+	unpinned := &URLTrustSource{URL: server.URL, PinnedPublicKeySHA256: []string{base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))}}
+	if _, err := unpinned.Certificates(context.TODO()); err == nil || !strings.Contains(err.Error(), "does not match any pinned public key") {
+		t.Errorf("expected a pinning error, got %v", err)
+	}
+
+	plain := &URLTrustSource{URL: strings.Replace(server.URL, "https://", "http://", 1), PinnedPublicKeySHA256: []string{pin}}
+	if _, err := plain.Certificates(context.TODO()); err == nil {
+		t.Error("expected an error for a plain http URL")
+	}
+}
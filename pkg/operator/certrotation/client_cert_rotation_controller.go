@@ -2,15 +2,18 @@ package certrotation
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/reasons"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -23,6 +26,8 @@ const (
 	CertificateIssuer = "auth.openshift.io/certificate-issuer"
 	// CertificateHostnames contains the hostnames used by a signer.
 	CertificateHostnames = "auth.openshift.io/certificate-hostnames"
+	// CertificateSPIFFEID contains the SPIFFE ID of a workload certificate.
+	CertificateSPIFFEID = "auth.openshift.io/certificate-spiffe-id"
 	// RunOnceContextKey is a context value key that can be used to call the controller Sync() and make it only run the syncWorker once and report error.
 	RunOnceContextKey = "cert-rotation-controller.openshift.io/run-once"
 )
@@ -45,6 +50,32 @@ type CertRotationController struct {
 
 	// Plumbing:
 	OperatorClient v1helpers.StaticPodOperatorClient
+
+	// rotationTimeline, if set, is kept up-to-date with the rotation times of signer and target.
+	rotationTimeline *RotationTimelineConfigMap
+	// operationTimeout, if set, overrides DefaultOperationTimeout.
+	operationTimeout *time.Duration
+	// registry holds the managed resources while the controller runs, DefaultManagedResourceRegistry if not set.
+	registry *ManagedResourceRegistry
+	// orphanedResourceDeletion deletes the objects the controller labeled but does not manage anymore, see
+	// WithOrphanedResourceDeletion.
+	orphanedResourceDeletion bool
+	// controllerLabel is the value of ManagedByControllerLabelName the controller labels its objects with.
+	controllerLabel string
+}
+
+// CertRotationControllerOption configures optional behaviour of the CertRotationController.
+type CertRotationControllerOption func(*CertRotationController)
+
+// WithLiveReadFallback reads the signer, the CA bundle and the target from the server when they are missing in the
+// informer caches, e.g. right after they were created, instead of creating them again. It costs a live GET for
+// every missing object on every sync.
+func WithLiveReadFallback() CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.rotatedSigningCASecret.liveReadFallback = true
+		c.CABundleConfigMap.liveReadFallback = true
+		c.RotatedSelfSignedCertKeySecret.liveReadFallback = true
+	}
 }
 
 func NewCertRotationController(
@@ -54,6 +85,7 @@ func NewCertRotationController(
 	rotatedSelfSignedCertKeySecret RotatedSelfSignedCertKeySecret,
 	operatorClient v1helpers.StaticPodOperatorClient,
 	recorder events.Recorder,
+	opts ...CertRotationControllerOption,
 ) factory.Controller {
 	c := &CertRotationController{
 		name:                           name,
@@ -62,22 +94,41 @@ func NewCertRotationController(
 		RotatedSelfSignedCertKeySecret: rotatedSelfSignedCertKeySecret,
 		OperatorClient:                 operatorClient,
 	}
-	return factory.New().
+	for _, opt := range opts {
+		opt(c)
+	}
+	registry := c.registry
+	if registry == nil {
+		registry = DefaultManagedResourceRegistry
+	}
+	f := factory.New().
 		ResyncEvery(time.Minute).
 		WithSync(c.Sync).
-		WithInformers(
-			rotatedSigningCASecret.Informer.Informer(),
-			caBundleConfigMap.Informer.Informer(),
-			rotatedSelfSignedCertKeySecret.Informer.Informer(),
-		).
 		WithPostStartHooks(
 			c.targetCertRecheckerPostRunHook,
-		).
+			registry.registerWhileRunning(c.managedResources()),
+		)
+	f = withResourceInformers(f, rotatedSigningCASecret, caBundleConfigMap, rotatedSelfSignedCertKeySecret)
+	return c.CABundleConfigMap.withTrustSources(f).
 		ToController("CertRotationController", recorder.WithComponentSuffix("cert-rotation-controller"))
 }
 
 func (c CertRotationController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
-	syncErr := c.syncWorker(ctx)
+	paused, err := c.rotatedSigningCASecret.rotationPaused(ctx)
+	if err != nil {
+		return reportRotationStatus(ctx, syncCtx, c.name, c.OperatorClient, err)
+	}
+	if paused {
+		return reportRotationPaused(ctx, syncCtx, c.name, c.OperatorClient, c.rotatedSigningCASecret)
+	}
+	conditions, syncErr := c.syncWorker(ctx)
+	return reportRotationStatus(ctx, syncCtx, c.name, c.OperatorClient, syncErr, conditions...)
+}
+
+// reportRotationStatus sets the CertRotationDegraded condition of the named controller according to the sync error,
+// together with the conditions of the signer, the CA bundle and the targets reported by the sync.
+func reportRotationStatus(ctx context.Context, syncCtx factory.SyncContext, name string, operatorClient v1helpers.StaticPodOperatorClient, syncErr error, conditions ...operatorv1.OperatorCondition) error {
+	metrics.observeSync(name, syncErr)
 
 	// running this function with RunOnceContextKey value context will make this "run-once" without updating status.
 	isRunOnce, ok := ctx.Value(RunOnceContextKey).(bool)
@@ -86,61 +137,90 @@ func (c CertRotationController) Sync(ctx context.Context, syncCtx factory.SyncCo
 	}
 
 	newCondition := operatorv1.OperatorCondition{
-		Type:   fmt.Sprintf(condition.CertRotationDegradedConditionTypeFmt, c.name),
+		Type:   fmt.Sprintf(condition.CertRotationDegradedConditionTypeFmt, name),
 		Status: operatorv1.ConditionFalse,
 	}
 	if syncErr != nil {
 		newCondition.Status = operatorv1.ConditionTrue
-		newCondition.Reason = "RotationError"
+		newCondition.Reason = string(reasons.RotationError)
 		newCondition.Message = syncErr.Error()
 	}
-	_, updated, updateErr := v1helpers.UpdateStaticPodStatus(ctx, c.OperatorClient, v1helpers.UpdateStaticPodConditionFn(newCondition))
+	updateFuncs := []v1helpers.UpdateStaticPodStatusFunc{v1helpers.UpdateStaticPodConditionFn(newCondition)}
+	for _, resourceCondition := range conditions {
+		updateFuncs = append(updateFuncs, v1helpers.UpdateStaticPodConditionFn(resourceCondition))
+	}
+	_, updated, updateErr := v1helpers.UpdateStaticPodStatus(ctx, operatorClient, updateFuncs...)
 	if updateErr != nil {
 		return updateErr
 	}
 	if updated && syncErr != nil {
-		syncCtx.Recorder().Warningf("RotationError", newCondition.Message)
+		syncCtx.Recorder().Warningf(string(reasons.RotationError), newCondition.Message)
 	}
 
 	return syncErr
 }
 
-func (c CertRotationController) syncWorker(ctx context.Context) error {
-	signingCertKeyPair, err := c.rotatedSigningCASecret.ensureSigningCertKeyPair(ctx)
-	if err != nil {
-		return err
+// syncWorker rotates the signer, the CA bundle and the target. It returns their conditions, the conditions of the
+// resources after a failed step are unknown.
+func (c CertRotationController) syncWorker(ctx context.Context) ([]operatorv1.OperatorCondition, error) {
+	timeout := DefaultOperationTimeout
+	if c.operationTimeout != nil {
+		timeout = *c.operationTimeout
 	}
 
-	cabundleCerts, err := c.CABundleConfigMap.ensureConfigMapCABundle(ctx, signingCertKeyPair)
+	var signingCertKeyPair *crypto.CA
+	err := runStep(ctx, timeout, c.rotatedSigningCASecret.stepName(), func(ctx context.Context) (err error) {
+		signingCertKeyPair, err = c.rotatedSigningCASecret.ensureSigningCertKeyPair(ctx)
+		return err
+	})
+	conditions := []operatorv1.OperatorCondition{signerValidCondition(c.name, c.rotatedSigningCASecret, signingCertKeyPair, err)}
 	if err != nil {
+		return append(conditions,
+			notSyncedCondition(fmt.Sprintf(condition.CertRotationCABundleValidConditionTypeFmt, c.name), c.CABundleConfigMap.stepName()),
+			notSyncedCondition(fmt.Sprintf(condition.CertRotationTargetCertValidConditionTypeFmt, c.name, c.RotatedSelfSignedCertKeySecret.Name), c.RotatedSelfSignedCertKeySecret.stepName()),
+		), err
+	}
+
+	var cabundleCerts []*x509.Certificate
+	err = runStep(ctx, timeout, c.CABundleConfigMap.stepName(), func(ctx context.Context) (err error) {
+		cabundleCerts, err = c.CABundleConfigMap.ensureConfigMapCABundle(ctx, signingCertKeyPair)
 		return err
+	})
+	conditions = append(conditions, caBundleValidCondition(c.name, c.CABundleConfigMap, cabundleCerts, err))
+	if err != nil {
+		return append(conditions,
+			notSyncedCondition(fmt.Sprintf(condition.CertRotationTargetCertValidConditionTypeFmt, c.name, c.RotatedSelfSignedCertKeySecret.Name), c.RotatedSelfSignedCertKeySecret.stepName()),
+		), err
 	}
 
-	if err := c.RotatedSelfSignedCertKeySecret.ensureTargetCertKeyPair(ctx, signingCertKeyPair, cabundleCerts); err != nil {
+	var targetSecret *corev1.Secret
+	err = runStep(ctx, timeout, c.RotatedSelfSignedCertKeySecret.stepName(), func(ctx context.Context) (err error) {
+		targetSecret, err = c.RotatedSelfSignedCertKeySecret.ensureTargetCertKeyPair(ctx, signingCertKeyPair, cabundleCerts)
 		return err
+	})
+	conditions = append(conditions, targetCertValidCondition(c.name, c.RotatedSelfSignedCertKeySecret, targetSecret, err))
+	if err != nil {
+		return conditions, err
 	}
 
-	return nil
-}
+	if c.rotationTimeline != nil {
+		if err := runStep(ctx, timeout, c.rotationTimeline.stepName(), func(ctx context.Context) error {
+			return c.rotationTimeline.ensureRotationTimeline(ctx, c.rotatedSigningCASecret, signingCertKeyPair, c.RotatedSelfSignedCertKeySecret, targetSecret)
+		}); err != nil {
+			return conditions, err
+		}
+	}
 
-func (c CertRotationController) targetCertRecheckerPostRunHook(ctx context.Context, syncCtx factory.SyncContext) error {
-	// If we have a need to force rechecking the cert, use this channel to do it.
-	refresher, ok := c.RotatedSelfSignedCertKeySecret.CertCreator.(TargetCertRechecker)
-	if !ok {
-		return nil
-	}
-	targetRefresh := refresher.RecheckChannel()
-	go wait.Until(func() {
-		for {
-			select {
-			case <-targetRefresh:
-				syncCtx.Queue().Add(factory.DefaultQueueKey)
-			case <-ctx.Done():
-				return
-			}
+	if c.orphanedResourceDeletion {
+		if err := runStep(ctx, timeout, c.orphanedResourcesStepName(), c.deleteOrphanedResources); err != nil {
+			return conditions, err
 		}
-	}, time.Minute, ctx.Done())
+	}
 
-	<-ctx.Done()
-	return nil
+	return conditions, nil
+}
+
+// targetCertRecheckerPostRunHook syncs when the configuration of the signer or the target might have changed.
+func (c CertRotationController) targetCertRecheckerPostRunHook(ctx context.Context, syncCtx factory.SyncContext) error {
+	return watchRecheckChannels(ctx, syncCtx, c.rotatedSigningCASecret.ConfigChanged, recheckChannel(c.RotatedSelfSignedCertKeySecret.CertCreator))
 }
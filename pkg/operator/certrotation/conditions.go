@@ -0,0 +1,73 @@
+package certrotation
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/reasons"
+)
+
+// signerValidCondition returns the SignerValid condition of the named controller after ensuring the signer.
+func signerValidCondition(name string, signer RotatedSigningCASecret, signingCertKeyPair *crypto.CA, err error) operatorv1.OperatorCondition {
+	conditionType := fmt.Sprintf(condition.CertRotationSignerValidConditionTypeFmt, name)
+	if err != nil {
+		return reasons.NewCondition(conditionType, operatorv1.ConditionFalse, reasons.RotationError,
+			"Secret %s/%s failed to rotate%s: %v", signer.Namespace, signer.Name, currentExpiry(signer.Lister, signer.Namespace, signer.Name), err)
+	}
+	return reasons.NewCondition(conditionType, operatorv1.ConditionTrue, reasons.AsExpected,
+		"Secret %s/%s is valid until %s.", signer.Namespace, signer.Name, signingCertKeyPair.Config.Certs[0].NotAfter.UTC().Format(time.RFC3339))
+}
+
+// caBundleValidCondition returns the CABundleValid condition of the named controller after ensuring the CA bundle.
+func caBundleValidCondition(name string, caBundle CABundleConfigMap, certificates []*x509.Certificate, err error) operatorv1.OperatorCondition {
+	conditionType := fmt.Sprintf(condition.CertRotationCABundleValidConditionTypeFmt, name)
+	if err != nil {
+		return reasons.NewCondition(conditionType, operatorv1.ConditionFalse, reasons.RotationError,
+			"ConfigMap %s/%s failed to update: %v", caBundle.Namespace, caBundle.Name, err)
+	}
+	var firstNotAfter time.Time
+	for _, certificate := range certificates {
+		if firstNotAfter.IsZero() || certificate.NotAfter.Before(firstNotAfter) {
+			firstNotAfter = certificate.NotAfter
+		}
+	}
+	return reasons.NewCondition(conditionType, operatorv1.ConditionTrue, reasons.AsExpected,
+		"ConfigMap %s/%s trusts %d certificates, the first expiring at %s.", caBundle.Namespace, caBundle.Name, len(certificates), firstNotAfter.UTC().Format(time.RFC3339))
+}
+
+// targetCertValidCondition returns the TargetCertValid condition of the target of the named controller after ensuring
+// the target.
+func targetCertValidCondition(name string, target RotatedSelfSignedCertKeySecret, secret *corev1.Secret, err error) operatorv1.OperatorCondition {
+	conditionType := fmt.Sprintf(condition.CertRotationTargetCertValidConditionTypeFmt, name, target.Name)
+	if err != nil {
+		return reasons.NewCondition(conditionType, operatorv1.ConditionFalse, reasons.RotationError,
+			"Secret %s/%s failed to rotate%s: %v", target.Namespace, target.Name, currentExpiry(target.Lister, target.Namespace, target.Name), err)
+	}
+	return reasons.NewCondition(conditionType, operatorv1.ConditionTrue, reasons.AsExpected,
+		"Secret %s/%s is valid until %s.", target.Namespace, target.Name, secret.Annotations[CertificateNotAfterAnnotation])
+}
+
+// notSyncedCondition returns the condition of a resource that was not synced because a previous step failed.
+func notSyncedCondition(conditionType, step string) operatorv1.OperatorCondition {
+	return reasons.NewCondition(conditionType, operatorv1.ConditionUnknown, reasons.PreconditionNotReady,
+		"Not synced because a previous step failed before %s.", step)
+}
+
+// currentExpiry describes the expiry of the certificate currently stored in the secret, if known.
+func currentExpiry(lister corev1listers.SecretLister, namespace, name string) string {
+	if lister == nil {
+		return ""
+	}
+	secret, err := lister.Secrets(namespace).Get(name)
+	if err != nil || len(secret.Annotations[CertificateNotAfterAnnotation]) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", its current certificate expires at %s", secret.Annotations[CertificateNotAfterAnnotation])
+}
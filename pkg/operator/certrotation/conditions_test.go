@@ -0,0 +1,118 @@
+package certrotation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var expiryRegexp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z`)
+
+func TestRotationConditions(t *testing.T) {
+	tests := []struct {
+		name           string
+		failingSecret  string
+		expectedStatus map[string]operatorv1.ConditionStatus
+		expectedReason map[string]string
+	}{
+		{
+			name: "all valid",
+			expectedStatus: map[string]operatorv1.ConditionStatus{
+				"CertRotation_Test_SignerValid":            operatorv1.ConditionTrue,
+				"CertRotation_Test_CABundleValid":          operatorv1.ConditionTrue,
+				"CertRotation_Test_target_TargetCertValid": operatorv1.ConditionTrue,
+			},
+			expectedReason: map[string]string{"CertRotation_Test_target_TargetCertValid": "AsExpected"},
+		},
+		{
+			name:          "failing target",
+			failingSecret: "target",
+			expectedStatus: map[string]operatorv1.ConditionStatus{
+				"CertRotation_Test_SignerValid":            operatorv1.ConditionTrue,
+				"CertRotation_Test_CABundleValid":          operatorv1.ConditionTrue,
+				"CertRotation_Test_target_TargetCertValid": operatorv1.ConditionFalse,
+			},
+			expectedReason: map[string]string{"CertRotation_Test_target_TargetCertValid": "RotationError"},
+		},
+		{
+			name:          "failing signer",
+			failingSecret: "signer",
+			expectedStatus: map[string]operatorv1.ConditionStatus{
+				"CertRotation_Test_SignerValid":            operatorv1.ConditionFalse,
+				"CertRotation_Test_CABundleValid":          operatorv1.ConditionUnknown,
+				"CertRotation_Test_target_TargetCertValid": operatorv1.ConditionUnknown,
+			},
+			expectedReason: map[string]string{"CertRotation_Test_SignerValid": "RotationError", "CertRotation_Test_CABundleValid": "PreconditionNotReady"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset()
+			client.PrependReactor("create", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.(clienttesting.CreateAction).GetObject().(*corev1.Secret).Name == tc.failingSecret {
+					return true, nil, errors.New("injected")
+				}
+				return false, nil, nil
+			})
+			secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			recorder := events.NewInMemoryRecorder("test")
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+			c := CertRotationController{
+				name: "Test",
+				rotatedSigningCASecret: RotatedSigningCASecret{
+					Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+					Lister: corev1listers.NewSecretLister(secrets), Client: client.CoreV1(), EventRecorder: recorder,
+				},
+				CABundleConfigMap: CABundleConfigMap{
+					Namespace: "ns", Name: "ca-bundle",
+					Lister: corev1listers.NewConfigMapLister(configMaps), Client: client.CoreV1(), EventRecorder: recorder,
+				},
+				RotatedSelfSignedCertKeySecret: RotatedSelfSignedCertKeySecret{
+					Namespace: "ns", Name: "target", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+					CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+					Lister:        corev1listers.NewSecretLister(secrets),
+					Client:        client.CoreV1(),
+					EventRecorder: recorder,
+				},
+				OperatorClient: operatorClient,
+			}
+
+			err := c.Sync(context.TODO(), factory.NewSyncContext("test", recorder))
+			if (err != nil) != (len(tc.failingSecret) > 0) {
+				t.Fatalf("unexpected sync error %v", err)
+			}
+			_, status, _, _ := operatorClient.GetStaticPodOperatorState()
+			for conditionType, expectedStatus := range tc.expectedStatus {
+				condition := v1helpers.FindOperatorCondition(status.Conditions, conditionType)
+				if condition == nil {
+					t.Errorf("expected condition %s, got %v", conditionType, status.Conditions)
+					continue
+				}
+				if condition.Status != expectedStatus {
+					t.Errorf("expected %s to be %s, got %s: %s", conditionType, expectedStatus, condition.Status, condition.Message)
+				}
+				if expectedReason, ok := tc.expectedReason[conditionType]; ok && condition.Reason != expectedReason {
+					t.Errorf("expected %s with reason %s, got %s", conditionType, expectedReason, condition.Reason)
+				}
+				if condition.Status == operatorv1.ConditionTrue && !expiryRegexp.MatchString(condition.Message) {
+					t.Errorf("expected %s to give the expiry, got %q", conditionType, condition.Message)
+				}
+			}
+		})
+	}
+}
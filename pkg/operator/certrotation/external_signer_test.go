@@ -0,0 +1,112 @@
+package certrotation
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// opaqueSigner hides the private key like an HSM does.
+type opaqueSigner struct {
+	gocrypto.Signer
+}
+
+func newOpaqueSigner(t *testing.T) gocrypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opaqueSigner{Signer: key}
+}
+
+func TestEnsureSigningCertKeyPairExternalSigner(t *testing.T) {
+	signer := newOpaqueSigner(t)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	c := &RotatedSigningCASecret{
+		Namespace:     "ns",
+		Name:          "signer",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		Signer:        signer,
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+
+	ca, err := c.ensureSigningCertKeyPair(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := client.Actions()
+	if len(actions) != 2 || !actions[1].Matches("create", "secrets") {
+		t.Fatalf("expected get and create, got %v", actions)
+	}
+	secret := actions[1].(clienttesting.CreateAction).GetObject().(*corev1.Secret)
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected type %q, got %q", corev1.SecretTypeTLS, secret.Type)
+	}
+	if key, ok := secret.Data["tls.key"]; !ok || len(key) > 0 || len(secret.Data["tls.crt"]) == 0 {
+		t.Errorf("expected only the certificate and an empty key, got %v", secret.Data)
+	}
+
+	// targets are signed by the external signer
+	clientCert, err := ca.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "foo"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Config.Certs[0])
+	if _, err := clientCert.Certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("expected the client certificate to verify: %v", err)
+	}
+
+	// nothing to do for a fresh certificate of the signer
+	indexer.Add(secret)
+	client.ClearActions()
+	if _, err := c.ensureSigningCertKeyPair(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("expected no actions, got %v", client.Actions())
+	}
+
+	// a new key in the HSM re-issues the certificate
+	c.Signer = newOpaqueSigner(t)
+	ca, err = c.ensureSigningCertKeyPair(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.SignerMatchesCertificate(c.Signer, ca.Config.Certs[0]) {
+		t.Errorf("expected a certificate for the new signer")
+	}
+
+	// an existing signer with a private key keeps its immutable type when moved to an external signer
+	existing := secret.DeepCopy()
+	existing.Data["tls.key"] = []byte("key")
+	indexer.Update(existing)
+	c.Signer = newOpaqueSigner(t)
+	client.ClearActions()
+	if _, err := c.ensureSigningCertKeyPair(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	updated := lastWrittenSecret(client)
+	if updated == nil || updated.Type != corev1.SecretTypeTLS || len(updated.Data["tls.key"]) > 0 {
+		t.Errorf("expected an update of the %s secret without the key, got %v", corev1.SecretTypeTLS, client.Actions())
+	}
+}
@@ -0,0 +1,105 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ExternalSinksAnnotation records the certificate last stored in each external sink of a target, as JSON object of
+// sink names to certificate hashes. Sinks whose hash does not match the current certificate are stored again.
+const ExternalSinksAnnotation = "certrotation.openshift.io/external-sinks"
+
+// ExternalSecretSink mirrors the cert/key pair of a target off-cluster, e.g. for disaster recovery. See
+// RotatedSelfSignedCertKeySecret.ExternalSinks, the externalsinks package implements it for Vault and AWS Secrets
+// Manager.
+type ExternalSecretSink interface {
+	// Name identifies the sink in ExternalSinksAnnotation, events and errors. It must be unique among the sinks of
+	// a target and stable, a renamed sink stores the current cert/key pair again.
+	Name() string
+	// Store writes the cert/key pair, replacing the previous one of the target. It is retried on the next sync
+	// when it fails, so it must be idempotent.
+	Store(ctx context.Context, certKeyPair ExternalCertKeyPair) error
+}
+
+// ExternalCertKeyPair is the cert/key pair of a target as stored in an ExternalSecretSink.
+type ExternalCertKeyPair struct {
+	// Namespace and Name are the target secret the pair is stored in.
+	Namespace string
+	Name      string
+	// Certificate is the PEM encoded certificate chain, the target certificate first.
+	Certificate []byte
+	// PrivateKey is the PEM encoded private key.
+	PrivateKey []byte
+}
+
+// storeInExternalSinks stores the cert/key pair of the secret in the external sinks which do not have it yet, and
+// records them in ExternalSinksAnnotation. It returns whether the secret changed. Sinks that fail are stored again on
+// the next call, their errors are returned after the others were stored.
+func (c RotatedSelfSignedCertKeySecret) storeInExternalSinks(ctx context.Context, secret *corev1.Secret, keys secretDataKeys) (bool, error) {
+	previous, hasPrevious := secret.Annotations[ExternalSinksAnnotation]
+	if len(c.ExternalSinks) == 0 {
+		if !hasPrevious {
+			return false, nil
+		}
+		delete(secret.Annotations, ExternalSinksAnnotation)
+		// a trailing dash removes the annotation from the existing secret, see resourcemerge.MergeMap
+		secret.Annotations[ExternalSinksAnnotation+"-"] = ""
+		return true, nil
+	}
+
+	stored := map[string]string{}
+	if hasPrevious {
+		// a corrupted annotation stores the cert/key pair in all sinks again
+		_ = json.Unmarshal([]byte(previous), &stored)
+	}
+	hash := hexSHA256(secret.Data[keys.cert])
+	certKeyPair := ExternalCertKeyPair{
+		Namespace:   c.Namespace,
+		Name:        c.Name,
+		Certificate: secret.Data[keys.cert],
+		PrivateKey:  secret.Data[keys.privateKey],
+	}
+
+	current := map[string]string{}
+	var errs []error
+	for _, sink := range c.ExternalSinks {
+		name := sink.Name()
+		if stored[name] == hash {
+			current[name] = hash
+			continue
+		}
+		if err := sink.Store(ctx, certKeyPair); err != nil {
+			errs = append(errs, fmt.Errorf("failed to store %s/%s in external sink %q: %w", c.Namespace, c.Name, name, err))
+			if previousHash, ok := stored[name]; ok {
+				current[name] = previousHash
+			}
+			continue
+		}
+		c.EventRecorder.Eventf("TargetStoredInExternalSink", "Stored the cert/key pair of %q in %q in external sink %q", c.Name, c.Namespace, name)
+		current[name] = hash
+	}
+
+	annotation, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	if hasPrevious && previous == string(annotation) {
+		return false, utilerrors.NewAggregate(errs)
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[ExternalSinksAnnotation] = string(annotation)
+	return true, utilerrors.NewAggregate(errs)
+}
+
+func hexSHA256(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
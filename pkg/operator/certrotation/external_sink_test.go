@@ -0,0 +1,101 @@
+package certrotation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type fakeExternalSink struct {
+	name   string
+	err    error
+	stored []ExternalCertKeyPair
+}
+
+func (s *fakeExternalSink) Name() string {
+	return s.name
+}
+
+func (s *fakeExternalSink) Store(_ context.Context, certKeyPair ExternalCertKeyPair) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.stored = append(s.stored, certKeyPair)
+	return nil
+}
+
+func TestExternalSinks(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	healthy, failing := &fakeExternalSink{name: "healthy"}, &fakeExternalSink{name: "failing", err: errors.New("unavailable")}
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace:     "ns",
+		Name:          "serving",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+		ExternalSinks: []ExternalSecretSink{healthy, failing},
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	ensure := func() (bool, error) {
+		t.Helper()
+		client.ClearActions()
+		_, err := target.ensureTargetCertKeyPair(context.TODO(), signer, signer.Config.Certs)
+		written := lastWrittenSecret(client)
+		if written != nil {
+			indexer.Add(written)
+		}
+		return written != nil, err
+	}
+
+	// the rotated secret is written even if a sink fails
+	written, err := ensure()
+	if !written || err == nil || !strings.Contains(err.Error(), `external sink "failing"`) {
+		t.Fatalf("expected the secret to be written and an error about the failing sink, got %v, %v", written, err)
+	}
+	if len(healthy.stored) != 1 {
+		t.Fatalf("expected the cert/key pair to be stored in the healthy sink, got %d", len(healthy.stored))
+	}
+	secret, _ := target.Lister.Secrets("ns").Get("serving")
+	if stored := healthy.stored[0]; stored.Namespace != "ns" || stored.Name != "serving" || string(stored.Certificate) != string(secret.Data["tls.crt"]) || string(stored.PrivateKey) != string(secret.Data["tls.key"]) {
+		t.Errorf("unexpected stored cert/key pair %s/%s", stored.Namespace, stored.Name)
+	}
+
+	// only the failed sink is retried
+	failing.err = nil
+	if written, err := ensure(); !written || err != nil {
+		t.Fatalf("expected the annotation to be updated, got %v, %v", written, err)
+	}
+	if len(healthy.stored) != 1 || len(failing.stored) != 1 {
+		t.Errorf("expected one store per sink, got %d and %d", len(healthy.stored), len(failing.stored))
+	}
+	if written, err := ensure(); written || err != nil {
+		t.Errorf("expected no update, got %v, %v", written, err)
+	}
+
+	// removing the sinks removes the annotation
+	target.ExternalSinks = nil
+	if written, err := ensure(); !written || err != nil {
+		t.Fatalf("expected the annotation to be removed, got %v, %v", written, err)
+	}
+	secret, _ = target.Lister.Secrets("ns").Get("serving")
+	if _, ok := secret.Annotations[ExternalSinksAnnotation]; ok {
+		t.Errorf("expected no %s annotation, got %v", ExternalSinksAnnotation, secret.Annotations)
+	}
+}
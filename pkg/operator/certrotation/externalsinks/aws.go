@@ -0,0 +1,229 @@
+package externalsinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+// AWSSecretsManagerSink stores the cert/key pairs in AWS Secrets Manager, as JSON object with the keys "tls.crt" and
+// "tls.key" in the secret <SecretNamePrefix><namespace>/<name>. The secret is created if it does not exist, every
+// rotation adds a new version.
+type AWSSecretsManagerSink struct {
+	// Region is the AWS region of the secrets, e.g. us-east-1.
+	Region string
+	// SecretNamePrefix is prepended to the names of the secrets, e.g. "openshift/<cluster>/".
+	SecretNamePrefix string
+	// Credentials returns the credentials to sign the requests with. It is called for every request, so that
+	// temporary credentials can be refreshed.
+	Credentials func(ctx context.Context) (AWSCredentials, error)
+	// Endpoint, if set, overrides the regional endpoint https://secretsmanager.<region>.amazonaws.com, e.g. for a
+	// VPC endpoint. It must be an https URL.
+	Endpoint string
+	// HTTPClient, if set, is used instead of http.DefaultClient.
+	HTTPClient *http.Client
+
+	// now returns the signing time, time.Now if not set.
+	now func() time.Time
+}
+
+// AWSCredentials are the credentials of an IAM user or role.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials only.
+	SessionToken string
+}
+
+var _ certrotation.ExternalSecretSink = &AWSSecretsManagerSink{}
+
+// Name includes the endpoint and the secret name prefix, so that sinks storing to different secrets do not collide.
+func (s *AWSSecretsManagerSink) Name() string {
+	return fmt.Sprintf("aws-secrets-manager:%s/%s", s.endpoint(), s.SecretNamePrefix)
+}
+
+func (s *AWSSecretsManagerSink) Store(ctx context.Context, certKeyPair certrotation.ExternalCertKeyPair) error {
+	secretString, err := json.Marshal(map[string]string{
+		"tls.crt": string(certKeyPair.Certificate),
+		"tls.key": string(certKeyPair.PrivateKey),
+	})
+	if err != nil {
+		return err
+	}
+	secretName := s.SecretNamePrefix + certKeyPair.Namespace + "/" + certKeyPair.Name
+	// the request token identifies the version, storing the same certificate again is a no-op
+	requestToken := hexSHA256(certKeyPair.Certificate)
+
+	err = s.call(ctx, "PutSecretValue", map[string]string{
+		"SecretId":           secretName,
+		"SecretString":       string(secretString),
+		"ClientRequestToken": requestToken,
+	})
+	if awsErr, ok := err.(*awsError); ok && awsErr.is("ResourceNotFoundException") {
+		err = s.call(ctx, "CreateSecret", map[string]string{
+			"Name":               secretName,
+			"SecretString":       string(secretString),
+			"ClientRequestToken": requestToken,
+		})
+	}
+	return err
+}
+
+// call invokes the Secrets Manager action with the input as JSON body.
+func (s *AWSSecretsManagerSink) call(ctx context.Context, action string, input interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(s.endpoint())
+	if err != nil {
+		return fmt.Errorf("invalid AWS Secrets Manager endpoint %q: %w", s.endpoint(), err)
+	}
+	// the secret value is sent in the body, never in plain text
+	if endpoint.Scheme != "https" {
+		return fmt.Errorf("invalid AWS Secrets Manager endpoint %q: not an https URL", s.endpoint())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint.String(), "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+
+	credentials, err := s.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the AWS credentials: %w", err)
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	signAWSRequestV4(req, body, credentials, s.Region, "secretsmanager", now())
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	ret := &awsError{action: action, status: resp.Status}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = json.Unmarshal(respBody, ret)
+	return ret
+}
+
+func (s *AWSSecretsManagerSink) endpoint() string {
+	if len(s.Endpoint) > 0 {
+		return s.Endpoint
+	}
+	return fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", s.Region)
+}
+
+// awsError is the error response of an AWS JSON protocol API.
+type awsError struct {
+	action string
+	status string
+
+	Type        string `json:"__type"`
+	Message     string `json:"message"`
+	MessageCaps string `json:"Message"`
+}
+
+// is returns whether the error is of the type, with or without the namespace of the service.
+func (e *awsError) is(errorType string) bool {
+	return e.Type == errorType || strings.HasSuffix(e.Type, "#"+errorType)
+}
+
+func (e *awsError) Error() string {
+	message := e.Message
+	if len(message) == 0 {
+		message = e.MessageCaps
+	}
+	return fmt.Sprintf("%s failed with status %s: %s %s", e.action, e.status, e.Type, message)
+}
+
+// signAWSRequestV4 adds the AWS Signature Version 4 authorization to the request, signing all its headers.
+func signAWSRequestV4(req *http.Request, body []byte, credentials AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if len(credentials.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+	req.Header.Del("Authorization")
+
+	host := req.Host
+	if len(host) == 0 {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, 0, len(values))
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalPath := req.URL.EscapedPath()
+	if len(canonicalPath) == 0 {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
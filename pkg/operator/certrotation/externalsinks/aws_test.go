@@ -0,0 +1,101 @@
+package externalsinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+func TestAWSSecretsManagerSink(t *testing.T) {
+	var actions []string
+	var inputs []map[string]string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Error(err)
+		}
+		actions, inputs = append(actions, r.Header.Get("X-Amz-Target")), append(inputs, input)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20240102/eu-west-1/secretsmanager/aws4_request, ") {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("expected the session token, got %q", r.Header.Get("X-Amz-Security-Token"))
+		}
+		if r.Header.Get("X-Amz-Target") == "secretsmanager.PutSecretValue" && len(actions) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	sink := &AWSSecretsManagerSink{
+		Region:           "eu-west-1",
+		SecretNamePrefix: "cluster/",
+		Endpoint:         server.URL,
+		Credentials: func(context.Context) (AWSCredentials, error) {
+			return AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, nil
+		},
+		HTTPClient: server.Client(),
+		now:        func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	certKeyPair := certrotation.ExternalCertKeyPair{Namespace: "ns", Name: "serving", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+
+	// a missing secret is created
+	if err := sink.Store(context.TODO(), certKeyPair); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Store(context.TODO(), certKeyPair); err != nil {
+		t.Fatal(err)
+	}
+	expectedActions := []string{"secretsmanager.PutSecretValue", "secretsmanager.CreateSecret", "secretsmanager.PutSecretValue"}
+	if strings.Join(actions, ",") != strings.Join(expectedActions, ",") {
+		t.Fatalf("expected actions %v, got %v", expectedActions, actions)
+	}
+	if inputs[1]["Name"] != "cluster/ns/serving" || inputs[2]["SecretId"] != "cluster/ns/serving" {
+		t.Errorf("unexpected secret names in %v", inputs)
+	}
+	if inputs[1]["ClientRequestToken"] != inputs[2]["ClientRequestToken"] {
+		t.Errorf("expected the same request token for the same certificate, got %v", inputs)
+	}
+	if sink.Name() == (&AWSSecretsManagerSink{Region: "eu-west-1", SecretNamePrefix: "other/", Endpoint: server.URL}).Name() {
+		t.Errorf("expected sinks with different secret name prefixes to have different names, got %q", sink.Name())
+	}
+
+	// the private key is never sent in plain text
+	sink.Endpoint = strings.Replace(server.URL, "https://", "http://", 1)
+	if err := sink.Store(context.TODO(), certKeyPair); err == nil || !strings.Contains(err.Error(), "not an https URL") {
+		t.Errorf("expected an http endpoint to be rejected, got %v", err)
+	}
+
+	var secretString map[string]string
+	if err := json.Unmarshal([]byte(inputs[2]["SecretString"]), &secretString); err != nil {
+		t.Fatal(err)
+	}
+	if secretString["tls.crt"] != "cert" || secretString["tls.key"] != "key" {
+		t.Errorf("unexpected secret string %v", secretString)
+	}
+}
+
+func TestSignAWSRequestV4(t *testing.T) {
+	// the get-vanilla case of the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequestV4(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if actual := req.Header.Get("Authorization"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
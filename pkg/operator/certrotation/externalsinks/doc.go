@@ -0,0 +1,2 @@
+// Package externalsinks implements certrotation.ExternalSecretSink for secret stores outside of the cluster.
+package externalsinks
@@ -0,0 +1,97 @@
+package externalsinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+// VaultKVSink stores the cert/key pairs in a Vault KV version 2 secrets engine, as the keys "tls.crt" and "tls.key"
+// of the Vault secret <PathPrefix>/<namespace>/<name>. Every rotation creates a new version of the Vault secret.
+type VaultKVSink struct {
+	// Address is the https URL of the Vault server, e.g. https://vault.example.com:8200.
+	Address string
+	// MountPath is the path the KV secrets engine is mounted at, e.g. "secret".
+	MountPath string
+	// PathPrefix is prepended to the paths of the Vault secrets, e.g. the name of the cluster.
+	PathPrefix string
+	// VaultNamespace is the Vault Enterprise namespace, if any.
+	VaultNamespace string
+	// Token returns the Vault token to authenticate with. It is called for every request, so that renewed tokens,
+	// e.g. read from a file, are picked up.
+	Token func(ctx context.Context) (string, error)
+	// HTTPClient, if set, is used instead of http.DefaultClient, e.g. to trust the CA of the Vault server.
+	HTTPClient *http.Client
+}
+
+var _ certrotation.ExternalSecretSink = &VaultKVSink{}
+
+// Name includes the server, the namespace and the path of the Vault secrets, so that sinks storing to different
+// secrets do not collide.
+func (s *VaultKVSink) Name() string {
+	return "vault:" + strings.TrimSuffix(s.Address, "/") + path.Join("/", s.VaultNamespace, s.MountPath, s.PathPrefix)
+}
+
+func (s *VaultKVSink) Store(ctx context.Context, certKeyPair certrotation.ExternalCertKeyPair) error {
+	address, err := url.Parse(s.Address)
+	if err != nil {
+		return fmt.Errorf("invalid Vault address %q: %w", s.Address, err)
+	}
+	// the token and the private key are sent with the request, never in plain text
+	if address.Scheme != "https" {
+		return fmt.Errorf("invalid Vault address %q: not an https URL", s.Address)
+	}
+	token, err := s.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the Vault token: %w", err)
+	}
+	address.Path = path.Join("/", address.Path, "v1", s.MountPath, "data", s.PathPrefix, certKeyPair.Namespace, certKeyPair.Name)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{
+			"tls.crt": string(certKeyPair.Certificate),
+			"tls.key": string(certKeyPair.PrivateKey),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", token)
+	if len(s.VaultNamespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", s.VaultNamespace)
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	var vaultErr struct {
+		Errors []string `json:"errors"`
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(respBody, &vaultErr); err != nil || len(vaultErr.Errors) == 0 {
+		return fmt.Errorf("writing %s failed with status %s", address.Path, resp.Status)
+	}
+	return fmt.Errorf("writing %s failed with status %s: %s", address.Path, resp.Status, strings.Join(vaultErr.Errors, "; "))
+}
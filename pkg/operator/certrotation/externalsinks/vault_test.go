@@ -0,0 +1,86 @@
+package externalsinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+func TestVaultKVSink(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]map[string]string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		requests, bodies = append(requests, r), append(bodies, body)
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"version":1}}`)
+	}))
+	defer server.Close()
+
+	token := "token"
+	sink := &VaultKVSink{
+		Address:        server.URL,
+		MountPath:      "secret",
+		PathPrefix:     "cluster",
+		VaultNamespace: "team",
+		Token:          func(context.Context) (string, error) { return token, nil },
+		HTTPClient:     server.Client(),
+	}
+	certKeyPair := certrotation.ExternalCertKeyPair{Namespace: "ns", Name: "serving", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+	if err := sink.Store(context.TODO(), certKeyPair); err != nil {
+		t.Fatal(err)
+	}
+	if actual := requests[0].URL.Path; actual != "/v1/secret/data/cluster/ns/serving" {
+		t.Errorf("unexpected path %q", actual)
+	}
+	if actual := requests[0].Header.Get("X-Vault-Namespace"); actual != "team" {
+		t.Errorf("unexpected Vault namespace %q", actual)
+	}
+	if data := bodies[0]["data"]; data["tls.crt"] != "cert" || data["tls.key"] != "key" {
+		t.Errorf("unexpected data %v", data)
+	}
+
+	token = "expired"
+	if err := sink.Store(context.TODO(), certKeyPair); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected the Vault error, got %v", err)
+	}
+
+	// the token and the private key are never sent in plain text
+	sink.Address = strings.Replace(server.URL, "https://", "http://", 1)
+	if err := sink.Store(context.TODO(), certKeyPair); err == nil || !strings.Contains(err.Error(), "not an https URL") {
+		t.Errorf("expected an http address to be rejected, got %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected no request to the http address, got %d requests", len(requests))
+	}
+}
+
+func TestVaultKVSinkName(t *testing.T) {
+	sinks := []*VaultKVSink{
+		{Address: "https://vault.example.com:8200", MountPath: "secret", PathPrefix: "cluster-a"},
+		{Address: "https://vault.example.com:8200", MountPath: "secret", PathPrefix: "cluster-b"},
+		{Address: "https://vault.example.com:8200", MountPath: "kv", PathPrefix: "cluster-a"},
+		{Address: "https://vault.example.com:8200", MountPath: "secret", PathPrefix: "cluster-a", VaultNamespace: "team"},
+		{Address: "https://backup.example.com:8200", MountPath: "secret", PathPrefix: "cluster-a"},
+	}
+	names := map[string]bool{}
+	for _, sink := range sinks {
+		if names[sink.Name()] {
+			t.Errorf("duplicate sink name %q", sink.Name())
+		}
+		names[sink.Name()] = true
+	}
+}
@@ -0,0 +1,42 @@
+package certrotation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ForceRotationAnnotation set on a signing CA or target secret makes the next sync rotate its certificate, without
+	// deleting the secret under its consumers. The value is the reason of the rotation, e.g. a ticket number. The
+	// annotation is removed with the rotation.
+	ForceRotationAnnotation = "certrotation.openshift.io/force-rotate"
+	// LastForcedRotationReasonAnnotation records the reason of the last rotation forced by the ForceRotationAnnotation.
+	LastForcedRotationReasonAnnotation = "certrotation.openshift.io/last-forced-rotation-reason"
+)
+
+// forcedRotationReason returns a non-empty reason when the rotation of the secret is forced by the
+// ForceRotationAnnotation.
+func forcedRotationReason(secret *corev1.Secret) string {
+	reason, ok := secret.Annotations[ForceRotationAnnotation]
+	if !ok {
+		return ""
+	}
+	if len(reason) == 0 {
+		reason = "no reason given"
+	}
+	return fmt.Sprintf("rotation forced by the %s annotation: %s", ForceRotationAnnotation, reason)
+}
+
+// completeForcedRotation records the reason of a forced rotation in the secret and removes the
+// ForceRotationAnnotation when the secret is applied.
+func completeForcedRotation(secret *corev1.Secret) {
+	reason, ok := secret.Annotations[ForceRotationAnnotation]
+	if !ok {
+		return
+	}
+	delete(secret.Annotations, ForceRotationAnnotation)
+	// a trailing dash removes the annotation from the existing secret, see resourcemerge.MergeMap
+	secret.Annotations[ForceRotationAnnotation+"-"] = ""
+	secret.Annotations[LastForcedRotationReasonAnnotation] = reason
+}
@@ -0,0 +1,80 @@
+package certrotation
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestForceRotation(t *testing.T) {
+	caConfig, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &crypto.CA{Config: caConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+	target := RotatedSelfSignedCertKeySecret{
+		Namespace: "ns", Name: "target", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+		CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+		Lister:        corev1listers.NewSecretLister(indexer),
+		Client:        client.CoreV1(),
+		EventRecorder: recorder,
+	}
+
+	secret, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forced := secret.DeepCopy()
+	forced.Annotations[ForceRotationAnnotation] = "TICKET-123"
+	indexer.Add(forced)
+	if err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), forced, forced.Namespace); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(rotated.Data[corev1.TLSCertKey], secret.Data[corev1.TLSCertKey]) {
+		t.Error("expected a new certificate")
+	}
+	if _, ok := rotated.Annotations[ForceRotationAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be removed, got %v", ForceRotationAnnotation, rotated.Annotations)
+	}
+	if reason := rotated.Annotations[LastForcedRotationReasonAnnotation]; reason != "TICKET-123" {
+		t.Errorf("expected the reason to be recorded, got %q", reason)
+	}
+	found := false
+	for _, event := range recorder.Events() {
+		if event.Reason == "TargetUpdateRequired" && strings.Contains(event.Message, "TICKET-123") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an event with the reason, got %v", recorder.Events())
+	}
+
+	// the rotated secret is not rotated again
+	indexer.Update(rotated)
+	again, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Data[corev1.TLSCertKey], rotated.Data[corev1.TLSCertKey]) {
+		t.Error("expected the certificate to be kept after the forced rotation")
+	}
+}
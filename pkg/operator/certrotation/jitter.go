@@ -0,0 +1,27 @@
+package certrotation
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// refreshJitter returns the delay of the refresh of a secret within [0, window). It is derived from the namespace and
+// name of the secret, so it is stable across syncs and operator restarts, but differs between the secrets of a
+// cluster.
+func refreshJitter(namespace, name string, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(namespace + "/" + name))
+	return time.Duration(hash.Sum64() % uint64(window))
+}
+
+// capRefreshJitter limits the jitter to 10% of the validity, so that a certificate is rotated at 90% of its validity
+// the latest.
+func capRefreshJitter(jitter, validity time.Duration) time.Duration {
+	if maxJitter := validity / 10; jitter > maxJitter {
+		return maxJitter
+	}
+	return jitter
+}
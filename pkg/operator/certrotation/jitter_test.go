@@ -0,0 +1,52 @@
+package certrotation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefreshJitter(t *testing.T) {
+	if jitter := refreshJitter("ns", "signer", 0); jitter != 0 {
+		t.Errorf("expected no jitter without a window, got %v", jitter)
+	}
+	jitter := refreshJitter("ns", "signer", time.Hour)
+	if jitter < 0 || jitter >= time.Hour {
+		t.Errorf("expected a jitter within the window, got %v", jitter)
+	}
+	if again := refreshJitter("ns", "signer", time.Hour); again != jitter {
+		t.Errorf("expected a stable jitter, got %v and %v", jitter, again)
+	}
+	distinct := map[time.Duration]bool{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		distinct[refreshJitter("ns", name, time.Hour)] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("expected the jitter to differ between secrets, got %v", distinct)
+	}
+
+	if capped := capRefreshJitter(time.Hour, 5*time.Hour); capped != 30*time.Minute {
+		t.Errorf("expected the jitter to be capped at 10%% of the validity, got %v", capped)
+	}
+}
+
+func TestNeedNewSigningCertKeyPairJitter(t *testing.T) {
+	now := time.Now()
+	annotations := map[string]string{
+		CertificateNotBeforeAnnotation: now.Add(-70 * time.Minute).Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  now.Add(10 * time.Hour).Format(time.RFC3339),
+	}
+	if needed, _ := needNewSigningCertKeyPair(annotations, time.Hour, false, 0); !needed {
+		t.Error("expected a signing CA past its refresh time to be rotated")
+	}
+	if needed, reason := needNewSigningCertKeyPair(annotations, time.Hour, false, 30*time.Minute); needed {
+		t.Errorf("expected the rotation to be delayed by the jitter, got %q", reason)
+	}
+
+	expired := map[string]string{
+		CertificateNotBeforeAnnotation: now.Add(-10 * time.Hour).Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  now.Add(-time.Minute).Format(time.RFC3339),
+	}
+	if needed, _ := needNewSigningCertKeyPair(expired, time.Hour, false, 30*time.Minute); !needed {
+		t.Error("expected an expired signing CA to be rotated regardless of the jitter")
+	}
+}
@@ -0,0 +1,149 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// lastWrittenSecret returns the secret of the last create or update, nil if there was none.
+func lastWrittenSecret(client *kubefake.Clientset) *corev1.Secret {
+	var secret *corev1.Secret
+	for _, action := range client.Actions() {
+		switch action := action.(type) {
+		case clienttesting.CreateAction:
+			secret = action.GetObject().(*corev1.Secret)
+		case clienttesting.UpdateAction:
+			secret = action.GetObject().(*corev1.Secret)
+		}
+	}
+	return secret
+}
+
+func keyAlgorithmOfSecret(t *testing.T, secret *corev1.Secret) crypto.KeyAlgorithm {
+	t.Helper()
+	certs, err := crypto.CertsFromPEM(secret.Data["tls.crt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyAlgorithm, err := crypto.KeyAlgorithmOf(certs[0].PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyAlgorithm
+}
+
+func TestKeyAlgorithm(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	signer := &RotatedSigningCASecret{
+		Namespace:     "ns",
+		Name:          "signer",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		KeyAlgorithm:  crypto.ECDSAP256KeyAlgorithm,
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace:     "ns",
+		Name:          "target",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		KeyAlgorithm:  crypto.ECDSAP384KeyAlgorithm,
+		CertCreator:   &ClientRotation{UserInfo: &user.DefaultInfo{Name: "foo"}},
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	ensure := func() (*crypto.CA, *corev1.Secret) {
+		t.Helper()
+		client.ClearActions()
+		ca, err := signer.ensureSigningCertKeyPair(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret := lastWrittenSecret(client); secret != nil {
+			indexer.Add(secret)
+		}
+		if _, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs); err != nil {
+			t.Fatal(err)
+		}
+		written := lastWrittenSecret(client)
+		if written != nil {
+			indexer.Add(written)
+		}
+		return ca, written
+	}
+
+	ca, written := ensure()
+	if actual, _ := crypto.KeyAlgorithmOf(ca.Config.Certs[0].PublicKey); actual != crypto.ECDSAP256KeyAlgorithm {
+		t.Errorf("expected an %s signer, got %s", crypto.ECDSAP256KeyAlgorithm, actual)
+	}
+	if actual := keyAlgorithmOfSecret(t, written); actual != crypto.ECDSAP384KeyAlgorithm {
+		t.Errorf("expected an %s target, got %s", crypto.ECDSAP384KeyAlgorithm, actual)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Config.Certs[0])
+	targetCerts, _ := crypto.CertsFromPEM(written.Data["tls.crt"])
+	if _, err := targetCerts[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("expected the target to verify: %v", err)
+	}
+
+	// nothing to do while the algorithms are unchanged
+	if _, written := ensure(); written != nil {
+		t.Errorf("expected no update, got %v", client.Actions())
+	}
+
+	// a change of the algorithm regenerates the target
+	target.KeyAlgorithm = crypto.RSA2048KeyAlgorithm
+	if _, written := ensure(); written == nil || keyAlgorithmOfSecret(t, written) != crypto.RSA2048KeyAlgorithm {
+		t.Errorf("expected an %s target, got %v", crypto.RSA2048KeyAlgorithm, client.Actions())
+	}
+
+	// without an algorithm, the existing keys are kept
+	signer.KeyAlgorithm = ""
+	target.KeyAlgorithm = ""
+	if _, written := ensure(); written != nil {
+		t.Errorf("expected no update without key algorithms, got %v", client.Actions())
+	}
+
+	// an explicitly set algorithm regenerates the signer
+	signer.KeyAlgorithm = crypto.RSA2048KeyAlgorithm
+	if ca, _ := ensure(); ca == nil {
+		t.Fatal("expected a signer")
+	} else if actual, _ := crypto.KeyAlgorithmOf(ca.Config.Certs[0].PublicKey); actual != crypto.RSA2048KeyAlgorithm {
+		t.Errorf("expected a %s signer, got %s", crypto.RSA2048KeyAlgorithm, actual)
+	}
+}
+
+// legacyCertCreator hides the TargetCertKeyAlgorithmCreator implementation of the wrapped creator.
+type legacyCertCreator struct {
+	TargetCertCreator
+}
+
+func TestKeyAlgorithmUnsupportedCertCreator(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{}
+	err = setTargetCertKeyPairSecret(context.TODO(), secret, time.Hour, &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}},
+		legacyCertCreator{&ClientRotation{UserInfo: &user.DefaultInfo{Name: "foo"}}}, crypto.ECDSAP256KeyAlgorithm, newSecretDataKeys("", ""))
+	if err == nil || !strings.Contains(err.Error(), "does not support key algorithm") {
+		t.Errorf("expected an error for a cert creator without key algorithms, got %v", err)
+	}
+}
@@ -1,9 +1,15 @@
 package certrotation
 
 import (
+	"crypto/sha256"
+	"fmt"
+
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 )
 
 const (
@@ -11,6 +17,10 @@ const (
 	// This groups all objects that store certs and allow easy query to get them all.
 	// The value of this label should be set to "true".
 	ManagedCertificateTypeLabelName = "auth.openshift.io/managed-certificate-type"
+
+	// ManagedByControllerLabelName marks config map or secret as written by the cert rotation controller identified
+	// by the value, see WithOrphanedResourceDeletion and managedByControllerLabelValue.
+	ManagedByControllerLabelName = "certrotation.openshift.io/controller"
 )
 
 type CertificateType string
@@ -59,3 +69,24 @@ func CertificateTypeFromObject(obj runtime.Object) (CertificateType, error) {
 		return CertificateTypeUnknown, nil
 	}
 }
+
+// managedByControllerLabelValue returns the value of ManagedByControllerLabelName for the named controller of the
+// operator. The hex encoded sha256 sum is a valid label value for any names and keeps controllers of the same name run
+// by different operators in a shared namespace apart.
+func managedByControllerLabelValue(operator, controller string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(operator+"/"+controller)))[:resourcenames.MaxLabelLength]
+}
+
+// labelAsManagedByController adds the label indicating the object was written by the cert rotation controller
+// identified by value, see managedByControllerLabelValue. Nothing is added if the value is empty.
+func labelAsManagedByController(obj metav1.Object, value string) {
+	if len(value) == 0 {
+		return
+	}
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = map[string]string{}
+	}
+	objLabels[ManagedByControllerLabelName] = value
+	obj.SetLabels(objLabels)
+}
@@ -0,0 +1,254 @@
+package certrotation
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// CertManifest declares the PKI of an operator: signers, the CA bundles trusting them and the targets they sign,
+// for example
+//
+//	signers:
+//	- name: KubeControllerManagerClient
+//	  namespace: openshift-kube-apiserver-operator
+//	  secretName: kube-control-plane-signer
+//	  validity: 1440h
+//	  refresh: 720h
+//	  bundle:
+//	    namespace: openshift-kube-apiserver-operator
+//	    name: kube-control-plane-signer-ca
+//	  targets:
+//	  - namespace: openshift-config-managed
+//	    name: kube-controller-manager-client-cert-key
+//	    validity: 720h
+//	    refresh: 360h
+//	    client:
+//	      user: system:kube-controller-manager
+type CertManifest struct {
+	Signers []SignerManifest `json:"signers"`
+}
+
+// SignerManifest declares a rotated signing CA with its CA bundle and targets.
+type SignerManifest struct {
+	// Name identifies the signer in the CertRotationDegraded condition of its controller.
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace"`
+	SecretName string          `json:"secretName"`
+	Validity   metav1.Duration `json:"validity"`
+	Refresh    metav1.Duration `json:"refresh"`
+	// RefreshOnlyWhenExpired disables the proactive refresh, see RotatedSigningCASecret.
+	RefreshOnlyWhenExpired bool `json:"refreshOnlyWhenExpired,omitempty"`
+
+	Bundle  BundleManifest   `json:"bundle"`
+	Targets []TargetManifest `json:"targets,omitempty"`
+}
+
+// BundleManifest declares the CA bundle config map of a signer.
+type BundleManifest struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// TargetManifest declares a cert and key signed by a signer. Exactly one of client, serving and signer must be set.
+type TargetManifest struct {
+	Namespace              string          `json:"namespace"`
+	Name                   string          `json:"name"`
+	Validity               metav1.Duration `json:"validity"`
+	Refresh                metav1.Duration `json:"refresh"`
+	RefreshOnlyWhenExpired bool            `json:"refreshOnlyWhenExpired,omitempty"`
+
+	Client  *ClientTargetManifest  `json:"client,omitempty"`
+	Serving *ServingTargetManifest `json:"serving,omitempty"`
+	Signer  *SignerTargetManifest  `json:"signer,omitempty"`
+}
+
+// ClientTargetManifest declares a client certificate.
+type ClientTargetManifest struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ServingTargetManifest declares a serving certificate for fixed hostnames.
+type ServingTargetManifest struct {
+	Hostnames []string `json:"hostnames"`
+}
+
+// SignerTargetManifest declares an intermediate signing CA.
+type SignerTargetManifest struct {
+	SignerName string `json:"signerName"`
+}
+
+// ReadCertManifest parses and validates a YAML or JSON cert manifest.
+func ReadCertManifest(data []byte) (*CertManifest, error) {
+	manifest := &CertManifest{}
+	if err := yaml.UnmarshalStrict(data, manifest); err != nil {
+		return nil, err
+	}
+	if errs := manifest.Validate(); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return manifest, nil
+}
+
+// Validate returns the errors of the manifest.
+func (m *CertManifest) Validate() field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i, signer := range m.Signers {
+		fldPath := field.NewPath("signers").Index(i)
+		if len(signer.Name) == 0 {
+			errs = append(errs, field.Required(fldPath.Child("name"), ""))
+		} else if names[signer.Name] {
+			errs = append(errs, field.Duplicate(fldPath.Child("name"), signer.Name))
+		}
+		names[signer.Name] = true
+		errs = append(errs, validateSecret(fldPath, signer.Namespace, signer.SecretName, "secretName")...)
+		errs = append(errs, validateValidity(fldPath, signer.Validity, signer.Refresh, signer.RefreshOnlyWhenExpired)...)
+		errs = append(errs, validateSecret(fldPath.Child("bundle"), signer.Bundle.Namespace, signer.Bundle.Name, "name")...)
+
+		for j, target := range signer.Targets {
+			targetPath := fldPath.Child("targets").Index(j)
+			errs = append(errs, validateSecret(targetPath, target.Namespace, target.Name, "name")...)
+			errs = append(errs, validateValidity(targetPath, target.Validity, target.Refresh, target.RefreshOnlyWhenExpired)...)
+			if target.Validity.Duration > signer.Validity.Duration {
+				errs = append(errs, field.Invalid(targetPath.Child("validity"), target.Validity.Duration.String(), fmt.Sprintf("must not be longer than the signer validity %v", signer.Validity.Duration)))
+			}
+
+			kinds := 0
+			if target.Client != nil {
+				kinds++
+				if len(target.Client.User) == 0 {
+					errs = append(errs, field.Required(targetPath.Child("client", "user"), ""))
+				}
+			}
+			if target.Serving != nil {
+				kinds++
+				if len(target.Serving.Hostnames) == 0 {
+					errs = append(errs, field.Required(targetPath.Child("serving", "hostnames"), ""))
+				}
+			}
+			if target.Signer != nil {
+				kinds++
+				if len(target.Signer.SignerName) == 0 {
+					errs = append(errs, field.Required(targetPath.Child("signer", "signerName"), ""))
+				}
+			}
+			if kinds != 1 {
+				errs = append(errs, field.Invalid(targetPath, target.Name, "exactly one of client, serving and signer must be set"))
+			}
+		}
+	}
+	return errs
+}
+
+func validateSecret(fldPath *field.Path, namespace, name, nameField string) field.ErrorList {
+	var errs field.ErrorList
+	if len(namespace) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("namespace"), ""))
+	}
+	if len(name) == 0 {
+		errs = append(errs, field.Required(fldPath.Child(nameField), ""))
+	}
+	return errs
+}
+
+// validateValidity checks the validity and the refresh. The refresh is not used, and may be unset, when the
+// certificate is only refreshed when expired.
+func validateValidity(fldPath *field.Path, validity, refresh metav1.Duration, refreshOnlyWhenExpired bool) field.ErrorList {
+	var errs field.ErrorList
+	if validity.Duration <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("validity"), validity.Duration.String(), "must be positive"))
+	}
+	if refreshOnlyWhenExpired {
+		return errs
+	}
+	if refresh.Duration <= 0 || refresh.Duration > validity.Duration {
+		errs = append(errs, field.Invalid(fldPath.Child("refresh"), refresh.Duration.String(), "must be positive and not longer than the validity"))
+	}
+	return errs
+}
+
+// NewCertRotationControllersFromManifest returns a MultipleTargetsCertRotationController per signer of the manifest.
+// The informers must cover the namespaces of all secrets and config maps of the manifest. The options are applied to
+// every controller.
+func NewCertRotationControllersFromManifest(
+	manifest *CertManifest,
+	kubeClient kubernetes.Interface,
+	kubeInformers v1helpers.KubeInformersForNamespaces,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	recorder events.Recorder,
+	opts ...MultipleTargetsCertRotationControllerOption,
+) ([]factory.Controller, error) {
+	if errs := manifest.Validate(); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	var controllers []factory.Controller
+	for _, signer := range manifest.Signers {
+		rotatedSigningCASecret := &RotatedSigningCASecret{
+			Namespace:              signer.Namespace,
+			Name:                   signer.SecretName,
+			Validity:               signer.Validity.Duration,
+			Refresh:                signer.Refresh.Duration,
+			RefreshOnlyWhenExpired: signer.RefreshOnlyWhenExpired,
+			Client:                 kubeClient.CoreV1(),
+			EventRecorder:          recorder,
+		}
+		caBundleConfigMap := &CABundleConfigMap{
+			Namespace:     signer.Bundle.Namespace,
+			Name:          signer.Bundle.Name,
+			Client:        kubeClient.CoreV1(),
+			EventRecorder: recorder,
+		}
+		targets := make([]RotatedSelfSignedCertKeySecret, len(signer.Targets))
+		targetPointers := make([]*RotatedSelfSignedCertKeySecret, len(signer.Targets))
+		for i, target := range signer.Targets {
+			targets[i] = RotatedSelfSignedCertKeySecret{
+				Namespace:              target.Namespace,
+				Name:                   target.Name,
+				Validity:               target.Validity.Duration,
+				Refresh:                target.Refresh.Duration,
+				RefreshOnlyWhenExpired: target.RefreshOnlyWhenExpired,
+				CertCreator:            target.certCreator(),
+				Client:                 kubeClient.CoreV1(),
+				EventRecorder:          recorder,
+			}
+			targetPointers[i] = &targets[i]
+		}
+		if err := WireInformersForNamespaces(kubeInformers, rotatedSigningCASecret, caBundleConfigMap, targetPointers...); err != nil {
+			return nil, err
+		}
+
+		controllers = append(controllers, NewCertRotationControllerMultipleTargets(
+			signer.Name,
+			*rotatedSigningCASecret,
+			*caBundleConfigMap,
+			targets,
+			operatorClient,
+			recorder,
+			opts...,
+		))
+	}
+	return controllers, nil
+}
+
+func (t TargetManifest) certCreator() TargetCertCreator {
+	switch {
+	case t.Client != nil:
+		return &ClientRotation{UserInfo: &user.DefaultInfo{Name: t.Client.User, Groups: t.Client.Groups}}
+	case t.Serving != nil:
+		hostnames := t.Serving.Hostnames
+		return &ServingRotation{Hostnames: func() []string { return hostnames }}
+	default:
+		return &SignerRotation{SignerName: t.Signer.SignerName}
+	}
+}
@@ -0,0 +1,206 @@
+package certrotation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const testCertManifest = `
+signers:
+- name: ControlPlane
+  namespace: operator
+  secretName: control-plane-signer
+  validity: 1440h
+  refresh: 720h
+  bundle:
+    namespace: operator
+    name: control-plane-signer-ca
+  targets:
+  - namespace: operand
+    name: client-cert-key
+    validity: 720h
+    refresh: 360h
+    client:
+      user: system:foo
+      groups: [system:masters]
+  - namespace: operand
+    name: serving-cert-key
+    validity: 720h
+    refresh: 360h
+    serving:
+      hostnames: [localhost, 127.0.0.1]
+- name: Aggregator
+  namespace: operator
+  secretName: aggregator-signer
+  validity: 720h
+  refresh: 360h
+  bundle:
+    namespace: operand
+    name: aggregator-ca
+  targets:
+  - namespace: operand
+    name: intermediate-signer
+    validity: 360h
+    refresh: 180h
+    signer:
+      signerName: intermediate
+`
+
+func TestReadCertManifest(t *testing.T) {
+	manifest, err := ReadCertManifest([]byte(testCertManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Signers) != 2 || len(manifest.Signers[0].Targets) != 2 {
+		t.Fatalf("unexpected manifest: %#v", manifest)
+	}
+	if manifest.Signers[0].Validity.Duration != 60*24*time.Hour {
+		t.Errorf("unexpected validity %v", manifest.Signers[0].Validity)
+	}
+
+	creator := manifest.Signers[0].Targets[0].certCreator().(*ClientRotation)
+	if creator.UserInfo.GetName() != "system:foo" || creator.UserInfo.GetGroups()[0] != "system:masters" {
+		t.Errorf("unexpected user %v", creator.UserInfo)
+	}
+	serving := manifest.Signers[0].Targets[1].certCreator().(*ServingRotation)
+	if hostnames := serving.Hostnames(); len(hostnames) != 2 || hostnames[0] != "localhost" {
+		t.Errorf("unexpected hostnames %v", hostnames)
+	}
+	if signer := manifest.Signers[1].Targets[0].certCreator().(*SignerRotation); signer.SignerName != "intermediate" {
+		t.Errorf("unexpected signer name %q", signer.SignerName)
+	}
+}
+
+func TestReadCertManifestRefreshOnlyWhenExpired(t *testing.T) {
+	manifest, err := ReadCertManifest([]byte(`
+signers:
+- name: foo
+  namespace: ns
+  secretName: signer
+  validity: 10h
+  refreshOnlyWhenExpired: true
+  bundle: {namespace: ns, name: ca}
+  targets:
+  - {namespace: ns, name: target, validity: 5h, refreshOnlyWhenExpired: true, serving: {hostnames: [localhost]}}
+`))
+	if err != nil {
+		t.Fatalf("expected the refresh to be optional when only refreshing expired certificates, got %v", err)
+	}
+	if !manifest.Signers[0].RefreshOnlyWhenExpired || !manifest.Signers[0].Targets[0].RefreshOnlyWhenExpired {
+		t.Errorf("unexpected manifest: %#v", manifest)
+	}
+}
+
+func TestReadCertManifestErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		manifest      string
+		expectedError string
+	}{
+		{
+			name:          "unknown field",
+			manifest:      "signers:\n- nam: foo\n",
+			expectedError: "unknown field",
+		},
+		{
+			name: "missing fields",
+			manifest: `
+signers:
+- name: foo
+  validity: 10h
+  refresh: 5h
+`,
+			expectedError: "signers[0].namespace: Required value",
+		},
+		{
+			name: "refresh longer than validity",
+			manifest: `
+signers:
+- name: foo
+  namespace: ns
+  secretName: signer
+  validity: 10h
+  refresh: 20h
+  bundle: {namespace: ns, name: ca}
+`,
+			expectedError: "signers[0].refresh: Invalid value",
+		},
+		{
+			name: "duplicate names",
+			manifest: `
+signers:
+- {name: foo, namespace: ns, secretName: a, validity: 10h, refresh: 5h, bundle: {namespace: ns, name: a}}
+- {name: foo, namespace: ns, secretName: b, validity: 10h, refresh: 5h, bundle: {namespace: ns, name: b}}
+`,
+			expectedError: "signers[1].name: Duplicate value",
+		},
+		{
+			name: "target without kind",
+			manifest: `
+signers:
+- name: foo
+  namespace: ns
+  secretName: signer
+  validity: 10h
+  refresh: 5h
+  bundle: {namespace: ns, name: ca}
+  targets:
+  - {namespace: ns, name: target, validity: 5h, refresh: 2h}
+`,
+			expectedError: "exactly one of client, serving and signer must be set",
+		},
+		{
+			name: "target outliving the signer",
+			manifest: `
+signers:
+- name: foo
+  namespace: ns
+  secretName: signer
+  validity: 10h
+  refresh: 5h
+  bundle: {namespace: ns, name: ca}
+  targets:
+  - {namespace: ns, name: target, validity: 20h, refresh: 2h, serving: {hostnames: [localhost]}}
+`,
+			expectedError: "signers[0].targets[0].validity: Invalid value: \"20h0m0s\": must not be longer than the signer validity 10h0m0s",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadCertManifest([]byte(test.manifest))
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("expected error containing %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestNewCertRotationControllersFromManifest(t *testing.T) {
+	manifest, err := ReadCertManifest([]byte(testCertManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeClient := fake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	recorder := events.NewInMemoryRecorder("test")
+
+	controllers, err := NewCertRotationControllersFromManifest(manifest, kubeClient, v1helpers.NewKubeInformersForNamespaces(kubeClient, "operator", "operand"), operatorClient, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(controllers) != 2 {
+		t.Errorf("expected a controller per signer, got %d", len(controllers))
+	}
+
+	_, err = NewCertRotationControllersFromManifest(manifest, kubeClient, v1helpers.NewKubeInformersForNamespaces(kubeClient, "operator"), operatorClient, recorder)
+	if err == nil || !strings.Contains(err.Error(), `"operand"`) {
+		t.Errorf("expected an error about missing informers, got %v", err)
+	}
+}
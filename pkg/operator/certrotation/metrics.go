@@ -0,0 +1,85 @@
+package certrotation
+
+import (
+	"time"
+
+	k8smetrics "k8s.io/component-base/metrics"
+
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+)
+
+// metrics provides access to the cert rotation metrics. They let operators alert on an impending expiry without
+// reading the secrets.
+var metrics = newCertRotationMetrics()
+
+type certRotationMetrics struct {
+	notAfter         *librarymetrics.GaugeVec
+	untilRefresh     *librarymetrics.GaugeVec
+	rotations        *librarymetrics.CounterVec
+	rotationFailures *librarymetrics.CounterVec
+}
+
+func newCertRotationMetrics() *certRotationMetrics {
+	return &certRotationMetrics{
+		notAfter: librarymetrics.NewGaugeVec(
+			&k8smetrics.GaugeOpts{
+				Name:           "openshift_certrotation_certificate_not_after_timestamp_seconds",
+				Help:           "The NotAfter time of a rotated certificate as unix timestamp, labeled with the namespace and name of its secret and the certificate type",
+				StabilityLevel: k8smetrics.ALPHA,
+			}, []string{"namespace", "name", "type"}),
+		untilRefresh: librarymetrics.NewGaugeVec(
+			&k8smetrics.GaugeOpts{
+				Name:           "openshift_certrotation_certificate_seconds_until_refresh",
+				Help:           "The seconds until a rotated certificate is due for rotation at the last sync, negative if overdue, labeled with the namespace and name of its secret and the certificate type",
+				StabilityLevel: k8smetrics.ALPHA,
+			}, []string{"namespace", "name", "type"}),
+		rotations: librarymetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Name:           "openshift_certrotation_rotations_total",
+				Help:           "The total number of rotations of a certificate, labeled with the namespace and name of its secret and the certificate type",
+				StabilityLevel: k8smetrics.ALPHA,
+			}, []string{"namespace", "name", "type"}),
+		rotationFailures: librarymetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Name:           "openshift_certrotation_rotation_failures_total",
+				Help:           "The total number of failed syncs of a cert rotation controller, labeled with the controller name",
+				StabilityLevel: k8smetrics.ALPHA,
+			}, []string{"controller"}),
+	}
+}
+
+// observeCertificate records the validity of the certificate in the secret annotations. Secrets without valid
+// annotations are not recorded.
+func (m *certRotationMetrics) observeCertificate(namespace, name string, certificateType CertificateType, annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) {
+	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
+	if len(reason) > 0 {
+		return
+	}
+	m.notAfter.WithLabelValues(namespace, name, string(certificateType)).Set(float64(notAfter.Unix()))
+	m.untilRefresh.WithLabelValues(namespace, name, string(certificateType)).Set(time.Until(refreshTime(notBefore, notAfter, refresh, refreshOnlyWhenExpired, jitter)).Seconds())
+}
+
+func (m *certRotationMetrics) observeRotation(namespace, name string, certificateType CertificateType) {
+	m.rotations.WithLabelValues(namespace, name, string(certificateType)).Inc()
+}
+
+func (m *certRotationMetrics) observeSync(controller string, syncErr error) {
+	if syncErr != nil {
+		m.rotationFailures.WithLabelValues(controller).Inc()
+	}
+}
+
+// refreshTime returns when a certificate is due for rotation: at the refresh duration after its creation, but at 80%
+// of its validity the latest, both delayed by the jitter, or only at expiry if refreshOnlyWhenExpired is set.
+func refreshTime(notBefore, notAfter time.Time, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) time.Time {
+	if refreshOnlyWhenExpired {
+		return notAfter
+	}
+	validity := notAfter.Sub(notBefore)
+	jitter = capRefreshJitter(jitter, validity)
+	at80Percent := notAfter.Add(-validity / 5).Add(jitter)
+	if developerSpecifiedRefresh := notBefore.Add(refresh).Add(jitter); developerSpecifiedRefresh.Before(at80Percent) {
+		return developerSpecifiedRefresh
+	}
+	return at80Percent
+}
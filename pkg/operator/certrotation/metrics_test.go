@@ -0,0 +1,91 @@
+package certrotation
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+)
+
+type recordingSink struct {
+	lock   sync.Mutex
+	values map[string]float64
+}
+
+func (s *recordingSink) record(name string, labels map[string]string, value float64, add bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := name + "/" + labels["namespace"] + "/" + labels["name"] + "/" + labels["type"] + labels["controller"]
+	if add {
+		s.values[key] += value
+		return
+	}
+	s.values[key] = value
+}
+
+func (s *recordingSink) AddCounter(name, _ string, labels map[string]string, value float64) {
+	s.record(name, labels, value, true)
+}
+
+func (s *recordingSink) SetGauge(name, _ string, labels map[string]string, value float64) {
+	s.record(name, labels, value, false)
+}
+
+func (s *recordingSink) ObserveHistogram(name, _ string, labels map[string]string, value float64) {}
+
+func TestRefreshTime(t *testing.T) {
+	notBefore := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(100 * time.Hour)
+	for _, test := range []struct {
+		name                   string
+		refresh                time.Duration
+		refreshOnlyWhenExpired bool
+		expected               time.Time
+	}{
+		{name: "refresh", refresh: 10 * time.Hour, expected: notBefore.Add(10 * time.Hour)},
+		{name: "80% of the validity", refresh: 90 * time.Hour, expected: notBefore.Add(80 * time.Hour)},
+		{name: "only when expired", refresh: 10 * time.Hour, refreshOnlyWhenExpired: true, expected: notAfter},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := refreshTime(notBefore, notAfter, test.refresh, test.refreshOnlyWhenExpired, 0); !actual.Equal(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	sink := &recordingSink{values: map[string]float64{}}
+	librarymetrics.Configure(librarymetrics.Options{DisablePrometheus: true, Sink: sink})
+	defer librarymetrics.Configure(librarymetrics.Options{})
+
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(10 * time.Hour)
+	annotations := map[string]string{
+		CertificateNotBeforeAnnotation: notBefore.Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  notAfter.Format(time.RFC3339),
+	}
+	metrics.observeRotation("ns", "target", CertificateTypeTarget)
+	metrics.observeCertificate("ns", "target", CertificateTypeTarget, annotations, 2*time.Hour, false, 0)
+	metrics.observeCertificate("ns", "broken", CertificateTypeTarget, map[string]string{}, 2*time.Hour, false, 0)
+	metrics.observeSync("Controller", nil)
+	metrics.observeSync("Controller", errors.New("failed"))
+
+	if actual := sink.values["openshift_certrotation_certificate_not_after_timestamp_seconds/ns/target/target"]; actual != float64(notAfter.Unix()) {
+		t.Errorf("expected not after %d, got %v", notAfter.Unix(), actual)
+	}
+	if actual := sink.values["openshift_certrotation_certificate_seconds_until_refresh/ns/target/target"]; actual < 3500 || actual > 3600 {
+		t.Errorf("expected about an hour until refresh, got %v", actual)
+	}
+	if actual := sink.values["openshift_certrotation_rotations_total/ns/target/target"]; actual != 1 {
+		t.Errorf("expected 1 rotation, got %v", actual)
+	}
+	if actual := sink.values["openshift_certrotation_rotation_failures_total///Controller"]; actual != 1 {
+		t.Errorf("expected 1 failure, got %v", actual)
+	}
+	if _, ok := sink.values["openshift_certrotation_certificate_not_after_timestamp_seconds/ns/broken/target"]; ok {
+		t.Errorf("expected no metrics for a secret without validity annotations")
+	}
+}
@@ -0,0 +1,232 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// minRecheckDelay avoids hot looping when a rotation is due but cannot happen yet, e.g. while waiting for the signer.
+const minRecheckDelay = 10 * time.Second
+
+// MultipleTargetsCertRotationController rotates one signer with its CA bundle and any number of targets signed by it.
+// Instead of a goroutine per target, all targets share a single scheduling loop: after every sync the controller
+// computes the earliest time any signer or target needs to be looked at again and requeues itself for that time.
+type MultipleTargetsCertRotationController struct {
+	name string
+
+	rotatedSigningCASecret          RotatedSigningCASecret
+	caBundleConfigMap               CABundleConfigMap
+	rotatedSelfSignedCertKeySecrets []RotatedSelfSignedCertKeySecret
+
+	operatorClient v1helpers.StaticPodOperatorClient
+	now            func() time.Time
+
+	// registry holds the managed resources while the controller runs, DefaultManagedResourceRegistry if not set.
+	registry *ManagedResourceRegistry
+}
+
+// MultipleTargetsCertRotationControllerOption configures optional behaviour of the MultipleTargetsCertRotationController.
+type MultipleTargetsCertRotationControllerOption func(*MultipleTargetsCertRotationController)
+
+// WithMultipleTargetsManagedResourceRegistry registers the managed resources of the MultipleTargetsCertRotationController
+// in the given registry instead of DefaultManagedResourceRegistry, e.g. to scope them to an operator or a test.
+func WithMultipleTargetsManagedResourceRegistry(registry *ManagedResourceRegistry) MultipleTargetsCertRotationControllerOption {
+	return func(c *MultipleTargetsCertRotationController) {
+		c.registry = registry
+	}
+}
+
+// NewCertRotationControllerMultipleTargets returns a controller rotating the signer, the CA bundle and all targets.
+// Every target rotates according to its own Validity and Refresh. It panics if ValidateMultipleTargets fails.
+// The degraded condition is reported like for NewCertRotationController.
+func NewCertRotationControllerMultipleTargets(
+	name string,
+	rotatedSigningCASecret RotatedSigningCASecret,
+	caBundleConfigMap CABundleConfigMap,
+	rotatedSelfSignedCertKeySecrets []RotatedSelfSignedCertKeySecret,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	recorder events.Recorder,
+	opts ...MultipleTargetsCertRotationControllerOption,
+) factory.Controller {
+	if err := ValidateMultipleTargets(rotatedSigningCASecret, rotatedSelfSignedCertKeySecrets); err != nil {
+		panic(fmt.Errorf("invalid configuration of cert rotation controller %q: %w", name, err))
+	}
+	c := &MultipleTargetsCertRotationController{
+		name:                            name,
+		rotatedSigningCASecret:          rotatedSigningCASecret,
+		caBundleConfigMap:               caBundleConfigMap,
+		rotatedSelfSignedCertKeySecrets: rotatedSelfSignedCertKeySecrets,
+		operatorClient:                  operatorClient,
+		now:                             time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	registry := c.registry
+	if registry == nil {
+		registry = DefaultManagedResourceRegistry
+	}
+
+	f := factory.New().
+		ResyncEvery(time.Minute).
+		WithSync(c.Sync).
+		WithPostStartHooks(
+			c.targetCertRecheckerPostRunHook,
+			registry.registerWhileRunning(managedResourcesFor(name, rotatedSigningCASecret, caBundleConfigMap, rotatedSelfSignedCertKeySecrets...)),
+		)
+	f = withResourceInformers(f, rotatedSigningCASecret, caBundleConfigMap, rotatedSelfSignedCertKeySecrets...)
+	return caBundleConfigMap.withTrustSources(f).ToController("CertRotationController", recorder.WithComponentSuffix("cert-rotation-controller"))
+}
+
+// ValidateMultipleTargets checks the validity and refresh of the signer and the targets. The signer must be valid at
+// least as long as the longest target, otherwise the targets are cut short to the remaining lifetime of the signer
+// and rotate far more often than configured.
+func ValidateMultipleTargets(rotatedSigningCASecret RotatedSigningCASecret, rotatedSelfSignedCertKeySecrets []RotatedSelfSignedCertKeySecret) error {
+	var errs []error
+	if err := validateRotationDurations(rotatedSigningCASecret.Validity, rotatedSigningCASecret.Refresh, rotatedSigningCASecret.RefreshOnlyWhenExpired); err != nil {
+		errs = append(errs, fmt.Errorf("signer %s/%s: %w", rotatedSigningCASecret.Namespace, rotatedSigningCASecret.Name, err))
+	}
+	for _, target := range rotatedSelfSignedCertKeySecrets {
+		if err := validateRotationDurations(target.Validity, target.Refresh, target.RefreshOnlyWhenExpired); err != nil {
+			errs = append(errs, fmt.Errorf("target %s/%s: %w", target.Namespace, target.Name, err))
+			continue
+		}
+		if target.Validity > rotatedSigningCASecret.Validity {
+			errs = append(errs, fmt.Errorf("target %s/%s: validity %v is longer than the validity %v of signer %s/%s", target.Namespace, target.Name, target.Validity, rotatedSigningCASecret.Validity, rotatedSigningCASecret.Namespace, rotatedSigningCASecret.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateRotationDurations(validity, refresh time.Duration, refreshOnlyWhenExpired bool) error {
+	if validity <= 0 {
+		return fmt.Errorf("validity %v must be positive", validity)
+	}
+	if !refreshOnlyWhenExpired && refresh <= 0 {
+		return fmt.Errorf("refresh %v must be positive", refresh)
+	}
+	return nil
+}
+
+func (c MultipleTargetsCertRotationController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	paused, err := c.rotatedSigningCASecret.rotationPaused(ctx)
+	if err != nil {
+		return reportRotationStatus(ctx, syncCtx, c.name, c.operatorClient, err)
+	}
+	if paused {
+		return reportRotationPaused(ctx, syncCtx, c.name, c.operatorClient, c.rotatedSigningCASecret)
+	}
+	conditions, syncErr := c.syncWorker(ctx)
+	if next, ok := c.nextRecheck(); ok {
+		delay := next.Sub(c.now())
+		if delay < minRecheckDelay {
+			delay = minRecheckDelay
+		}
+		klog.V(4).Infof("%s: next certificate recheck in %v", c.name, delay)
+		syncCtx.Queue().AddAfter(factory.DefaultQueueKey, delay)
+	}
+	return reportRotationStatus(ctx, syncCtx, c.name, c.operatorClient, syncErr, conditions...)
+}
+
+// syncWorker rotates the signer, the CA bundle and the targets. It returns their conditions, the conditions of the
+// resources after a failed signer or CA bundle step are unknown.
+func (c MultipleTargetsCertRotationController) syncWorker(ctx context.Context) ([]operatorv1.OperatorCondition, error) {
+	notSyncedTargets := func() []operatorv1.OperatorCondition {
+		var conditions []operatorv1.OperatorCondition
+		for _, target := range c.rotatedSelfSignedCertKeySecrets {
+			conditions = append(conditions, notSyncedCondition(fmt.Sprintf(condition.CertRotationTargetCertValidConditionTypeFmt, c.name, target.Name), target.stepName()))
+		}
+		return conditions
+	}
+
+	var signingCertKeyPair *crypto.CA
+	err := runStep(ctx, DefaultOperationTimeout, c.rotatedSigningCASecret.stepName(), func(ctx context.Context) (err error) {
+		signingCertKeyPair, err = c.rotatedSigningCASecret.ensureSigningCertKeyPair(ctx)
+		return err
+	})
+	conditions := []operatorv1.OperatorCondition{signerValidCondition(c.name, c.rotatedSigningCASecret, signingCertKeyPair, err)}
+	if err != nil {
+		conditions = append(conditions, notSyncedCondition(fmt.Sprintf(condition.CertRotationCABundleValidConditionTypeFmt, c.name), c.caBundleConfigMap.stepName()))
+		return append(conditions, notSyncedTargets()...), err
+	}
+	var cabundleCerts []*x509.Certificate
+	caBundleConfigMap := c.caBundleConfigMap
+	caBundleConfigMap.now = c.now
+	err = runStep(ctx, DefaultOperationTimeout, c.caBundleConfigMap.stepName(), func(ctx context.Context) (err error) {
+		cabundleCerts, err = caBundleConfigMap.ensureConfigMapCABundle(ctx, signingCertKeyPair)
+		return err
+	})
+	conditions = append(conditions, caBundleValidCondition(c.name, c.caBundleConfigMap, cabundleCerts, err))
+	if err != nil {
+		return append(conditions, notSyncedTargets()...), err
+	}
+
+	// a broken target must not block the rotation of the others
+	var errs []error
+	for _, target := range c.rotatedSelfSignedCertKeySecrets {
+		var targetSecret *corev1.Secret
+		err := runStep(ctx, DefaultOperationTimeout, target.stepName(), func(ctx context.Context) (err error) {
+			targetSecret, err = target.ensureTargetCertKeyPair(ctx, signingCertKeyPair, cabundleCerts)
+			return err
+		})
+		conditions = append(conditions, targetCertValidCondition(c.name, target, targetSecret, err))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return conditions, utilerrors.NewAggregate(errs)
+}
+
+// nextRecheck returns the earliest time the signer or any target reaches its refresh time, 80% of its validity or expiry.
+func (c MultipleTargetsCertRotationController) nextRecheck() (time.Time, bool) {
+	var next time.Time
+	consider := func(annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) {
+		t, ok := nextActionTime(annotations, refresh, refreshOnlyWhenExpired, jitter)
+		if ok && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	signer := c.rotatedSigningCASecret
+	if secret, err := signer.Lister.Secrets(signer.Namespace).Get(signer.Name); err == nil {
+		consider(secret.Annotations, signer.Refresh, signer.RefreshOnlyWhenExpired, refreshJitter(signer.Namespace, signer.Name, signer.RefreshJitter))
+	}
+	for _, target := range c.rotatedSelfSignedCertKeySecrets {
+		if secret, err := target.Lister.Secrets(target.Namespace).Get(target.Name); err == nil {
+			consider(secret.Annotations, target.Refresh, target.RefreshOnlyWhenExpired, refreshJitter(target.Namespace, target.Name, target.RefreshJitter))
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// nextActionTime mirrors the time based checks of needNewSigningCertKeyPair and needNewTargetCertKeyPairForTime
+// and returns when they will first trigger, including the refresh jitter.
+func nextActionTime(annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) (time.Time, bool) {
+	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
+	if len(reason) > 0 {
+		return time.Time{}, false
+	}
+	return refreshTime(notBefore, notAfter, refresh, refreshOnlyWhenExpired, jitter), true
+}
+
+// targetCertRecheckerPostRunHook watches the recheck channels of the signer and all targets in a single goroutine.
+func (c MultipleTargetsCertRotationController) targetCertRecheckerPostRunHook(ctx context.Context, syncCtx factory.SyncContext) error {
+	channels := []<-chan struct{}{c.rotatedSigningCASecret.ConfigChanged}
+	for _, target := range c.rotatedSelfSignedCertKeySecrets {
+		channels = append(channels, recheckChannel(target.CertCreator))
+	}
+	return watchRecheckChannels(ctx, syncCtx, channels...)
+}
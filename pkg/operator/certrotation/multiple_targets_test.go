@@ -0,0 +1,175 @@
+package certrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestNextActionTime(t *testing.T) {
+	notBefore := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	annotations := map[string]string{
+		CertificateNotBeforeAnnotation: notBefore.Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  notBefore.Add(100 * time.Hour).Format(time.RFC3339),
+	}
+	tests := []struct {
+		name                   string
+		annotations            map[string]string
+		refresh                time.Duration
+		refreshOnlyWhenExpired bool
+		jitter                 time.Duration
+		expected               time.Time
+		expectedOK             bool
+	}{
+		{name: "refresh", annotations: annotations, refresh: 10 * time.Hour, expected: notBefore.Add(10 * time.Hour), expectedOK: true},
+		{name: "80% of validity", annotations: annotations, refresh: 90 * time.Hour, expected: notBefore.Add(80 * time.Hour), expectedOK: true},
+		{name: "only when expired", annotations: annotations, refresh: 10 * time.Hour, refreshOnlyWhenExpired: true, expected: notBefore.Add(100 * time.Hour), expectedOK: true},
+		{name: "refresh with jitter", annotations: annotations, refresh: 10 * time.Hour, jitter: 3 * time.Hour, expected: notBefore.Add(13 * time.Hour), expectedOK: true},
+		{name: "80% of validity with capped jitter", annotations: annotations, refresh: 90 * time.Hour, jitter: 30 * time.Hour, expected: notBefore.Add(90 * time.Hour), expectedOK: true},
+		{name: "missing annotations"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok := nextActionTime(test.annotations, test.refresh, test.refreshOnlyWhenExpired, test.jitter)
+			if ok != test.expectedOK || !actual.Equal(test.expected) {
+				t.Errorf("expected %v (%v), got %v (%v)", test.expected, test.expectedOK, actual, ok)
+			}
+		})
+	}
+}
+
+func TestValidateMultipleTargets(t *testing.T) {
+	signer := RotatedSigningCASecret{Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour}
+	tests := []struct {
+		name          string
+		targets       []RotatedSelfSignedCertKeySecret
+		expectedError string
+	}{
+		{
+			name: "targets with different validity",
+			targets: []RotatedSelfSignedCertKeySecret{
+				{Namespace: "ns", Name: "short", Validity: time.Hour, Refresh: 30 * time.Minute},
+				{Namespace: "ns", Name: "long", Validity: 48 * time.Hour, Refresh: 24 * time.Hour},
+				{Namespace: "ns", Name: "expiring", Validity: 24 * time.Hour, RefreshOnlyWhenExpired: true},
+			},
+		},
+		{
+			name:          "target outliving the signer",
+			targets:       []RotatedSelfSignedCertKeySecret{{Namespace: "ns", Name: "target", Validity: 72 * time.Hour, Refresh: 24 * time.Hour}},
+			expectedError: "target ns/target: validity 72h0m0s is longer than the validity 48h0m0s of signer ns/signer",
+		},
+		{
+			name:          "missing refresh",
+			targets:       []RotatedSelfSignedCertKeySecret{{Namespace: "ns", Name: "target", Validity: 24 * time.Hour}},
+			expectedError: "target ns/target: refresh 0s must be positive",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateMultipleTargets(signer, test.targets)
+			switch {
+			case len(test.expectedError) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(test.expectedError) > 0 && (err == nil || err.Error() != test.expectedError):
+				t.Errorf("expected error %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestMultipleTargetsSync(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	recorder := events.NewInMemoryRecorder("test")
+
+	target := func(name string) RotatedSelfSignedCertKeySecret {
+		return RotatedSelfSignedCertKeySecret{
+			Namespace: "ns", Name: name, Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+			CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{name + ".ns.svc"} }},
+			Lister:        corev1listers.NewSecretLister(secrets),
+			Client:        client.CoreV1(),
+			EventRecorder: recorder,
+		}
+	}
+	c := MultipleTargetsCertRotationController{
+		name: "test",
+		rotatedSigningCASecret: RotatedSigningCASecret{
+			Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+			Lister: corev1listers.NewSecretLister(secrets), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		caBundleConfigMap: CABundleConfigMap{
+			Namespace: "ns", Name: "ca-bundle",
+			Lister: corev1listers.NewConfigMapLister(configMaps), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		rotatedSelfSignedCertKeySecrets: []RotatedSelfSignedCertKeySecret{target("foo"), target("bar")},
+		operatorClient:                  v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil),
+		now:                             time.Now,
+	}
+
+	if err := c.Sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	created := map[string]bool{}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "create" {
+			obj, err := meta.Accessor(action.(clienttesting.CreateAction).GetObject())
+			if err != nil {
+				t.Fatal(err)
+			}
+			created[action.GetResource().Resource+"/"+obj.GetName()] = true
+		}
+	}
+	for _, expected := range []string{"secrets/signer", "configmaps/ca-bundle", "secrets/foo", "secrets/bar"} {
+		if !created[expected] {
+			t.Errorf("expected %s to be created, got %v", expected, created)
+		}
+	}
+}
+
+func TestMultipleTargetsRechecker(t *testing.T) {
+	fooChanged := make(chan struct{})
+	barChanged := make(chan struct{})
+	c := MultipleTargetsCertRotationController{
+		rotatedSelfSignedCertKeySecrets: []RotatedSelfSignedCertKeySecret{
+			{CertCreator: &ServingRotation{HostnamesChanged: fooChanged}},
+			{CertCreator: &ServingRotation{HostnamesChanged: barChanged}},
+			{CertCreator: &ClientRotation{}},
+		},
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := c.targetCertRecheckerPostRunHook(ctx, syncCtx); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	barChanged <- struct{}{}
+	key, _ := syncCtx.Queue().Get()
+	if key != factory.DefaultQueueKey {
+		t.Errorf("expected the default queue key, got %v", key)
+	}
+	syncCtx.Queue().Done(key)
+	close(fooChanged)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the rechecker to stop with the context")
+	}
+}
@@ -0,0 +1,70 @@
+package certrotation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// WireInformersForNamespaces sets the informers and listers of the signer, the CA bundle and the targets to the ones
+// of their namespaces, e.g. for a signer in the operator namespace and targets in operand namespaces. It fails if the
+// informers do not cover a namespace.
+func WireInformersForNamespaces(kubeInformers v1helpers.KubeInformersForNamespaces, signer *RotatedSigningCASecret, caBundle *CABundleConfigMap, targets ...*RotatedSelfSignedCertKeySecret) error {
+	namespaces := []string{signer.Namespace, caBundle.Namespace}
+	for _, target := range targets {
+		namespaces = append(namespaces, target.Namespace)
+	}
+	for _, namespace := range namespaces {
+		if !kubeInformers.Namespaces().Has(namespace) {
+			return fmt.Errorf("missing informers for namespace %q", namespace)
+		}
+	}
+
+	signer.Informer = kubeInformers.InformersFor(signer.Namespace).Core().V1().Secrets()
+	signer.Lister = signer.Informer.Lister()
+	caBundle.Informer = kubeInformers.InformersFor(caBundle.Namespace).Core().V1().ConfigMaps()
+	caBundle.Lister = caBundle.Informer.Lister()
+	for _, target := range targets {
+		target.Informer = kubeInformers.InformersFor(target.Namespace).Core().V1().Secrets()
+		target.Lister = target.Informer.Lister()
+	}
+	return nil
+}
+
+// withResourceInformers makes the controller built by the factory sync on events of the signer, the CA bundle and the
+// targets only. Their informers may be shared with other controllers and cover other objects of their namespaces.
+func withResourceInformers(f *factory.Factory, signer RotatedSigningCASecret, caBundle CABundleConfigMap, targets ...RotatedSelfSignedCertKeySecret) *factory.Factory {
+	f = f.WithFilteredEventsInformers(objectFilter(signer.Namespace, signer.Name), signer.Informer.Informer())
+	f = f.WithFilteredEventsInformers(objectFilter(caBundle.Namespace, caBundle.Name), caBundle.Informer.Informer())
+	for _, target := range targets {
+		names := []string{target.Name}
+		if target.KeystorePassphrase != nil {
+			names = append(names, target.KeystorePassphrase.Name)
+		}
+		f = f.WithFilteredEventsInformers(objectFilter(target.Namespace, names...), target.Informer.Informer())
+	}
+	return f
+}
+
+// objectFilter accepts the objects of the given names in the namespace, including tombstones of deleted ones.
+func objectFilter(namespace string, names ...string) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		metaObj, err := meta.Accessor(obj)
+		if err != nil || metaObj.GetNamespace() != namespace {
+			return false
+		}
+		for _, name := range names {
+			if metaObj.GetName() == name {
+				return true
+			}
+		}
+		return false
+	}
+}
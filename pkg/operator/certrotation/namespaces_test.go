@@ -0,0 +1,117 @@
+package certrotation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestMultipleNamespaces(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(client, "operator", "bundle", "operand-a", "operand-b")
+
+	signer := &RotatedSigningCASecret{
+		Namespace: "operator", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+		Client: client.CoreV1(), EventRecorder: recorder,
+	}
+	caBundle := &CABundleConfigMap{Namespace: "bundle", Name: "ca-bundle", Client: client.CoreV1(), EventRecorder: recorder}
+	targets := []RotatedSelfSignedCertKeySecret{
+		{
+			Namespace: "operand-a", Name: "serving", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+			CertCreator: &ServingRotation{Hostnames: func() []string { return []string{"a.svc"} }},
+			Client:      client.CoreV1(), EventRecorder: recorder,
+		},
+		{
+			Namespace: "operand-b", Name: "serving", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+			CertCreator: &ServingRotation{Hostnames: func() []string { return []string{"b.svc"} }},
+			Client:      client.CoreV1(), EventRecorder: recorder,
+		},
+	}
+
+	if err := WireInformersForNamespaces(v1helpers.NewKubeInformersForNamespaces(client, "operator", "bundle"), signer, caBundle, &targets[0], &targets[1]); err == nil || !strings.Contains(err.Error(), "operand-a") {
+		t.Errorf("expected an error about missing informers for operand-a, got %v", err)
+	}
+	if err := WireInformersForNamespaces(kubeInformers, signer, caBundle, &targets[0], &targets[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewManagedResourceRegistry()
+	controller := NewCertRotationControllerMultipleTargets("test", *signer, *caBundle, targets,
+		v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil), recorder,
+		WithMultipleTargetsManagedResourceRegistry(registry))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kubeInformers.Start(ctx.Done())
+	for _, informer := range []cache.SharedIndexInformer{signer.Informer.Informer(), caBundle.Informer.Informer(), targets[0].Informer.Informer(), targets[1].Informer.Informer()} {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			t.Fatal("informers did not sync")
+		}
+	}
+
+	if err := controller.Sync(ctx, factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Secrets("operator").Get(ctx, "signer", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the signer in the operator namespace: %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("bundle").Get(ctx, "ca-bundle", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the CA bundle in the bundle namespace: %v", err)
+	}
+	for _, namespace := range []string{"operand-a", "operand-b"} {
+		if _, err := client.CoreV1().Secrets(namespace).Get(ctx, "serving", metav1.GetOptions{}); err != nil {
+			t.Errorf("expected the target in the %s namespace: %v", namespace, err)
+		}
+	}
+
+	go controller.Run(ctx, 1)
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return len(registry.List()) == 4, nil
+	}); err != nil {
+		t.Errorf("expected the signer, the CA bundle and the targets in the scoped registry, got %v", registry.List())
+	}
+	for _, resource := range DefaultManagedResourceRegistry.List() {
+		if resource.Controller == "test" {
+			t.Errorf("expected no resources in the default registry, got %v", resource)
+		}
+	}
+}
+
+func TestObjectFilter(t *testing.T) {
+	filter := objectFilter("operand", "serving", "passphrase")
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	tests := []struct {
+		name     string
+		obj      interface{}
+		expected bool
+	}{
+		{name: "target", obj: secret("operand", "serving"), expected: true},
+		{name: "second name", obj: secret("operand", "passphrase"), expected: true},
+		{name: "tombstone", obj: cache.DeletedFinalStateUnknown{Key: "operand/serving", Obj: secret("operand", "serving")}, expected: true},
+		{name: "other secret of the namespace", obj: secret("operand", "other")},
+		{name: "same name in another namespace", obj: secret("operator", "serving")},
+		{name: "not an object", obj: "operand/serving"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := filter(test.obj); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
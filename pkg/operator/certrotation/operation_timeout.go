@@ -0,0 +1,59 @@
+package certrotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultOperationTimeout bounds each step of a rotation, i.e. ensuring the signer, the CA bundle or a target, so
+// that a slow apiserver fails the sync with an error naming the step instead of blocking the controller.
+const DefaultOperationTimeout = time.Minute
+
+// WithOperationTimeout sets the timeout of each rotation step. Zero disables the timeouts.
+func WithOperationTimeout(timeout time.Duration) CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.operationTimeout = &timeout
+	}
+}
+
+// runStep runs one rotation step with the timeout. A step is not started if the context is done already, and errors
+// caused by the timeout or the cancellation of the context name the step.
+func runStep(ctx context.Context, timeout time.Duration, step string, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s not started: %w", step, err)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := fn(ctx)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s timed out after %s: %w", step, timeout, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%s cancelled: %w", step, err)
+	}
+	return err
+}
+
+func (c RotatedSigningCASecret) stepName() string {
+	return fmt.Sprintf("ensuring signer secret %s/%s", c.Namespace, c.Name)
+}
+
+func (c CABundleConfigMap) stepName() string {
+	return fmt.Sprintf("ensuring CA bundle configmap %s/%s", c.Namespace, c.Name)
+}
+
+func (c RotatedSelfSignedCertKeySecret) stepName() string {
+	return fmt.Sprintf("ensuring target secret %s/%s", c.Namespace, c.Name)
+}
+
+func (c *RotationTimelineConfigMap) stepName() string {
+	return fmt.Sprintf("ensuring rotation timeline configmap %s/%s", c.Namespace, c.Name)
+}
@@ -0,0 +1,45 @@
+package certrotation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStep(t *testing.T) {
+	blocking := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	err := runStep(context.Background(), 10*time.Millisecond, "ensuring signer secret ns/signer", blocking)
+	if err == nil || !strings.Contains(err.Error(), "ensuring signer secret ns/signer timed out after 10ms") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout error naming the step, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err = runStep(ctx, time.Minute, "ensuring target secret ns/target", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Errorf("expected the step not to run with a done context")
+	}
+	if err == nil || !strings.Contains(err.Error(), "ensuring target secret ns/target not started") || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected not started error naming the step, got %v", err)
+	}
+
+	stepErr := errors.New("conflict")
+	if err := runStep(context.Background(), 0, "step", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); ok {
+			t.Errorf("expected no deadline for zero timeout")
+		}
+		return stepErr
+	}); err != stepErr {
+		t.Errorf("expected the step error unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package certrotation
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// WithOrphanedResourceDeletion labels the signer, the CA bundle and the target with ManagedByControllerLabelName and
+// deletes the secrets and config maps in their namespaces which carry the label of the controller but are not managed
+// by it anymore, e.g. after the target was renamed. The label value is derived from the operator name and the
+// controller name, so it must be the same for every run of the operator and unique among the operators sharing a
+// namespace. Objects are labeled when they are written, objects not written
+// since the option was enabled are never deleted. Neither are objects registered by another running controller in the
+// managed resource registry.
+func WithOrphanedResourceDeletion(operatorName string) CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.orphanedResourceDeletion = true
+		c.controllerLabel = managedByControllerLabelValue(operatorName, c.name)
+		c.rotatedSigningCASecret.controllerLabel = c.controllerLabel
+		c.CABundleConfigMap.controllerLabel = c.controllerLabel
+		c.RotatedSelfSignedCertKeySecret.controllerLabel = c.controllerLabel
+	}
+}
+
+func (c CertRotationController) orphanedResourcesStepName() string {
+	return fmt.Sprintf("deleting orphaned resources of %s", c.name)
+}
+
+// deleteOrphanedResources deletes the objects labeled as written by the controller that are neither managed by it
+// nor registered by another controller.
+func (c CertRotationController) deleteOrphanedResources(ctx context.Context) error {
+	registry := c.registry
+	if registry == nil {
+		registry = DefaultManagedResourceRegistry
+	}
+	keep := sets.NewString()
+	for _, resource := range append(c.managedResources(), registry.List()...) {
+		keep.Insert(fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name))
+	}
+	isOrphan := func(kind ManagedResourceKind, obj metav1.Object) bool {
+		return !keep.Has(fmt.Sprintf("%s/%s/%s", kind, obj.GetNamespace(), obj.GetName()))
+	}
+	selector := labels.SelectorFromSet(labels.Set{ManagedByControllerLabelName: c.controllerLabel})
+	recorder := c.RotatedSelfSignedCertKeySecret.EventRecorder
+
+	var errs []error
+	deleteSecrets := func(lister corev1listers.SecretLister, client corev1client.SecretsGetter, namespace string) {
+		secrets, err := lister.Secrets(namespace).List(selector)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		for _, secret := range secrets {
+			if !isOrphan(ManagedResourceKindSecret, secret) {
+				continue
+			}
+			err := client.Secrets(namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{Preconditions: uidPrecondition(secret.UID)})
+			switch {
+			case errors.IsNotFound(err) || errors.IsConflict(err):
+				// deleted or replaced in the meantime
+			case err != nil:
+				errs = append(errs, err)
+			default:
+				recorder.Eventf("OrphanedSecretDeleted", "Deleted Secret/%s -n %s which is not managed by %s anymore", secret.Name, namespace, c.name)
+			}
+		}
+	}
+	signer, target := c.rotatedSigningCASecret, c.RotatedSelfSignedCertKeySecret
+	deleteSecrets(target.Lister, target.Client, target.Namespace)
+	if signer.Namespace != target.Namespace {
+		deleteSecrets(signer.Lister, signer.Client, signer.Namespace)
+	}
+
+	caBundle := c.CABundleConfigMap
+	configMaps, err := caBundle.Lister.ConfigMaps(caBundle.Namespace).List(selector)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, configMap := range configMaps {
+		if !isOrphan(ManagedResourceKindConfigMap, configMap) {
+			continue
+		}
+		err := caBundle.Client.ConfigMaps(caBundle.Namespace).Delete(ctx, configMap.Name, metav1.DeleteOptions{Preconditions: uidPrecondition(configMap.UID)})
+		switch {
+		case errors.IsNotFound(err) || errors.IsConflict(err):
+			// deleted or replaced in the meantime
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			recorder.Eventf("OrphanedConfigMapDeleted", "Deleted ConfigMap/%s -n %s which is not managed by %s anymore", configMap.Name, caBundle.Namespace, c.name)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func uidPrecondition(uid types.UID) *metav1.Preconditions {
+	return &metav1.Preconditions{UID: &uid}
+}
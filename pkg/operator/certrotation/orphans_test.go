@@ -0,0 +1,102 @@
+package certrotation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestOrphanedResourceDeletion(t *testing.T) {
+	labeled := func(operator, controller string) map[string]string {
+		return map[string]string{ManagedByControllerLabelName: managedByControllerLabelValue(operator, controller)}
+	}
+	client := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "renamed-target", Labels: labeled("operator", "test")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unlabeled"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other-controller", Labels: labeled("operator", "other")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other-operator", Labels: labeled("other-operator", "test")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "registered", Labels: labeled("operator", "test")}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "renamed-bundle", Labels: labeled("operator", "test")}},
+	)
+	recorder := events.NewInMemoryRecorder("test")
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(client, "ns")
+	signer := &RotatedSigningCASecret{
+		Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+		Client: client.CoreV1(), EventRecorder: recorder,
+	}
+	caBundle := &CABundleConfigMap{Namespace: "ns", Name: "ca-bundle", Client: client.CoreV1(), EventRecorder: recorder}
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace: "ns", Name: "target", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+		CertCreator: &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+		Client:      client.CoreV1(), EventRecorder: recorder,
+	}
+	if err := WireInformersForNamespaces(kubeInformers, signer, caBundle, target); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewManagedResourceRegistry()
+	registry.Register(ManagedResource{Controller: "other", Kind: ManagedResourceKindSecret, Namespace: "ns", Name: "registered"})
+
+	controller := NewCertRotationController("test", *signer, *caBundle, *target,
+		v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil), recorder,
+		WithManagedResourceRegistry(registry), WithOrphanedResourceDeletion("operator"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kubeInformers.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), signer.Informer.Informer().HasSynced, caBundle.Informer.Informer().HasSynced) {
+		t.Fatal("informers did not sync")
+	}
+	if err := controller.Sync(ctx, factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"signer", "target"} {
+		secret, err := client.CoreV1().Secrets("ns").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret.Labels[ManagedByControllerLabelName] != managedByControllerLabelValue("operator", "test") {
+			t.Errorf("expected %s to be labeled with the controller, got %v", name, secret.Labels)
+		}
+	}
+	for _, name := range []string{"unlabeled", "other-controller", "other-operator", "registered"} {
+		if _, err := client.CoreV1().Secrets("ns").Get(ctx, name, metav1.GetOptions{}); err != nil {
+			t.Errorf("expected secret %s to be kept: %v", name, err)
+		}
+	}
+	if _, err := client.CoreV1().Secrets("ns").Get(ctx, "renamed-target", metav1.GetOptions{}); err == nil {
+		t.Error("expected the orphaned secret to be deleted")
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "renamed-bundle", metav1.GetOptions{}); err == nil {
+		t.Error("expected the orphaned config map to be deleted")
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "ca-bundle", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the CA bundle to be kept: %v", err)
+	}
+}
+
+func TestManagedByControllerLabelValue(t *testing.T) {
+	long := strings.Repeat("very-long-controller-name ", 5)
+	value := managedByControllerLabelValue("operator", long)
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		t.Errorf("expected a valid label value, got %q: %v", value, errs)
+	}
+	if value != managedByControllerLabelValue("operator", long) {
+		t.Error("expected the value to be stable")
+	}
+	if managedByControllerLabelValue("operator", "test") == managedByControllerLabelValue("other-operator", "test") {
+		t.Error("expected controllers of different operators to get different values")
+	}
+}
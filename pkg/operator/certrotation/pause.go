@@ -0,0 +1,51 @@
+package certrotation
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/reasons"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// RotationPausedAnnotation set to "true" on the signing CA secret makes the cert rotation controllers of the signing
+// CA skip the rotation of the signing CA, the CA bundle and the targets, e.g. during a maintenance window. The
+// controllers report the pause in their CertRotationDegraded condition, so that it is not forgotten while
+// certificates approach their expiry. Removing the annotation resumes the rotation.
+const RotationPausedAnnotation = "certrotation.openshift.io/paused"
+
+// rotationPaused returns true if the signing CA secret has the RotationPausedAnnotation.
+func (c RotatedSigningCASecret) rotationPaused(ctx context.Context) (bool, error) {
+	secret, err := v1helpers.GetSecret(ctx, c.Lister, c.liveReadClient(), c.Namespace, c.Name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return secret.Annotations[RotationPausedAnnotation] == "true", nil
+}
+
+// reportRotationPaused sets the CertRotationDegraded condition of the named controller to report the pause.
+func reportRotationPaused(ctx context.Context, syncCtx factory.SyncContext, name string, operatorClient v1helpers.StaticPodOperatorClient, signer RotatedSigningCASecret) error {
+	isRunOnce, ok := ctx.Value(RunOnceContextKey).(bool)
+	if ok && isRunOnce {
+		return nil
+	}
+
+	newCondition := reasons.NewCondition(fmt.Sprintf(condition.CertRotationDegradedConditionTypeFmt, name), operatorv1.ConditionTrue, reasons.Paused,
+		"Rotation is paused by the %s annotation of secret %s/%s. Certificates are not renewed until it is removed.", RotationPausedAnnotation, signer.Namespace, signer.Name)
+	_, updated, err := v1helpers.UpdateStaticPodStatus(ctx, operatorClient, v1helpers.UpdateStaticPodConditionFn(newCondition))
+	if err != nil {
+		return err
+	}
+	if updated {
+		syncCtx.Recorder().Warningf(string(reasons.Paused), newCondition.Message)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package certrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestRotationPaused(t *testing.T) {
+	signerSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "signer", Annotations: map[string]string{RotationPausedAnnotation: "true"}},
+		Type:       corev1.SecretTypeTLS,
+	}
+	client := kubefake.NewSimpleClientset(signerSecret)
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secrets.Add(signerSecret)
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	recorder := events.NewInMemoryRecorder("test")
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+
+	c := CertRotationController{
+		name: "Test",
+		rotatedSigningCASecret: RotatedSigningCASecret{
+			Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+			Lister: corev1listers.NewSecretLister(secrets), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		CABundleConfigMap: CABundleConfigMap{
+			Namespace: "ns", Name: "ca-bundle",
+			Lister: corev1listers.NewConfigMapLister(configMaps), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		RotatedSelfSignedCertKeySecret: RotatedSelfSignedCertKeySecret{
+			Namespace: "ns", Name: "target", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+			CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+			Lister:        corev1listers.NewSecretLister(secrets),
+			Client:        client.CoreV1(),
+			EventRecorder: recorder,
+		},
+		OperatorClient: operatorClient,
+	}
+	degraded := func() *operatorv1.OperatorCondition {
+		_, status, _, _ := operatorClient.GetStaticPodOperatorState()
+		return v1helpers.FindOperatorCondition(status.Conditions, "CertRotation_Test_Degraded")
+	}
+
+	client.ClearActions()
+	if err := c.Sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Errorf("expected no rotation while paused, got %v", actions)
+	}
+	if condition := degraded(); condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "Paused" {
+		t.Errorf("expected the pause to be reported, got %v", condition)
+	}
+
+	// removing the annotation resumes the rotation
+	resumed := signerSecret.DeepCopy()
+	resumed.Annotations = nil
+	secrets.Update(resumed)
+	if err := c.Sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Actions()) == 0 {
+		t.Error("expected the rotation to resume")
+	}
+	if condition := degraded(); condition == nil || condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected the condition to be cleared, got %v", condition)
+	}
+}
@@ -0,0 +1,321 @@
+package certrotation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"hash"
+	"unicode/utf16"
+)
+
+// The PKCS#12 keystore follows the defaults of OpenSSL 3: the private key is encrypted with PBES2 (PBKDF2 with
+// HMAC-SHA256 and AES-256-CBC), the certificates are not encrypted and the integrity is protected by an HMAC-SHA256.
+// Java reads such keystores since 8u301 and 11.0.12.
+
+var (
+	oidPKCS7Data            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidCertBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS8ShroudedKeyBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidX509Certificate      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBES2                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	pkcs12Iterations        = 2048
+	pkcs12SaltLength        = 16
+	pkcs12KeystoreAlias     = "tls"
+	pkcs12MACKeyDiversifier = byte(3)
+)
+
+type pkcs12PFX struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set"`
+}
+
+type pkcs12Attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data asn1.RawValue
+}
+
+type pkcs12AlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs12EncryptedPrivateKeyInfo struct {
+	Algorithm     pkcs12AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pkcs12PBES2Params struct {
+	KeyDerivationFunc pkcs12AlgorithmIdentifier
+	EncryptionScheme  pkcs12AlgorithmIdentifier
+}
+
+type pkcs12PBKDF2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int
+	PRF        pkcs12AlgorithmIdentifier
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkcs12AlgorithmIdentifier
+	Digest    []byte
+}
+
+// encodePKCS12Keystore encodes the private key and the certificate chain, the leaf first, as PKCS#12 keystore with
+// the alias "tls", protected by the passphrase.
+func encodePKCS12Keystore(privateKey interface{}, certificates []*x509.Certificate, passphrase string) ([]byte, error) {
+	localKeyID := sha1.Sum(certificates[0].Raw)
+	leafAttributes, err := pkcs12BagAttributes(localKeyID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var certBags []pkcs12SafeBag
+	for i, certificate := range certificates {
+		certBag, err := asn1.Marshal(pkcs12CertBag{ID: oidX509Certificate, Data: explicitTag(mustMarshal(certificate.Raw))})
+		if err != nil {
+			return nil, err
+		}
+		bag := pkcs12SafeBag{ID: oidCertBag, Value: explicitTag(certBag)}
+		if i == 0 {
+			bag.Attributes = leafAttributes
+		}
+		certBags = append(certBags, bag)
+	}
+
+	encryptedKey, err := encryptPKCS8PrivateKey(privateKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	keyBags := []pkcs12SafeBag{{ID: oidPKCS8ShroudedKeyBag, Value: explicitTag(encryptedKey), Attributes: leafAttributes}}
+
+	var authenticatedSafe []pkcs12ContentInfo
+	for _, bags := range [][]pkcs12SafeBag{certBags, keyBags} {
+		safeContents, err := asn1.Marshal(bags)
+		if err != nil {
+			return nil, err
+		}
+		authenticatedSafe = append(authenticatedSafe, pkcs12ContentInfo{ContentType: oidPKCS7Data, Content: explicitTag(mustMarshal(safeContents))})
+	}
+	authenticatedSafeBytes, err := asn1.Marshal(authenticatedSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt := make([]byte, pkcs12SaltLength)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, err
+	}
+	macKey := pkcs12KDF(sha256.New, pkcs12MACKeyDiversifier, bmpPassphrase(passphrase), macSalt, pkcs12Iterations, sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authenticatedSafeBytes)
+
+	return asn1.Marshal(pkcs12PFX{
+		Version:  3,
+		AuthSafe: pkcs12ContentInfo{ContentType: oidPKCS7Data, Content: explicitTag(mustMarshal(authenticatedSafeBytes))},
+		MacData: pkcs12MacData{
+			Mac:        pkcs12DigestInfo{Algorithm: pkcs12AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}, Digest: mac.Sum(nil)},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// pkcs12BagAttributes returns the local key id, which pairs the key with its certificate, and the alias.
+func pkcs12BagAttributes(localKeyID []byte) ([]pkcs12Attribute, error) {
+	alias := []byte{}
+	for _, c := range utf16.Encode([]rune(pkcs12KeystoreAlias)) {
+		alias = append(alias, byte(c>>8), byte(c))
+	}
+	friendlyName, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: alias})
+	if err != nil {
+		return nil, err
+	}
+	return []pkcs12Attribute{
+		{ID: oidFriendlyName, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: friendlyName}},
+		{ID: oidLocalKeyID, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(localKeyID)}},
+	}, nil
+}
+
+// encryptPKCS8PrivateKey returns the EncryptedPrivateKeyInfo of the key, encrypted with PBES2.
+func encryptPKCS8PrivateKey(privateKey interface{}, passphrase string) ([]byte, error) {
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, pkcs12SaltLength)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	key := pbkdf2SHA256([]byte(passphrase), salt, pkcs12Iterations, 32)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(keyBytes)%aes.BlockSize
+	for i := 0; i < padding; i++ {
+		keyBytes = append(keyBytes, byte(padding))
+	}
+	encrypted := make([]byte, len(keyBytes))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, keyBytes)
+
+	kdfParams, err := asn1.Marshal(pkcs12PBKDF2Params{
+		Salt:       salt,
+		Iterations: pkcs12Iterations,
+		KeyLength:  32,
+		PRF:        pkcs12AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	pbes2Params, err := asn1.Marshal(pkcs12PBES2Params{
+		KeyDerivationFunc: pkcs12AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkcs12AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: mustMarshal(iv)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs12EncryptedPrivateKeyInfo{
+		Algorithm:     pkcs12AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2Params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// pbkdf2SHA256 derives a key from the password as specified in RFC 8018, section 5.2.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLength; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		blockIndex := make([]byte, 4)
+		binary.BigEndian.PutUint32(blockIndex, block)
+		prf.Write(blockIndex)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLength]
+}
+
+// pkcs12KDF derives key material from the password as specified in RFC 7292, appendix B.2.
+func pkcs12KDF(newHash func() hash.Hash, id byte, password, salt []byte, iterations, size int) []byte {
+	h := newHash()
+	u, v := h.Size(), h.BlockSize()
+
+	d := make([]byte, v)
+	for i := range d {
+		d[i] = id
+	}
+	fill := func(data []byte) []byte {
+		if len(data) == 0 {
+			return nil
+		}
+		filled := make([]byte, v*((len(data)+v-1)/v))
+		for i := range filled {
+			filled[i] = data[i%len(data)]
+		}
+		return filled
+	}
+	i := append(fill(salt), fill(password)...)
+
+	var key []byte
+	for len(key) < size {
+		h.Reset()
+		h.Write(d)
+		h.Write(i)
+		a := h.Sum(nil)
+		for j := 1; j < iterations; j++ {
+			h.Reset()
+			h.Write(a)
+			a = h.Sum(a[:0])
+		}
+		key = append(key, a...)
+
+		// I_j = (I_j + B + 1) mod 2^(v*8) for every block I_j of I, B being A repeated to v bytes
+		b := make([]byte, v)
+		for j := range b {
+			b[j] = a[j%u]
+		}
+		for j := 0; j < len(i); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(i[j+k]) + int(b[k]) + carry
+				i[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return key[:size]
+}
+
+// bmpPassphrase returns the passphrase as null terminated BMPString, the password format of the PKCS#12 KDF.
+func bmpPassphrase(passphrase string) []byte {
+	var bmp []byte
+	for _, c := range utf16.Encode([]rune(passphrase)) {
+		bmp = append(bmp, byte(c>>8), byte(c))
+	}
+	return append(bmp, 0, 0)
+}
+
+// explicitTag wraps the DER encoded value in the [0] EXPLICIT tag of the PKCS#12 structures.
+func explicitTag(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// mustMarshal encodes a byte slice, which cannot fail, as OCTET STRING.
+func mustMarshal(octets []byte) []byte {
+	der, err := asn1.Marshal(octets)
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
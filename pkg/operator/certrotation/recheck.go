@@ -0,0 +1,81 @@
+package certrotation
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// RecheckNotifier triggers a sync of the cert rotation controllers watching its channels, for changes the informers
+// do not observe, e.g. of the user of a ClientRotation or the configuration of a signing CA. It can be embedded in a
+// TargetCertCreator to implement TargetCertRechecker. Every channel returned by RecheckChannel receives the
+// notifications, so a notifier can be shared by several controllers.
+type RecheckNotifier struct {
+	lock        sync.Mutex
+	subscribers []chan struct{}
+}
+
+var _ TargetCertRechecker = &RecheckNotifier{}
+
+func NewRecheckNotifier() *RecheckNotifier {
+	return &RecheckNotifier{}
+}
+
+// Notify requests a sync from every subscriber. It does not block, notifications while a sync of the subscriber is
+// pending are coalesced.
+func (n *RecheckNotifier) Notify() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, ch := range n.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// RecheckChannel returns a new channel receiving the notifications. Watchers call it once, when they start.
+func (n *RecheckNotifier) RecheckChannel() <-chan struct{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	ch := make(chan struct{}, 1)
+	n.subscribers = append(n.subscribers, ch)
+	return ch
+}
+
+// watchRecheckChannels queues a sync whenever one of the channels receives, until the context is done. Nil channels
+// are ignored, closed channels are not watched anymore.
+func watchRecheckChannels(ctx context.Context, syncCtx factory.SyncContext, channels ...<-chan struct{}) error {
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}}
+	for _, ch := range channels {
+		if ch != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+		}
+	}
+
+	for len(cases) > 1 {
+		chosen, _, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return nil
+		}
+		if !ok {
+			// the channel was closed, stop watching it
+			cases = append(cases[:chosen], cases[chosen+1:]...)
+			continue
+		}
+		syncCtx.Queue().Add(factory.DefaultQueueKey)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// recheckChannel returns the recheck channel of the cert creator, nil if it does not implement TargetCertRechecker.
+func recheckChannel(certCreator TargetCertCreator) <-chan struct{} {
+	if rechecker, ok := certCreator.(TargetCertRechecker); ok {
+		return rechecker.RecheckChannel()
+	}
+	return nil
+}
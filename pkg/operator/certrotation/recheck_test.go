@@ -0,0 +1,120 @@
+package certrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRecheckNotifier(t *testing.T) {
+	signerNotifier, userNotifier := NewRecheckNotifier(), NewRecheckNotifier()
+	c := CertRotationController{
+		rotatedSigningCASecret:         RotatedSigningCASecret{ConfigChanged: signerNotifier.RecheckChannel()},
+		RotatedSelfSignedCertKeySecret: RotatedSelfSignedCertKeySecret{CertCreator: &ClientRotation{UserInfoChanged: userNotifier.RecheckChannel()}},
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- c.targetCertRecheckerPostRunHook(ctx, syncCtx)
+	}()
+
+	for _, notifier := range []*RecheckNotifier{signerNotifier, userNotifier} {
+		// notifications are coalesced and never block
+		notifier.Notify()
+		notifier.Notify()
+		if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			return syncCtx.Queue().Len() == 1, nil
+		}); err != nil {
+			t.Fatalf("expected a queued sync, got %d", syncCtx.Queue().Len())
+		}
+		key, _ := syncCtx.Queue().Get()
+		syncCtx.Queue().Done(key)
+		syncCtx.Queue().Forget(key)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecheckNotifierFanOut(t *testing.T) {
+	notifier := NewRecheckNotifier()
+	first, second := notifier.RecheckChannel(), notifier.RecheckChannel()
+	notifier.Notify()
+	notifier.Notify()
+	for i, ch := range []<-chan struct{}{first, second} {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("expected subscriber %d to be notified", i)
+		}
+		select {
+		case <-ch:
+			t.Errorf("expected the notifications of subscriber %d to be coalesced", i)
+		default:
+		}
+	}
+}
+
+func TestClientRotationUserChange(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	creator := &ClientRotation{UserInfo: &user.DefaultInfo{Name: "foo", Groups: []string{"system:masters", "a"}}}
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace:     "ns",
+		Name:          "client",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		CertCreator:   creator,
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	ensure := func() bool {
+		t.Helper()
+		client.ClearActions()
+		if _, err := target.ensureTargetCertKeyPair(context.TODO(), signer, signer.Config.Certs); err != nil {
+			t.Fatal(err)
+		}
+		written := lastWrittenSecret(client)
+		if written != nil {
+			indexer.Add(written)
+		}
+		return written != nil
+	}
+
+	if !ensure() {
+		t.Fatal("expected the client certificate to be created")
+	}
+	// the order of the groups does not matter
+	creator.UserInfo = &user.DefaultInfo{Name: "foo", Groups: []string{"a", "system:masters"}}
+	if ensure() {
+		t.Errorf("expected no rotation for the same user, got %v", client.Actions())
+	}
+	creator.UserInfo = &user.DefaultInfo{Name: "foo", Groups: []string{"a"}}
+	if !ensure() {
+		t.Error("expected a rotation for changed groups")
+	}
+	creator.UserInfo = &user.DefaultInfo{Name: "bar", Groups: []string{"a"}}
+	if !ensure() {
+		t.Error("expected a rotation for a changed user")
+	}
+}
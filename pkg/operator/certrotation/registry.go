@@ -0,0 +1,230 @@
+package certrotation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// ManagedResourceKind is the kind of object holding managed certificates.
+type ManagedResourceKind string
+
+const (
+	ManagedResourceKindSecret    ManagedResourceKind = "Secret"
+	ManagedResourceKindConfigMap ManagedResourceKind = "ConfigMap"
+)
+
+// ManagedResource is a secret or a config map maintained by a cert rotation controller.
+type ManagedResource struct {
+	// Controller is the name of the cert rotation controller managing the resource.
+	Controller      string
+	Kind            ManagedResourceKind
+	Namespace       string
+	Name            string
+	CertificateType CertificateType
+
+	// notAfter returns the time the earliest certificate in the resource expires.
+	notAfter func() (time.Time, error)
+}
+
+// NotAfter returns the time the earliest certificate stored in the resource expires.
+func (r ManagedResource) NotAfter() (time.Time, error) {
+	if r.notAfter == nil {
+		return time.Time{}, fmt.Errorf("expiry of %s %s/%s is unknown", r.Kind, r.Namespace, r.Name)
+	}
+	return r.notAfter()
+}
+
+func (r ManagedResource) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", r.Controller, r.Kind, r.Namespace, r.Name)
+}
+
+// ManagedResourceRegistry tracks all resources managed by cert rotation controllers.
+// Consumers like status reporters, metrics or related objects can query it instead of tracking the resources
+// on their own.
+type ManagedResourceRegistry struct {
+	lock      sync.RWMutex
+	resources map[string]ManagedResource
+}
+
+// DefaultManagedResourceRegistry is the process-wide registry every cert rotation controller registers its resources in
+// while it runs, unless configured otherwise with WithManagedResourceRegistry.
+var DefaultManagedResourceRegistry = NewManagedResourceRegistry()
+
+// WithManagedResourceRegistry registers the managed resources of the CertRotationController in the given registry
+// instead of DefaultManagedResourceRegistry, e.g. to scope them to an operator or a test.
+func WithManagedResourceRegistry(registry *ManagedResourceRegistry) CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.registry = registry
+	}
+}
+
+func NewManagedResourceRegistry() *ManagedResourceRegistry {
+	return &ManagedResourceRegistry{resources: map[string]ManagedResource{}}
+}
+
+// Register adds resources to the registry. Registering a resource again replaces the previous registration.
+func (r *ManagedResourceRegistry) Register(resources ...ManagedResource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, resource := range resources {
+		r.resources[resource.key()] = resource
+	}
+}
+
+// Unregister removes resources from the registry. Unknown resources are ignored.
+func (r *ManagedResourceRegistry) Unregister(resources ...ManagedResource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, resource := range resources {
+		delete(r.resources, resource.key())
+	}
+}
+
+// registerWhileRunning returns a post start hook registering the resources until the controller stops.
+func (r *ManagedResourceRegistry) registerWhileRunning(resources []ManagedResource) factory.PostStartHook {
+	return func(ctx context.Context, _ factory.SyncContext) error {
+		r.Register(resources...)
+		defer r.Unregister(resources...)
+		<-ctx.Done()
+		return nil
+	}
+}
+
+// List returns all registered resources sorted by namespace, name, kind and controller.
+func (r *ManagedResourceRegistry) List() []ManagedResource {
+	return r.filter(func(ManagedResource) bool { return true })
+}
+
+// ListByNamespace returns the registered resources in the given namespace.
+func (r *ManagedResourceRegistry) ListByNamespace(namespace string) []ManagedResource {
+	return r.filter(func(resource ManagedResource) bool { return resource.Namespace == namespace })
+}
+
+// ListByController returns the resources registered by the given controller.
+func (r *ManagedResourceRegistry) ListByController(controller string) []ManagedResource {
+	return r.filter(func(resource ManagedResource) bool { return resource.Controller == controller })
+}
+
+// ListExpiringBefore returns the resources holding a certificate that expires before the given time, soonest first.
+// Resources which expiry cannot be determined yet, e.g. because they were not created yet, are skipped.
+func (r *ManagedResourceRegistry) ListExpiringBefore(deadline time.Time) []ManagedResource {
+	expiries := map[string]time.Time{}
+	ret := r.filter(func(resource ManagedResource) bool {
+		notAfter, err := resource.NotAfter()
+		if err != nil || !notAfter.Before(deadline) {
+			return false
+		}
+		expiries[resource.key()] = notAfter
+		return true
+	})
+	sort.SliceStable(ret, func(i, j int) bool {
+		return expiries[ret[i].key()].Before(expiries[ret[j].key()])
+	})
+	return ret
+}
+
+func (r *ManagedResourceRegistry) filter(include func(ManagedResource) bool) []ManagedResource {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	ret := []ManagedResource{}
+	for _, resource := range r.resources {
+		if include(resource) {
+			ret = append(ret, resource)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Namespace != ret[j].Namespace {
+			return ret[i].Namespace < ret[j].Namespace
+		}
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		if ret[i].Kind != ret[j].Kind {
+			return ret[i].Kind < ret[j].Kind
+		}
+		return ret[i].Controller < ret[j].Controller
+	})
+	return ret
+}
+
+// managedResources returns the resources maintained by the cert rotation controller.
+func (c CertRotationController) managedResources() []ManagedResource {
+	return managedResourcesFor(c.name, c.rotatedSigningCASecret, c.CABundleConfigMap, c.RotatedSelfSignedCertKeySecret)
+}
+
+// managedResourcesFor returns the resources maintained by a cert rotation controller with the given signer, CA bundle and targets.
+func managedResourcesFor(controller string, signer RotatedSigningCASecret, caBundle CABundleConfigMap, targets ...RotatedSelfSignedCertKeySecret) []ManagedResource {
+	ret := []ManagedResource{
+		{
+			Controller:      controller,
+			Kind:            ManagedResourceKindSecret,
+			Namespace:       signer.Namespace,
+			Name:            signer.Name,
+			CertificateType: CertificateTypeSigner,
+			notAfter: func() (time.Time, error) {
+				secret, err := signer.Lister.Secrets(signer.Namespace).Get(signer.Name)
+				if err != nil {
+					return time.Time{}, err
+				}
+				return notAfterFromAnnotations(secret.Annotations)
+			},
+		},
+		{
+			Controller:      controller,
+			Kind:            ManagedResourceKindConfigMap,
+			Namespace:       caBundle.Namespace,
+			Name:            caBundle.Name,
+			CertificateType: CertificateTypeCABundle,
+			notAfter: func() (time.Time, error) {
+				configMap, err := caBundle.Lister.ConfigMaps(caBundle.Namespace).Get(caBundle.Name)
+				if err != nil {
+					return time.Time{}, err
+				}
+				certificates, err := cert.ParseCertsPEM([]byte(configMap.Data["ca-bundle.crt"]))
+				if err != nil {
+					return time.Time{}, err
+				}
+				notAfter := certificates[0].NotAfter
+				for _, certificate := range certificates[1:] {
+					if certificate.NotAfter.Before(notAfter) {
+						notAfter = certificate.NotAfter
+					}
+				}
+				return notAfter, nil
+			},
+		},
+	}
+	for i := range targets {
+		target := targets[i]
+		ret = append(ret, ManagedResource{
+			Controller:      controller,
+			Kind:            ManagedResourceKindSecret,
+			Namespace:       target.Namespace,
+			Name:            target.Name,
+			CertificateType: CertificateTypeTarget,
+			notAfter: func() (time.Time, error) {
+				secret, err := target.Lister.Secrets(target.Namespace).Get(target.Name)
+				if err != nil {
+					return time.Time{}, err
+				}
+				return notAfterFromAnnotations(secret.Annotations)
+			},
+		})
+	}
+	return ret
+}
+
+func notAfterFromAnnotations(annotations map[string]string) (time.Time, error) {
+	_, notAfter, reason := getValidityFromAnnotations(annotations)
+	if len(reason) > 0 {
+		return time.Time{}, fmt.Errorf("%s", reason)
+	}
+	return notAfter, nil
+}
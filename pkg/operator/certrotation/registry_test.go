@@ -0,0 +1,113 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func TestManagedResourceRegistry(t *testing.T) {
+	now := time.Now()
+	ca, err := newTestCACertificate(pkix.Name{CommonName: "signer-tests"}, int64(1), metav1.Duration{Duration: time.Hour * 24}, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caBundle, err := crypto.EncodeCertificates(ca.Config.Certs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, secret := range []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "signer", Annotations: map[string]string{
+			CertificateNotBeforeAnnotation: now.Add(-time.Hour).Format(time.RFC3339),
+			CertificateNotAfterAnnotation:  now.Add(48 * time.Hour).Format(time.RFC3339),
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-b", Name: "target", Annotations: map[string]string{
+			CertificateNotBeforeAnnotation: now.Add(-time.Hour).Format(time.RFC3339),
+			CertificateNotAfterAnnotation:  now.Add(2 * time.Hour).Format(time.RFC3339),
+		}}},
+	} {
+		if err := secrets.Add(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := configMaps.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "ca-bundle"},
+		Data:       map[string]string{"ca-bundle.crt": string(caBundle)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	controller := CertRotationController{
+		name:                           "first",
+		rotatedSigningCASecret:         RotatedSigningCASecret{Namespace: "ns-a", Name: "signer", Lister: corev1listers.NewSecretLister(secrets)},
+		CABundleConfigMap:              CABundleConfigMap{Namespace: "ns-a", Name: "ca-bundle", Lister: corev1listers.NewConfigMapLister(configMaps)},
+		RotatedSelfSignedCertKeySecret: RotatedSelfSignedCertKeySecret{Namespace: "ns-b", Name: "target", Lister: corev1listers.NewSecretLister(secrets)},
+	}
+	registry := NewManagedResourceRegistry()
+	registry.Register(controller.managedResources()...)
+	registry.Register(ManagedResource{Controller: "second", Kind: ManagedResourceKindSecret, Namespace: "ns-b", Name: "missing", CertificateType: CertificateTypeTarget})
+	// registering again is a no-op
+	registry.Register(controller.managedResources()...)
+
+	if got := len(registry.List()); got != 4 {
+		t.Errorf("expected 4 resources, got %d", got)
+	}
+	if got := names(registry.ListByNamespace("ns-a")); got != "ca-bundle,signer" {
+		t.Errorf("unexpected resources in ns-a: %s", got)
+	}
+	if got := names(registry.ListByController("second")); got != "missing" {
+		t.Errorf("unexpected resources of second controller: %s", got)
+	}
+	if got := names(registry.ListExpiringBefore(now.Add(36 * time.Hour))); got != "target,ca-bundle" {
+		t.Errorf("unexpected resources expiring within 36h: %s", got)
+	}
+	if got := names(registry.ListExpiringBefore(now.Add(72 * time.Hour))); got != "target,ca-bundle,signer" {
+		t.Errorf("unexpected resources expiring within 72h: %s", got)
+	}
+}
+
+func TestRegisterWhileRunning(t *testing.T) {
+	registry := NewManagedResourceRegistry()
+	resources := []ManagedResource{{Controller: "test", Kind: ManagedResourceKindSecret, Namespace: "ns", Name: "target"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		registry.registerWhileRunning(resources)(ctx, nil)
+	}()
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return len(registry.List()) == 1, nil
+	}); err != nil {
+		t.Fatalf("expected the resources to be registered while running: %v", err)
+	}
+
+	cancel()
+	<-done
+	if got := registry.List(); len(got) != 0 {
+		t.Errorf("expected the resources to be unregistered after stop, got %v", got)
+	}
+}
+
+func names(resources []ManagedResource) string {
+	ret := ""
+	for i, resource := range resources {
+		if i > 0 {
+			ret += ","
+		}
+		ret += resource.Name
+	}
+	return ret
+}
@@ -0,0 +1,82 @@
+package certrotation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// secretDataKeys are the keys a cert/key pair is stored under in a secret.
+type secretDataKeys struct {
+	cert       string
+	privateKey string
+}
+
+// newSecretDataKeys returns the configured keys, defaulting to the kubernetes.io/tls keys.
+func newSecretDataKeys(certKey, privateKeyKey string) secretDataKeys {
+	keys := secretDataKeys{cert: certKey, privateKey: privateKeyKey}
+	if len(keys.cert) == 0 {
+		keys.cert = corev1.TLSCertKey
+	}
+	if len(keys.privateKey) == 0 {
+		keys.privateKey = corev1.TLSPrivateKeyKey
+	}
+	return keys
+}
+
+// isDefault returns true for the kubernetes.io/tls keys.
+func (k secretDataKeys) isDefault() bool {
+	return k.cert == corev1.TLSCertKey && k.privateKey == corev1.TLSPrivateKeyKey
+}
+
+// secretType returns kubernetes.io/tls for the default keys. New secrets with custom keys are Opaque, because
+// kubernetes.io/tls secrets must contain tls.crt and tls.key. The type of a secret is immutable, so an existing
+// kubernetes.io/tls secret keeps its type when the keys are customized and set mirrors the pair into tls.crt and
+// tls.key, rather than the secret being deleted and recreated.
+func (k secretDataKeys) secretType(existing corev1.SecretType) corev1.SecretType {
+	if k.isDefault() || existing == corev1.SecretTypeTLS {
+		return corev1.SecretTypeTLS
+	}
+	return corev1.SecretTypeOpaque
+}
+
+// missing returns a non-empty reason when the secret does not hold the cert/key pair under custom keys,
+// e.g. after the keys were reconfigured. Secrets with the default keys are left to the annotation checks.
+func (k secretDataKeys) missing(secret *corev1.Secret) string {
+	if k.isDefault() {
+		return ""
+	}
+	if len(secret.Data[k.cert]) == 0 || len(secret.Data[k.privateKey]) == 0 {
+		return fmt.Sprintf("missing %q or %q", k.cert, k.privateKey)
+	}
+	return ""
+}
+
+// set stores the cert/key pair under the keys and removes stale default keys left behind by a reconfiguration.
+// kubernetes.io/tls secrets keep the pair under the default keys too. Without keyBytes, e.g. for a signer whose key
+// lives outside of the cluster, the private key is removed, or left empty in kubernetes.io/tls secrets, which must
+// have a tls.key entry.
+func (k secretDataKeys) set(secret *corev1.Secret, certBytes, keyBytes []byte) {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if secret.Type == corev1.SecretTypeTLS && !k.isDefault() {
+		secret.Data[corev1.TLSCertKey] = certBytes
+		secret.Data[corev1.TLSPrivateKeyKey] = keyBytes
+	} else {
+		for _, defaultKey := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if defaultKey != k.cert && defaultKey != k.privateKey {
+				delete(secret.Data, defaultKey)
+			}
+		}
+	}
+	secret.Data[k.cert] = certBytes
+	if keyBytes == nil {
+		delete(secret.Data, k.privateKey)
+		if secret.Type == corev1.SecretTypeTLS {
+			secret.Data[corev1.TLSPrivateKeyKey] = []byte{}
+		}
+		return
+	}
+	secret.Data[k.privateKey] = keyBytes
+}
@@ -0,0 +1,146 @@
+package certrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestSecretDataKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		certKey       string
+		privateKeyKey string
+		existingType  corev1.SecretType
+		data          map[string][]byte
+
+		expectedType    corev1.SecretType
+		expectedMissing bool
+		expectedData    []string
+	}{
+		{
+			name:         "defaults",
+			expectedType: corev1.SecretTypeTLS,
+			expectedData: []string{"tls.crt", "tls.key"},
+		},
+		{
+			name:            "custom keys",
+			certKey:         "server.crt",
+			privateKeyKey:   "server.key",
+			expectedType:    corev1.SecretTypeOpaque,
+			expectedMissing: true,
+			expectedData:    []string{"server.crt", "server.key"},
+		},
+		{
+			name:            "custom keys replace default keys",
+			certKey:         "server.crt",
+			privateKeyKey:   "server.key",
+			data:            map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key"), "ca.crt": []byte("ca")},
+			expectedType:    corev1.SecretTypeOpaque,
+			expectedMissing: true,
+			expectedData:    []string{"ca.crt", "server.crt", "server.key"},
+		},
+		{
+			name:            "custom keys keep an existing kubernetes.io/tls type",
+			certKey:         "server.crt",
+			privateKeyKey:   "server.key",
+			existingType:    corev1.SecretTypeTLS,
+			data:            map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+			expectedType:    corev1.SecretTypeTLS,
+			expectedMissing: true,
+			expectedData:    []string{"server.crt", "server.key", "tls.crt", "tls.key"},
+		},
+		{
+			name:         "custom cert key only",
+			certKey:      "etcd-peer.crt",
+			expectedType: corev1.SecretTypeOpaque,
+			data:         map[string][]byte{"etcd-peer.crt": []byte("cert"), "tls.key": []byte("key")},
+			expectedData: []string{"etcd-peer.crt", "tls.key"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := newSecretDataKeys(test.certKey, test.privateKeyKey)
+			secret := &corev1.Secret{Type: test.existingType, Data: test.data}
+			secret.Type = keys.secretType(secret.Type)
+			if secret.Type != test.expectedType {
+				t.Errorf("expected type %q, got %q", test.expectedType, secret.Type)
+			}
+
+			if missing := len(keys.missing(secret)) > 0; missing != test.expectedMissing {
+				t.Errorf("expected missing %v, got %v", test.expectedMissing, missing)
+			}
+
+			keys.set(secret, []byte("new-cert"), []byte("new-key"))
+			if len(keys.missing(secret)) > 0 {
+				t.Errorf("unexpected missing keys after set: %v", secret.Data)
+			}
+			if len(secret.Data) != len(test.expectedData) {
+				t.Errorf("expected keys %v, got %v", test.expectedData, secret.Data)
+			}
+			for _, key := range test.expectedData {
+				if _, ok := secret.Data[key]; !ok {
+					t.Errorf("expected key %q, got %v", key, secret.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureSigningCertKeyPairCustomKeys(t *testing.T) {
+	// the annotations say the signer is fresh, but it was written under the default keys
+	initialSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "signer",
+			Annotations: map[string]string{
+				"auth.openshift.io/certificate-not-after":  "2108-09-08T22:47:31-07:00",
+				"auth.openshift.io/certificate-not-before": "2108-09-08T20:47:31-07:00",
+			}},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(initialSecret)
+	client := kubefake.NewSimpleClientset(initialSecret)
+
+	c := &RotatedSigningCASecret{
+		Namespace:     "ns",
+		Name:          "signer",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		CertKey:       "signer.crt",
+		PrivateKeyKey: "signer.key",
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+
+	if _, err := c.ensureSigningCertKeyPair(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret type is immutable, the existing kubernetes.io/tls secret is updated in place instead of recreated
+	actions := client.Actions()
+	if len(actions) != 2 || !actions[1].Matches("update", "secrets") {
+		t.Fatalf("expected get and update, got %v", actions)
+	}
+	actual := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.Secret)
+	if actual.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected type %q, got %q", corev1.SecretTypeTLS, actual.Type)
+	}
+	if len(actual.Data["signer.crt"]) == 0 || len(actual.Data["signer.key"]) == 0 {
+		t.Error(actual.Data)
+	}
+	if string(actual.Data["tls.crt"]) != string(actual.Data["signer.crt"]) || string(actual.Data["tls.key"]) != string(actual.Data["signer.key"]) {
+		t.Errorf("expected the pair to be mirrored into tls.crt and tls.key, got %v", actual.Data)
+	}
+}
@@ -0,0 +1,139 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+// WithServerSideApply writes the signer, the CA bundle and the target with server-side apply under the field manager,
+// instead of updating the objects read from the informer caches. Controllers sharing a CA bundle config map then do
+// not conflict on stale reads and update it twice. Applies are forced, the controller owns the fields it sets. Only the
+// labels and annotations the controller sets itself are applied, the ones of users and other controllers stay theirs.
+// Labels, annotations and data keys it no longer sets are removed only if it owns them, i.e. not the ones set by
+// updates before server-side apply was enabled. Annotations it consumes, e.g. the ForceRotationAnnotation, are removed
+// with a merge patch.
+func WithServerSideApply(fieldManager string) CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.rotatedSigningCASecret.fieldManager = fieldManager
+		c.CABundleConfigMap.fieldManager = fieldManager
+		c.RotatedSelfSignedCertKeySecret.fieldManager = fieldManager
+	}
+}
+
+// applySecret writes the secret with server-side apply if a field manager is set, with resourceapply.ApplySecret
+// otherwise.
+func applySecret(ctx context.Context, client corev1client.SecretsGetter, recorder events.Recorder, fieldManager string, required *corev1.Secret) (*corev1.Secret, bool, error) {
+	if len(fieldManager) == 0 {
+		return resourceapply.ApplySecret(ctx, client, recorder, required)
+	}
+	secret := corev1apply.Secret(required.Name, required.Namespace).
+		WithLabels(ownedEntries(required.Labels, controllerLabels)).
+		WithAnnotations(ownedEntries(required.Annotations, controllerAnnotations)).
+		WithType(required.Type).
+		WithData(required.Data)
+	actual, err := client.Secrets(required.Namespace).Apply(ctx, secret, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		recorder.Warningf("SecretApplyFailed", "Failed to apply Secret/%s -n %s: %v", required.Name, required.Namespace, err)
+		return nil, false, err
+	}
+	if patch := annotationRemovalPatch(required.Annotations); patch != nil {
+		actual, err = client.Secrets(required.Namespace).Patch(ctx, required.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+		if err != nil {
+			recorder.Warningf("SecretApplyFailed", "Failed to remove annotations of Secret/%s -n %s: %v", required.Name, required.Namespace, err)
+			return nil, false, err
+		}
+	}
+	modified := actual.ResourceVersion != required.ResourceVersion
+	if modified {
+		recorder.Eventf("SecretApplied", "Applied Secret/%s -n %s with field manager %s", required.Name, required.Namespace, fieldManager)
+	}
+	return actual, modified, nil
+}
+
+// applyConfigMap writes the config map with server-side apply if a field manager is set, with
+// resourceapply.ApplyConfigMap otherwise.
+func applyConfigMap(ctx context.Context, client corev1client.ConfigMapsGetter, recorder events.Recorder, fieldManager string, required *corev1.ConfigMap) (*corev1.ConfigMap, bool, error) {
+	if len(fieldManager) == 0 {
+		return resourceapply.ApplyConfigMap(ctx, client, recorder, required)
+	}
+	configMap := corev1apply.ConfigMap(required.Name, required.Namespace).
+		WithLabels(ownedEntries(required.Labels, controllerLabels)).
+		WithAnnotations(ownedEntries(required.Annotations, controllerAnnotations)).
+		WithData(required.Data).
+		WithBinaryData(required.BinaryData)
+	actual, err := client.ConfigMaps(required.Namespace).Apply(ctx, configMap, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		recorder.Warningf("ConfigMapApplyFailed", "Failed to apply ConfigMap/%s -n %s: %v", required.Name, required.Namespace, err)
+		return nil, false, err
+	}
+	if patch := annotationRemovalPatch(required.Annotations); patch != nil {
+		actual, err = client.ConfigMaps(required.Namespace).Patch(ctx, required.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+		if err != nil {
+			recorder.Warningf("ConfigMapApplyFailed", "Failed to remove annotations of ConfigMap/%s -n %s: %v", required.Name, required.Namespace, err)
+			return nil, false, err
+		}
+	}
+	modified := actual.ResourceVersion != required.ResourceVersion
+	if modified {
+		recorder.Eventf("ConfigMapApplied", "Applied ConfigMap/%s -n %s with field manager %s", required.Name, required.Namespace, fieldManager)
+	}
+	return actual, modified, nil
+}
+
+var (
+	// controllerLabels are the labels the controller sets on the objects it writes.
+	controllerLabels = sets.NewString(ManagedCertificateTypeLabelName, ManagedByControllerLabelName)
+	// controllerAnnotations are the annotations the controller sets on the objects it writes.
+	controllerAnnotations = sets.NewString(
+		CertificateNotBeforeAnnotation,
+		CertificateNotAfterAnnotation,
+		CertificateIssuer,
+		CertificateHostnames,
+		CertificateSPIFFEID,
+		CABundleTrustSourcesAnnotation,
+		ExternalSinksAnnotation,
+		LastForcedRotationReasonAnnotation,
+		OutputFormatsHashAnnotation,
+	)
+)
+
+// ownedEntries returns the entries of the keys the controller sets. Removal markers, keys with a trailing dash which
+// resourcemerge.MergeMap removes from the existing object, are dropped: with server-side apply, a key that is not
+// applied anymore is removed if the controller owns it.
+func ownedEntries(m map[string]string, owned sets.String) map[string]string {
+	result := map[string]string{}
+	for k, v := range m {
+		if owned.Has(k) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// annotationRemovalPatch returns a merge patch removing the annotations with removal markers, nil if there are none.
+// They may be owned by other field managers, e.g. the ForceRotationAnnotation set by a user, so not applying them
+// does not remove them.
+func annotationRemovalPatch(annotations map[string]string) []byte {
+	removed := map[string]interface{}{}
+	for k := range annotations {
+		if strings.HasSuffix(k, "-") && len(k) > 1 {
+			removed[strings.TrimSuffix(k, "-")] = nil
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": removed}})
+	return patch
+}
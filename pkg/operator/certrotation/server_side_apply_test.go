@@ -0,0 +1,164 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// applyReactor answers server-side applies, which the fake object tracker does not support, with the applied object
+// and stores it in the tracker. Like with the fields of other field managers, labels and annotations of an existing
+// object that are not applied are kept.
+func applyReactor(tracker clienttesting.ObjectTracker, newObject func() runtime.Object) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := newObject()
+		if err := json.Unmarshal(patch.GetPatch(), obj); err != nil {
+			return true, nil, err
+		}
+		applied := obj.(metav1.Object)
+		applied.SetResourceVersion("1")
+		existing, err := tracker.Get(action.GetResource(), patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, obj, tracker.Add(obj)
+		}
+		existingMeta := existing.(metav1.Object)
+		labels, annotations := existingMeta.GetLabels(), existingMeta.GetAnnotations()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for k, v := range applied.GetLabels() {
+			labels[k] = v
+		}
+		for k, v := range applied.GetAnnotations() {
+			annotations[k] = v
+		}
+		applied.SetLabels(labels)
+		applied.SetAnnotations(annotations)
+		return true, obj, tracker.Update(action.GetResource(), obj, patch.GetNamespace())
+	}
+}
+
+func TestServerSideApply(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	client.PrependReactor("patch", "secrets", applyReactor(client.Tracker(), func() runtime.Object { return &corev1.Secret{} }))
+	client.PrependReactor("patch", "configmaps", applyReactor(client.Tracker(), func() runtime.Object { return &corev1.ConfigMap{} }))
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	recorder := events.NewInMemoryRecorder("test")
+	c := &CertRotationController{
+		name: "Test",
+		rotatedSigningCASecret: RotatedSigningCASecret{
+			Namespace: "ns", Name: "signer", Validity: 48 * time.Hour, Refresh: 24 * time.Hour,
+			Lister: corev1listers.NewSecretLister(secrets), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		CABundleConfigMap: CABundleConfigMap{
+			Namespace: "ns", Name: "ca-bundle",
+			Lister: corev1listers.NewConfigMapLister(configMaps), Client: client.CoreV1(), EventRecorder: recorder,
+		},
+		RotatedSelfSignedCertKeySecret: RotatedSelfSignedCertKeySecret{
+			Namespace: "ns", Name: "target", Validity: 24 * time.Hour, Refresh: 12 * time.Hour,
+			CertCreator:   &ServingRotation{Hostnames: func() []string { return []string{"foo.ns.svc"} }},
+			Lister:        corev1listers.NewSecretLister(secrets),
+			Client:        client.CoreV1(),
+			EventRecorder: recorder,
+		},
+		OperatorClient: v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil),
+	}
+	WithServerSideApply("test-operator")(c)
+
+	if err := c.Sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	applied := map[string]bool{}
+	for _, action := range client.Actions() {
+		switch action := action.(type) {
+		case clienttesting.PatchAction:
+			if action.GetPatchType() != types.ApplyPatchType {
+				t.Errorf("expected server-side applies, got a %s patch", action.GetPatchType())
+			}
+			applied[action.GetResource().Resource+"/"+action.GetName()] = true
+		case clienttesting.CreateAction, clienttesting.UpdateAction:
+			t.Errorf("expected no create or update, got %v", action)
+		}
+	}
+	for _, expected := range []string{"secrets/signer", "configmaps/ca-bundle", "secrets/target"} {
+		if !applied[expected] {
+			t.Errorf("expected %s to be applied, got %v", expected, applied)
+		}
+	}
+}
+
+func TestServerSideApplyRemovalMarkers(t *testing.T) {
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "target", Annotations: map[string]string{
+			ForceRotationAnnotation: "TICKET-1",
+			"user":                  "value",
+		}},
+		Type: corev1.SecretTypeTLS,
+	}
+	client := kubefake.NewSimpleClientset(existing)
+	client.PrependReactor("patch", "secrets", applyReactor(client.Tracker(), func() runtime.Object { return &corev1.Secret{} }))
+	required := existing.DeepCopy()
+	required.Data = map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}
+	required.Annotations[CertificateIssuer] = "signer"
+	completeForcedRotation(required)
+
+	actual, modified, err := applySecret(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), "test-operator", required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified {
+		t.Error("expected the secret to be reported as modified")
+	}
+	if _, ok := actual.Annotations[ForceRotationAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be removed, got %v", ForceRotationAnnotation, actual.Annotations)
+	}
+	if _, ok := actual.Annotations[ForceRotationAnnotation+"-"]; ok {
+		t.Errorf("expected the removal marker not to be applied, got %v", actual.Annotations)
+	}
+	if actual.Annotations["user"] != "value" || actual.Annotations[LastForcedRotationReasonAnnotation] != "TICKET-1" || actual.Annotations[CertificateIssuer] != "signer" || string(actual.Data["tls.crt"]) != "cert" {
+		t.Errorf("expected the annotations and data to be applied, got %v", actual)
+	}
+	stored, err := client.Tracker().Get(corev1.SchemeGroupVersion.WithResource("secrets"), "ns", "target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored.(*corev1.Secret).Annotations[ForceRotationAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be removed from the stored secret, got %v", ForceRotationAnnotation, stored.(*corev1.Secret).Annotations)
+	}
+
+	// the annotations of users are not applied, the controller does not take them over
+	for _, action := range client.Actions() {
+		if patch, ok := action.(clienttesting.PatchAction); ok && patch.GetPatchType() == types.ApplyPatchType {
+			applied := &corev1.Secret{}
+			if err := json.Unmarshal(patch.GetPatch(), applied); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := applied.Annotations["user"]; ok {
+				t.Errorf("expected only the annotations of the controller to be applied, got %v", applied.Annotations)
+			}
+		}
+	}
+}
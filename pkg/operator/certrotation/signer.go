@@ -3,12 +3,13 @@ package certrotation
 import (
 	"bytes"
 	"context"
+	gocrypto "crypto"
 	"fmt"
 	"time"
 
 	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
-	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,16 +37,70 @@ type RotatedSigningCASecret struct {
 	// but only rotate when the signing CA expires. This is useful for auto-recovery when we want to enforce
 	// rotation on expiration only, but not interfere with the ordinary rotation controller.
 	RefreshOnlyWhenExpired bool
+	// RefreshJitter spreads the rotations of many signing CAs over a window: the refresh and the rotation at 80% of
+	// validity are delayed by up to RefreshJitter, by a delay derived from the namespace and name of the secret. The
+	// delay is at most 10% of the validity, so the signing CA is still rotated before it expires.
+	RefreshJitter time.Duration
+
+	// CertKey is the secret data key of the signing certificate. Defaults to tls.crt.
+	// Secrets with keys other than tls.crt and tls.key are of type Opaque.
+	CertKey string
+	// PrivateKeyKey is the secret data key of the signing private key. Defaults to tls.key.
+	PrivateKeyKey string
+
+	// KeyAlgorithm is the algorithm of the signing key, e.g. ECDSA-P256. Defaults to RSA-2048. If set, a signing CA
+	// with a key of another algorithm is rotated, if not, the key of an existing signing CA is kept. It is ignored if
+	// Signer is set.
+	KeyAlgorithm crypto.KeyAlgorithm
+
+	// Signer, if set, holds the private key of the signing CA outside of the cluster, e.g. in an HSM accessed through
+	// PKCS#11. The secret then only stores the signing certificate, which is re-issued for the same key on rotation.
+	// The type of the secret is kept, the private key of a kubernetes.io/tls secret is left empty.
+	Signer gocrypto.Signer
+
+	// Backend, if set, issues the target certificates with a CA outside of the cluster, e.g. cert-manager or Vault.
+	// The secret is not used then and the CA certificates of the backend are added to the CA bundle. It is exclusive
+	// with Signer.
+	Backend SignerBackend
+
+	// ConfigChanged, if set, triggers a sync of the controllers of the signing CA when its configuration might have
+	// changed, e.g. through a RecheckNotifier.
+	ConfigChanged <-chan struct{}
 
 	// Plumbing:
 	Informer      corev1informers.SecretInformer
 	Lister        corev1listers.SecretLister
 	Client        corev1client.SecretsGetter
 	EventRecorder events.Recorder
+
+	// liveReadFallback reads the object from the server when it is missing in the informer cache, see
+	// WithLiveReadFallback.
+	liveReadFallback bool
+	// fieldManager, if set, writes the secret with server-side apply, see WithServerSideApply.
+	fieldManager string
+	// controllerLabel, if set, is the value of ManagedByControllerLabelName the secret is labeled with, see
+	// WithOrphanedResourceDeletion.
+	controllerLabel string
+}
+
+// liveReadClient returns the client to read the secret from the server when it is missing in the informer cache, nil
+// unless enabled with WithLiveReadFallback.
+func (c RotatedSigningCASecret) liveReadClient() corev1client.SecretsGetter {
+	if !c.liveReadFallback {
+		return nil
+	}
+	return c.Client
 }
 
 func (c RotatedSigningCASecret) ensureSigningCertKeyPair(ctx context.Context) (*crypto.CA, error) {
-	originalSigningCertKeyPairSecret, err := c.Lister.Secrets(c.Namespace).Get(c.Name)
+	if c.Backend != nil {
+		if c.Signer != nil {
+			return nil, fmt.Errorf("signing CA %s/%s: only one of Signer and Backend may be set", c.Namespace, c.Name)
+		}
+		return newSignerBackendCA(ctx, c.Backend)
+	}
+
+	originalSigningCertKeyPairSecret, err := v1helpers.GetSecret(ctx, c.Lister, c.liveReadClient(), c.Namespace, c.Name)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
@@ -54,24 +109,48 @@ func (c RotatedSigningCASecret) ensureSigningCertKeyPair(ctx context.Context) (*
 		// create an empty one
 		signingCertKeyPairSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name}}
 	}
-	signingCertKeyPairSecret.Type = corev1.SecretTypeTLS
+	keys := newSecretDataKeys(c.CertKey, c.PrivateKeyKey)
+	signingCertKeyPairSecret.Type = keys.secretType(signingCertKeyPairSecret.Type)
 
-	if needed, reason := needNewSigningCertKeyPair(signingCertKeyPairSecret.Annotations, c.Refresh, c.RefreshOnlyWhenExpired); needed {
+	reason := forcedRotationReason(signingCertKeyPairSecret)
+	needed := len(reason) > 0
+	if !needed {
+		needed, reason = needNewSigningCertKeyPair(signingCertKeyPairSecret.Annotations, c.Refresh, c.RefreshOnlyWhenExpired, refreshJitter(c.Namespace, c.Name, c.RefreshJitter))
+	}
+	if !needed {
+		if c.Signer != nil {
+			reason = signerMismatch(signingCertKeyPairSecret.Data[keys.cert], c.Signer)
+		} else {
+			reason = keys.missing(signingCertKeyPairSecret)
+			if len(reason) == 0 {
+				reason = keyAlgorithmMismatch(signingCertKeyPairSecret.Data[keys.cert], c.KeyAlgorithm)
+			}
+		}
+		needed = len(reason) > 0
+	}
+	if needed {
 		c.EventRecorder.Eventf("SignerUpdateRequired", "%q in %q requires a new signing cert/key pair: %v", c.Name, c.Namespace, reason)
-		if err := setSigningCertKeyPairSecret(signingCertKeyPairSecret, c.Validity); err != nil {
+		if err := setSigningCertKeyPairSecret(signingCertKeyPairSecret, c.Validity, keys, c.KeyAlgorithm, c.Signer); err != nil {
 			return nil, err
 		}
+		completeForcedRotation(signingCertKeyPairSecret)
 
 		LabelAsManagedSecret(signingCertKeyPairSecret, CertificateTypeSigner)
+		labelAsManagedByController(signingCertKeyPairSecret, c.controllerLabel)
 
-		actualSigningCertKeyPairSecret, _, err := resourceapply.ApplySecret(ctx, c.Client, c.EventRecorder, signingCertKeyPairSecret)
+		actualSigningCertKeyPairSecret, _, err := applySecret(ctx, c.Client, c.EventRecorder, c.fieldManager, signingCertKeyPairSecret)
 		if err != nil {
 			return nil, err
 		}
 		signingCertKeyPairSecret = actualSigningCertKeyPairSecret
+		metrics.observeRotation(c.Namespace, c.Name, CertificateTypeSigner)
 	}
+	metrics.observeCertificate(c.Namespace, c.Name, CertificateTypeSigner, signingCertKeyPairSecret.Annotations, c.Refresh, c.RefreshOnlyWhenExpired, refreshJitter(c.Namespace, c.Name, c.RefreshJitter))
 	// at this point, the secret has the correct signer, so we should read that signer to be able to sign
-	signingCertKeyPair, err := crypto.GetCAFromBytes(signingCertKeyPairSecret.Data["tls.crt"], signingCertKeyPairSecret.Data["tls.key"])
+	if c.Signer != nil {
+		return crypto.GetCAFromCertsAndSigner(signingCertKeyPairSecret.Data[keys.cert], c.Signer)
+	}
+	signingCertKeyPair, err := crypto.GetCAFromBytes(signingCertKeyPairSecret.Data[keys.cert], signingCertKeyPairSecret.Data[keys.privateKey])
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +158,7 @@ func (c RotatedSigningCASecret) ensureSigningCertKeyPair(ctx context.Context) (*
 	return signingCertKeyPair, nil
 }
 
-func needNewSigningCertKeyPair(annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool) (bool, string) {
+func needNewSigningCertKeyPair(annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) (bool, string) {
 	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
 	if len(reason) > 0 {
 		return true, reason
@@ -94,12 +173,13 @@ func needNewSigningCertKeyPair(annotations map[string]string, refresh time.Durat
 	}
 
 	validity := notAfter.Sub(notBefore)
-	at80Percent := notAfter.Add(-validity / 5)
+	jitter = capRefreshJitter(jitter, validity)
+	at80Percent := notAfter.Add(-validity / 5).Add(jitter)
 	if time.Now().After(at80Percent) {
 		return true, fmt.Sprintf("past its latest possible time %v", at80Percent)
 	}
 
-	developerSpecifiedRefresh := notBefore.Add(refresh)
+	developerSpecifiedRefresh := notBefore.Add(refresh).Add(jitter)
 	if time.Now().After(developerSpecifiedRefresh) {
 		return true, fmt.Sprintf("past its refresh time %v", developerSpecifiedRefresh)
 	}
@@ -128,31 +208,76 @@ func getValidityFromAnnotations(annotations map[string]string) (notBefore time.T
 	return notBefore, notAfter, ""
 }
 
-// setSigningCertKeyPairSecret creates a new signing cert/key pair and sets them in the secret
-func setSigningCertKeyPairSecret(signingCertKeyPairSecret *corev1.Secret, validity time.Duration) error {
-	signerName := fmt.Sprintf("%s_%s@%d", signingCertKeyPairSecret.Namespace, signingCertKeyPairSecret.Name, time.Now().Unix())
-	ca, err := crypto.MakeSelfSignedCAConfigForDuration(signerName, validity)
+// signerMismatch returns a non-empty reason when the signing certificate is missing or not for the key of the signer.
+func signerMismatch(certBytes []byte, signer gocrypto.Signer) string {
+	certs, err := crypto.CertsFromPEM(certBytes)
 	if err != nil {
-		return err
+		return fmt.Sprintf("bad signing certificate: %v", err)
+	}
+	if !crypto.SignerMatchesCertificate(signer, certs[0]) {
+		return "signing certificate does not match the signer"
 	}
+	return ""
+}
 
-	certBytes := &bytes.Buffer{}
-	keyBytes := &bytes.Buffer{}
-	if err := ca.WriteCertConfig(certBytes, keyBytes); err != nil {
-		return err
+// setSigningCertKeyPairSecret creates a new signing cert/key pair and sets them in the secret. With an external signer,
+// only the new signing certificate for the key of the signer is set.
+func setSigningCertKeyPairSecret(signingCertKeyPairSecret *corev1.Secret, validity time.Duration, keys secretDataKeys, keyAlgorithm crypto.KeyAlgorithm, signer gocrypto.Signer) error {
+	signerName := fmt.Sprintf("%s_%s@%d", signingCertKeyPairSecret.Namespace, signingCertKeyPairSecret.Name, time.Now().Unix())
+	var ca *crypto.TLSCertificateConfig
+	var certBytes, keyBytes []byte
+	var err error
+	if signer != nil {
+		ca, err = crypto.MakeSelfSignedCAConfigForSigner(signerName, validity, signer)
+		if err != nil {
+			return err
+		}
+		certBytes, err = crypto.EncodeCertificates(ca.Certs...)
+		if err != nil {
+			return err
+		}
+	} else {
+		ca, err = crypto.MakeSelfSignedCAConfigForDurationWithKeyAlgorithm(signerName, validity, keyAlgorithm)
+		if err != nil {
+			return err
+		}
+		certBuffer := &bytes.Buffer{}
+		keyBuffer := &bytes.Buffer{}
+		if err := ca.WriteCertConfig(certBuffer, keyBuffer); err != nil {
+			return err
+		}
+		certBytes, keyBytes = certBuffer.Bytes(), keyBuffer.Bytes()
 	}
 
 	if signingCertKeyPairSecret.Annotations == nil {
 		signingCertKeyPairSecret.Annotations = map[string]string{}
 	}
-	if signingCertKeyPairSecret.Data == nil {
-		signingCertKeyPairSecret.Data = map[string][]byte{}
-	}
-	signingCertKeyPairSecret.Data["tls.crt"] = certBytes.Bytes()
-	signingCertKeyPairSecret.Data["tls.key"] = keyBytes.Bytes()
+	keys.set(signingCertKeyPairSecret, certBytes, keyBytes)
 	signingCertKeyPairSecret.Annotations[CertificateNotAfterAnnotation] = ca.Certs[0].NotAfter.Format(time.RFC3339)
 	signingCertKeyPairSecret.Annotations[CertificateNotBeforeAnnotation] = ca.Certs[0].NotBefore.Format(time.RFC3339)
 	signingCertKeyPairSecret.Annotations[CertificateIssuer] = ca.Certs[0].Issuer.CommonName
 
 	return nil
 }
+
+// keyAlgorithmMismatch returns a non-empty reason when the certificate is for a key of another algorithm than the
+// configured one, e.g. after the algorithm was changed. Certificates that cannot be read are left to the other checks.
+// Without a configured algorithm, any key is accepted: certificates written before the algorithm could be configured
+// may have keys of other algorithms than the default one, and must not all be rotated on upgrade.
+func keyAlgorithmMismatch(certBytes []byte, keyAlgorithm crypto.KeyAlgorithm) string {
+	if len(keyAlgorithm) == 0 {
+		return ""
+	}
+	certs, err := crypto.CertsFromPEM(certBytes)
+	if err != nil {
+		return ""
+	}
+	actual, err := crypto.KeyAlgorithmOf(certs[0].PublicKey)
+	if err != nil {
+		return fmt.Sprintf("key algorithm %s is required: %v", keyAlgorithm, err)
+	}
+	if actual != keyAlgorithm {
+		return fmt.Sprintf("key algorithm %s is required, the key is %s", keyAlgorithm, actual)
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package certrotation
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// SignerBackend issues the certificates of the targets with a CA outside of the cluster, e.g. cert-manager or Vault,
+// instead of the signing CA stored in a secret. See RotatedSigningCASecret.Backend.
+type SignerBackend interface {
+	// CACertificates returns the certificates of the CA issuing the target certificates, the issuing certificate
+	// first. The issuing certificate is added to the CA bundle.
+	CACertificates(ctx context.Context) ([]*x509.Certificate, error)
+	// SignCertificateRequest issues a certificate for the request. The template is the certificate requested by the
+	// target, with the validity, key usages and basic constraints the backend should honor. It returns the issued
+	// certificate followed by its chain.
+	SignCertificateRequest(ctx context.Context, request *x509.CertificateRequest, template *x509.Certificate) ([]*x509.Certificate, error)
+}
+
+// signerBackendKey is the key of the CA of a SignerBackend. It cannot sign, target certificates are issued by
+// newCertificateWithSignerBackend instead.
+type signerBackendKey struct {
+	backend SignerBackend
+}
+
+// newSignerBackendCA returns the CA of the backend. It is only good for issuing certificates with
+// newCertificateWithSignerBackend.
+func newSignerBackendCA(ctx context.Context, backend SignerBackend) (*crypto.CA, error) {
+	certs, err := backend.CACertificates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the CA certificates of the signer backend: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("the signer backend has no CA certificates")
+	}
+	return &crypto.CA{
+		SerialGenerator: &crypto.RandomSerialGenerator{},
+		Config:          &crypto.TLSCertificateConfig{Certs: certs, Key: &signerBackendKey{backend: backend}},
+	}, nil
+}
+
+// signerBackendOf returns the backend of a CA returned by newSignerBackendCA.
+func signerBackendOf(signer *crypto.CA) (SignerBackend, bool) {
+	key, ok := signer.Config.Key.(*signerBackendKey)
+	if !ok {
+		return nil, false
+	}
+	return key.backend, true
+}
+
+// newCertificateWithSignerBackend has the backend issue the certificate the creator creates. The creator creates the
+// key and the requested certificate with a throwaway local CA, the backend issues that certificate for the key.
+func newCertificateWithSignerBackend(ctx context.Context, backend SignerBackend, validity time.Duration, create func(*crypto.CA) (*crypto.TLSCertificateConfig, error)) (*crypto.TLSCertificateConfig, error) {
+	requestCAConfig, err := crypto.MakeSelfSignedCAConfigForDurationWithKeyAlgorithm("signer-backend-request", validity, crypto.ECDSAP256KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	requested, err := create(&crypto.CA{Config: requestCAConfig, SerialGenerator: &crypto.RandomSerialGenerator{}})
+	if err != nil {
+		return nil, err
+	}
+	key, ok := requested.Key.(gocrypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("cannot request a certificate for a key of type %T", requested.Key)
+	}
+
+	template := requested.Certs[0]
+	requestBytes, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        template.Subject,
+		DNSNames:       template.DNSNames,
+		EmailAddresses: template.EmailAddresses,
+		IPAddresses:    template.IPAddresses,
+		URIs:           template.URIs,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	request, err := x509.ParseCertificateRequest(requestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	issued, err := backend.SignCertificateRequest(ctx, request, template)
+	if err != nil {
+		return nil, fmt.Errorf("the signer backend failed to issue a certificate for %q: %w", template.Subject.CommonName, err)
+	}
+	if len(issued) == 0 {
+		return nil, fmt.Errorf("the signer backend issued no certificate for %q", template.Subject.CommonName)
+	}
+	if !crypto.SignerMatchesCertificate(key, issued[0]) {
+		return nil, fmt.Errorf("the signer backend issued a certificate for %q for another key", template.Subject.CommonName)
+	}
+	return &crypto.TLSCertificateConfig{Certs: issued, Key: requested.Key}, nil
+}
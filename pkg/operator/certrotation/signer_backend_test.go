@@ -0,0 +1,119 @@
+package certrotation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// fakeSignerBackend issues the requested certificates with a local CA, like cert-manager does for a CertificateRequest.
+type fakeSignerBackend struct {
+	ca       *crypto.TLSCertificateConfig
+	requests []*x509.CertificateRequest
+}
+
+func (b *fakeSignerBackend) CACertificates(ctx context.Context) ([]*x509.Certificate, error) {
+	return b.ca.Certs, nil
+}
+
+func (b *fakeSignerBackend) SignCertificateRequest(ctx context.Context, request *x509.CertificateRequest, template *x509.Certificate) ([]*x509.Certificate, error) {
+	b.requests = append(b.requests, request)
+	issued := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               request.Subject,
+		DNSNames:              request.DNSNames,
+		IPAddresses:           request.IPAddresses,
+		NotBefore:             template.NotBefore,
+		NotAfter:              template.NotAfter,
+		KeyUsage:              template.KeyUsage,
+		ExtKeyUsage:           template.ExtKeyUsage,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, issued, b.ca.Certs[0], request.PublicKey, b.ca.Key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*x509.Certificate{cert}, b.ca.Certs...), nil
+}
+
+func TestSignerBackend(t *testing.T) {
+	backendCA, err := crypto.MakeSelfSignedCAConfigForDuration("vault-issuer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &fakeSignerBackend{ca: backendCA}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	signer := RotatedSigningCASecret{Namespace: "ns", Name: "signer", Backend: backend}
+	target := RotatedSelfSignedCertKeySecret{
+		Namespace: "ns",
+		Name:      "target",
+		Validity:  time.Hour,
+		Refresh:   30 * time.Minute,
+		CertCreator: &ServingRotation{
+			Hostnames: func() []string { return []string{"foo.ns.svc", "10.0.0.1"} },
+		},
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+
+	ca, err := signer.ensureSigningCertKeyPair(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca.Config.Certs[0] != backendCA.Certs[0] {
+		t.Errorf("expected the CA of the backend, got %v", ca.Config.Certs[0].Subject)
+	}
+
+	secret, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.requests) != 1 || !strings.Contains(strings.Join(backend.requests[0].DNSNames, ","), "foo.ns.svc") {
+		t.Fatalf("expected a request for the hostnames, got %v", backend.requests)
+	}
+	if secret.Annotations[CertificateIssuer] != "vault-issuer" || secret.Annotations[CertificateHostnames] != "10.0.0.1,foo.ns.svc" {
+		t.Errorf("unexpected annotations %v", secret.Annotations)
+	}
+	issued, err := crypto.GetTLSCertificateConfigFromBytes(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(backendCA.Certs[0])
+	if _, err := issued.Certs[0].Verify(x509.VerifyOptions{DNSName: "foo.ns.svc", Roots: roots}); err != nil {
+		t.Errorf("expected the target to be issued by the backend: %v", err)
+	}
+
+	// nothing to do for a fresh certificate
+	indexer.Add(secret)
+	if _, err := target.ensureTargetCertKeyPair(context.TODO(), ca, ca.Config.Certs); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.requests) != 1 {
+		t.Errorf("expected no new request, got %d", len(backend.requests))
+	}
+
+	signer.Signer = newOpaqueSigner(t)
+	if _, err := signer.ensureSigningCertKeyPair(context.TODO()); err == nil {
+		t.Error("expected Signer and Backend to be exclusive")
+	}
+}
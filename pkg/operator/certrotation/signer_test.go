@@ -132,3 +132,33 @@ func TestEnsureSigningCertKeyPair(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureSigningCertKeyPairLiveReadFallback(t *testing.T) {
+	// created by the previous sync, but not observed by the informer yet
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "signer", ResourceVersion: "1"},
+		Type:       corev1.SecretTypeTLS,
+	})
+	c := &RotatedSigningCASecret{
+		Namespace:        "ns",
+		Name:             "signer",
+		Validity:         24 * time.Hour,
+		Refresh:          12 * time.Hour,
+		Client:           client.CoreV1(),
+		Lister:           corev1listers.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})),
+		EventRecorder:    events.NewInMemoryRecorder("test"),
+		liveReadFallback: true,
+	}
+
+	if _, err := c.ensureSigningCertKeyPair(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range client.Actions() {
+		if action.Matches("create", "secrets") {
+			t.Fatalf("expected the secret read from the server to be updated, got %v", client.Actions())
+		}
+	}
+	if actions := client.Actions(); !actions[0].Matches("get", "secrets") || !actions[len(actions)-1].Matches("update", "secrets") {
+		t.Errorf("expected a live read and an update, got %v", actions)
+	}
+}
@@ -0,0 +1,85 @@
+package certrotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestSPIFFERotation(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+
+	spiffeID := "spiffe://cluster.local/ns/foo/sa/bar"
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace:     "ns",
+		Name:          "workload",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		CertCreator:   &SPIFFERotation{SPIFFEID: func() string { return spiffeID }},
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	ensure := func() *corev1.Secret {
+		t.Helper()
+		client.ClearActions()
+		if _, err := target.ensureTargetCertKeyPair(context.TODO(), signer, signer.Config.Certs); err != nil {
+			t.Fatal(err)
+		}
+		written := lastWrittenSecret(client)
+		if written != nil {
+			indexer.Add(written)
+		}
+		return written
+	}
+	certifiedSPIFFEID := func(secret *corev1.Secret) string {
+		t.Helper()
+		certs, err := crypto.CertsFromPEM(secret.Data["tls.crt"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(certs[0].URIs) != 1 {
+			t.Fatalf("expected one URI SAN, got %v", certs[0].URIs)
+		}
+		return certs[0].URIs[0].String()
+	}
+
+	written := ensure()
+	if written == nil {
+		t.Fatal("expected the target to be created")
+	}
+	if actual := certifiedSPIFFEID(written); actual != spiffeID {
+		t.Errorf("expected %q, got %q", spiffeID, actual)
+	}
+	if written.Annotations[CertificateSPIFFEID] != spiffeID {
+		t.Errorf("expected the SPIFFE ID annotation, got %v", written.Annotations)
+	}
+
+	if written := ensure(); written != nil {
+		t.Errorf("expected no update, got %v", client.Actions())
+	}
+
+	// a new identity is issued when the SPIFFE ID changes
+	spiffeID = "spiffe://cluster.local/ns/foo/sa/baz"
+	written = ensure()
+	if written == nil {
+		t.Fatal("expected a new certificate for the new SPIFFE ID")
+	}
+	if actual := certifiedSPIFFEID(written); actual != spiffeID {
+		t.Errorf("expected %q, got %q", spiffeID, actual)
+	}
+}
@@ -16,7 +16,7 @@ import (
 	"github.com/openshift/library-go/pkg/certs"
 	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
-	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
@@ -49,15 +49,50 @@ type RotatedSelfSignedCertKeySecret struct {
 	// but only rotate when the certificate expires. This is useful for auto-recovery when we want to enforce
 	// rotation on expiration only, but not interfere with the ordinary rotation controller.
 	RefreshOnlyWhenExpired bool
+	// RefreshJitter spreads the rotations of many certificates over a window: the refresh and the rotation at 80% of
+	// validity are delayed by up to RefreshJitter, by a delay derived from the namespace and name of the secret. The
+	// delay is at most 10% of the validity, so the certificate is still rotated before it expires.
+	RefreshJitter time.Duration
 
 	// CertCreator does the actual cert generation.
 	CertCreator TargetCertCreator
 
+	// CertKey is the secret data key of the certificate, e.g. server.crt. Defaults to tls.crt.
+	// Secrets with keys other than tls.crt and tls.key are of type Opaque.
+	CertKey string
+	// PrivateKeyKey is the secret data key of the private key, e.g. server.key. Defaults to tls.key.
+	PrivateKeyKey string
+
+	// KeyAlgorithm is the algorithm of the key, e.g. ECDSA-P256. Defaults to RSA-2048. If set, a certificate for a key
+	// of another algorithm is rotated, if not, the key of an existing certificate is kept. Setting it requires a
+	// CertCreator implementing TargetCertKeyAlgorithmCreator.
+	KeyAlgorithm crypto.KeyAlgorithm
+
+	// OutputFormats, if set, stores the cert/key pair in these formats too, e.g. as PKCS#12 keystore for Java servers.
+	OutputFormats []TargetOutputFormat
+	// KeystorePassphrase references the key of a secret in Namespace holding the passphrase of the PKCS#12 keystore.
+	// It is required for the PKCS12 output format. A changed passphrase recreates the keystore.
+	KeystorePassphrase *corev1.SecretKeySelector
+
+	// ExternalSinks, if set, additionally store the cert/key pair off-cluster after every rotation. The pair is
+	// stored before the secret is written, a sink may thus receive a pair that never reaches the secret when
+	// writing it fails. Failed sinks are retried on the next sync and fail it until they succeed.
+	ExternalSinks []ExternalSecretSink
+
 	// Plumbing:
 	Informer      corev1informers.SecretInformer
 	Lister        corev1listers.SecretLister
 	Client        corev1client.SecretsGetter
 	EventRecorder events.Recorder
+
+	// liveReadFallback reads the object from the server when it is missing in the informer cache, see
+	// WithLiveReadFallback.
+	liveReadFallback bool
+	// fieldManager, if set, writes the secret with server-side apply, see WithServerSideApply.
+	fieldManager string
+	// controllerLabel, if set, is the value of ManagedByControllerLabelName the secret is labeled with, see
+	// WithOrphanedResourceDeletion.
+	controllerLabel string
 }
 
 type TargetCertCreator interface {
@@ -75,42 +110,98 @@ type TargetCertRechecker interface {
 	RecheckChannel() <-chan struct{}
 }
 
-func (c RotatedSelfSignedCertKeySecret) ensureTargetCertKeyPair(ctx context.Context, signingCertKeyPair *crypto.CA, caBundleCerts []*x509.Certificate) error {
+// TargetCertKeyAlgorithmCreator is an optional interface to be implemented by the TargetCertCreator to create
+// key-cert pairs with a key of RotatedSelfSignedCertKeySecret.KeyAlgorithm.
+type TargetCertKeyAlgorithmCreator interface {
+	// NewCertificateWithKeyAlgorithm creates a new key-cert pair with the given signer and a key of the given algorithm.
+	NewCertificateWithKeyAlgorithm(signer *crypto.CA, validity time.Duration, keyAlgorithm crypto.KeyAlgorithm) (*crypto.TLSCertificateConfig, error)
+}
+
+// targetCertIdentityChecker is implemented by the TargetCertCreators whose certificates must be rotated when the
+// identity they certify changes.
+type targetCertIdentityChecker interface {
+	// identityMismatch returns a non-empty reason if the certificate certifies another identity.
+	identityMismatch(certificate *x509.Certificate) string
+}
+
+// liveReadClient returns the client to read the secret from the server when it is missing in the informer cache, nil
+// unless enabled with WithLiveReadFallback.
+func (c RotatedSelfSignedCertKeySecret) liveReadClient() corev1client.SecretsGetter {
+	if !c.liveReadFallback {
+		return nil
+	}
+	return c.Client
+}
+
+func (c RotatedSelfSignedCertKeySecret) ensureTargetCertKeyPair(ctx context.Context, signingCertKeyPair *crypto.CA, caBundleCerts []*x509.Certificate) (*corev1.Secret, error) {
 	// at this point our trust bundle has been updated.  We don't know for sure that consumers have updated, but that's why we have a second
 	// validity percentage.  We always check to see if we need to sign.  Often we are signing with an old key or we have no target
 	// and need to mint one
 	// TODO do the cross signing thing, but this shows the API consumers want and a very simple impl.
-	originalTargetCertKeyPairSecret, err := c.Lister.Secrets(c.Namespace).Get(c.Name)
+	originalTargetCertKeyPairSecret, err := v1helpers.GetSecret(ctx, c.Lister, c.liveReadClient(), c.Namespace, c.Name)
 	if err != nil && !apierrors.IsNotFound(err) {
-		return err
+		return nil, err
 	}
 	targetCertKeyPairSecret := originalTargetCertKeyPairSecret.DeepCopy()
 	if apierrors.IsNotFound(err) {
 		// create an empty one
 		targetCertKeyPairSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name}}
 	}
-	targetCertKeyPairSecret.Type = corev1.SecretTypeTLS
+	keys := newSecretDataKeys(c.CertKey, c.PrivateKeyKey)
+	targetCertKeyPairSecret.Type = keys.secretType(targetCertKeyPairSecret.Type)
 
-	if reason := needNewTargetCertKeyPair(targetCertKeyPairSecret.Annotations, signingCertKeyPair, caBundleCerts, c.Refresh, c.RefreshOnlyWhenExpired); len(reason) > 0 {
+	reason := forcedRotationReason(targetCertKeyPairSecret)
+	if len(reason) == 0 {
+		reason = needNewTargetCertKeyPair(targetCertKeyPairSecret.Annotations, signingCertKeyPair, caBundleCerts, c.Refresh, c.RefreshOnlyWhenExpired, refreshJitter(c.Namespace, c.Name, c.RefreshJitter))
+	}
+	if len(reason) == 0 {
+		reason = keys.missing(targetCertKeyPairSecret)
+	}
+	if identityChecker, ok := c.CertCreator.(targetCertIdentityChecker); ok && len(reason) == 0 {
+		// a certificate that cannot be parsed is left to the other checks
+		if certs, err := crypto.CertsFromPEM(targetCertKeyPairSecret.Data[keys.cert]); err == nil {
+			reason = identityChecker.identityMismatch(certs[0])
+		}
+	}
+	if _, ok := c.CertCreator.(TargetCertKeyAlgorithmCreator); ok && len(reason) == 0 {
+		reason = keyAlgorithmMismatch(targetCertKeyPairSecret.Data[keys.cert], c.KeyAlgorithm)
+	}
+	rotate := len(reason) > 0
+	if rotate {
 		c.EventRecorder.Eventf("TargetUpdateRequired", "%q in %q requires a new target cert/key pair: %v", c.Name, c.Namespace, reason)
-		if err := setTargetCertKeyPairSecret(targetCertKeyPairSecret, c.Validity, signingCertKeyPair, c.CertCreator); err != nil {
-			return err
+		if err := setTargetCertKeyPairSecret(ctx, targetCertKeyPairSecret, c.Validity, signingCertKeyPair, c.CertCreator, c.KeyAlgorithm, keys); err != nil {
+			return nil, err
 		}
-
+		completeForcedRotation(targetCertKeyPairSecret)
+	}
+	outputFormatsChanged, err := c.setTargetOutputFormats(targetCertKeyPairSecret, keys)
+	if err != nil {
+		return nil, err
+	}
+	externalSinksChanged, externalSinksErr := c.storeInExternalSinks(ctx, targetCertKeyPairSecret, keys)
+	if rotate || outputFormatsChanged || externalSinksChanged {
 		LabelAsManagedSecret(targetCertKeyPairSecret, CertificateTypeTarget)
+		labelAsManagedByController(targetCertKeyPairSecret, c.controllerLabel)
 
-		actualTargetCertKeyPairSecret, _, err := resourceapply.ApplySecret(ctx, c.Client, c.EventRecorder, targetCertKeyPairSecret)
+		actualTargetCertKeyPairSecret, _, err := applySecret(ctx, c.Client, c.EventRecorder, c.fieldManager, targetCertKeyPairSecret)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		targetCertKeyPairSecret = actualTargetCertKeyPairSecret
+		if rotate {
+			metrics.observeRotation(c.Namespace, c.Name, CertificateTypeTarget)
+		}
+	}
+	metrics.observeCertificate(c.Namespace, c.Name, CertificateTypeTarget, targetCertKeyPairSecret.Annotations, c.Refresh, c.RefreshOnlyWhenExpired, refreshJitter(c.Namespace, c.Name, c.RefreshJitter))
+	if externalSinksErr != nil {
+		return nil, externalSinksErr
 	}
 
-	return nil
+	return targetCertKeyPairSecret, nil
 }
 
-func needNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool) string {
-	if reason := needNewTargetCertKeyPairForTime(annotations, signer, refresh, refreshOnlyWhenExpired); len(reason) > 0 {
+func needNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) string {
+	if reason := needNewTargetCertKeyPairForTime(annotations, signer, refresh, refreshOnlyWhenExpired, jitter); len(reason) > 0 {
 		return reason
 	}
 
@@ -147,7 +238,7 @@ func needNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA,
 //Hence, if the CAs are rotated too fast (like CA percentage around 10% or smaller), we will not hit the time to make use of the CA. Or if the cert renewal percentage is at 90%, there is not much time either.
 //
 //So with a cert percentage of 75% and equally long CA and cert validities at the worst case we start at 85% of the cert to renew, trying again every minute.
-func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *crypto.CA, refresh time.Duration, refreshOnlyWhenExpired bool) string {
+func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *crypto.CA, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) string {
 	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
 	if len(reason) > 0 {
 		return reason
@@ -164,13 +255,14 @@ func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *cryp
 
 	// Are we at 80% of validity?
 	validity := notAfter.Sub(notBefore)
-	at80Percent := notAfter.Add(-validity / 5)
+	jitter = capRefreshJitter(jitter, validity)
+	at80Percent := notAfter.Add(-validity / 5).Add(jitter)
 	if time.Now().After(at80Percent) {
 		return fmt.Sprintf("past its latest possible time %v", at80Percent)
 	}
 
 	// If Certificate is past its refresh time, we may have action to take. We only do this if the signer is old enough.
-	refreshTime := notBefore.Add(refresh)
+	refreshTime := notBefore.Add(refresh).Add(jitter)
 	if time.Now().After(refreshTime) {
 		// make sure the signer has been valid for more than 10% of the target's refresh time.
 		timeToWaitForTrustRotation := refresh / 10
@@ -184,13 +276,10 @@ func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *cryp
 
 // setTargetCertKeyPairSecret creates a new cert/key pair and sets them in the secret.  Only one of client, serving, or signer rotation may be specified.
 // TODO refactor with an interface for actually signing and move the one-of check higher in the stack.
-func setTargetCertKeyPairSecret(targetCertKeyPairSecret *corev1.Secret, validity time.Duration, signer *crypto.CA, certCreator TargetCertCreator) error {
+func setTargetCertKeyPairSecret(ctx context.Context, targetCertKeyPairSecret *corev1.Secret, validity time.Duration, signer *crypto.CA, certCreator TargetCertCreator, keyAlgorithm crypto.KeyAlgorithm, keys secretDataKeys) error {
 	if targetCertKeyPairSecret.Annotations == nil {
 		targetCertKeyPairSecret.Annotations = map[string]string{}
 	}
-	if targetCertKeyPairSecret.Data == nil {
-		targetCertKeyPairSecret.Data = map[string][]byte{}
-	}
 
 	// our annotation is based on our cert validity, so we want to make sure that we don't specify something past our signer
 	targetValidity := validity
@@ -199,15 +288,31 @@ func setTargetCertKeyPairSecret(targetCertKeyPairSecret *corev1.Secret, validity
 		targetValidity = remainingSignerValidity
 	}
 
-	certKeyPair, err := certCreator.NewCertificate(signer, targetValidity)
+	create := func(signer *crypto.CA) (*crypto.TLSCertificateConfig, error) {
+		if keyAlgorithmCreator, ok := certCreator.(TargetCertKeyAlgorithmCreator); ok {
+			return keyAlgorithmCreator.NewCertificateWithKeyAlgorithm(signer, targetValidity, keyAlgorithm)
+		}
+		if len(keyAlgorithm) > 0 {
+			return nil, fmt.Errorf("%T does not support key algorithm %q", certCreator, keyAlgorithm)
+		}
+		return certCreator.NewCertificate(signer, targetValidity)
+	}
+	var certKeyPair *crypto.TLSCertificateConfig
+	var err error
+	if backend, ok := signerBackendOf(signer); ok {
+		certKeyPair, err = newCertificateWithSignerBackend(ctx, backend, targetValidity, create)
+	} else {
+		certKeyPair, err = create(signer)
+	}
 	if err != nil {
 		return err
 	}
 
-	targetCertKeyPairSecret.Data["tls.crt"], targetCertKeyPairSecret.Data["tls.key"], err = certKeyPair.GetPEMBytes()
+	certBytes, keyBytes, err := certKeyPair.GetPEMBytes()
 	if err != nil {
 		return err
 	}
+	keys.set(targetCertKeyPairSecret, certBytes, keyBytes)
 	targetCertKeyPairSecret.Annotations[CertificateNotAfterAnnotation] = certKeyPair.Certs[0].NotAfter.Format(time.RFC3339)
 	targetCertKeyPairSecret.Annotations[CertificateNotBeforeAnnotation] = certKeyPair.Certs[0].NotBefore.Format(time.RFC3339)
 	targetCertKeyPairSecret.Annotations[CertificateIssuer] = certKeyPair.Certs[0].Issuer.CommonName
@@ -216,16 +321,56 @@ func setTargetCertKeyPairSecret(targetCertKeyPairSecret *corev1.Secret, validity
 	return nil
 }
 
+// AuthorityInfoAccess adds the Authority Information Access extension to the certificates of a TargetCertCreator
+// embedding it, e.g. for OCSP stapling. Changes apply to the certificates issued afterwards.
+type AuthorityInfoAccess struct {
+	// OCSPServer are the URLs of the OCSP responders of the issuer.
+	OCSPServer []string
+	// IssuingCertificateURL are the URLs of the certificate of the issuer.
+	IssuingCertificateURL []string
+}
+
+// extensionFns returns the extension adding the Authority Information Access, none if no URL is set.
+func (a AuthorityInfoAccess) extensionFns() []crypto.CertificateExtensionFunc {
+	if len(a.OCSPServer) == 0 && len(a.IssuingCertificateURL) == 0 {
+		return nil
+	}
+	return []crypto.CertificateExtensionFunc{crypto.WithAuthorityInfoAccess(a.OCSPServer, a.IssuingCertificateURL)}
+}
+
 type ClientRotation struct {
 	UserInfo user.Info
+	// UserInfoChanged, if set, triggers a check of the certificate when the name, uid or groups of UserInfo might
+	// have changed, e.g. through a RecheckNotifier. A certificate for another user is rotated.
+	UserInfoChanged <-chan struct{}
+
+	AuthorityInfoAccess
 }
 
 func (r *ClientRotation) NewCertificate(signer *crypto.CA, validity time.Duration) (*crypto.TLSCertificateConfig, error) {
-	return signer.MakeClientCertificateForDuration(r.UserInfo, validity)
+	return r.NewCertificateWithKeyAlgorithm(signer, validity, crypto.DefaultKeyAlgorithm)
+}
+
+func (r *ClientRotation) NewCertificateWithKeyAlgorithm(signer *crypto.CA, validity time.Duration, keyAlgorithm crypto.KeyAlgorithm) (*crypto.TLSCertificateConfig, error) {
+	return signer.MakeClientCertificateForDurationWithKeyAlgorithm(r.UserInfo, validity, keyAlgorithm, r.extensionFns()...)
+}
+
+func (r *ClientRotation) RecheckChannel() <-chan struct{} {
+	return r.UserInfoChanged
+}
+
+func (r *ClientRotation) identityMismatch(certificate *x509.Certificate) string {
+	existing := certificate.Subject
+	if existing.CommonName != r.UserInfo.GetName() || existing.SerialNumber != r.UserInfo.GetUID() ||
+		!sets.NewString(existing.Organization...).Equal(sets.NewString(r.UserInfo.GetGroups()...)) {
+		return fmt.Sprintf("user %q with groups %q is existing, %q with groups %q is required",
+			existing.CommonName, strings.Join(existing.Organization, ","), r.UserInfo.GetName(), strings.Join(r.UserInfo.GetGroups(), ","))
+	}
+	return ""
 }
 
 func (r *ClientRotation) NeedNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool) string {
-	return needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired)
+	return needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, 0)
 }
 
 func (r *ClientRotation) SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string {
@@ -236,13 +381,19 @@ type ServingRotation struct {
 	Hostnames              ServingHostnameFunc
 	CertificateExtensionFn []crypto.CertificateExtensionFunc
 	HostnamesChanged       <-chan struct{}
+
+	AuthorityInfoAccess
 }
 
 func (r *ServingRotation) NewCertificate(signer *crypto.CA, validity time.Duration) (*crypto.TLSCertificateConfig, error) {
+	return r.NewCertificateWithKeyAlgorithm(signer, validity, crypto.DefaultKeyAlgorithm)
+}
+
+func (r *ServingRotation) NewCertificateWithKeyAlgorithm(signer *crypto.CA, validity time.Duration, keyAlgorithm crypto.KeyAlgorithm) (*crypto.TLSCertificateConfig, error) {
 	if len(r.Hostnames()) == 0 {
 		return nil, fmt.Errorf("no hostnames set")
 	}
-	return signer.MakeServerCertForDuration(sets.NewString(r.Hostnames()...), validity, r.CertificateExtensionFn...)
+	return signer.MakeServerCertForDurationWithKeyAlgorithm(sets.NewString(r.Hostnames()...), validity, keyAlgorithm, append(r.extensionFns(), r.CertificateExtensionFn...)...)
 }
 
 func (r *ServingRotation) RecheckChannel() <-chan struct{} {
@@ -250,7 +401,7 @@ func (r *ServingRotation) RecheckChannel() <-chan struct{} {
 }
 
 func (r *ServingRotation) NeedNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool) string {
-	reason := needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired)
+	reason := needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, 0)
 	if len(reason) > 0 {
 		return reason
 	}
@@ -288,17 +439,78 @@ type ServingHostnameFunc func() []string
 
 type SignerRotation struct {
 	SignerName string
+
+	AuthorityInfoAccess
 }
 
 func (r *SignerRotation) NewCertificate(signer *crypto.CA, validity time.Duration) (*crypto.TLSCertificateConfig, error) {
+	return r.NewCertificateWithKeyAlgorithm(signer, validity, crypto.DefaultKeyAlgorithm)
+}
+
+func (r *SignerRotation) NewCertificateWithKeyAlgorithm(signer *crypto.CA, validity time.Duration, keyAlgorithm crypto.KeyAlgorithm) (*crypto.TLSCertificateConfig, error) {
 	signerName := fmt.Sprintf("%s_@%d", r.SignerName, time.Now().Unix())
-	return crypto.MakeCAConfigForDuration(signerName, validity, signer)
+	return crypto.MakeCAConfigForDurationWithKeyAlgorithm(signerName, validity, signer, keyAlgorithm, r.extensionFns()...)
 }
 
 func (r *SignerRotation) NeedNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool) string {
-	return needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired)
+	return needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, 0)
 }
 
 func (r *SignerRotation) SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string {
 	return annotations
 }
+
+// SPIFFERotation issues X509-SVIDs, certificates identifying a workload by its SPIFFE ID as URI SAN, for workloads
+// authenticating each other with mutual TLS. The certificates are valid as client and as serving certificates.
+type SPIFFERotation struct {
+	// SPIFFEID returns the SPIFFE ID of the workload, e.g. spiffe://cluster.local/ns/foo/sa/bar.
+	SPIFFEID SPIFFEIDFunc
+	// SPIFFEIDChanged, if set, triggers a check of the certificate when the SPIFFE ID might have changed.
+	SPIFFEIDChanged <-chan struct{}
+
+	AuthorityInfoAccess
+}
+
+type SPIFFEIDFunc func() string
+
+func (r *SPIFFERotation) NewCertificate(signer *crypto.CA, validity time.Duration) (*crypto.TLSCertificateConfig, error) {
+	return r.NewCertificateWithKeyAlgorithm(signer, validity, crypto.DefaultKeyAlgorithm)
+}
+
+func (r *SPIFFERotation) NewCertificateWithKeyAlgorithm(signer *crypto.CA, validity time.Duration, keyAlgorithm crypto.KeyAlgorithm) (*crypto.TLSCertificateConfig, error) {
+	return signer.MakeSPIFFECertificateForDurationWithKeyAlgorithm(r.SPIFFEID(), validity, keyAlgorithm, r.extensionFns()...)
+}
+
+func (r *SPIFFERotation) RecheckChannel() <-chan struct{} {
+	return r.SPIFFEIDChanged
+}
+
+func (r *SPIFFERotation) NeedNewTargetCertKeyPair(annotations map[string]string, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired bool) string {
+	reason := needNewTargetCertKeyPair(annotations, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, 0)
+	if len(reason) > 0 {
+		return reason
+	}
+
+	if existing, required := annotations[CertificateSPIFFEID], r.SPIFFEID(); existing != required {
+		return fmt.Sprintf("SPIFFE ID %q is existing, %q is required", existing, required)
+	}
+	return ""
+}
+
+func (r *SPIFFERotation) identityMismatch(certificate *x509.Certificate) string {
+	existing := ""
+	if len(certificate.URIs) > 0 {
+		existing = certificate.URIs[0].String()
+	}
+	if required := r.SPIFFEID(); existing != required {
+		return fmt.Sprintf("SPIFFE ID %q is existing, %q is required", existing, required)
+	}
+	return ""
+}
+
+func (r *SPIFFERotation) SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string {
+	if uris := cert.Certs[0].URIs; len(uris) > 0 {
+		annotations[CertificateSPIFFEID] = uris[0].String()
+	}
+	return annotations
+}
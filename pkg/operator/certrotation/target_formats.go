@@ -0,0 +1,150 @@
+package certrotation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// TargetOutputFormat is an additional serialization of the target cert/key pair stored next to the PEM encoded
+// certificate and key.
+type TargetOutputFormat string
+
+const (
+	// TargetOutputFormatCombinedPEM stores the private key followed by the certificate chain under CombinedPEMKey, for
+	// servers like HAProxy which read both from a single file.
+	TargetOutputFormatCombinedPEM TargetOutputFormat = "CombinedPEM"
+	// TargetOutputFormatPKCS12 stores a PKCS#12 keystore holding the private key and the certificate chain under
+	// PKCS12KeystoreKey, protected by the passphrase of RotatedSelfSignedCertKeySecret.KeystorePassphrase.
+	TargetOutputFormatPKCS12 TargetOutputFormat = "PKCS12"
+
+	// CombinedPEMKey is the secret data key holding the private key and the certificate chain PEM encoded.
+	CombinedPEMKey = "tls-combined.pem"
+	// PKCS12KeystoreKey is the secret data key holding the PKCS#12 keystore. The alias of the key entry is "tls".
+	PKCS12KeystoreKey = "keystore.p12"
+
+	// OutputFormatsHashAnnotation is the hash of the cert/key pair, the passphrase and the formats the additional
+	// serializations were created from. The PKCS#12 encoding is randomized, so the serializations are only recreated
+	// when the hash changes.
+	OutputFormatsHashAnnotation = "certrotation.openshift.io/output-formats-hash"
+)
+
+// targetOutputFormatKeys maps the additional formats to the secret data keys holding them.
+var targetOutputFormatKeys = map[TargetOutputFormat]string{
+	TargetOutputFormatCombinedPEM: CombinedPEMKey,
+	TargetOutputFormatPKCS12:      PKCS12KeystoreKey,
+}
+
+// setTargetOutputFormats sets the requested additional serializations of the cert/key pair in the secret data and
+// returns whether the secret changed. The serializations of formats that are no longer requested are removed.
+func (c RotatedSelfSignedCertKeySecret) setTargetOutputFormats(secret *corev1.Secret, keys secretDataKeys) (bool, error) {
+	changed := false
+	for format, key := range targetOutputFormatKeys {
+		if _, ok := secret.Data[key]; ok && !hasTargetOutputFormat(c.OutputFormats, format) {
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	if len(c.OutputFormats) == 0 {
+		if _, ok := secret.Annotations[OutputFormatsHashAnnotation]; ok {
+			delete(secret.Annotations, OutputFormatsHashAnnotation)
+			// a trailing dash removes the annotation from the existing secret, see resourcemerge.MergeMap
+			secret.Annotations[OutputFormatsHashAnnotation+"-"] = ""
+			changed = true
+		}
+		return changed, nil
+	}
+
+	passphrase := ""
+	if hasTargetOutputFormat(c.OutputFormats, TargetOutputFormatPKCS12) {
+		var err error
+		if passphrase, err = c.keystorePassphrase(); err != nil {
+			return false, err
+		}
+	}
+
+	certBytes, keyBytes := secret.Data[keys.cert], secret.Data[keys.privateKey]
+	hash := targetOutputFormatsHash(certBytes, keyBytes, passphrase, c.OutputFormats)
+	if secret.Annotations[OutputFormatsHashAnnotation] == hash && hasTargetOutputFormatKeys(secret, c.OutputFormats) {
+		return changed, nil
+	}
+
+	certKeyPair, err := crypto.GetTLSCertificateConfigFromBytes(certBytes, keyBytes)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse the cert/key pair of %s/%s: %v", c.Namespace, c.Name, err)
+	}
+	for _, format := range c.OutputFormats {
+		var data []byte
+		switch format {
+		case TargetOutputFormatCombinedPEM:
+			data = append(append(append([]byte{}, keyBytes...), '\n'), certBytes...)
+		case TargetOutputFormatPKCS12:
+			if data, err = encodePKCS12Keystore(certKeyPair.Key, certKeyPair.Certs, passphrase); err != nil {
+				return false, err
+			}
+		default:
+			return false, fmt.Errorf("unsupported target output format %q", format)
+		}
+		secret.Data[targetOutputFormatKeys[format]] = data
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[OutputFormatsHashAnnotation] = hash
+	return true, nil
+}
+
+// keystorePassphrase returns the passphrase of the PKCS#12 keystore from the KeystorePassphrase secret.
+func (c RotatedSelfSignedCertKeySecret) keystorePassphrase() (string, error) {
+	if c.KeystorePassphrase == nil {
+		return "", fmt.Errorf("output format %q of %s/%s requires a KeystorePassphrase", TargetOutputFormatPKCS12, c.Namespace, c.Name)
+	}
+	passphraseSecret, err := c.Lister.Secrets(c.Namespace).Get(c.KeystorePassphrase.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the keystore passphrase of %s/%s: %v", c.Namespace, c.Name, err)
+	}
+	passphrase, ok := passphraseSecret.Data[c.KeystorePassphrase.Key]
+	if !ok || len(passphrase) == 0 {
+		return "", fmt.Errorf("keystore passphrase secret %s/%s has no key %q", c.Namespace, c.KeystorePassphrase.Name, c.KeystorePassphrase.Key)
+	}
+	return string(passphrase), nil
+}
+
+// targetOutputFormatsHash hashes the inputs of the additional serializations. The private key is hashed with the
+// passphrase, so the hash does not allow guessing the passphrase.
+func targetOutputFormatsHash(certBytes, keyBytes []byte, passphrase string, formats []TargetOutputFormat) string {
+	sortedFormats := make([]string, 0, len(formats))
+	for _, format := range formats {
+		sortedFormats = append(sortedFormats, string(format))
+	}
+	sort.Strings(sortedFormats)
+
+	hash := sha256.New()
+	for _, input := range append([]string{string(certBytes), string(keyBytes), passphrase}, sortedFormats...) {
+		fmt.Fprintf(hash, "%d:%s", len(input), input)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func hasTargetOutputFormatKeys(secret *corev1.Secret, formats []TargetOutputFormat) bool {
+	for _, format := range formats {
+		if _, ok := secret.Data[targetOutputFormatKeys[format]]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTargetOutputFormat(formats []TargetOutputFormat, format TargetOutputFormat) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
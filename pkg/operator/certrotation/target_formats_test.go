@@ -0,0 +1,145 @@
+package certrotation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestTargetOutputFormats(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+
+	passphraseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "passphrase"},
+		Data:       map[string][]byte{"passphrase": []byte("s3cret")},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(passphraseSecret)
+	client := kubefake.NewSimpleClientset()
+	target := &RotatedSelfSignedCertKeySecret{
+		Namespace:          "ns",
+		Name:               "target",
+		Validity:           24 * time.Hour,
+		Refresh:            12 * time.Hour,
+		CertCreator:        &ServingRotation{Hostnames: func() []string { return []string{"foo"} }},
+		OutputFormats:      []TargetOutputFormat{TargetOutputFormatCombinedPEM, TargetOutputFormatPKCS12},
+		KeystorePassphrase: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "passphrase"}, Key: "passphrase"},
+		Client:             client.CoreV1(),
+		Lister:             corev1listers.NewSecretLister(indexer),
+		EventRecorder:      events.NewInMemoryRecorder("test"),
+	}
+	ensure := func() *corev1.Secret {
+		t.Helper()
+		client.ClearActions()
+		if _, err := target.ensureTargetCertKeyPair(context.TODO(), signer, signer.Config.Certs); err != nil {
+			t.Fatal(err)
+		}
+		written := lastWrittenSecret(client)
+		if written != nil {
+			indexer.Add(written)
+		}
+		return written
+	}
+
+	written := ensure()
+	if written == nil {
+		t.Fatal("expected the target to be created")
+	}
+	if _, err := tls.X509KeyPair(written.Data[CombinedPEMKey], written.Data[CombinedPEMKey]); err != nil {
+		t.Errorf("expected the combined PEM to hold the cert/key pair: %v", err)
+	}
+	assertPKCS12Keystore(t, written.Data[PKCS12KeystoreKey], "s3cret", written.Data["tls.crt"])
+
+	// the keystore is not recreated while nothing changed
+	if written := ensure(); written != nil {
+		t.Errorf("expected no update, got %v", client.Actions())
+	}
+
+	// a new passphrase recreates the keystore
+	updatedPassphraseSecret := passphraseSecret.DeepCopy()
+	updatedPassphraseSecret.Data["passphrase"] = []byte("n3w")
+	indexer.Update(updatedPassphraseSecret)
+	written = ensure()
+	if written == nil {
+		t.Fatal("expected the keystore to be recreated")
+	}
+	assertPKCS12Keystore(t, written.Data[PKCS12KeystoreKey], "n3w", written.Data["tls.crt"])
+
+	// formats no longer requested are removed
+	target.OutputFormats = []TargetOutputFormat{TargetOutputFormatCombinedPEM}
+	written = ensure()
+	if written == nil {
+		t.Fatal("expected the keystore to be removed")
+	}
+	if _, ok := written.Data[PKCS12KeystoreKey]; ok {
+		t.Errorf("expected no %s, got keys %v", PKCS12KeystoreKey, written.Data)
+	}
+	if _, ok := written.Data[CombinedPEMKey]; !ok {
+		t.Errorf("expected %s to be kept", CombinedPEMKey)
+	}
+
+	// a rotation refreshes the serializations
+	rotated := written.DeepCopy()
+	rotated.Annotations[ForceRotationAnnotation] = "test"
+	indexer.Update(rotated)
+	written = ensure()
+	if written == nil {
+		t.Fatal("expected a rotation")
+	}
+	if !bytes.Contains(written.Data[CombinedPEMKey], written.Data["tls.crt"]) || !bytes.HasPrefix(written.Data[CombinedPEMKey], written.Data["tls.key"]) {
+		t.Errorf("expected the combined PEM to hold the rotated cert/key pair")
+	}
+}
+
+func TestTargetOutputFormatsRequirePassphrase(t *testing.T) {
+	target := RotatedSelfSignedCertKeySecret{Namespace: "ns", Name: "target", OutputFormats: []TargetOutputFormat{TargetOutputFormatPKCS12}}
+	_, err := target.setTargetOutputFormats(&corev1.Secret{}, newSecretDataKeys("", ""))
+	if err == nil || !strings.Contains(err.Error(), "requires a KeystorePassphrase") {
+		t.Errorf("expected an error without a passphrase, got %v", err)
+	}
+}
+
+// assertPKCS12Keystore checks that OpenSSL verifies the MAC of the keystore and decrypts the key of the certificate.
+func assertPKCS12Keystore(t *testing.T, keystore []byte, passphrase string, certPEM []byte) {
+	t.Helper()
+	if len(keystore) == 0 {
+		t.Fatal("expected a PKCS#12 keystore")
+	}
+	openssl, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl not found")
+	}
+	keystoreFile := filepath.Join(t.TempDir(), "keystore.p12")
+	if err := os.WriteFile(keystoreFile, keystore, 0600); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(openssl, "pkcs12", "-in", keystoreFile, "-passin", "pass:"+passphrase, "-nodes").CombinedOutput()
+	if err != nil {
+		t.Fatalf("openssl failed to read the keystore: %v\n%s", err, output)
+	}
+	if _, err := tls.X509KeyPair(certPEM, output); err != nil {
+		t.Errorf("expected the keystore to hold the key of the certificate: %v\n%s", err, output)
+	}
+	if !strings.Contains(string(output), "friendlyName: tls") {
+		t.Errorf("expected the alias tls, got\n%s", output)
+	}
+}
@@ -3,6 +3,7 @@ package certrotation
 import (
 	"context"
 	"crypto/x509/pkix"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	clienttesting "k8s.io/client-go/testing"
@@ -123,7 +125,7 @@ func TestNeedNewTargetCertKeyPairForTime(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			actual := needNewTargetCertKeyPairForTime(test.annotations, signer, test.refresh, test.refreshOnlyWhenExpired)
+			actual := needNewTargetCertKeyPairForTime(test.annotations, signer, test.refresh, test.refreshOnlyWhenExpired, 0)
 			if !strings.HasPrefix(actual, test.expected) {
 				t.Errorf("expected %v, got %v", test.expected, actual)
 			}
@@ -229,7 +231,7 @@ func TestEnsureTargetCertKeyPair(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = c.ensureTargetCertKeyPair(context.TODO(), newCA, newCA.Config.Certs)
+			_, err = c.ensureTargetCertKeyPair(context.TODO(), newCA, newCA.Config.Certs)
 			switch {
 			case err != nil && len(test.expectedError) == 0:
 				t.Error(err)
@@ -423,7 +425,7 @@ func TestEnsureTargetSignerCertKeyPair(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = c.ensureTargetCertKeyPair(context.TODO(), newCA, newCA.Config.Certs)
+			_, err = c.ensureTargetCertKeyPair(context.TODO(), newCA, newCA.Config.Certs)
 			switch {
 			case err != nil && len(test.expectedError) == 0:
 				t.Error(err)
@@ -437,3 +439,30 @@ func TestEnsureTargetSignerCertKeyPair(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorityInfoAccess(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	aia := AuthorityInfoAccess{OCSPServer: []string{"http://ocsp.example.com"}, IssuingCertificateURL: []string{"http://pki.example.com/signer.crt"}}
+	creators := map[string]TargetCertCreator{
+		"client":  &ClientRotation{UserInfo: &user.DefaultInfo{Name: "foo"}, AuthorityInfoAccess: aia},
+		"serving": &ServingRotation{Hostnames: func() []string { return []string{"foo.svc"} }, AuthorityInfoAccess: aia},
+		"signer":  &SignerRotation{SignerName: "intermediate", AuthorityInfoAccess: aia},
+		"spiffe":  &SPIFFERotation{SPIFFEID: func() string { return "spiffe://cluster.local/ns/foo/sa/bar" }, AuthorityInfoAccess: aia},
+	}
+	for name, creator := range creators {
+		t.Run(name, func(t *testing.T) {
+			certKeyPair, err := creator.NewCertificate(signer, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			certificate := certKeyPair.Certs[0]
+			if !reflect.DeepEqual(certificate.OCSPServer, aia.OCSPServer) || !reflect.DeepEqual(certificate.IssuingCertificateURL, aia.IssuingCertificateURL) {
+				t.Errorf("expected %v, got OCSP servers %v and issuing certificate URLs %v", aia, certificate.OCSPServer, certificate.IssuingCertificateURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+)
+
+// RotationTimelineConfigMap maintains a ConfigMap describing the current validity and the next planned rotation of
+// the signer and the target cert of a CertRotationController, so that operands which need to coordinate reload
+// windows (e.g. draining long-lived connections) can plan without parsing certificates.
+// Multiple controllers can share one ConfigMap; each of them owns the key named "<target namespace>.<target name>".
+type RotationTimelineConfigMap struct {
+	// Namespace is the namespace of the ConfigMap to maintain.
+	Namespace string
+	// Name is the name of the ConfigMap to maintain.
+	Name string
+
+	// Plumbing:
+	Lister        corev1listers.ConfigMapLister
+	Client        corev1client.ConfigMapsGetter
+	EventRecorder events.Recorder
+}
+
+// RotationTimeline is the value stored per target in the rotation timeline ConfigMap.
+type RotationTimeline struct {
+	Signer CertRotationTimes `json:"signer"`
+	Target CertRotationTimes `json:"target"`
+}
+
+// CertRotationTimes describes the validity of a certificate and when it will be rotated at the latest.
+type CertRotationTimes struct {
+	NotBefore    metav1.Time `json:"notBefore"`
+	NotAfter     metav1.Time `json:"notAfter"`
+	NextRotation metav1.Time `json:"nextRotation"`
+}
+
+// WithRotationTimeline makes the CertRotationController maintain the given rotation timeline ConfigMap.
+func WithRotationTimeline(timeline RotationTimelineConfigMap) CertRotationControllerOption {
+	return func(c *CertRotationController) {
+		c.rotationTimeline = &timeline
+	}
+}
+
+// ensureRotationTimeline records the rotation times of the signer and the target as returned by the preceding ensure
+// steps, the listers may not have caught up with their writes yet. The shared ConfigMap is updated with a live read
+// and retried on conflicts, so that keys written by other controllers are never dropped.
+func (c RotationTimelineConfigMap) ensureRotationTimeline(ctx context.Context, signer RotatedSigningCASecret, signingCertKeyPair *crypto.CA, target RotatedSelfSignedCertKeySecret, targetSecret *corev1.Secret) error {
+	signerCert := signingCertKeyPair.Config.Certs[0]
+	timeline := RotationTimeline{
+		Signer: rotationTimes(signerCert.NotBefore, signerCert.NotAfter, signer.Refresh, signer.RefreshOnlyWhenExpired, refreshJitter(signer.Namespace, signer.Name, signer.RefreshJitter)),
+		Target: rotationTimesFromAnnotations(targetSecret.Annotations, target.Refresh, target.RefreshOnlyWhenExpired, refreshJitter(target.Namespace, target.Name, target.RefreshJitter)),
+	}
+	timelineBytes, err := json.Marshal(timeline)
+	if err != nil {
+		return err
+	}
+	key := target.Namespace + "." + target.Name
+
+	// avoid a live read on every sync when the cache is already up to date
+	if cached, err := c.Lister.ConfigMaps(c.Namespace).Get(c.Name); err == nil && cached.Data[key] == string(timelineBytes) {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := c.Client.ConfigMaps(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			required := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name},
+				Data:       map[string]string{key: string(timelineBytes)},
+			}
+			_, err := c.Client.ConfigMaps(c.Namespace).Create(ctx, required, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// created concurrently by another controller, retry as update
+				return apierrors.NewConflict(corev1.Resource("configmaps"), c.Name, err)
+			}
+			if err == nil {
+				c.EventRecorder.Eventf("ConfigMapCreated", "Created %s because it was missing", resourcehelper.FormatResourceForCLIWithNamespace(required))
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Data[key] == string(timelineBytes) {
+			return nil
+		}
+		required := existing.DeepCopy()
+		if required.Data == nil {
+			required.Data = map[string]string{}
+		}
+		required.Data[key] = string(timelineBytes)
+		if _, err := c.Client.ConfigMaps(c.Namespace).Update(ctx, required, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		c.EventRecorder.Eventf("ConfigMapUpdated", "Updated rotation timeline of %s in %s", key, resourcehelper.FormatResourceForCLIWithNamespace(required))
+		return nil
+	})
+}
+
+// rotationTimesFromAnnotations is rotationTimes for the validity recorded in the cert annotations of a secret.
+func rotationTimesFromAnnotations(annotations map[string]string, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) CertRotationTimes {
+	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
+	if len(reason) > 0 {
+		return CertRotationTimes{}
+	}
+	return rotationTimes(notBefore, notAfter, refresh, refreshOnlyWhenExpired, jitter)
+}
+
+// rotationTimes computes when a cert with the given validity is rotated at the latest, mirroring the time based
+// checks of needNewSigningCertKeyPair and needNewTargetCertKeyPairForTime, including the refresh jitter.
+func rotationTimes(notBefore, notAfter time.Time, refresh time.Duration, refreshOnlyWhenExpired bool, jitter time.Duration) CertRotationTimes {
+	return CertRotationTimes{
+		NotBefore:    metav1.NewTime(notBefore),
+		NotAfter:     metav1.NewTime(notAfter),
+		NextRotation: metav1.NewTime(refreshTime(notBefore, notAfter, refresh, refreshOnlyWhenExpired, jitter)),
+	}
+}
@@ -0,0 +1,117 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRotationTimes(t *testing.T) {
+	notBefore := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(100 * time.Hour)
+	annotations := map[string]string{
+		CertificateNotBeforeAnnotation: notBefore.Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  notAfter.Format(time.RFC3339),
+	}
+
+	tests := []struct {
+		name                   string
+		refresh                time.Duration
+		refreshOnlyWhenExpired bool
+		jitter                 time.Duration
+		expected               time.Time
+	}{
+		{name: "refresh before 80%", refresh: 50 * time.Hour, expected: notBefore.Add(50 * time.Hour)},
+		{name: "80% before refresh", refresh: 90 * time.Hour, expected: notBefore.Add(80 * time.Hour)},
+		{name: "only when expired", refresh: 50 * time.Hour, refreshOnlyWhenExpired: true, expected: notAfter},
+		{name: "refresh with jitter", refresh: 50 * time.Hour, jitter: 5 * time.Hour, expected: notBefore.Add(55 * time.Hour)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := rotationTimesFromAnnotations(annotations, test.refresh, test.refreshOnlyWhenExpired, test.jitter)
+			if !actual.NextRotation.Time.Equal(test.expected) {
+				t.Errorf("expected next rotation at %v, got %v", test.expected, actual.NextRotation)
+			}
+		})
+	}
+
+	if actual := rotationTimesFromAnnotations(nil, time.Hour, false, 0); !actual.NextRotation.IsZero() {
+		t.Errorf("expected empty times without annotations, got %v", actual)
+	}
+}
+
+func TestEnsureRotationTimeline(t *testing.T) {
+	signingCertKeyPair, err := crypto.MakeSelfSignedCAConfigForDuration("signer", 10*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerCA := &crypto.CA{Config: signingCertKeyPair, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	signerNotBefore := signingCertKeyPair.Certs[0].NotBefore
+
+	targetNotBefore := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	targetSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "target", Annotations: map[string]string{
+		CertificateNotBeforeAnnotation: targetNotBefore.Format(time.RFC3339),
+		CertificateNotAfterAnnotation:  targetNotBefore.Add(10 * time.Hour).Format(time.RFC3339),
+	}}}
+
+	// the lister is stale, the other controller's key was written after it was synced
+	stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "timeline"}}
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	configMapIndexer.Add(stale)
+	existing := stale.DeepCopy()
+	existing.Data = map[string]string{"other.target": "{}"}
+	client := kubefake.NewSimpleClientset(existing)
+	conflicts := 0
+	client.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts == 0 {
+			conflicts++
+			return true, nil, apierrors.NewConflict(corev1.Resource("configmaps"), "timeline", fmt.Errorf("stale"))
+		}
+		return false, nil, nil
+	})
+
+	c := RotationTimelineConfigMap{
+		Namespace:     "ns",
+		Name:          "timeline",
+		Lister:        corev1listers.NewConfigMapLister(configMapIndexer),
+		Client:        client.CoreV1(),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	signer := RotatedSigningCASecret{Namespace: "ns", Name: "signer", Refresh: 2 * time.Hour}
+	target := RotatedSelfSignedCertKeySecret{Namespace: "ns", Name: "target", Refresh: 4 * time.Hour}
+	if err := c.ensureRotationTimeline(context.TODO(), signer, signerCA, target, targetSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "timeline", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := actual.Data["other.target"]; !ok {
+		t.Errorf("expected keys of other controllers to be kept, got %v", actual.Data)
+	}
+	timeline := RotationTimeline{}
+	if err := json.Unmarshal([]byte(actual.Data["ns.target"]), &timeline); err != nil {
+		t.Fatal(err)
+	}
+	if expected := signerNotBefore.Add(2 * time.Hour); !timeline.Signer.NextRotation.Time.Equal(expected) {
+		t.Errorf("expected signer rotation at %v, got %v", expected, timeline.Signer.NextRotation)
+	}
+	if expected := targetNotBefore.Add(4 * time.Hour); !timeline.Target.NextRotation.Time.Equal(expected) {
+		t.Errorf("expected target rotation at %v, got %v", expected, timeline.Target.NextRotation)
+	}
+}
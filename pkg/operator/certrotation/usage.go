@@ -0,0 +1,168 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/reasons"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// CertificateLastUsedAnnotation contains the time, in RFC3339 format, an operand last used the certificate of a
+// target secret. Operands which do not mount the secret, e.g. because they read it through the API, stamp it with
+// StampLastUsed.
+const CertificateLastUsedAnnotation = "auth.openshift.io/certificate-last-used"
+
+// StampLastUsed sets the CertificateLastUsedAnnotation of the secret to now.
+func StampLastUsed(ctx context.Context, client corev1client.SecretsGetter, namespace, name string, now time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{CertificateLastUsedAnnotation: now.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.Secrets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// PodReferencesSecret returns true if the pod mounts the secret or reads env vars from it.
+func PodReferencesSecret(pod *corev1.Pod, secretName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == secretName {
+			return true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && source.Secret.Name == secretName {
+					return true
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == secretName {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnusedTargets returns the registered target secrets which no pod references and which were not stamped as used
+// within maxIdle. Targets in namespaces without informers are considered used, because their pods are unknown.
+func UnusedTargets(registry *ManagedResourceRegistry, kubeInformers v1helpers.KubeInformersForNamespaces, now time.Time, maxIdle time.Duration) ([]ManagedResource, error) {
+	namespaces := kubeInformers.Namespaces()
+	var unused []ManagedResource
+	for _, resource := range registry.List() {
+		if resource.Kind != ManagedResourceKindSecret || resource.CertificateType != CertificateTypeTarget || !namespaces.Has(resource.Namespace) {
+			continue
+		}
+
+		secret, err := kubeInformers.SecretLister().Secrets(resource.Namespace).Get(resource.Name)
+		if err != nil {
+			// missing secrets are not stale, they are about to be created
+			continue
+		}
+		if lastUsed, err := time.Parse(time.RFC3339, secret.Annotations[CertificateLastUsedAnnotation]); err == nil && now.Sub(lastUsed) < maxIdle {
+			continue
+		}
+
+		pods, err := kubeInformers.PodLister().Pods(resource.Namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		used := false
+		for _, pod := range pods {
+			if PodReferencesSecret(pod, resource.Name) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			unused = append(unused, resource)
+		}
+	}
+	return unused, nil
+}
+
+type unusedCertificatesController struct {
+	name           string
+	registry       *ManagedResourceRegistry
+	kubeInformers  v1helpers.KubeInformersForNamespaces
+	operatorClient v1helpers.OperatorClient
+	maxIdle        time.Duration
+	now            func() time.Time
+}
+
+// NewUnusedCertificatesController reports the target secrets of the registry which no pod uses, to help clean up
+// dead PKI objects. It sets the condition <name>UnusedCertificates to True listing them. The pods and secrets of the
+// target namespaces must be in kubeInformers.
+func NewUnusedCertificatesController(
+	name string,
+	registry *ManagedResourceRegistry,
+	maxIdle time.Duration,
+	operatorClient v1helpers.OperatorClient,
+	kubeInformers v1helpers.KubeInformersForNamespaces,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &unusedCertificatesController{
+		name:           name,
+		registry:       registry,
+		kubeInformers:  kubeInformers,
+		operatorClient: operatorClient,
+		maxIdle:        maxIdle,
+		now:            time.Now,
+	}
+	f := factory.New().WithSync(c.sync).ResyncEvery(10 * time.Minute)
+	for _, namespace := range kubeInformers.Namespaces().List() {
+		informers := kubeInformers.InformersFor(namespace)
+		f = f.WithBareInformers(informers.Core().V1().Pods().Informer(), informers.Core().V1().Secrets().Informer())
+	}
+	return f.ToController(name+"UnusedCertificatesController", recorder.WithComponentSuffix("unused-certificates-controller"))
+}
+
+func (c *unusedCertificatesController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	unused, err := UnusedTargets(c.registry, c.kubeInformers, c.now(), c.maxIdle)
+	if err != nil {
+		return err
+	}
+
+	condition := operatorv1.OperatorCondition{
+		Type:   c.name + "UnusedCertificates",
+		Status: operatorv1.ConditionFalse,
+		Reason: string(reasons.AllCertificatesUsed),
+	}
+	if len(unused) > 0 {
+		var names []string
+		for _, resource := range unused {
+			names = append(names, fmt.Sprintf("secret/%s -n %s", resource.Name, resource.Namespace))
+		}
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = string(reasons.UnusedCertificates)
+		condition.Message = fmt.Sprintf("The certificates of %s are not used by any pod and were not stamped as used for %s", strings.Join(names, ", "), c.maxIdle)
+	}
+	_, _, err = v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition))
+	return err
+}
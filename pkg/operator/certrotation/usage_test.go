@@ -0,0 +1,70 @@
+package certrotation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestUnusedTargets(t *testing.T) {
+	now := time.Now()
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(fake.NewSimpleClientset(), "ns")
+	secrets := kubeInformers.InformersFor("ns").Core().V1().Secrets().Informer().GetIndexer()
+	pods := kubeInformers.InformersFor("ns").Core().V1().Pods().Informer().GetIndexer()
+
+	registry := NewManagedResourceRegistry()
+	for _, name := range []string{"mounted", "projected", "env", "stamped", "stamped-long-ago", "unused", "missing"} {
+		registry.Register(ManagedResource{Controller: "c", Kind: ManagedResourceKindSecret, Namespace: "ns", Name: name, CertificateType: CertificateTypeTarget})
+		if name == "missing" {
+			continue
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+		switch name {
+		case "stamped":
+			secret.Annotations = map[string]string{CertificateLastUsedAnnotation: now.Add(-time.Hour).Format(time.RFC3339)}
+		case "stamped-long-ago":
+			secret.Annotations = map[string]string{CertificateLastUsedAnnotation: now.Add(-48 * time.Hour).Format(time.RFC3339)}
+		}
+		if err := secrets.Add(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry.Register(
+		ManagedResource{Controller: "c", Kind: ManagedResourceKindSecret, Namespace: "ns", Name: "signer", CertificateType: CertificateTypeSigner},
+		ManagedResource{Controller: "c", Kind: ManagedResourceKindSecret, Namespace: "other", Name: "unknown-namespace", CertificateType: CertificateTypeTarget},
+	)
+
+	if err := pods.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod"},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "a", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "mounted"}}},
+				{Name: "b", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+					{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected"}}},
+				}}}},
+			},
+			Containers: []corev1.Container{{Name: "c", Env: []corev1.EnvVar{{Name: "KEY", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}, Key: "tls.key"},
+			}}}}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	unused, err := UnusedTargets(registry, kubeInformers, now, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, resource := range unused {
+		names = append(names, resource.Name)
+	}
+	if expected := []string{"stamped-long-ago", "unused"}; len(names) != len(expected) || names[0] != expected[0] || names[1] != expected[1] {
+		t.Errorf("expected unused %v, got %v", expected, names)
+	}
+}
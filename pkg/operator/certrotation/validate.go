@@ -0,0 +1,188 @@
+package certrotation
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// CertChainErrorReason classifies a CertChainError.
+type CertChainErrorReason string
+
+const (
+	// CertChainInvalid is reported for missing or unparsable certificates and keys, and keys not matching their certificate.
+	CertChainInvalid CertChainErrorReason = "Invalid"
+	// CertChainExpired is reported for an expired or not yet valid signer or target certificate.
+	CertChainExpired CertChainErrorReason = "Expired"
+	// CertChainSignerNotInBundle is reported when the CA bundle does not contain the current signer certificate.
+	CertChainSignerNotInBundle CertChainErrorReason = "SignerNotInBundle"
+	// CertChainNotSignedByBundle is reported when the target certificate does not verify against the CA bundle.
+	CertChainNotSignedByBundle CertChainErrorReason = "NotSignedByBundle"
+	// CertChainHostnameMismatch is reported when the target certificate does not certify the hostnames recorded in
+	// its CertificateHostnames annotation.
+	CertChainHostnameMismatch CertChainErrorReason = "HostnameMismatch"
+	// CertChainWrongKeyUsage is reported for a signer or CA target certificate that cannot sign certificates, and a
+	// target certificate that cannot authenticate a server or client.
+	CertChainWrongKeyUsage CertChainErrorReason = "WrongKeyUsage"
+)
+
+// CertChainError is a problem of a signer, CA bundle and target triple found by ValidateCertChain.
+type CertChainError struct {
+	Reason CertChainErrorReason
+	// Resource is the object the problem was found in, e.g. "secret ns/name".
+	Resource string
+	Message  string
+}
+
+func (e *CertChainError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Resource, e.Reason, e.Message)
+}
+
+// ValidateCertChain checks a signer secret, CA bundle config map and target secret as written by the cert rotation
+// controller, without running it, e.g. for preflight checks of operators or tests against live clusters. The
+// certificates and keys are read from the tls.crt and tls.key keys of the secrets and the ca-bundle.crt key of the
+// config map. A signer without a private key, e.g. one with an external signer or a signer backend, is checked by its
+// certificate alone. It returns all problems found, none if the triple is valid.
+func ValidateCertChain(signerSecret *corev1.Secret, caBundleConfigMap *corev1.ConfigMap, targetSecret *corev1.Secret) []*CertChainError {
+	return validateCertChainAt(signerSecret, caBundleConfigMap, targetSecret, time.Now())
+}
+
+func validateCertChainAt(signerSecret *corev1.Secret, caBundleConfigMap *corev1.ConfigMap, targetSecret *corev1.Secret, now time.Time) []*CertChainError {
+	var errs []*CertChainError
+	report := func(reason CertChainErrorReason, resource string, format string, args ...interface{}) {
+		errs = append(errs, &CertChainError{Reason: reason, Resource: resource, Message: fmt.Sprintf(format, args...)})
+	}
+	signerResource := fmt.Sprintf("secret %s/%s", signerSecret.Namespace, signerSecret.Name)
+	caBundleResource := fmt.Sprintf("configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
+	targetResource := fmt.Sprintf("secret %s/%s", targetSecret.Namespace, targetSecret.Name)
+
+	var signerCert *x509.Certificate
+	if signerKey := signerSecret.Data[corev1.TLSPrivateKeyKey]; len(signerKey) == 0 {
+		// the key of an external signer or a signer backend lives outside of the cluster, only the certificate is checked
+		if certs, err := crypto.CertsFromPEM(signerSecret.Data[corev1.TLSCertKey]); err != nil {
+			report(CertChainInvalid, signerResource, "%v", err)
+		} else {
+			signerCert = certs[0]
+		}
+	} else if certKeyPair, err := crypto.GetTLSCertificateConfigFromBytes(signerSecret.Data[corev1.TLSCertKey], signerKey); err != nil {
+		report(CertChainInvalid, signerResource, "%v", err)
+	} else {
+		signerCert = certKeyPair.Certs[0]
+	}
+	if signerCert != nil {
+		if reason := validityMismatch(signerCert, now); len(reason) > 0 {
+			report(CertChainExpired, signerResource, "%s", reason)
+		}
+		if !signerCert.IsCA || signerCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+			report(CertChainWrongKeyUsage, signerResource, "certificate %q is not a CA that can sign certificates", signerCert.Subject.CommonName)
+		}
+	}
+
+	var bundle []*x509.Certificate
+	if caBundle := caBundleConfigMap.Data["ca-bundle.crt"]; len(caBundle) == 0 {
+		report(CertChainInvalid, caBundleResource, "missing ca-bundle.crt")
+	} else if certs, err := crypto.CertsFromPEM([]byte(caBundle)); err != nil {
+		report(CertChainInvalid, caBundleResource, "%v", err)
+	} else {
+		bundle = certs
+		if signerCert != nil && !containsCertificate(bundle, signerCert) {
+			report(CertChainSignerNotInBundle, caBundleResource, "missing signer certificate %q", signerCert.Subject.CommonName)
+		}
+	}
+
+	certKeyPair, err := crypto.GetTLSCertificateConfigFromBytes(targetSecret.Data[corev1.TLSCertKey], targetSecret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		report(CertChainInvalid, targetResource, "%v", err)
+		return errs
+	}
+	targetCert := certKeyPair.Certs[0]
+	expired := validityMismatch(targetCert, now)
+	if len(expired) > 0 {
+		report(CertChainExpired, targetResource, "%s", expired)
+	}
+
+	if bundle != nil {
+		roots := x509.NewCertPool()
+		for _, cert := range bundle {
+			roots.AddCert(cert)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certKeyPair.Certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		verifyTime := now
+		if len(expired) > 0 {
+			// the expiry is reported already, verify the signature within the validity of the target
+			verifyTime = targetCert.NotBefore
+		}
+		if _, err := targetCert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   verifyTime,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			report(CertChainNotSignedByBundle, targetResource, "%v", err)
+		}
+	}
+
+	hostnames := sets.NewString()
+	if annotation := targetSecret.Annotations[CertificateHostnames]; len(annotation) > 0 {
+		hostnames.Insert(strings.Split(annotation, ",")...)
+	}
+	certified := sets.NewString(targetCert.DNSNames...)
+	for _, ip := range targetCert.IPAddresses {
+		certified.Insert(ip.String())
+	}
+	if missing := hostnames.Difference(certified); missing.Len() > 0 {
+		report(CertChainHostnameMismatch, targetResource, "certificate does not certify %s", strings.Join(missing.List(), ","))
+	}
+
+	switch {
+	case targetCert.IsCA && targetCert.KeyUsage&x509.KeyUsageCertSign == 0:
+		report(CertChainWrongKeyUsage, targetResource, "certificate is a CA that cannot sign certificates")
+	case targetCert.IsCA:
+		// an intermediate signer, see SignerRotation
+	case targetCert.KeyUsage&x509.KeyUsageDigitalSignature == 0:
+		report(CertChainWrongKeyUsage, targetResource, "certificate cannot be used for digital signatures")
+	case hostnames.Len() > 0 && !hasExtKeyUsage(targetCert, x509.ExtKeyUsageServerAuth):
+		report(CertChainWrongKeyUsage, targetResource, "serving certificate cannot be used for server authentication")
+	case !hasExtKeyUsage(targetCert, x509.ExtKeyUsageServerAuth) && !hasExtKeyUsage(targetCert, x509.ExtKeyUsageClientAuth):
+		report(CertChainWrongKeyUsage, targetResource, "certificate cannot be used for server or client authentication")
+	}
+	return errs
+}
+
+// validityMismatch returns a non-empty reason if the certificate is not valid at the given time.
+func validityMismatch(cert *x509.Certificate, now time.Time) string {
+	switch {
+	case now.After(cert.NotAfter):
+		return fmt.Sprintf("certificate %q expired at %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+	case now.Before(cert.NotBefore):
+		return fmt.Sprintf("certificate %q is not valid before %s", cert.Subject.CommonName, cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasExtKeyUsage(cert *x509.Certificate, usage x509.ExtKeyUsage) bool {
+	for _, u := range cert.ExtKeyUsage {
+		if u == usage || u == x509.ExtKeyUsageAny {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package certrotation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func TestValidateCertChain(t *testing.T) {
+	newCA := func(name string) *crypto.CA {
+		t.Helper()
+		config, err := crypto.MakeSelfSignedCAConfigForDuration(name, 48*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	}
+	secret := func(name string, certKeyPair *crypto.TLSCertificateConfig, annotations map[string]string) *corev1.Secret {
+		t.Helper()
+		certBytes, keyBytes, err := certKeyPair.GetPEMBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Annotations: annotations},
+			Data:       map[string][]byte{"tls.crt": certBytes, "tls.key": keyBytes},
+		}
+	}
+	bundle := func(cas ...*crypto.CA) *corev1.ConfigMap {
+		t.Helper()
+		var certs []byte
+		for _, ca := range cas {
+			certBytes, _, err := ca.Config.GetPEMBytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			certs = append(certs, certBytes...)
+		}
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ca-bundle"}, Data: map[string]string{"ca-bundle.crt": string(certs)}}
+	}
+
+	signer, otherSigner := newCA("signer"), newCA("other-signer")
+	serving, err := signer.MakeServerCertForDuration(sets.NewString("foo.ns.svc", "10.0.0.1"), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := signer.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "foo"}, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servingAnnotations := map[string]string{CertificateHostnames: "10.0.0.1,foo.ns.svc"}
+	signerSecret := secret("signer", signer.Config, nil)
+	brokenTarget := secret("target", serving, servingAnnotations)
+	delete(brokenTarget.Data, "tls.key")
+	externalSignerSecret := secret("signer", signer.Config, nil)
+	externalSignerSecret.Data["tls.key"] = []byte{}
+	intermediate, err := (&SignerRotation{SignerName: "intermediate"}).NewCertificate(signer, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonSigningCA := func() *crypto.TLSCertificateConfig {
+		t.Helper()
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "non-signing-ca"},
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(24 * time.Hour),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, signer.Config.Certs[0], &key.PublicKey, signer.Config.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &crypto.TLSCertificateConfig{Certs: []*x509.Certificate{cert}, Key: key}
+	}()
+
+	tests := []struct {
+		name     string
+		signer   *corev1.Secret
+		caBundle *corev1.ConfigMap
+		target   *corev1.Secret
+		now      time.Time
+		expected []CertChainErrorReason
+	}{
+		{
+			name:     "valid serving certificate",
+			signer:   signerSecret,
+			caBundle: bundle(otherSigner, signer),
+			target:   secret("target", serving, servingAnnotations),
+		},
+		{
+			name:     "valid client certificate",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", client, nil),
+		},
+		{
+			name:     "target expired",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", serving, servingAnnotations),
+			now:      time.Now().Add(36 * time.Hour),
+			expected: []CertChainErrorReason{CertChainExpired},
+		},
+		{
+			name:     "signer and target expired",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", serving, servingAnnotations),
+			now:      time.Now().Add(72 * time.Hour),
+			expected: []CertChainErrorReason{CertChainExpired, CertChainExpired},
+		},
+		{
+			name:     "signer missing in bundle",
+			signer:   signerSecret,
+			caBundle: bundle(otherSigner),
+			target:   secret("target", serving, servingAnnotations),
+			expected: []CertChainErrorReason{CertChainSignerNotInBundle, CertChainNotSignedByBundle},
+		},
+		{
+			name:     "hostnames not certified",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", serving, map[string]string{CertificateHostnames: "10.0.0.1,bar.ns.svc,foo.ns.svc"}),
+			expected: []CertChainErrorReason{CertChainHostnameMismatch},
+		},
+		{
+			name:     "client certificate for a server",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", client, servingAnnotations),
+			expected: []CertChainErrorReason{CertChainHostnameMismatch, CertChainWrongKeyUsage},
+		},
+		{
+			name:     "intermediate signer as target",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", intermediate, nil),
+		},
+		{
+			name:     "CA that cannot sign as target",
+			signer:   signerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", nonSigningCA, nil),
+			expected: []CertChainErrorReason{CertChainWrongKeyUsage},
+		},
+		{
+			name:     "signer without key",
+			signer:   externalSignerSecret,
+			caBundle: bundle(signer),
+			target:   secret("target", serving, servingAnnotations),
+		},
+		{
+			name:     "signer without key and certificate",
+			signer:   &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "signer"}},
+			caBundle: bundle(signer),
+			target:   secret("target", serving, servingAnnotations),
+			expected: []CertChainErrorReason{CertChainInvalid},
+		},
+		{
+			name:     "target as signer",
+			signer:   secret("signer", serving, nil),
+			caBundle: bundle(signer),
+			target:   secret("target", serving, servingAnnotations),
+			expected: []CertChainErrorReason{CertChainWrongKeyUsage, CertChainSignerNotInBundle},
+		},
+		{
+			name:     "missing key and bundle",
+			signer:   signerSecret,
+			caBundle: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ca-bundle"}},
+			target:   brokenTarget,
+			expected: []CertChainErrorReason{CertChainInvalid, CertChainInvalid},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := test.now
+			if now.IsZero() {
+				now = time.Now()
+			}
+			var actual []CertChainErrorReason
+			errs := validateCertChainAt(test.signer, test.caBundle, test.target, now)
+			for _, err := range errs {
+				actual = append(actual, err.Reason)
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %v, got %v", test.expected, errs)
+			}
+		})
+	}
+}
@@ -37,6 +37,10 @@ const (
 	// This is not transient condition and normally a correction or manual intervention is required on the config custom resource.
 	ConfigObservationDegradedConditionType = "ConfigObservationDegraded"
 
+	// ObservedConfigSchemaDegradedConditionType is true when the observed config does not conform to the schema of the operand config.
+	// This usually indicates a bug in one of the config observers.
+	ObservedConfigSchemaDegradedConditionType = "ObservedConfigSchemaDegraded"
+
 	// ResourceSyncControllerDegradedConditionType is true when the operator failed to synchronize one or more secrets or config maps required
 	// to run the operand. Operand ability to provide service might be affected by this condition.
 	// This condition is set to false when the operator is able to create secrets and config maps.
@@ -47,6 +51,18 @@ const (
 	// validity can expire and without rotating/renewing them manual recovery might be required to fix the cluster.
 	CertRotationDegradedConditionTypeFmt = "CertRotation_%s_Degraded"
 
+	// CertRotationSignerValidConditionTypeFmt is true when the signing CA of the named cert rotation controller is
+	// rotated successfully. The message gives the expiry of the current signing CA.
+	CertRotationSignerValidConditionTypeFmt = "CertRotation_%s_SignerValid"
+
+	// CertRotationCABundleValidConditionTypeFmt is true when the CA bundle of the named cert rotation controller is
+	// updated successfully. The message gives the expiry of the first certificate of the bundle to expire.
+	CertRotationCABundleValidConditionTypeFmt = "CertRotation_%s_CABundleValid"
+
+	// CertRotationTargetCertValidConditionTypeFmt is true when the target certificate stored in the named secret is
+	// rotated successfully by the named cert rotation controller. The message gives the expiry of the certificate.
+	CertRotationTargetCertValidConditionTypeFmt = "CertRotation_%s_%s_TargetCertValid"
+
 	// InstallerControllerDegradedConditionType is true when the operator is not able to create new installer pods so the new revisions
 	// cannot be rolled out. This might happen when one or more required secrets or config maps does not exists.
 	// In case the missing secret or config map is available, this condition is automatically set to false.
@@ -0,0 +1,127 @@
+package configobserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultAuditTrailMaxEntries = 10
+	// maxAuditEntryBytes bounds a single entry, leaving room for the history in a config map (1MiB).
+	maxAuditEntryBytes = 64 * 1024
+
+	auditEntryKeyPrefix = "change-"
+)
+
+// AuditSource is an object the observed config is derived from. Its resourceVersion is recorded with every change
+// of the observed config.
+type AuditSource struct {
+	// Name identifies the object in the audit trail, e.g. apiservers.config.openshift.io/cluster.
+	Name string
+	// Get returns the object, usually from one of the listers.
+	Get func(listers Listers) (metav1.Object, error)
+}
+
+// AuditTrail records every change of the observed config into a config map, keeping the most recent MaxEntries
+// changes with their diff and the resourceVersions of the sources at the time of the change.
+type AuditTrail struct {
+	Namespace string
+	Name      string
+	Client    corev1client.ConfigMapsGetter
+	Sources   []AuditSource
+	// MaxEntries is the number of changes kept in the config map. Defaults to 10.
+	MaxEntries int
+
+	now func() time.Time
+}
+
+// record stores the change. Failures are only logged, the audit trail must not block the observed config.
+func (a *AuditTrail) record(ctx context.Context, listers Listers, path []string, existing, observed map[string]interface{}) {
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	timestamp := now().UTC()
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "time: %s\n", timestamp.Format(time.RFC3339))
+	if len(path) > 0 {
+		fmt.Fprintf(b, "path: %s\n", strings.Join(path, "/"))
+	}
+	if len(a.Sources) > 0 {
+		fmt.Fprintf(b, "sources:\n")
+	}
+	for _, source := range a.Sources {
+		obj, err := source.Get(listers)
+		switch {
+		case apierrors.IsNotFound(err):
+			fmt.Fprintf(b, "  %s: not found\n", source.Name)
+		case err != nil:
+			fmt.Fprintf(b, "  %s: %v\n", source.Name, err)
+		default:
+			fmt.Fprintf(b, "  %s: resourceVersion=%s\n", source.Name, obj.GetResourceVersion())
+		}
+	}
+	fmt.Fprintf(b, "diff:\n%s\n", diff.ObjectDiff(existing, observed))
+	entry := b.String()
+	if len(entry) > maxAuditEntryBytes {
+		entry = entry[:maxAuditEntryBytes] + "\n... truncated"
+	}
+
+	key := auditEntryKeyPrefix + timestamp.Format("20060102T150405.000Z")
+	if err := a.store(ctx, key, entry); err != nil {
+		klog.Warningf("Failed to record observed config change in %s/%s: %v", a.Namespace, a.Name, err)
+	}
+}
+
+func (a *AuditTrail) store(ctx context.Context, key, entry string) error {
+	configMap, err := a.Client.ConfigMaps(a.Namespace).Get(ctx, a.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = a.Client.ConfigMaps(a.Namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: a.Namespace, Name: a.Name},
+			Data:       map[string]string{key: entry},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	configMap = configMap.DeepCopy()
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[key] = entry
+	maxEntries := a.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultAuditTrailMaxEntries
+	}
+	rotateAuditEntries(configMap.Data, maxEntries)
+	_, err = a.Client.ConfigMaps(a.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// rotateAuditEntries removes the oldest entries beyond maxEntries. The keys sort by time.
+func rotateAuditEntries(data map[string]string, maxEntries int) {
+	keys := []string{}
+	for key := range data {
+		if strings.HasPrefix(key, auditEntryKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for len(keys) > maxEntries {
+		delete(data, keys[0])
+		keys = keys[1:]
+	}
+}
@@ -0,0 +1,118 @@
+package configobserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestAuditTrail(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	now := time.Date(2022, 9, 1, 3, 0, 0, 0, time.UTC)
+	auditTrail := &AuditTrail{
+		Namespace: "ns",
+		Name:      "observed-config-history",
+		Client:    kubeClient.CoreV1(),
+		Sources: []AuditSource{
+			{
+				Name: "apiservers.config.openshift.io/cluster",
+				Get: func(Listers) (metav1.Object, error) {
+					return &metav1.ObjectMeta{Name: "cluster", ResourceVersion: "42"}, nil
+				},
+			},
+			{
+				Name: "proxies.config.openshift.io/cluster",
+				Get: func(Listers) (metav1.Object, error) {
+					return nil, apierrors.NewNotFound(schema.GroupResource{Group: "config.openshift.io", Resource: "proxies"}, "cluster")
+				},
+			},
+		},
+		MaxEntries: 2,
+		now:        func() time.Time { return now },
+	}
+
+	level := 0
+	configObserver := ConfigObserver{
+		listers: &fakeLister{},
+		observers: []ObserveConfigFunc{
+			func(listers Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+				return map[string]interface{}{"logLevel": fmt.Sprint(level)}, nil
+			},
+		},
+		degradedConditionType: condition.ConfigObservationDegradedConditionType,
+		auditTrail:            auditTrail,
+	}
+
+	spec := &operatorv1.OperatorSpec{}
+	for i := 0; i < 3; i++ {
+		configObserver.operatorClient = &fakeOperatorClient{startingSpec: spec}
+		if err := configObserver.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(configObserver.operatorClient.(*fakeOperatorClient).spec.ObservedConfig.Object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec = &operatorv1.OperatorSpec{ObservedConfig: runtime.RawExtension{Raw: raw}}
+		level++
+		now = now.Add(time.Minute)
+	}
+
+	configMap, err := kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "observed-config-history", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMap.Data) != 2 {
+		t.Fatalf("expected the 2 most recent of 3 changes, got %v", keys(configMap.Data))
+	}
+	entry, ok := configMap.Data["change-20220901T030200.000Z"]
+	if !ok {
+		t.Fatalf("expected the latest change, got %v", keys(configMap.Data))
+	}
+	for _, expected := range []string{
+		"time: 2022-09-01T03:02:00Z",
+		"apiservers.config.openshift.io/cluster: resourceVersion=42",
+		"proxies.config.openshift.io/cluster: not found",
+		`"1"`,
+		`"2"`,
+	} {
+		if !strings.Contains(entry, expected) {
+			t.Errorf("expected %q in entry:\n%s", expected, entry)
+		}
+	}
+}
+
+func keys(data map[string]string) []string {
+	ret := []string{}
+	for key := range data {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+func TestRotateAuditEntries(t *testing.T) {
+	data := map[string]string{
+		"change-20220901T030000.000Z": "",
+		"change-20220901T030100.000Z": "",
+		"change-20220901T030200.000Z": "",
+		"README":                      "",
+	}
+	rotateAuditEntries(data, 2)
+	if _, ok := data["change-20220901T030000.000Z"]; ok || len(data) != 3 {
+		t.Errorf("expected the oldest entry to be removed, got %v", keys(data))
+	}
+}
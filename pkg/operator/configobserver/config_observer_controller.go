@@ -55,6 +55,9 @@ type ConfigObserver struct {
 
 	nestedConfigPath      []string
 	degradedConditionType string
+
+	// auditTrail records the changes of the observed config, if set.
+	auditTrail *AuditTrail
 }
 
 func NewConfigObserver(
@@ -102,6 +105,30 @@ func NewNestedConfigObserver(
 	nestedConfigPath []string,
 	degradedConditionPrefix string,
 	observers ...ObserveConfigFunc,
+) factory.Controller {
+	return NewNestedConfigObserverWithAuditTrail(
+		operatorClient,
+		eventRecorder,
+		listers,
+		informers,
+		nestedConfigPath,
+		degradedConditionPrefix,
+		nil,
+		observers...,
+	)
+}
+
+// NewNestedConfigObserverWithAuditTrail creates a nested config observer like NewNestedConfigObserver which additionally
+// records every change of the observed config into the audit trail config map, if auditTrail is not nil.
+func NewNestedConfigObserverWithAuditTrail(
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+	listers Listers,
+	informers []factory.Informer,
+	nestedConfigPath []string,
+	degradedConditionPrefix string,
+	auditTrail *AuditTrail,
+	observers ...ObserveConfigFunc,
 ) factory.Controller {
 	c := &ConfigObserver{
 		operatorClient:        operatorClient,
@@ -109,6 +136,7 @@ func NewNestedConfigObserver(
 		listers:               listers,
 		nestedConfigPath:      nestedConfigPath,
 		degradedConditionType: degradedConditionPrefix + condition.ConfigObservationDegradedConditionType,
+		auditTrail:            auditTrail,
 	}
 
 	return factory.New().ResyncEvery(time.Minute).WithSync(c.sync).WithInformers(append(informers, listersToInformer(listers)...)...).ToController("ConfigObserver", eventRecorder.WithComponentSuffix("config-observer"))
@@ -185,7 +213,10 @@ func (c ConfigObserver) updateObservedConfig(ctx context.Context, syncCtx factor
 	if len(c.nestedConfigPath) == 0 {
 		if !equality.Semantic.DeepEqual(existingConfig, mergedObservedConfig) {
 			syncCtx.Recorder().Eventf("ObservedConfigChanged", "Writing updated observed config: %v", diff.ObjectDiff(existingConfig, mergedObservedConfig))
-			return c.updateConfig(ctx, syncCtx, mergedObservedConfig, v1helpers.UpdateObservedConfigFn)
+			if err := c.updateConfig(ctx, syncCtx, mergedObservedConfig, v1helpers.UpdateObservedConfigFn); err != nil {
+				return err
+			}
+			c.recordChange(ctx, existingConfig, mergedObservedConfig)
 		}
 		return nil
 	}
@@ -200,11 +231,22 @@ func (c ConfigObserver) updateObservedConfig(ctx context.Context, syncCtx factor
 	}
 	if !equality.Semantic.DeepEqual(existingConfigNested, mergedObservedConfigNested) {
 		syncCtx.Recorder().Eventf("ObservedConfigChanged", "Writing updated section (%q) of observed config: %q", strings.Join(c.nestedConfigPath, "/"), diff.ObjectDiff(existingConfigNested, mergedObservedConfigNested))
-		return c.updateConfig(ctx, syncCtx, mergedObservedConfigNested, c.updateNestedConfigHelper)
+		if err := c.updateConfig(ctx, syncCtx, mergedObservedConfigNested, c.updateNestedConfigHelper); err != nil {
+			return err
+		}
+		c.recordChange(ctx, existingConfigNested, mergedObservedConfigNested)
 	}
 	return nil
 }
 
+// recordChange records a written change of the observed config in the audit trail, if configured.
+func (c ConfigObserver) recordChange(ctx context.Context, existingConfig, observedConfig map[string]interface{}) {
+	if c.auditTrail == nil {
+		return
+	}
+	c.auditTrail.record(ctx, c.listers, c.nestedConfigPath, existingConfig, observedConfig)
+}
+
 type updateObservedConfigFn func(config map[string]interface{}) v1helpers.UpdateOperatorSpecFunc
 
 func (c ConfigObserver) updateConfig(ctx context.Context, syncCtx factory.SyncContext, updatedMaybeNestedConfig map[string]interface{}, updateConfigHelper updateObservedConfigFn) error {
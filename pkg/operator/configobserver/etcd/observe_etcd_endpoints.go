@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/openshift/library-go/pkg/network/networkutils"
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -114,11 +115,7 @@ func innerObserveStorageURLs(fallbackObserver fallBackObserverFn, alwaysAppendLo
 		}
 
 		// use the canonical representation of the ip address (not original input) when constructing the url
-		if ip.To4() != nil {
-			etcdURLs = append(etcdURLs, fmt.Sprintf("https://%s:2379", ip))
-		} else {
-			etcdURLs = append(etcdURLs, fmt.Sprintf("https://[%s]:2379", ip))
-		}
+		etcdURLs = append(etcdURLs, fmt.Sprintf("https://%s:2379", networkutils.URLHost(ip.String())))
 	}
 
 	if len(etcdURLs) == 0 {
@@ -193,11 +190,7 @@ func innerObserveStorageURLsFromOldEndPoint(genericListers configobserver.Lister
 				continue
 			}
 			// use the canonical representation of the ip address (not original input) when constructing the url
-			if ip.To4() != nil {
-				etcdURLs = append(etcdURLs, fmt.Sprintf("https://%s:2379", ip))
-			} else {
-				etcdURLs = append(etcdURLs, fmt.Sprintf("https://[%s]:2379", ip))
-			}
+			etcdURLs = append(etcdURLs, fmt.Sprintf("https://%s:2379", networkutils.URLHost(ip.String())))
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	kyaml "k8s.io/apimachinery/pkg/util/yaml"
@@ -13,6 +14,7 @@ import (
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -89,7 +91,7 @@ func (s *revisionDiffProfileSuppressor) shouldSuppressConfigUpdates() (suppress
 	}
 
 	for revision := range uniqueRevisionMap {
-		configMapNameWithRevision := fmt.Sprintf("%s-%d", revisionConfigMapName, revision)
+		configMapNameWithRevision := resourcenames.WithSuffix(revisionConfigMapName, strconv.Itoa(int(revision)), resourcenames.MaxNameLength)
 		configMap, err := s.configMapLister.Get(configMapNameWithRevision)
 		if err != nil {
 			return false, "", err
@@ -0,0 +1,129 @@
+package operandcapabilities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// The operands publish what they support in a config map, one key per operand instance, e.g. per pod, holding the
+// JSON encoded OperandCapabilities of the instance. During a rolling upgrade old and new instances run side by side,
+// so the operator only generates config all published instances support. Every instance refreshes its entry
+// periodically, entries older than the max age passed to Negotiate are ignored, so that instances which are gone
+// don't block new config forever.
+
+// OperandCapabilities is what an operand instance supports.
+type OperandCapabilities struct {
+	// Version is the version of the operand, for information only.
+	Version string `json:"version,omitempty"`
+	// ConfigVersions are the versions of the config the operand understands.
+	ConfigVersions []string `json:"configVersions"`
+	// Capabilities are the optional features of the operand, which the operator can enable in the config.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// LastUpdateTime is set on publishing.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// Publish sets the capabilities of the operand instance in the config map, creating the config map if needed.
+func Publish(ctx context.Context, client corev1client.ConfigMapsGetter, namespace, name, instance string, capabilities OperandCapabilities) error {
+	capabilities.LastUpdateTime = metav1.Now()
+	value, err := json.Marshal(capabilities)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		configMap, err := client.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = client.ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Data:       map[string]string{instance: string(value)},
+			}, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// another instance was faster, retry as a conflict
+				return apierrors.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		configMap = configMap.DeepCopy()
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[instance] = string(value)
+		_, err = client.ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Negotiated is what all published operand instances support.
+type Negotiated struct {
+	// Instances are the operand instances taken into account.
+	Instances []string
+	// ConfigVersions are the config versions all instances understand.
+	ConfigVersions sets.String
+	// Capabilities are the capabilities all instances have.
+	Capabilities sets.String
+}
+
+// Negotiate returns what all operand instances published in the config map within maxAge support. A zero maxAge
+// takes all instances into account. It returns nil if no instance published, e.g. because the operand is of a version
+// not knowing the protocol yet.
+func Negotiate(configMap *corev1.ConfigMap, maxAge time.Duration, now time.Time) (*Negotiated, error) {
+	if configMap == nil {
+		return nil, nil
+	}
+	instances := make([]string, 0, len(configMap.Data))
+	for instance := range configMap.Data {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	var negotiated *Negotiated
+	for _, instance := range instances {
+		capabilities := OperandCapabilities{}
+		if err := json.Unmarshal([]byte(configMap.Data[instance]), &capabilities); err != nil {
+			return nil, fmt.Errorf("configmap/%s -n %s: invalid capabilities of %q: %v", configMap.Name, configMap.Namespace, instance, err)
+		}
+		if maxAge > 0 && capabilities.LastUpdateTime.Add(maxAge).Before(now) {
+			continue
+		}
+		if negotiated == nil {
+			negotiated = &Negotiated{
+				ConfigVersions: sets.NewString(capabilities.ConfigVersions...),
+				Capabilities:   sets.NewString(capabilities.Capabilities...),
+			}
+		} else {
+			negotiated.ConfigVersions = negotiated.ConfigVersions.Intersection(sets.NewString(capabilities.ConfigVersions...))
+			negotiated.Capabilities = negotiated.Capabilities.Intersection(sets.NewString(capabilities.Capabilities...))
+		}
+		negotiated.Instances = append(negotiated.Instances, instance)
+	}
+	return negotiated, nil
+}
+
+// PreferredConfigVersion returns the first of the config versions, ordered by preference, all instances understand.
+func (n *Negotiated) PreferredConfigVersion(preferredFirst ...string) (string, bool) {
+	for _, version := range preferredFirst {
+		if n.ConfigVersions.Has(version) {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// Has returns true if all instances have the capability.
+func (n *Negotiated) Has(capability string) bool {
+	return n != nil && n.Capabilities.Has(capability)
+}
@@ -0,0 +1,80 @@
+package operandcapabilities
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPublishAndNegotiate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx := context.TODO()
+
+	if err := Publish(ctx, client.CoreV1(), "ns", "operand-capabilities", "pod-old", OperandCapabilities{
+		Version:        "4.13",
+		ConfigVersions: []string{"v1"},
+		Capabilities:   []string{"foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Publish(ctx, client.CoreV1(), "ns", "operand-capabilities", "pod-new", OperandCapabilities{
+		Version:        "4.14",
+		ConfigVersions: []string{"v1", "v2"},
+		Capabilities:   []string{"foo", "bar"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "operand-capabilities", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	negotiated, err := Negotiate(configMap, time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := negotiated.PreferredConfigVersion("v2", "v1"); version != "v1" {
+		t.Errorf("expected v1 during the skew, got %q", version)
+	}
+	if !negotiated.Has("foo") || negotiated.Has("bar") {
+		t.Errorf("expected only foo during the skew, got %v", negotiated.Capabilities.List())
+	}
+
+	// the old instance is gone and stops refreshing its entry
+	negotiated, err = Negotiate(configMap, time.Hour, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if negotiated != nil {
+		t.Errorf("expected no instance within max age, got %v", negotiated.Instances)
+	}
+	if err := Publish(ctx, client.CoreV1(), "ns", "operand-capabilities", "pod-new", OperandCapabilities{
+		ConfigVersions: []string{"v1", "v2"},
+		Capabilities:   []string{"foo", "bar"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err = client.CoreV1().ConfigMaps("ns").Get(ctx, "operand-capabilities", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMap.Data["pod-old"] = `{"configVersions":["v1"],"lastUpdateTime":"2020-01-01T00:00:00Z"}`
+	negotiated, err = Negotiate(configMap, time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := negotiated.PreferredConfigVersion("v2", "v1"); version != "v2" {
+		t.Errorf("expected v2 after the upgrade, got %q", version)
+	}
+	if !negotiated.Has("bar") {
+		t.Errorf("expected bar after the upgrade, got %v", negotiated.Capabilities.List())
+	}
+
+	configMap.Data["broken"] = "{"
+	if _, err := Negotiate(configMap, time.Hour, time.Now()); err == nil {
+		t.Errorf("expected an error for invalid capabilities")
+	}
+}
@@ -0,0 +1,117 @@
+package operandcapabilities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type ConfigMapLister interface {
+	ConfigMapLister() corev1listers.ConfigMapLister
+}
+
+type capabilitiesObserver struct {
+	namespace string
+	name      string
+	maxAge    time.Duration
+}
+
+func (o *capabilitiesObserver) negotiate(genericListers configobserver.Listers) (*Negotiated, error) {
+	listers := genericListers.(ConfigMapLister)
+	configMap, err := listers.ConfigMapLister().ConfigMaps(o.namespace).Get(o.name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Negotiate(configMap, o.maxAge, time.Now())
+}
+
+// NewObserveConfigVersionFunc returns a config observer setting the config version at the config path to the first
+// of the preferred versions all operand instances understand. Without published capabilities, the last preferred
+// version is set, it is expected to be understood by all operand versions. If the instances have no version in common,
+// the existing config is kept.
+func NewObserveConfigVersionFunc(namespace, name string, maxAge time.Duration, preferredFirst []string, configPath []string) configobserver.ObserveConfigFunc {
+	o := &capabilitiesObserver{namespace: namespace, name: name, maxAge: maxAge}
+	return func(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, _ []error) {
+		defer func() {
+			ret = configobserver.Pruned(ret, configPath)
+		}()
+
+		errs := []error{}
+		negotiated, err := o.negotiate(genericListers)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+
+		newVersion := preferredFirst[len(preferredFirst)-1]
+		if negotiated != nil {
+			var ok bool
+			if newVersion, ok = negotiated.PreferredConfigVersion(preferredFirst...); !ok {
+				return existingConfig, append(errs, fmt.Errorf("none of the config versions %v is understood by all of %s",
+					preferredFirst, strings.Join(negotiated.Instances, ", ")))
+			}
+		}
+
+		currentVersion, _, err := unstructured.NestedString(existingConfig, configPath...)
+		if err != nil {
+			errs = append(errs, err)
+			// keep going on read error from existing config
+		}
+		if currentVersion != newVersion {
+			recorder.Eventf("ObserveConfigVersionUpdated", "Updated %v to %s", strings.Join(configPath, "."), newVersion)
+		}
+
+		observedConfig := map[string]interface{}{}
+		if err := unstructured.SetNestedField(observedConfig, newVersion, configPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+		return observedConfig, errs
+	}
+}
+
+// NewObserveCapabilitiesFunc returns a config observer setting the list of the known capabilities all operand instances
+// have at the config path. Without published capabilities, the list is empty.
+func NewObserveCapabilitiesFunc(namespace, name string, maxAge time.Duration, knownCapabilities sets.String, configPath []string) configobserver.ObserveConfigFunc {
+	o := &capabilitiesObserver{namespace: namespace, name: name, maxAge: maxAge}
+	return func(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, _ []error) {
+		defer func() {
+			ret = configobserver.Pruned(ret, configPath)
+		}()
+
+		errs := []error{}
+		negotiated, err := o.negotiate(genericListers)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+
+		newCapabilities := []string{}
+		if negotiated != nil {
+			newCapabilities = negotiated.Capabilities.Intersection(knownCapabilities).List()
+		}
+
+		currentCapabilities, _, err := unstructured.NestedStringSlice(existingConfig, configPath...)
+		if err != nil {
+			errs = append(errs, err)
+			// keep going on read error from existing config
+		}
+		if !sets.NewString(currentCapabilities...).Equal(sets.NewString(newCapabilities...)) {
+			recorder.Eventf("ObserveCapabilitiesUpdated", "Updated %v to %s", strings.Join(configPath, "."), strings.Join(newCapabilities, ","))
+		}
+
+		observedConfig := map[string]interface{}{}
+		if err := unstructured.SetNestedStringSlice(observedConfig, newCapabilities, configPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+		return observedConfig, errs
+	}
+}
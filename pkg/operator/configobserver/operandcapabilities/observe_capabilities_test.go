@@ -0,0 +1,131 @@
+package operandcapabilities
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+)
+
+type testLister struct {
+	lister corev1listers.ConfigMapLister
+}
+
+func (l testLister) ConfigMapLister() corev1listers.ConfigMapLister {
+	return l.lister
+}
+
+func (l testLister) ResourceSyncer() resourcesynccontroller.ResourceSyncer {
+	return nil
+}
+
+func (l testLister) PreRunHasSynced() []cache.InformerSynced {
+	return nil
+}
+
+func capabilitiesConfigMap(t *testing.T, instances map[string]OperandCapabilities) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "operand-capabilities"},
+		Data:       map[string]string{},
+	}
+	for instance, capabilities := range instances {
+		capabilities.LastUpdateTime = metav1.Now()
+		value, err := json.Marshal(capabilities)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configMap.Data[instance] = string(value)
+	}
+	return configMap
+}
+
+func TestObserveConfigVersion(t *testing.T) {
+	configPath := []string{"operand", "configVersion"}
+
+	tests := []struct {
+		name           string
+		instances      map[string]OperandCapabilities
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectErrors   bool
+	}{
+		{
+			name:           "nothing published",
+			existingConfig: map[string]interface{}{},
+			expectedConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v1"}},
+		},
+		{
+			name: "skewed instances",
+			instances: map[string]OperandCapabilities{
+				"a": {ConfigVersions: []string{"v1"}},
+				"b": {ConfigVersions: []string{"v1", "v2"}},
+			},
+			existingConfig: map[string]interface{}{},
+			expectedConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v1"}},
+		},
+		{
+			name: "upgraded instances",
+			instances: map[string]OperandCapabilities{
+				"a": {ConfigVersions: []string{"v1", "v2"}},
+				"b": {ConfigVersions: []string{"v1", "v2"}},
+			},
+			existingConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v1"}},
+			expectedConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v2"}},
+		},
+		{
+			name: "no common version",
+			instances: map[string]OperandCapabilities{
+				"a": {ConfigVersions: []string{"v0"}},
+				"b": {ConfigVersions: []string{"v2"}},
+			},
+			existingConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v1"}},
+			expectedConfig: map[string]interface{}{"operand": map[string]interface{}{"configVersion": "v1"}},
+			expectErrors:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if test.instances != nil {
+				indexer.Add(capabilitiesConfigMap(t, test.instances))
+			}
+			observe := NewObserveConfigVersionFunc("ns", "operand-capabilities", time.Hour, []string{"v2", "v1"}, configPath)
+			actual, errs := observe(testLister{lister: corev1listers.NewConfigMapLister(indexer)}, events.NewInMemoryRecorder("test"), test.existingConfig)
+			if test.expectErrors != (len(errs) > 0) {
+				t.Errorf("expected errors %v, got %v", test.expectErrors, errs)
+			}
+			if !reflect.DeepEqual(test.expectedConfig, actual) {
+				t.Errorf("expected %v, got %v", test.expectedConfig, actual)
+			}
+		})
+	}
+}
+
+func TestObserveCapabilities(t *testing.T) {
+	configPath := []string{"operand", "capabilities"}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(capabilitiesConfigMap(t, map[string]OperandCapabilities{
+		"a": {ConfigVersions: []string{"v1"}, Capabilities: []string{"foo", "bar", "unknown"}},
+		"b": {ConfigVersions: []string{"v1"}, Capabilities: []string{"foo", "unknown"}},
+	}))
+
+	observe := NewObserveCapabilitiesFunc("ns", "operand-capabilities", time.Hour, sets.NewString("foo", "bar"), configPath)
+	actual, errs := observe(testLister{lister: corev1listers.NewConfigMapLister(indexer)}, events.NewInMemoryRecorder("test"), map[string]interface{}{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	expected := map[string]interface{}{"operand": map[string]interface{}{"capabilities": []interface{}{"foo"}}}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
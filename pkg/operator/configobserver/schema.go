@@ -0,0 +1,329 @@
+package configobserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// ObservedConfigSchemaKey is the key of the published schema in the config map.
+const ObservedConfigSchemaKey = "schema.json"
+
+// ObservedConfigSchema is the OpenAPI schema of the operand config the observed config is validated against. It is
+// published into a config map, so that it can be inspected in must-gathers together with the observed config.
+type ObservedConfigSchema struct {
+	// Schema is the schema of the operand config, e.g. taken from the CRD of the config type.
+	Schema *apiextensionsv1.JSONSchemaProps
+	// NestedConfigPath is the path of the operand config in the observed config, empty for the whole observed config.
+	NestedConfigPath []string
+	// DefaultConfig is the default operand config, in YAML or JSON, the observed config is merged onto when the operand
+	// config is rendered. If set, the merged config is validated including the required fields. The observed config
+	// alone is a sparse overlay on the defaults, its required fields are not checked.
+	DefaultConfig []byte
+
+	// Namespace and Name locate the config map the schema is published to.
+	Namespace string
+	Name      string
+	Client    corev1client.ConfigMapsGetter
+}
+
+type observedConfigSchemaController struct {
+	schema                ObservedConfigSchema
+	operatorClient        v1helpers.OperatorClient
+	degradedConditionType string
+}
+
+// NewObservedConfigSchemaController publishes the schema and validates the observed config against it on every change of
+// the operator config. Violations are reported in the <prefix>ObservedConfigSchemaDegraded condition, so that bugs of
+// config observers are caught before the operand crashes on the config.
+//
+// The validation covers the structural schemas of CRDs: types, properties, required fields, items, additional properties,
+// enums, patterns, lengths and bounds. Fields not declared in the schema are violations unless
+// x-kubernetes-preserve-unknown-fields is set. Required fields are only checked with a DefaultConfig.
+func NewObservedConfigSchemaController(
+	schema ObservedConfigSchema,
+	degradedConditionPrefix string,
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &observedConfigSchemaController{
+		schema:                schema,
+		operatorClient:        operatorClient,
+		degradedConditionType: degradedConditionPrefix + condition.ObservedConfigSchemaDegradedConditionType,
+	}
+	return factory.New().ResyncEvery(time.Minute).WithSync(c.sync).WithInformers(operatorClient.Informer()).ToController("ObservedConfigSchema", eventRecorder.WithComponentSuffix("observed-config-schema"))
+}
+
+func (c *observedConfigSchemaController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, _, err := c.operatorClient.GetOperatorState()
+	if management.IsOperatorRemovable() && apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.publish(ctx, syncCtx.Recorder()); err != nil {
+		return err
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(spec.ObservedConfig.Raw) > 0 {
+		if err := json.NewDecoder(bytes.NewBuffer(spec.ObservedConfig.Raw)).Decode(&observedConfig); err != nil {
+			return c.updateCondition(ctx, fmt.Errorf("failed to decode the observed config: %w", err))
+		}
+	}
+	config, _, err := unstructured.NestedMap(observedConfig, c.schema.NestedConfigPath...)
+	if err != nil {
+		return c.updateCondition(ctx, fmt.Errorf("unable to extract the config under %v: %w", c.schema.NestedConfigPath, err))
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	if len(c.schema.DefaultConfig) == 0 {
+		return c.updateCondition(ctx, ValidateObservedConfig(config, c.schema.Schema).ToAggregate())
+	}
+
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	mergedBytes, err := resourcemerge.MergeProcessConfig(nil, c.schema.DefaultConfig, configBytes)
+	if err != nil {
+		return c.updateCondition(ctx, fmt.Errorf("failed to merge the observed config with the default config: %w", err))
+	}
+	merged := map[string]interface{}{}
+	if err := json.NewDecoder(bytes.NewBuffer(mergedBytes)).Decode(&merged); err != nil {
+		return c.updateCondition(ctx, fmt.Errorf("failed to decode the merged config: %w", err))
+	}
+	return c.updateCondition(ctx, ValidateConfig(merged, c.schema.Schema).ToAggregate())
+}
+
+func (c *observedConfigSchemaController) publish(ctx context.Context, recorder events.Recorder) error {
+	schemaBytes, err := json.MarshalIndent(c.schema.Schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.schema.Client, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: c.schema.Namespace, Name: c.schema.Name},
+		Data:       map[string]string{ObservedConfigSchemaKey: string(schemaBytes)},
+	})
+	return err
+}
+
+func (c *observedConfigSchemaController) updateCondition(ctx context.Context, validationErr error) error {
+	cond := operatorv1.OperatorCondition{
+		Type:   c.degradedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if validationErr != nil {
+		cond.Status = operatorv1.ConditionTrue
+		cond.Reason = "SchemaViolation"
+		cond.Message = validationErr.Error()
+	}
+	_, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(cond))
+	return err
+}
+
+// ValidateObservedConfig validates the (decoded JSON) observed config against the schema. The observed config is a
+// sparse overlay on the default config, so required fields are not checked.
+func ValidateObservedConfig(config map[string]interface{}, schema *apiextensionsv1.JSONSchemaProps) field.ErrorList {
+	if schema == nil {
+		return nil
+	}
+	return validateValue(nil, config, schema, false)
+}
+
+// ValidateConfig validates the (decoded JSON) complete operand config, e.g. the observed config merged with the
+// default config, against the schema, including the required fields.
+func ValidateConfig(config map[string]interface{}, schema *apiextensionsv1.JSONSchemaProps) field.ErrorList {
+	if schema == nil {
+		return nil
+	}
+	return validateValue(nil, config, schema, true)
+}
+
+func validateValue(fldPath *field.Path, value interface{}, schema *apiextensionsv1.JSONSchemaProps, checkRequired bool) field.ErrorList {
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return field.ErrorList{field.Invalid(fldPath, nil, "must not be null")}
+	}
+
+	var errs field.ErrorList
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		errs = append(errs, field.NotSupported(fldPath, value, enumValues(schema.Enum)))
+	}
+
+	if schema.XIntOrString {
+		switch value.(type) {
+		case string, float64, int64, int:
+			return errs
+		}
+		return append(errs, field.Invalid(fldPath, value, "must be an integer or a string"))
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be an object"))
+		}
+		errs = append(errs, validateObject(fldPath, obj, schema, checkRequired)...)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be an array"))
+		}
+		if schema.MinItems != nil && int64(len(items)) < *schema.MinItems {
+			errs = append(errs, field.Invalid(fldPath, len(items), fmt.Sprintf("must have at least %d items", *schema.MinItems)))
+		}
+		if schema.MaxItems != nil && int64(len(items)) > *schema.MaxItems {
+			errs = append(errs, field.TooMany(fldPath, len(items), int(*schema.MaxItems)))
+		}
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range items {
+				errs = append(errs, validateValue(fldPath.Index(i), item, schema.Items.Schema, checkRequired)...)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be a string"))
+		}
+		if schema.MinLength != nil && int64(len(s)) < *schema.MinLength {
+			errs = append(errs, field.Invalid(fldPath, s, fmt.Sprintf("must be at least %d characters long", *schema.MinLength)))
+		}
+		if schema.MaxLength != nil && int64(len(s)) > *schema.MaxLength {
+			errs = append(errs, field.TooLong(fldPath, s, int(*schema.MaxLength)))
+		}
+		if len(schema.Pattern) > 0 {
+			pattern, err := regexp.Compile(schema.Pattern)
+			if err != nil {
+				errs = append(errs, field.InternalError(fldPath, fmt.Errorf("invalid pattern %q in the schema: %w", schema.Pattern, err)))
+			} else if !pattern.MatchString(s) {
+				errs = append(errs, field.Invalid(fldPath, s, fmt.Sprintf("must match %q", schema.Pattern)))
+			}
+		}
+	case "integer", "number":
+		n, ok := toFloat(value)
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be a number"))
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			return append(errs, field.Invalid(fldPath, value, "must be an integer"))
+		}
+		if schema.Minimum != nil && (n < *schema.Minimum || (schema.ExclusiveMinimum && n == *schema.Minimum)) {
+			errs = append(errs, field.Invalid(fldPath, value, boundMessage("greater", *schema.Minimum, schema.ExclusiveMinimum)))
+		}
+		if schema.Maximum != nil && (n > *schema.Maximum || (schema.ExclusiveMaximum && n == *schema.Maximum)) {
+			errs = append(errs, field.Invalid(fldPath, value, boundMessage("less", *schema.Maximum, schema.ExclusiveMaximum)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be a boolean"))
+		}
+	}
+	return errs
+}
+
+func validateObject(fldPath *field.Path, obj map[string]interface{}, schema *apiextensionsv1.JSONSchemaProps, checkRequired bool) field.ErrorList {
+	var errs field.ErrorList
+	if checkRequired {
+		for _, required := range schema.Required {
+			if _, ok := obj[required]; !ok {
+				errs = append(errs, field.Required(fldPath.Child(required), ""))
+			}
+		}
+	}
+
+	preserveUnknownFields := schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if propertySchema, ok := schema.Properties[key]; ok {
+			errs = append(errs, validateValue(fldPath.Child(key), obj[key], &propertySchema, checkRequired)...)
+			continue
+		}
+		switch {
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+			errs = append(errs, validateValue(fldPath.Key(key), obj[key], schema.AdditionalProperties.Schema, checkRequired)...)
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Allows, preserveUnknownFields:
+		default:
+			errs = append(errs, field.Forbidden(fldPath.Child(key), "field is not declared in the schema"))
+		}
+	}
+	return errs
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func boundMessage(comparison string, bound float64, exclusive bool) string {
+	if exclusive {
+		return fmt.Sprintf("must be %s than %v", comparison, bound)
+	}
+	return fmt.Sprintf("must be %s than or equal to %v", comparison, bound)
+}
+
+func enumContains(enum []apiextensionsv1.JSON, value interface{}) bool {
+	for _, allowed := range enum {
+		var allowedValue interface{}
+		if err := json.Unmarshal(allowed.Raw, &allowedValue); err != nil {
+			continue
+		}
+		if reflect.DeepEqual(allowedValue, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumValues(enum []apiextensionsv1.JSON) []string {
+	var ret []string
+	for _, allowed := range enum {
+		ret = append(ret, string(allowed.Raw))
+	}
+	return ret
+}
@@ -0,0 +1,199 @@
+package configobserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func float64Ptr(f float64) *float64 { return &f }
+
+func boolPtr(b bool) *bool { return &b }
+
+var testOperandConfigSchema = &apiextensionsv1.JSONSchemaProps{
+	Type:     "object",
+	Required: []string{"servingInfo"},
+	Properties: map[string]apiextensionsv1.JSONSchemaProps{
+		"servingInfo": {
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"minTLSVersion": {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"VersionTLS12"`)}, {Raw: []byte(`"VersionTLS13"`)}}},
+				"cipherSuites":  {Type: "array", MinItems: int64Ptr(1), Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string", Pattern: "^TLS_"}}},
+				"bindAddress":   {Type: "string", MaxLength: int64Ptr(20)},
+			},
+		},
+		"logLevel": {Type: "integer", Minimum: float64Ptr(0), Maximum: float64Ptr(10)},
+		"apiServerArguments": {
+			Type:                 "object",
+			AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &apiextensionsv1.JSONSchemaProps{Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}}}},
+		},
+		"extensions":  {Type: "object", XPreserveUnknownFields: boolPtr(true)},
+		"port":        {XIntOrString: true},
+		"corsOrigins": {Type: "array", Nullable: true},
+	},
+}
+
+func TestValidateObservedConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		complete       bool
+		expectedErrors []string
+	}{
+		{
+			name: "valid",
+			config: `{"servingInfo": {"minTLSVersion": "VersionTLS12", "cipherSuites": ["TLS_AES_128_GCM_SHA256"], "bindAddress": "0.0.0.0:443"},
+				"logLevel": 2, "apiServerArguments": {"feature-gates": ["A=true"]}, "extensions": {"anything": {"goes": 1}}, "port": "https", "corsOrigins": null}`,
+		},
+		{
+			name:   "sparse observed config",
+			config: `{"logLevel": 2}`,
+		},
+		{
+			name:           "missing required field",
+			config:         `{"logLevel": 2}`,
+			complete:       true,
+			expectedErrors: []string{"servingInfo: Required value"},
+		},
+		{
+			name:           "unknown field",
+			config:         `{"servingInfo": {"minTlsVersion": "VersionTLS12"}}`,
+			expectedErrors: []string{"servingInfo.minTlsVersion: Forbidden: field is not declared in the schema"},
+		},
+		{
+			name:   "wrong types",
+			config: `{"servingInfo": {"cipherSuites": "TLS_AES_128_GCM_SHA256"}, "logLevel": 1.5, "apiServerArguments": {"feature-gates": "A=true"}, "port": true}`,
+			expectedErrors: []string{
+				"servingInfo.cipherSuites: Invalid value: \"TLS_AES_128_GCM_SHA256\": must be an array",
+				"logLevel: Invalid value: 1.5: must be an integer",
+				"apiServerArguments[feature-gates]: Invalid value: \"A=true\": must be an array",
+				"port: Invalid value: true: must be an integer or a string",
+			},
+		},
+		{
+			name:   "constraints",
+			config: `{"servingInfo": {"minTLSVersion": "VersionTLS10", "cipherSuites": ["AES"], "bindAddress": "very-long-hostname.example.com:443"}, "logLevel": 11}`,
+			expectedErrors: []string{
+				"servingInfo.minTLSVersion: Unsupported value: \"VersionTLS10\"",
+				"servingInfo.cipherSuites[0]: Invalid value: \"AES\": must match \"^TLS_\"",
+				"servingInfo.bindAddress: Too long",
+				"logLevel: Invalid value: 11: must be less than or equal to 10",
+			},
+		},
+		{
+			name:           "null",
+			config:         `{"servingInfo": null}`,
+			expectedErrors: []string{"servingInfo: Invalid value: \"null\": must not be null"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(test.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			validate := ValidateObservedConfig
+			if test.complete {
+				validate = ValidateConfig
+			}
+			errs := validate(config, testOperandConfigSchema)
+			if len(errs) != len(test.expectedErrors) {
+				t.Fatalf("expected %d errors, got %v", len(test.expectedErrors), errs)
+			}
+			message := errs.ToAggregate()
+			for _, expected := range test.expectedErrors {
+				if message == nil || !strings.Contains(message.Error(), expected) {
+					t.Errorf("expected error containing %q, got %v", expected, message)
+				}
+			}
+		})
+	}
+}
+
+func TestObservedConfigSchemaController(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"operand": {"servingInfo": {}, "logLevel": "2"}}`)},
+	}, &operatorv1.OperatorStatus{}, nil)
+	c := &observedConfigSchemaController{
+		schema: ObservedConfigSchema{
+			Schema:           testOperandConfigSchema,
+			NestedConfigPath: []string{"operand"},
+			Namespace:        "ns",
+			Name:             "operand-config-schema",
+			Client:           kubeClient.CoreV1(),
+		},
+		operatorClient:        operatorClient,
+		degradedConditionType: condition.ObservedConfigSchemaDegradedConditionType,
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "operand-config-schema", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(configMap.Data[ObservedConfigSchemaKey], `"servingInfo"`) {
+		t.Errorf("expected the schema to be published, got %q", configMap.Data[ObservedConfigSchemaKey])
+	}
+	_, status, _, _ := operatorClient.GetOperatorState()
+	cond := v1helpers.FindOperatorCondition(status.Conditions, condition.ObservedConfigSchemaDegradedConditionType)
+	if cond == nil || cond.Status != operatorv1.ConditionTrue || cond.Message != `logLevel: Invalid value: "2": must be a number` {
+		t.Errorf("expected a schema violation, got %#v", cond)
+	}
+
+	if _, _, err := v1helpers.UpdateSpec(context.TODO(), operatorClient, func(spec *operatorv1.OperatorSpec) error {
+		spec.ObservedConfig = runtime.RawExtension{Raw: []byte(`{"operand": {"servingInfo": {}, "logLevel": 2}}`)}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	_, status, _, _ = operatorClient.GetOperatorState()
+	if cond := v1helpers.FindOperatorCondition(status.Conditions, condition.ObservedConfigSchemaDegradedConditionType); cond == nil || cond.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected no schema violation, got %#v", cond)
+	}
+
+	// required fields are checked in the observed config merged with the defaults
+	if _, _, err := v1helpers.UpdateSpec(context.TODO(), operatorClient, func(spec *operatorv1.OperatorSpec) error {
+		spec.ObservedConfig = runtime.RawExtension{Raw: []byte(`{"operand": {"logLevel": 2}}`)}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		defaultConfig   string
+		expectedStatus  operatorv1.ConditionStatus
+		expectedMessage string
+	}{
+		{defaultConfig: "servingInfo:\n  bindAddress: 0.0.0.0:443\n", expectedStatus: operatorv1.ConditionFalse},
+		{defaultConfig: "logLevel: 0\n", expectedStatus: operatorv1.ConditionTrue, expectedMessage: "servingInfo: Required value"},
+	} {
+		c.schema.DefaultConfig = []byte(test.defaultConfig)
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+		_, status, _, _ = operatorClient.GetOperatorState()
+		if cond := v1helpers.FindOperatorCondition(status.Conditions, condition.ObservedConfigSchemaDegradedConditionType); cond == nil || cond.Status != test.expectedStatus || cond.Message != test.expectedMessage {
+			t.Errorf("expected %s %q with the defaults %q, got %#v", test.expectedStatus, test.expectedMessage, test.defaultConfig, cond)
+		}
+	}
+}
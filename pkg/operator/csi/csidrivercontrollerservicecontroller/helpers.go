@@ -1,7 +1,6 @@
 package csidrivercontrollerservicecontroller
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"os"
 	"strconv"
@@ -20,6 +19,7 @@ import (
 	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
 	"github.com/openshift/library-go/pkg/operator/loglevel"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcehash"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -241,12 +241,7 @@ func addObjectHash(deployment *appsv1.Deployment, inputHashes map[string]string)
 		deployment.Spec.Template.Annotations = map[string]string{}
 	}
 	for k, v := range inputHashes {
-		annotationKey := fmt.Sprintf("operator.openshift.io/dep-%s", k)
-		if len(annotationKey) > 63 {
-			hash := sha256.Sum256([]byte(k))
-			annotationKey = fmt.Sprintf("operator.openshift.io/dep-%x", hash)
-			annotationKey = annotationKey[:63]
-		}
+		annotationKey := resourcenames.PrefixedHash("operator.openshift.io/dep-", k, resourcenames.MaxLabelLength)
 		deployment.Annotations[annotationKey] = v
 		deployment.Spec.Template.Annotations[annotationKey] = v
 	}
@@ -1,7 +1,6 @@
 package csidrivernodeservicecontroller
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"strings"
 
@@ -12,6 +11,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/operator/csi/csiconfigobservercontroller"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcehash"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -105,12 +105,7 @@ func addObjectHash(daemonSet *appsv1.DaemonSet, inputHashes map[string]string) e
 		daemonSet.Spec.Template.Annotations = map[string]string{}
 	}
 	for k, v := range inputHashes {
-		annotationKey := fmt.Sprintf("operator.openshift.io/dep-%s", k)
-		if len(annotationKey) > 63 {
-			hash := sha256.Sum256([]byte(k))
-			annotationKey = fmt.Sprintf("operator.openshift.io/dep-%x", hash)
-			annotationKey = annotationKey[:63]
-		}
+		annotationKey := resourcenames.PrefixedHash("operator.openshift.io/dep-", k, resourcenames.MaxLabelLength)
 		daemonSet.Annotations[annotationKey] = v
 		daemonSet.Spec.Template.Annotations[annotationKey] = v
 	}
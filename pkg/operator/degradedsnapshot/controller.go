@@ -0,0 +1,228 @@
+package degradedsnapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// maxSnapshots is the number of snapshots kept in the config map, the oldest are rotated out first.
+	maxSnapshots = 5
+	// maxSnapshotBytes bounds a single snapshot, leaving room for maxSnapshots in a config map (1MiB).
+	maxSnapshotBytes = 150 * 1024
+
+	snapshotKeyPrefix = "snapshot-"
+
+	// degradedConditionsAnnotation on the config map lists the Degraded conditions that were true during the last
+	// sync, so transitions are detected across restarts.
+	degradedConditionsAnnotation = "operator.openshift.io/degraded-conditions"
+)
+
+// DegradedSnapshotController captures the recent events and the state of related objects into a config map every time
+// an operator condition of the Degraded type turns true. Events expire after an hour, the snapshots stay around
+// to explain transient failures. The Degraded conditions are recorded in the config map too, so a condition that turned
+// true while the operator restarted is captured by the first sync of the new process.
+type DegradedSnapshotController struct {
+	namespace       string
+	configMapName   string
+	operatorClient  v1helpers.OperatorClient
+	configMapClient corev1client.ConfigMapsGetter
+	sources         []Source
+	eventRecorder   events.Recorder
+
+	// degraded are the Degraded conditions that were true during the last sync, nil until they are read from the
+	// config map.
+	degraded sets.String
+	now      func() time.Time
+}
+
+// NewDegradedSnapshotController returns a controller writing snapshots of the sources to the config map.
+func NewDegradedSnapshotController(
+	namespace, configMapName string,
+	operatorClient v1helpers.OperatorClient,
+	configMapClient corev1client.ConfigMapsGetter,
+	sources []Source,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &DegradedSnapshotController{
+		namespace:       namespace,
+		configMapName:   configMapName,
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		sources:         sources,
+		eventRecorder:   eventRecorder.WithComponentSuffix("degraded-snapshot-controller"),
+		now:             time.Now,
+	}
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithSync(c.sync).
+		ToController("DegradedSnapshotController", eventRecorder)
+}
+
+func (c *DegradedSnapshotController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+
+	degraded := sets.NewString()
+	for _, condition := range status.Conditions {
+		if strings.HasSuffix(condition.Type, operatorv1.OperatorStatusTypeDegraded) && condition.Status == operatorv1.ConditionTrue {
+			degraded.Insert(condition.Type)
+		}
+	}
+	previous := c.degraded
+	if previous == nil {
+		if previous, err = c.lastDegraded(ctx); err != nil {
+			return err
+		}
+	}
+	if previous.Equal(degraded) {
+		c.degraded = degraded
+		return nil
+	}
+
+	newlyDegraded := degraded.Difference(previous)
+	snapshot := ""
+	if newlyDegraded.Len() > 0 {
+		snapshot = c.snapshot(ctx, newlyDegraded.List(), status.Conditions)
+	}
+	if err := c.store(ctx, degraded, snapshot); err != nil {
+		if len(snapshot) == 0 {
+			return err
+		}
+		// do not retry, the snapshot would not describe the transition anymore
+		klog.Warningf("Failed to store degraded snapshot in %s/%s: %v", c.namespace, c.configMapName, err)
+		c.degraded = degraded
+		return nil
+	}
+	c.degraded = degraded
+	if len(snapshot) > 0 {
+		c.eventRecorder.Eventf("DegradedSnapshotCaptured", "Captured snapshot of %s into config map %s/%s", strings.Join(newlyDegraded.List(), ", "), c.namespace, c.configMapName)
+	}
+	return nil
+}
+
+// lastDegraded returns the Degraded conditions recorded in the config map by the last sync, possibly of a previous
+// process.
+func (c *DegradedSnapshotController) lastDegraded(ctx context.Context) (sets.String, error) {
+	configMap, err := c.configMapClient.ConfigMaps(c.namespace).Get(ctx, c.configMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return sets.NewString(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDegradedConditions(configMap.Annotations[degradedConditionsAnnotation]), nil
+}
+
+func parseDegradedConditions(value string) sets.String {
+	ret := sets.NewString()
+	for _, conditionType := range strings.Split(value, ",") {
+		if conditionType = strings.TrimSpace(conditionType); len(conditionType) > 0 {
+			ret.Insert(conditionType)
+		}
+	}
+	return ret
+}
+
+func (c *DegradedSnapshotController) snapshot(ctx context.Context, conditionTypes []string, conditions []operatorv1.OperatorCondition) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# Degraded: %s at %s\n\n## conditions\n", strings.Join(conditionTypes, ", "), c.now().UTC().Format(time.RFC3339))
+	for _, condition := range conditions {
+		fmt.Fprintf(b, "%s=%s %s: %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+	for _, source := range c.sources {
+		content, err := source.Collect(ctx)
+		if err != nil {
+			content = fmt.Sprintf("failed to collect: %v", err)
+		}
+		fmt.Fprintf(b, "\n## %s\n%s\n", source.Name(), content)
+	}
+
+	snapshot := b.String()
+	if len(snapshot) > maxSnapshotBytes {
+		snapshot = truncate(snapshot, maxSnapshotBytes) + "\n... truncated"
+	}
+	return snapshot
+}
+
+// truncate cuts s to at most maxBytes without splitting a multi-byte character.
+func truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
+}
+
+// store records the Degraded conditions and, if not empty, adds the snapshot to the config map.
+func (c *DegradedSnapshotController) store(ctx context.Context, degraded sets.String, snapshot string) error {
+	key := snapshotKeyPrefix + c.now().UTC().Format("20060102T150405Z")
+	configMap, err := c.configMapClient.ConfigMaps(c.namespace).Get(ctx, c.configMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   c.namespace,
+				Name:        c.configMapName,
+				Annotations: map[string]string{degradedConditionsAnnotation: strings.Join(degraded.List(), ",")},
+			},
+		}
+		if len(snapshot) > 0 {
+			configMap.Data = map[string]string{key: snapshot}
+		}
+		_, err = c.configMapClient.ConfigMaps(c.namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	configMap = configMap.DeepCopy()
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[degradedConditionsAnnotation] = strings.Join(degraded.List(), ",")
+	if len(snapshot) > 0 {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[key] = snapshot
+		rotateSnapshots(configMap.Data)
+	}
+	_, err = c.configMapClient.ConfigMaps(c.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// rotateSnapshots removes the oldest snapshots beyond maxSnapshots. The keys sort by time.
+func rotateSnapshots(data map[string]string) {
+	keys := []string{}
+	for key := range data {
+		if strings.HasPrefix(key, snapshotKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for len(keys) > maxSnapshots {
+		delete(data, keys[0])
+		keys = keys[1:]
+	}
+}
@@ -0,0 +1,185 @@
+package degradedsnapshot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestSync(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := &operatorv1.OperatorStatus{}
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, status, nil)
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns", Name: "older"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "foo"},
+			Type:           corev1.EventTypeNormal, Reason: "Started", Message: "started container",
+			LastTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns", Name: "newer"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "foo"},
+			Type:           corev1.EventTypeWarning, Reason: "BackOff", Message: "back-off restarting failed container",
+			LastTimestamp: metav1.NewTime(now),
+		},
+	)
+	recorder := events.NewInMemoryRecorder("test")
+	c := &DegradedSnapshotController{
+		namespace:       "ns",
+		configMapName:   "degraded-snapshots",
+		operatorClient:  operatorClient,
+		configMapClient: kubeClient.CoreV1(),
+		sources:         []Source{NewEventsSource(kubeClient.CoreV1(), "ns", "", 10)},
+		eventRecorder:   recorder,
+		now:             func() time.Time { return now },
+	}
+	syncCtx := factory.NewSyncContext("test", recorder)
+	setDegraded := func(degraded bool) {
+		conditionStatus := operatorv1.ConditionFalse
+		if degraded {
+			conditionStatus = operatorv1.ConditionTrue
+		}
+		status.Conditions = []operatorv1.OperatorCondition{{Type: "FooDegraded", Status: conditionStatus, Reason: "Broken", Message: "foo is broken"}}
+	}
+
+	// the initial state is captured, nothing was recorded before
+	setDegraded(true)
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "degraded-snapshots", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.Data) != 1 || cm.Annotations[degradedConditionsAnnotation] != "FooDegraded" {
+		t.Fatalf("expected a snapshot of the initial state, got %v %v", cm.Annotations, cm.Data)
+	}
+
+	// a restarted controller does not capture the same transition again
+	restarted := *c
+	restarted.degraded = nil
+	if err := restarted.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if cm, err = kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "degraded-snapshots", metav1.GetOptions{}); err != nil || len(cm.Data) != 1 {
+		t.Fatalf("expected no new snapshot after restart, got %v: %v", cm.Data, err)
+	}
+
+	for i := 0; i < maxSnapshots+2; i++ {
+		now = now.Add(time.Minute)
+		setDegraded(false)
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+		setDegraded(true)
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm, err = kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "degraded-snapshots", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.Data) != maxSnapshots {
+		t.Errorf("expected %d snapshots, got %d", maxSnapshots, len(cm.Data))
+	}
+	if _, ok := cm.Data["snapshot-20220101T000200Z"]; ok {
+		t.Errorf("expected the oldest snapshots to be rotated out")
+	}
+	snapshot, ok := cm.Data["snapshot-20220101T000700Z"]
+	if !ok {
+		t.Fatalf("expected the latest snapshot, got keys %v", cm.Data)
+	}
+	for _, expected := range []string{"# Degraded: FooDegraded", "FooDegraded=True Broken: foo is broken", "## events in ns"} {
+		if !strings.Contains(snapshot, expected) {
+			t.Errorf("expected %q in snapshot:\n%s", expected, snapshot)
+		}
+	}
+	if strings.Index(snapshot, "BackOff") > strings.Index(snapshot, "Started") {
+		t.Errorf("expected newest events first:\n%s", snapshot)
+	}
+}
+
+func TestObjectSourceRedactsSecrets(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"namespace":   "ns",
+			"name":        "creds",
+			"annotations": map[string]interface{}{corev1.LastAppliedConfigAnnotation: `{"data":{"password":"c2VjcmV0"}}`},
+		},
+		"data":       map[string]interface{}{"password": "c2VjcmV0"},
+		"stringData": map[string]interface{}{"token": "secret"},
+	}}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), secret)
+
+	content, err := NewObjectSource(client, corev1.SchemeGroupVersion.WithResource("secrets"), "ns", "creds").Collect(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(content, "c2VjcmV0") || strings.Contains(content, "secret\n") {
+		t.Errorf("expected secret values to be redacted:\n%s", content)
+	}
+	for _, expected := range []string{"password: <redacted 8 bytes>", "token: <redacted 6 bytes>"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected %q in:\n%s", expected, content)
+		}
+	}
+}
+
+func TestEventsSourceReadsAllPages(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(name string, age time.Duration) corev1.Event {
+		return corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns", Name: name},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "foo"},
+			Type:           corev1.EventTypeNormal, Reason: name,
+			LastTimestamp: metav1.NewTime(now.Add(-age)),
+		}
+	}
+	// the fake client does not record the continue token, the pages are returned in order
+	pages := []*corev1.EventList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Event{event("Old", time.Hour), event("Older", 2*time.Hour)}},
+		{Items: []corev1.Event{event("Newest", 0)}},
+	}
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("list", "events", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		page := pages[0]
+		pages = pages[1:]
+		return true, page, nil
+	})
+
+	content, err := NewEventsSource(kubeClient.CoreV1(), "ns", "", 2).Collect(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "Newest") || !strings.Contains(content, "Old") || strings.Contains(content, "Older") {
+		t.Errorf("expected the two most recent events of all pages:\n%s", content)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if actual := truncate("aé", 2); actual != "a" {
+		t.Errorf("expected the multi-byte character to be dropped, got %q", actual)
+	}
+	if actual := truncate("aé", 3); actual != "aé" {
+		t.Errorf("expected the string to be kept, got %q", actual)
+	}
+}
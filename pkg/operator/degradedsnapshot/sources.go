@@ -0,0 +1,142 @@
+package degradedsnapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Source contributes a section to a snapshot.
+type Source interface {
+	// Name is the title of the section.
+	Name() string
+	// Collect returns the content of the section.
+	Collect(ctx context.Context) (string, error)
+}
+
+// eventsPageSize is the number of events read from the server per list call.
+const eventsPageSize = 500
+
+// NewEventsSource returns a source collecting the most recent events in the namespace matching the field selector,
+// e.g. "type=Warning" or "involvedObject.kind=Deployment", newest first. An empty selector matches all events.
+// The events are listed in pages, only the most recent limit events are kept in memory.
+func NewEventsSource(client corev1client.EventsGetter, namespace, fieldSelector string, limit int) Source {
+	return &eventsSource{client: client, namespace: namespace, fieldSelector: fieldSelector, limit: limit}
+}
+
+type eventsSource struct {
+	client        corev1client.EventsGetter
+	namespace     string
+	fieldSelector string
+	limit         int
+}
+
+func (s *eventsSource) Name() string {
+	return fmt.Sprintf("events in %s", s.namespace)
+}
+
+func (s *eventsSource) Collect(ctx context.Context) (string, error) {
+	// the server returns events in no particular order, all pages have to be read to find the most recent ones
+	var events []corev1.Event
+	options := metav1.ListOptions{FieldSelector: s.fieldSelector, Limit: eventsPageSize}
+	for {
+		list, err := s.client.Events(s.namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		events = append(events, list.Items...)
+		sort.SliceStable(events, func(i, j int) bool {
+			return eventTime(&events[i]).After(eventTime(&events[j]).Time)
+		})
+		if s.limit > 0 && len(events) > s.limit {
+			events = events[:s.limit]
+		}
+		if len(list.Continue) == 0 {
+			break
+		}
+		options.Continue = list.Continue
+	}
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("%s %s %s %s/%s: %s",
+			eventTime(&event).UTC().Format("2006-01-02T15:04:05Z"), event.Type, event.Reason,
+			strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, event.Message))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func eventTime(event *corev1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.Time{Time: event.EventTime.Time}
+	default:
+		return event.FirstTimestamp
+	}
+}
+
+// NewObjectSource returns a source collecting the state of a single object as YAML, without managed fields.
+// The values of secrets are redacted, only their keys and sizes are collected.
+func NewObjectSource(client dynamic.Interface, resource schema.GroupVersionResource, namespace, name string) Source {
+	return &objectSource{client: client, resource: resource, namespace: namespace, name: name}
+}
+
+type objectSource struct {
+	client    dynamic.Interface
+	resource  schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+func (s *objectSource) Name() string {
+	if len(s.namespace) == 0 {
+		return fmt.Sprintf("%s/%s", s.resource.Resource, s.name)
+	}
+	return fmt.Sprintf("%s/%s -n %s", s.resource.Resource, s.name, s.namespace)
+}
+
+func (s *objectSource) Collect(ctx context.Context) (string, error) {
+	var client dynamic.ResourceInterface = s.client.Resource(s.resource)
+	if len(s.namespace) > 0 {
+		client = s.client.Resource(s.resource).Namespace(s.namespace)
+	}
+	obj, err := client.Get(ctx, s.name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	obj.SetManagedFields(nil)
+	if s.resource.Group == "" && s.resource.Resource == "secrets" {
+		redactSecret(obj.Object)
+	}
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// redactSecret replaces the values of the data and stringData of a secret with their size.
+func redactSecret(secret map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := secret[field].(map[string]interface{})
+		if !ok {
+			delete(secret, field)
+			continue
+		}
+		for key, value := range values {
+			values[key] = fmt.Sprintf("<redacted %d bytes>", len(fmt.Sprint(value)))
+		}
+	}
+	// kubectl apply keeps the whole object, including the data, in an annotation
+	unstructured.RemoveNestedField(secret, "metadata", "annotations", corev1.LastAppliedConfigAnnotation)
+}
@@ -0,0 +1,152 @@
+package dependencycheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// dependencyState is the result of the recent probes of a dependency.
+type dependencyState struct {
+	lastProbe           time.Time
+	consecutiveFailures int
+	lastErr             error
+}
+
+// DependencyCheckController probes the external dependencies of an operator in their intervals and maps the
+// results to the <Name>DependencyDegraded and <Name>DependencyAvailable operator conditions and to metrics.
+type DependencyCheckController struct {
+	operatorClient v1helpers.OperatorClient
+	dependencies   []Dependency
+
+	states map[string]*dependencyState
+	now    func() time.Time
+}
+
+// NewDependencyCheckController returns a controller probing the dependencies. The controller resyncs
+// in the shortest interval of the dependencies and only probes the dependencies that are due.
+func NewDependencyCheckController(
+	name string,
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+	dependencies ...Dependency,
+) factory.Controller {
+	c := &DependencyCheckController{
+		operatorClient: operatorClient,
+		states:         map[string]*dependencyState{},
+		now:            time.Now,
+	}
+	resync := defaultInterval
+	for _, dependency := range dependencies {
+		dependency = dependency.withDefaults()
+		if dependency.Interval < resync {
+			resync = dependency.Interval
+		}
+		c.dependencies = append(c.dependencies, dependency)
+		c.states[dependency.Name] = &dependencyState{}
+	}
+
+	return factory.New().
+		WithSync(c.sync).
+		ResyncEvery(resync).
+		ToController(name, eventRecorder)
+}
+
+func (c *DependencyCheckController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	now := c.now()
+	due := []Dependency{}
+	for _, dependency := range c.dependencies {
+		if state := c.states[dependency.Name]; state.lastProbe.IsZero() || !now.Before(state.lastProbe.Add(dependency.Interval)) {
+			due = append(due, dependency)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	// probe concurrently, a slow dependency must not delay the others
+	errs := make([]error, len(due))
+	wg := sync.WaitGroup{}
+	for i := range due {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.probe(ctx, due[i])
+		}(i)
+	}
+	wg.Wait()
+
+	updateFuncs := []v1helpers.UpdateStatusFunc{}
+	for i, dependency := range due {
+		state := c.states[dependency.Name]
+		state.lastProbe = now
+		state.lastErr = errs[i]
+		if errs[i] == nil {
+			if state.consecutiveFailures >= dependency.FailureThreshold {
+				syncCtx.Recorder().Eventf("DependencyRecovered", "Dependency %s is healthy again", dependency.Name)
+			}
+			state.consecutiveFailures = 0
+		} else {
+			state.consecutiveFailures++
+			if state.consecutiveFailures == dependency.FailureThreshold {
+				syncCtx.Recorder().Warningf("DependencyUnhealthy", "Dependency %s failed %d consecutive probes: %v", dependency.Name, state.consecutiveFailures, errs[i])
+			}
+		}
+		healthy := state.consecutiveFailures < dependency.FailureThreshold
+		metrics.setUp(dependency.Name, healthy)
+		for _, condition := range conditionsFor(dependency, state, healthy) {
+			updateFuncs = append(updateFuncs, v1helpers.UpdateConditionFn(condition))
+		}
+	}
+
+	_, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, updateFuncs...)
+	return err
+}
+
+func (c *DependencyCheckController) probe(ctx context.Context, dependency Dependency) error {
+	ctx, cancel := context.WithTimeout(ctx, dependency.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dependency.Probe(ctx)
+	metrics.observeProbe(dependency.Name, time.Since(start).Seconds(), err != nil)
+	return err
+}
+
+// conditionsFor maps the state of a dependency to its conditions. Failures below the threshold keep reporting
+// the dependency healthy, but the message mentions them.
+func conditionsFor(dependency Dependency, state *dependencyState, healthy bool) []operatorv1.OperatorCondition {
+	degraded := operatorv1.OperatorCondition{
+		Type:   dependency.degradedConditionType(),
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	available := operatorv1.OperatorCondition{
+		Type:   dependency.availableConditionType(),
+		Status: operatorv1.ConditionTrue,
+		Reason: "AsExpected",
+	}
+	switch {
+	case !healthy:
+		degraded.Status = operatorv1.ConditionTrue
+		degraded.Reason = "ProbeFailed"
+		degraded.Message = fmt.Sprintf("%d consecutive probes failed: %v", state.consecutiveFailures, state.lastErr)
+		available.Status = operatorv1.ConditionFalse
+		available.Reason = degraded.Reason
+		available.Message = degraded.Message
+	case state.consecutiveFailures > 0:
+		degraded.Message = fmt.Sprintf("%d of %d allowed consecutive probes failed: %v", state.consecutiveFailures, dependency.FailureThreshold-1, state.lastErr)
+	}
+
+	if !dependency.AffectsAvailability {
+		return []operatorv1.OperatorCondition{degraded}
+	}
+	return []operatorv1.OperatorCondition{degraded, available}
+}
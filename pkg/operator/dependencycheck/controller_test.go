@@ -0,0 +1,124 @@
+package dependencycheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestSync(t *testing.T) {
+	var cloudErr error
+	storageProbes := 0
+	now := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("test")
+	c := &DependencyCheckController{
+		operatorClient: operatorClient,
+		states:         map[string]*dependencyState{},
+		now:            func() time.Time { return now },
+	}
+	for _, dependency := range []Dependency{
+		{Name: "CloudAPI", Probe: func(context.Context) error { return cloudErr }, Interval: time.Minute, FailureThreshold: 2, AffectsAvailability: true},
+		{Name: "Storage", Probe: func(context.Context) error { storageProbes++; return nil }, Interval: 10 * time.Minute},
+	} {
+		dependency = dependency.withDefaults()
+		c.dependencies = append(c.dependencies, dependency)
+		c.states[dependency.Name] = &dependencyState{}
+	}
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	expectConditions := func(expected map[string]operatorv1.ConditionStatus) {
+		t.Helper()
+		_, status, _, _ := operatorClient.GetOperatorState()
+		if len(status.Conditions) != len(expected) {
+			t.Errorf("expected %d conditions, got %v", len(expected), status.Conditions)
+		}
+		for conditionType, conditionStatus := range expected {
+			if condition := v1helpers.FindOperatorCondition(status.Conditions, conditionType); condition == nil || condition.Status != conditionStatus {
+				t.Errorf("expected %s=%s, got %v", conditionType, conditionStatus, condition)
+			}
+		}
+	}
+	sync := func(after time.Duration) {
+		t.Helper()
+		now = now.Add(after)
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sync(0)
+	expectConditions(map[string]operatorv1.ConditionStatus{
+		"CloudAPIDependencyDegraded":  operatorv1.ConditionFalse,
+		"CloudAPIDependencyAvailable": operatorv1.ConditionTrue,
+		"StorageDependencyDegraded":   operatorv1.ConditionFalse,
+	})
+
+	// a single failure is below the threshold
+	cloudErr = fmt.Errorf("connection refused")
+	sync(time.Minute)
+	expectConditions(map[string]operatorv1.ConditionStatus{
+		"CloudAPIDependencyDegraded":  operatorv1.ConditionFalse,
+		"CloudAPIDependencyAvailable": operatorv1.ConditionTrue,
+		"StorageDependencyDegraded":   operatorv1.ConditionFalse,
+	})
+
+	// not due yet
+	sync(30 * time.Second)
+	if c.states["CloudAPI"].consecutiveFailures != 1 {
+		t.Errorf("expected no probe before the interval, got %d failures", c.states["CloudAPI"].consecutiveFailures)
+	}
+
+	sync(30 * time.Second)
+	expectConditions(map[string]operatorv1.ConditionStatus{
+		"CloudAPIDependencyDegraded":  operatorv1.ConditionTrue,
+		"CloudAPIDependencyAvailable": operatorv1.ConditionFalse,
+		"StorageDependencyDegraded":   operatorv1.ConditionFalse,
+	})
+
+	cloudErr = nil
+	sync(time.Minute)
+	expectConditions(map[string]operatorv1.ConditionStatus{
+		"CloudAPIDependencyDegraded":  operatorv1.ConditionFalse,
+		"CloudAPIDependencyAvailable": operatorv1.ConditionTrue,
+		"StorageDependencyDegraded":   operatorv1.ConditionFalse,
+	})
+
+	if storageProbes != 1 {
+		t.Errorf("expected storage to be probed once within its interval, got %d", storageProbes)
+	}
+	reasons := []string{}
+	for _, event := range recorder.Events() {
+		reasons = append(reasons, event.Reason)
+	}
+	if fmt.Sprint(reasons) != "[DependencyUnhealthy DependencyRecovered]" {
+		t.Errorf("unexpected events: %v", reasons)
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	probe := HTTPProbe(server.Client(), server.URL)
+	if err := probe(context.TODO()); err != nil {
+		t.Error(err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := probe(context.TODO()); err == nil {
+		t.Error("expected 503 to fail the probe")
+	}
+}
@@ -0,0 +1,78 @@
+package dependencycheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultInterval         = time.Minute
+	defaultFailureThreshold = 3
+)
+
+// ProbeFunc checks an external dependency and returns an error when it is not healthy.
+type ProbeFunc func(ctx context.Context) error
+
+// Dependency is an external dependency of the operator, e.g. a cloud API, an identity provider or a storage backend.
+type Dependency struct {
+	// Name is the CamelCase name of the dependency, e.g. CloudAPI. The conditions reported for the dependency are
+	// <Name>DependencyDegraded and, if AffectsAvailability is set, <Name>DependencyAvailable.
+	Name string
+	// Probe checks the dependency.
+	Probe ProbeFunc
+	// Interval is the time between probes. Defaults to one minute.
+	Interval time.Duration
+	// Timeout bounds a single probe. Defaults to the interval.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed probes before the dependency is reported unhealthy.
+	// A single successful probe reports it healthy again. Defaults to 3.
+	FailureThreshold int
+	// AffectsAvailability reports an unhealthy dependency as not available in addition to degraded.
+	AffectsAvailability bool
+}
+
+func (d Dependency) degradedConditionType() string {
+	return d.Name + "DependencyDegraded"
+}
+
+func (d Dependency) availableConditionType() string {
+	return d.Name + "DependencyAvailable"
+}
+
+func (d Dependency) withDefaults() Dependency {
+	if d.Interval <= 0 {
+		d.Interval = defaultInterval
+	}
+	if d.Timeout <= 0 {
+		d.Timeout = d.Interval
+	}
+	if d.FailureThreshold <= 0 {
+		d.FailureThreshold = defaultFailureThreshold
+	}
+	return d
+}
+
+// HTTPProbe returns a probe sending a GET request to the url. Any status code other than 2xx fails the probe.
+func HTTPProbe(client *http.Client, url string) ProbeFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		// drain the body to reuse the connection for the next probe
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("GET %s returned %s", url, resp.Status)
+		}
+		return nil
+	}
+}
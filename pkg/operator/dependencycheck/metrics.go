@@ -0,0 +1,52 @@
+package dependencycheck
+
+import (
+	k8smetrics "k8s.io/component-base/metrics"
+
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+)
+
+// metrics provides access to the dependency check metrics.
+var metrics = newDependencyMetrics()
+
+type dependencyMetrics struct {
+	up            *librarymetrics.GaugeVec
+	probeFailures *librarymetrics.CounterVec
+	probeDuration *librarymetrics.HistogramVec
+}
+
+func newDependencyMetrics() *dependencyMetrics {
+	return &dependencyMetrics{
+		up: librarymetrics.NewGaugeVec(
+			&k8smetrics.GaugeOpts{
+				Name: "openshift_operator_dependency_up",
+				Help: "Whether an external dependency of the operator is healthy (1) or not (0), labeled with the dependency name",
+			}, []string{"dependency"}),
+		probeFailures: librarymetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Name: "openshift_operator_dependency_probe_failures_total",
+				Help: "The total number of failed probes of an external dependency, labeled with the dependency name",
+			}, []string{"dependency"}),
+		probeDuration: librarymetrics.NewHistogramVec(
+			&k8smetrics.HistogramOpts{
+				Name:    "openshift_operator_dependency_probe_duration_seconds",
+				Help:    "How long a probe of an external dependency takes in seconds, labeled with the dependency name",
+				Buckets: k8smetrics.DefBuckets,
+			}, []string{"dependency"}),
+	}
+}
+
+func (m *dependencyMetrics) observeProbe(dependency string, seconds float64, failed bool) {
+	m.probeDuration.WithLabelValues(dependency).Observe(seconds)
+	if failed {
+		m.probeFailures.WithLabelValues(dependency).Inc()
+	}
+}
+
+func (m *dependencyMetrics) setUp(dependency string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.up.WithLabelValues(dependency).Set(value)
+}
@@ -17,6 +17,7 @@ import (
 	opv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/reasons"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
@@ -169,7 +170,10 @@ func (c *DeploymentController) syncManaged(ctx context.Context, opSpec *opv1.Ope
 		Status: opv1.ConditionFalse,
 	}
 
-	if ok, msg := isProgressing(deployment); ok {
+	if msg := resourceapply.PausedMessage(deployment); len(msg) > 0 {
+		progressingCondition.Message = msg
+		progressingCondition.Reason = string(reasons.Paused)
+	} else if ok, msg := isProgressing(deployment); ok {
 		progressingCondition.Status = opv1.ConditionTrue
 		progressingCondition.Message = msg
 		progressingCondition.Reason = "Deploying"
@@ -5,9 +5,15 @@ import (
 	"os"
 
 	opv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/forceredeploy"
+	"github.com/openshift/library-go/pkg/operator/nodeplacement"
+	"github.com/openshift/library-go/pkg/operator/podtemplatemutator"
+	"github.com/openshift/library-go/pkg/operator/workloadtier"
 )
 
 // WithReplicasHook sets the deployment.Spec.Replicas field according to the number
@@ -38,3 +44,55 @@ func WithImageHook() DeploymentHookFunc {
 		return nil
 	}
 }
+
+// WithNodePlacementHook sets node selector, tolerations and affinity of the deployment according to
+// the placement policy, the cluster infrastructure and the infra nodes, see nodeplacement.ComputePlacement.
+func WithNodePlacementHook(infraLister configlistersv1.InfrastructureLister, nodeLister corev1listers.NodeLister, policy nodeplacement.Policy) DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		infra, err := infraLister.Get("cluster")
+		if err != nil {
+			return err
+		}
+		hasInfraNodes, err := nodeplacement.HasInfraNodes(nodeLister)
+		if err != nil {
+			return err
+		}
+		placement, err := nodeplacement.ComputePlacement(policy, infra, hasInfraNodes)
+		if err != nil {
+			return err
+		}
+		placement.ApplyTo(&deployment.Spec.Template.Spec)
+		return nil
+	}
+}
+
+// WithWorkloadTierHook sets the priority class of the deployment and the management workload annotation of its pods
+// according to the tier, see workloadtier.Tier.
+func WithWorkloadTierHook(tier workloadtier.Tier) DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		tier.ApplyTo(&deployment.Spec.Template)
+		return nil
+	}
+}
+
+// WithForceRedeploymentHook annotates the pod template of the deployment with the hash of the force redeployment
+// reason returned by reasonFn, so that a new reason rolls out new pods. Changes of the reason are recorded by the tracker.
+func WithForceRedeploymentHook(reasonFn func() (string, error), tracker *forceredeploy.Tracker) DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		reason, err := reasonFn()
+		if err != nil {
+			return err
+		}
+		forceredeploy.SetPodTemplateAnnotation(&deployment.Spec.Template, reason)
+		tracker.Observe(reason, "")
+		return nil
+	}
+}
+
+// WithPodTemplateMutatorsHook applies the mutators to the pod template of the deployment, e.g. to inject sidecar
+// containers. The mutators run in their order, see podtemplatemutator.Mutators.
+func WithPodTemplateMutatorsHook(mutators ...podtemplatemutator.Mutator) DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		return podtemplatemutator.Mutators(mutators).ApplyTo(&deployment.Spec.Template)
+	}
+}
@@ -132,20 +132,7 @@ func (m *InProcessMigrator) runMigration(gvr schema.GroupVersionResource, writeK
 	d := m.dynamicClient.Resource(gvr)
 
 	listProcessor := newListProcessor(ctx, m.dynamicClient, func(obj *unstructured.Unstructured) error {
-		for {
-			_, updateErr := d.Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
-			if updateErr == nil || errors.IsNotFound(updateErr) || errors.IsConflict(updateErr) {
-				return nil
-			}
-			if retryable := canRetry(updateErr); retryable == nil || *retryable == false {
-				klog.Warningf("Update of %s/%s failed: %v", obj.GetNamespace(), obj.GetName(), updateErr)
-				return updateErr // not retryable or we don't know. Return error and controller will restart migration.
-			}
-			if seconds, delay := errors.SuggestsClientDelay(updateErr); delay && seconds > 0 {
-				klog.V(2).Infof("Sleeping %ds while updating %s/%s of type %v after retryable error: %v", seconds, obj.GetNamespace(), obj.GetName(), gvr, updateErr)
-				time.Sleep(time.Duration(seconds) * time.Second)
-			}
-		}
+		return touchObject(ctx, d, obj)
 	})
 	result = listProcessor.run(ctx, gvr)
 }
@@ -195,3 +182,26 @@ func preferredResourceVersion(c discovery.ServerResourcesInterface, gr schema.Gr
 	}
 	return "", fmt.Errorf("failed to find version for %s, discoveryErr=%v", gr, discoveryErr)
 }
+
+// touchObject does a no-op update of obj, forcing the apiserver to re-write it to storage with the current
+// write key. Conflicts and not-found errors are ignored, retryable errors are retried.
+func touchObject(ctx context.Context, d dynamic.NamespaceableResourceInterface, obj *unstructured.Unstructured) error {
+	for {
+		_, updateErr := d.Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+		if updateErr == nil || errors.IsNotFound(updateErr) || errors.IsConflict(updateErr) {
+			return nil
+		}
+		if retryable := canRetry(updateErr); retryable == nil || *retryable == false {
+			klog.Warningf("Update of %s/%s failed: %v", obj.GetNamespace(), obj.GetName(), updateErr)
+			return updateErr // not retryable or we don't know. Return error and controller will restart migration.
+		}
+		if seconds, delay := errors.SuggestsClientDelay(updateErr); delay && seconds > 0 {
+			klog.V(2).Infof("Sleeping %ds while updating %s/%s of type %v after retryable error: %v", seconds, obj.GetNamespace(), obj.GetName(), obj.GroupVersionKind(), updateErr)
+			select {
+			case <-time.After(time.Duration(seconds) * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
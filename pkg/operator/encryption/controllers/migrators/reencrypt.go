@@ -0,0 +1,127 @@
+package migrators
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/pager"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+const defaultReencryptionPageSize = 500
+
+// ReencryptionProgress is reported after every processed page.
+type ReencryptionProgress struct {
+	Resource  schema.GroupVersionResource
+	Namespace string
+	// Processed is the number of objects of Resource in Namespace touched so far.
+	Processed int
+}
+
+// Reencryptor touches (no-op updates) all objects of the given resources in a set of namespaces, forcing the
+// apiserver to re-write them to etcd with the current encryption write key. This is what the in-process migrator
+// does cluster-wide; Reencryptor is meant for standalone use after a key rotation, e.g. limited to the namespaces
+// of an operand.
+type Reencryptor struct {
+	dynamicClient dynamic.Interface
+	resources     []schema.GroupVersionResource
+	namespaces    []string
+	pageSize      int64
+	rateLimiter   flowcontrol.RateLimiter
+	progressFn    func(ReencryptionProgress)
+}
+
+// NewReencryptor returns a Reencryptor for secrets and configmaps in the given namespaces.
+func NewReencryptor(dynamicClient dynamic.Interface, namespaces ...string) *Reencryptor {
+	return &Reencryptor{
+		dynamicClient: dynamicClient,
+		resources: []schema.GroupVersionResource{
+			{Version: "v1", Resource: "secrets"},
+			{Version: "v1", Resource: "configmaps"},
+		},
+		namespaces: namespaces,
+		pageSize:   defaultReencryptionPageSize,
+	}
+}
+
+// WithResources overrides the resources to touch.
+func (r *Reencryptor) WithResources(resources ...schema.GroupVersionResource) *Reencryptor {
+	r.resources = resources
+	return r
+}
+
+// WithPageSize sets the page size used to list objects.
+func (r *Reencryptor) WithPageSize(pageSize int64) *Reencryptor {
+	r.pageSize = pageSize
+	return r
+}
+
+// WithRateLimiter limits the rate of updates sent to the apiserver, e.g. flowcontrol.NewTokenBucketRateLimiter(10, 20).
+func (r *Reencryptor) WithRateLimiter(rateLimiter flowcontrol.RateLimiter) *Reencryptor {
+	r.rateLimiter = rateLimiter
+	return r
+}
+
+// WithProgress sets a function that is called after every processed page.
+func (r *Reencryptor) WithProgress(progressFn func(ReencryptionProgress)) *Reencryptor {
+	r.progressFn = progressFn
+	return r
+}
+
+// Run touches all objects. It stops at the first error, or when ctx is done.
+func (r *Reencryptor) Run(ctx context.Context) error {
+	for _, gvr := range r.resources {
+		for _, ns := range r.namespaces {
+			if err := r.reencrypt(ctx, gvr, ns); err != nil {
+				return fmt.Errorf("failed to re-encrypt %s in namespace %q: %v", gvr.Resource, ns, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Reencryptor) reencrypt(ctx context.Context, gvr schema.GroupVersionResource, namespace string) error {
+	d := r.dynamicClient.Resource(gvr)
+	progress := ReencryptionProgress{Resource: gvr, Namespace: namespace}
+
+	listPager := pager.New(pager.SimplePageFunc(func(opts metav1.ListOptions) (runtime.Object, error) {
+		list, err := d.Namespace(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if err := r.touch(ctx, d, &list.Items[i]); err != nil {
+				return nil, err
+			}
+			progress.Processed++
+		}
+		if r.progressFn != nil {
+			r.progressFn(progress)
+		}
+		list.Items = nil // do not accumulate items
+		return list, nil
+	}))
+	listPager.PageSize = r.pageSize
+	listPager.FullListIfExpired = false
+
+	if _, _, err := listPager.List(ctx, metav1.ListOptions{}); err != nil {
+		return err
+	}
+	klog.V(2).Infof("Re-encrypted %d %s in namespace %q", progress.Processed, gvr.Resource, namespace)
+	return nil
+}
+
+func (r *Reencryptor) touch(ctx context.Context, d dynamic.NamespaceableResourceInterface, obj *unstructured.Unstructured) error {
+	if r.rateLimiter != nil {
+		if err := r.rateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return touchObject(ctx, d, obj)
+}
@@ -0,0 +1,67 @@
+package migrators
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestReencryptor(t *testing.T) {
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		schema.GroupResource{Resource: "secrets"}.WithVersion("v1"):    "SecretList",
+		schema.GroupResource{Resource: "configmaps"}.WithVersion("v1"): "ConfigMapList",
+	}
+	resources := []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm1", Namespace: "ns1"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: "ns1"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret2", Namespace: "ns2"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret3", Namespace: "other"}},
+	}
+	unstructuredObjs := []runtime.Object{}
+	for _, rawObject := range resources {
+		rawUnstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(rawObject.DeepCopyObject())
+		if err != nil {
+			t.Fatal(err)
+		}
+		unstructured.SetNestedField(rawUnstructured, "v1", "apiVersion")
+		unstructured.SetNestedField(rawUnstructured, reflect.TypeOf(rawObject).Elem().Name(), "kind")
+		unstructuredObjs = append(unstructuredObjs, &unstructured.Unstructured{Object: rawUnstructured})
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, unstructuredObjs...)
+
+	var progress []ReencryptionProgress
+	err := NewReencryptor(dynamicClient, "ns1", "ns2").
+		WithPageSize(1).
+		WithProgress(func(p ReencryptionProgress) { progress = append(progress, p) }).
+		Run(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var updated []string
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "update" {
+			updated = append(updated, action.GetNamespace()+"/"+action.GetResource().Resource)
+		}
+	}
+	expectedUpdated := []string{"ns1/secrets", "ns2/secrets", "ns1/configmaps"}
+	if !reflect.DeepEqual(expectedUpdated, updated) {
+		t.Errorf("expected updates %v, got %v", expectedUpdated, updated)
+	}
+
+	processed := map[string]int{}
+	for _, p := range progress {
+		processed[p.Namespace+"/"+p.Resource.Resource] = p.Processed
+	}
+	expectedProcessed := map[string]int{"ns1/secrets": 1, "ns2/secrets": 1, "ns1/configmaps": 1, "ns2/configmaps": 0}
+	if !reflect.DeepEqual(expectedProcessed, processed) {
+		t.Errorf("expected progress %v, got %v", expectedProcessed, processed)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	encryptiontesting "github.com/openshift/library-go/pkg/operator/encryption/testing"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -813,7 +814,8 @@ func validateSecretWithEncryptionConfig(actualSecret *corev1.Secret, expectedEnc
 			Name:      expectedSecretName,
 			Namespace: "openshift-config-managed",
 			Annotations: map[string]string{
-				state.KubernetesDescriptionKey: state.KubernetesDescriptionScaryValue,
+				state.KubernetesDescriptionKey:          state.KubernetesDescriptionScaryValue,
+				resourceapply.SecretDataHashAnnotation: resourceapply.SecretDataHash(actualSecret.Data),
 			},
 			Finalizers: []string{"encryption.apiserver.operator.openshift.io/deletion-protection"},
 		},
@@ -15,6 +15,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/operator/encryption/encryptionconfig"
 	"github.com/openshift/library-go/pkg/operator/encryption/statemachine"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -97,7 +98,7 @@ func (d *RevisionLabelPodDeployer) DeployedEncryptionConfigSecret(ctx context.Co
 		return nil, false, nil
 	}
 
-	s, err := d.secretClient.Get(ctx, encryptionconfig.EncryptionConfSecretName+"-"+revision, metav1.GetOptions{})
+	s, err := d.secretClient.Get(ctx, resourcenames.WithSuffix(encryptionconfig.EncryptionConfSecretName, revision, resourcenames.MaxNameLength), metav1.GetOptions{})
 	if err != nil {
 		// if encryption is not enabled at this revision or the secret was deleted, we should not error
 		if errors.IsNotFound(err) {
@@ -0,0 +1,162 @@
+package endpointauth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/apiserver/pkg/server/options"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultAuthenticationCacheTTL = 2 * time.Minute
+	defaultAllowCacheTTL          = 2 * time.Minute
+	defaultDenyCacheTTL           = 10 * time.Second
+	defaultReviewTimeout          = 10 * time.Second
+)
+
+// Config configures the authentication of requests to operator HTTP endpoints, e.g. debug or metrics endpoints, by
+// TokenReview and their authorization by SubjectAccessReview against the kube-apiserver. Both results are cached.
+type Config struct {
+	// Client creates the TokenReviews and SubjectAccessReviews.
+	Client kubernetes.Interface
+	// AuthenticationCacheTTL is how long a token review is cached. Defaults to two minutes.
+	AuthenticationCacheTTL time.Duration
+	// AllowCacheTTL is how long an allowed subject access review is cached. Defaults to two minutes.
+	AllowCacheTTL time.Duration
+	// DenyCacheTTL is how long a denied subject access review is cached. Defaults to ten seconds.
+	DenyCacheTTL time.Duration
+	// AlwaysAllowPaths are served without authentication and authorization, e.g. /healthz.
+	AlwaysAllowPaths []string
+}
+
+// WithAuthenticationAndAuthorization wraps handler and only lets requests through whose bearer token is valid and
+// whose user is allowed to access the request path as non-resource URL with the lower-case request method as verb,
+// e.g. "get" on "/metrics". It makes kube-rbac-proxy sidecars unnecessary for such endpoints.
+func (c Config) WithAuthenticationAndAuthorization(handler http.Handler) (http.Handler, error) {
+	if c.Client == nil {
+		return nil, fmt.Errorf("missing client")
+	}
+	authenticationCacheTTL := c.AuthenticationCacheTTL
+	if authenticationCacheTTL <= 0 {
+		authenticationCacheTTL = defaultAuthenticationCacheTTL
+	}
+
+	authn, _, err := authenticatorfactory.DelegatingAuthenticatorConfig{
+		Anonymous:                false,
+		TokenAccessReviewClient:  c.Client.AuthenticationV1(),
+		TokenAccessReviewTimeout: defaultReviewTimeout,
+		WebhookRetryBackoff:      options.DefaultAuthWebhookRetryBackoff(),
+		CacheTTL:                 authenticationCacheTTL,
+	}.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %v", err)
+	}
+	authz, err := c.NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	return withAuthenticationAndAuthorization(handler, authn, authz, sets.NewString(c.AlwaysAllowPaths...)), nil
+}
+
+// NewAuthorizer returns the cached SubjectAccessReview authorizer used by WithAuthenticationAndAuthorization, for
+// endpoints which authenticate requests by other means, e.g. by client certificate. See WithAuthorization.
+func (c Config) NewAuthorizer() (authorizer.Authorizer, error) {
+	if c.Client == nil {
+		return nil, fmt.Errorf("missing client")
+	}
+	allowCacheTTL := c.AllowCacheTTL
+	if allowCacheTTL <= 0 {
+		allowCacheTTL = defaultAllowCacheTTL
+	}
+	denyCacheTTL := c.DenyCacheTTL
+	if denyCacheTTL <= 0 {
+		denyCacheTTL = defaultDenyCacheTTL
+	}
+
+	authz, err := authorizerfactory.DelegatingAuthorizerConfig{
+		SubjectAccessReviewClient: c.Client.AuthorizationV1(),
+		AllowCacheTTL:             allowCacheTTL,
+		DenyCacheTTL:              denyCacheTTL,
+		WebhookRetryBackoff:       options.DefaultAuthWebhookRetryBackoff(),
+	}.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %v", err)
+	}
+	return authz, nil
+}
+
+// WithAuthorization wraps handler and only lets requests through whose user, as returned by userFor, is allowed to
+// access the request path as non-resource URL with the lower-case request method as verb. Requests without user are
+// unauthorized.
+func WithAuthorization(handler http.Handler, authz authorizer.Authorizer, userFor func(req *http.Request) (user.Info, bool)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u, ok := userFor(req)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if authorize(w, req, authz, u) {
+			handler.ServeHTTP(w, req)
+		}
+	})
+}
+
+func withAuthenticationAndAuthorization(handler http.Handler, authn authenticator.Request, authz authorizer.Authorizer, alwaysAllowPaths sets.String) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if alwaysAllowPaths.Has(req.URL.Path) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		resp, ok, err := authn.AuthenticateRequest(req)
+		if err != nil {
+			klog.V(4).Infof("Failed to authenticate request for %s: %v", req.URL.Path, err)
+		}
+		if err != nil || !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		// the token must not be passed on to the handler
+		req.Header.Del("Authorization")
+
+		if authorize(w, req, authz, resp.User) {
+			handler.ServeHTTP(w, req)
+		}
+	})
+}
+
+// authorize writes the error response and returns false if the user is not allowed to access the request path.
+func authorize(w http.ResponseWriter, req *http.Request, authz authorizer.Authorizer, u user.Info) bool {
+	verb := strings.ToLower(req.Method)
+	if verb == "head" {
+		verb = "get"
+	}
+	decision, reason, err := authz.Authorize(req.Context(), authorizer.AttributesRecord{
+		User:            u,
+		Verb:            verb,
+		Path:            req.URL.Path,
+		ResourceRequest: false,
+	})
+	if err != nil {
+		klog.Warningf("Failed to authorize %q for %s %s: %v", u.GetName(), verb, req.URL.Path, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false
+	}
+	if decision != authorizer.DecisionAllow {
+		klog.V(4).Infof("Forbidden %q to %s %s: %s", u.GetName(), verb, req.URL.Path, reason)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
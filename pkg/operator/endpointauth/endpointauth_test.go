@@ -0,0 +1,82 @@
+package endpointauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWithAuthenticationAndAuthorization(t *testing.T) {
+	authn := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		switch req.Header.Get("Authorization") {
+		case "Bearer prometheus-token":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "system:serviceaccount:openshift-monitoring:prometheus-k8s"}}, true, nil
+		case "Bearer other-token":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "system:serviceaccount:default:other"}}, true, nil
+		}
+		return nil, false, nil
+	})
+	authz := authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+		if a.GetUser().GetName() == "system:serviceaccount:openshift-monitoring:prometheus-k8s" &&
+			!a.IsResourceRequest() && a.GetPath() == "/metrics" && a.GetVerb() == "get" {
+			return authorizer.DecisionAllow, "", nil
+		}
+		return authorizer.DecisionNoOpinion, "", nil
+	})
+
+	var forwardedAuthorization string
+	handler := withAuthenticationAndAuthorization(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		forwardedAuthorization = req.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}), authn, authz, sets.NewString("/healthz"))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "always allowed path", method: http.MethodGet, path: "/healthz", expectedStatus: http.StatusOK},
+		{name: "anonymous", method: http.MethodGet, path: "/metrics", expectedStatus: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/metrics", token: "invalid", expectedStatus: http.StatusUnauthorized},
+		{name: "allowed", method: http.MethodGet, path: "/metrics", token: "prometheus-token", expectedStatus: http.StatusOK},
+		{name: "head is get", method: http.MethodHead, path: "/metrics", token: "prometheus-token", expectedStatus: http.StatusOK},
+		{name: "wrong verb", method: http.MethodPost, path: "/metrics", token: "prometheus-token", expectedStatus: http.StatusForbidden},
+		{name: "wrong path", method: http.MethodGet, path: "/debug/pprof", token: "prometheus-token", expectedStatus: http.StatusForbidden},
+		{name: "other user", method: http.MethodGet, path: "/metrics", token: "other-token", expectedStatus: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if len(test.token) > 0 {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected %d, got %d", test.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	if len(forwardedAuthorization) > 0 {
+		t.Errorf("expected the token to be removed before calling the handler")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if _, err := (Config{}).WithAuthenticationAndAuthorization(http.NotFoundHandler()); err == nil {
+		t.Errorf("expected an error without client")
+	}
+	if _, err := (Config{Client: fake.NewSimpleClientset()}).WithAuthenticationAndAuthorization(http.NotFoundHandler()); err != nil {
+		t.Error(err)
+	}
+}
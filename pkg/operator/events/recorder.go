@@ -196,6 +196,9 @@ func (r *recorder) Warningf(reason, messageFmt string, args ...interface{}) {
 
 // Event emits the normal type event.
 func (r *recorder) Event(reason, message string) {
+	if !shouldEmit(r.sourceComponent, corev1.EventTypeNormal, reason, message) {
+		return
+	}
 	event := makeEvent(r.involvedObjectRef, r.sourceComponent, corev1.EventTypeNormal, reason, message)
 	ctx := context.Background()
 	if r.ctx != nil {
@@ -208,6 +211,9 @@ func (r *recorder) Event(reason, message string) {
 
 // Warning emits the warning type event.
 func (r *recorder) Warning(reason, message string) {
+	if !shouldEmit(r.sourceComponent, corev1.EventTypeWarning, reason, message) {
+		return
+	}
 	event := makeEvent(r.involvedObjectRef, r.sourceComponent, corev1.EventTypeWarning, reason, message)
 	ctx := context.Background()
 	if r.ctx != nil {
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// NewEventRecorderAdapter returns a client-go record.EventRecorder, as used by controller-runtime, that sends the events
+// through the given recorder. The object the events are about is ignored: the events are attributed to the involved
+// object and component of the recorder, like the events of library-go controllers running in the same process.
+// Annotations are dropped.
+func NewEventRecorderAdapter(recorder Recorder) record.EventRecorder {
+	return &eventRecorderAdapter{recorder: recorder}
+}
+
+type eventRecorderAdapter struct {
+	recorder Recorder
+}
+
+func (r *eventRecorderAdapter) Event(_ runtime.Object, eventtype, reason, message string) {
+	if eventtype == corev1.EventTypeWarning {
+		r.recorder.Warning(reason, message)
+		return
+	}
+	r.recorder.Event(reason, message)
+}
+
+func (r *eventRecorderAdapter) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *eventRecorderAdapter) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// NewRecorderFromEventRecorder returns a Recorder that sends the events through the given client-go record.EventRecorder,
+// e.g. from the GetEventRecorderFor() of a controller-runtime manager, about the involved object. The source component
+// of the events is the one of the client-go recorder, the component name of the returned recorder is only used for
+// event sampling.
+func NewRecorderFromEventRecorder(recorder record.EventRecorder, involvedObject runtime.Object, componentName string) Recorder {
+	return &fromEventRecorder{recorder: recorder, involvedObject: involvedObject, component: componentName}
+}
+
+type fromEventRecorder struct {
+	recorder       record.EventRecorder
+	involvedObject runtime.Object
+	component      string
+}
+
+func (r *fromEventRecorder) ComponentName() string {
+	return r.component
+}
+
+func (r *fromEventRecorder) ForComponent(componentName string) Recorder {
+	newRecorderForComponent := *r
+	newRecorderForComponent.component = componentName
+	return &newRecorderForComponent
+}
+
+func (r *fromEventRecorder) WithComponentSuffix(suffix string) Recorder {
+	return r.ForComponent(fmt.Sprintf("%s-%s", r.ComponentName(), suffix))
+}
+
+// WithContext is a no-op, the client-go recorder creates the events asynchronously.
+func (r *fromEventRecorder) WithContext(ctx context.Context) Recorder {
+	return r
+}
+
+// Shutdown is a no-op, the owner of the client-go recorder shuts down its broadcaster.
+func (r *fromEventRecorder) Shutdown() {}
+
+func (r *fromEventRecorder) Event(reason, message string) {
+	if !shouldEmit(r.component, corev1.EventTypeNormal, reason, message) {
+		return
+	}
+	r.recorder.Event(r.involvedObject, corev1.EventTypeNormal, reason, message)
+}
+
+func (r *fromEventRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *fromEventRecorder) Warning(reason, message string) {
+	if !shouldEmit(r.component, corev1.EventTypeWarning, reason, message) {
+		return
+	}
+	r.recorder.Event(r.involvedObject, corev1.EventTypeWarning, reason, message)
+}
+
+func (r *fromEventRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventRecorderAdapter(t *testing.T) {
+	recorder := NewInMemoryRecorder("operator")
+	adapter := NewEventRecorderAdapter(recorder)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+
+	adapter.Event(pod, corev1.EventTypeNormal, "Created", "created foo")
+	adapter.Eventf(pod, corev1.EventTypeWarning, "Failed", "failed %s", "foo")
+	adapter.AnnotatedEventf(pod, map[string]string{"key": "value"}, corev1.EventTypeNormal, "Annotated", "annotated %d", 1)
+
+	expected := []struct{ eventType, reason, message string }{
+		{corev1.EventTypeNormal, "Created", "created foo"},
+		{corev1.EventTypeWarning, "Failed", "failed foo"},
+		{corev1.EventTypeNormal, "Annotated", "annotated 1"},
+	}
+	events := recorder.Events()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %v", len(expected), events)
+	}
+	for i, e := range expected {
+		if events[i].Type != e.eventType || events[i].Reason != e.reason || events[i].Message != e.message {
+			t.Errorf("expected %v, got %s %s %s", e, events[i].Type, events[i].Reason, events[i].Message)
+		}
+	}
+}
+
+func TestRecorderFromEventRecorder(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	recorder := NewRecorderFromEventRecorder(fakeRecorder, &corev1.Pod{}, "operator")
+
+	recorder.Eventf("Created", "created %s", "foo")
+	recorder.Warning("Failed", "failed foo")
+
+	for _, expected := range []string{"Normal Created created foo", "Warning Failed failed foo"} {
+		select {
+		case actual := <-fakeRecorder.Events:
+			if actual != expected {
+				t.Errorf("expected %q, got %q", expected, actual)
+			}
+		default:
+			t.Errorf("expected %q, got no event", expected)
+		}
+	}
+
+	if name := recorder.WithComponentSuffix("controller").ComponentName(); name != "operator-controller" {
+		t.Errorf("expected component operator-controller, got %q", name)
+	}
+}
@@ -157,6 +157,9 @@ func (r *upstreamRecorder) Event(reason, message string) {
 		r.fallbackRecorder.Event(reason, message)
 		return
 	}
+	if !shouldEmit(r.component, corev1.EventTypeNormal, reason, message) {
+		return
+	}
 	r.eventRecorder.Event(r.involvedObjectRef, corev1.EventTypeNormal, reason, message)
 }
 
@@ -169,5 +172,8 @@ func (r *upstreamRecorder) Warning(reason, message string) {
 		r.fallbackRecorder.Warning(reason, message)
 		return
 	}
+	if !shouldEmit(r.component, corev1.EventTypeWarning, reason, message) {
+		return
+	}
 	r.eventRecorder.Event(r.involvedObjectRef, corev1.EventTypeWarning, reason, message)
 }
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// SamplingMode controls which events are sent by the recorders of this package. It is process-wide, so that clusters
+// with strict event volume budgets can be accommodated without touching every Recorder call site.
+type SamplingMode string
+
+const (
+	// EmitAll sends every event. This is the default.
+	EmitAll SamplingMode = "All"
+	// EmitWarningsOnly drops all normal events.
+	EmitWarningsOnly SamplingMode = "WarningsOnly"
+	// DropRepeatedNormal drops normal events which repeat (same component, reason and message) within the repeat
+	// window, e.g. notices emitted on every periodic resync.
+	DropRepeatedNormal SamplingMode = "DropRepeatedNormal"
+
+	// EventSamplingEnv is the environment variable read by SetSamplingModeFromEnv.
+	EventSamplingEnv = "OPERATOR_EVENT_SAMPLING"
+
+	defaultRepeatWindow = 10 * time.Minute
+)
+
+var (
+	// samplingMode holds the current SamplingMode. It is read on every event without taking samplingLock.
+	samplingMode atomic.Value
+
+	// samplingLock guards the repeat tracking of DropRepeatedNormal.
+	samplingLock sync.Mutex
+	repeatWindow = defaultRepeatWindow
+	// lastSeen holds the last emission time of normal events in DropRepeatedNormal mode.
+	lastSeen = map[string]time.Time{}
+	// seenOrder holds the keys of lastSeen in emission order, so that expired entries are dropped from its head
+	// instead of scanning lastSeen on every event.
+	seenOrder []seenEvent
+	// now allows to override the clock in unit tests.
+	now = time.Now
+)
+
+type seenEvent struct {
+	key  string
+	time time.Time
+}
+
+func currentSamplingMode() SamplingMode {
+	if mode, ok := samplingMode.Load().(SamplingMode); ok {
+		return mode
+	}
+	return EmitAll
+}
+
+// SetSamplingMode sets the process-wide event sampling mode.
+func SetSamplingMode(mode SamplingMode) error {
+	switch mode {
+	case EmitAll, EmitWarningsOnly, DropRepeatedNormal:
+	case "":
+		mode = EmitAll
+	default:
+		return fmt.Errorf("unknown event sampling mode %q, must be one of %s, %s, %s", mode, EmitAll, EmitWarningsOnly, DropRepeatedNormal)
+	}
+	samplingLock.Lock()
+	defer samplingLock.Unlock()
+	samplingMode.Store(mode)
+	lastSeen = map[string]time.Time{}
+	seenOrder = nil
+	return nil
+}
+
+// SetSamplingModeFromEnv sets the sampling mode from the OPERATOR_EVENT_SAMPLING environment variable, if set.
+func SetSamplingModeFromEnv() error {
+	mode, ok := os.LookupEnv(EventSamplingEnv)
+	if !ok {
+		return nil
+	}
+	return SetSamplingMode(SamplingMode(mode))
+}
+
+// AddSamplingFlags adds the --event-sampling flag to fs. It defaults to the value of OPERATOR_EVENT_SAMPLING.
+func AddSamplingFlags(fs *pflag.FlagSet) {
+	fs.Var(&samplingModeFlag{}, "event-sampling", fmt.Sprintf("Which events to emit: %s, %s or %s. Defaults to $%s or %s.", EmitAll, EmitWarningsOnly, DropRepeatedNormal, EventSamplingEnv, EmitAll))
+}
+
+type samplingModeFlag struct{}
+
+func (f *samplingModeFlag) String() string {
+	return string(currentSamplingMode())
+}
+
+func (f *samplingModeFlag) Set(value string) error {
+	return SetSamplingMode(SamplingMode(value))
+}
+
+func (f *samplingModeFlag) Type() string {
+	return "string"
+}
+
+// shouldEmit returns whether an event passes the current sampling mode.
+func shouldEmit(component, eventType, reason, message string) bool {
+	if eventType == corev1.EventTypeWarning {
+		return true
+	}
+	switch mode := currentSamplingMode(); mode {
+	case EmitWarningsOnly:
+		klog.V(4).Infof("Dropping event %s: %s (sampling mode %s)", reason, message, mode)
+		return false
+	case DropRepeatedNormal:
+		if isRepeated(component + "\x00" + reason + "\x00" + message) {
+			klog.V(4).Infof("Dropping repeated event %s: %s (sampling mode %s)", reason, message, mode)
+			return false
+		}
+	}
+	return true
+}
+
+// isRepeated returns whether the event was emitted within the repeat window and records it otherwise.
+func isRepeated(key string) bool {
+	samplingLock.Lock()
+	defer samplingLock.Unlock()
+
+	current := now()
+	// forget expired entries, oldest first, so the map does not grow unbounded
+	for len(seenOrder) > 0 && current.Sub(seenOrder[0].time) >= repeatWindow {
+		if last, ok := lastSeen[seenOrder[0].key]; ok && last.Equal(seenOrder[0].time) {
+			delete(lastSeen, seenOrder[0].key)
+		}
+		seenOrder = seenOrder[1:]
+	}
+	if _, ok := lastSeen[key]; ok {
+		return true
+	}
+	lastSeen[key] = current
+	seenOrder = append(seenOrder, seenEvent{key: key, time: current})
+	return false
+}
+
+func init() {
+	if err := SetSamplingModeFromEnv(); err != nil {
+		klog.Warningf("Ignoring %s: %v", EventSamplingEnv, err)
+	}
+}
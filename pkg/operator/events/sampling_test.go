@@ -0,0 +1,86 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSampling(t *testing.T) {
+	defer SetSamplingMode(EmitAll)
+	current := time.Now()
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name     string
+		mode     SamplingMode
+		emit     func(r Recorder)
+		expected int
+	}{
+		{
+			name: "all",
+			mode: EmitAll,
+			emit: func(r Recorder) {
+				r.Event("Resync", "nothing changed")
+				r.Event("Resync", "nothing changed")
+				r.Warning("Failed", "broken")
+			},
+			expected: 3,
+		},
+		{
+			name: "warnings only",
+			mode: EmitWarningsOnly,
+			emit: func(r Recorder) {
+				r.Event("Resync", "nothing changed")
+				r.Warning("Failed", "broken")
+				r.Warning("Failed", "broken")
+			},
+			expected: 2,
+		},
+		{
+			name: "drop repeated normal",
+			mode: DropRepeatedNormal,
+			emit: func(r Recorder) {
+				r.Event("Resync", "nothing changed")
+				r.Event("Resync", "nothing changed")
+				r.Event("Resync", "something changed")
+				r.Warning("Failed", "broken")
+				r.Warning("Failed", "broken")
+				current = current.Add(defaultRepeatWindow)
+				r.Event("Resync", "nothing changed")
+			},
+			expected: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := SetSamplingMode(test.mode); err != nil {
+				t.Fatal(err)
+			}
+			client := fake.NewSimpleClientset()
+			r := NewRecorder(client.CoreV1().Events("test-namespace"), "test-operator", &corev1.ObjectReference{Namespace: "test-namespace", Name: "test"})
+			test.emit(r)
+			if actions := len(client.Actions()); actions != test.expected {
+				t.Errorf("expected %d events, got %d", test.expected, actions)
+			}
+		})
+	}
+
+	if err := SetSamplingMode(DropRepeatedNormal); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		shouldEmit("test-operator", corev1.EventTypeNormal, "Resync", "nothing changed")
+		current = current.Add(defaultRepeatWindow)
+	}
+	if len(lastSeen) != 1 || len(seenOrder) != 1 {
+		t.Errorf("expected expired events to be forgotten, got %d tracked events", len(lastSeen))
+	}
+
+	if err := SetSamplingMode("Unknown"); err == nil {
+		t.Errorf("expected error for unknown sampling mode")
+	}
+}
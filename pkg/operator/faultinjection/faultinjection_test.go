@@ -0,0 +1,160 @@
+package faultinjection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestScheduleIsDeterministic(t *testing.T) {
+	faults := func() []Fault {
+		s := NewSchedule(42, Rule{Fault: Conflict, Probability: 0.5}, Rule{Fault: Timeout, Probability: 0.2})
+		var ret []Fault
+		for i := 0; i < 100; i++ {
+			fault, _ := s.Next(Request{Verb: "update", Resource: "configmaps"}, false)
+			ret = append(ret, fault)
+		}
+		return ret
+	}
+	first, second := faults(), faults()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same faults for the same seed:\n%v\n%v", first, second)
+	}
+	seen := map[Fault]bool{}
+	for _, fault := range first {
+		seen[fault] = true
+	}
+	if !seen[Conflict] || !seen[Timeout] || !seen[""] {
+		t.Errorf("expected conflicts, timeouts and successes, got %v", first)
+	}
+}
+
+func TestScheduleMatching(t *testing.T) {
+	s := NewSchedule(0,
+		Rule{Fault: Conflict, Resources: []string{"deployments/status"}, Probability: 1, Limit: 1},
+		Rule{Fault: StaleRead, Probability: 1},
+	)
+
+	if _, inject := s.Next(Request{Verb: "update", Resource: "deployments"}, false); inject {
+		t.Errorf("expected no fault for other resources")
+	}
+	if fault, _ := s.Next(Request{Verb: "update", Resource: "deployments", Subresource: "status"}, false); fault != Conflict {
+		t.Errorf("expected a conflict, got %q", fault)
+	}
+	if _, inject := s.Next(Request{Verb: "update", Resource: "deployments", Subresource: "status"}, false); inject {
+		t.Errorf("expected no fault above the limit")
+	}
+	if _, inject := s.Next(Request{Verb: "get", Resource: "deployments"}, false); inject {
+		t.Errorf("expected no stale read without earlier read")
+	}
+	if fault, _ := s.Next(Request{Verb: "get", Resource: "deployments"}, true); fault != StaleRead {
+		t.Errorf("expected a stale read, got %q", fault)
+	}
+	if len(s.Injections()) != 2 {
+		t.Errorf("expected two injections, got %v", s.Injections())
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	resourceVersion := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resourceVersion++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", ResourceVersion: strconv.Itoa(resourceVersion)},
+		})
+	}))
+	defer server.Close()
+
+	schedule := NewSchedule(0,
+		Rule{Fault: StaleRead, Probability: 1, Limit: 1},
+		Rule{Fault: Conflict, Probability: 1, Limit: 1},
+		Rule{Fault: Timeout, Verbs: []string{"delete"}, Probability: 1},
+	)
+	client := kubernetes.NewForConfigOrDie(Inject(&rest.Config{Host: server.URL}, schedule))
+	configMaps := client.CoreV1().ConfigMaps("ns")
+
+	cm, err := configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.ResourceVersion != "1" {
+		t.Errorf("expected the first read to be fresh, got %q", cm.ResourceVersion)
+	}
+	cm, err = configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.ResourceVersion != "1" {
+		t.Errorf("expected a stale read, got %q", cm.ResourceVersion)
+	}
+	cm, err = configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.ResourceVersion != "2" {
+		t.Errorf("expected a fresh read, got %q", cm.ResourceVersion)
+	}
+
+	if _, err := configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{}); !apierrors.IsConflict(err) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if _, err := configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Errorf("expected no error above the limit, got %v", err)
+	}
+	if err := configMaps.Delete(context.TODO(), "foo", metav1.DeleteOptions{}); !apierrors.IsTimeout(err) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestInjectIntoFake(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}})
+	InjectIntoFake(client, NewSchedule(0,
+		Rule{Fault: StaleRead, Probability: 1, Limit: 1},
+		Rule{Fault: Conflict, Probability: 1, Limit: 1},
+	))
+	configMaps := client.CoreV1().ConfigMaps("ns")
+
+	cm, err := configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.Data = map[string]string{"key": "value"}
+	if _, err := configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{}); !apierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if _, err := configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err = configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.Data) != 0 {
+		t.Errorf("expected a stale read, got %v", cm.Data)
+	}
+	cm, err = configMaps.Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["key"] != "value" {
+		t.Errorf("expected a fresh read, got %v", cm.Data)
+	}
+
+	if _, err := configMaps.Get(context.TODO(), "missing", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package faultinjection
+
+import (
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/klog/v2"
+)
+
+// Fake is implemented by the fake clientsets.
+type Fake interface {
+	PrependReactor(verb, resource string, reaction clienttesting.ReactionFunc)
+	Tracker() clienttesting.ObjectTracker
+}
+
+// InjectIntoFake makes the fake clientset fail according to the schedule, for unit tests of controllers. Stale reads
+// are injected into gets only.
+func InjectIntoFake(client Fake, schedule *Schedule) {
+	client.PrependReactor("*", "*", NewReactor(client.Tracker(), schedule))
+}
+
+// NewReactor returns a reaction injecting faults into the actions of a fake clientset backed by the tracker.
+func NewReactor(tracker clienttesting.ObjectTracker, schedule *Schedule) clienttesting.ReactionFunc {
+	var lock sync.Mutex
+	// objects are the objects last returned by gets by resource, namespace and name
+	objects := map[string]runtime.Object{}
+
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		r := Request{
+			Verb:        action.GetVerb(),
+			Resource:    action.GetResource().Resource,
+			Subresource: action.GetSubresource(),
+			Namespace:   action.GetNamespace(),
+		}
+		var key string
+		getAction, isGet := action.(clienttesting.GetAction)
+		if isGet {
+			r.Name = getAction.GetName()
+			key = fmt.Sprintf("%s/%s/%s/%s", action.GetResource().GroupResource(), r.Subresource, r.Namespace, r.Name)
+		}
+
+		lock.Lock()
+		stale, staleAvailable := objects[key]
+		lock.Unlock()
+
+		fault, inject := schedule.Next(r, isGet && staleAvailable)
+		if inject {
+			klog.V(2).Infof("Injecting %s into %s", fault, r)
+			switch fault {
+			case StaleRead:
+				return true, stale.DeepCopyObject(), nil
+			case Conflict:
+				return true, nil, apierrors.NewConflict(action.GetResource().GroupResource(), r.Name, fmt.Errorf("injected fault"))
+			case Timeout:
+				return true, nil, apierrors.NewTimeoutError("injected fault", 0)
+			}
+		}
+		if !isGet {
+			return false, nil, nil
+		}
+
+		// serve the get here to remember the object for later stale reads
+		obj, err := tracker.Get(action.GetResource(), r.Namespace, r.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		lock.Lock()
+		objects[key] = obj.DeepCopyObject()
+		lock.Unlock()
+		return true, obj, nil
+	}
+}
@@ -0,0 +1,132 @@
+package faultinjection
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Fault is a failure injected into a client request.
+type Fault string
+
+const (
+	// Conflict fails the request with 409 Conflict, as if the resourceVersion of the request was outdated.
+	Conflict Fault = "Conflict"
+	// Timeout fails the request with 504 Gateway Timeout, as if the server did not respond in time.
+	Timeout Fault = "Timeout"
+	// StaleRead answers a read with the response the same read got before, as if it was served from an outdated cache.
+	// The first read of an object or list is never stale.
+	StaleRead Fault = "StaleRead"
+)
+
+// defaultVerbs are the verbs a rule without verbs matches.
+var defaultVerbs = map[Fault]sets.String{
+	Conflict:  sets.NewString("create", "update", "patch"),
+	Timeout:   sets.NewString("get", "list", "create", "update", "patch", "delete", "deletecollection"),
+	StaleRead: sets.NewString("get", "list"),
+}
+
+// Rule injects a fault into the matching requests with a probability.
+type Rule struct {
+	Fault Fault
+	// Verbs are the request verbs, e.g. get or update. Defaults to the verbs the fault makes sense for.
+	Verbs []string
+	// Resources are the resources, e.g. configmaps or deployments/status. Empty matches all resources.
+	Resources []string
+	// Probability in [0,1] the fault is injected with into a matching request.
+	Probability float64
+	// Limit is the maximum number of injections, 0 for no limit.
+	Limit int
+}
+
+// Request identifies a client request.
+type Request struct {
+	Verb        string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string
+}
+
+func (r Request) resource() string {
+	if len(r.Subresource) > 0 {
+		return r.Resource + "/" + r.Subresource
+	}
+	return r.Resource
+}
+
+func (r Request) String() string {
+	return fmt.Sprintf("%s %s %s/%s", r.Verb, r.resource(), r.Namespace, r.Name)
+}
+
+// Injection is a fault injected into a request.
+type Injection struct {
+	Fault   Fault
+	Request Request
+}
+
+// Schedule decides which requests fail. The decisions are drawn from a random source seeded with a fixed seed, so the
+// same sequence of requests always gets the same faults. Controllers issuing requests concurrently make the sequence,
+// and thus the faults, non-deterministic.
+type Schedule struct {
+	lock       sync.Mutex
+	rand       *rand.Rand
+	rules      []Rule
+	counts     []int
+	injections []Injection
+}
+
+// NewSchedule returns a schedule applying the rules in order, the first rule that injects a fault wins.
+func NewSchedule(seed int64, rules ...Rule) *Schedule {
+	return &Schedule{
+		rand:   rand.New(rand.NewSource(seed)),
+		rules:  rules,
+		counts: make([]int, len(rules)),
+	}
+}
+
+// Next returns the fault to inject into the request, if any. Stale reads are only possible when staleAvailable is true.
+func (s *Schedule) Next(req Request, staleAvailable bool) (Fault, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, rule := range s.rules {
+		if !rule.matches(req) {
+			continue
+		}
+		if rule.Limit > 0 && s.counts[i] >= rule.Limit {
+			continue
+		}
+		// draw for every matching rule, so the decisions do not depend on whether a stale response is available
+		if s.rand.Float64() >= rule.Probability {
+			continue
+		}
+		if rule.Fault == StaleRead && !staleAvailable {
+			continue
+		}
+		s.counts[i]++
+		s.injections = append(s.injections, Injection{Fault: rule.Fault, Request: req})
+		return rule.Fault, true
+	}
+	return "", false
+}
+
+// Injections returns the faults injected so far.
+func (s *Schedule) Injections() []Injection {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]Injection(nil), s.injections...)
+}
+
+func (r Rule) matches(req Request) bool {
+	verbs := defaultVerbs[r.Fault]
+	if len(r.Verbs) > 0 {
+		verbs = sets.NewString(r.Verbs...)
+	}
+	if !verbs.Has(req.Verb) {
+		return false
+	}
+	return len(r.Resources) == 0 || sets.NewString(r.Resources...).Has(req.resource())
+}
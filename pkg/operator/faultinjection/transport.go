@@ -0,0 +1,120 @@
+package faultinjection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+var requestInfoFactory = &request.RequestInfoFactory{
+	APIPrefixes:          sets.NewString("api", "apis"),
+	GrouplessAPIPrefixes: sets.NewString("api"),
+}
+
+// Inject returns a copy of the config whose clients fail according to the schedule. It is meant for tests only, e.g.
+//
+//	kubeClient := kubernetes.NewForConfigOrDie(faultinjection.Inject(config, schedule))
+func Inject(config *rest.Config, schedule *Schedule) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(NewRoundTripper(schedule))
+	return config
+}
+
+// NewRoundTripper returns a middleware injecting faults into the API requests according to the schedule.
+func NewRoundTripper(schedule *Schedule) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &faultInjectingRT{baseRT: rt, schedule: schedule, responses: map[string]cachedResponse{}}
+	}
+}
+
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+type faultInjectingRT struct {
+	baseRT   http.RoundTripper
+	schedule *Schedule
+
+	lock sync.Mutex
+	// responses are the last successful responses of reads by URL
+	responses map[string]cachedResponse
+}
+
+func (rt *faultInjectingRT) RoundTrip(req *http.Request) (*http.Response, error) {
+	info, err := requestInfoFactory.NewRequestInfo(req)
+	if err != nil || !info.IsResourceRequest {
+		return rt.baseRT.RoundTrip(req)
+	}
+	r := Request{Verb: info.Verb, Resource: info.Resource, Subresource: info.Subresource, Namespace: info.Namespace, Name: info.Name}
+	isRead := r.Verb == "get" || r.Verb == "list"
+	key := req.URL.String()
+
+	rt.lock.Lock()
+	stale, staleAvailable := rt.responses[key]
+	rt.lock.Unlock()
+
+	fault, inject := rt.schedule.Next(r, isRead && staleAvailable)
+	if inject {
+		klog.V(2).Infof("Injecting %s into %s", fault, r)
+		switch fault {
+		case StaleRead:
+			return newResponse(req, stale.statusCode, stale.header, stale.body), nil
+		case Conflict:
+			return newStatusResponse(req, apierrors.NewConflict(schema.GroupResource{Group: info.APIGroup, Resource: info.Resource}, info.Name, fmt.Errorf("injected fault")))
+		case Timeout:
+			return newStatusResponse(req, apierrors.NewTimeoutError("injected fault", 0))
+		}
+	}
+
+	resp, err := rt.baseRT.RoundTrip(req)
+	if err != nil || !isRead || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	rt.lock.Lock()
+	rt.responses[key] = cachedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	rt.lock.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func newStatusResponse(req *http.Request, err *apierrors.StatusError) (*http.Response, error) {
+	status := err.Status()
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	body, marshalErr := json.Marshal(status)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return newResponse(req, int(status.Code), http.Header{"Content-Type": []string{"application/json"}}, body), nil
+}
+
+func newResponse(req *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
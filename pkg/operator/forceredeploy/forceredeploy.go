@@ -0,0 +1,86 @@
+package forceredeploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// ReasonHashAnnotation is set on pod templates of deployment based operands to the hash of the force redeployment
+	// reason, so that changing the reason rolls out new pods.
+	ReasonHashAnnotation = "operator.openshift.io/force-redeployment-reason-hash"
+
+	// ReasonKey is the config map key holding the force redeployment reason of static pod operands.
+	ReasonKey = "forceRedeploymentReason"
+	// ReasonHashKey is the config map key holding the hash of the force redeployment reason of static pod operands.
+	ReasonHashKey = "forceRedeploymentReasonHash"
+)
+
+// Hash returns a stable hash of the force redeployment reason, or an empty string when there is no reason.
+func Hash(reason string) string {
+	if len(reason) == 0 {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(reason))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// SetPodTemplateAnnotation annotates the pod template with the hash of the reason, or removes the annotation
+// when there is no reason.
+func SetPodTemplateAnnotation(template *corev1.PodTemplateSpec, reason string) {
+	if len(reason) == 0 {
+		delete(template.Annotations, ReasonHashAnnotation)
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[ReasonHashAnnotation] = Hash(reason)
+}
+
+// Tracker records an event every time the force redeployment reason of an operand changes.
+// The first observed reason is only remembered, as it was either acted upon by a previous process or is the initial state.
+type Tracker struct {
+	operand  string
+	recorder events.Recorder
+
+	lock     sync.Mutex
+	observed bool
+	last     string
+}
+
+// NewTracker returns a tracker for the given operand, e.g. "deployment/openshift-foo/foo".
+func NewTracker(operand string, recorder events.Recorder) *Tracker {
+	return &Tracker{operand: operand, recorder: recorder}
+}
+
+// Observe records the current reason and returns true when it changed since the last observation.
+// The source, when known, documents where the change came from, e.g. the resource version of the operator resource
+// which allows finding the user in the audit log.
+func (t *Tracker) Observe(reason, source string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.observed && t.last == reason {
+		return false
+	}
+	changed := t.observed
+	t.observed = true
+	t.last = reason
+	if !changed {
+		return false
+	}
+	switch {
+	case len(reason) == 0:
+		t.recorder.Eventf("ForceRedeploymentCleared", "Force redeployment reason of %s was cleared", t.operand)
+	case len(source) > 0:
+		t.recorder.Eventf("ForceRedeploymentRequested", "Redeployment of %s was forced (%s): %q", t.operand, source, reason)
+	default:
+		t.recorder.Eventf("ForceRedeploymentRequested", "Redeployment of %s was forced: %q", t.operand, reason)
+	}
+	return true
+}
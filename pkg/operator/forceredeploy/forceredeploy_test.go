@@ -0,0 +1,97 @@
+package forceredeploy
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestSetPodTemplateAnnotation(t *testing.T) {
+	template := &corev1.PodTemplateSpec{}
+	SetPodTemplateAnnotation(template, "kick it")
+	first := template.Annotations[ReasonHashAnnotation]
+	if len(first) == 0 {
+		t.Fatalf("expected annotation to be set")
+	}
+	SetPodTemplateAnnotation(template, "kick it again")
+	if template.Annotations[ReasonHashAnnotation] == first {
+		t.Errorf("expected a new reason to change the annotation")
+	}
+	SetPodTemplateAnnotation(template, "")
+	if _, ok := template.Annotations[ReasonHashAnnotation]; ok {
+		t.Errorf("expected annotation to be removed")
+	}
+}
+
+func TestTracker(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test")
+	tracker := NewTracker("deployment/ns/foo", recorder)
+
+	for _, step := range []struct {
+		reason          string
+		expectedChanged bool
+	}{
+		{reason: "initial"},
+		{reason: "initial"},
+		{reason: "second", expectedChanged: true},
+		{reason: "", expectedChanged: true},
+	} {
+		if changed := tracker.Observe(step.reason, ""); changed != step.expectedChanged {
+			t.Errorf("reason %q: expected changed %v, got %v", step.reason, step.expectedChanged, changed)
+		}
+	}
+	if len(recorder.Events()) != 2 {
+		t.Fatalf("expected 2 events, got %v", recorder.Events())
+	}
+	if recorder.Events()[0].Reason != "ForceRedeploymentRequested" || recorder.Events()[1].Reason != "ForceRedeploymentCleared" {
+		t.Errorf("unexpected events: %v", recorder.Events())
+	}
+}
+
+func TestStaticPodForceRedeploymentController(t *testing.T) {
+	spec := &operatorv1.StaticPodOperatorSpec{}
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(spec, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	kubeClient := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+	c := &StaticPodForceRedeploymentController{
+		targetNamespace: "ns",
+		configMapName:   "force-redeployment",
+		operatorClient:  operatorClient,
+		configMapClient: kubeClient.CoreV1(),
+		tracker:         NewTracker("static pods in ns", recorder),
+	}
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	spec.ForceRedeploymentReason = "certificates were replaced manually"
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("ns").Get(context.TODO(), "force-redeployment", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data[ReasonKey] != spec.ForceRedeploymentReason || cm.Data[ReasonHashKey] != Hash(spec.ForceRedeploymentReason) {
+		t.Errorf("unexpected config map data: %v", cm.Data)
+	}
+	requested := 0
+	for _, event := range recorder.Events() {
+		if event.Reason == "ForceRedeploymentRequested" {
+			requested++
+		}
+	}
+	if requested != 1 {
+		t.Errorf("expected one ForceRedeploymentRequested event, got %v", recorder.Events())
+	}
+}
@@ -0,0 +1,70 @@
+package forceredeploy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// StaticPodForceRedeploymentController copies spec.forceRedeploymentReason of a static pod operator into a config map.
+// Listing the config map among the revisioned config maps of the revision controller makes every new reason create
+// a new revision, which in turn rolls out the static pods.
+type StaticPodForceRedeploymentController struct {
+	targetNamespace string
+	configMapName   string
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient corev1client.ConfigMapsGetter
+	tracker         *Tracker
+}
+
+// NewStaticPodForceRedeploymentController returns a controller maintaining the force redeployment config map.
+func NewStaticPodForceRedeploymentController(
+	targetNamespace, configMapName string,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	configMapClient corev1client.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &StaticPodForceRedeploymentController{
+		targetNamespace: targetNamespace,
+		configMapName:   configMapName,
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		tracker:         NewTracker(fmt.Sprintf("static pods in %s", targetNamespace), eventRecorder),
+	}
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithSync(c.sync).
+		ToController("StaticPodForceRedeploymentController", eventRecorder)
+}
+
+// ForceRedeploymentConfigMap returns the config map that carries the reason into static pod revisions.
+func ForceRedeploymentConfigMap(namespace, name, reason string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data: map[string]string{
+			ReasonKey:     reason,
+			ReasonHashKey: Hash(reason),
+		},
+	}
+}
+
+func (c *StaticPodForceRedeploymentController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, resourceVersion, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	reason := spec.ForceRedeploymentReason
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), ForceRedeploymentConfigMap(c.targetNamespace, c.configMapName, reason)); err != nil {
+		return err
+	}
+	c.tracker.Observe(reason, fmt.Sprintf("operator resourceVersion %s", resourceVersion))
+	return nil
+}
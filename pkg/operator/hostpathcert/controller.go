@@ -0,0 +1,81 @@
+package hostpathcert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// HostPathCertWriterController projects keys of a (rotated) secret onto the host filesystem.
+// It is meant to run in a DaemonSet for node-level agents that consume certificates from the host
+// and cannot mount the secret directly.
+type HostPathCertWriterController struct {
+	namespace     string
+	secretName    string
+	files         []HostPathFile
+	secretLister  corev1listers.SecretLister
+	eventRecorder events.Recorder
+}
+
+// NewHostPathCertWriterController returns a controller writing the given secret keys to the host paths.
+// The secret informer must be scoped to a namespace that contains the secret.
+func NewHostPathCertWriterController(
+	namespace, secretName string,
+	files []HostPathFile,
+	secretInformer corev1informers.SecretInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &HostPathCertWriterController{
+		namespace:     namespace,
+		secretName:    secretName,
+		files:         files,
+		secretLister:  secretInformer.Lister(),
+		eventRecorder: eventRecorder.WithComponentSuffix("host-path-cert-writer"),
+	}
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(secretName), secretInformer.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		ToController("HostPathCertWriterController", eventRecorder)
+}
+
+func (c *HostPathCertWriterController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	secret, err := c.secretLister.Secrets(c.namespace).Get(c.secretName)
+	if apierrors.IsNotFound(err) {
+		klog.V(2).Infof("Secret %s/%s not found, nothing to write", c.namespace, c.secretName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, file := range c.files {
+		content, ok := secret.Data[file.Key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("secret %s/%s is missing key %q", c.namespace, c.secretName, file.Key))
+			continue
+		}
+		// the file can be changed on the host by other actors, re-write it when it drifts
+		if err := VerifyFile(content, file); err == nil {
+			continue
+		}
+		klog.Infof("Writing key %q of secret %s/%s to %q ...", file.Key, c.namespace, c.secretName, file.Path)
+		if err := WriteFile(content, file); err != nil {
+			c.eventRecorder.Warningf("CertificateUpdateFailed", "Failed writing key %q of secret %s/%s to %q: %v", file.Key, c.namespace, c.secretName, file.Path, err)
+			errs = append(errs, err)
+			continue
+		}
+		c.eventRecorder.Eventf("CertificateUpdated", "Wrote key %q of secret %s/%s to %q", file.Key, c.namespace, c.secretName, file.Path)
+	}
+	return utilerrors.NewAggregate(errs)
+}
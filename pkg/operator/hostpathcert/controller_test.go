@@ -0,0 +1,113 @@
+package hostpathcert
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	file := HostPathFile{Key: "tls.crt", Path: filepath.Join(dir, "certs", "tls.crt"), Mode: 0640}
+
+	if err := WriteFile([]byte("cert"), file); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("expected mode 0640, got %v", info.Mode().Perm())
+	}
+	if err := VerifyFile([]byte("other"), file); err == nil {
+		t.Errorf("expected content mismatch to be reported")
+	}
+	if err := os.Chmod(file.Path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFile([]byte("cert"), file); err == nil {
+		t.Errorf("expected mode mismatch to be reported")
+	}
+	if err := os.Chmod(file.Path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	labeled := file
+	labeled.SELinuxLabel = "system_u:object_r:hostpathcert_test_t:s0"
+	if err := VerifyFile([]byte("cert"), labeled); err == nil {
+		t.Errorf("expected SELinux label mismatch to be reported")
+	}
+	if err := WriteFile([]byte("cert"), HostPathFile{Path: "relative/tls.crt"}); err == nil {
+		t.Errorf("expected relative path to be rejected")
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	files := []HostPathFile{
+		{Key: "tls.crt", Path: filepath.Join(dir, "tls.crt"), Mode: 0644},
+		{Key: "tls.key", Path: filepath.Join(dir, "tls.key")},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "serving-cert"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := events.NewInMemoryRecorder("test")
+	c := &HostPathCertWriterController{
+		namespace:     "ns",
+		secretName:    "serving-cert",
+		files:         files,
+		secretLister:  corev1listers.NewSecretLister(indexer),
+		eventRecorder: recorder,
+	}
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("expected 2 events, got %d", len(recorder.Events()))
+	}
+	for key, expected := range map[string]string{"tls.crt": "cert", "tls.key": "key"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("expected %q in %s, got %q", expected, key, content)
+		}
+	}
+
+	// nothing to do when the host is in sync
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("expected no new events, got %d", len(recorder.Events()))
+	}
+
+	// drift on the host is repaired
+	if err := ioutil.WriteFile(files[1].Path, []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFile([]byte("key"), files[1]); err != nil {
+		t.Error(err)
+	}
+}
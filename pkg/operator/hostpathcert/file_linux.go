@@ -0,0 +1,42 @@
+//go:build linux
+// +build linux
+
+package hostpathcert
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const selinuxXattr = "security.selinux"
+
+// setSELinuxLabel sets the SELinux context of the file the same way chcon does.
+func setSELinuxLabel(path, label string) error {
+	// the label is stored NUL terminated, like libselinux does
+	return unix.Lsetxattr(path, selinuxXattr, append([]byte(label), 0), 0)
+}
+
+// getSELinuxLabel returns the SELinux context of the file the same way ls -Z does.
+func getSELinuxLabel(path string) (string, error) {
+	size, err := unix.Lgetxattr(path, selinuxXattr, nil)
+	if err != nil {
+		return "", err
+	}
+	label := make([]byte, size)
+	size, err = unix.Lgetxattr(path, selinuxXattr, label)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(label[:size], "\x00")), nil
+}
+
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
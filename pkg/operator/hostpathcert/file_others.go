@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package hostpathcert
+
+import (
+	"fmt"
+	"os"
+)
+
+// setSELinuxLabel is not supported on this platform
+func setSELinuxLabel(path, label string) error {
+	return fmt.Errorf("setting SELinux label is not supported on this platform")
+}
+
+// getSELinuxLabel is not supported on this platform
+func getSELinuxLabel(path string) (string, error) {
+	return "", fmt.Errorf("reading SELinux label is not supported on this platform")
+}
+
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,102 @@
+package hostpathcert
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod"
+)
+
+// FileOwner describes the numeric owner of a file written to the host.
+type FileOwner struct {
+	UID int
+	GID int
+}
+
+// HostPathFile describes where and how a single key of a secret is projected onto the host filesystem.
+type HostPathFile struct {
+	// Key is the key in the secret data that holds the content of the file.
+	Key string
+	// Path is the absolute path of the file on the host.
+	Path string
+	// Mode is the permission of the file. Defaults to 0600 when unset.
+	Mode os.FileMode
+	// Owner is the owner of the file. The ownership is left untouched when unset.
+	Owner *FileOwner
+	// SELinuxLabel is the SELinux context set on the file, e.g. "system_u:object_r:container_file_t:s0".
+	// The label is left untouched when empty.
+	SELinuxLabel string
+}
+
+func (f HostPathFile) mode() os.FileMode {
+	if f.Mode == 0 {
+		return 0600
+	}
+	return f.Mode
+}
+
+// WriteFile atomically writes the content to the host path, sets its ownership, permissions and SELinux label
+// and then verifies that what landed on disk is what was meant to be written.
+func WriteFile(content []byte, file HostPathFile) error {
+	if !filepath.IsAbs(file.Path) {
+		return fmt.Errorf("path %q must be absolute", file.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+		return err
+	}
+	if err := staticpod.WriteFileAtomic(content, file.mode(), file.Path); err != nil {
+		return err
+	}
+	if file.Owner != nil {
+		if err := os.Chown(file.Path, file.Owner.UID, file.Owner.GID); err != nil {
+			return err
+		}
+	}
+	// chmod explicitly as the temporary file permissions are subject to umask
+	if err := os.Chmod(file.Path, file.mode()); err != nil {
+		return err
+	}
+	if len(file.SELinuxLabel) > 0 {
+		if err := setSELinuxLabel(file.Path, file.SELinuxLabel); err != nil {
+			return err
+		}
+	}
+	return VerifyFile(content, file)
+}
+
+// VerifyFile checks the file on the host has the expected content, permissions, ownership and SELinux label.
+func VerifyFile(content []byte, file HostPathFile) error {
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm() != file.mode().Perm() {
+		return fmt.Errorf("file %q has mode %v, expected %v", file.Path, info.Mode().Perm(), file.mode().Perm())
+	}
+	if file.Owner != nil {
+		uid, gid, ok := fileOwner(info)
+		if ok && (uid != file.Owner.UID || gid != file.Owner.GID) {
+			return fmt.Errorf("file %q is owned by %d:%d, expected %d:%d", file.Path, uid, gid, file.Owner.UID, file.Owner.GID)
+		}
+	}
+	if len(file.SELinuxLabel) > 0 {
+		label, err := getSELinuxLabel(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read the SELinux label of file %q: %v", file.Path, err)
+		}
+		if label != file.SELinuxLabel {
+			return fmt.Errorf("file %q has SELinux label %q, expected %q", file.Path, label, file.SELinuxLabel)
+		}
+	}
+	existing, err := ioutil.ReadFile(file.Path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(existing, content) {
+		return fmt.Errorf("file %q content does not match the expected content", file.Path)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package jointoken
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+const (
+	// signingKeyPrefix prefixes the secret data keys holding signing keys. The suffix is the unix creation time.
+	signingKeyPrefix = "key-"
+	signingKeyLength = 32
+)
+
+// SigningKey is an HMAC key used to sign join tokens.
+type SigningKey struct {
+	ID      string
+	Key     []byte
+	Created time.Time
+}
+
+// SigningKeys are the keys accepted for validation. Current is used to sign new tokens.
+type SigningKeys struct {
+	Current SigningKey
+	All     []SigningKey
+}
+
+// Find returns the key with the given ID, or nil.
+func (k *SigningKeys) Find(id string) *SigningKey {
+	if k == nil {
+		return nil
+	}
+	for i := range k.All {
+		if k.All[i].ID == id {
+			return &k.All[i]
+		}
+	}
+	return nil
+}
+
+// SigningKeysFromSecret reads the signing keys maintained by RotatedSigningKeySecret.
+func SigningKeysFromSecret(secret *corev1.Secret) (*SigningKeys, error) {
+	keys := &SigningKeys{}
+	for dataKey, value := range secret.Data {
+		if !strings.HasPrefix(dataKey, signingKeyPrefix) {
+			continue
+		}
+		created, err := strconv.ParseInt(strings.TrimPrefix(dataKey, signingKeyPrefix), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key %q in secret %s/%s", dataKey, secret.Namespace, secret.Name)
+		}
+		keys.All = append(keys.All, SigningKey{ID: dataKey, Key: value, Created: time.Unix(created, 0)})
+	}
+	if len(keys.All) == 0 {
+		return nil, fmt.Errorf("no signing keys in secret %s/%s", secret.Namespace, secret.Name)
+	}
+	sort.Slice(keys.All, func(i, j int) bool { return keys.All[i].Created.After(keys.All[j].Created) })
+	keys.Current = keys.All[0]
+	return keys, nil
+}
+
+// RotatedSigningKeySecret rotates the HMAC signing key of join tokens stored in a secret. A new key is created when
+// the current one is older than Refresh. Old keys are kept until TokenValidity has passed after they were replaced,
+// so that all tokens they signed expire before the key is removed.
+type RotatedSigningKeySecret struct {
+	// Namespace is the namespace of the Secret.
+	Namespace string
+	// Name is the name of the Secret.
+	Name string
+	// Refresh is the age of the current signing key at which a new one is created.
+	Refresh time.Duration
+	// TokenValidity is the maximum validity of tokens signed with the keys.
+	TokenValidity time.Duration
+
+	// Plumbing:
+	Informer      corev1informers.SecretInformer
+	Lister        corev1listers.SecretLister
+	Client        corev1client.SecretsGetter
+	EventRecorder events.Recorder
+}
+
+// EnsureSigningKeys rotates and prunes the signing keys if needed and returns the resulting keys.
+func (c RotatedSigningKeySecret) EnsureSigningKeys(ctx context.Context) (*SigningKeys, error) {
+	original, err := c.Lister.Secrets(c.Namespace).Get(c.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	secret := original.DeepCopy()
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name}}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	now := time.Now()
+	keys, _ := SigningKeysFromSecret(secret)
+	changed := false
+	if keys == nil || now.Sub(keys.Current.Created) >= c.Refresh {
+		reason := "missing signing key"
+		if keys != nil {
+			reason = fmt.Sprintf("signing key %s is past its refresh time", keys.Current.ID)
+		}
+		c.EventRecorder.Eventf("JoinTokenSigningKeyUpdateRequired", "%q in %q requires a new signing key: %s", c.Name, c.Namespace, reason)
+		key := make([]byte, signingKeyLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		secret.Data[signingKeyPrefix+strconv.FormatInt(now.Unix(), 10)] = key
+		changed = true
+		keys, err = SigningKeysFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a key is needed as long as tokens signed with it can be valid, i.e. TokenValidity after its successor was created.
+	for i := 1; i < len(keys.All); i++ {
+		if now.Sub(keys.All[i-1].Created) > c.TokenValidity {
+			delete(secret.Data, keys.All[i].ID)
+			changed = true
+		}
+	}
+
+	if changed {
+		actual, _, err := resourceapply.ApplySecret(ctx, c.Client, c.EventRecorder, secret)
+		if err != nil {
+			return nil, err
+		}
+		return SigningKeysFromSecret(actual)
+	}
+	return keys, nil
+}
+
+// NewSigningKeyRotationController returns a controller that keeps the signing key secret rotated.
+func NewSigningKeyRotationController(name string, secret RotatedSigningKeySecret, recorder events.Recorder) factory.Controller {
+	return factory.New().
+		ResyncEvery(time.Minute).
+		WithSync(func(ctx context.Context, syncCtx factory.SyncContext) error {
+			_, err := secret.EnsureSigningKeys(ctx)
+			return err
+		}).
+		WithInformers(secret.Informer.Informer()).
+		ToController(name, recorder.WithComponentSuffix("join-token-signing-key-rotation"))
+}
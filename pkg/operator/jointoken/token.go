@@ -0,0 +1,91 @@
+package jointoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed tokens and tokens with a wrong signature.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for correctly signed tokens past their expiry.
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims are the signed content of a join token.
+type Claims struct {
+	// KeyID identifies the signing key.
+	KeyID string `json:"kid"`
+	// Subject is what the token was issued for, e.g. a node or agent name. It may be empty.
+	Subject string `json:"sub,omitempty"`
+	// ExpiresAt is the unix time after which the token is rejected.
+	ExpiresAt int64 `json:"exp"`
+	// Nonce makes every token unique.
+	Nonce string `json:"nonce"`
+}
+
+// Generate returns a token for subject, signed with the current key of keys and valid for validity.
+// The token has the form "<base64url claims>.<base64url HMAC-SHA256>".
+func Generate(keys *SigningKeys, subject string, validity time.Duration, now time.Time) (string, error) {
+	if keys == nil || len(keys.Current.Key) == 0 {
+		return "", fmt.Errorf("no signing key")
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	claims := Claims{
+		KeyID:     keys.Current.ID,
+		Subject:   subject,
+		ExpiresAt: now.Add(validity).Unix(),
+		Nonce:     base64.RawURLEncoding.EncodeToString(nonce),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(sign(keys.Current.Key, encodedPayload)), nil
+}
+
+// Validate checks the signature of token against the key it names and its expiry, and returns the claims.
+// Tokens signed with any key in keys are accepted, so tokens issued before a key rotation stay valid until they expire.
+func Validate(keys *SigningKeys, token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	key := keys.Find(claims.KeyID)
+	if key == nil || !hmac.Equal(signature, sign(key.Key, parts[0])) {
+		return nil, ErrInvalidToken
+	}
+	if now.Unix() > claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return claims, nil
+}
+
+func sign(key []byte, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
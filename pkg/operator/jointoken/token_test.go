@@ -0,0 +1,90 @@
+package jointoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestGenerateAndValidate(t *testing.T) {
+	now := time.Now()
+	keys := &SigningKeys{Current: SigningKey{ID: "key-2", Key: []byte("new")}}
+	keys.All = []SigningKey{keys.Current, {ID: "key-1", Key: []byte("old")}}
+
+	token, err := Generate(keys, "node-1", time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := Validate(keys, token, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "node-1" || claims.KeyID != "key-2" {
+		t.Errorf("unexpected claims %#v", claims)
+	}
+
+	if _, err := Validate(keys, token, now.Add(2*time.Hour)); err != ErrExpiredToken {
+		t.Errorf("expected expired token, got %v", err)
+	}
+	if _, err := Validate(keys, token+"x", now); err != ErrInvalidToken {
+		t.Errorf("expected invalid token, got %v", err)
+	}
+	if _, err := Validate(&SigningKeys{All: []SigningKey{{ID: "key-2", Key: []byte("other")}}}, token, now); err != ErrInvalidToken {
+		t.Errorf("expected invalid token for wrong key, got %v", err)
+	}
+
+	oldToken, err := Generate(&SigningKeys{Current: keys.All[1]}, "node-2", time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Validate(keys, oldToken, now); err != nil {
+		t.Errorf("expected token signed with previous key to be valid, got %v", err)
+	}
+}
+
+func TestEnsureSigningKeys(t *testing.T) {
+	now := time.Now()
+	oldest := signingKeyPrefix + "1"
+	previous := signingKeyPrefix + "2"
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "join-token-signer"},
+		Data: map[string][]byte{
+			oldest:   []byte("oldest"),
+			previous: []byte("previous"),
+		},
+	}
+	client := fake.NewSimpleClientset(existing)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(existing)
+
+	c := RotatedSigningKeySecret{
+		Namespace:     "ns",
+		Name:          "join-token-signer",
+		Refresh:       time.Hour,
+		TokenValidity: time.Hour,
+		Lister:        corev1listers.NewSecretLister(indexer),
+		Client:        client.CoreV1(),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+	}
+	keys, err := c.EnsureSigningKeys(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now.Sub(keys.Current.Created) > time.Minute {
+		t.Errorf("expected a new current key, got %v", keys.Current.ID)
+	}
+	if keys.Find(previous) == nil {
+		t.Errorf("expected previous key to be kept for validation")
+	}
+	if keys.Find(oldest) != nil {
+		t.Errorf("expected oldest key to be pruned")
+	}
+}
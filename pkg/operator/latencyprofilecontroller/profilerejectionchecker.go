@@ -2,11 +2,13 @@ package latencyprofilecontroller
 
 import (
 	"fmt"
+	"strconv"
 
 	listersv1 "k8s.io/client-go/listers/core/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
 	nodeobserver "github.com/openshift/library-go/pkg/operator/configobserver/node"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 )
 
 type profileRejectRevisionChecker struct {
@@ -60,7 +62,7 @@ func (r *profileRejectRevisionChecker) checkProfileRejection(targetProfile confi
 	}
 
 	// get config map for the highest revision
-	configMap, err := r.configMapLister.Get(fmt.Sprintf("%s-%d", revisionConfigMapName, highestCurrentRevision))
+	configMap, err := r.configMapLister.Get(resourcenames.WithSuffix(revisionConfigMapName, strconv.Itoa(int(highestCurrentRevision)), resourcenames.MaxNameLength))
 	if err != nil {
 		return false, "", err
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -12,6 +13,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	nodeobserver "github.com/openshift/library-go/pkg/operator/configobserver/node"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 )
 
 const (
@@ -59,7 +61,7 @@ func (r *revisionConfigMatcher) matchProfileForActiveRevisions(profile configv1.
 			return false, revisionZeroMessage, nil
 		}
 
-		configMapNameWithRevision := fmt.Sprintf("%s-%d", revisionConfigMapName, revision)
+		configMapNameWithRevision := resourcenames.WithSuffix(revisionConfigMapName, strconv.Itoa(int(revision)), resourcenames.MaxNameLength)
 		configMap, err := r.configMapLister.Get(configMapNameWithRevision)
 		if err != nil {
 			return false, "", err
@@ -0,0 +1,200 @@
+// Package librarymetrics records the metrics of library-go to Prometheus and, if configured, to a Sink, e.g. an
+// adapter to an OpenTelemetry meter exporting via OTLP. The destinations are configured once at operator start with
+// Configure.
+//
+// The package covers the metrics library-go defines itself. Metrics mirroring upstream components, like the storage
+// migrator or the client-go event metrics, stay Prometheus-only to keep their names and semantics aligned with
+// upstream.
+package librarymetrics
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// Sink receives the library metrics. It decouples the library from a particular OpenTelemetry API version: an
+// operator implements it on top of the meter it already uses, mapping counters to counters, gauges to gauges or
+// up-down counters and histograms to histograms. Implementations must be safe for concurrent use.
+type Sink interface {
+	// AddCounter adds the non-negative value to the counter with the labels.
+	AddCounter(name, help string, labels map[string]string, value float64)
+	// SetGauge sets the gauge with the labels to the value.
+	SetGauge(name, help string, labels map[string]string, value float64)
+	// ObserveHistogram records the value in the histogram with the labels.
+	ObserveHistogram(name, help string, labels map[string]string, value float64)
+}
+
+// Options configure where the library metrics are recorded to.
+type Options struct {
+	// DisablePrometheus stops recording to the Prometheus metrics of the legacy registry, e.g. when the Sink
+	// replaces Prometheus. The metrics stay registered without any series.
+	DisablePrometheus bool
+	// Sink records the metrics in addition to Prometheus, if set.
+	Sink Sink
+}
+
+var (
+	optionsLock sync.RWMutex
+	options     Options
+)
+
+// Configure sets where the library metrics are recorded to. It is meant to be called once at operator start, before
+// the controllers start. Without it, the metrics are recorded to Prometheus only.
+func Configure(opts Options) {
+	optionsLock.Lock()
+	defer optionsLock.Unlock()
+	options = opts
+}
+
+func currentOptions() Options {
+	optionsLock.RLock()
+	defer optionsLock.RUnlock()
+	return options
+}
+
+// desc is the name, help and labels shared by all metric vectors.
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func newDesc(namespace, subsystem, name, help string, labels []string) desc {
+	return desc{
+		name:   metrics.BuildFQName(namespace, subsystem, name),
+		help:   help,
+		labels: labels,
+	}
+}
+
+func (d desc) labelMap(values []string) map[string]string {
+	labels := make(map[string]string, len(d.labels))
+	for i := range d.labels {
+		if i < len(values) {
+			labels[d.labels[i]] = values[i]
+		}
+	}
+	return labels
+}
+
+// CounterVec is a counter partitioned by labels, like metrics.CounterVec.
+type CounterVec struct {
+	desc
+	prometheus *metrics.CounterVec
+}
+
+// NewCounterVec returns a counter vector registered in the legacy registry.
+func NewCounterVec(opts *metrics.CounterOpts, labels []string) *CounterVec {
+	c := &CounterVec{
+		desc:       newDesc(opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels),
+		prometheus: metrics.NewCounterVec(opts, labels),
+	}
+	legacyregistry.MustRegister(c.prometheus)
+	return c
+}
+
+// Counter is the counter of a label set.
+type Counter struct {
+	vec    *CounterVec
+	values []string
+}
+
+// WithLabelValues returns the counter of the label values, in the order of the labels.
+func (c *CounterVec) WithLabelValues(values ...string) Counter {
+	return Counter{vec: c, values: values}
+}
+
+// Inc increments the counter by 1.
+func (c Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds the value, which must not be negative.
+func (c Counter) Add(value float64) {
+	opts := currentOptions()
+	if !opts.DisablePrometheus {
+		c.vec.prometheus.WithLabelValues(c.values...).Add(value)
+	}
+	if opts.Sink != nil {
+		opts.Sink.AddCounter(c.vec.name, c.vec.help, c.vec.labelMap(c.values), value)
+	}
+}
+
+// GaugeVec is a gauge partitioned by labels, like metrics.GaugeVec.
+type GaugeVec struct {
+	desc
+	prometheus *metrics.GaugeVec
+}
+
+// NewGaugeVec returns a gauge vector registered in the legacy registry.
+func NewGaugeVec(opts *metrics.GaugeOpts, labels []string) *GaugeVec {
+	g := &GaugeVec{
+		desc:       newDesc(opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels),
+		prometheus: metrics.NewGaugeVec(opts, labels),
+	}
+	legacyregistry.MustRegister(g.prometheus)
+	return g
+}
+
+// Gauge is the gauge of a label set.
+type Gauge struct {
+	vec    *GaugeVec
+	values []string
+}
+
+// WithLabelValues returns the gauge of the label values, in the order of the labels.
+func (g *GaugeVec) WithLabelValues(values ...string) Gauge {
+	return Gauge{vec: g, values: values}
+}
+
+// Set sets the gauge to the value.
+func (g Gauge) Set(value float64) {
+	opts := currentOptions()
+	if !opts.DisablePrometheus {
+		g.vec.prometheus.WithLabelValues(g.values...).Set(value)
+	}
+	if opts.Sink != nil {
+		opts.Sink.SetGauge(g.vec.name, g.vec.help, g.vec.labelMap(g.values), value)
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels, like metrics.HistogramVec. The buckets only apply to
+// Prometheus, the aggregation of the Sink is up to its implementation.
+type HistogramVec struct {
+	desc
+	prometheus *metrics.HistogramVec
+}
+
+// NewHistogramVec returns a histogram vector registered in the legacy registry.
+func NewHistogramVec(opts *metrics.HistogramOpts, labels []string) *HistogramVec {
+	h := &HistogramVec{
+		desc:       newDesc(opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels),
+		prometheus: metrics.NewHistogramVec(opts, labels),
+	}
+	legacyregistry.MustRegister(h.prometheus)
+	return h
+}
+
+// Histogram is the histogram of a label set.
+type Histogram struct {
+	vec    *HistogramVec
+	values []string
+}
+
+// WithLabelValues returns the histogram of the label values, in the order of the labels.
+func (h *HistogramVec) WithLabelValues(values ...string) Histogram {
+	return Histogram{vec: h, values: values}
+}
+
+// Observe records the value.
+func (h Histogram) Observe(value float64) {
+	opts := currentOptions()
+	if !opts.DisablePrometheus {
+		h.vec.prometheus.WithLabelValues(h.values...).Observe(value)
+	}
+	if opts.Sink != nil {
+		opts.Sink.ObserveHistogram(h.vec.name, h.vec.help, h.vec.labelMap(h.values), value)
+	}
+}
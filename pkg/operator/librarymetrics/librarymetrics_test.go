@@ -0,0 +1,110 @@
+package librarymetrics
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+type recorded struct {
+	kind   string
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+type fakeSink struct {
+	lock     sync.Mutex
+	recorded []recorded
+}
+
+func (s *fakeSink) record(kind, name string, labels map[string]string, value float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.recorded = append(s.recorded, recorded{kind: kind, name: name, labels: labels, value: value})
+}
+
+func (s *fakeSink) AddCounter(name, _ string, labels map[string]string, value float64) {
+	s.record("counter", name, labels, value)
+}
+
+func (s *fakeSink) SetGauge(name, _ string, labels map[string]string, value float64) {
+	s.record("gauge", name, labels, value)
+}
+
+func (s *fakeSink) ObserveHistogram(name, _ string, labels map[string]string, value float64) {
+	s.record("histogram", name, labels, value)
+}
+
+func TestCounterVec(t *testing.T) {
+	defer Configure(Options{})
+
+	counter := NewCounterVec(&k8smetrics.CounterOpts{
+		Subsystem: "librarymetrics_test",
+		Name:      "counter_total",
+		Help:      "Test counter",
+	}, []string{"controller"})
+
+	counter.WithLabelValues("a").Inc()
+	if value, err := testutil.GetCounterMetricValue(counter.prometheus.WithLabelValues("a")); err != nil || value != 1 {
+		t.Fatalf("expected Prometheus counter 1, got %v: %v", value, err)
+	}
+
+	sink := &fakeSink{}
+	Configure(Options{DisablePrometheus: true, Sink: sink})
+	counter.WithLabelValues("a").Add(2)
+	if value, err := testutil.GetCounterMetricValue(counter.prometheus.WithLabelValues("a")); err != nil || value != 1 {
+		t.Errorf("expected Prometheus counter to stay 1, got %v: %v", value, err)
+	}
+	expected := []recorded{{kind: "counter", name: "librarymetrics_test_counter_total", labels: map[string]string{"controller": "a"}, value: 2}}
+	if !reflect.DeepEqual(sink.recorded, expected) {
+		t.Errorf("expected sink records %v, got %v", expected, sink.recorded)
+	}
+}
+
+func TestGaugeVec(t *testing.T) {
+	defer Configure(Options{})
+
+	gauge := NewGaugeVec(&k8smetrics.GaugeOpts{
+		Subsystem: "librarymetrics_test",
+		Name:      "up",
+		Help:      "Test gauge",
+	}, []string{"dependency"})
+
+	sink := &fakeSink{}
+	Configure(Options{Sink: sink})
+	gauge.WithLabelValues("dns").Set(1)
+
+	if value, err := testutil.GetGaugeMetricValue(gauge.prometheus.WithLabelValues("dns")); err != nil || value != 1 {
+		t.Errorf("expected Prometheus gauge 1, got %v: %v", value, err)
+	}
+	expected := []recorded{{kind: "gauge", name: "librarymetrics_test_up", labels: map[string]string{"dependency": "dns"}, value: 1}}
+	if !reflect.DeepEqual(sink.recorded, expected) {
+		t.Errorf("expected sink records %v, got %v", expected, sink.recorded)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	defer Configure(Options{})
+
+	histogram := NewHistogramVec(&k8smetrics.HistogramOpts{
+		Subsystem: "librarymetrics_test",
+		Name:      "wait_seconds",
+		Help:      "Test histogram",
+	}, []string{"controller"})
+
+	sink := &fakeSink{}
+	Configure(Options{Sink: sink})
+	histogram.WithLabelValues("a").Observe(0.5)
+
+	if count, err := testutil.GetHistogramMetricCount(histogram.prometheus.WithLabelValues("a")); err != nil || count != 1 {
+		t.Errorf("expected 1 Prometheus observation, got %v: %v", count, err)
+	}
+	expected := []recorded{{kind: "histogram", name: "librarymetrics_test_wait_seconds", labels: map[string]string{"controller": "a"}, value: 0.5}}
+	if !reflect.DeepEqual(sink.recorded, expected) {
+		t.Errorf("expected sink records %v, got %v", expected, sink.recorded)
+	}
+}
@@ -75,3 +75,15 @@ func IsOperatorManaged(state v1.ManagementState) bool {
 	}
 	return true
 }
+
+// IsOperatorReadOnly indicates whether the operator management state asks the control loop to only observe the operand,
+// without changing it. This is the case for the Unmanaged and Removed states, unless the operator opted out of them.
+func IsOperatorReadOnly(state v1.ManagementState) bool {
+	switch state {
+	case v1.Unmanaged:
+		return !IsOperatorAlwaysManaged()
+	case v1.Removed:
+		return IsOperatorRemovable()
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package metricstls
+
+import (
+	"net/http"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/openshift/library-go/pkg/operator/endpointauth"
+)
+
+// WithSubjectAccessReview wraps handler and only lets requests through whose verified client certificate identity
+// (CN as user, O as groups) is allowed to "get" the request path as non-resource URL, e.g. "/metrics".
+// It is meant to be used behind a TLS config built with WithClientCA, making kube-rbac-proxy unnecessary.
+// The SubjectAccessReviews are created and cached by the authorizer of config, like for endpointauth.
+func WithSubjectAccessReview(handler http.Handler, config endpointauth.Config) (http.Handler, error) {
+	authz, err := config.NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	return endpointauth.WithAuthorization(handler, authz, clientCertificateUser), nil
+}
+
+func clientCertificateUser(req *http.Request) (user.Info, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	subject := req.TLS.VerifiedChains[0][0].Subject
+	return &user.DefaultInfo{Name: subject.CommonName, Groups: subject.Organization}, true
+}
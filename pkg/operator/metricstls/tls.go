@@ -0,0 +1,110 @@
+package metricstls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// TLSProfileSpec returns the spec of the given cluster TLS security profile.
+// If profile is nil or a custom profile without spec, the Intermediate TLS Profile is returned.
+func TLSProfileSpec(profile *configv1.TLSSecurityProfile) *configv1.TLSProfileSpec {
+	var profileType configv1.TLSProfileType
+	if profile == nil {
+		profileType = configv1.TLSProfileIntermediateType
+	} else {
+		profileType = profile.Type
+	}
+
+	var profileSpec *configv1.TLSProfileSpec
+	if profileType == configv1.TLSProfileCustomType {
+		if profile.Custom != nil {
+			profileSpec = &profile.Custom.TLSProfileSpec
+		}
+	} else {
+		profileSpec = configv1.TLSProfiles[profileType]
+	}
+
+	// nothing found / custom type set but no actual custom spec
+	if profileSpec == nil {
+		profileSpec = configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+	}
+	return profileSpec
+}
+
+// SecureTLSConfigForProfile returns a TLS server config with minimum version and cipher suites of the given cluster
+// TLS security profile. Ciphers unknown to Go are skipped.
+func SecureTLSConfigForProfile(profile *configv1.TLSSecurityProfile) (*tls.Config, error) {
+	spec := TLSProfileSpec(profile)
+	minVersion, err := crypto.TLSVersion(string(spec.MinTLSVersion))
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{MinVersion: minVersion}
+	for _, name := range crypto.OpenSSLToIANACipherSuites(spec.Ciphers) {
+		cipher, err := crypto.CipherSuite(name)
+		if err != nil {
+			continue
+		}
+		config.CipherSuites = append(config.CipherSuites, cipher)
+	}
+	return crypto.SecureTLSConfig(config), nil
+}
+
+// ClientCAFromConfigMap reads the client CA bundle from the "ca-bundle.crt" key of a config map, e.g. the one
+// maintained by a CertRotationController. The bundle is re-read on every handshake and only re-parsed on change,
+// so CA rotation does not require a restart.
+type ClientCAFromConfigMap struct {
+	Namespace string
+	Name      string
+	Lister    corev1listers.ConfigMapLister
+
+	lock       sync.Mutex
+	lastBundle string
+	pool       *x509.CertPool
+}
+
+// CertPool returns the current client CA pool.
+func (c *ClientCAFromConfigMap) CertPool() (*x509.CertPool, error) {
+	cm, err := c.Lister.ConfigMaps(c.Namespace).Get(c.Name)
+	if err != nil {
+		return nil, err
+	}
+	bundle := cm.Data["ca-bundle.crt"]
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.pool != nil && bundle == c.lastBundle {
+		return c.pool, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(bundle)) {
+		return nil, fmt.Errorf("no client CA certificates found in configmap %s/%s", c.Namespace, c.Name)
+	}
+	c.pool = pool
+	c.lastBundle = bundle
+	return pool, nil
+}
+
+// WithClientCA makes config require and verify client certificates against the CA pool returned by clientCA
+// at the time of the handshake.
+func WithClientCA(config *tls.Config, clientCA func() (*x509.CertPool, error)) *tls.Config {
+	ret := config.Clone()
+	ret.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := clientCA()
+		if err != nil {
+			return nil, err
+		}
+		perConnection := config.Clone()
+		perConnection.ClientCAs = pool
+		perConnection.ClientAuth = tls.RequireAndVerifyClientCert
+		return perConnection, nil
+	}
+	return ret
+}
@@ -0,0 +1,133 @@
+package metricstls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/endpointauth"
+)
+
+func TestSecureTLSConfigForProfile(t *testing.T) {
+	config, err := SecureTLSConfigForProfile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected TLS 1.2 for intermediate profile, got %v", config.MinVersion)
+	}
+
+	config, err = SecureTLSConfigForProfile(&configv1.TLSSecurityProfile{Type: configv1.TLSProfileModernType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected TLS 1.3 for modern profile, got %v", config.MinVersion)
+	}
+}
+
+func TestClientCAFromConfigMap(t *testing.T) {
+	ca, err := crypto.MakeSelfSignedCAConfig("client-ca", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPEM, _, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ca"}, Data: map[string]string{"ca-bundle.crt": string(caPEM)}})
+
+	clientCA := &ClientCAFromConfigMap{Namespace: "ns", Name: "ca", Lister: corev1listers.NewConfigMapLister(indexer)}
+	pool, err := clientCA.CertPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := clientCA.CertPool(); again != pool {
+		t.Errorf("expected cached pool for unchanged bundle")
+	}
+
+	config := WithClientCA(&tls.Config{MinVersion: tls.VersionTLS12}, clientCA.CertPool)
+	perConnection, err := config.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perConnection.ClientAuth != tls.RequireAndVerifyClientCert || perConnection.ClientCAs != pool {
+		t.Errorf("expected client certificates to be verified against the pool")
+	}
+}
+
+func TestWithSubjectAccessReview(t *testing.T) {
+	var lock sync.Mutex
+	reviews := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sar := &authorizationv1.SubjectAccessReview{}
+		if err := json.NewDecoder(req.Body).Decode(sar); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		reviews[sar.Spec.User]++
+		lock.Unlock()
+		sar.Status.Allowed = sar.Spec.User == "prometheus" && sar.Spec.NonResourceAttributes.Path == "/metrics"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sar)
+	}))
+	defer server.Close()
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := WithSubjectAccessReview(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}), endpointauth.Config{Client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		noTLS    bool
+		expected int
+	}{
+		{name: "allowed", user: "prometheus", expected: http.StatusOK},
+		{name: "allowed again", user: "prometheus", expected: http.StatusOK},
+		{name: "forbidden", user: "someone", expected: http.StatusForbidden},
+		{name: "no client cert", noTLS: true, expected: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if !test.noTLS {
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: test.user}}}}}
+			} else {
+				req.TLS = nil
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, w.Code)
+			}
+		})
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if reviews["prometheus"] != 1 {
+		t.Errorf("expected the decision for prometheus to be cached, got %d SubjectAccessReviews", reviews["prometheus"])
+	}
+}
@@ -0,0 +1,107 @@
+package monitoringtls
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const defaultCABundleKey = "ca-bundle.crt"
+
+// ScrapeTLS describes how Prometheus verifies an operand serving metrics with a certificate managed by certrotation.
+// Referencing the CA bundle config map (see certrotation.CABundleConfigMap) rather than a single CA keeps scraping working
+// across signer rotations, because the bundle keeps the previous signers until they expire.
+type ScrapeTLS struct {
+	// ServerName is the name verified against the serving certificate, see ServiceServerName.
+	ServerName string
+	// CABundleConfigMap is the name of the config map holding the CA bundle, in the namespace of the monitor.
+	CABundleConfigMap string
+	// CABundleKey is the key of the CA bundle in the config map. Defaults to ca-bundle.crt.
+	CABundleKey string
+	// ClientCertSecret, if set, is the name of a secret with the client certificate used for scraping,
+	// in the namespace of the monitor.
+	ClientCertSecret string
+	// ClientCertKey is the key of the client certificate in the secret, as configured for the certrotation
+	// controller. Defaults to tls.crt.
+	ClientCertKey string
+	// ClientKeyKey is the key of the client private key in the secret. Defaults to tls.key.
+	ClientKeyKey string
+}
+
+// ServiceServerName returns the name the service serving certificate is issued for.
+func ServiceServerName(serviceName, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", serviceName, namespace)
+}
+
+// TLSConfig returns a tlsConfig stanza for ServiceMonitor and PodMonitor endpoints that references the CA bundle config map
+// and the client certificate secret, so Prometheus always picks up the latest rotated content.
+func (s ScrapeTLS) TLSConfig() map[string]interface{} {
+	caKey := s.CABundleKey
+	if len(caKey) == 0 {
+		caKey = defaultCABundleKey
+	}
+	tlsConfig := map[string]interface{}{
+		"serverName": s.ServerName,
+		"ca": map[string]interface{}{
+			"configMap": map[string]interface{}{"name": s.CABundleConfigMap, "key": caKey},
+		},
+	}
+	if len(s.ClientCertSecret) > 0 {
+		certKey, keyKey := s.ClientCertKey, s.ClientKeyKey
+		if len(certKey) == 0 {
+			certKey = corev1.TLSCertKey
+		}
+		if len(keyKey) == 0 {
+			keyKey = corev1.TLSPrivateKeyKey
+		}
+		tlsConfig["cert"] = map[string]interface{}{
+			"secret": map[string]interface{}{"name": s.ClientCertSecret, "key": certKey},
+		}
+		tlsConfig["keySecret"] = map[string]interface{}{"name": s.ClientCertSecret, "key": keyKey}
+	}
+	return tlsConfig
+}
+
+// FileTLSConfig returns a tlsConfig stanza referencing files mounted into the Prometheus pods.
+// Empty files are omitted.
+func FileTLSConfig(serverName, caFile, certFile, keyFile string) map[string]interface{} {
+	tlsConfig := map[string]interface{}{"serverName": serverName}
+	for field, value := range map[string]string{"caFile": caFile, "certFile": certFile, "keyFile": keyFile} {
+		if len(value) > 0 {
+			tlsConfig[field] = value
+		}
+	}
+	return tlsConfig
+}
+
+// SetEndpointsTLSConfig switches all endpoints of the ServiceMonitor or PodMonitor to https and sets their tlsConfig.
+func SetEndpointsTLSConfig(monitor *unstructured.Unstructured, tlsConfig map[string]interface{}) error {
+	var field string
+	switch monitor.GetKind() {
+	case "ServiceMonitor":
+		field = "endpoints"
+	case "PodMonitor":
+		field = "podMetricsEndpoints"
+	default:
+		return fmt.Errorf("unsupported kind %q, expected ServiceMonitor or PodMonitor", monitor.GetKind())
+	}
+
+	endpoints, found, err := unstructured.NestedSlice(monitor.Object, "spec", field)
+	if err != nil {
+		return err
+	}
+	if !found || len(endpoints) == 0 {
+		return fmt.Errorf("%s %s/%s has no spec.%s", monitor.GetKind(), monitor.GetNamespace(), monitor.GetName(), field)
+	}
+	for i := range endpoints {
+		endpoint, ok := endpoints[i].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s %s/%s has invalid spec.%s[%d]", monitor.GetKind(), monitor.GetNamespace(), monitor.GetName(), field, i)
+		}
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = runtime.DeepCopyJSON(tlsConfig)
+	}
+	return unstructured.SetNestedSlice(monitor.Object, endpoints, "spec", field)
+}
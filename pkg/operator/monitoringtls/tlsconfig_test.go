@@ -0,0 +1,98 @@
+package monitoringtls
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const serviceMonitor = `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: foo
+  namespace: openshift-foo
+spec:
+  endpoints:
+  - port: metrics
+    interval: 30s
+  - port: other-metrics
+  selector:
+    matchLabels:
+      app: foo
+`
+
+func TestSetEndpointsTLSConfig(t *testing.T) {
+	monitor := resourceread.ReadUnstructuredOrDie([]byte(serviceMonitor))
+	tlsConfig := ScrapeTLS{
+		ServerName:        ServiceServerName("foo", "openshift-foo"),
+		CABundleConfigMap: "foo-ca-bundle",
+		ClientCertSecret:  "prometheus-client-cert",
+	}.TLSConfig()
+	if err := SetEndpointsTLSConfig(monitor, tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"serverName": "foo.openshift-foo.svc",
+		"ca":         map[string]interface{}{"configMap": map[string]interface{}{"name": "foo-ca-bundle", "key": "ca-bundle.crt"}},
+		"cert":       map[string]interface{}{"secret": map[string]interface{}{"name": "prometheus-client-cert", "key": "tls.crt"}},
+		"keySecret":  map[string]interface{}{"name": "prometheus-client-cert", "key": "tls.key"},
+	}
+	endpoints, _, err := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints, got %d", len(endpoints))
+	}
+	for _, endpoint := range endpoints {
+		endpoint := endpoint.(map[string]interface{})
+		if endpoint["scheme"] != "https" {
+			t.Errorf("expected https scheme, got %v", endpoint["scheme"])
+		}
+		if !reflect.DeepEqual(endpoint["tlsConfig"], expected) {
+			t.Errorf("unexpected tlsConfig: %#v", endpoint["tlsConfig"])
+		}
+	}
+	if endpoints[0].(map[string]interface{})["interval"] != "30s" {
+		t.Errorf("expected other endpoint fields to be preserved")
+	}
+}
+
+func TestTLSConfigCustomClientCertKeys(t *testing.T) {
+	tlsConfig := ScrapeTLS{
+		ServerName:        "foo",
+		CABundleConfigMap: "foo-ca-bundle",
+		ClientCertSecret:  "prometheus-client-cert",
+		ClientCertKey:     "client.crt",
+		ClientKeyKey:      "client.key",
+	}.TLSConfig()
+	if cert := tlsConfig["cert"].(map[string]interface{})["secret"].(map[string]interface{})["key"]; cert != "client.crt" {
+		t.Errorf("expected client.crt, got %v", cert)
+	}
+	if key := tlsConfig["keySecret"].(map[string]interface{})["key"]; key != "client.key" {
+		t.Errorf("expected client.key, got %v", key)
+	}
+}
+
+func TestSetEndpointsTLSConfigErrors(t *testing.T) {
+	podMonitorWithoutEndpoints := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "PodMonitor", "spec": map[string]interface{}{}}}
+	if err := SetEndpointsTLSConfig(podMonitorWithoutEndpoints, FileTLSConfig("foo", "/etc/ca.crt", "", "")); err == nil {
+		t.Errorf("expected missing endpoints to be reported")
+	}
+	service := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service"}}
+	if err := SetEndpointsTLSConfig(service, nil); err == nil {
+		t.Errorf("expected unsupported kind to be reported")
+	}
+}
+
+func TestFileTLSConfig(t *testing.T) {
+	expected := map[string]interface{}{"serverName": "foo", "caFile": "/etc/ca.crt"}
+	if actual := FileTLSConfig("foo", "/etc/ca.crt", "", ""); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
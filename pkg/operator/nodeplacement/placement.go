@@ -0,0 +1,150 @@
+package nodeplacement
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// MasterNodeRoleLabel is the label of control plane nodes.
+	MasterNodeRoleLabel = "node-role.kubernetes.io/master"
+	// InfraNodeRoleLabel is the label of infra nodes.
+	InfraNodeRoleLabel = "node-role.kubernetes.io/infra"
+)
+
+// Role is the kind of nodes an operand wants to run on.
+type Role string
+
+const (
+	// ControlPlaneRole operands run on control plane nodes, unless the control plane is external (hosted).
+	ControlPlaneRole Role = "ControlPlane"
+	// InfraRole operands run on infra nodes if the cluster declares them, on workers otherwise.
+	InfraRole Role = "Infra"
+	// WorkerRole operands run on any schedulable node.
+	WorkerRole Role = "Worker"
+)
+
+// Policy declares where an operand should be scheduled.
+type Policy struct {
+	Role Role
+
+	// NodeSelector overrides the default node selector of the role, e.g. for custom infra nodes. The infra toleration
+	// is only added for InfraRole if the selector selects on the infra node role label.
+	NodeSelector map[string]string
+	// Tolerations are added to the default tolerations of the role, e.g. for taints of custom infra nodes.
+	Tolerations []corev1.Toleration
+
+	// SpreadPodLabels, if set, adds a preferred pod anti-affinity on the hostname topology for pods with these labels,
+	// unless the cluster topology is single replica.
+	SpreadPodLabels map[string]string
+}
+
+// Placement is the computed scheduling configuration of an operand.
+type Placement struct {
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
+}
+
+// HasInfraNodes returns whether the cluster has nodes labeled with the infra node role.
+func HasInfraNodes(nodeLister corev1listers.NodeLister) (bool, error) {
+	requirement, err := labels.NewRequirement(InfraNodeRoleLabel, selection.Exists, nil)
+	if err != nil {
+		return false, err
+	}
+	nodes, err := nodeLister.List(labels.NewSelector().Add(*requirement))
+	if err != nil {
+		return false, err
+	}
+	return len(nodes) > 0, nil
+}
+
+// ComputePlacement computes the placement for the policy given the cluster infrastructure and whether the cluster has
+// infra nodes, see HasInfraNodes. infra may be nil, in which case a highly available, self-hosted cluster is assumed.
+func ComputePlacement(policy Policy, infra *configv1.Infrastructure, hasInfraNodes bool) (*Placement, error) {
+	controlPlaneTopology := configv1.HighlyAvailableTopologyMode
+	infrastructureTopology := configv1.HighlyAvailableTopologyMode
+	if infra != nil {
+		if len(infra.Status.ControlPlaneTopology) > 0 {
+			controlPlaneTopology = infra.Status.ControlPlaneTopology
+		}
+		if len(infra.Status.InfrastructureTopology) > 0 {
+			infrastructureTopology = infra.Status.InfrastructureTopology
+		}
+	}
+
+	ret := &Placement{}
+	topology := infrastructureTopology
+	switch policy.Role {
+	case ControlPlaneRole:
+		topology = controlPlaneTopology
+		if controlPlaneTopology != configv1.ExternalTopologyMode {
+			ret.NodeSelector = map[string]string{MasterNodeRoleLabel: ""}
+			ret.Tolerations = []corev1.Toleration{{Key: MasterNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+		}
+	case InfraRole:
+		infraToleration := corev1.Toleration{Key: InfraNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+		if _, selectsInfra := policy.NodeSelector[InfraNodeRoleLabel]; selectsInfra {
+			ret.Tolerations = []corev1.Toleration{infraToleration}
+		} else if len(policy.NodeSelector) == 0 && hasInfraNodes {
+			ret.NodeSelector = map[string]string{InfraNodeRoleLabel: ""}
+			ret.Tolerations = []corev1.Toleration{infraToleration}
+		}
+	case WorkerRole:
+	default:
+		return nil, fmt.Errorf("unknown node placement role %q", policy.Role)
+	}
+
+	if len(policy.NodeSelector) > 0 {
+		ret.NodeSelector = map[string]string{}
+		for k, v := range policy.NodeSelector {
+			ret.NodeSelector[k] = v
+		}
+	}
+	ret.Tolerations = append(ret.Tolerations, policy.Tolerations...)
+
+	if len(policy.SpreadPodLabels) > 0 && topology != configv1.SingleReplicaTopologyMode {
+		ret.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: policy.SpreadPodLabels},
+						TopologyKey:   corev1.LabelHostname,
+					},
+				}},
+			},
+		}
+	}
+	return ret, nil
+}
+
+// ApplyTo sets the computed node selector and affinity of the pod spec and adds the computed tolerations to those of
+// the pod spec. What the placement leaves empty keeps the value of the manifest.
+func (p *Placement) ApplyTo(spec *corev1.PodSpec) {
+	if len(p.NodeSelector) > 0 {
+		spec.NodeSelector = p.NodeSelector
+	}
+	if p.Affinity != nil {
+		spec.Affinity = p.Affinity
+	}
+	for _, toleration := range p.Tolerations {
+		found := false
+		for _, existing := range spec.Tolerations {
+			if equality.Semantic.DeepEqual(existing, toleration) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			spec.Tolerations = append(spec.Tolerations, toleration)
+		}
+	}
+}
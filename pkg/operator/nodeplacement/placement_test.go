@@ -0,0 +1,121 @@
+package nodeplacement
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func infraWithTopology(controlPlane, infrastructure configv1.TopologyMode) *configv1.Infrastructure {
+	return &configv1.Infrastructure{Status: configv1.InfrastructureStatus{ControlPlaneTopology: controlPlane, InfrastructureTopology: infrastructure}}
+}
+
+func TestComputePlacement(t *testing.T) {
+	masterToleration := corev1.Toleration{Key: MasterNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	infraToleration := corev1.Toleration{Key: InfraNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name             string
+		policy           Policy
+		infra            *configv1.Infrastructure
+		hasInfraNodes    bool
+		expectedSelector map[string]string
+		expectedTolerate []corev1.Toleration
+		expectedAffinity bool
+		expectedErr      bool
+	}{
+		{
+			name:             "control plane",
+			policy:           Policy{Role: ControlPlaneRole, SpreadPodLabels: map[string]string{"app": "a"}},
+			infra:            infraWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode),
+			expectedSelector: map[string]string{MasterNodeRoleLabel: ""},
+			expectedTolerate: []corev1.Toleration{masterToleration},
+			expectedAffinity: true,
+		},
+		{
+			name:   "external control plane",
+			policy: Policy{Role: ControlPlaneRole},
+			infra:  infraWithTopology(configv1.ExternalTopologyMode, configv1.HighlyAvailableTopologyMode),
+		},
+		{
+			name:             "single replica control plane does not spread",
+			policy:           Policy{Role: ControlPlaneRole, SpreadPodLabels: map[string]string{"app": "a"}},
+			infra:            infraWithTopology(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode),
+			expectedSelector: map[string]string{MasterNodeRoleLabel: ""},
+			expectedTolerate: []corev1.Toleration{masterToleration},
+		},
+		{
+			name:   "infra without infra nodes",
+			policy: Policy{Role: InfraRole},
+		},
+		{
+			name:             "infra with infra nodes",
+			policy:           Policy{Role: InfraRole},
+			hasInfraNodes:    true,
+			expectedSelector: map[string]string{InfraNodeRoleLabel: ""},
+			expectedTolerate: []corev1.Toleration{infraToleration},
+		},
+		{
+			name:             "infra with unrelated custom node selector",
+			policy:           Policy{Role: InfraRole, NodeSelector: map[string]string{"disktype": "ssd"}},
+			hasInfraNodes:    true,
+			expectedSelector: map[string]string{"disktype": "ssd"},
+		},
+		{
+			name:             "custom infra nodes",
+			policy:           Policy{Role: InfraRole, NodeSelector: map[string]string{InfraNodeRoleLabel: ""}},
+			expectedSelector: map[string]string{InfraNodeRoleLabel: ""},
+			expectedTolerate: []corev1.Toleration{infraToleration},
+		},
+		{
+			name:        "unknown role",
+			policy:      Policy{Role: "Other"},
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			placement, err := ComputePlacement(test.policy, test.infra, test.hasInfraNodes)
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(test.expectedSelector, placement.NodeSelector) {
+				t.Errorf("expected node selector %v, got %v", test.expectedSelector, placement.NodeSelector)
+			}
+			if !reflect.DeepEqual(test.expectedTolerate, placement.Tolerations) {
+				t.Errorf("expected tolerations %v, got %v", test.expectedTolerate, placement.Tolerations)
+			}
+			if (placement.Affinity != nil) != test.expectedAffinity {
+				t.Errorf("expected affinity %v, got %v", test.expectedAffinity, placement.Affinity)
+			}
+		})
+	}
+}
+
+func TestApplyTo(t *testing.T) {
+	manifestToleration := corev1.Toleration{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}
+	spec := &corev1.PodSpec{
+		NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+		Tolerations:  []corev1.Toleration{manifestToleration},
+	}
+	(&Placement{}).ApplyTo(spec)
+	if !reflect.DeepEqual(spec.NodeSelector, map[string]string{"kubernetes.io/os": "linux"}) || !reflect.DeepEqual(spec.Tolerations, []corev1.Toleration{manifestToleration}) {
+		t.Errorf("expected an empty placement to keep the manifest, got %v", spec)
+	}
+
+	infraToleration := corev1.Toleration{Key: InfraNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	placement := &Placement{NodeSelector: map[string]string{InfraNodeRoleLabel: ""}, Tolerations: []corev1.Toleration{infraToleration}}
+	placement.ApplyTo(spec)
+	placement.ApplyTo(spec)
+	if !reflect.DeepEqual(spec.NodeSelector, placement.NodeSelector) {
+		t.Errorf("expected node selector %v, got %v", placement.NodeSelector, spec.NodeSelector)
+	}
+	if expected := []corev1.Toleration{manifestToleration, infraToleration}; !reflect.DeepEqual(spec.Tolerations, expected) {
+		t.Errorf("expected tolerations %v, got %v", expected, spec.Tolerations)
+	}
+}
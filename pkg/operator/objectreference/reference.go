@@ -0,0 +1,189 @@
+package objectreference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var (
+	SecretsResource    = schema.GroupResource{Resource: "secrets"}
+	ConfigMapsResource = schema.GroupResource{Resource: "configmaps"}
+)
+
+// Reference points to a namespaced object, possibly in a namespace other than the one of the referencing object,
+// e.g. a "secretRef" in a config API.
+type Reference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// ParseReference parses "<namespace>/<name>" or "<name>". The latter is defaulted to defaultNamespace.
+func ParseReference(s, defaultNamespace string) (Reference, error) {
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 1:
+		return Reference{Namespace: defaultNamespace, Name: parts[0]}, nil
+	case 2:
+		return Reference{Namespace: parts[0], Name: parts[1]}, nil
+	default:
+		return Reference{}, fmt.Errorf("invalid reference %q, expected <namespace>/<name> or <name>", s)
+	}
+}
+
+func (r Reference) String() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// IsEmpty returns true if neither namespace nor name are set.
+func (r Reference) IsEmpty() bool {
+	return len(r.Namespace) == 0 && len(r.Name) == 0
+}
+
+// ResourceLocation converts the reference for use with the resourcesynccontroller.
+func (r Reference) ResourceLocation() resourcesynccontroller.ResourceLocation {
+	return resourcesynccontroller.ResourceLocation{Namespace: r.Namespace, Name: r.Name}
+}
+
+// Validate checks that the reference is complete and the namespace and name are valid.
+// If allowedNamespaces is non-empty, the referenced namespace must be one of them.
+func (r Reference) Validate(fldPath *field.Path, allowedNamespaces ...string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(r.Namespace) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Label(r.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), r.Namespace, msg))
+		}
+		if len(allowedNamespaces) > 0 && !sets.NewString(allowedNamespaces...).Has(r.Namespace) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("namespace"), r.Namespace, allowedNamespaces))
+		}
+	}
+	if len(r.Name) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(r.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), r.Name, msg))
+		}
+	}
+	return allErrs
+}
+
+// CheckAccess runs a SelfSubjectAccessReview for every verb on the referenced object and returns an error naming
+// the denied verbs. Use it as a preflight to report missing RBAC before trying to resolve the reference.
+func CheckAccess(ctx context.Context, client authorizationv1client.SelfSubjectAccessReviewsGetter, resource schema.GroupResource, ref Reference, verbs ...string) error {
+	var denied []string
+	for _, verb := range verbs {
+		review, err := client.SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: ref.Namespace,
+					Name:      ref.Name,
+					Verb:      verb,
+					Group:     resource.Group,
+					Resource:  resource.Resource,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, verb)
+		}
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("not allowed to %s %s %s", strings.Join(denied, ","), resource.String(), ref)
+	}
+	return nil
+}
+
+// Resolver resolves secret and configmap references through the informers of KubeInformersForNamespaces.
+// Every referenced namespace must be covered by the informers.
+type Resolver struct {
+	informers v1helpers.KubeInformersForNamespaces
+}
+
+func NewResolver(informers v1helpers.KubeInformersForNamespaces) *Resolver {
+	return &Resolver{informers: informers}
+}
+
+// Secret returns the referenced secret.
+func (r *Resolver) Secret(ref Reference) (*corev1.Secret, error) {
+	if !r.informers.Namespaces().Has(ref.Namespace) {
+		return nil, fmt.Errorf("secret %s: namespace %q is not watched", ref, ref.Namespace)
+	}
+	return r.informers.InformersFor(ref.Namespace).Core().V1().Secrets().Lister().Secrets(ref.Namespace).Get(ref.Name)
+}
+
+// ConfigMap returns the referenced configmap.
+func (r *Resolver) ConfigMap(ref Reference) (*corev1.ConfigMap, error) {
+	if !r.informers.Namespaces().Has(ref.Namespace) {
+		return nil, fmt.Errorf("configmap %s: namespace %q is not watched", ref, ref.Namespace)
+	}
+	return r.informers.InformersFor(ref.Namespace).Core().V1().ConfigMaps().Lister().ConfigMaps(ref.Namespace).Get(ref.Name)
+}
+
+// SecretInformers returns the secret informers of the namespaces of refs, for use with factory.WithFilteredEventsInformers
+// and ReferencesFilter to be notified about changes of the referenced secrets.
+func (r *Resolver) SecretInformers(refs ...Reference) []factory.Informer {
+	ret := []factory.Informer{}
+	for _, ns := range namespaces(refs) {
+		if r.informers.Namespaces().Has(ns) {
+			ret = append(ret, r.informers.InformersFor(ns).Core().V1().Secrets().Informer())
+		}
+	}
+	return ret
+}
+
+// ConfigMapInformers returns the configmap informers of the namespaces of refs.
+func (r *Resolver) ConfigMapInformers(refs ...Reference) []factory.Informer {
+	ret := []factory.Informer{}
+	for _, ns := range namespaces(refs) {
+		if r.informers.Namespaces().Has(ns) {
+			ret = append(ret, r.informers.InformersFor(ns).Core().V1().ConfigMaps().Informer())
+		}
+	}
+	return ret
+}
+
+// ReferencesFilter is an event filter matching only the referenced objects.
+func ReferencesFilter(refs ...Reference) factory.EventFilterFunc {
+	refSet := map[Reference]bool{}
+	for _, ref := range refs {
+		refSet[ref] = true
+	}
+	return func(obj interface{}) bool {
+		// the object might be getting deleted
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		metaObj, ok := obj.(metav1.ObjectMetaAccessor)
+		if !ok {
+			return false
+		}
+		return refSet[Reference{Namespace: metaObj.GetObjectMeta().GetNamespace(), Name: metaObj.GetObjectMeta().GetName()}]
+	}
+}
+
+func namespaces(refs []Reference) []string {
+	ret := sets.NewString()
+	for _, ref := range refs {
+		ret.Insert(ref.Namespace)
+	}
+	return ret.List()
+}
@@ -0,0 +1,91 @@
+package objectreference
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in          string
+		expected    Reference
+		expectedErr bool
+	}{
+		{in: "name", expected: Reference{Namespace: "default-ns", Name: "name"}},
+		{in: "ns/name", expected: Reference{Namespace: "ns", Name: "name"}},
+		{in: "a/b/c", expectedErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			actual, err := ParseReference(test.in, "default-ns")
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name              string
+		ref               Reference
+		allowedNamespaces []string
+		expectedErrs      int
+	}{
+		{name: "valid", ref: Reference{Namespace: "ns", Name: "name"}},
+		{name: "empty", ref: Reference{}, expectedErrs: 2},
+		{name: "invalid name", ref: Reference{Namespace: "ns", Name: "Name_"}, expectedErrs: 1},
+		{name: "not allowed namespace", ref: Reference{Namespace: "ns", Name: "name"}, allowedNamespaces: []string{"other"}, expectedErrs: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.ref.Validate(field.NewPath("secretRef"), test.allowedNamespaces...)
+			if len(errs) != test.expectedErrs {
+				t.Errorf("expected %d errors, got %v", test.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+
+	ref := Reference{Namespace: "ns", Name: "name"}
+	if err := CheckAccess(context.TODO(), client.AuthorizationV1(), SecretsResource, ref, "get"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := CheckAccess(context.TODO(), client.AuthorizationV1(), SecretsResource, ref, "get", "watch")
+	if err == nil || err.Error() != "not allowed to watch secrets ns/name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReferencesFilter(t *testing.T) {
+	filter := ReferencesFilter(Reference{Namespace: "ns", Name: "name"})
+	if !filter(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}) {
+		t.Errorf("expected referenced secret to match")
+	}
+	if filter(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "name"}}) {
+		t.Errorf("expected secret in other namespace to not match")
+	}
+	if !filter(cache.DeletedFinalStateUnknown{Key: "ns/name", Obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}}) {
+		t.Errorf("expected tombstone of referenced secret to match")
+	}
+}
@@ -0,0 +1,163 @@
+package operandnamespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// OperandNamespaceController ensures the operand namespaces exist with their labels and annotations and reconciles
+// them when they drift. With cleanup enabled, it deletes the namespaces when the operator is Removed or its CR is
+// deleted, holding a finalizer on the operator CR until the namespaces are gone.
+//
+// It produces the following conditions:
+// <name>Degraded: produced when the sync() method returns an error.
+type OperandNamespaceController struct {
+	name            string
+	namespaces      []Namespace
+	cleanup         bool
+	operatorClient  v1helpers.OperatorClientWithFinalizers
+	namespaceClient corev1client.NamespacesGetter
+	namespaceLister corev1listers.NamespaceLister
+}
+
+// NewOperandNamespaceController returns a controller for the namespaces. If cleanup is true, the namespaces are
+// deleted on ManagementState=Removed and, for removable operators, when the operator CR is deleted.
+func NewOperandNamespaceController(
+	name string,
+	namespaces []Namespace,
+	cleanup bool,
+	operatorClient v1helpers.OperatorClientWithFinalizers,
+	namespaceClient corev1client.NamespacesGetter,
+	namespaceInformer corev1informers.NamespaceInformer,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &OperandNamespaceController{
+		name:            name,
+		namespaces:      namespaces,
+		cleanup:         cleanup,
+		operatorClient:  operatorClient,
+		namespaceClient: namespaceClient,
+		namespaceLister: namespaceInformer.Lister(),
+	}
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithFilteredEventsInformers(factory.NamesFilter(names...), namespaceInformer.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		WithSyncDegradedOnError(operatorClient).
+		ToController(c.name, recorder.WithComponentSuffix(strings.ToLower(name)+"-operand-namespace-controller"))
+}
+
+func (c *OperandNamespaceController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, _, err := c.operatorClient.GetOperatorState()
+	if apierrors.IsNotFound(err) && management.IsOperatorRemovable() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the finalizer must be released in every management state, otherwise the deletion of the CR hangs
+	if c.cleanup && management.IsOperatorRemovable() {
+		meta, err := c.operatorClient.GetObjectMeta()
+		if err != nil {
+			return err
+		}
+		if meta.DeletionTimestamp != nil {
+			if spec.ManagementState == operatorv1.Managed || spec.ManagementState == operatorv1.Removed {
+				return c.syncDeleting(ctx, syncCtx)
+			}
+			// the namespaces are left alone while not managed
+			return v1helpers.RemoveFinalizer(ctx, c.operatorClient, c.name)
+		}
+	}
+
+	switch spec.ManagementState {
+	case operatorv1.Managed:
+	case operatorv1.Removed:
+		if c.cleanup {
+			return c.syncDeleting(ctx, syncCtx)
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	if c.cleanup && management.IsOperatorRemovable() {
+		if err := v1helpers.EnsureFinalizer(ctx, c.operatorClient, c.name); err != nil {
+			return err
+		}
+	}
+	return c.syncManaged(ctx, syncCtx)
+}
+
+func (c *OperandNamespaceController) syncManaged(ctx context.Context, syncCtx factory.SyncContext) error {
+	var errs []error
+	for _, ns := range c.namespaces {
+		if _, _, err := resourceapply.ApplyNamespace(ctx, c.namespaceClient, syncCtx.Recorder(), ns.toNamespace()); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %q: %v", ns.Name, err))
+		}
+	}
+	return v1helpers.NewMultiLineAggregate(errs)
+}
+
+// syncDeleting deletes the namespaces and removes the finalizer once all of them are gone.
+func (c *OperandNamespaceController) syncDeleting(ctx context.Context, syncCtx factory.SyncContext) error {
+	var errs []error
+	remaining := 0
+	for _, ns := range c.namespaces {
+		existing, err := c.namespaceLister.Get(ns.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		remaining++
+		if existing.DeletionTimestamp != nil {
+			continue
+		}
+		err = c.namespaceClient.Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("namespace %q: %v", ns.Name, err))
+			continue
+		}
+		klog.V(2).Infof("Deleted namespace %s", ns.Name)
+		syncCtx.Recorder().Eventf("NamespaceDeleted", "Deleted namespace %s", ns.Name)
+	}
+	if len(errs) > 0 {
+		return v1helpers.NewMultiLineAggregate(errs)
+	}
+	if remaining > 0 {
+		// the namespace informer requeues once the namespaces are gone
+		return nil
+	}
+
+	// All removed, remove the finalizer as the last step
+	if !management.IsOperatorRemovable() {
+		return nil
+	}
+	return v1helpers.RemoveFinalizer(ctx, c.operatorClient, c.name)
+}
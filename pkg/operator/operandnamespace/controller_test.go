@@ -0,0 +1,161 @@
+package operandnamespace
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestSync(t *testing.T) {
+	management.SetOperatorRemovable()
+	defer management.SetOperatorNotRemovable()
+
+	namespace := NewNamespace("openshift-foo").WithClusterMonitoring().WithPodSecurity("privileged").WithWorkloadPinning()
+	now := metav1.Now()
+
+	tests := []struct {
+		name              string
+		managementState   operatorv1.ManagementState
+		deletionTimestamp *metav1.Time
+		cleanup           bool
+		existing          *corev1.Namespace
+
+		expectedActions   []string
+		expectedFinalizer bool
+		verify            func(t *testing.T, actions []clienttesting.Action)
+	}{
+		{
+			name:              "create",
+			managementState:   operatorv1.Managed,
+			cleanup:           true,
+			expectedActions:   []string{"get", "create"},
+			expectedFinalizer: true,
+			verify: func(t *testing.T, actions []clienttesting.Action) {
+				created := actions[1].(clienttesting.CreateAction).GetObject().(*corev1.Namespace)
+				if created.Labels[ClusterMonitoringLabel] != "true" || created.Labels["pod-security.kubernetes.io/enforce"] != "privileged" {
+					t.Errorf("unexpected labels: %v", created.Labels)
+				}
+				if created.Annotations[WorkloadPinningAnnotation] != "management" {
+					t.Errorf("unexpected annotations: %v", created.Annotations)
+				}
+			},
+		},
+		{
+			name:            "reconcile drift",
+			managementState: operatorv1.Managed,
+			existing: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-foo", Labels: map[string]string{
+				ClusterMonitoringLabel: "false",
+				"admin":                "label",
+			}}},
+			expectedActions: []string{"get", "update"},
+			verify: func(t *testing.T, actions []clienttesting.Action) {
+				updated := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.Namespace)
+				if updated.Labels[ClusterMonitoringLabel] != "true" || updated.Labels["admin"] != "label" {
+					t.Errorf("unexpected labels: %v", updated.Labels)
+				}
+			},
+		},
+		{
+			name:            "removed without cleanup",
+			managementState: operatorv1.Removed,
+			existing:        namespace.toNamespace(),
+		},
+		{
+			name:            "removed with cleanup",
+			managementState: operatorv1.Removed,
+			cleanup:         true,
+			existing:        namespace.toNamespace(),
+			expectedActions: []string{"delete"},
+		},
+		{
+			name:              "operator deleted, namespace is gone",
+			managementState:   operatorv1.Managed,
+			deletionTimestamp: &now,
+			cleanup:           true,
+		},
+		{
+			name:              "operator deleted, namespace is terminating",
+			managementState:   operatorv1.Managed,
+			deletionTimestamp: &now,
+			cleanup:           true,
+			existing:          &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-foo", DeletionTimestamp: &now}},
+			expectedFinalizer: true,
+		},
+		{
+			name:              "operator deleted while unmanaged",
+			managementState:   operatorv1.Unmanaged,
+			deletionTimestamp: &now,
+			cleanup:           true,
+			existing:          namespace.toNamespace(),
+		},
+		{
+			name:              "operator deleted while removed",
+			managementState:   operatorv1.Removed,
+			deletionTimestamp: &now,
+			cleanup:           true,
+			existing:          namespace.toNamespace(),
+			expectedActions:   []string{"delete"},
+			expectedFinalizer: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			kubeClient := fake.NewSimpleClientset()
+			if test.existing != nil {
+				indexer.Add(test.existing)
+				kubeClient = fake.NewSimpleClientset(test.existing)
+			}
+			meta := &metav1.ObjectMeta{Name: "cluster", DeletionTimestamp: test.deletionTimestamp}
+			operatorClient := v1helpers.NewFakeOperatorClientWithObjectMeta(meta, &operatorv1.OperatorSpec{ManagementState: test.managementState}, &operatorv1.OperatorStatus{}, nil)
+			if test.deletionTimestamp != nil {
+				if err := v1helpers.EnsureFinalizer(context.TODO(), operatorClient, "OperandNamespaces"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c := &OperandNamespaceController{
+				name:            "OperandNamespaces",
+				namespaces:      []Namespace{namespace},
+				cleanup:         test.cleanup,
+				operatorClient:  operatorClient,
+				namespaceClient: kubeClient.CoreV1(),
+				namespaceLister: corev1listers.NewNamespaceLister(indexer),
+			}
+			if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+				t.Fatal(err)
+			}
+
+			actions := kubeClient.Actions()
+			if len(actions) != len(test.expectedActions) {
+				t.Fatalf("expected actions %v, got %v", test.expectedActions, actions)
+			}
+			for i, verb := range test.expectedActions {
+				if actions[i].GetVerb() != verb {
+					t.Errorf("expected action %d to be %s, got %v", i, verb, actions[i])
+				}
+			}
+			if test.verify != nil {
+				test.verify(t, actions)
+			}
+
+			actualMeta, _ := operatorClient.GetObjectMeta()
+			if hasFinalizer := len(actualMeta.Finalizers) > 0; hasFinalizer != test.expectedFinalizer {
+				t.Errorf("expected finalizer %v, got %v", test.expectedFinalizer, actualMeta.Finalizers)
+			}
+		})
+	}
+}
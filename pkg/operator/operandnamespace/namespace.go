@@ -0,0 +1,83 @@
+package operandnamespace
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterMonitoringLabel makes the cluster monitoring stack scrape the service monitors in the namespace.
+	ClusterMonitoringLabel = "openshift.io/cluster-monitoring"
+	// WorkloadPinningAnnotation allows pods in the namespace to be pinned to the management CPUs.
+	WorkloadPinningAnnotation = "workload.openshift.io/allowed"
+
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityAuditLabel   = "pod-security.kubernetes.io/audit"
+	podSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+	// podSecurityLabelSyncLabel disables the label syncer which would otherwise overwrite the pod security labels.
+	podSecurityLabelSyncLabel = "security.openshift.io/scc.podSecurityLabelSync"
+)
+
+// Namespace is an operand namespace with the labels and annotations it must have. Other labels and annotations,
+// e.g. set by an admin, are left alone.
+type Namespace struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// NewNamespace returns a namespace without labels and annotations, use the With* methods to add them.
+func NewNamespace(name string) Namespace {
+	return Namespace{Name: name, Labels: map[string]string{}, Annotations: map[string]string{}}
+}
+
+// WithClusterMonitoring labels the namespace for the cluster monitoring stack.
+func (n Namespace) WithClusterMonitoring() Namespace {
+	return n.WithLabel(ClusterMonitoringLabel, "true")
+}
+
+// WithPodSecurity enforces, audits and warns with the pod security admission level, e.g. privileged or restricted,
+// and disables the label syncer for the namespace.
+func (n Namespace) WithPodSecurity(level string) Namespace {
+	return n.WithLabel(podSecurityEnforceLabel, level).
+		WithLabel(podSecurityAuditLabel, level).
+		WithLabel(podSecurityWarnLabel, level).
+		WithLabel(podSecurityLabelSyncLabel, "false")
+}
+
+// WithWorkloadPinning allows pinning the pods of the namespace to the management CPUs.
+func (n Namespace) WithWorkloadPinning() Namespace {
+	return n.WithAnnotation(WorkloadPinningAnnotation, "management")
+}
+
+// WithLabel adds a label. A key with "-" suffix removes the label from the namespace.
+func (n Namespace) WithLabel(key, value string) Namespace {
+	n.Labels = copyWith(n.Labels, key, value)
+	return n
+}
+
+// WithAnnotation adds an annotation. A key with "-" suffix removes the annotation from the namespace.
+func (n Namespace) WithAnnotation(key, value string) Namespace {
+	n.Annotations = copyWith(n.Annotations, key, value)
+	return n
+}
+
+func (n Namespace) toNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        n.Name,
+			Labels:      n.Labels,
+			Annotations: n.Annotations,
+		},
+	}
+}
+
+// copyWith returns a copy of m with the key set, so namespaces derived from the same base do not share maps.
+func copyWith(m map[string]string, key, value string) map[string]string {
+	ret := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		ret[k] = v
+	}
+	ret[key] = value
+	return ret
+}
@@ -0,0 +1,151 @@
+// Package pkiprobe checks that the PKI issued by the operator actually works: after every rotation of a client
+// certificate or CA bundle, a short-lived job connects to the operand endpoint with them and the result is reported in
+// an operator condition.
+package pkiprobe
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	batchv1client "k8s.io/client-go/kubernetes/typed/batch/v1"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/reasons"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// PKIProbeController runs a probe job for the current client certificate and CA bundle of the endpoint and reports
+// its result in the <Name>PKIProbeDegraded condition. The condition keeps its state while a job runs. Jobs of
+// previous rotations are deleted.
+type PKIProbeController struct {
+	endpoint       Endpoint
+	operatorClient v1helpers.OperatorClient
+	jobsClient     batchv1client.JobsGetter
+
+	secretLister    corev1listers.SecretLister
+	configMapLister corev1listers.ConfigMapLister
+	jobLister       batchv1listers.JobLister
+}
+
+// NewPKIProbeController returns a controller probing the endpoint. The informers must be of the namespace of the
+// endpoint.
+func NewPKIProbeController(
+	endpoint Endpoint,
+	operatorClient v1helpers.OperatorClient,
+	jobsClient batchv1client.JobsGetter,
+	kubeInformersForNamespace informers.SharedInformerFactory,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &PKIProbeController{
+		endpoint:        endpoint,
+		operatorClient:  operatorClient,
+		jobsClient:      jobsClient,
+		secretLister:    kubeInformersForNamespace.Core().V1().Secrets().Lister(),
+		configMapLister: kubeInformersForNamespace.Core().V1().ConfigMaps().Lister(),
+		jobLister:       kubeInformersForNamespace.Batch().V1().Jobs().Lister(),
+	}
+	return factory.New().
+		WithSync(c.sync).
+		WithInformers(
+			kubeInformersForNamespace.Core().V1().Secrets().Informer(),
+			kubeInformersForNamespace.Core().V1().ConfigMaps().Informer(),
+			kubeInformersForNamespace.Batch().V1().Jobs().Informer(),
+		).
+		ToController(endpoint.Name+"PKIProbeController", recorder.WithComponentSuffix("pki-probe-controller"))
+}
+
+func (c *PKIProbeController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	secret, err := c.secretLister.Secrets(c.endpoint.Namespace).Get(c.endpoint.ClientCertSecret)
+	if apierrors.IsNotFound(err) {
+		// nothing to probe before the client certificate is issued
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	configMap, err := c.configMapLister.ConfigMaps(c.endpoint.Namespace).Get(c.endpoint.CABundleConfigMap)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pkiHash := PKIHash(secret, configMap)
+
+	jobs, err := c.jobLister.Jobs(c.endpoint.Namespace).List(labels.SelectorFromSet(labels.Set{ProbeLabel: toDNSLabel(c.endpoint.Name)}))
+	if err != nil {
+		return err
+	}
+	var current *batchv1.Job
+	for _, job := range jobs {
+		if job.Annotations[PKIHashAnnotation] == pkiHash {
+			current = job
+			continue
+		}
+		// the job of a previous rotation, its result is no longer relevant
+		if err := c.deleteJob(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	if current == nil {
+		job := c.endpoint.NewJob(pkiHash)
+		if _, err := c.jobsClient.Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		syncCtx.Recorder().Eventf("PKIProbeJobCreated", "Created job %s/%s probing %s with the current client certificate and CA bundle", job.Namespace, job.Name, c.endpoint.URL)
+		return nil
+	}
+
+	condition, finished := probeCondition(c.endpoint, current)
+	if !finished {
+		return nil
+	}
+	_, _, err = v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition))
+	return err
+}
+
+func (c *PKIProbeController) deleteJob(ctx context.Context, job *batchv1.Job) error {
+	propagation := metav1.DeletePropagationBackground
+	err := c.jobsClient.Jobs(job.Namespace).Delete(ctx, job.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// probeCondition maps the status of the job to the condition of the probe, false if the job did not finish yet.
+func probeCondition(endpoint Endpoint, job *batchv1.Job) (operatorv1.OperatorCondition, bool) {
+	for _, jobCondition := range job.Status.Conditions {
+		if jobCondition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch jobCondition.Type {
+		case batchv1.JobComplete:
+			return reasons.NewCondition(endpoint.degradedConditionType(), operatorv1.ConditionFalse, reasons.AsExpected,
+				"Job %s succeeded to connect to %s with the current client certificate and CA bundle.", job.Name, endpoint.URL), true
+		case batchv1.JobFailed:
+			return reasons.NewCondition(endpoint.degradedConditionType(), operatorv1.ConditionTrue, reasons.PKIProbeFailed,
+				"Job %s failed to connect to %s with the current client certificate and CA bundle: %s. See the logs of the job in namespace %s for details.",
+				job.Name, endpoint.URL, jobFailureMessage(jobCondition), job.Namespace), true
+		}
+	}
+	return operatorv1.OperatorCondition{}, false
+}
+
+func jobFailureMessage(jobCondition batchv1.JobCondition) string {
+	if len(jobCondition.Message) > 0 {
+		return fmt.Sprintf("%s: %s", jobCondition.Reason, jobCondition.Message)
+	}
+	return jobCondition.Reason
+}
@@ -0,0 +1,159 @@
+package pkiprobe
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var testEndpoint = Endpoint{
+	Name:              "EtcdClient",
+	Namespace:         "openshift-etcd-operator",
+	URL:               "https://etcd.openshift-etcd.svc:2379/health",
+	Image:             "quay.io/openshift/cli:latest",
+	ClientCertSecret:  "etcd-client",
+	CABundleConfigMap: "etcd-ca-bundle",
+}
+
+func TestNewJob(t *testing.T) {
+	job := testEndpoint.NewJob("0123456789")
+
+	if job.Name != "etcdclient-pki-probe-0123456789" || job.Namespace != testEndpoint.Namespace {
+		t.Errorf("unexpected job %s/%s", job.Namespace, job.Name)
+	}
+	if job.Labels[ProbeLabel] != "etcdclient" || job.Annotations[PKIHashAnnotation] != "0123456789" {
+		t.Errorf("unexpected metadata %v %v", job.Labels, job.Annotations)
+	}
+	if *job.Spec.BackoffLimit != 0 || *job.Spec.ActiveDeadlineSeconds != defaultActiveDeadlineSeconds || job.Spec.TTLSecondsAfterFinished != nil {
+		t.Errorf("expected a short-lived job without retries that is kept after it finished, got %#v", job.Spec)
+	}
+	command := strings.Join(job.Spec.Template.Spec.Containers[0].Command, " ")
+	for _, expected := range []string{
+		"--cacert /etc/pki-probe/ca/ca-bundle.crt",
+		"--cert /etc/pki-probe/client/tls.crt",
+		"--key /etc/pki-probe/client/tls.key",
+		testEndpoint.URL,
+	} {
+		if !strings.Contains(command, expected) {
+			t.Errorf("expected command %q to contain %q", command, expected)
+		}
+	}
+	volumes := job.Spec.Template.Spec.Volumes
+	if volumes[0].Secret.SecretName != "etcd-client" || volumes[1].ConfigMap.Name != "etcd-ca-bundle" {
+		t.Errorf("unexpected volumes %#v", volumes)
+	}
+}
+
+func TestSync(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testEndpoint.Namespace, Name: testEndpoint.ClientCertSecret},
+		Data:       map[string][]byte{"tls.crt": []byte("cert-1"), "tls.key": []byte("key-1")},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testEndpoint.Namespace, Name: testEndpoint.CABundleConfigMap},
+		Data:       map[string]string{"ca-bundle.crt": "ca-1"},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	kubeClient := kubefake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	c := &PKIProbeController{
+		endpoint:        testEndpoint,
+		operatorClient:  operatorClient,
+		jobsClient:      kubeClient.BatchV1(),
+		secretLister:    corev1listers.NewSecretLister(indexer),
+		configMapLister: corev1listers.NewConfigMapLister(indexer),
+		jobLister:       batchv1listers.NewJobLister(indexer),
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	sync := func() {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs := func() []batchv1.Job {
+		t.Helper()
+		list, err := kubeClient.BatchV1().Jobs(testEndpoint.Namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list.Items
+	}
+	finishJob := func(conditionType batchv1.JobConditionType) {
+		t.Helper()
+		job := jobs()[0]
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"}}
+		if err := indexer.Add(&job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	degraded := func() *operatorv1.OperatorCondition {
+		_, status, _, _ := operatorClient.GetOperatorState()
+		return v1helpers.FindOperatorCondition(status.Conditions, "EtcdClientPKIProbeDegraded")
+	}
+
+	// nothing to probe before the client certificate is issued
+	sync()
+	if len(jobs()) != 0 {
+		t.Fatalf("expected no job, got %v", jobs())
+	}
+
+	indexer.Add(secret)
+	indexer.Add(configMap)
+	sync()
+	if len(jobs()) != 1 {
+		t.Fatalf("expected a probe job, got %v", jobs())
+	}
+	firstJob := jobs()[0].Name
+
+	// the condition is unchanged while the job runs
+	indexer.Add(&jobs()[0])
+	sync()
+	if condition := degraded(); condition != nil {
+		t.Errorf("expected no condition while the job runs, got %v", condition)
+	}
+
+	finishJob(batchv1.JobFailed)
+	sync()
+	if condition := degraded(); condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "PKIProbeFailed" ||
+		!strings.Contains(condition.Message, "BackoffLimitExceeded") {
+		t.Errorf("expected a failed probe to be degraded, got %v", condition)
+	}
+
+	// a rotation probes again and deletes the job of the previous rotation
+	rotated := secret.DeepCopy()
+	rotated.Data["tls.crt"] = []byte("cert-2")
+	indexer.Update(rotated)
+	sync()
+	if actual := jobs(); len(actual) != 1 || actual[0].Name == firstJob {
+		t.Fatalf("expected only a new probe job, got %v", actual)
+	}
+	indexer.Delete(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: testEndpoint.Namespace, Name: firstJob}})
+
+	finishJob(batchv1.JobComplete)
+	sync()
+	if condition := degraded(); condition == nil || condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected a successful probe to clear the condition, got %v", condition)
+	}
+
+	// the finished job records the probe, the same PKI is not probed again
+	secondJob := jobs()[0].Name
+	sync()
+	if actual := jobs(); len(actual) != 1 || actual[0].Name != secondJob {
+		t.Errorf("expected the finished job to be kept, got %v", actual)
+	}
+}
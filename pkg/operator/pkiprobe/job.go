@@ -0,0 +1,194 @@
+package pkiprobe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// ProbeLabel marks the jobs of a probe, the value is the name of the probe.
+	ProbeLabel = "pkiprobe.openshift.io/probe"
+	// PKIHashAnnotation is the hash of the client certificate and CA bundle a job probes with.
+	PKIHashAnnotation = "pkiprobe.openshift.io/pki-hash"
+
+	defaultCABundleKey           = "ca-bundle.crt"
+	defaultActiveDeadlineSeconds = 120
+
+	clientCertMountPath = "/etc/pki-probe/client"
+	caBundleMountPath   = "/etc/pki-probe/ca"
+)
+
+// Endpoint is an operand endpoint that must accept the rotated client certificate and serve a certificate trusted by
+// the CA bundle.
+type Endpoint struct {
+	// Name is the CamelCase name of the probe, e.g. EtcdClient. The probe reports the <Name>PKIProbeDegraded
+	// condition and names its jobs after it.
+	Name string
+	// Namespace is the namespace of the jobs, the client certificate secret and the CA bundle config map.
+	Namespace string
+	// URL is the https URL the job requests, e.g. https://etcd.openshift-etcd.svc:2379/health.
+	URL string
+	// Image is the image of the job. It must contain curl.
+	Image string
+
+	// ClientCertSecret is the name of the secret with the client certificate, e.g. the secret of a
+	// certrotation.RotatedSelfSignedCertKeySecret.
+	ClientCertSecret string
+	// ClientCertKey is the key of the client certificate in the secret. Defaults to tls.crt.
+	ClientCertKey string
+	// ClientKeyKey is the key of the client private key in the secret. Defaults to tls.key.
+	ClientKeyKey string
+	// CABundleConfigMap is the name of the config map with the CA bundle verifying the serving certificate, e.g. the
+	// config map of a certrotation.CABundleConfigMap.
+	CABundleConfigMap string
+	// CABundleKey is the key of the CA bundle in the config map. Defaults to ca-bundle.crt.
+	CABundleKey string
+
+	// ServiceAccountName is the service account of the job, the default service account if not set.
+	ServiceAccountName string
+	// ActiveDeadlineSeconds bounds the run time of the job. Defaults to 120 seconds.
+	ActiveDeadlineSeconds int64
+}
+
+func (e Endpoint) degradedConditionType() string {
+	return e.Name + "PKIProbeDegraded"
+}
+
+func (e Endpoint) clientCertKey() string {
+	if len(e.ClientCertKey) == 0 {
+		return corev1.TLSCertKey
+	}
+	return e.ClientCertKey
+}
+
+func (e Endpoint) clientKeyKey() string {
+	if len(e.ClientKeyKey) == 0 {
+		return corev1.TLSPrivateKeyKey
+	}
+	return e.ClientKeyKey
+}
+
+func (e Endpoint) caBundleKey() string {
+	if len(e.CABundleKey) == 0 {
+		return defaultCABundleKey
+	}
+	return e.CABundleKey
+}
+
+// PKIHash returns the hash identifying the client certificate and the CA bundle a probe job runs with. A rotation of
+// either changes the hash.
+func PKIHash(clientCertSecret *corev1.Secret, caBundleConfigMap *corev1.ConfigMap) string {
+	hasher := sha256.New()
+	writeMap := func(m map[string][]byte) {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(hasher, "%s=%d:", key, len(m[key]))
+			hasher.Write(m[key])
+		}
+	}
+	writeMap(clientCertSecret.Data)
+	caData := map[string][]byte{}
+	for key, value := range caBundleConfigMap.Data {
+		caData[key] = []byte(value)
+	}
+	writeMap(caData)
+	return hex.EncodeToString(hasher.Sum(nil))[:10]
+}
+
+// JobName returns the name of the probe job for the PKI hash.
+func (e Endpoint) JobName(pkiHash string) string {
+	return fmt.Sprintf("%s-pki-probe-%s", toDNSLabel(e.Name), pkiHash)
+}
+
+// NewJob returns a short-lived job requesting the URL of the endpoint with the client certificate, verifying the
+// serving certificate with the CA bundle. The job succeeds if the endpoint accepts the request with a 2xx status.
+// It is not retried. The finished job is kept as the record of the probe for the PKI hash, so that it is not probed
+// again, until the PKIProbeController deletes it after the next rotation.
+func (e Endpoint) NewJob(pkiHash string) *batchv1.Job {
+	activeDeadlineSeconds := e.ActiveDeadlineSeconds
+	if activeDeadlineSeconds <= 0 {
+		activeDeadlineSeconds = defaultActiveDeadlineSeconds
+	}
+	labels := map[string]string{ProbeLabel: toDNSLabel(e.Name)}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   e.Namespace,
+			Name:        e.JobName(pkiHash),
+			Labels:      labels,
+			Annotations: map[string]string{PKIHashAnnotation: pkiHash},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32(0),
+			ActiveDeadlineSeconds: pointer.Int64(activeDeadlineSeconds),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: e.ServiceAccountName,
+					Containers: []corev1.Container{{
+						Name:  "pki-probe",
+						Image: e.Image,
+						Command: []string{
+							"curl", "--fail", "--silent", "--show-error", "--output", "/dev/null",
+							"--max-time", fmt.Sprintf("%d", activeDeadlineSeconds/2),
+							"--cacert", caBundleMountPath + "/" + e.caBundleKey(),
+							"--cert", clientCertMountPath + "/" + e.clientCertKey(),
+							"--key", clientCertMountPath + "/" + e.clientKeyKey(),
+							e.URL,
+						},
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "client-cert", MountPath: clientCertMountPath, ReadOnly: true},
+							{Name: "ca-bundle", MountPath: caBundleMountPath, ReadOnly: true},
+						},
+					}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "client-cert",
+							VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+								SecretName: e.ClientCertSecret,
+								Items: []corev1.KeyToPath{
+									{Key: e.clientCertKey(), Path: e.clientCertKey()},
+									{Key: e.clientKeyKey(), Path: e.clientKeyKey()},
+								},
+							}},
+						},
+						{
+							Name: "ca-bundle",
+							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: e.CABundleConfigMap},
+								Items:                []corev1.KeyToPath{{Key: e.caBundleKey(), Path: e.caBundleKey()}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// toDNSLabel converts the CamelCase name to a lower case DNS label, e.g. etcdclient for EtcdClient.
+func toDNSLabel(name string) string {
+	label := make([]rune, 0, len(name))
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			label = append(label, r-'A'+'a')
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			label = append(label, r)
+		}
+	}
+	return string(label)
+}
@@ -0,0 +1,115 @@
+package podtemplatemutator
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Well known orders of mutators. Mutators with the same order run sorted by name.
+const (
+	// OrderInitContainers is for mutators adding init containers, e.g. a FIPS self check.
+	OrderInitContainers = 100
+	// OrderSidecars is for mutators adding sidecar containers, e.g. kube-rbac-proxy or the konnectivity agent.
+	OrderSidecars = 200
+	// OrderFinal is for mutators which must see all containers, e.g. to set env vars or resources on all of them.
+	OrderFinal = 1000
+)
+
+// Mutator modifies the pod templates of operands, e.g. to inject sidecar containers. Mutators must be idempotent,
+// because they are applied to the pod template on every sync.
+type Mutator struct {
+	// Name identifies the mutator in errors. Names must be unique.
+	Name string
+	// Order decides when the mutator runs, mutators with lower orders run first.
+	Order int
+	// Mutate modifies the pod template.
+	Mutate func(template *corev1.PodTemplateSpec) error
+}
+
+// Mutators are applied in the order of their Order and name, independently of the order they are listed in. This
+// makes the result stable when consumers register mutators from different places.
+type Mutators []Mutator
+
+// ApplyTo runs the mutators on the pod template. It fails on the first failing mutator, and for duplicate names before
+// any mutator runs.
+func (m Mutators) ApplyTo(template *corev1.PodTemplateSpec) error {
+	names := sets.NewString()
+	for _, mutator := range m {
+		if names.Has(mutator.Name) {
+			return fmt.Errorf("duplicate pod template mutator %q", mutator.Name)
+		}
+		names.Insert(mutator.Name)
+	}
+
+	sorted := make(Mutators, len(m))
+	copy(sorted, m)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Order != sorted[j].Order {
+			return sorted[i].Order < sorted[j].Order
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, mutator := range sorted {
+		if err := mutator.Mutate(template); err != nil {
+			return fmt.Errorf("pod template mutator %q failed: %w", mutator.Name, err)
+		}
+	}
+	return nil
+}
+
+// AddContainer returns a mutator adding the sidecar container, replacing a container of the same name.
+func AddContainer(name string, container corev1.Container) Mutator {
+	return Mutator{
+		Name:  name,
+		Order: OrderSidecars,
+		Mutate: func(template *corev1.PodTemplateSpec) error {
+			template.Spec.Containers = setContainer(template.Spec.Containers, container)
+			return nil
+		},
+	}
+}
+
+// AddInitContainer returns a mutator adding the init container, replacing an init container of the same name.
+// Init containers added by mutators run after the init containers of the manifest.
+func AddInitContainer(name string, container corev1.Container) Mutator {
+	return Mutator{
+		Name:  name,
+		Order: OrderInitContainers,
+		Mutate: func(template *corev1.PodTemplateSpec) error {
+			template.Spec.InitContainers = setContainer(template.Spec.InitContainers, container)
+			return nil
+		},
+	}
+}
+
+// AddVolume returns a mutator adding the volume, e.g. for the secrets of a sidecar, replacing a volume of the same name.
+func AddVolume(name string, volume corev1.Volume) Mutator {
+	return Mutator{
+		Name:  name,
+		Order: OrderSidecars,
+		Mutate: func(template *corev1.PodTemplateSpec) error {
+			for i := range template.Spec.Volumes {
+				if template.Spec.Volumes[i].Name == volume.Name {
+					template.Spec.Volumes[i] = *volume.DeepCopy()
+					return nil
+				}
+			}
+			template.Spec.Volumes = append(template.Spec.Volumes, *volume.DeepCopy())
+			return nil
+		},
+	}
+}
+
+func setContainer(containers []corev1.Container, container corev1.Container) []corev1.Container {
+	for i := range containers {
+		if containers[i].Name == container.Name {
+			containers[i] = *container.DeepCopy()
+			return containers
+		}
+	}
+	return append(containers, *container.DeepCopy())
+}
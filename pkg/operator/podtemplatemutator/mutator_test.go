@@ -0,0 +1,80 @@
+package podtemplatemutator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMutatorsApplyTo(t *testing.T) {
+	var calls []string
+	recording := func(name string, order int) Mutator {
+		return Mutator{Name: name, Order: order, Mutate: func(*corev1.PodTemplateSpec) error {
+			calls = append(calls, name)
+			return nil
+		}}
+	}
+
+	mutators := Mutators{
+		recording("z-final", OrderFinal),
+		recording("b-sidecar", OrderSidecars),
+		recording("a-sidecar", OrderSidecars),
+		recording("init", OrderInitContainers),
+	}
+	if err := mutators.ApplyTo(&corev1.PodTemplateSpec{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"init", "a-sidecar", "b-sidecar", "z-final"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected order %v, got %v", expected, calls)
+	}
+	if mutators[0].Name != "z-final" {
+		t.Errorf("expected the mutators not to be reordered in place")
+	}
+
+	if err := (Mutators{recording("a", 1), recording("a", 1)}).ApplyTo(&corev1.PodTemplateSpec{}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected duplicate error, got %v", err)
+	}
+	if err := (Mutators{recording("a", 1), recording("b", 2), recording("a", 3)}).ApplyTo(&corev1.PodTemplateSpec{}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected duplicate error for mutators of different orders, got %v", err)
+	}
+
+	failing := Mutator{Name: "failing", Mutate: func(*corev1.PodTemplateSpec) error { return errors.New("boom") }}
+	if err := (Mutators{failing}).ApplyTo(&corev1.PodTemplateSpec{}); err == nil || !strings.Contains(err.Error(), `"failing"`) {
+		t.Errorf("expected error naming the mutator, got %v", err)
+	}
+}
+
+func TestAddContainers(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "operand"}, {Name: "kube-rbac-proxy", Image: "old"}},
+	}}
+	mutators := Mutators{
+		AddContainer("kube-rbac-proxy", corev1.Container{Name: "kube-rbac-proxy", Image: "new"}),
+		AddContainer("konnectivity", corev1.Container{Name: "konnectivity-agent", Image: "agent"}),
+		AddInitContainer("fips", corev1.Container{Name: "fips-check", Image: "fips"}),
+		AddVolume("proxy-tls", corev1.Volume{Name: "proxy-tls"}),
+	}
+
+	// twice, to check mutators are idempotent
+	for i := 0; i < 2; i++ {
+		if err := mutators.ApplyTo(template); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "fips-check", Image: "fips"}},
+		Containers: []corev1.Container{
+			{Name: "operand"},
+			{Name: "kube-rbac-proxy", Image: "new"},
+			{Name: "konnectivity-agent", Image: "agent"},
+		},
+		Volumes: []corev1.Volume{{Name: "proxy-tls"}},
+	}
+	if !reflect.DeepEqual(template.Spec, expected) {
+		t.Errorf("unexpected pod spec %#v", template.Spec)
+	}
+}
@@ -0,0 +1,131 @@
+package reasons
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// Reason is a stable, UpperCamelCase reason of conditions and events. Dashboards and alerts key off reasons, so
+// they must not change once released.
+type Reason string
+
+// Standard reasons of the library controllers.
+const (
+	AsExpected               Reason = "AsExpected"
+	SyncError                Reason = "SyncError"
+	Unmanaged                Reason = "Unmanaged"
+	Removed                  Reason = "Removed"
+	Paused                   Reason = "Paused"
+	PreconditionNotReady     Reason = "PreconditionNotReady"
+	RotationError            Reason = "RotationError"
+	AwaitingCASync           Reason = "AwaitingCASync"
+	ConfigMergeError         Reason = "ConfigMergeError"
+	CapabilityDisabled       Reason = "CapabilityDisabled"
+	CapabilitiesEnabled      Reason = "CapabilitiesEnabled"
+	RestartingForTrustChange Reason = "RestartingForTrustChange"
+	PKIProbeFailed           Reason = "PKIProbeFailed"
+	UnusedCertificates       Reason = "UnusedCertificates"
+	AllCertificatesUsed      Reason = "AllCertificatesUsed"
+)
+
+// reasonRegexp accepts UpperCamelCase reasons with letters and digits only, a subset of the reasons metav1.Condition
+// accepts.
+var reasonRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// Validate returns an error if the reason is not UpperCamelCase.
+func Validate(reason string) error {
+	if !reasonRegexp.MatchString(reason) {
+		return fmt.Errorf("reason %q must be UpperCamelCase and contain letters and digits only", reason)
+	}
+	return nil
+}
+
+// Catalog documents the reasons of an operator, so that they can be listed e.g. for dashboards.
+type Catalog struct {
+	lock     sync.RWMutex
+	reasons  map[Reason]string
+	runbooks map[Reason]string
+}
+
+// DefaultCatalog contains the standard reasons. Operators register their own reasons in it.
+var DefaultCatalog = NewCatalog()
+
+func init() {
+	DefaultCatalog.MustRegister(AsExpected, "The condition is in its expected state.")
+	DefaultCatalog.MustRegister(SyncError, "The sync of the controller failed.")
+	DefaultCatalog.MustRegister(Unmanaged, "The operator is unmanaged, the controller does not reconcile.")
+	DefaultCatalog.MustRegister(Removed, "The operand is removed.")
+	DefaultCatalog.MustRegister(Paused, "The reconciliation is paused by an annotation.")
+	DefaultCatalog.MustRegister(PreconditionNotReady, "A precondition of the controller is not met yet.")
+	DefaultCatalog.MustRegister(RotationError, "The rotation of a certificate failed.")
+	DefaultCatalog.MustRegister(AwaitingCASync, "A new CA is not yet trusted everywhere, the rotation waits for it.")
+	DefaultCatalog.MustRegister(ConfigMergeError, "The observed config cannot be merged with the config overrides.")
+	DefaultCatalog.MustRegister(CapabilityDisabled, "A cluster capability the controller requires is disabled.")
+	DefaultCatalog.MustRegister(CapabilitiesEnabled, "All cluster capabilities the controller requires are enabled.")
+	DefaultCatalog.MustRegister(RestartingForTrustChange, "Operand pods restart one by one to stop trusting a removed CA.")
+	DefaultCatalog.MustRegister(PKIProbeFailed, "An operand endpoint does not accept the rotated client certificate or is not trusted by the CA bundle.")
+	DefaultCatalog.MustRegister(UnusedCertificates, "Rotated target certificates are not used by any pod.")
+	DefaultCatalog.MustRegister(AllCertificatesUsed, "All rotated target certificates are used by a pod.")
+
+	DefaultCatalog.MustRegisterRunbook(UnusedCertificates, "https://github.com/openshift/library-go/blob/master/pkg/operator/certrotation/runbooks/UnusedCertificates.md")
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{reasons: map[Reason]string{}, runbooks: map[Reason]string{}}
+}
+
+// Register adds a reason with its description. Reasons must be valid and registered only once.
+func (c *Catalog) Register(reason Reason, description string) error {
+	if err := Validate(string(reason)); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.reasons[reason]; ok {
+		return fmt.Errorf("reason %q is already registered", reason)
+	}
+	c.reasons[reason] = description
+	return nil
+}
+
+// MustRegister is like Register, but panics on error.
+func (c *Catalog) MustRegister(reason Reason, description string) Reason {
+	if err := c.Register(reason, description); err != nil {
+		panic(err)
+	}
+	return reason
+}
+
+// Description returns the description of the reason, false if it is not registered.
+func (c *Catalog) Description(reason Reason) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	description, ok := c.reasons[reason]
+	return description, ok
+}
+
+// List returns the registered reasons, sorted.
+func (c *Catalog) List() []Reason {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	ret := make([]Reason, 0, len(c.reasons))
+	for reason := range c.reasons {
+		ret = append(ret, reason)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+// NewCondition returns an operator condition with the reason and the formatted message.
+func NewCondition(conditionType string, status operatorv1.ConditionStatus, reason Reason, messageFormat string, args ...interface{}) operatorv1.OperatorCondition {
+	return operatorv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  string(reason),
+		Message: fmt.Sprintf(messageFormat, args...),
+	}
+}
@@ -0,0 +1,46 @@
+package reasons
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, valid := range []string{"AsExpected", "RotationError", "Degraded2"} {
+		if err := Validate(valid); err != nil {
+			t.Errorf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "asExpected", "Rotation_Error", "Rotation Error", "Rotation::Error"} {
+		if err := Validate(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	for _, reason := range DefaultCatalog.List() {
+		if err := Validate(string(reason)); err != nil {
+			t.Errorf("default catalog: %v", err)
+		}
+		if description, _ := DefaultCatalog.Description(reason); len(description) == 0 {
+			t.Errorf("default catalog: reason %q has no description", reason)
+		}
+	}
+
+	c := NewCatalog()
+	if err := c.Register("CustomReason", "custom"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("CustomReason", "again"); err == nil {
+		t.Errorf("expected duplicate registration to fail")
+	}
+	if err := c.Register("custom_reason", "invalid"); err == nil {
+		t.Errorf("expected invalid reason to fail")
+	}
+	if description, ok := c.Description("CustomReason"); !ok || description != "custom" {
+		t.Errorf("unexpected description %q", description)
+	}
+	if list := c.List(); len(list) != 1 || list[0] != "CustomReason" {
+		t.Errorf("unexpected reasons %v", list)
+	}
+}
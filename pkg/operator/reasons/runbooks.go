@@ -0,0 +1,89 @@
+package reasons
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// RegisterRunbook links the registered reason to the runbook explaining its remediation. Conditions set by
+// v1helpers.SetOperatorCondition and warning events recorded by a recorder returned by WithRunbooks get the runbook
+// appended to their message, so alerts derived from them point to it.
+func (c *Catalog) RegisterRunbook(reason Reason, runbookURL string) error {
+	parsed, err := url.Parse(runbookURL)
+	if err != nil {
+		return fmt.Errorf("runbook of reason %q: %w", reason, err)
+	}
+	if (parsed.Scheme != "https" && parsed.Scheme != "http") || len(parsed.Host) == 0 {
+		return fmt.Errorf("runbook of reason %q: %q must be an absolute http or https URL", reason, runbookURL)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.reasons[reason]; !ok {
+		return fmt.Errorf("runbook of reason %q: reason is not registered", reason)
+	}
+	c.runbooks[reason] = runbookURL
+	return nil
+}
+
+// MustRegisterRunbook is like RegisterRunbook, but panics on error.
+func (c *Catalog) MustRegisterRunbook(reason Reason, runbookURL string) {
+	if err := c.RegisterRunbook(reason, runbookURL); err != nil {
+		panic(err)
+	}
+}
+
+// Runbook returns the runbook of the reason, false if none is registered.
+func (c *Catalog) Runbook(reason Reason) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	runbookURL, ok := c.runbooks[reason]
+	return runbookURL, ok
+}
+
+// AppendRunbook appends the runbook of the reason to the message, if one is registered and the message does not
+// contain it yet.
+func (c *Catalog) AppendRunbook(reason, message string) string {
+	runbookURL, ok := c.Runbook(Reason(reason))
+	if !ok || strings.Contains(message, runbookURL) {
+		return message
+	}
+	if len(message) == 0 {
+		return "Runbook: " + runbookURL
+	}
+	return message + " Runbook: " + runbookURL
+}
+
+// WithRunbooks returns a recorder appending the runbooks registered in the catalog to the messages of warning events.
+// Normal events are recorded unchanged.
+func WithRunbooks(recorder events.Recorder, catalog *Catalog) events.Recorder {
+	return &runbookRecorder{Recorder: recorder, catalog: catalog}
+}
+
+type runbookRecorder struct {
+	events.Recorder
+	catalog *Catalog
+}
+
+func (r *runbookRecorder) Warning(reason, message string) {
+	r.Recorder.Warning(reason, r.catalog.AppendRunbook(reason, message))
+}
+
+func (r *runbookRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *runbookRecorder) ForComponent(componentName string) events.Recorder {
+	return WithRunbooks(r.Recorder.ForComponent(componentName), r.catalog)
+}
+
+func (r *runbookRecorder) WithComponentSuffix(componentNameSuffix string) events.Recorder {
+	return WithRunbooks(r.Recorder.WithComponentSuffix(componentNameSuffix), r.catalog)
+}
+
+func (r *runbookRecorder) WithContext(ctx context.Context) events.Recorder {
+	return WithRunbooks(r.Recorder.WithContext(ctx), r.catalog)
+}
@@ -0,0 +1,78 @@
+package reasons
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRunbooks(t *testing.T) {
+	c := NewCatalog()
+	c.MustRegister("CustomReason", "custom")
+	if err := c.RegisterRunbook("UnknownReason", "https://docs.example.com/unknown"); err == nil {
+		t.Error("expected a runbook of an unregistered reason to fail")
+	}
+	for _, invalid := range []string{"docs.example.com/custom", "file:///runbook.md", "https://"} {
+		if err := c.RegisterRunbook("CustomReason", invalid); err == nil {
+			t.Errorf("expected runbook %q to fail", invalid)
+		}
+	}
+	c.MustRegisterRunbook("CustomReason", "https://docs.example.com/custom")
+
+	for _, test := range []struct {
+		reason   string
+		message  string
+		expected string
+	}{
+		{reason: "CustomReason", message: "Sync failed.", expected: "Sync failed. Runbook: https://docs.example.com/custom"},
+		{reason: "CustomReason", message: "Sync failed. Runbook: https://docs.example.com/custom", expected: "Sync failed. Runbook: https://docs.example.com/custom"},
+		{reason: "CustomReason", expected: "Runbook: https://docs.example.com/custom"},
+		{reason: "OtherReason", message: "Sync failed.", expected: "Sync failed."},
+	} {
+		if actual := c.AppendRunbook(test.reason, test.message); actual != test.expected {
+			t.Errorf("expected %q for reason %q and message %q, got %q", test.expected, test.reason, test.message, actual)
+		}
+	}
+}
+
+func TestWithRunbooks(t *testing.T) {
+	c := NewCatalog()
+	c.MustRegister("CustomReason", "custom")
+	c.MustRegisterRunbook("CustomReason", "https://docs.example.com/custom")
+
+	inMemory := events.NewInMemoryRecorder("test")
+	recorder := WithRunbooks(inMemory, c).WithComponentSuffix("controller")
+	recorder.Warningf("CustomReason", "sync of %s failed", "foo")
+	recorder.Eventf("CustomReason", "sync of %s succeeded", "foo")
+
+	recorded := inMemory.Events()
+	if len(recorded) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(recorded))
+	}
+	if expected := "sync of foo failed Runbook: https://docs.example.com/custom"; recorded[0].Message != expected {
+		t.Errorf("expected warning message %q, got %q", expected, recorded[0].Message)
+	}
+	if expected := "sync of foo succeeded"; recorded[1].Message != expected {
+		t.Errorf("expected normal event message %q, got %q", expected, recorded[1].Message)
+	}
+}
+
+// TestDefaultRunbooksExist checks the runbooks of the default catalog kept in this repository exist.
+func TestDefaultRunbooksExist(t *testing.T) {
+	const repositoryURL = "https://github.com/openshift/library-go/blob/master/"
+	for _, reason := range DefaultCatalog.List() {
+		runbookURL, ok := DefaultCatalog.Runbook(reason)
+		if !ok || !strings.HasPrefix(runbookURL, repositoryURL) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join("..", "..", "..", strings.TrimPrefix(runbookURL, repositoryURL))); err != nil {
+			t.Errorf("runbook of reason %q: %v", reason, err)
+		}
+	}
+	if _, ok := DefaultCatalog.Runbook(UnusedCertificates); !ok {
+		t.Errorf("expected a runbook for %q", UnusedCertificates)
+	}
+}
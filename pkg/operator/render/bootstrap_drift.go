@@ -0,0 +1,216 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/render/options"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+// BootstrapConfigKey is the key the bootstrap config is stored under by ApplyBootstrapConfig.
+const BootstrapConfigKey = "config.yaml"
+
+// ApplyBootstrapConfig stores the bootstrap config rendered by GenericOptions.ApplyTo in a config map, so that it is
+// still available in the cluster after the bootstrap node is gone. Call it from the operator (e.g. from the rendered
+// config file mounted into the bootstrap pod) to reconcile the bootstrap-time config into a cluster object.
+func ApplyBootstrapConfig(ctx context.Context, client corev1client.ConfigMapsGetter, recorder events.Recorder, namespace, name string, fileConfig *options.FileConfig) (*corev1.ConfigMap, bool, error) {
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{BootstrapConfigKey: string(fileConfig.BootstrapConfig)},
+	})
+}
+
+// ApplyBootstrapAssets stores the assets loaded by GenericOptions.ApplyTo, e.g. the bootstrap certs and keys, in a
+// secret, so that they can still be read with BootstrapAssets after the bootstrap node is gone. The asset paths are
+// turned into secret keys with BootstrapAssetKey.
+func ApplyBootstrapAssets(ctx context.Context, client corev1client.SecretsGetter, recorder events.Recorder, namespace, name string, fileConfig *options.FileConfig) (*corev1.Secret, bool, error) {
+	data := make(map[string][]byte, len(fileConfig.Assets))
+	for path, content := range fileConfig.Assets {
+		key := BootstrapAssetKey(path)
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, false, fmt.Errorf("invalid bootstrap asset %q: %s", path, strings.Join(errs, ", "))
+		}
+		if _, duplicate := data[key]; duplicate {
+			return nil, false, fmt.Errorf("bootstrap asset %q collides with another asset as key %q", path, key)
+		}
+		data[key] = content
+	}
+	return resourceapply.ApplySecret(ctx, client, recorder, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	})
+}
+
+// BootstrapAssetKey returns the secret key of a bootstrap asset path, with path separators replaced by "__", e.g.
+// "tls/ca.crt" is stored as "tls__ca.crt".
+func BootstrapAssetKey(path string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", "__")
+}
+
+// BootstrapAssets returns the bootstrap assets stored by ApplyBootstrapAssets, by BootstrapAssetKey. A missing secret
+// returns no assets and no error, e.g. on clusters installed before the secret was introduced.
+func BootstrapAssets(lister corev1listers.SecretLister, namespace, name string) (map[string][]byte, error) {
+	secret, err := lister.Secrets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	assets := make(map[string][]byte, len(secret.Data))
+	for key, content := range secret.Data {
+		assets[key] = content
+	}
+	return assets, nil
+}
+
+// ConfigDifferences compares the bootstrap config with the config rendered at runtime and returns a sorted list of
+// differences, one per leaf field, in the form "<path>: <bootstrap value> -> <runtime value>". Both configs may be
+// YAML or JSON.
+func ConfigDifferences(bootstrapConfig, runtimeConfig []byte) ([]string, error) {
+	bootstrap := map[string]interface{}{}
+	if err := yaml.Unmarshal(bootstrapConfig, &bootstrap); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap config: %v", err)
+	}
+	runtime := map[string]interface{}{}
+	if err := yaml.Unmarshal(runtimeConfig, &runtime); err != nil {
+		return nil, fmt.Errorf("failed to parse runtime config: %v", err)
+	}
+
+	var diffs []string
+	diffValues("", bootstrap, runtime, &diffs)
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// BootstrapConfigDriftReporter compares the bootstrap config stored by ApplyBootstrapConfig with the config rendered
+// at runtime. Operators create one per bootstrap config map and call Report on every sync, the drift event is only
+// emitted when the drift changes.
+type BootstrapConfigDriftReporter struct {
+	lister       corev1listers.ConfigMapLister
+	recorder     events.Recorder
+	namespace    string
+	name         string
+	ignoredPaths []string
+
+	lock sync.Mutex
+	// reported is the drift last reported by this reporter.
+	reported string
+}
+
+// NewBootstrapConfigDriftReporter returns a reporter for the bootstrap config map namespace/name. Differences at or
+// below ignoredPaths are not reported.
+func NewBootstrapConfigDriftReporter(lister corev1listers.ConfigMapLister, recorder events.Recorder, namespace, name string, ignoredPaths ...string) *BootstrapConfigDriftReporter {
+	return &BootstrapConfigDriftReporter{
+		lister:       lister,
+		recorder:     recorder,
+		namespace:    namespace,
+		name:         name,
+		ignoredPaths: ignoredPaths,
+	}
+}
+
+// Report compares the bootstrap config with runtimeConfig and emits a warning event listing the differences whenever
+// they change. A missing bootstrap config map is not an error, e.g. on clusters installed before the config map was
+// introduced. The differences are returned for further use, e.g. in a condition message.
+func (r *BootstrapConfigDriftReporter) Report(runtimeConfig []byte) ([]string, error) {
+	cm, err := r.lister.ConfigMaps(r.namespace).Get(r.name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	diffs, err := ConfigDifferences([]byte(cm.Data[BootstrapConfigKey]), runtimeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var relevant []string
+	for _, d := range diffs {
+		ignored := false
+		for _, p := range r.ignoredPaths {
+			if d == p || strings.HasPrefix(d, p+".") || strings.HasPrefix(d, p+":") || strings.HasPrefix(d, p+"[") {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			relevant = append(relevant, d)
+		}
+	}
+	r.reportDrift(relevant)
+	return relevant, nil
+}
+
+func (r *BootstrapConfigDriftReporter) reportDrift(diffs []string) {
+	joined := strings.Join(diffs, "\n")
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.reported == joined {
+		return
+	}
+	r.reported = joined
+	if len(diffs) > 0 {
+		r.recorder.Warningf("BootstrapConfigDrift", "Runtime config differs from bootstrap config in configmap %s/%s:\n%s", r.namespace, r.name, joined)
+	}
+}
+
+func diffValues(path string, bootstrap, runtime interface{}, diffs *[]string) {
+	bootstrapMap, bootstrapIsMap := bootstrap.(map[string]interface{})
+	runtimeMap, runtimeIsMap := runtime.(map[string]interface{})
+	if bootstrapIsMap && runtimeIsMap {
+		keys := map[string]bool{}
+		for k := range bootstrapMap {
+			keys[k] = true
+		}
+		for k := range runtimeMap {
+			keys[k] = true
+		}
+		for k := range keys {
+			childPath := k
+			if len(path) > 0 {
+				childPath = path + "." + k
+			}
+			bootstrapChild, inBootstrap := bootstrapMap[k]
+			runtimeChild, inRuntime := runtimeMap[k]
+			switch {
+			case !inBootstrap:
+				*diffs = append(*diffs, fmt.Sprintf("%s: <unset> -> %s", childPath, toJSON(runtimeChild)))
+			case !inRuntime:
+				*diffs = append(*diffs, fmt.Sprintf("%s: %s -> <unset>", childPath, toJSON(bootstrapChild)))
+			default:
+				diffValues(childPath, bootstrapChild, runtimeChild, diffs)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(bootstrap, runtime) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, toJSON(bootstrap), toJSON(runtime)))
+	}
+}
+
+func toJSON(v interface{}) string {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bs)
+}
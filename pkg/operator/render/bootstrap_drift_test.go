@@ -0,0 +1,113 @@
+package render
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/render/options"
+)
+
+func TestConfigDifferences(t *testing.T) {
+	bootstrap := []byte(`
+apiVersion: kubecontrolplane.config.openshift.io/v1
+kind: KubeAPIServerConfig
+servingInfo:
+  bindAddress: 0.0.0.0:6443
+  minTLSVersion: VersionTLS12
+apiServerArguments:
+  feature-gates: [A=true]
+`)
+	runtime := []byte(`{"apiVersion":"kubecontrolplane.config.openshift.io/v1","kind":"KubeAPIServerConfig","servingInfo":{"bindAddress":"0.0.0.0:6443","minTLSVersion":"VersionTLS13"},"apiServerArguments":{"feature-gates":["A=true","B=true"]},"corsAllowedOrigins":["//localhost"]}`)
+
+	diffs, err := ConfigDifferences(bootstrap, runtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`apiServerArguments.feature-gates: ["A=true"] -> ["A=true","B=true"]`,
+		`corsAllowedOrigins: <unset> -> ["//localhost"]`,
+		`servingInfo.minTLSVersion: "VersionTLS12" -> "VersionTLS13"`,
+	}
+	if !reflect.DeepEqual(expected, diffs) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, diffs)
+	}
+}
+
+func TestBootstrapConfigDriftReporter(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := corev1listers.NewConfigMapLister(indexer)
+	recorder := events.NewInMemoryRecorder("test-bootstrap-drift")
+	reporter := NewBootstrapConfigDriftReporter(lister, recorder, "ns", "bootstrap-config", "c")
+
+	diffs, err := reporter.Report([]byte(`{"a":"b"}`))
+	if err != nil || len(diffs) != 0 || len(recorder.Events()) != 0 {
+		t.Fatalf("expected missing bootstrap config to be ignored, got %v, %v", diffs, err)
+	}
+
+	indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bootstrap-config"},
+		Data:       map[string]string{BootstrapConfigKey: "a: b\nc: d\n"},
+	})
+	diffs, err = reporter.Report([]byte(`{"a":"x","c":"e"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{`a: "b" -> "x"`}; !reflect.DeepEqual(expected, diffs) {
+		t.Errorf("expected %q, got %q", expected, diffs)
+	}
+	if len(recorder.Events()) != 1 || recorder.Events()[0].Reason != "BootstrapConfigDrift" {
+		t.Errorf("expected BootstrapConfigDrift event, got %v", recorder.Events())
+	}
+
+	// the same drift on the next sync is not reported again
+	if _, err := reporter.Report([]byte(`{"a":"x","c":"e"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Errorf("expected the BootstrapConfigDrift event not to repeat, got %v", recorder.Events())
+	}
+
+	// a separate reporter, e.g. after an operator restart, reports the drift again
+	if _, err := NewBootstrapConfigDriftReporter(lister, recorder, "ns", "bootstrap-config", "c").Report([]byte(`{"a":"x","c":"e"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("expected a separate reporter to report the drift, got %v", recorder.Events())
+	}
+}
+
+func TestApplyBootstrapAssets(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	fileConfig := &options.FileConfig{Assets: map[string][]byte{"tls/ca.crt": []byte("ca"), "admin.kubeconfig": []byte("kubeconfig")}}
+	if _, _, err := ApplyBootstrapAssets(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), "ns", "bootstrap-assets", fileConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	secret, err := client.CoreV1().Secrets("ns").Get(context.TODO(), "bootstrap-assets", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer.Add(secret)
+	assets, err := BootstrapAssets(corev1listers.NewSecretLister(indexer), "ns", "bootstrap-assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]byte{"tls__ca.crt": []byte("ca"), "admin.kubeconfig": []byte("kubeconfig")}
+	if !reflect.DeepEqual(expected, assets) {
+		t.Errorf("expected %q, got %q", expected, assets)
+	}
+
+	fileConfig.Assets["tls__ca.crt"] = []byte("other")
+	if _, _, err := ApplyBootstrapAssets(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), "ns", "bootstrap-assets", fileConfig); err == nil {
+		t.Error("expected colliding asset keys to fail")
+	}
+}
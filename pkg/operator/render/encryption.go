@@ -0,0 +1,106 @@
+package render
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/assets"
+)
+
+// EncryptedAssetSuffix is appended to the names of encrypted assets.
+const EncryptedAssetSuffix = ".enc"
+
+// LoadAssetEncryptionKey reads a base64 encoded AES-256 key from the file.
+func LoadAssetEncryptionKey(path string) ([]byte, error) {
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key in %q: %v", path, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key in %q must be 32 bytes long, got %d", path, len(key))
+	}
+	return key, nil
+}
+
+// EncryptAssets returns the assets encrypted with AES-GCM, with the EncryptedAssetSuffix appended to their names.
+// The name is authenticated too, so that encrypted assets cannot be swapped unnoticed.
+func EncryptAssets(key []byte, as assets.Assets) (assets.Assets, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	var ret assets.Assets
+	for _, a := range as {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		a.Data = aead.Seal(nonce, nonce, a.Data, []byte(a.Name))
+		a.Name += EncryptedAssetSuffix
+		ret = append(ret, a)
+	}
+	return ret, nil
+}
+
+// DecryptAsset decrypts the data of an asset encrypted by EncryptAssets. The name is the one of the plain asset,
+// relative to the output dir of the render command.
+func DecryptAsset(key []byte, name string, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%q is too short", name)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %q: %v", name, err)
+	}
+	return plain, nil
+}
+
+// DecryptAssetDir decrypts the encrypted assets in the input dir, as written by the render command, into the output dir.
+// Other files are ignored.
+func DecryptAssetDir(inputDir, outputDir string, key []byte) error {
+	return filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, EncryptedAssetSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(inputDir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(rel, EncryptedAssetSuffix)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		plain, err := DecryptAsset(key, name, data)
+		if err != nil {
+			return err
+		}
+		return assets.Asset{Name: name, Data: plain, FilePermission: assets.Permission(info.Mode().Perm())}.WriteFile(outputDir)
+	})
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
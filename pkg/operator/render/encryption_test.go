@@ -0,0 +1,83 @@
+package render
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/render/options"
+)
+
+func TestWriteFilesEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	templatesDir := filepath.Join(dir, "templates")
+	outputDir := filepath.Join(dir, "output")
+	manifest := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"{{.Name}}\"\n")
+	for _, manifestDir := range []string{"bootstrap-manifests", "manifests"} {
+		if err := os.MkdirAll(filepath.Join(templatesDir, manifestDir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(templatesDir, "manifests", "secret.yaml"), manifest, 0644); err != nil {
+		t.Fatal(err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := &options.GenericOptions{
+		TemplatesDir:           templatesDir,
+		AssetOutputDir:         outputDir,
+		AssetEncryptionKeyFile: keyFile,
+		ConfigOutputFile:       filepath.Join(dir, "config.yaml"),
+	}
+	if err := WriteFiles(opt, &options.FileConfig{}, struct{ Name string }{Name: "keys"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Stat(opt.ConfigOutputFile); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the config to be written with mode 0600, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "manifests", "secret.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no plain manifest, got %v", err)
+	}
+	encrypted, err := ioutil.ReadFile(filepath.Join(outputDir, "manifests", "secret.yaml"+EncryptedAssetSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte("keys")) {
+		t.Errorf("expected the manifest to be encrypted")
+	}
+
+	// a renamed asset must not decrypt
+	if _, err := DecryptAsset(key, "manifests/other.yaml", encrypted); err == nil {
+		t.Errorf("expected decrypting with another name to fail")
+	}
+	wrongKey := make([]byte, 32)
+	if _, err := DecryptAsset(wrongKey, "manifests/secret.yaml", encrypted); err == nil {
+		t.Errorf("expected decrypting with another key to fail")
+	}
+
+	decryptedDir := filepath.Join(dir, "decrypted")
+	if err := DecryptAssetDir(outputDir, decryptedDir, key); err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := ioutil.ReadFile(filepath.Join(decryptedDir, "manifests", "secret.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"keys\"\n"; string(decrypted) != expected {
+		t.Errorf("expected %q, got %q", expected, decrypted)
+	}
+}
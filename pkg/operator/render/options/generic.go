@@ -28,6 +28,9 @@ type GenericOptions struct {
 	TemplatesDir   string
 	AssetInputDir  string
 	AssetOutputDir string
+	// AssetEncryptionKeyFile is a file holding a base64 encoded AES-256 key. If set, the rendered assets are written
+	// encrypted, see render.DecryptAssetDir.
+	AssetEncryptionKeyFile string
 
 	FeatureSet string
 }
@@ -48,6 +51,7 @@ func NewGenericOptions() *GenericOptions {
 func (o *GenericOptions) AddFlags(fs *pflag.FlagSet, configGVK schema.GroupVersionKind) {
 	fs.StringVar(&o.AssetOutputDir, "asset-output-dir", o.AssetOutputDir, "Output path for rendered manifests.")
 	fs.StringVar(&o.AssetInputDir, "asset-input-dir", o.AssetInputDir, "A path to directory with certificates and secrets.")
+	fs.StringVar(&o.AssetEncryptionKeyFile, "asset-encryption-key-file", o.AssetEncryptionKeyFile, "A file with a base64 encoded 32 byte key. If set, the rendered manifests are encrypted with AES-GCM.")
 	fs.StringVar(&o.TemplatesDir, "templates-input-dir", o.TemplatesDir, "A path to a directory with manifest templates.")
 	fs.StringSliceVar(&o.AdditionalConfigOverrideFiles, "config-override-files", o.AdditionalConfigOverrideFiles,
 		fmt.Sprintf("Additional sparse %s files for customiziation through the installer, merged into the default config in the given order.", gvkOutput{configGVK}))
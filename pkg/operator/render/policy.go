@@ -0,0 +1,215 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/assets"
+)
+
+// PolicyCheck validates a rendered manifest.
+type PolicyCheck interface {
+	// Name identifies the check in the report.
+	Name() string
+	// Check returns the violations of the policy by the object, nil if there are none.
+	Check(obj *unstructured.Unstructured) []string
+}
+
+// PolicyViolation is a violation of a policy by a rendered manifest.
+type PolicyViolation struct {
+	File    string
+	Object  string
+	Check   string
+	Message string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s (%s): [%s] %s", v.File, v.Object, v.Check, v.Message)
+}
+
+// PolicyReport is the error returned when rendered manifests violate policies.
+type PolicyReport []PolicyViolation
+
+func (r PolicyReport) Error() string {
+	lines := make([]string, 0, len(r))
+	for _, v := range r {
+		lines = append(lines, v.String())
+	}
+	return fmt.Sprintf("%d policy violations in rendered manifests:\n%s", len(r), strings.Join(lines, "\n"))
+}
+
+// DefaultPolicyChecks returns the built-in checks: no :latest images, cpu and memory requests, a seccomp profile and
+// labels on every object.
+func DefaultPolicyChecks() []PolicyCheck {
+	return []PolicyCheck{
+		NoLatestImageCheck(),
+		ResourceRequestsCheck(),
+		SeccompProfileCheck(),
+		RequiredLabelsCheck(),
+	}
+}
+
+// CheckPolicies runs the checks on the manifests and returns a PolicyReport with all violations, nil if there are none.
+// Manifests which do not decode into an object, e.g. empty files, are skipped.
+func CheckPolicies(manifests assets.Assets, checks ...PolicyCheck) error {
+	var report PolicyReport
+	for _, manifest := range manifests {
+		content := map[string]interface{}{}
+		if err := yaml.Unmarshal(manifest.Data, &content); err != nil || len(content) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		name := obj.GetKind() + "/" + obj.GetName()
+		if len(obj.GetNamespace()) > 0 {
+			name = obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+		}
+		for _, check := range checks {
+			for _, message := range check.Check(obj) {
+				report = append(report, PolicyViolation{File: manifest.Name, Object: name, Check: check.Name(), Message: message})
+			}
+		}
+	}
+	if len(report) == 0 {
+		return nil
+	}
+	sort.SliceStable(report, func(i, j int) bool { return report[i].File < report[j].File })
+	return report
+}
+
+// PolicyCheckFunc adapts a function to the PolicyCheck interface.
+type PolicyCheckFunc struct {
+	CheckName string
+	CheckFunc func(obj *unstructured.Unstructured) []string
+}
+
+func (f PolicyCheckFunc) Name() string {
+	return f.CheckName
+}
+
+func (f PolicyCheckFunc) Check(obj *unstructured.Unstructured) []string {
+	return f.CheckFunc(obj)
+}
+
+// NoLatestImageCheck rejects container images with the latest tag or without a tag or digest.
+func NoLatestImageCheck() PolicyCheck {
+	return PolicyCheckFunc{CheckName: "no-latest-image", CheckFunc: func(obj *unstructured.Unstructured) []string {
+		var violations []string
+		for _, container := range containers(obj) {
+			image, _, _ := unstructured.NestedString(container, "image")
+			if usesLatestTag(image) {
+				violations = append(violations, fmt.Sprintf("container %q uses image %q without a pinned tag or digest", containerName(container), image))
+			}
+		}
+		return violations
+	}}
+}
+
+// ResourceRequestsCheck requires cpu and memory requests on every container.
+func ResourceRequestsCheck() PolicyCheck {
+	return PolicyCheckFunc{CheckName: "resource-requests", CheckFunc: func(obj *unstructured.Unstructured) []string {
+		var violations []string
+		for _, container := range containers(obj) {
+			requests, _, _ := unstructured.NestedMap(container, "resources", "requests")
+			for _, resource := range []string{"cpu", "memory"} {
+				if _, ok := requests[resource]; !ok {
+					violations = append(violations, fmt.Sprintf("container %q has no %s request", containerName(container), resource))
+				}
+			}
+		}
+		return violations
+	}}
+}
+
+// SeccompProfileCheck requires a seccomp profile on the pod or on every container.
+func SeccompProfileCheck() PolicyCheck {
+	return PolicyCheckFunc{CheckName: "seccomp-profile", CheckFunc: func(obj *unstructured.Unstructured) []string {
+		podSpec, ok := podSpec(obj)
+		if !ok {
+			return nil
+		}
+		if _, ok, _ := unstructured.NestedMap(podSpec, "securityContext", "seccompProfile"); ok {
+			return nil
+		}
+		var violations []string
+		for _, container := range containers(obj) {
+			if _, ok, _ := unstructured.NestedMap(container, "securityContext", "seccompProfile"); !ok {
+				violations = append(violations, fmt.Sprintf("container %q has no seccomp profile and neither has the pod", containerName(container)))
+			}
+		}
+		return violations
+	}}
+}
+
+// RequiredLabelsCheck requires the label keys on every object, or any label at all if no keys are given.
+func RequiredLabelsCheck(keys ...string) PolicyCheck {
+	return PolicyCheckFunc{CheckName: "required-labels", CheckFunc: func(obj *unstructured.Unstructured) []string {
+		labels := obj.GetLabels()
+		if len(keys) == 0 && len(labels) == 0 {
+			return []string{"object has no labels"}
+		}
+		var violations []string
+		for _, key := range keys {
+			if _, ok := labels[key]; !ok {
+				violations = append(violations, fmt.Sprintf("object has no %q label", key))
+			}
+		}
+		return violations
+	}}
+}
+
+// podSpec returns the pod spec of pods and of the workload kinds with a pod template.
+func podSpec(obj *unstructured.Unstructured) (map[string]interface{}, bool) {
+	var path []string
+	switch obj.GetKind() {
+	case "Pod":
+		path = []string{"spec"}
+	case "Deployment", "DaemonSet", "StatefulSet", "ReplicaSet", "Job":
+		path = []string{"spec", "template", "spec"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		return nil, false
+	}
+	spec, ok, err := unstructured.NestedMap(obj.Object, path...)
+	return spec, ok && err == nil
+}
+
+// containers returns the init containers and containers of the pod spec.
+func containers(obj *unstructured.Unstructured) []map[string]interface{} {
+	spec, ok := podSpec(obj)
+	if !ok {
+		return nil
+	}
+	var ret []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _, _ := unstructured.NestedSlice(spec, field)
+		for _, item := range list {
+			if container, ok := item.(map[string]interface{}); ok {
+				ret = append(ret, container)
+			}
+		}
+	}
+	return ret
+}
+
+func containerName(container map[string]interface{}) string {
+	name, _, _ := unstructured.NestedString(container, "name")
+	return name
+}
+
+// usesLatestTag returns true for images with the latest tag and for images without tag and digest, which resolve to latest.
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	// a colon after the last slash separates the tag, before it the registry port
+	i := strings.LastIndex(image, ":")
+	if i < 0 || i < strings.LastIndex(image, "/") {
+		return true
+	}
+	return image[i+1:] == "latest"
+}
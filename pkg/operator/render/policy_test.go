@@ -0,0 +1,106 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/assets"
+)
+
+const compliantDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: openshift-foo
+  labels:
+    app: operator
+spec:
+  template:
+    spec:
+      securityContext:
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+      - name: operator
+        image: quay.io/openshift/foo@sha256:0000
+        resources:
+          requests:
+            cpu: 10m
+            memory: 50Mi
+`
+
+const nonCompliantPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: bootstrap
+  namespace: kube-system
+spec:
+  initContainers:
+  - name: setup
+    image: registry.local:5000/setup
+    securityContext:
+      seccompProfile:
+        type: RuntimeDefault
+    resources:
+      requests:
+        cpu: 10m
+        memory: 50Mi
+  containers:
+  - name: server
+    image: quay.io/openshift/foo:latest
+    resources:
+      requests:
+        cpu: 10m
+`
+
+func TestCheckPolicies(t *testing.T) {
+	manifests := assets.Assets{
+		{Name: "manifests/deployment.yaml", Data: []byte(compliantDeployment)},
+		{Name: "bootstrap-manifests/pod.yaml", Data: []byte(nonCompliantPod)},
+		{Name: "manifests/namespace.yaml", Data: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: openshift-foo\n  labels:\n    openshift.io/run-level: \"\"\n")},
+		{Name: "manifests/empty.yaml", Data: []byte("# intentionally empty\n")},
+	}
+
+	if err := CheckPolicies(manifests[:1], DefaultPolicyChecks()...); err != nil {
+		t.Errorf("expected the compliant deployment to pass, got: %v", err)
+	}
+
+	err := CheckPolicies(manifests, DefaultPolicyChecks()...)
+	report, ok := err.(PolicyReport)
+	if !ok {
+		t.Fatalf("expected a PolicyReport, got %v", err)
+	}
+	actual := []string{}
+	for _, v := range report {
+		actual = append(actual, v.String())
+	}
+	expected := []string{
+		`bootstrap-manifests/pod.yaml (Pod/kube-system/bootstrap): [no-latest-image] container "setup" uses image "registry.local:5000/setup" without a pinned tag or digest`,
+		`bootstrap-manifests/pod.yaml (Pod/kube-system/bootstrap): [no-latest-image] container "server" uses image "quay.io/openshift/foo:latest" without a pinned tag or digest`,
+		`bootstrap-manifests/pod.yaml (Pod/kube-system/bootstrap): [resource-requests] container "server" has no memory request`,
+		`bootstrap-manifests/pod.yaml (Pod/kube-system/bootstrap): [seccomp-profile] container "server" has no seccomp profile and neither has the pod`,
+		`bootstrap-manifests/pod.yaml (Pod/kube-system/bootstrap): [required-labels] object has no labels`,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected report:\n%#v", actual)
+	}
+
+	if err := CheckPolicies(manifests[2:3], RequiredLabelsCheck("app")); err == nil {
+		t.Errorf("expected the missing app label to be reported")
+	}
+}
+
+func TestUsesLatestTag(t *testing.T) {
+	for image, expected := range map[string]bool{
+		"quay.io/openshift/foo":             true,
+		"quay.io/openshift/foo:latest":      true,
+		"registry.local:5000/foo":           true,
+		"registry.local:5000/foo:v4.12":     false,
+		"quay.io/openshift/foo@sha256:0000": false,
+		"foo:1.0":                           false,
+	} {
+		if actual := usesLatestTag(image); actual != expected {
+			t.Errorf("%s: expected %v, got %v", image, expected, actual)
+		}
+	}
+}
@@ -2,29 +2,67 @@ package render
 
 import (
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
 
 	"github.com/openshift/library-go/pkg/assets"
 	"github.com/openshift/library-go/pkg/operator/render/options"
 )
 
-// WriteFiles writes the manifests and the bootstrap config file.
+// WriteFiles writes the manifests and the bootstrap config file. The manifests are encrypted if the options have an
+// asset encryption key file.
 func WriteFiles(opt *options.GenericOptions, fileConfig *options.FileConfig, templateData interface{}, additionalPredicates ...assets.FileInfoPredicate) error {
+	return WriteFilesWithPolicyChecks(opt, fileConfig, templateData, nil, additionalPredicates...)
+}
+
+// WriteFilesWithPolicyChecks writes the manifests and the bootstrap config file like WriteFiles, but runs the policy
+// checks on the rendered manifests first. Nothing is written if a manifest violates a policy, the returned error
+// is a PolicyReport listing all violations.
+func WriteFilesWithPolicyChecks(opt *options.GenericOptions, fileConfig *options.FileConfig, templateData interface{}, checks []PolicyCheck, additionalPredicates ...assets.FileInfoPredicate) error {
 	defaultPredicates := []assets.FileInfoPredicate{assets.OnlyYaml, assets.InstallerFeatureSet(opt.FeatureSet)}
-	// write assets
-	for _, manifestDir := range []string{"bootstrap-manifests", "manifests"} {
+	manifestDirs := []string{"bootstrap-manifests", "manifests"}
+	rendered := map[string]assets.Assets{}
+	var allManifests assets.Assets
+	for _, manifestDir := range manifestDirs {
 		manifests, err := assets.New(filepath.Join(opt.TemplatesDir, manifestDir), templateData, append(additionalPredicates, defaultPredicates...)...)
 		if err != nil {
 			return fmt.Errorf("failed rendering assets: %v", err)
 		}
-		if err := manifests.WriteFiles(filepath.Join(opt.AssetOutputDir, manifestDir)); err != nil {
-			return fmt.Errorf("failed writing assets to %q: %v", filepath.Join(opt.AssetOutputDir, manifestDir), err)
+		rendered[manifestDir] = manifests
+		for _, manifest := range manifests {
+			manifest.Name = filepath.Join(manifestDir, manifest.Name)
+			allManifests = append(allManifests, manifest)
+		}
+	}
+	if len(checks) > 0 {
+		if err := CheckPolicies(allManifests, checks...); err != nil {
+			return err
+		}
+	}
+
+	// write assets
+	if len(opt.AssetEncryptionKeyFile) > 0 {
+		key, err := LoadAssetEncryptionKey(opt.AssetEncryptionKeyFile)
+		if err != nil {
+			return err
+		}
+		encrypted, err := EncryptAssets(key, allManifests)
+		if err != nil {
+			return fmt.Errorf("failed encrypting assets: %v", err)
+		}
+		if err := encrypted.WriteFiles(opt.AssetOutputDir); err != nil {
+			return fmt.Errorf("failed writing assets to %q: %v", opt.AssetOutputDir, err)
+		}
+	} else {
+		for _, manifestDir := range manifestDirs {
+			if err := rendered[manifestDir].WriteFiles(filepath.Join(opt.AssetOutputDir, manifestDir)); err != nil {
+				return fmt.Errorf("failed writing assets to %q: %v", filepath.Join(opt.AssetOutputDir, manifestDir), err)
+			}
 		}
 	}
 
-	// create bootstrap configuration
-	if err := ioutil.WriteFile(opt.ConfigOutputFile, fileConfig.BootstrapConfig, 0644); err != nil {
+	// create bootstrap configuration, readable only by the owner as it may reference bootstrap credentials
+	config := assets.Asset{Name: filepath.Base(opt.ConfigOutputFile), FilePermission: assets.PermissionFileRestricted, Data: fileConfig.BootstrapConfig}
+	if err := config.WriteFile(filepath.Dir(opt.ConfigOutputFile)); err != nil {
 		return fmt.Errorf("failed to write merged config to %q: %v", opt.ConfigOutputFile, err)
 	}
 
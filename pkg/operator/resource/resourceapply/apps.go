@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"k8s.io/klog/v2"
 
@@ -124,6 +125,9 @@ func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.Deploymen
 	if err != nil {
 		return nil, false, err
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -154,8 +158,15 @@ func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.Deploymen
 		klog.Infof("Deployment %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, toWrite))
 	}
 
+	// explain rollouts, so that operand restarts can be tracked down from events
+	podTemplateChanges := podTemplateChanges(existing.Spec.Template, toWrite.Spec.Template)
+	if len(podTemplateChanges) > 0 {
+		klog.V(2).Infof("Deployment %q pod template changes: %s", required.Namespace+"/"+required.Name, strings.Join(podTemplateChanges, "; "))
+	}
+
+	reportFieldOwnershipConflicts(recorder, existing, toWrite)
 	actual, err := client.Deployments(required.Namespace).Update(ctx, toWrite, metav1.UpdateOptions{})
-	reportUpdateEvent(recorder, required, err)
+	reportUpdateEvent(recorder, required, err, podTemplateChanges...)
 	return actual, true, err
 }
 
@@ -211,6 +222,9 @@ func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSets
 	if err != nil {
 		return nil, false, err
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -240,7 +254,14 @@ func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSets
 	if klog.V(4).Enabled() {
 		klog.Infof("DaemonSet %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, toWrite))
 	}
+	// explain rollouts, so that operand restarts can be tracked down from events
+	podTemplateChanges := podTemplateChanges(existing.Spec.Template, toWrite.Spec.Template)
+	if len(podTemplateChanges) > 0 {
+		klog.V(2).Infof("DaemonSet %q pod template changes: %s", required.Namespace+"/"+required.Name, strings.Join(podTemplateChanges, "; "))
+	}
+
+	reportFieldOwnershipConflicts(recorder, existing, toWrite)
 	actual, err := client.DaemonSets(required.Namespace).Update(ctx, toWrite, metav1.UpdateOptions{})
-	reportUpdateEvent(recorder, required, err)
+	reportUpdateEvent(recorder, required, err, podTemplateChanges...)
 	return actual, true, err
 }
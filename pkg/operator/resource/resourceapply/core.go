@@ -104,6 +104,9 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 	if cache.SafeToSkipApply(required, existing) {
 		return existing, false, nil
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -118,6 +121,7 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 		klog.Infof("Namespace %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
 	}
 
+	reportFieldOwnershipConflicts(recorder, existing, existingCopy)
 	actual, err := client.Namespaces().Update(ctx, existingCopy, metav1.UpdateOptions{})
 	reportUpdateEvent(recorder, required, err)
 	cache.UpdateCachedResourceMetadata(required, actual)
@@ -151,6 +155,9 @@ func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGette
 	if cache.SafeToSkipApply(required, existing) {
 		return existing, false, nil
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -179,6 +186,7 @@ func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGette
 		klog.Infof("Service %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
 
+	reportFieldOwnershipConflicts(recorder, existing, existingCopy)
 	actual, err := client.Services(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	reportUpdateEvent(recorder, required, err)
 	cache.UpdateCachedResourceMetadata(required, actual)
@@ -203,6 +211,9 @@ func ApplyPodImproved(ctx context.Context, client coreclientv1.PodsGetter, recor
 	if cache.SafeToSkipApply(required, existing) {
 		return existing, false, nil
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -241,6 +252,9 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 	if cache.SafeToSkipApply(required, existing) {
 		return existing, false, nil
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
@@ -253,6 +267,7 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 	if klog.V(4).Enabled() {
 		klog.Infof("ServiceAccount %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
+	reportFieldOwnershipConflicts(recorder, existing, existingCopy)
 	actual, err := client.ServiceAccounts(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	reportUpdateEvent(recorder, required, err)
 	cache.UpdateCachedResourceMetadata(required, actual)
@@ -277,13 +292,17 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 	if cache.SafeToSkipApply(required, existing) {
 		return existing, false, nil
 	}
+	if skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	modified := resourcemerge.BoolPtr(false)
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(modified, &existingCopy.ObjectMeta, required.ObjectMeta)
 
-	caBundleInjected := required.Labels["config.openshift.io/inject-trusted-cabundle"] == "true"
+	caBundleInjected := required.Labels["config.openshift.io/inject-trusted-cabundle"] == "true" ||
+		required.Labels["operator.openshift.io/inject-library-trusted-cabundle"] == "true"
 	_, newCABundleRequired := required.Data["ca-bundle.crt"]
 
 	var modifiedKeys []string
@@ -329,6 +348,7 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 		existingCopy.Data["ca-bundle.crt"] = existingCABundle
 	}
 
+	reportFieldOwnershipConflicts(recorder, existing, existingCopy)
 	actual, err := client.ConfigMaps(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 
 	var details string
@@ -356,6 +376,9 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 	if cache.SafeToSkipApply(requiredInput, existing) {
 		return existing, false, nil
 	}
+	if err == nil && skipPaused(existing) {
+		return existing, false, nil
+	}
 
 	required := requiredInput.DeepCopy()
 	if required.Data == nil {
@@ -370,6 +393,11 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 		required.Data[k] = []byte(v)
 	}
 	required.StringData = nil
+	requiredDataHash := SecretDataHash(required.Data)
+	if required.Annotations == nil {
+		required.Annotations = map[string]string{}
+	}
+	required.Annotations[SecretDataHashAnnotation] = requiredDataHash
 
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
@@ -383,24 +411,43 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 		return nil, false, err
 	}
 
-	existingCopy := existing.DeepCopy()
+	// copy everything but the data, which is replaced or merged without copying the content
+	existingCopy := &corev1.Secret{
+		TypeMeta:   existing.TypeMeta,
+		ObjectMeta: *existing.ObjectMeta.DeepCopy(),
+		Immutable:  existing.Immutable,
+		Type:       existing.Type,
+	}
 
 	resourcemerge.EnsureObjectMeta(resourcemerge.BoolPtr(false), &existingCopy.ObjectMeta, required.ObjectMeta)
 
+	// the data is compared by the hash annotation the secret was applied with, rather than hashing the existing data
+	// on every call. Changes made by others that keep the annotation are caught when they change the keys or the
+	// length of a value. Secrets applied before the annotation existed are hashed once.
+	existingDataHash, hasDataHash := existing.Annotations[SecretDataHashAnnotation]
+	var existingData map[string][]byte
 	switch required.Type {
 	case corev1.SecretTypeServiceAccountToken:
 		// Secrets for ServiceAccountTokens will have data injected by kube controller manager.
 		// We will apply only the explicitly set keys.
-		if existingCopy.Data == nil {
-			existingCopy.Data = map[string][]byte{}
+		existingCopy.Data = make(map[string][]byte, len(existing.Data)+len(required.Data))
+		for k, v := range existing.Data {
+			existingCopy.Data[k] = v
 		}
-
 		for k, v := range required.Data {
 			existingCopy.Data[k] = v
 		}
+		existingData = secretDataKeys(existing.Data, required.Data)
 
 	default:
 		existingCopy.Data = required.Data
+		existingData = existing.Data
+	}
+	var dataSame bool
+	if hasDataHash {
+		dataSame = secretDataHashesEqual(existingDataHash, requiredDataHash) && secretDataShapeEqual(existingData, required.Data)
+	} else {
+		dataSame = secretDataHashesEqual(SecretDataHash(existingData), requiredDataHash)
 	}
 
 	existingCopy.Type = required.Type
@@ -410,7 +457,14 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 		existingCopy.Type = corev1.SecretTypeOpaque
 	}
 
-	if equality.Semantic.DeepEqual(existingCopy, existing) {
+	unchanged := dataSame && existingCopy.Type == existing.Type && equality.Semantic.DeepEqual(existingCopy.ObjectMeta, existing.ObjectMeta)
+	if !unchanged && dataSame && !hasDataHash {
+		// secrets applied before the annotation existed are not updated only to add it
+		withoutDataHash := existingCopy.ObjectMeta.DeepCopy()
+		delete(withoutDataHash.Annotations, SecretDataHashAnnotation)
+		unchanged = existingCopy.Type == existing.Type && equality.Semantic.DeepEqual(*withoutDataHash, existing.ObjectMeta)
+	}
+	if unchanged {
 		cache.UpdateCachedResourceMetadata(requiredInput, existingCopy)
 		return existing, false, nil
 	}
@@ -426,6 +480,7 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 	 * We need to explicitly opt for delete+create in that case.
 	 */
 	if existingCopy.Type == existing.Type {
+		reportFieldOwnershipConflicts(recorder, existing, existingCopy)
 		actual, err = client.Secrets(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 		reportUpdateEvent(recorder, existingCopy, err)
 
@@ -517,6 +517,21 @@ func TestApplySecret(t *testing.T) {
 				return
 			}
 
+			if tc.changed {
+				// written secrets carry the hash of their data
+				tc.expected = withSecretDataHash(tc.expected)
+				for i, action := range tc.actions {
+					switch action := action.(type) {
+					case clienttesting.CreateActionImpl:
+						action.Object = withSecretDataHash(action.Object.(*corev1.Secret))
+						tc.actions[i] = action
+					case clienttesting.UpdateActionImpl:
+						action.Object = withSecretDataHash(action.Object.(*corev1.Secret))
+						tc.actions[i] = action
+					}
+				}
+			}
+
 			if !equality.Semantic.DeepEqual(tc.expected, got) {
 				t.Errorf("objects don't match %s", cmp.Diff(tc.expected, got))
 			}
@@ -1012,6 +1027,9 @@ func TestSyncSecret(t *testing.T) {
 				return
 			}
 
+			if tc.expectedChanged {
+				tc.expectedSecret = withSecretDataHash(tc.expectedSecret)
+			}
 			if !equality.Semantic.DeepEqual(secret, tc.expectedSecret) {
 				t.Errorf("secrets differ: %s", cmp.Diff(tc.expectedSecret, secret))
 			}
@@ -1273,6 +1291,9 @@ func TestSyncPartialSync(t *testing.T) {
 				return
 			}
 
+			if tc.expectedChanged {
+				tc.expectedSecret = withSecretDataHash(tc.expectedSecret)
+			}
 			if !equality.Semantic.DeepEqual(secret, tc.expectedSecret) {
 				t.Errorf("secrets differ: %s", cmp.Diff(tc.expectedSecret, secret))
 			}
@@ -1454,3 +1475,16 @@ func TestApplyService(t *testing.T) {
 		})
 	}
 }
+
+// withSecretDataHash returns a copy of the secret with the data hash annotation ApplySecret sets on written secrets.
+func withSecretDataHash(secret *corev1.Secret) *corev1.Secret {
+	if secret == nil {
+		return nil
+	}
+	ret := secret.DeepCopy()
+	if ret.Annotations == nil {
+		ret.Annotations = map[string]string{}
+	}
+	ret.Annotations[SecretDataHashAnnotation] = SecretDataHash(ret.Data)
+	return ret
+}
@@ -0,0 +1,147 @@
+package resourceapply
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+)
+
+var fieldOwnershipConflictsMetric = librarymetrics.NewCounterVec(&metrics.CounterOpts{
+	Subsystem:      "resourceapply",
+	Name:           "field_ownership_conflicts_total",
+	Help:           "Number of updates overwriting fields owned by another field manager, by kind and field manager",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"kind", "manager"})
+
+var (
+	fieldManagerLock sync.RWMutex
+	// fieldManager is the field manager of the updates of this process. The kube-apiserver defaults it to the user
+	// agent prefix, which rest.DefaultKubernetesUserAgent derives from the binary name.
+	fieldManager = filepath.Base(os.Args[0])
+)
+
+// SetFieldManager sets the field manager the updates of this process are recorded with, for clients with a custom
+// user agent or field manager. Fields owned by other managers are reported when they are overwritten.
+func SetFieldManager(manager string) {
+	fieldManagerLock.Lock()
+	defer fieldManagerLock.Unlock()
+	fieldManager = manager
+}
+
+func getFieldManager() string {
+	fieldManagerLock.RLock()
+	defer fieldManagerLock.RUnlock()
+	return fieldManager
+}
+
+// reportFieldOwnershipConflicts records a warning event and increments a metric for every other field manager owning
+// fields that change from existing to toWrite, turning silent fights about fields into diagnosable conflicts. Changes
+// of lists are attributed to the whole list.
+func reportFieldOwnershipConflicts(recorder events.Recorder, existing, toWrite runtime.Object) {
+	existingMeta, err := meta.Accessor(existing)
+	if err != nil || len(existingMeta.GetManagedFields()) == 0 {
+		return
+	}
+	changed, err := changedFields(existing, toWrite)
+	if err != nil {
+		klog.V(4).Infof("Failed to compute the changed fields: %v", err)
+		return
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	self := getFieldManager()
+	conflicts := map[string][]string{}
+	for _, entry := range existingMeta.GetManagedFields() {
+		if entry.Manager == self || entry.FieldsV1 == nil {
+			continue
+		}
+		owned := &fieldpath.Set{}
+		if err := owned.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			klog.V(4).Infof("Failed to parse the managed fields of %q: %v", entry.Manager, err)
+			continue
+		}
+		for _, path := range changed {
+			if owns(owned, path) {
+				conflicts[entry.Manager] = append(conflicts[entry.Manager], path.String())
+			}
+		}
+	}
+
+	gvk := resourcehelper.GuessObjectGroupVersionKind(toWrite)
+	for manager, paths := range conflicts {
+		fieldOwnershipConflictsMetric.WithLabelValues(gvk.Kind, manager).Inc()
+		// a manager can have several entries, e.g. for different operations or subresources, hence the set
+		recorder.Warningf("FieldOwnershipConflict", "Updating %s overwrites fields owned by %q: %s",
+			resourcehelper.FormatResourceForCLIWithNamespace(toWrite), manager, strings.Join(sets.NewString(paths...).List(), ", "))
+	}
+}
+
+// owns returns true if the managed fields contain the path or fields below it.
+func owns(owned *fieldpath.Set, path fieldpath.Path) bool {
+	if owned.Has(path) {
+		return true
+	}
+	node := owned
+	for _, pe := range path {
+		child, ok := node.Children.Get(pe)
+		if !ok {
+			return false
+		}
+		node = child
+	}
+	return !node.Empty()
+}
+
+// changedFields returns the paths of the fields that differ between the objects, descending into maps only.
+func changedFields(existing, toWrite runtime.Object) ([]fieldpath.Path, error) {
+	existingMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return nil, err
+	}
+	toWriteMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(toWrite)
+	if err != nil {
+		return nil, err
+	}
+	var changed []fieldpath.Path
+	diffMaps(fieldpath.Path{}, existingMap, toWriteMap, &changed)
+	return changed, nil
+}
+
+func diffMaps(prefix fieldpath.Path, a, b map[string]interface{}, changed *[]fieldpath.Path) {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	for k := range keys {
+		name := k
+		path := append(prefix.Copy(), fieldpath.PathElement{FieldName: &name})
+		aMap, aIsMap := a[k].(map[string]interface{})
+		bMap, bIsMap := b[k].(map[string]interface{})
+		if aIsMap && bIsMap {
+			diffMaps(path, aMap, bMap, changed)
+			continue
+		}
+		if !reflect.DeepEqual(a[k], b[k]) {
+			*changed = append(*changed, path)
+		}
+	}
+}
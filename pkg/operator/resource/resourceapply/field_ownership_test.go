@@ -0,0 +1,119 @@
+package resourceapply
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func managedFieldsEntry(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func fieldOwnershipConflicts(recorder events.InMemoryRecorder) []string {
+	var ret []string
+	for _, event := range recorder.Events() {
+		if event.Reason == "FieldOwnershipConflict" {
+			ret = append(ret, event.Message)
+		}
+	}
+	return ret
+}
+
+func TestReportFieldOwnershipConflictsConfigMap(t *testing.T) {
+	SetFieldManager("test-operator")
+	defer SetFieldManager("")
+
+	tests := []struct {
+		name              string
+		managedFields     []metav1.ManagedFieldsEntry
+		expectedConflicts []string
+	}{
+		{
+			name: "no conflict with own fields",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test-operator", `{"f:data":{".":{},"f:foo":{}}}`),
+			},
+		},
+		{
+			name: "no conflict with unchanged fields of others",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test-operator", `{"f:data":{".":{},"f:foo":{}}}`),
+				managedFieldsEntry("kubectl-edit", `{"f:metadata":{"f:labels":{"f:app":{}}}}`),
+			},
+		},
+		{
+			name: "conflict with changed fields of others",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test-operator", `{"f:data":{".":{},"f:foo":{}}}`),
+				managedFieldsEntry("kubectl-edit", `{"f:data":{"f:bar":{}},"f:metadata":{"f:labels":{"f:app":{}}}}`),
+			},
+			expectedConflicts: []string{`Updating ConfigMap/foo -n ns overwrites fields owned by "kubectl-edit": .data.bar`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", Labels: map[string]string{"app": "foo"}, ManagedFields: test.managedFields},
+				Data:       map[string]string{"foo": "old", "bar": "edited"},
+			}
+			required := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+				Data:       map[string]string{"foo": "new"},
+			}
+			recorder := events.NewInMemoryRecorder("test")
+			if _, _, err := ApplyConfigMap(context.TODO(), fake.NewSimpleClientset(existing).CoreV1(), recorder, required); err != nil {
+				t.Fatal(err)
+			}
+			conflicts := fieldOwnershipConflicts(recorder)
+			if strings.Join(conflicts, "\n") != strings.Join(test.expectedConflicts, "\n") {
+				t.Errorf("expected conflicts %q, got %q", test.expectedConflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestReportFieldOwnershipConflictsDeployment(t *testing.T) {
+	SetFieldManager("test-operator")
+	defer SetFieldManager("")
+
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", ManagedFields: []metav1.ManagedFieldsEntry{
+			managedFieldsEntry("test-operator", `{"f:spec":{"f:template":{"f:spec":{"f:containers":{}}}}}`),
+			managedFieldsEntry("horizontal-pod-autoscaler", `{"f:spec":{"f:replicas":{}}}`),
+		}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(5),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "foo", Image: "old"}}}},
+		},
+	}
+	required := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(2),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "foo", Image: "new"}}}},
+		},
+	}
+	recorder := events.NewInMemoryRecorder("test")
+	if _, _, err := ApplyDeployment(context.TODO(), fake.NewSimpleClientset(existing).AppsV1(), recorder, required, -1); err != nil {
+		t.Fatal(err)
+	}
+	conflicts := fieldOwnershipConflicts(recorder)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0], `"horizontal-pod-autoscaler": .spec.replicas`) {
+		t.Errorf("expected a conflict about the replicas, got %q", conflicts)
+	}
+}
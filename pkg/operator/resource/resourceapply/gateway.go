@@ -0,0 +1,180 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+)
+
+const GatewayAPIGroup = "gateway.networking.k8s.io"
+
+// ApplyGateway applies the Gateway API Gateway. The version is taken from the apiVersion of the required object.
+func ApplyGateway(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return applyGatewayAPIResource(ctx, client, recorder, "gateways", required)
+}
+
+// ApplyHTTPRoute applies the Gateway API HTTPRoute. The version is taken from the apiVersion of the required object.
+func ApplyHTTPRoute(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return applyGatewayAPIResource(ctx, client, recorder, "httproutes", required)
+}
+
+// ApplyReferenceGrant applies the Gateway API ReferenceGrant. The version is taken from the apiVersion of the required object.
+func ApplyReferenceGrant(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return applyGatewayAPIResource(ctx, client, recorder, "referencegrants", required)
+}
+
+func DeleteGateway(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return deleteGatewayAPIResource(ctx, client, recorder, "gateways", required)
+}
+
+func DeleteHTTPRoute(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return deleteGatewayAPIResource(ctx, client, recorder, "httproutes", required)
+}
+
+func DeleteReferenceGrant(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	return deleteGatewayAPIResource(ctx, client, recorder, "referencegrants", required)
+}
+
+func gatewayAPIResource(resource string, required *unstructured.Unstructured) (schema.GroupVersionResource, error) {
+	gvk := required.GroupVersionKind()
+	if gvk.Group != GatewayAPIGroup || len(gvk.Version) == 0 {
+		return schema.GroupVersionResource{}, fmt.Errorf("expected %s %s, got apiVersion %q", GatewayAPIGroup, resource, required.GetAPIVersion())
+	}
+	return schema.GroupVersionResource{Group: GatewayAPIGroup, Version: gvk.Version, Resource: resource}, nil
+}
+
+// applyGatewayAPIResource creates or updates the spec, labels, annotations and owner references of a Gateway API resource.
+// The status is owned by the gateway controller and never touched.
+func applyGatewayAPIResource(ctx context.Context, client dynamic.Interface, recorder events.Recorder, resource string, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	gvr, err := gatewayAPIResource(resource, required)
+	if err != nil {
+		return nil, false, err
+	}
+	kind := required.GetKind() + "." + gvr.Group + "/" + gvr.Version
+	namespace := required.GetNamespace()
+
+	existing, err := client.Resource(gvr).Namespace(namespace).Get(ctx, required.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		requiredCopy := resourcemerge.WithCleanLabelsAndAnnotations(required.DeepCopy()).(*unstructured.Unstructured)
+		newObj, createErr := client.Resource(gvr).Namespace(namespace).Create(ctx, requiredCopy, metav1.CreateOptions{})
+		if createErr != nil {
+			recorder.Warningf(required.GetKind()+"CreateFailed", "Failed to create %s: %v", kind, createErr)
+			return nil, true, createErr
+		}
+		recorder.Eventf(required.GetKind()+"Created", "Created %s because it was missing", kind)
+		return newObj, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	toUpdate, modified, err := ensureGenericSpec(required, existing, noDefaulting, requiredFieldsEquality{})
+	if err != nil {
+		return nil, false, err
+	}
+	if !modified {
+		toUpdate = existing.DeepCopy()
+	}
+	ensureUnstructuredObjectMeta(&modified, toUpdate, required)
+
+	if !modified {
+		return existing, false, nil
+	}
+
+	if klog.V(4).Enabled() {
+		klog.Infof("%s %q changes: %v", required.GetKind(), namespace+"/"+required.GetName(), JSONPatchNoError(existing, toUpdate))
+	}
+
+	newObj, err := client.Resource(gvr).Namespace(namespace).Update(ctx, toUpdate, metav1.UpdateOptions{})
+	if err != nil {
+		recorder.Warningf(required.GetKind()+"UpdateFailed", "Failed to update %s: %v", kind, err)
+		return nil, true, err
+	}
+
+	recorder.Eventf(required.GetKind()+"Updated", "Updated %s because it changed", kind)
+	return newObj, true, nil
+}
+
+// requiredFieldsEquality compares only the fields set in the required spec. The Gateway API defaults many fields, e.g.
+// the group and kind of parent and backend references and the path match of HTTPRoute rules, which differ between
+// API versions. Lists must have the same length, the fields of their items are compared the same way.
+type requiredFieldsEquality struct{}
+
+func (requiredFieldsEquality) DeepEqual(existing, required interface{}) bool {
+	return requiredFieldsEqual(existing, required)
+}
+
+func requiredFieldsEqual(existing, required interface{}) bool {
+	switch required := required.(type) {
+	case map[string]interface{}:
+		existing, ok := existing.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range required {
+			existingValue, ok := existing[k]
+			if !ok || !requiredFieldsEqual(existingValue, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		existing, ok := existing.([]interface{})
+		if !ok || len(existing) != len(required) {
+			return false
+		}
+		for i := range required {
+			if !requiredFieldsEqual(existing[i], required[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return equality.Semantic.DeepEqual(existing, required)
+	}
+}
+
+// ensureUnstructuredObjectMeta merges the labels, annotations and owner references like resourcemerge.EnsureObjectMeta.
+func ensureUnstructuredObjectMeta(modified *bool, existing, required *unstructured.Unstructured) {
+	metaModified := false
+	labels := existing.GetLabels()
+	resourcemerge.MergeMap(&metaModified, &labels, required.GetLabels())
+	annotations := existing.GetAnnotations()
+	resourcemerge.MergeMap(&metaModified, &annotations, required.GetAnnotations())
+	ownerReferences := existing.GetOwnerReferences()
+	resourcemerge.MergeOwnerRefs(&metaModified, &ownerReferences, required.GetOwnerReferences())
+	if !metaModified {
+		return
+	}
+
+	*modified = true
+	existing.SetLabels(labels)
+	existing.SetAnnotations(annotations)
+	existing.SetOwnerReferences(ownerReferences)
+}
+
+func deleteGatewayAPIResource(ctx context.Context, client dynamic.Interface, recorder events.Recorder, resource string, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	gvr, err := gatewayAPIResource(resource, required)
+	if err != nil {
+		return nil, false, err
+	}
+	err = client.Resource(gvr).Namespace(required.GetNamespace()).Delete(ctx, required.GetName(), metav1.DeleteOptions{})
+	if err != nil && errors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	reportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
@@ -0,0 +1,197 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const fakeHTTPRoute = `apiVersion: gateway.networking.k8s.io/v1beta1
+kind: HTTPRoute
+metadata:
+  name: console
+  namespace: openshift-console
+  labels:
+    app: console
+spec:
+  parentRefs:
+  - name: default
+    namespace: openshift-ingress
+  rules:
+  - backendRefs:
+    - name: console
+      port: 443
+`
+
+func TestApplyHTTPRoute(t *testing.T) {
+	tests := []struct {
+		name            string
+		existing        string
+		expectedActions []string
+		expectModified  bool
+		expectStatus    bool
+	}{
+		{
+			name:            "create",
+			expectedActions: []string{"get", "create"},
+			expectModified:  true,
+		},
+		{
+			name:            "no change",
+			existing:        fakeHTTPRoute,
+			expectedActions: []string{"get"},
+		},
+		{
+			name: "server defaulted fields",
+			existing: `apiVersion: gateway.networking.k8s.io/v1beta1
+kind: HTTPRoute
+metadata:
+  name: console
+  namespace: openshift-console
+  labels:
+    app: console
+spec:
+  parentRefs:
+  - group: gateway.networking.k8s.io
+    kind: Gateway
+    name: default
+    namespace: openshift-ingress
+  rules:
+  - backendRefs:
+    - group: ""
+      kind: Service
+      name: console
+      port: 443
+      weight: 1
+    matches:
+    - path:
+        type: PathPrefix
+        value: /
+`,
+			expectedActions: []string{"get"},
+		},
+		{
+			name: "list item removed",
+			existing: `apiVersion: gateway.networking.k8s.io/v1beta1
+kind: HTTPRoute
+metadata:
+  name: console
+  namespace: openshift-console
+  labels:
+    app: console
+spec:
+  parentRefs:
+  - name: default
+    namespace: openshift-ingress
+  - name: other
+    namespace: openshift-ingress
+  rules:
+  - backendRefs:
+    - name: console
+      port: 443
+`,
+			expectedActions: []string{"get", "update"},
+			expectModified:  true,
+		},
+		{
+			name: "spec changed",
+			existing: `apiVersion: gateway.networking.k8s.io/v1beta1
+kind: HTTPRoute
+metadata:
+  name: console
+  namespace: openshift-console
+  labels:
+    app: console
+spec:
+  parentRefs:
+  - name: default
+    namespace: openshift-ingress
+  rules:
+  - backendRefs:
+    - name: console
+      port: 8443
+status:
+  parents: []
+`,
+			expectedActions: []string{"get", "update"},
+			expectModified:  true,
+			expectStatus:    true,
+		},
+		{
+			name: "labels changed",
+			existing: `apiVersion: gateway.networking.k8s.io/v1beta1
+kind: HTTPRoute
+metadata:
+  name: console
+  namespace: openshift-console
+  labels:
+    other: label
+spec:
+  parentRefs:
+  - name: default
+    namespace: openshift-ingress
+  rules:
+  - backendRefs:
+    - name: console
+      port: 443
+`,
+			expectedActions: []string{"get", "update"},
+			expectModified:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dynamicScheme := runtime.NewScheme()
+			dynamicScheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: GatewayAPIGroup, Version: "v1beta1", Kind: "HTTPRoute"}, &unstructured.Unstructured{})
+			objs := []runtime.Object{}
+			if len(test.existing) > 0 {
+				objs = append(objs, resourceread.ReadUnstructuredOrDie([]byte(test.existing)))
+			}
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(dynamicScheme, objs...)
+			recorder := events.NewInMemoryRecorder("gateway-test")
+
+			required := resourceread.ReadUnstructuredOrDie([]byte(fakeHTTPRoute))
+			_, modified, err := ApplyKnownUnstructured(context.TODO(), dynamicClient, recorder, required)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if modified != test.expectModified {
+				t.Errorf("expected modified %v, got %v", test.expectModified, modified)
+			}
+
+			actions := dynamicClient.Actions()
+			if len(actions) != len(test.expectedActions) {
+				t.Fatalf("expected actions %v, got %v", test.expectedActions, actions)
+			}
+			for i, verb := range test.expectedActions {
+				if actions[i].GetVerb() != verb {
+					t.Errorf("expected action %d to be %s, got %v", i, verb, actions[i])
+				}
+			}
+			if !test.expectModified {
+				return
+			}
+
+			actual := actions[len(actions)-1].(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+			rules, _, _ := unstructured.NestedSlice(actual.Object, "spec", "rules")
+			if len(rules) != 1 || rules[0].(map[string]interface{})["backendRefs"].([]interface{})[0].(map[string]interface{})["port"] != int64(443) {
+				t.Errorf("expected the required spec, got %v", actual.Object["spec"])
+			}
+			if actual.GetLabels()["app"] != "console" {
+				t.Errorf("expected the required labels, got %v", actual.GetLabels())
+			}
+			if _, hasStatus := actual.Object["status"]; hasStatus != test.expectStatus {
+				t.Errorf("expected the existing status to be kept, got %v", actual.Object["status"])
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -199,6 +200,12 @@ func ApplyDirectly(ctx context.Context, clients *ClientHolder, recorder events.R
 			} else {
 				result.Result, result.Changed, result.Error = ApplyCSIDriver(ctx, clients.kubeClient.StorageV1(), recorder, t)
 			}
+		case *schedulingv1.PriorityClass:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				result.Result, result.Changed, result.Error = ApplyPriorityClass(ctx, clients.kubeClient.SchedulingV1(), recorder, t)
+			}
 		case *migrationv1alpha1.StorageVersionMigration:
 			if clients.migrationClient == nil {
 				result.Error = fmt.Errorf("missing migrationClient")
@@ -328,6 +335,12 @@ func DeleteAll(ctx context.Context, clients *ClientHolder, recorder events.Recor
 			} else {
 				_, result.Changed, result.Error = DeleteCSIDriver(ctx, clients.kubeClient.StorageV1(), recorder, t)
 			}
+		case *schedulingv1.PriorityClass:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeletePriorityClass(ctx, clients.kubeClient.SchedulingV1(), recorder, t)
+			}
 		case *migrationv1alpha1.StorageVersionMigration:
 			if clients.migrationClient == nil {
 				result.Error = fmt.Errorf("missing migrationClient")
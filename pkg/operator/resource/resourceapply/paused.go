@@ -0,0 +1,48 @@
+package resourceapply
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+)
+
+// PausedAnnotation set to "true" on an object makes the apply functions leave the object as it is, e.g. to debug an
+// operand with a manually modified deployment. Controllers report the paused objects in their Progressing condition.
+// Removing the annotation resumes the reconciliation.
+const PausedAnnotation = "operator.openshift.io/paused"
+
+// IsPaused returns true if the reconciliation of the object is paused by the PausedAnnotation.
+func IsPaused(obj metav1.Object) bool {
+	return obj != nil && obj.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// skipPaused returns true if the existing object is paused and must not be updated.
+func skipPaused(existing runtime.Object) bool {
+	existingMeta, ok := existing.(metav1.Object)
+	if !ok || !IsPaused(existingMeta) {
+		return false
+	}
+	klog.V(2).Infof("Skipping the update of paused %s", resourcehelper.FormatResourceForCLIWithNamespace(existing))
+	return true
+}
+
+// PausedMessage returns a message listing the paused objects, or an empty string if none is paused.
+func PausedMessage(objs ...runtime.Object) string {
+	var paused []string
+	for _, obj := range objs {
+		if objMeta, ok := obj.(metav1.Object); ok && IsPaused(objMeta) {
+			paused = append(paused, resourcehelper.FormatResourceForCLIWithNamespace(obj))
+		}
+	}
+	if len(paused) == 0 {
+		return ""
+	}
+	sort.Strings(paused)
+	return fmt.Sprintf("reconciliation is paused by the %s annotation of %s", PausedAnnotation, strings.Join(paused, ", "))
+}
@@ -0,0 +1,97 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyPaused(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", Annotations: map[string]string{PausedAnnotation: "true"}},
+		Data:       map[string]string{"foo": "debug"},
+	}
+	required := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+		Data:       map[string]string{"foo": "bar"},
+	}
+	client := fake.NewSimpleClientset(existing)
+	actual, modified, err := ApplyConfigMap(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified {
+		t.Errorf("expected the paused config map not to be modified")
+	}
+	if actual.Data["foo"] != "debug" {
+		t.Errorf("expected the existing config map, got %v", actual.Data)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("unexpected action %v", action)
+		}
+	}
+
+	existing.Annotations[PausedAnnotation] = "false"
+	client = fake.NewSimpleClientset(existing)
+	actual, modified, err = ApplyConfigMap(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified || actual.Data["foo"] != "bar" {
+		t.Errorf("expected the config map to be updated once resumed, got %v", actual.Data)
+	}
+}
+
+func TestApplyDeploymentPaused(t *testing.T) {
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", Annotations: map[string]string{PausedAnnotation: "true"}},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "foo", Image: "debug"}}}},
+		},
+	}
+	required := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "foo", Image: "new"}}}},
+		},
+	}
+	client := fake.NewSimpleClientset(existing)
+	actual, modified, err := ApplyDeployment(context.TODO(), client.AppsV1(), events.NewInMemoryRecorder("test"), required, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified || actual.Spec.Template.Spec.Containers[0].Image != "debug" {
+		t.Errorf("expected the paused deployment not to be modified, got image %q", actual.Spec.Template.Spec.Containers[0].Image)
+	}
+}
+
+func TestPausedMessage(t *testing.T) {
+	paused := func(kind runtime.Object, name string) runtime.Object {
+		obj := kind.DeepCopyObject()
+		obj.(metav1.Object).SetNamespace("ns")
+		obj.(metav1.Object).SetName(name)
+		obj.(metav1.Object).SetAnnotations(map[string]string{PausedAnnotation: "true"})
+		return obj
+	}
+
+	if msg := PausedMessage(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "active"}}); len(msg) != 0 {
+		t.Errorf("expected no message, got %q", msg)
+	}
+	msg := PausedMessage(
+		paused(&corev1.Secret{}, "b"),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "active"}},
+		paused(&appsv1.Deployment{}, "a"),
+	)
+	expected := "reconciliation is paused by the operator.openshift.io/paused annotation of Deployment.apps/a -n ns, Secret/b -n ns"
+	if msg != expected {
+		t.Errorf("expected %q, got %q", expected, msg)
+	}
+}
@@ -0,0 +1,121 @@
+package resourceapply
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// maxAnnotationValueLength is the length pod template annotation values are shortened to in the changes, the values
+// are mostly hashes of inputs which are recognizable by their prefix.
+const maxAnnotationValueLength = 16
+
+// podTemplateChanges explains why an update of the pod template rolls out new pods. It reports the changes of images,
+// env vars, labels and annotations. Env var values are never reported because they might be confidential. Changes of
+// fields defaulted by the server are ignored, so a template changing only in them has no changes.
+func podTemplateChanges(existing, required corev1.PodTemplateSpec) []string {
+	var changes []string
+	changes = append(changes, mapChanges("pod template label", existing.Labels, required.Labels, false)...)
+	changes = append(changes, mapChanges("pod template annotation", existing.Annotations, required.Annotations, true)...)
+	changes = append(changes, containerChanges("init container", existing.Spec.InitContainers, required.Spec.InitContainers)...)
+	changes = append(changes, containerChanges("container", existing.Spec.Containers, required.Spec.Containers)...)
+	return changes
+}
+
+func mapChanges(what string, existing, required map[string]string, shorten bool) []string {
+	format := func(value string) string {
+		if shorten && len(value) > maxAnnotationValueLength {
+			return value[:maxAnnotationValueLength] + "..."
+		}
+		return value
+	}
+	var changes []string
+	for _, key := range sets.StringKeySet(existing).Union(sets.StringKeySet(required)).List() {
+		existingValue, existingOK := existing[key]
+		requiredValue, requiredOK := required[key]
+		switch {
+		case !existingOK:
+			changes = append(changes, fmt.Sprintf("%s %q added", what, key))
+		case !requiredOK:
+			changes = append(changes, fmt.Sprintf("%s %q removed", what, key))
+		case existingValue != requiredValue:
+			changes = append(changes, fmt.Sprintf("%s %q changed from %q to %q", what, key, format(existingValue), format(requiredValue)))
+		}
+	}
+	return changes
+}
+
+func containerChanges(what string, existing, required []corev1.Container) []string {
+	existingByName := map[string]corev1.Container{}
+	for _, container := range existing {
+		existingByName[container.Name] = container
+	}
+	requiredNames := sets.NewString()
+
+	var changes []string
+	for _, container := range required {
+		requiredNames.Insert(container.Name)
+		existingContainer, ok := existingByName[container.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s %q added", what, container.Name))
+			continue
+		}
+		if existingContainer.Image != container.Image {
+			changes = append(changes, fmt.Sprintf("%s %q image changed from %q to %q", what, container.Name, existingContainer.Image, container.Image))
+		}
+		for _, change := range envChanges(existingContainer.Env, container.Env) {
+			changes = append(changes, fmt.Sprintf("%s %q %s", what, container.Name, change))
+		}
+	}
+
+	var removed []string
+	for name := range existingByName {
+		if !requiredNames.Has(name) {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		changes = append(changes, fmt.Sprintf("%s %q removed", what, name))
+	}
+	return changes
+}
+
+func envChanges(existing, required []corev1.EnvVar) []string {
+	existingByName := map[string]corev1.EnvVar{}
+	for _, env := range existing {
+		existingByName[env.Name] = env
+	}
+	requiredByName := map[string]corev1.EnvVar{}
+	for _, env := range required {
+		requiredByName[env.Name] = env
+	}
+
+	var changes []string
+	for _, name := range sets.StringKeySet(existingByName).Union(sets.StringKeySet(requiredByName)).List() {
+		existingEnv, existingOK := existingByName[name]
+		requiredEnv, requiredOK := requiredByName[name]
+		switch {
+		case !existingOK:
+			changes = append(changes, fmt.Sprintf("env var %q added", name))
+		case !requiredOK:
+			changes = append(changes, fmt.Sprintf("env var %q removed", name))
+		case existingEnv.Value != requiredEnv.Value || !equality.Semantic.DeepEqual(defaultedEnvVarSource(existingEnv.ValueFrom), defaultedEnvVarSource(requiredEnv.ValueFrom)):
+			changes = append(changes, fmt.Sprintf("env var %q changed", name))
+		}
+	}
+	return changes
+}
+
+// defaultedEnvVarSource sets the field ref api version the server defaults.
+func defaultedEnvVarSource(source *corev1.EnvVarSource) *corev1.EnvVarSource {
+	if source == nil || source.FieldRef == nil || len(source.FieldRef.APIVersion) > 0 {
+		return source
+	}
+	source = source.DeepCopy()
+	source.FieldRef.APIVersion = "v1"
+	return source
+}
@@ -0,0 +1,78 @@
+package resourceapply
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodTemplateChanges(t *testing.T) {
+	template := func(annotations map[string]string, containers ...corev1.Container) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}, Annotations: annotations},
+			Spec:       corev1.PodSpec{Containers: containers},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing corev1.PodTemplateSpec
+		required corev1.PodTemplateSpec
+		expected []string
+	}{
+		{
+			name:     "no changes",
+			existing: template(nil, corev1.Container{Name: "a", Image: "img:1", TerminationMessagePath: "/dev/termination-log"}),
+			required: template(nil, corev1.Container{Name: "a", Image: "img:1"}),
+		},
+		{
+			name:     "image changed",
+			existing: template(nil, corev1.Container{Name: "a", Image: "img:1"}),
+			required: template(nil, corev1.Container{Name: "a", Image: "img:2"}),
+			expected: []string{`container "a" image changed from "img:1" to "img:2"`},
+		},
+		{
+			name: "env changed without values",
+			existing: template(nil, corev1.Container{Name: "a", Env: []corev1.EnvVar{
+				{Name: "SECRET", Value: "old"},
+				{Name: "REMOVED", Value: "x"},
+				{Name: "NODE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "spec.nodeName"}}},
+			}}),
+			required: template(nil, corev1.Container{Name: "a", Env: []corev1.EnvVar{
+				{Name: "SECRET", Value: "new"},
+				{Name: "ADDED", Value: "y"},
+				{Name: "NODE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+			}}),
+			expected: []string{
+				`container "a" env var "ADDED" added`,
+				`container "a" env var "REMOVED" removed`,
+				`container "a" env var "SECRET" changed`,
+			},
+		},
+		{
+			name:     "hash annotation changed",
+			existing: template(map[string]string{"operator.openshift.io/config-hash": "0123456789abcdef0123"}),
+			required: template(map[string]string{"operator.openshift.io/config-hash": "fedcba9876543210fedc", "new": "x"}),
+			expected: []string{
+				`pod template annotation "new" added`,
+				`pod template annotation "operator.openshift.io/config-hash" changed from "0123456789abcdef..." to "fedcba9876543210..."`,
+			},
+		},
+		{
+			name:     "containers added and removed",
+			existing: template(nil, corev1.Container{Name: "a"}),
+			required: template(nil, corev1.Container{Name: "b"}),
+			expected: []string{`container "b" added`, `container "a" removed`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := podTemplateChanges(test.existing, test.required)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
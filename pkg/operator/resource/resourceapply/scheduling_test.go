@@ -0,0 +1,102 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyPriorityClass(t *testing.T) {
+	preemptLowerPriority := corev1.PreemptLowerPriority
+	preemptNever := corev1.PreemptNever
+
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+		input    *schedulingv1.PriorityClass
+
+		expectedModified bool
+		expectedActions  []string
+	}{
+		{
+			name:             "create",
+			input:            &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000},
+			expectedModified: true,
+			expectedActions:  []string{"get", "create"},
+		},
+		{
+			name: "no change with defaulted preemption policy",
+			existing: []runtime.Object{
+				&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000, PreemptionPolicy: &preemptLowerPriority},
+			},
+			input:           &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000},
+			expectedActions: []string{"get"},
+		},
+		{
+			name: "update description",
+			existing: []runtime.Object{
+				&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000, Description: "old"},
+			},
+			input:            &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000, Description: "new"},
+			expectedModified: true,
+			expectedActions:  []string{"get", "update"},
+		},
+		{
+			name: "re-create on value change",
+			existing: []runtime.Object{
+				&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000},
+			},
+			input:            &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 2000},
+			expectedModified: true,
+			expectedActions:  []string{"get", "delete", "create"},
+		},
+		{
+			name: "re-create on preemption policy change",
+			existing: []runtime.Object{
+				&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000, PreemptionPolicy: &preemptLowerPriority},
+			},
+			input:            &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Value: 1000, PreemptionPolicy: &preemptNever},
+			expectedModified: true,
+			expectedActions:  []string{"get", "delete", "create"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(test.existing...)
+			_, actualModified, err := ApplyPriorityClass(context.TODO(), client.SchedulingV1(), events.NewInMemoryRecorder("test"), test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.expectedModified != actualModified {
+				t.Errorf("expected modified %v, got %v", test.expectedModified, actualModified)
+			}
+			actions := client.Actions()
+			if len(actions) != len(test.expectedActions) {
+				t.Fatal(spew.Sdump(actions))
+			}
+			for i, verb := range test.expectedActions {
+				if !actions[i].Matches(verb, "priorityclasses") {
+					t.Error(spew.Sdump(actions))
+				}
+			}
+			if test.expectedModified {
+				actual, err := client.SchedulingV1().PriorityClasses().Get(context.TODO(), "foo", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if actual.Value != test.input.Value || actual.Description != test.input.Description {
+					t.Errorf("unexpected priority class: %s", spew.Sdump(actual))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package resourceapply
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+)
+
+// SecretDataHashAnnotation is the SHA-256 hash of the data applied to a secret, see SecretDataHash. It lets
+// ApplySecret and consumers of the secret tell whether its content changed without comparing the content itself.
+const SecretDataHashAnnotation = "operator.openshift.io/secret-data-hash"
+
+// SecretDataHash returns the hex encoded SHA-256 hash of the secret data. Keys are hashed in sorted order and
+// length-prefixed, so that no two different data maps have the same hash. The content is streamed into the hash,
+// it is not copied.
+func SecretDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hasher := sha256.New()
+	length := make([]byte, 8)
+	for _, key := range keys {
+		binary.BigEndian.PutUint64(length, uint64(len(key)))
+		hasher.Write(length)
+		hasher.Write([]byte(key))
+		binary.BigEndian.PutUint64(length, uint64(len(data[key])))
+		hasher.Write(length)
+		hasher.Write(data[key])
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// secretDataHashesEqual compares the hashes in constant time.
+func secretDataHashesEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// secretDataShapeEqual returns whether the data have the same keys and values of the same lengths, without comparing
+// the content.
+func secretDataShapeEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		other, ok := b[key]
+		if !ok || len(other) != len(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// secretDataKeys returns the entries of the data with the keys of required, sharing the content with data.
+func secretDataKeys(data map[string][]byte, required map[string][]byte) map[string][]byte {
+	ret := make(map[string][]byte, len(required))
+	for key := range required {
+		if value, ok := data[key]; ok {
+			ret[key] = value
+		}
+	}
+	return ret
+}
@@ -0,0 +1,104 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestSecretDataHash(t *testing.T) {
+	if SecretDataHash(nil) != SecretDataHash(map[string][]byte{}) {
+		t.Error("expected nil and empty data to have the same hash")
+	}
+	if SecretDataHash(map[string][]byte{"a": []byte("bc")}) == SecretDataHash(map[string][]byte{"ab": []byte("c")}) {
+		t.Error("expected different data to have different hashes")
+	}
+	if SecretDataHash(map[string][]byte{"a": []byte("1"), "b": []byte("2")}) != SecretDataHash(map[string][]byte{"b": []byte("2"), "a": []byte("1")}) {
+		t.Error("expected the hash to not depend on the order of the keys")
+	}
+}
+
+func TestApplySecretDataHash(t *testing.T) {
+	data := map[string][]byte{"tls.crt": []byte("cert")}
+	required := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		existing        *corev1.Secret
+		expectedChanged bool
+	}{
+		{
+			name: "secret applied without the annotation is not updated only to add it",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       data,
+			},
+		},
+		{
+			name:     "secret with the same hash is not updated",
+			existing: withSecretDataHash(required()),
+		},
+		{
+			name: "data changed by others is restored",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", Annotations: map[string]string{SecretDataHashAnnotation: SecretDataHash(data)}},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{"tls.crt": []byte("other")},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "data is not hashed when the annotation matches",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", Annotations: map[string]string{SecretDataHashAnnotation: SecretDataHash(data)}},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{"tls.crt": []byte("CERT")},
+			},
+		},
+		{
+			name: "keys added by others are removed",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", Annotations: map[string]string{SecretDataHashAnnotation: SecretDataHash(data)}},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "data with a different hash is updated",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", Annotations: map[string]string{SecretDataHashAnnotation: "outdated"}},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       data,
+			},
+			expectedChanged: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tc.existing)
+			actual, changed, err := ApplySecret(context.TODO(), client.CoreV1(), events.NewInMemoryRecorder("test"), required())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tc.expectedChanged {
+				t.Errorf("expected changed %t, got %t", tc.expectedChanged, changed)
+			}
+			if changed && actual.Annotations[SecretDataHashAnnotation] != SecretDataHash(data) {
+				t.Errorf("expected the updated secret to carry the data hash, got %v", actual.Annotations)
+			}
+		})
+	}
+}
@@ -20,6 +20,12 @@ func ApplyKnownUnstructured(ctx context.Context, client dynamic.Interface, recor
 		return ApplyPrometheusRule(ctx, client, recorder, obj)
 	case schema.GroupKind{Group: "snapshot.storage.k8s.io", Kind: "VolumeSnapshotClass"}:
 		return ApplyVolumeSnapshotClass(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "Gateway"}:
+		return ApplyGateway(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "HTTPRoute"}:
+		return ApplyHTTPRoute(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "ReferenceGrant"}:
+		return ApplyReferenceGrant(ctx, client, recorder, obj)
 
 	}
 
@@ -35,6 +41,12 @@ func DeleteKnownUnstructured(ctx context.Context, client dynamic.Interface, reco
 		return DeletePrometheusRule(ctx, client, recorder, obj)
 	case schema.GroupKind{Group: "snapshot.storage.k8s.io", Kind: "VolumeSnapshotClass"}:
 		return DeleteVolumeSnapshotClass(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "Gateway"}:
+		return DeleteGateway(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "HTTPRoute"}:
+		return DeleteHTTPRoute(ctx, client, recorder, obj)
+	case schema.GroupKind{Group: GatewayAPIGroup, Kind: "ReferenceGrant"}:
+		return DeleteReferenceGrant(ctx, client, recorder, obj)
 
 	}
 
@@ -0,0 +1,154 @@
+package resourcemerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// DeprecatedConfigField maps a deprecated config field to the field that replaced it.
+type DeprecatedConfigField struct {
+	// DeprecatedPath is the path of the deprecated field, e.g. []string{"servingInfo", "certFile"}.
+	DeprecatedPath []string
+	// ReplacementPath is the path of the field replacing the deprecated one. If empty, the deprecated field is
+	// dropped and only reported.
+	ReplacementPath []string
+}
+
+func (f DeprecatedConfigField) String() string {
+	if len(f.ReplacementPath) == 0 {
+		return strings.Join(f.DeprecatedPath, ".")
+	}
+	return fmt.Sprintf("%s (use %s)", strings.Join(f.DeprecatedPath, "."), strings.Join(f.ReplacementPath, "."))
+}
+
+// TranslateDeprecatedConfig moves every deprecated field found in config to its replacement path.
+// If the replacement field is already set, it wins and the deprecated value is dropped.
+// It modifies config and returns the deprecated fields that were found.
+func TranslateDeprecatedConfig(config map[string]interface{}, deprecations []DeprecatedConfigField) ([]DeprecatedConfigField, error) {
+	var found []DeprecatedConfigField
+	for _, deprecation := range deprecations {
+		if len(deprecation.DeprecatedPath) == 0 {
+			continue
+		}
+		val, ok, err := unstructured.NestedFieldNoCopy(config, deprecation.DeprecatedPath...)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %v from config, %v", strings.Join(deprecation.DeprecatedPath, "."), err)
+		}
+		if !ok {
+			continue
+		}
+		found = append(found, deprecation)
+		unstructured.RemoveNestedField(config, deprecation.DeprecatedPath...)
+
+		if len(deprecation.ReplacementPath) == 0 {
+			continue
+		}
+		_, replacementSet, err := unstructured.NestedFieldNoCopy(config, deprecation.ReplacementPath...)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %v from config, %v", strings.Join(deprecation.ReplacementPath, "."), err)
+		}
+		if replacementSet {
+			continue
+		}
+		if err := unstructured.SetNestedField(config, val, deprecation.ReplacementPath...); err != nil {
+			return nil, fmt.Errorf("error setting %v in config, %v", strings.Join(deprecation.ReplacementPath, "."), err)
+		}
+	}
+	return found, nil
+}
+
+// DeprecatedConfigMerger is MergeProcessConfig which translates deprecated fields in every config layer before
+// merging, so that overrides using old field names are not silently dropped when the operand config schema evolves.
+// Operators merge the config on every sync, so they keep one merger per operand config, e.g. in their controller. It
+// emits a warning event listing the deprecated fields in use when that list changes.
+type DeprecatedConfigMerger struct {
+	recorder     events.Recorder
+	deprecations []DeprecatedConfigField
+	specialCases map[string]MergeFunc
+
+	lock sync.Mutex
+	// reported are the deprecated fields in use last reported.
+	reported string
+}
+
+// NewDeprecatedConfigMerger returns a merger translating the deprecated fields. The recorder may be nil, then no
+// events are emitted.
+func NewDeprecatedConfigMerger(recorder events.Recorder, deprecations []DeprecatedConfigField, specialCases map[string]MergeFunc) *DeprecatedConfigMerger {
+	return &DeprecatedConfigMerger{
+		recorder:     recorder,
+		deprecations: deprecations,
+		specialCases: specialCases,
+	}
+}
+
+// Merge translates the deprecated fields of the config layers and merges them, see MergeProcessConfig.
+func (m *DeprecatedConfigMerger) Merge(configYAMLs ...[]byte) ([]byte, error) {
+	translatedYAMLs := make([][]byte, 0, len(configYAMLs))
+	used := map[string]bool{}
+	for _, configYAML := range configYAMLs {
+		if len(configYAML) == 0 {
+			translatedYAMLs = append(translatedYAMLs, configYAML)
+			continue
+		}
+		configJSON, err := kyaml.ToJSON(configYAML)
+		if err != nil {
+			// maybe it's just json
+			configJSON = configYAML
+		}
+		config := map[string]interface{}{}
+		if err := json.NewDecoder(bytes.NewBuffer(configJSON)).Decode(&config); err != nil {
+			return nil, err
+		}
+		found, err := TranslateDeprecatedConfig(config, m.deprecations)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			translatedYAMLs = append(translatedYAMLs, configYAML)
+			continue
+		}
+		for _, f := range found {
+			used[f.String()] = true
+		}
+		translated, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &unstructured.Unstructured{Object: config})
+		if err != nil {
+			return nil, err
+		}
+		translatedYAMLs = append(translatedYAMLs, translated)
+	}
+
+	if m.recorder != nil {
+		m.reportDeprecatedFields(used)
+	}
+
+	return MergeProcessConfig(m.specialCases, translatedYAMLs...)
+}
+
+func (m *DeprecatedConfigMerger) reportDeprecatedFields(used map[string]bool) {
+	fields := make([]string, 0, len(used))
+	for f := range used {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	joined := strings.Join(fields, ", ")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.reported == joined {
+		return
+	}
+	m.reported = joined
+	if len(fields) > 0 {
+		m.recorder.Warningf("DeprecatedConfigFields", "The configuration uses deprecated fields: %s", joined)
+	}
+}
@@ -0,0 +1,101 @@
+package resourcemerge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestTranslateDeprecatedConfig(t *testing.T) {
+	deprecations := []DeprecatedConfigField{
+		{DeprecatedPath: []string{"servingInfo", "cert"}, ReplacementPath: []string{"servingInfo", "certFile"}},
+		{DeprecatedPath: []string{"oldFlag"}},
+	}
+
+	tests := []struct {
+		name          string
+		config        map[string]interface{}
+		expected      map[string]interface{}
+		expectedFound int
+	}{
+		{
+			name:     "nothing deprecated",
+			config:   map[string]interface{}{"servingInfo": map[string]interface{}{"certFile": "a"}},
+			expected: map[string]interface{}{"servingInfo": map[string]interface{}{"certFile": "a"}},
+		},
+		{
+			name:          "moved to replacement",
+			config:        map[string]interface{}{"servingInfo": map[string]interface{}{"cert": "a"}, "oldFlag": true},
+			expected:      map[string]interface{}{"servingInfo": map[string]interface{}{"certFile": "a"}},
+			expectedFound: 2,
+		},
+		{
+			name:          "replacement wins",
+			config:        map[string]interface{}{"servingInfo": map[string]interface{}{"cert": "a", "certFile": "b"}},
+			expected:      map[string]interface{}{"servingInfo": map[string]interface{}{"certFile": "b"}},
+			expectedFound: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, err := TranslateDeprecatedConfig(test.config, deprecations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found) != test.expectedFound {
+				t.Errorf("expected %d deprecated fields, got %v", test.expectedFound, found)
+			}
+			if !reflect.DeepEqual(test.expected, test.config) {
+				t.Error(diff.ObjectDiff(test.expected, test.config))
+			}
+		})
+	}
+}
+
+func TestDeprecatedConfigMerger(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test-merge-deprecations")
+	deprecations := []DeprecatedConfigField{
+		{DeprecatedPath: []string{"servingInfo", "cert"}, ReplacementPath: []string{"servingInfo", "certFile"}},
+	}
+
+	merger := NewDeprecatedConfigMerger(recorder, deprecations, nil)
+	actual, err := merger.Merge(
+		[]byte(`{"servingInfo":{"certFile":"default"}}`),
+		[]byte(`servingInfo:
+  cert: override
+`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"servingInfo":{"certFile":"override"}}`; strings.TrimSpace(string(actual)) != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	if len(recorder.Events()) != 1 || recorder.Events()[0].Reason != "DeprecatedConfigFields" {
+		t.Errorf("expected a DeprecatedConfigFields event, got %v", recorder.Events())
+	}
+
+	// merging the same config again, like every sync does, does not repeat the event
+	if _, err := merger.Merge([]byte(`servingInfo:
+  cert: override
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Errorf("expected the DeprecatedConfigFields event not to repeat, got %v", recorder.Events())
+	}
+
+	// another merger, e.g. of another operator with the same component name, reports on its own
+	if _, err := NewDeprecatedConfigMerger(recorder, deprecations, nil).Merge([]byte(`servingInfo:
+  cert: override
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("expected a DeprecatedConfigFields event of the other merger, got %v", recorder.Events())
+	}
+}
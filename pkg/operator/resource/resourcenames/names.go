@@ -0,0 +1,67 @@
+package resourcenames
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxNameLength is the maximum length of most object names (DNS-1123 subdomain).
+	MaxNameLength = 253
+	// MaxLabelLength is the maximum length of label values, DNS-1123 labels and the name part of qualified
+	// annotation keys. Services, namespaces and other label-like names are limited to it.
+	MaxLabelLength = 63
+
+	// hashSuffixLength is the number of hex characters of the sha256 sum appended to truncated names.
+	hashSuffixLength = 8
+)
+
+// TruncateWithHash returns name unchanged if it fits into maxLength. Otherwise the name is truncated
+// and suffixed with "-" and a short hash of the full input, so that two long names sharing a common prefix
+// still result in different outputs. The result is never longer than maxLength.
+func TruncateWithHash(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	suffix := shortHash(name)
+	if maxLength <= len(suffix)+1 {
+		return suffix[:maxLength]
+	}
+	prefix := strings.TrimRight(name[:maxLength-len(suffix)-1], "-.")
+	return prefix + "-" + suffix
+}
+
+// PrefixedHash returns prefix+input if it fits into maxLength. Otherwise input is replaced by the hex encoded
+// sha256 sum of it and the result is cut at maxLength. This is the format used for input hash annotation keys
+// like "operator.openshift.io/dep-<input>".
+func PrefixedHash(prefix, input string, maxLength int) string {
+	name := prefix + input
+	if len(name) <= maxLength {
+		return name
+	}
+	name = fmt.Sprintf("%s%x", prefix, sha256.Sum256([]byte(input)))
+	if len(name) > maxLength {
+		name = name[:maxLength]
+	}
+	return name
+}
+
+// WithSuffix joins name and suffix with a "-", truncating name (see TruncateWithHash) so that the suffix is always
+// kept intact and the result fits into maxLength. This is useful for names derived from a base name, e.g.
+// "<name>-<revision>" or "<name>-<target>".
+func WithSuffix(name, suffix string, maxLength int) string {
+	full := name + "-" + suffix
+	if len(full) <= maxLength {
+		return full
+	}
+	available := maxLength - len(suffix) - 1
+	if available <= 0 {
+		return TruncateWithHash(full, maxLength)
+	}
+	return TruncateWithHash(name, available) + "-" + suffix
+}
+
+func shortHash(s string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))[:hashSuffixLength]
+}
@@ -0,0 +1,55 @@
+package resourcenames
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateWithHash(t *testing.T) {
+	long := strings.Repeat("a", 70)
+
+	if got := TruncateWithHash("short", MaxLabelLength); got != "short" {
+		t.Errorf("expected short name to be unchanged, got %q", got)
+	}
+
+	got := TruncateWithHash(long, MaxLabelLength)
+	if len(got) != MaxLabelLength {
+		t.Errorf("expected length %d, got %d (%q)", MaxLabelLength, len(got), got)
+	}
+	if got != TruncateWithHash(long, MaxLabelLength) {
+		t.Errorf("expected deterministic output")
+	}
+	if other := TruncateWithHash(long+"b", MaxLabelLength); other == got {
+		t.Errorf("expected different inputs with common prefix to differ, both are %q", got)
+	}
+	if got := TruncateWithHash(strings.Repeat("a", 20)+"-"+strings.Repeat("b", 20), 20); strings.Contains(got, "--") {
+		t.Errorf("expected no double dash, got %q", got)
+	}
+	if got := TruncateWithHash(long, 5); len(got) != 5 {
+		t.Errorf("expected length 5, got %q", got)
+	}
+}
+
+func TestPrefixedHash(t *testing.T) {
+	prefix := "operator.openshift.io/dep-"
+	if got := PrefixedHash(prefix, "ns.name.configmap", MaxLabelLength); got != prefix+"ns.name.configmap" {
+		t.Errorf("unexpected %q", got)
+	}
+	got := PrefixedHash(prefix, strings.Repeat("x", 60), MaxLabelLength)
+	if len(got) != MaxLabelLength || !strings.HasPrefix(got, prefix) {
+		t.Errorf("unexpected %q", got)
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	if got := WithSuffix("config", "3", MaxNameLength); got != "config-3" {
+		t.Errorf("unexpected %q", got)
+	}
+	got := WithSuffix(strings.Repeat("c", 80), "12", MaxLabelLength)
+	if len(got) != MaxLabelLength || !strings.HasSuffix(got, "-12") {
+		t.Errorf("unexpected %q", got)
+	}
+	if got := WithSuffix("name", strings.Repeat("s", 70), MaxLabelLength); len(got) != MaxLabelLength {
+		t.Errorf("unexpected %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package resourceread
+
+import (
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+var (
+	schedulingScheme = runtime.NewScheme()
+	schedulingCodecs = serializer.NewCodecFactory(schedulingScheme)
+)
+
+func init() {
+	utilruntime.Must(schedulingv1.AddToScheme(schedulingScheme))
+}
+
+func ReadPriorityClassV1OrDie(objBytes []byte) *schedulingv1.PriorityClass {
+	requiredObj, err := runtime.Decode(schedulingCodecs.UniversalDecoder(schedulingv1.SchemeGroupVersion), objBytes)
+	if err != nil {
+		panic(err)
+	}
+	return requiredObj.(*schedulingv1.PriorityClass)
+}
@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 
@@ -38,9 +39,15 @@ type ResourceSyncController struct {
 
 	// knownNamespaces is the list of namespaces we are watching.
 	knownNamespaces sets.String
-
-	configMapGetter            corev1client.ConfigMapsGetter
-	secretGetter               corev1client.SecretsGetter
+	// dynamicInformers is set when the watched namespaces change at runtime. Rules for any namespace are accepted
+	// then, they are applied while the informers of their namespaces are synced.
+	dynamicInformers v1helpers.DynamicKubeInformersForNamespaces
+
+	configMapGetter corev1client.ConfigMapsGetter
+	secretGetter    corev1client.SecretsGetter
+	// configMapsClient and secretsClient read destinations missing in the informer cache from the server.
+	configMapsClient           corev1client.ConfigMapsGetter
+	secretsClient              corev1client.SecretsGetter
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces
 	operatorConfigClient       v1helpers.OperatorClient
 
@@ -68,9 +75,11 @@ func NewResourceSyncController(
 		kubeInformersForNamespaces: kubeInformersForNamespaces,
 		knownNamespaces:            kubeInformersForNamespaces.Namespaces(),
 
-		configMapGetter: v1helpers.CachedConfigMapGetter(configMapsGetter, kubeInformersForNamespaces),
-		secretGetter:    v1helpers.CachedSecretGetter(secretsGetter, kubeInformersForNamespaces),
-		syncCtx:         factory.NewSyncContext("ResourceSyncController", eventRecorder.WithComponentSuffix("resource-sync-controller")),
+		configMapGetter:  v1helpers.CachedConfigMapGetter(configMapsGetter, kubeInformersForNamespaces),
+		secretGetter:     v1helpers.CachedSecretGetter(secretsGetter, kubeInformersForNamespaces),
+		configMapsClient: configMapsGetter,
+		secretsClient:    secretsGetter,
+		syncCtx:          factory.NewSyncContext("ResourceSyncController", eventRecorder.WithComponentSuffix("resource-sync-controller")),
 	}
 
 	informers := []factory.Informer{
@@ -85,12 +94,54 @@ func NewResourceSyncController(
 		informers = append(informers, informer.Core().V1().Secrets().Informer())
 	}
 
+	if dynamicInformers, ok := kubeInformersForNamespaces.(v1helpers.DynamicKubeInformersForNamespaces); ok {
+		c.dynamicInformers = dynamicInformers
+		// the rules are applied through these listers, HasSynced waits for their informers
+		dynamicInformers.ConfigMapLister()
+		dynamicInformers.SecretLister()
+		dynamicInformers.AddNamespaceChangeHandler(c.namespaceChanged)
+	}
+
 	f := factory.New().WithSync(c.Sync).WithSyncContext(c.syncCtx).WithInformers(informers...).ResyncEvery(time.Minute).ToController(c.name, eventRecorder.WithComponentSuffix("resource-sync-controller"))
 	c.runFn = f.Run
 
 	return c
 }
 
+// namespaceChanged watches the config maps and secrets of a namespace added to the dynamic informers, and syncs the
+// rules of added and removed namespaces.
+func (c *ResourceSyncController) namespaceChanged(namespace string, added bool) {
+	queueSync := func(interface{}) { c.syncCtx.Queue().Add(c.syncCtx.QueueKey()) }
+	if informers := c.dynamicInformers.InformersFor(namespace); added && len(namespace) > 0 && informers != nil {
+		handler := cache.ResourceEventHandlerFuncs{
+			AddFunc:    queueSync,
+			UpdateFunc: func(_, obj interface{}) { queueSync(obj) },
+			DeleteFunc: queueSync,
+		}
+		informers.Core().V1().ConfigMaps().Informer().AddEventHandler(handler)
+		informers.Core().V1().Secrets().Informer().AddEventHandler(handler)
+	}
+	queueSync(nil)
+}
+
+// watchesNamespace returns whether rules for the namespace are accepted.
+func (c *ResourceSyncController) watchesNamespace(namespace string) bool {
+	return c.dynamicInformers != nil || c.knownNamespaces.Has(namespace)
+}
+
+// namespacesSynced returns false while the informers of the namespaces of a rule are not synced, e.g. before the
+// namespaces are added to the dynamic informers. The rule is applied by the sync their informers trigger, rather than
+// acting on an incomplete cache.
+func (c *ResourceSyncController) namespacesSynced(destination ResourceLocation, source syncRuleSource) bool {
+	if c.dynamicInformers == nil {
+		return true
+	}
+	if !c.dynamicInformers.HasSynced(destination.Namespace) {
+		return false
+	}
+	return source.ResourceLocation == emptyResourceLocation || c.dynamicInformers.HasSynced(source.Namespace)
+}
+
 func (c *ResourceSyncController) Run(ctx context.Context, workers int) {
 	c.runFn(ctx, workers)
 }
@@ -114,10 +165,10 @@ func (c *ResourceSyncController) SyncConfigMapConditionally(destination, source
 }
 
 func (c *ResourceSyncController) syncConfigMap(destination ResourceLocation, source ResourceLocation, preconditionsFulfilledFn preconditionsFulfilled, keys ...string) error {
-	if !c.knownNamespaces.Has(destination.Namespace) {
+	if !c.watchesNamespace(destination.Namespace) {
 		return fmt.Errorf("not watching namespace %q", destination.Namespace)
 	}
-	if source != emptyResourceLocation && !c.knownNamespaces.Has(source.Namespace) {
+	if source != emptyResourceLocation && !c.watchesNamespace(source.Namespace) {
 		return fmt.Errorf("not watching namespace %q", source.Namespace)
 	}
 
@@ -149,10 +200,10 @@ func (c *ResourceSyncController) SyncSecretConditionally(destination, source Res
 }
 
 func (c *ResourceSyncController) syncSecret(destination, source ResourceLocation, preconditionsFulfilledFn preconditionsFulfilled, keys ...string) error {
-	if !c.knownNamespaces.Has(destination.Namespace) {
+	if !c.watchesNamespace(destination.Namespace) {
 		return fmt.Errorf("not watching namespace %q", destination.Namespace)
 	}
-	if source != emptyResourceLocation && !c.knownNamespaces.Has(source.Namespace) {
+	if source != emptyResourceLocation && !c.watchesNamespace(source.Namespace) {
 		return fmt.Errorf("not watching namespace %q", source.Namespace)
 	}
 
@@ -193,6 +244,9 @@ func (c *ResourceSyncController) Sync(ctx context.Context, syncCtx factory.SyncC
 	errors := []error{}
 
 	for destination, source := range c.configMapSyncRules {
+		if !c.namespacesSynced(destination, source) {
+			continue
+		}
 		// skip the sync if the preconditions aren't fulfilled
 		if fulfilled, err := source.preconditionsFulfilledFn(); !fulfilled || err != nil {
 			if err != nil {
@@ -203,7 +257,8 @@ func (c *ResourceSyncController) Sync(ctx context.Context, syncCtx factory.SyncC
 
 		if source.ResourceLocation == emptyResourceLocation {
 			// use the cache to check whether the configmap exists in target namespace, if not skip the extra delete call.
-			if _, err := c.configMapGetter.ConfigMaps(destination.Namespace).Get(ctx, destination.Name, metav1.GetOptions{}); err != nil {
+			// A configmap synced just before might not be in the cache yet, it is read from the server then.
+			if _, err := v1helpers.GetConfigMap(ctx, c.kubeInformersForNamespaces.ConfigMapLister(), c.configMapsClient, destination.Namespace, destination.Name); err != nil {
 				if !apierrors.IsNotFound(err) {
 					errors = append(errors, err)
 				}
@@ -221,6 +276,9 @@ func (c *ResourceSyncController) Sync(ctx context.Context, syncCtx factory.SyncC
 		}
 	}
 	for destination, source := range c.secretSyncRules {
+		if !c.namespacesSynced(destination, source) {
+			continue
+		}
 		// skip the sync if the preconditions aren't fulfilled
 		if fulfilled, err := source.preconditionsFulfilledFn(); !fulfilled || err != nil {
 			if err != nil {
@@ -231,7 +289,8 @@ func (c *ResourceSyncController) Sync(ctx context.Context, syncCtx factory.SyncC
 
 		if source.ResourceLocation == emptyResourceLocation {
 			// use the cache to check whether the secret exists in target namespace, if not skip the extra delete call.
-			if _, err := c.secretGetter.Secrets(destination.Namespace).Get(ctx, destination.Name, metav1.GetOptions{}); err != nil {
+			// A secret synced just before might not be in the cache yet, it is read from the server then.
+			if _, err := v1helpers.GetSecret(ctx, c.kubeInformersForNamespaces.SecretLister(), c.secretsClient, destination.Namespace, destination.Name); err != nil {
 				if !apierrors.IsNotFound(err) {
 					errors = append(errors, err)
 				}
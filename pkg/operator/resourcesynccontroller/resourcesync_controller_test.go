@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	ktesting "k8s.io/client-go/testing"
 
+	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
@@ -106,6 +107,8 @@ func TestSyncSecret(t *testing.T) {
 
 	c.configMapGetter = kubeClient.CoreV1()
 	c.secretGetter = kubeClient.CoreV1()
+	c.configMapsClient = kubeClient.CoreV1()
+	c.secretsClient = kubeClient.CoreV1()
 
 	ctx, ctxCancel := context.WithCancel(context.TODO())
 	defer ctxCancel()
@@ -197,6 +200,8 @@ func TestSyncConfigMap(t *testing.T) {
 	)
 	c.configMapGetter = kubeClient.CoreV1()
 	c.secretGetter = kubeClient.CoreV1()
+	c.configMapsClient = kubeClient.CoreV1()
+	c.secretsClient = kubeClient.CoreV1()
 
 	// sync ones for namespaces we don't have
 	if err := c.SyncSecret(ResourceLocation{Namespace: "other", Name: "foo"}, ResourceLocation{Namespace: "operator", Name: "foo"}); err == nil || err.Error() != `not watching namespace "other"` {
@@ -249,6 +254,47 @@ func TestSyncConfigMap(t *testing.T) {
 	}
 }
 
+func TestSyncDynamicNamespaces(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "config", Name: "pear"}},
+	)
+	fakeOperatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	kubeInformersForNamespaces := v1helpers.NewDynamicKubeInformersForNamespaces(kubeClient, "operator")
+	c := NewResourceSyncController(fakeOperatorClient, kubeInformersForNamespaces, kubeClient.CoreV1(), kubeClient.CoreV1(), eventstesting.NewTestingEventRecorder(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kubeInformersForNamespaces.Start(ctx.Done())
+
+	// rules for namespaces which are not watched yet are applied once they are
+	if err := c.SyncConfigMap(ResourceLocation{Namespace: "operator", Name: "apple"}, ResourceLocation{Namespace: "config", Name: "pear"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Sync(ctx, c.syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("operator").Get(ctx, "apple", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected no config map synced from an unwatched namespace, got %v", err)
+	}
+
+	kubeInformersForNamespaces.AddNamespace("config")
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return kubeInformersForNamespaces.HasSynced("config") && kubeInformersForNamespaces.HasSynced("operator"), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Sync(ctx, c.syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("operator").Get(ctx, "apple", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the config map to be synced, got %v", err)
+	}
+	_, status, _, _ := fakeOperatorClient.GetOperatorState()
+	if degraded := v1helpers.FindOperatorCondition(status.Conditions, condition.ResourceSyncControllerDegradedConditionType); degraded == nil || degraded.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected the controller not to be degraded, got %v", degraded)
+	}
+}
+
 func TestSyncConditionally(t *testing.T) {
 	for _, tc := range []struct {
 		name       string
@@ -300,6 +346,8 @@ func TestSyncConditionally(t *testing.T) {
 			)
 			c.configMapGetter = kubeClient.CoreV1()
 			c.secretGetter = kubeClient.CoreV1()
+			c.configMapsClient = kubeClient.CoreV1()
+			c.secretsClient = kubeClient.CoreV1()
 
 			if err := c.SyncSecretConditionally(ResourceLocation{Namespace: "operator", Name: "secret"}, ResourceLocation{Namespace: "config", Name: "secret"}, tc.fn); err != nil {
 				t.Fatal(err)
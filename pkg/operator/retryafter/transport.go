@@ -0,0 +1,197 @@
+package retryafter
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	flowcontrolv1beta2 "k8s.io/api/flowcontrol/v1beta2"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+)
+
+// unknownController is the controller label of requests outside of controller syncs.
+const unknownController = "unknown"
+
+const (
+	reasonRetryAfter          = "retry-after"
+	reasonPriorityAndFairness = "priority-and-fairness"
+)
+
+var (
+	throttledMetric = librarymetrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "controller",
+		Name:           "api_throttled_total",
+		Help:           "Number of API requests throttled by the server, by controller and reason",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller", "reason"})
+
+	waitMetric = librarymetrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "controller",
+		Name:           "api_throttle_wait_seconds",
+		Help:           "Time waited before retrying throttled API requests, by controller",
+		Buckets:        []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+)
+
+// Options configure the retries of throttled requests.
+type Options struct {
+	// MaxRetries is the number of retries of a throttled request, defaults to 3.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry without Retry-After, defaults to 500ms. It doubles with every
+	// throttled request of the same controller until a request succeeds.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff of a controller, defaults to 30s. Waits requested by Retry-After are a lower bound
+	// and are not capped, the request context bounds them.
+	MaxBackoff time.Duration
+}
+
+// Wrap returns a copy of the config whose clients retry requests throttled by the server, i.e. with 429 Too Many
+// Requests or with Retry-After, after the requested time, instead of failing or retrying immediately. Throttles
+// are attributed to the controller whose sync issued the request, and increase its backoff. Responses still
+// throttled after the retries are returned without Retry-After, so that client-go does not retry them again, e.g.
+//
+//	kubeClient := kubernetes.NewForConfigOrDie(retryafter.Wrap(config, retryafter.Options{}))
+func Wrap(config *rest.Config, options Options) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(NewRoundTripper(options))
+	return config
+}
+
+// NewRoundTripper returns a middleware retrying throttled requests.
+func NewRoundTripper(options Options) func(http.RoundTripper) http.RoundTripper {
+	if options.MaxRetries == 0 {
+		options.MaxRetries = 3
+	}
+	if options.InitialBackoff == 0 {
+		options.InitialBackoff = 500 * time.Millisecond
+	}
+	if options.MaxBackoff == 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	backoffs := &controllerBackoffs{options: options, backoffs: map[string]time.Duration{}}
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &retryAfterRT{baseRT: rt, options: options, backoffs: backoffs}
+	}
+}
+
+type retryAfterRT struct {
+	baseRT   http.RoundTripper
+	options  Options
+	backoffs *controllerBackoffs
+}
+
+func (rt *retryAfterRT) RoundTrip(req *http.Request) (*http.Response, error) {
+	controller := factory.ControllerNameFrom(req.Context())
+	if len(controller) == 0 {
+		controller = unknownController
+	}
+
+	for retry := 0; ; retry++ {
+		resp, err := rt.baseRT.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		reason, retryAfter, throttled := throttleReason(resp)
+		if !throttled {
+			rt.backoffs.reset(controller)
+			return resp, nil
+		}
+		throttledMetric.WithLabelValues(controller, reason).Inc()
+
+		// requests with a body can only be retried if the body can be rewound, client-go retries them itself
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		if retry >= rt.options.MaxRetries {
+			// the retries are exhausted, client-go must not start over with its own
+			resp.Header.Del("Retry-After")
+			return resp, nil
+		}
+		wait := rt.backoffs.next(controller, retryAfter)
+		klog.V(4).Infof("%s %s of controller %s throttled (%s), retrying in %s", req.Method, req.URL.Path, controller, reason, wait)
+
+		// drain the body, so that the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		waitMetric.WithLabelValues(controller).Observe(wait.Seconds())
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// throttleReason returns whether the response throttles the request, why, and after how long to retry, zero if
+// the server did not say.
+func throttleReason(resp *http.Response) (string, time.Duration, bool) {
+	retryAfterHeader := resp.Header.Get("Retry-After")
+	if resp.StatusCode != http.StatusTooManyRequests && (len(retryAfterHeader) == 0 || resp.StatusCode < 500) {
+		return "", 0, false
+	}
+	reason := reasonRetryAfter
+	if resp.StatusCode == http.StatusTooManyRequests && len(resp.Header.Get(flowcontrolv1beta2.ResponseHeaderMatchedPriorityLevelConfigurationUID)) > 0 {
+		reason = reasonPriorityAndFairness
+	}
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(retryAfterHeader); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return reason, retryAfter, true
+}
+
+// controllerBackoffs keeps a backoff per controller, so that a controller throttled repeatedly slows down, while
+// the other controllers keep their pace.
+type controllerBackoffs struct {
+	options Options
+
+	lock     sync.Mutex
+	backoffs map[string]time.Duration
+}
+
+// next returns the wait before the next retry of the controller, at least the wait requested by the server, and
+// doubles the backoff of the controller up to MaxBackoff.
+func (b *controllerBackoffs) next(controller string, retryAfter time.Duration) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	backoff, ok := b.backoffs[controller]
+	if !ok {
+		backoff = b.options.InitialBackoff
+	}
+	b.backoffs[controller] = backoff * 2
+	if b.backoffs[controller] > b.options.MaxBackoff {
+		b.backoffs[controller] = b.options.MaxBackoff
+	}
+
+	if retryAfter > backoff {
+		return retryAfter
+	}
+	return backoff
+}
+
+func (b *controllerBackoffs) reset(controller string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.backoffs, controller)
+}
@@ -0,0 +1,156 @@
+package retryafter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+type fakeRT struct {
+	statuses []int
+	headers  http.Header
+	bodies   []string
+}
+
+func (rt *fakeRT) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		rt.bodies = append(rt.bodies, string(body))
+	}
+	status := rt.statuses[0]
+	if len(rt.statuses) > 1 {
+		rt.statuses = rt.statuses[1:]
+	}
+	header := http.Header{}
+	if status != http.StatusOK {
+		header = rt.headers.Clone()
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(bytes.NewReader(nil)), Request: req}, nil
+}
+
+func TestRoundTripRetriesThrottledRequests(t *testing.T) {
+	base := &fakeRT{statuses: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}}
+	rt := NewRoundTripper(Options{InitialBackoff: time.Millisecond})(base)
+
+	ctx := factory.WithControllerName(context.Background(), "FooController")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "https://example.com/api/v1/namespaces/ns/configmaps/foo", bytes.NewReader([]byte("body")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the retry to succeed, got %d", resp.StatusCode)
+	}
+	if len(base.bodies) != 3 || base.bodies[2] != "body" {
+		t.Errorf("expected the body to be sent with every retry, got %q", base.bodies)
+	}
+}
+
+func TestRoundTripGivesUpAfterMaxRetries(t *testing.T) {
+	base := &fakeRT{statuses: []int{http.StatusTooManyRequests}}
+	rt := NewRoundTripper(Options{MaxRetries: 2, InitialBackoff: time.Millisecond})(base)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/api/v1/pods", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the throttled response, got %d", resp.StatusCode)
+	}
+}
+
+func TestRoundTripDoesNotStackClientRetries(t *testing.T) {
+	base := &fakeRT{statuses: []int{http.StatusTooManyRequests}, headers: http.Header{"Retry-After": []string{"1"}}}
+	rt := NewRoundTripper(Options{MaxRetries: 1})(base)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/api/v1/pods", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || len(resp.Header.Get("Retry-After")) > 0 {
+		t.Errorf("expected the throttled response without Retry-After, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// requests that cannot be retried here are left to client-go
+	req, _ = http.NewRequest(http.MethodPost, "https://example.com/api/v1/pods", io.NopCloser(bytes.NewReader([]byte("body"))))
+	req.GetBody = nil
+	resp, err = rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After to be kept, got %v", resp.Header)
+	}
+}
+
+func TestRoundTripStopsWithContext(t *testing.T) {
+	base := &fakeRT{statuses: []int{http.StatusTooManyRequests}, headers: http.Header{"Retry-After": []string{"10"}}}
+	rt := NewRoundTripper(Options{})(base)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/api/v1/pods", nil)
+	if _, err := rt.RoundTrip(req); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestThrottleReason(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		header             http.Header
+		expectedReason     string
+		expectedRetryAfter time.Duration
+		expectedThrottled  bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "conflict", status: http.StatusConflict, header: http.Header{"Retry-After": []string{"1"}}},
+		{name: "too many requests", status: http.StatusTooManyRequests, header: http.Header{"Retry-After": []string{"2"}}, expectedReason: reasonRetryAfter, expectedRetryAfter: 2 * time.Second, expectedThrottled: true},
+		{name: "priority and fairness", status: http.StatusTooManyRequests, header: http.Header{"Retry-After": []string{"1"}, "X-Kubernetes-Pf-Prioritylevel-Uid": []string{"uid"}}, expectedReason: reasonPriorityAndFairness, expectedRetryAfter: time.Second, expectedThrottled: true},
+		{name: "unavailable with retry after", status: http.StatusServiceUnavailable, header: http.Header{"Retry-After": []string{"1"}}, expectedReason: reasonRetryAfter, expectedRetryAfter: time.Second, expectedThrottled: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, retryAfter, throttled := throttleReason(&http.Response{StatusCode: test.status, Header: test.header})
+			if reason != test.expectedReason || retryAfter != test.expectedRetryAfter || throttled != test.expectedThrottled {
+				t.Errorf("expected (%q, %s, %v), got (%q, %s, %v)", test.expectedReason, test.expectedRetryAfter, test.expectedThrottled, reason, retryAfter, throttled)
+			}
+		})
+	}
+}
+
+func TestControllerBackoffs(t *testing.T) {
+	b := &controllerBackoffs{options: Options{InitialBackoff: time.Second, MaxBackoff: 4 * time.Second}, backoffs: map[string]time.Duration{}}
+	var waits []time.Duration
+	for i := 0; i < 4; i++ {
+		waits = append(waits, b.next("a", 0))
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	for i := range expected {
+		if waits[i] != expected[i] {
+			t.Fatalf("expected waits %v, got %v", expected, waits)
+		}
+	}
+	if wait := b.next("b", 3*time.Second); wait != 3*time.Second {
+		t.Errorf("expected retry after to win for other controller, got %s", wait)
+	}
+	if wait := b.next("b", time.Minute); wait != time.Minute {
+		t.Errorf("expected retry after beyond the max backoff to be honored, got %s", wait)
+	}
+	b.reset("a")
+	if wait := b.next("a", 0); wait != time.Second {
+		t.Errorf("expected reset backoff, got %s", wait)
+	}
+}
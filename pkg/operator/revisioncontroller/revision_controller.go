@@ -21,6 +21,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/management"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -122,7 +123,7 @@ func (c RevisionController) createRevisionIfNeeded(ctx context.Context, recorder
 }
 
 func nameFor(name string, revision int32) string {
-	return fmt.Sprintf("%s-%d", name, revision)
+	return resourcenames.WithSuffix(name, strconv.Itoa(int(revision)), resourcenames.MaxNameLength)
 }
 
 // isLatestRevisionCurrent returns whether the latest revision is up to date and an optional reason
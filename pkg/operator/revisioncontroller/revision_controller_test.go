@@ -532,3 +532,17 @@ func TestRevisionController(t *testing.T) {
 		})
 	}
 }
+
+func TestNameFor(t *testing.T) {
+	if name := nameFor("kube-apiserver-pod", 12); name != "kube-apiserver-pod-12" {
+		t.Errorf("expected short names to be kept, got %q", name)
+	}
+	long := strings.Repeat("a", 260)
+	name := nameFor(long, 12)
+	if len(name) > 253 || !strings.HasSuffix(name, "-12") {
+		t.Errorf("expected a name of at most 253 characters ending in the revision, got %q", name)
+	}
+	if name == nameFor(long+"b", 12) {
+		t.Errorf("expected long names with a common prefix to differ")
+	}
+}
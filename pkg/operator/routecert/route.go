@@ -0,0 +1,154 @@
+package routecert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// ApplyRouteTLS populates route spec.tls from the serving cert secret and the CA bundle.
+// The certificate and key are taken from the kubernetes.io/tls keys of the secret, see ApplyRouteTLSWithKeys for
+// secrets with other keys. The CA bundle is set as
+// the caCertificate and, for reencrypt routes, also as the destinationCACertificate so the router trusts
+// backends serving certificates issued by the same signer.
+// Routes without TLS configured default to edge termination. Passthrough routes cannot carry certificates.
+// It returns true when the route was modified.
+func ApplyRouteTLS(route *routev1.Route, secret *corev1.Secret, caBundle string) (bool, error) {
+	return ApplyRouteTLSWithKeys(route, secret, "", "", caBundle)
+}
+
+// ApplyRouteTLSWithKeys is ApplyRouteTLS for a secret holding the certificate and key under the given keys, e.g. the
+// CertKey and PrivateKeyKey of certrotation.RotatedSelfSignedCertKeySecret. Empty keys default to tls.crt and tls.key.
+func ApplyRouteTLSWithKeys(route *routev1.Route, secret *corev1.Secret, certKey, privateKeyKey, caBundle string) (bool, error) {
+	if len(certKey) == 0 {
+		certKey = corev1.TLSCertKey
+	}
+	if len(privateKeyKey) == 0 {
+		privateKeyKey = corev1.TLSPrivateKeyKey
+	}
+	certificate := string(secret.Data[certKey])
+	key := string(secret.Data[privateKeyKey])
+	if len(certificate) == 0 || len(key) == 0 {
+		return false, fmt.Errorf("secret %s/%s is missing %q or %q", secret.Namespace, secret.Name, certKey, privateKeyKey)
+	}
+
+	if route.Spec.TLS == nil {
+		route.Spec.TLS = &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge}
+	}
+	tls := route.Spec.TLS
+	if tls.Termination == routev1.TLSTerminationPassthrough {
+		return false, fmt.Errorf("route %s/%s uses passthrough termination and cannot carry a certificate", route.Namespace, route.Name)
+	}
+
+	desired := *tls
+	desired.Certificate = certificate
+	desired.Key = key
+	desired.CACertificate = caBundle
+	if tls.Termination == routev1.TLSTerminationReencrypt {
+		desired.DestinationCACertificate = caBundle
+	}
+	if desired == *tls {
+		return false, nil
+	}
+	*tls = desired
+	return true, nil
+}
+
+// RouteServingCertController keeps the TLS configuration of a route in sync with a rotated serving cert secret
+// (see certrotation.RotatedSelfSignedCertKeySecret) and the CA bundle config map (see certrotation.CABundleConfigMap).
+type RouteServingCertController struct {
+	namespace         string
+	routeName         string
+	secretName        string
+	certKey           string
+	privateKeyKey     string
+	caBundleConfigMap string
+	routeClient       routev1client.RoutesGetter
+	secretLister      corev1listers.SecretLister
+	configMapLister   corev1listers.ConfigMapLister
+	eventRecorder     events.Recorder
+}
+
+// NewRouteServingCertController returns a controller updating the route spec.tls every time the serving cert
+// secret or the CA bundle config map is rotated. The certificate and key are read from the certKey and privateKeyKey of
+// the secret, empty keys default to tls.crt and tls.key.
+// The kubeInformersForNamespace must be scoped to the namespace of the route, the secret and the config map.
+func NewRouteServingCertController(
+	namespace, routeName, secretName, certKey, privateKeyKey, caBundleConfigMapName string,
+	routeClient routev1client.RoutesGetter,
+	kubeInformersForNamespace informers.SharedInformerFactory,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &RouteServingCertController{
+		namespace:         namespace,
+		routeName:         routeName,
+		secretName:        secretName,
+		certKey:           certKey,
+		privateKeyKey:     privateKeyKey,
+		caBundleConfigMap: caBundleConfigMapName,
+		routeClient:       routeClient,
+		secretLister:      kubeInformersForNamespace.Core().V1().Secrets().Lister(),
+		configMapLister:   kubeInformersForNamespace.Core().V1().ConfigMaps().Lister(),
+		eventRecorder:     eventRecorder.WithComponentSuffix("route-serving-cert"),
+	}
+	return factory.New().
+		WithFilteredEventsInformers(
+			factory.NamesFilter(secretName, caBundleConfigMapName),
+			kubeInformersForNamespace.Core().V1().Secrets().Informer(),
+			kubeInformersForNamespace.Core().V1().ConfigMaps().Informer(),
+		).
+		WithSync(c.sync).
+		// routes are not watched, make sure manual edits are eventually reverted
+		ResyncEvery(time.Minute).
+		ToController("RouteServingCertController", eventRecorder)
+}
+
+func (c *RouteServingCertController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	secret, err := c.secretLister.Secrets(c.namespace).Get(c.secretName)
+	if apierrors.IsNotFound(err) {
+		klog.V(2).Infof("Waiting for serving cert secret %s/%s", c.namespace, c.secretName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	caBundle := ""
+	caBundleConfigMap, err := c.configMapLister.ConfigMaps(c.namespace).Get(c.caBundleConfigMap)
+	switch {
+	case apierrors.IsNotFound(err):
+		klog.V(2).Infof("Waiting for CA bundle config map %s/%s", c.namespace, c.caBundleConfigMap)
+		return nil
+	case err != nil:
+		return err
+	default:
+		caBundle = caBundleConfigMap.Data["ca-bundle.crt"]
+	}
+
+	route, err := c.routeClient.Routes(c.namespace).Get(ctx, c.routeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	route = route.DeepCopy()
+	modified, err := ApplyRouteTLSWithKeys(route, secret, c.certKey, c.privateKeyKey, caBundle)
+	if err != nil || !modified {
+		return err
+	}
+	if _, err := c.routeClient.Routes(c.namespace).Update(ctx, route, metav1.UpdateOptions{}); err != nil {
+		c.eventRecorder.Warningf("RouteTLSUpdateFailed", "Failed to update TLS of route %s/%s: %v", c.namespace, c.routeName, err)
+		return err
+	}
+	c.eventRecorder.Eventf("RouteTLSUpdated", "Updated TLS of route %s/%s from secret %s and config map %s", c.namespace, c.routeName, c.secretName, c.caBundleConfigMap)
+	return nil
+}
@@ -0,0 +1,140 @@
+package routecert
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func newSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "serving-cert"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}
+}
+
+func TestApplyRouteTLS(t *testing.T) {
+	tests := []struct {
+		name             string
+		tls              *routev1.TLSConfig
+		expectedTLS      *routev1.TLSConfig
+		expectedModified bool
+		expectedErr      bool
+	}{
+		{
+			name:             "no tls defaults to edge",
+			expectedTLS:      &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: "cert", Key: "key", CACertificate: "ca"},
+			expectedModified: true,
+		},
+		{
+			name:             "reencrypt gets destination CA",
+			tls:              &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt, InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect},
+			expectedTLS:      &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt, InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect, Certificate: "cert", Key: "key", CACertificate: "ca", DestinationCACertificate: "ca"},
+			expectedModified: true,
+		},
+		{
+			name:        "up to date",
+			tls:         &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: "cert", Key: "key", CACertificate: "ca"},
+			expectedTLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: "cert", Key: "key", CACertificate: "ca"},
+		},
+		{
+			name:        "passthrough",
+			tls:         &routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough},
+			expectedTLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough},
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := &routev1.Route{Spec: routev1.RouteSpec{TLS: test.tls}}
+			modified, err := ApplyRouteTLS(route, newSecret(), "ca")
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if modified != test.expectedModified {
+				t.Errorf("expected modified %v, got %v", test.expectedModified, modified)
+			}
+			if *route.Spec.TLS != *test.expectedTLS {
+				t.Errorf("expected %#v, got %#v", *test.expectedTLS, *route.Spec.TLS)
+			}
+		})
+	}
+}
+
+func TestApplyRouteTLSWithKeys(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "serving-cert"},
+		Data:       map[string][]byte{"server.crt": []byte("cert"), "server.key": []byte("key")},
+	}
+	route := &routev1.Route{}
+	if _, err := ApplyRouteTLS(route, secret, "ca"); err == nil {
+		t.Errorf("expected the missing default keys to be reported")
+	}
+	modified, err := ApplyRouteTLSWithKeys(route, secret, "server.crt", "server.key", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified || route.Spec.TLS.Certificate != "cert" || route.Spec.TLS.Key != "key" {
+		t.Errorf("unexpected route TLS: %#v", route.Spec.TLS)
+	}
+}
+
+func TestSync(t *testing.T) {
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := secrets.Add(newSecret()); err != nil {
+		t.Fatal(err)
+	}
+	if err := configMaps.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ca-bundle"},
+		Data:       map[string]string{"ca-bundle.crt": "ca"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	routeClient := routefake.NewSimpleClientset(&routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "console"},
+		Spec:       routev1.RouteSpec{TLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt}},
+	})
+	recorder := events.NewInMemoryRecorder("test")
+	c := &RouteServingCertController{
+		namespace:         "ns",
+		routeName:         "console",
+		secretName:        "serving-cert",
+		caBundleConfigMap: "ca-bundle",
+		routeClient:       routeClient.RouteV1(),
+		secretLister:      corev1listers.NewSecretLister(secrets),
+		configMapLister:   corev1listers.NewConfigMapLister(configMaps),
+		eventRecorder:     recorder,
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	route, err := routeClient.RouteV1().Routes("ns").Get(context.TODO(), "console", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Spec.TLS.Certificate != "cert" || route.Spec.TLS.Key != "key" || route.Spec.TLS.DestinationCACertificate != "ca" {
+		t.Errorf("unexpected route TLS: %#v", route.Spec.TLS)
+	}
+	updates := 0
+	for _, action := range routeClient.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Errorf("expected exactly one route update, got %d", updates)
+	}
+}
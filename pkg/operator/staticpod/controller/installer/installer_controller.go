@@ -19,6 +19,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/loglevel"
 	"github.com/openshift/library-go/pkg/operator/management"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/revision"
 	"github.com/openshift/library-go/pkg/operator/staticpod/startupmonitor/annotations"
@@ -948,7 +949,7 @@ func (c InstallerController) ensureSecretRevisionResourcesExists(ctx context.Con
 		if secret.Optional {
 			continue
 		}
-		name := fmt.Sprintf("%s-%d", secret.Name, latestRevisionNumber)
+		name := resourcenames.WithSuffix(secret.Name, strconv.Itoa(int(latestRevisionNumber)), resourcenames.MaxNameLength)
 		_, err := c.secretsGetter.Secrets(c.targetNamespace).Get(ctx, name, metav1.GetOptions{})
 		if err == nil {
 			continue
@@ -969,7 +970,7 @@ func (c InstallerController) ensureConfigMapRevisionResourcesExists(ctx context.
 		if config.Optional {
 			continue
 		}
-		name := fmt.Sprintf("%s-%d", config.Name, latestRevisionNumber)
+		name := resourcenames.WithSuffix(config.Name, strconv.Itoa(int(latestRevisionNumber)), resourcenames.MaxNameLength)
 		_, err := c.configMapsGetter.ConfigMaps(c.targetNamespace).Get(ctx, name, metav1.GetOptions{})
 		if err == nil {
 			continue
@@ -29,6 +29,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/config/client"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcenames"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 	"github.com/openshift/library-go/pkg/operator/resource/retry"
 	"github.com/openshift/library-go/pkg/operator/staticpod"
@@ -198,7 +199,7 @@ func (o *InstallOptions) Validate() error {
 }
 
 func (o *InstallOptions) nameFor(prefix string) string {
-	return fmt.Sprintf("%s-%s", prefix, o.Revision)
+	return resourcenames.WithSuffix(prefix, o.Revision, resourcenames.MaxNameLength)
 }
 
 func (o *InstallOptions) prefixFor(name string) string {
@@ -0,0 +1,80 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// ConditionPolicy dampens flapping of a union condition in both directions. Conditions must be bad for the inertia
+// before the union condition turns bad, and a bad union condition only turns good again once all conditions have been
+// good for the recovery duration. Pending transitions are listed in the message of the union condition. The message
+// only changes with the conditions, not with the time passing, so that it is not rewritten on every sync.
+type ConditionPolicy struct {
+	// Inertia returns how long a condition must be bad before the union condition turns bad. Nil means immediately.
+	Inertia Inertia
+	// Recovery returns how long a condition must be good before a bad union condition turns good. Nil means immediately.
+	Recovery Inertia
+}
+
+// Union is UnionCondition applying the policy. previous is the current union condition, nil if there is none yet.
+// recheckAfter is the time until the first pending transition is due, zero if there is none. The union condition must
+// be computed again then.
+func (p ConditionPolicy) Union(conditionType string, defaultConditionStatus operatorv1.ConditionStatus, previous *operatorv1.OperatorCondition, allConditions ...operatorv1.OperatorCondition) (unioned operatorv1.OperatorCondition, recheckAfter time.Duration) {
+	unionedCondition := UnionCondition(conditionType, defaultConditionStatus, p.Inertia, allConditions...)
+	if unionedCondition.Status != defaultConditionStatus {
+		return unionedCondition, 0
+	}
+
+	now := time.Now()
+	recheck := func(after time.Duration) {
+		if after > 0 && (recheckAfter == 0 || after < recheckAfter) {
+			recheckAfter = after
+		}
+	}
+	var pending, recovering []string
+	var badConditions []operatorv1.OperatorCondition
+	previousBad := previous != nil && previous.Status != defaultConditionStatus && previous.Status != operatorv1.ConditionUnknown
+	for _, condition := range allConditions {
+		if !strings.HasSuffix(condition.Type, conditionType) {
+			continue
+		}
+		since := now.Sub(condition.LastTransitionTime.Time)
+		transitioned := condition.LastTransitionTime.UTC().Format(time.RFC3339)
+		switch {
+		case condition.Status != defaultConditionStatus:
+			badConditions = append(badConditions, condition)
+			if p.Inertia != nil {
+				pending = append(pending, fmt.Sprintf("%s: %s since %s, reported after %s", condition.Type, condition.Status, transitioned, p.Inertia(condition)))
+				recheck(p.Inertia(condition) - since)
+			}
+		case previousBad && p.Recovery != nil && since < p.Recovery(condition):
+			recovering = append(recovering, fmt.Sprintf("%s: recovering, %s since %s, reported after %s", condition.Type, condition.Status, transitioned, p.Recovery(condition)))
+			recheck(p.Recovery(condition) - since)
+		}
+	}
+
+	if len(recovering) > 0 {
+		// hold the previous bad condition until all conditions recovered
+		heldCondition := *previous
+		heldCondition.Type = conditionType
+		heldCondition.Message = joinNonEmpty(unionMessage(badConditions), strings.Join(recovering, "\n"))
+		return heldCondition, recheckAfter
+	}
+	if len(pending) > 0 {
+		unionedCondition.Message = joinNonEmpty(unionedCondition.Message, strings.Join(pending, "\n"))
+	}
+	return unionedCondition, recheckAfter
+}
+
+func joinNonEmpty(messages ...string) string {
+	var ret []string
+	for _, message := range messages {
+		if len(message) > 0 {
+			ret = append(ret, message)
+		}
+	}
+	return strings.Join(ret, "\n")
+}
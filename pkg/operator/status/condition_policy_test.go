@@ -0,0 +1,108 @@
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestConditionPolicyUnion(t *testing.T) {
+	now := time.Now()
+	policy := ConditionPolicy{
+		Inertia:  MustNewInertia(2 * time.Minute).Inertia,
+		Recovery: MustNewInertia(5 * time.Minute).Inertia,
+	}
+	condition := func(conditionType string, status operatorv1.ConditionStatus, age time.Duration, message string) operatorv1.OperatorCondition {
+		return operatorv1.OperatorCondition{Type: conditionType, Status: status, LastTransitionTime: metav1.NewTime(now.Add(-age)), Reason: "Reason", Message: message}
+	}
+	degraded := &operatorv1.OperatorCondition{Type: "Degraded", Status: operatorv1.ConditionTrue, Reason: "FooDegraded_Reason", Message: "FooDegraded: broken", LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))}
+	notDegraded := &operatorv1.OperatorCondition{Type: "Degraded", Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+
+	tests := []struct {
+		name            string
+		previous        *operatorv1.OperatorCondition
+		conditions      []operatorv1.OperatorCondition
+		expectedStatus  operatorv1.ConditionStatus
+		expectedReason  string
+		expectedMessage []string
+		expectedRecheck time.Duration
+	}{
+		{
+			name:           "all good",
+			previous:       notDegraded,
+			conditions:     []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionFalse, time.Hour, "")},
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "AsExpected",
+		},
+		{
+			name:            "young bad condition is pending",
+			previous:        notDegraded,
+			conditions:      []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionTrue, time.Minute, "broken")},
+			expectedStatus:  operatorv1.ConditionFalse,
+			expectedReason:  "AsExpected",
+			expectedMessage: []string{"FooDegraded: broken", "FooDegraded: True since " + now.Add(-time.Minute).UTC().Format(time.RFC3339) + ", reported after 2m0s"},
+			expectedRecheck: time.Minute,
+		},
+		{
+			name:            "old bad condition degrades",
+			previous:        notDegraded,
+			conditions:      []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionTrue, 3*time.Minute, "broken")},
+			expectedStatus:  operatorv1.ConditionTrue,
+			expectedReason:  "Foo_Reason",
+			expectedMessage: []string{"FooDegraded: broken"},
+		},
+		{
+			name:            "young good condition keeps degraded",
+			previous:        degraded,
+			conditions:      []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionFalse, time.Minute, "")},
+			expectedStatus:  operatorv1.ConditionTrue,
+			expectedReason:  "FooDegraded_Reason",
+			expectedMessage: []string{"FooDegraded: recovering, False since " + now.Add(-time.Minute).UTC().Format(time.RFC3339) + ", reported after 5m0s"},
+			expectedRecheck: 4 * time.Minute,
+		},
+		{
+			name:           "old good condition recovers",
+			previous:       degraded,
+			conditions:     []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionFalse, 6*time.Minute, "")},
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "AsExpected",
+		},
+		{
+			name:           "young good condition without previous degraded does not degrade",
+			conditions:     []operatorv1.OperatorCondition{condition("FooDegraded", operatorv1.ConditionFalse, time.Minute, "")},
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "AsExpected",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, recheckAfter := policy.Union("Degraded", operatorv1.ConditionFalse, test.previous, test.conditions...)
+			// the test runs after now, the recheck is due a little earlier
+			if recheckAfter > test.expectedRecheck || recheckAfter < test.expectedRecheck-time.Minute/2 {
+				t.Errorf("expected a recheck after %s, got %s", test.expectedRecheck, recheckAfter)
+			}
+			if actual.Status != test.expectedStatus {
+				t.Errorf("expected status %s, got %s", test.expectedStatus, actual.Status)
+			}
+			if actual.Reason != test.expectedReason {
+				t.Errorf("expected reason %q, got %q", test.expectedReason, actual.Reason)
+			}
+			for _, expected := range test.expectedMessage {
+				if !strings.Contains(actual.Message, expected) {
+					t.Errorf("expected message to contain %q, got %q", expected, actual.Message)
+				}
+			}
+			if test.previous != nil && test.previous.Status == actual.Status && test.previous.Status == operatorv1.ConditionTrue && !actual.LastTransitionTime.Equal(&test.previous.LastTransitionTime) {
+				t.Errorf("expected held condition to keep its transition time")
+			}
+			// the message is stable while time passes
+			if later, _ := policy.Union("Degraded", operatorv1.ConditionFalse, test.previous, test.conditions...); later.Message != actual.Message {
+				t.Errorf("expected a stable message %q, got %q", actual.Message, later.Message)
+			}
+		})
+	}
+}
@@ -48,6 +48,7 @@ type StatusSyncer struct {
 	controllerFactory *factory.Factory
 	recorder          events.Recorder
 	degradedInertia   Inertia
+	degradedPolicy    *ConditionPolicy
 }
 
 var _ factory.Controller = &StatusSyncer{}
@@ -108,6 +109,14 @@ func (c *StatusSyncer) WithDegradedInertia(inertia Inertia) *StatusSyncer {
 	return &output
 }
 
+// WithDegradedPolicy returns a copy of the StatusSyncer applying the
+// policy to degraded conditions. It replaces the degraded inertia.
+func (c *StatusSyncer) WithDegradedPolicy(policy ConditionPolicy) *StatusSyncer {
+	output := *c
+	output.degradedPolicy = &policy
+	return &output
+}
+
 // sync reacts to a change in prereqs by finding information that is required to match another value in the cluster. This
 // must be information that is logically "owned" by another component.
 func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
@@ -189,7 +198,26 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 		clusterOperatorObj.Status.RelatedObjects = c.relatedObjects
 	}
 
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition("Degraded", operatorv1.ConditionFalse, c.degradedInertia, currentDetailedStatus.Conditions...))
+	if c.degradedPolicy != nil {
+		var previous *operatorv1.OperatorCondition
+		if existing := configv1helpers.FindStatusCondition(clusterOperatorObj.Status.Conditions, configv1.OperatorDegraded); existing != nil {
+			previous = &operatorv1.OperatorCondition{
+				Type:               string(existing.Type),
+				Status:             operatorv1.ConditionStatus(existing.Status),
+				LastTransitionTime: existing.LastTransitionTime,
+				Reason:             existing.Reason,
+				Message:            existing.Message,
+			}
+		}
+		degraded, recheckAfter := c.degradedPolicy.Union("Degraded", operatorv1.ConditionFalse, previous, currentDetailedStatus.Conditions...)
+		configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, OperatorConditionToClusterOperatorCondition(degraded))
+		if recheckAfter > 0 {
+			// the message does not change while a transition is pending, no informer event triggers the transition
+			syncCtx.Queue().AddAfter(factory.DefaultQueueKey, recheckAfter)
+		}
+	} else {
+		configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition("Degraded", operatorv1.ConditionFalse, c.degradedInertia, currentDetailedStatus.Conditions...))
+	}
 	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition("Progressing", operatorv1.ConditionFalse, nil, currentDetailedStatus.Conditions...))
 	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition("Available", operatorv1.ConditionTrue, nil, currentDetailedStatus.Conditions...))
 	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition("Upgradeable", operatorv1.ConditionTrue, nil, currentDetailedStatus.Conditions...))
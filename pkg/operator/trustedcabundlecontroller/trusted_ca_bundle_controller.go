@@ -0,0 +1,191 @@
+package trustedcabundlecontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// InjectTrustedCABundleLabel marks config maps which should get the trusted CA bundle injected. It is distinct from
+	// config.openshift.io/inject-trusted-cabundle reconciled by the cluster-network-operator, so that both injectors
+	// never write the same config map.
+	InjectTrustedCABundleLabel = "operator.openshift.io/inject-library-trusted-cabundle"
+	// TrustedCABundleKey is the config map key the bundle is written to.
+	TrustedCABundleKey = "ca-bundle.crt"
+)
+
+// InjectionTargetSelector selects the config maps labeled for injection. Use it to build the label-filtered,
+// all-namespaces config map informer passed to NewTrustedCABundleController.
+var InjectionTargetSelector = labels.SelectorFromSet(labels.Set{InjectTrustedCABundleLabel: "true"})
+
+// TrustedCABundleController injects a trust bundle into every config map labeled with
+// operator.openshift.io/inject-library-trusted-cabundle=true. The bundle is the union of the "ca-bundle.crt" keys of the source
+// config maps, e.g. the cluster/proxy trust bundle and the CA bundle maintained by a CertRotationController.
+// Updates of the targets are rate limited so a bundle change does not cause a write storm.
+type TrustedCABundleController struct {
+	sources []resourcesynccontroller.ResourceLocation
+
+	sourceLister    v1helpers.KubeInformersForNamespaces
+	targetInformer  corev1informers.ConfigMapInformer
+	configMapClient corev1client.ConfigMapsGetter
+	rateLimiter     flowcontrol.RateLimiter
+}
+
+// NewTrustedCABundleController creates the controller. targetInformer must watch all namespaces and should be
+// restricted to InjectionTargetSelector. If rateLimiter is nil, a default of 5 updates per second is used.
+func NewTrustedCABundleController(
+	sources []resourcesynccontroller.ResourceLocation,
+	sourceInformers v1helpers.KubeInformersForNamespaces,
+	targetInformer corev1informers.ConfigMapInformer,
+	configMapClient corev1client.ConfigMapsGetter,
+	rateLimiter flowcontrol.RateLimiter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	if rateLimiter == nil {
+		rateLimiter = flowcontrol.NewTokenBucketRateLimiter(5, 10)
+	}
+	c := &TrustedCABundleController{
+		sources:         sources,
+		sourceLister:    sourceInformers,
+		targetInformer:  targetInformer,
+		configMapClient: configMapClient,
+		rateLimiter:     rateLimiter,
+	}
+
+	sourceInformerList := []factory.Informer{}
+	for _, source := range sources {
+		sourceInformerList = append(sourceInformerList, sourceInformers.InformersFor(source.Namespace).Core().V1().ConfigMaps().Informer())
+	}
+
+	return factory.New().
+		ResyncEvery(10*time.Minute).
+		WithSync(c.sync).
+		WithFilteredEventsInformers(isSource(sources), sourceInformerList...).
+		WithFilteredEventsInformers(isInjectionTarget, targetInformer.Informer()).
+		ToController("TrustedCABundleController", eventRecorder.WithComponentSuffix("trusted-ca-bundle-controller"))
+}
+
+// isSource matches the source config maps by namespace and name, config maps of the same name in other namespaces
+// must not trigger a sync.
+func isSource(sources []resourcesynccontroller.ResourceLocation) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		metaObj, ok := obj.(metav1.ObjectMetaAccessor)
+		if !ok {
+			return false
+		}
+		for _, source := range sources {
+			if metaObj.GetObjectMeta().GetNamespace() == source.Namespace && metaObj.GetObjectMeta().GetName() == source.Name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isInjectionTarget(obj interface{}) bool {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return false
+	}
+	return InjectionTargetSelector.Matches(labels.Set(cm.Labels))
+}
+
+func (c *TrustedCABundleController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	bundle, err := c.combinedBundle()
+	if err != nil {
+		return err
+	}
+
+	targets, err := c.targetInformer.Lister().List(InjectionTargetSelector)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	updated := 0
+	for _, target := range targets {
+		if target.Data[TrustedCABundleKey] == string(bundle) {
+			continue
+		}
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+		required := target.DeepCopy()
+		if required.Data == nil {
+			required.Data = map[string]string{}
+		}
+		required.Data[TrustedCABundleKey] = string(bundle)
+		if _, err := c.configMapClient.ConfigMaps(required.Namespace).Update(ctx, required, metav1.UpdateOptions{}); err != nil {
+			if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+				// the informer will tell us about the new state
+				continue
+			}
+			errs = append(errs, fmt.Errorf("failed to inject trusted CA bundle into configmap %s/%s: %v", required.Namespace, required.Name, err))
+			continue
+		}
+		updated++
+	}
+	if updated > 0 {
+		syncCtx.Recorder().Eventf("TrustedCABundleInjected", "Injected trusted CA bundle into %d config maps", updated)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// combinedBundle returns the de-duplicated, non-expired certificates of all sources. Missing sources are skipped.
+func (c *TrustedCABundleController) combinedBundle() ([]byte, error) {
+	var certificates []*x509.Certificate
+	for _, source := range c.sources {
+		cm, err := c.sourceLister.InformersFor(source.Namespace).Core().V1().ConfigMaps().Lister().ConfigMaps(source.Namespace).Get(source.Name)
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("trusted CA bundle source configmap %s/%s not found", source.Namespace, source.Name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(cm.Data[TrustedCABundleKey]) == 0 {
+			continue
+		}
+		certs, err := cert.ParseCertsPEM([]byte(cm.Data[TrustedCABundleKey]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in configmap %s/%s: %v", TrustedCABundleKey, source.Namespace, source.Name, err)
+		}
+		for _, newCert := range certs {
+			duplicate := false
+			for _, existing := range certificates {
+				if bytes.Equal(existing.Raw, newCert.Raw) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				certificates = append(certificates, newCert)
+			}
+		}
+	}
+	certificates = crypto.FilterExpiredCerts(certificates...)
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no trusted CA certificates found in %v", c.sources)
+	}
+	return crypto.EncodeCertificates(certificates...)
+}
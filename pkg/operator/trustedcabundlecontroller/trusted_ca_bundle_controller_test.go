@@ -0,0 +1,112 @@
+package trustedcabundlecontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func newCABundle(t *testing.T, name string) string {
+	ca, err := crypto.MakeSelfSignedCAConfig(name, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(certPEM)
+}
+
+func TestTrustedCABundleControllerSync(t *testing.T) {
+	proxyBundle := newCABundle(t, "proxy-ca")
+	operatorBundle := newCABundle(t, "operator-ca")
+
+	objects := []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config-managed", Name: "trusted-ca-bundle"}, Data: map[string]string{TrustedCABundleKey: proxyBundle}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "ca-bundle"}, Data: map[string]string{TrustedCABundleKey: operatorBundle + proxyBundle}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "target", Labels: map[string]string{InjectTrustedCABundleLabel: "true"}}, Data: map[string]string{"other": "keep"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "unlabeled"}},
+	}
+	client := fake.NewSimpleClientset(objects...)
+	informerFactories := map[string]informers.SharedInformerFactory{}
+	for _, ns := range []string{"openshift-config-managed", "operator"} {
+		informerFactories[ns] = informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(ns))
+	}
+	targetInformer := informers.NewSharedInformerFactory(client, 0).Core().V1().ConfigMaps()
+	for _, obj := range objects {
+		cm := obj.(*corev1.ConfigMap)
+		if f, ok := informerFactories[cm.Namespace]; ok {
+			f.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm)
+		}
+		targetInformer.Informer().GetIndexer().Add(cm)
+	}
+
+	c := &TrustedCABundleController{
+		sources: []resourcesynccontroller.ResourceLocation{
+			{Namespace: "openshift-config-managed", Name: "trusted-ca-bundle"},
+			{Namespace: "operator", Name: "ca-bundle"},
+			{Namespace: "operator", Name: "missing"},
+		},
+		sourceLister:    v1helpers.NewFakeKubeInformersForNamespaces(informerFactories),
+		targetInformer:  targetInformer,
+		configMapClient: client.CoreV1(),
+		rateLimiter:     flowcontrol.NewFakeAlwaysRateLimiter(),
+	}
+
+	if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Fatalf("expected exactly one update, got %d: %v", updates, client.Actions())
+	}
+
+	target, err := client.CoreV1().ConfigMaps("app").Get(context.TODO(), "target", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Data["other"] != "keep" {
+		t.Errorf("expected other keys to be preserved, got %v", target.Data)
+	}
+	certs, err := cert.ParseCertsPEM([]byte(target.Data[TrustedCABundleKey]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 {
+		t.Errorf("expected 2 de-duplicated certificates, got %d", len(certs))
+	}
+	if !strings.Contains(target.Data[TrustedCABundleKey], strings.TrimSpace(operatorBundle)) {
+		t.Errorf("expected operator CA in bundle")
+	}
+}
+
+func TestIsSource(t *testing.T) {
+	filter := isSource([]resourcesynccontroller.ResourceLocation{{Namespace: "operator", Name: "ca-bundle"}})
+	if !filter(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "ca-bundle"}}) {
+		t.Error("expected the source config map to match")
+	}
+	if filter(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "ca-bundle"}}) {
+		t.Error("expected a config map of the same name in another namespace not to match")
+	}
+}
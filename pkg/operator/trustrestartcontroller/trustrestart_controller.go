@@ -0,0 +1,311 @@
+package trustrestartcontroller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// TrustedCAsAnnotation is set on the deployment to the fingerprints of the CAs its running pods trust.
+	TrustedCAsAnnotation = "operator.openshift.io/trusted-cas"
+	// RestartStartedAnnotation is set on the deployment while its pods are restarted. Pods created before the time
+	// it holds are restarted.
+	RestartStartedAnnotation = "operator.openshift.io/trust-restart-started"
+
+	caBundleKey = "ca-bundle.crt"
+)
+
+// ConnectivityCheckFunc verifies that the restarted operand still works with the new trust, e.g. by connecting to it.
+type ConnectivityCheckFunc func(ctx context.Context) error
+
+// TrustRestartController restarts the pods of a deployment one by one when CAs are removed from the CA bundle they
+// trust, because processes usually read the trusted CAs at start only and keep trusting removed CAs otherwise.
+// Added CAs don't trigger a restart, they are expected to be part of a rotation, which removes the old CA later.
+//
+// The next pod is only restarted when all pods of the deployment are ready. When a connectivity check is given, it
+// must pass after each restart. Otherwise the restart is aborted with <name>Degraded=True and the remaining pods keep
+// the old trust until the check passes. <name>Progressing is true during the restart.
+type TrustRestartController struct {
+	name           string
+	operatorClient v1helpers.OperatorClient
+	clock          clock.Clock
+
+	caBundleNamespace   string
+	caBundleName        string
+	deploymentNamespace string
+	deploymentName      string
+	connectivityCheck   ConnectivityCheckFunc
+
+	kubeClient       kubernetes.Interface
+	configMapLister  corev1listers.ConfigMapLister
+	deploymentLister appsv1listers.DeploymentLister
+	podLister        corev1listers.PodLister
+	recorder         events.Recorder
+}
+
+// NewTrustRestartController returns a controller restarting the pods of the deployment when CAs are removed from the
+// ca-bundle.crt key of the CA bundle config map. The connectivity check is optional. The informers must cover the
+// namespaces of the config map and of the deployment.
+func NewTrustRestartController(
+	name string,
+	caBundleNamespace, caBundleName string,
+	deploymentNamespace, deploymentName string,
+	connectivityCheck ConnectivityCheckFunc,
+	recorder events.Recorder,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+) factory.Controller {
+	configMapInformer := kubeInformersForNamespaces.InformersFor(caBundleNamespace).Core().V1().ConfigMaps()
+	deploymentInformer := kubeInformersForNamespaces.InformersFor(deploymentNamespace).Apps().V1().Deployments()
+	podInformer := kubeInformersForNamespaces.InformersFor(deploymentNamespace).Core().V1().Pods()
+
+	c := &TrustRestartController{
+		name:           name,
+		operatorClient: operatorClient,
+		clock:          clock.RealClock{},
+
+		caBundleNamespace:   caBundleNamespace,
+		caBundleName:        caBundleName,
+		deploymentNamespace: deploymentNamespace,
+		deploymentName:      deploymentName,
+		connectivityCheck:   connectivityCheck,
+
+		kubeClient:       kubeClient,
+		configMapLister:  configMapInformer.Lister(),
+		deploymentLister: deploymentInformer.Lister(),
+		podLister:        podInformer.Lister(),
+		recorder:         recorder,
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		configMapInformer.Informer(),
+		deploymentInformer.Informer(),
+		podInformer.Informer(),
+	).WithSync(
+		c.sync,
+	).ResyncEvery(
+		30*time.Second,
+	).ToController(
+		c.name,
+		recorder.WithComponentSuffix(strings.ToLower(name)+"-trust-restart"),
+	)
+}
+
+func (c *TrustRestartController) Name() string {
+	return c.name
+}
+
+func (c *TrustRestartController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	opSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if apierrors.IsNotFound(err) && management.IsOperatorRemovable() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if opSpec.ManagementState != opv1.Managed {
+		return nil
+	}
+
+	progressingCondition := opv1.OperatorCondition{
+		Type:   c.name + opv1.OperatorStatusTypeProgressing,
+		Status: opv1.ConditionFalse,
+	}
+	degradedCondition := opv1.OperatorCondition{
+		Type:   c.name + opv1.OperatorStatusTypeDegraded,
+		Status: opv1.ConditionFalse,
+	}
+	syncErr := c.syncManaged(ctx, &progressingCondition, &degradedCondition)
+
+	if _, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient,
+		v1helpers.UpdateConditionFn(progressingCondition),
+		v1helpers.UpdateConditionFn(degradedCondition),
+	); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (c *TrustRestartController) syncManaged(ctx context.Context, progressingCondition, degradedCondition *opv1.OperatorCondition) error {
+	caBundle, err := c.configMapLister.ConfigMaps(c.caBundleNamespace).Get(c.caBundleName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	trusted, err := fingerprints([]byte(caBundle.Data[caBundleKey]))
+	if err != nil {
+		degradedCondition.Status = opv1.ConditionTrue
+		degradedCondition.Reason = "InvalidCABundle"
+		degradedCondition.Message = fmt.Sprintf("configmap/%s -n %s: %v", c.caBundleName, c.caBundleNamespace, err)
+		return nil
+	}
+
+	deployment, err := c.deploymentLister.Deployments(c.deploymentNamespace).Get(c.deploymentName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previous, recorded := deployment.Annotations[TrustedCAsAnnotation]
+	started, restarting := deployment.Annotations[RestartStartedAnnotation]
+	if !restarting {
+		removed := sets.NewString(strings.Split(previous, ",")...).Difference(trusted).Delete("")
+		if !recorded || len(removed) == 0 {
+			// the pods were started with the current CAs or the CAs were only added
+			if previous != strings.Join(trusted.List(), ",") {
+				return c.updateAnnotations(ctx, deployment, trusted, "")
+			}
+			return nil
+		}
+		c.recorder.Eventf("TrustRestartStarted", "Restarting the pods of deployment/%s -n %s because CAs %s were removed from configmap/%s -n %s",
+			c.deploymentName, c.deploymentNamespace, strings.Join(removed.List(), ", "), c.caBundleName, c.caBundleNamespace)
+		started = c.clock.Now().UTC().Format(time.RFC3339)
+		if err := c.updateAnnotations(ctx, deployment, sets.NewString(strings.Split(previous, ",")...), started); err != nil {
+			return err
+		}
+	}
+	startedTime, err := time.Parse(time.RFC3339, started)
+	if err != nil {
+		// restart all pods
+		startedTime = c.clock.Now()
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	pods, err := c.podLister.Pods(c.deploymentNamespace).List(selector)
+	if err != nil {
+		return err
+	}
+	desiredReplicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desiredReplicas = *deployment.Spec.Replicas
+	}
+
+	var ready, restarted int
+	var oldPods []*corev1.Pod
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && isPodReady(pod) {
+			ready++
+		}
+		if pod.CreationTimestamp.Time.Before(startedTime) {
+			oldPods = append(oldPods, pod)
+		} else {
+			restarted++
+		}
+	}
+	progressingCondition.Status = opv1.ConditionTrue
+	progressingCondition.Reason = "RestartingForTrustChange"
+	progressingCondition.Message = fmt.Sprintf("deployment/%s -n %s: %d/%d pods have been restarted for the new CA bundle", c.deploymentName, c.deploymentNamespace, restarted, len(pods))
+
+	// wait for the previous restart to finish and for the replacement to become ready
+	if ready < len(pods) || int32(ready) < desiredReplicas {
+		return nil
+	}
+
+	if restarted > 0 && c.connectivityCheck != nil {
+		if err := c.connectivityCheck(ctx); err != nil {
+			degradedCondition.Status = opv1.ConditionTrue
+			degradedCondition.Reason = "TrustRestartAborted"
+			degradedCondition.Message = fmt.Sprintf("deployment/%s -n %s: restart for the new CA bundle aborted after %d/%d pods: %v", c.deploymentName, c.deploymentNamespace, restarted, len(pods), err)
+			c.recorder.Warningf("TrustRestartAborted", "Restart of deployment/%s -n %s aborted, the new CA bundle breaks connections: %v", c.deploymentName, c.deploymentNamespace, err)
+			return nil
+		}
+	}
+
+	if len(oldPods) == 0 {
+		c.recorder.Eventf("TrustRestartCompleted", "Restarted all pods of deployment/%s -n %s for the new CA bundle", c.deploymentName, c.deploymentNamespace)
+		progressingCondition.Status = opv1.ConditionFalse
+		progressingCondition.Reason = ""
+		progressingCondition.Message = ""
+		return c.updateAnnotations(ctx, deployment, trusted, "")
+	}
+
+	// restart the oldest pod first, like a rollout replaces the oldest pods first
+	sort.Slice(oldPods, func(i, j int) bool {
+		return oldPods[i].CreationTimestamp.Before(&oldPods[j].CreationTimestamp)
+	})
+	pod := oldPods[0]
+	c.recorder.Eventf("TrustRestartPod", "Restarting pod/%s -n %s for the new CA bundle", pod.Name, pod.Namespace)
+	// we use eviction, not deletion.  Eviction honors PDBs.
+	return c.kubeClient.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+	})
+}
+
+// updateAnnotations records the trusted CAs and the start of the restart, an empty start finishes the restart.
+func (c *TrustRestartController) updateAnnotations(ctx context.Context, deployment *appsv1.Deployment, trusted sets.String, started string) error {
+	deploymentCopy := deployment.DeepCopy()
+	if deploymentCopy.Annotations == nil {
+		deploymentCopy.Annotations = map[string]string{}
+	}
+	deploymentCopy.Annotations[TrustedCAsAnnotation] = strings.Join(trusted.Delete("").List(), ",")
+	if len(started) > 0 {
+		deploymentCopy.Annotations[RestartStartedAnnotation] = started
+	} else {
+		delete(deploymentCopy.Annotations, RestartStartedAnnotation)
+	}
+	_, err := c.kubeClient.AppsV1().Deployments(deploymentCopy.Namespace).Update(ctx, deploymentCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Updated the trust annotations of deployment/%s -n %s", deploymentCopy.Name, deploymentCopy.Namespace)
+	return nil
+}
+
+// fingerprints returns short fingerprints of the certificates of the PEM bundle.
+func fingerprints(caBundle []byte) (sets.String, error) {
+	ret := sets.NewString()
+	if len(caBundle) == 0 {
+		return ret, nil
+	}
+	certs, err := crypto.CertsFromPEM(caBundle)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		hash := sha256.Sum256(cert.Raw)
+		ret.Insert(hex.EncodeToString(hash[:])[:16])
+	}
+	return ret, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package trustrestartcontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func newCABundle(t *testing.T, names ...string) (string, string) {
+	var certs []byte
+	for _, name := range names {
+		ca, err := crypto.MakeSelfSignedCAConfigForDuration(name, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pem, err := crypto.EncodeCertificates(ca.Certs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certs = append(certs, pem...)
+	}
+	trusted, err := fingerprints(certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(certs), trusted.List()[0]
+}
+
+func newPod(name string, created time.Time, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: name, Labels: map[string]string{"app": "operand"}, CreationTimestamp: metav1.NewTime(created)},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Minute)
+	newBundle, newCA := newCABundle(t, "new")
+
+	tests := []struct {
+		name                string
+		annotations         map[string]string
+		pods                []*corev1.Pod
+		connectivityErr     error
+		expectedAnnotations map[string]string
+		expectedEvicted     string
+		expectedProgressing opv1.ConditionStatus
+		expectedDegraded    opv1.ConditionStatus
+	}{
+		{
+			name:                "first observation records the trusted CAs",
+			pods:                []*corev1.Pod{newPod("a", started.Add(-time.Hour), true)},
+			expectedAnnotations: map[string]string{TrustedCAsAnnotation: newCA},
+			expectedProgressing: opv1.ConditionFalse,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+		{
+			name:                "added CA does not restart",
+			annotations:         map[string]string{TrustedCAsAnnotation: ""},
+			pods:                []*corev1.Pod{newPod("a", started.Add(-time.Hour), true)},
+			expectedAnnotations: map[string]string{TrustedCAsAnnotation: newCA},
+			expectedProgressing: opv1.ConditionFalse,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+		{
+			name:        "removed CA starts the restart with the oldest pod",
+			annotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef," + newCA},
+			pods: []*corev1.Pod{
+				newPod("a", started.Add(-time.Hour), true),
+				newPod("b", started.Add(-2*time.Hour), true),
+			},
+			expectedAnnotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef," + newCA, RestartStartedAnnotation: now.Format(time.RFC3339)},
+			expectedEvicted:     "b",
+			expectedProgressing: opv1.ConditionTrue,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+		{
+			name:        "restart waits for readiness",
+			annotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef", RestartStartedAnnotation: started.Format(time.RFC3339)},
+			pods: []*corev1.Pod{
+				newPod("a", started.Add(-time.Hour), true),
+				newPod("c", started.Add(time.Second), false),
+			},
+			expectedProgressing: opv1.ConditionTrue,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+		{
+			name:        "restart continues when the connectivity check passes",
+			annotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef", RestartStartedAnnotation: started.Format(time.RFC3339)},
+			pods: []*corev1.Pod{
+				newPod("a", started.Add(-time.Hour), true),
+				newPod("c", started.Add(time.Second), true),
+			},
+			expectedEvicted:     "a",
+			expectedProgressing: opv1.ConditionTrue,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+		{
+			name:        "restart is aborted when the connectivity check fails",
+			annotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef", RestartStartedAnnotation: started.Format(time.RFC3339)},
+			pods: []*corev1.Pod{
+				newPod("a", started.Add(-time.Hour), true),
+				newPod("c", started.Add(time.Second), true),
+			},
+			connectivityErr:     errors.New("x509: certificate signed by unknown authority"),
+			expectedProgressing: opv1.ConditionTrue,
+			expectedDegraded:    opv1.ConditionTrue,
+		},
+		{
+			name:        "restart completes when all pods were restarted",
+			annotations: map[string]string{TrustedCAsAnnotation: "0123456789abcdef", RestartStartedAnnotation: started.Format(time.RFC3339)},
+			pods: []*corev1.Pod{
+				newPod("c", started.Add(time.Second), true),
+				newPod("d", started.Add(2*time.Second), true),
+			},
+			expectedAnnotations: map[string]string{TrustedCAsAnnotation: newCA},
+			expectedProgressing: opv1.ConditionFalse,
+			expectedDegraded:    opv1.ConditionFalse,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			caBundle := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "config", Name: "ca-bundle"},
+				Data:       map[string]string{"ca-bundle.crt": newBundle},
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "operand", Annotations: test.annotations},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "operand"}},
+				},
+			}
+			objects := []runtime.Object{caBundle, deployment}
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			deploymentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMapIndexer.Add(caBundle)
+			deploymentIndexer.Add(deployment)
+			for _, pod := range test.pods {
+				podIndexer.Add(pod)
+				objects = append(objects, pod)
+			}
+			kubeClient := fake.NewSimpleClientset(objects...)
+			var evicted string
+			kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					evicted = action.(clienttesting.CreateAction).GetObject().(metav1.Object).GetName()
+				}
+				return true, nil, nil
+			})
+			operatorClient := v1helpers.NewFakeOperatorClient(&opv1.OperatorSpec{ManagementState: opv1.Managed}, &opv1.OperatorStatus{}, nil)
+
+			c := &TrustRestartController{
+				name:                "Operand",
+				operatorClient:      operatorClient,
+				clock:               clocktesting.NewFakeClock(now),
+				caBundleNamespace:   "config",
+				caBundleName:        "ca-bundle",
+				deploymentNamespace: "operand",
+				deploymentName:      "operand",
+				connectivityCheck: func(ctx context.Context) error {
+					return test.connectivityErr
+				},
+				kubeClient:       kubeClient,
+				configMapLister:  corev1listers.NewConfigMapLister(configMapIndexer),
+				deploymentLister: appsv1listers.NewDeploymentLister(deploymentIndexer),
+				podLister:        corev1listers.NewPodLister(podIndexer),
+				recorder:         events.NewInMemoryRecorder("test"),
+			}
+			if err := c.sync(context.TODO(), factory.NewSyncContext("test", c.recorder)); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := kubeClient.AppsV1().Deployments("operand").Get(context.TODO(), "operand", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedAnnotations := test.expectedAnnotations
+			if expectedAnnotations == nil {
+				expectedAnnotations = test.annotations
+			}
+			if len(actual.Annotations) != len(expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", expectedAnnotations, actual.Annotations)
+			}
+			for k, v := range expectedAnnotations {
+				if actual.Annotations[k] != v {
+					t.Errorf("expected annotations %v, got %v", expectedAnnotations, actual.Annotations)
+				}
+			}
+			if evicted != test.expectedEvicted {
+				t.Errorf("expected evicted pod %q, got %q", test.expectedEvicted, evicted)
+			}
+
+			_, status, _, _ := operatorClient.GetOperatorState()
+			if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandProgressing"); condition == nil || condition.Status != test.expectedProgressing {
+				t.Errorf("expected OperandProgressing=%s, got %v", test.expectedProgressing, condition)
+			}
+			if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandDegraded"); condition == nil || condition.Status != test.expectedDegraded {
+				t.Errorf("expected OperandDegraded=%s, got %v", test.expectedDegraded, condition)
+			}
+		})
+	}
+}
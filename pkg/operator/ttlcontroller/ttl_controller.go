@@ -0,0 +1,138 @@
+package ttlcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// EphemeralLabel marks objects with a limited lifetime, so they can be listed without walking the whole namespace.
+	EphemeralLabel = "operator.openshift.io/ephemeral"
+	// ExpiresAtAnnotation holds the time in RFC3339 format after which the object is deleted.
+	ExpiresAtAnnotation = "operator.openshift.io/expires-at"
+)
+
+// SetTTL stamps the object so that it is deleted by the TTLController once the ttl has passed.
+// Use it for debug config maps, one-off jobs, canary pods and other ephemeral artifacts before creating them.
+func SetTTL(obj metav1.Object, ttl time.Duration) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[EphemeralLabel] = "true"
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ExpiresAtAnnotation] = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// Expired returns true when the object has a TTL that passed at the given time.
+func Expired(obj metav1.Object, now time.Time) (bool, error) {
+	expiresAt, ok := obj.GetAnnotations()[ExpiresAtAnnotation]
+	if !ok {
+		return false, nil
+	}
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation on %s/%s: %v", ExpiresAtAnnotation, obj.GetNamespace(), obj.GetName(), err)
+	}
+	return now.After(t), nil
+}
+
+// ephemeralSelector selects the objects stamped by SetTTL.
+var ephemeralSelector = labels.SelectorFromSet(labels.Set{EphemeralLabel: "true"})
+
+// TTLController deletes expired ephemeral objects of the given resources in a namespace.
+type TTLController struct {
+	namespace     string
+	resources     []schema.GroupVersionResource
+	dynamicClient dynamic.Interface
+	informers     dynamicinformer.DynamicSharedInformerFactory
+	eventRecorder events.Recorder
+
+	now func() time.Time
+}
+
+// NewTTLController returns a controller which periodically deletes objects stamped by SetTTL after they expire.
+// The objects are read from informers of dynamicInformers, which must include the namespace, e.g. built with
+// dynamicinformer.NewFilteredDynamicSharedInformerFactory. The caller starts the informer factory.
+func NewTTLController(
+	namespace string,
+	resources []schema.GroupVersionResource,
+	dynamicClient dynamic.Interface,
+	dynamicInformers dynamicinformer.DynamicSharedInformerFactory,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &TTLController{
+		namespace:     namespace,
+		resources:     resources,
+		dynamicClient: dynamicClient,
+		informers:     dynamicInformers,
+		eventRecorder: eventRecorder.WithComponentSuffix("ttl-controller"),
+		now:           time.Now,
+	}
+	f := factory.New().ResyncEvery(time.Minute).WithSync(c.sync)
+	for _, resource := range resources {
+		f = f.WithInformers(dynamicInformers.ForResource(resource).Informer())
+	}
+	return f.ToController("TTLController", eventRecorder)
+}
+
+func (c *TTLController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	var errs []error
+	for _, resource := range c.resources {
+		client := c.dynamicClient.Resource(resource).Namespace(c.namespace)
+		list, err := c.informers.ForResource(resource).Lister().ByNamespace(c.namespace).List(ephemeralSelector)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, item := range list {
+			obj, err := meta.Accessor(item)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			expired, err := Expired(obj, c.now())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !expired || obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			klog.V(4).Infof("Deleting expired %s %s/%s", resource.Resource, obj.GetNamespace(), obj.GetName())
+			uid := obj.GetUID()
+			propagation := metav1.DeletePropagationBackground
+			err = client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{
+				// make sure we do not delete a newer object with the same name
+				Preconditions:     &metav1.Preconditions{UID: &uid},
+				PropagationPolicy: &propagation,
+			})
+			if err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, err)
+				continue
+			}
+			c.eventRecorder.Eventf("ExpiredObjectDeleted", "Deleted expired %s %s/%s", resource.Resource, obj.GetNamespace(), obj.GetName())
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,95 @@
+package ttlcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestSetTTL(t *testing.T) {
+	cm := &corev1.ConfigMap{}
+	SetTTL(cm, time.Hour)
+	if cm.Labels[EphemeralLabel] != "true" {
+		t.Errorf("expected ephemeral label, got %v", cm.Labels)
+	}
+	if expired, err := Expired(cm, time.Now()); err != nil || expired {
+		t.Errorf("expected not expired, got %v: %v", expired, err)
+	}
+	if expired, err := Expired(cm, time.Now().Add(2*time.Hour)); err != nil || !expired {
+		t.Errorf("expected expired, got %v: %v", expired, err)
+	}
+	if expired, err := Expired(&corev1.ConfigMap{}, time.Now()); err != nil || expired {
+		t.Errorf("expected objects without TTL to never expire, got %v: %v", expired, err)
+	}
+	cm.Annotations[ExpiresAtAnnotation] = "tomorrow"
+	if _, err := Expired(cm, time.Now()); err == nil {
+		t.Errorf("expected invalid annotation to be reported")
+	}
+}
+
+func TestSync(t *testing.T) {
+	configMap := func(name string, expiresAt time.Time, ephemeral bool) runtime.Object {
+		cm := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Annotations: map[string]string{ExpiresAtAnnotation: expiresAt.Format(time.RFC3339)}},
+		}
+		if ephemeral {
+			cm.Labels = map[string]string{EphemeralLabel: "true"}
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &unstructured.Unstructured{Object: u}
+	}
+
+	now := time.Now()
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	objects := []runtime.Object{
+		configMap("expired", now.Add(-time.Minute), true),
+		configMap("fresh", now.Add(time.Hour), true),
+		configMap("not-ephemeral", now.Add(-time.Minute), false),
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, objects...)
+	informers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, "ns", nil)
+	for _, obj := range objects {
+		if err := informers.ForResource(gvr).Informer().GetIndexer().Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recorder := events.NewInMemoryRecorder("test")
+	c := &TTLController{
+		namespace:     "ns",
+		resources:     []schema.GroupVersionResource{gvr},
+		dynamicClient: client,
+		informers:     informers,
+		eventRecorder: recorder,
+		now:           func() time.Time { return now },
+	}
+	if err := c.sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := client.Resource(gvr).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := map[string]bool{}
+	for _, item := range list.Items {
+		remaining[item.GetName()] = true
+	}
+	if remaining["expired"] || !remaining["fresh"] || !remaining["not-ephemeral"] {
+		t.Errorf("unexpected remaining objects: %v", remaining)
+	}
+}
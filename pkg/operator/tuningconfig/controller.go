@@ -0,0 +1,68 @@
+package tuningconfig
+
+import (
+	"context"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// TuningConfigController applies the data of a config map to the knobs, so operators can be tuned without a restart.
+// Invalid configuration is rejected as a whole and the previous values are kept.
+type TuningConfigController struct {
+	namespace       string
+	configMapName   string
+	knobs           *Knobs
+	configMapLister corev1listers.ConfigMapLister
+	eventRecorder   events.Recorder
+}
+
+// NewTuningConfigController returns a controller watching the named config map.
+// The config map informer must be scoped to a namespace containing the config map.
+func NewTuningConfigController(
+	namespace, configMapName string,
+	knobs *Knobs,
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &TuningConfigController{
+		namespace:       namespace,
+		configMapName:   configMapName,
+		knobs:           knobs,
+		configMapLister: configMapInformer.Lister(),
+		eventRecorder:   eventRecorder.WithComponentSuffix("tuning-config-controller"),
+	}
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(configMapName), configMapInformer.Informer()).
+		WithSync(c.sync).
+		ToController("TuningConfigController", eventRecorder)
+}
+
+func (c *TuningConfigController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	values := map[string]string{}
+	configMap, err := c.configMapLister.ConfigMaps(c.namespace).Get(c.configMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+		// a missing config map resets all knobs to their defaults
+	case err != nil:
+		return err
+	default:
+		values = configMap.Data
+	}
+
+	changes, err := c.knobs.Apply(values)
+	if err != nil {
+		// retrying does not help, the config map has to be fixed
+		c.eventRecorder.Warningf("TuningConfigInvalid", "Ignoring config map %s/%s: %v", c.namespace, c.configMapName, err)
+		return nil
+	}
+	if len(changes) > 0 {
+		c.eventRecorder.Eventf("TuningConfigApplied", "Applied tuning from config map %s/%s: %s", c.namespace, c.configMapName, strings.Join(changes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package tuningconfig
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// ControllerKnobs tune a factory controller: the interval of its periodical resync, the timeout of a sync and the
+// delays of its queue retrying failed syncs. The retry delay is the larger of the per item exponential backoff between
+// RetryBaseDelay and RetryMaxDelay and the overall RetryQPS and RetryBurst token bucket, like the default controller
+// rate limiter. RetryQPS and RetryBurst only pace the requeues of failed syncs, they do not limit the requests of the
+// controller to the API server, which follow the QPS and Burst of its rest.Config.
+type ControllerKnobs struct {
+	ResyncInterval *DurationKnob
+	SyncTimeout    *DurationKnob
+	RetryBaseDelay *DurationKnob
+	RetryMaxDelay  *DurationKnob
+	RetryQPS       *FloatKnob
+	RetryBurst     *FloatKnob
+}
+
+// AddControllerKnobs registers the knobs of the named controller as "<controller>.resyncInterval",
+// "<controller>.syncTimeout", "<controller>.retryBaseDelay", "<controller>.retryMaxDelay", "<controller>.retryQPS"
+// and "<controller>.retryBurst". A sync timeout of 0 disables the timeout. The retry knobs default to the values of
+// workqueue.DefaultControllerRateLimiter.
+func (k *Knobs) AddControllerKnobs(controller string, resyncInterval, syncTimeout time.Duration) *ControllerKnobs {
+	return &ControllerKnobs{
+		ResyncInterval: k.AddDuration(controller+".resyncInterval", resyncInterval, 10*time.Second, 24*time.Hour),
+		SyncTimeout:    k.AddDuration(controller+".syncTimeout", syncTimeout, 0, time.Hour),
+		RetryBaseDelay: k.AddDuration(controller+".retryBaseDelay", 5*time.Millisecond, time.Millisecond, time.Minute),
+		RetryMaxDelay:  k.AddDuration(controller+".retryMaxDelay", 1000*time.Second, time.Second, 2*time.Hour),
+		RetryQPS:       k.AddFloat(controller+".retryQPS", 10, 0.1, 1000),
+		RetryBurst:     k.AddFloat(controller+".retryBurst", 100, 1, 10000),
+	}
+}
+
+// Configure makes the controller built by the factory read the knobs: the periodical resync, the sync timeout and the
+// queue rate limiter follow the current values without a restart.
+func (c *ControllerKnobs) Configure(f *factory.Factory) *factory.Factory {
+	return f.
+		ResyncEveryFunc(c.ResyncInterval.Get).
+		WithSyncTimeout(c.SyncTimeout.Get).
+		WithRateLimiter(c.RateLimiter())
+}
+
+// RateLimiter returns a queue rate limiter reading the retry knobs on every retry.
+func (c *ControllerKnobs) RateLimiter() workqueue.RateLimiter {
+	return &knobsRateLimiter{
+		knobs:    c,
+		failures: map[interface{}]int{},
+		bucket:   rate.NewLimiter(rate.Limit(c.RetryQPS.Get()), int(c.RetryBurst.Get())),
+	}
+}
+
+// knobsRateLimiter is workqueue.DefaultControllerRateLimiter with its parameters read from the knobs.
+type knobsRateLimiter struct {
+	knobs *ControllerKnobs
+
+	lock     sync.Mutex
+	failures map[interface{}]int
+	bucket   *rate.Limiter
+}
+
+var _ workqueue.RateLimiter = &knobsRateLimiter{}
+
+func (r *knobsRateLimiter) When(item interface{}) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+	maxDelay := r.knobs.RetryMaxDelay.Get()
+	backoff := float64(r.knobs.RetryBaseDelay.Get().Nanoseconds()) * math.Pow(2, float64(exp))
+	delay := maxDelay
+	if backoff < float64(maxDelay.Nanoseconds()) {
+		delay = time.Duration(backoff)
+	}
+
+	if limit := rate.Limit(r.knobs.RetryQPS.Get()); r.bucket.Limit() != limit {
+		r.bucket.SetLimit(limit)
+	}
+	if burst := int(r.knobs.RetryBurst.Get()); r.bucket.Burst() != burst {
+		r.bucket.SetBurst(burst)
+	}
+	if bucketDelay := r.bucket.Reserve().Delay(); bucketDelay > delay {
+		delay = bucketDelay
+	}
+	return delay
+}
+
+func (r *knobsRateLimiter) NumRequeues(item interface{}) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.failures[item]
+}
+
+func (r *knobsRateLimiter) Forget(item interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.failures, item)
+}
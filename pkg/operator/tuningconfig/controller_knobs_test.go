@@ -0,0 +1,42 @@
+package tuningconfig
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestControllerKnobsRateLimiter(t *testing.T) {
+	knobs := NewKnobs()
+	controllerKnobs := knobs.AddControllerKnobs("foo", time.Minute, 0)
+	limiter := controllerKnobs.RateLimiter()
+
+	for i, expected := range []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond} {
+		if delay := limiter.When("key"); delay != expected {
+			t.Errorf("expected retry %d to be delayed by %v, got %v", i, expected, delay)
+		}
+	}
+
+	if _, err := knobs.Apply(map[string]string{"foo.retryBaseDelay": "1s", "foo.retryMaxDelay": "5s"}); err != nil {
+		t.Fatal(err)
+	}
+	if delay := limiter.When("key"); delay != 5*time.Second {
+		t.Errorf("expected the changed max delay 5s, got %v", delay)
+	}
+	if requeues := limiter.NumRequeues("key"); requeues != 4 {
+		t.Errorf("expected 4 requeues, got %d", requeues)
+	}
+	limiter.Forget("key")
+	if delay := limiter.When("key"); delay != time.Second {
+		t.Errorf("expected the changed base delay 1s after forget, got %v", delay)
+	}
+}
+
+func TestControllerKnobsNames(t *testing.T) {
+	knobs := NewKnobs()
+	knobs.AddControllerKnobs("foo", time.Minute, time.Minute)
+	expected := "foo.resyncInterval,foo.retryBaseDelay,foo.retryBurst,foo.retryMaxDelay,foo.retryQPS,foo.syncTimeout"
+	if names := knobs.Names(); strings.Join(names, ",") != expected {
+		t.Errorf("unexpected knob names %v", names)
+	}
+}
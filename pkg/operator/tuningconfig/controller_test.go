@@ -0,0 +1,106 @@
+package tuningconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestKnobsApply(t *testing.T) {
+	knobs := NewKnobs()
+	resync := knobs.AddDuration("resyncInterval", time.Minute, 10*time.Second, time.Hour)
+	qps := knobs.AddFloat("retryQPS", 50, 1, 500)
+	feature := knobs.AddBool("enableFoo", false)
+
+	changes, err := knobs.Apply(map[string]string{"resyncInterval": "5m", "enableFoo": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changes, ";") != "enableFoo: false -> true;resyncInterval: 1m0s -> 5m0s" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if resync.Get() != 5*time.Minute || !feature.Get() || qps.Get() != 50 {
+		t.Errorf("unexpected values: %v %v %v", resync.Get(), feature.Get(), qps.Get())
+	}
+
+	// any invalid value rejects the whole change
+	for _, invalid := range []map[string]string{
+		{"resyncInterval": "1s", "retryQPS": "100"},
+		{"retryQPS": "many"},
+		{"unknown": "1"},
+	} {
+		if _, err := knobs.Apply(invalid); err == nil {
+			t.Errorf("expected %v to be rejected", invalid)
+		}
+	}
+	if resync.Get() != 5*time.Minute || qps.Get() != 50 {
+		t.Errorf("expected values to be kept after invalid configuration: %v %v", resync.Get(), qps.Get())
+	}
+
+	// missing keys reset to defaults
+	changes, err = knobs.Apply(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || resync.Get() != time.Minute || feature.Get() {
+		t.Errorf("expected reset to defaults, got %v", changes)
+	}
+}
+
+func TestSync(t *testing.T) {
+	knobs := NewKnobs()
+	qps := knobs.AddFloat("retryQPS", 50, 1, 500)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	recorder := events.NewInMemoryRecorder("test")
+	c := &TuningConfigController{
+		namespace:       "ns",
+		configMapName:   "tuning",
+		knobs:           knobs,
+		configMapLister: corev1listers.NewConfigMapLister(indexer),
+		eventRecorder:   recorder,
+	}
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tuning"}, Data: map[string]string{"retryQPS": "100"}}
+	if err := indexer.Add(cm); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if qps.Get() != 100 {
+		t.Errorf("expected QPS 100, got %v", qps.Get())
+	}
+
+	cm = cm.DeepCopy()
+	cm.Data["retryQPS"] = "0"
+	if err := indexer.Update(cm); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if qps.Get() != 100 {
+		t.Errorf("expected QPS to stay 100, got %v", qps.Get())
+	}
+
+	reasons := []string{}
+	for _, event := range recorder.Events() {
+		reasons = append(reasons, event.Reason)
+	}
+	if strings.Join(reasons, ",") != "TuningConfigApplied,TuningConfigInvalid" {
+		t.Errorf("unexpected events: %v", reasons)
+	}
+	if !strings.Contains(recorder.Events()[0].Message, "retryQPS: 50 -> 100") {
+		t.Errorf("expected the delta in the event, got %q", recorder.Events()[0].Message)
+	}
+}
@@ -0,0 +1,234 @@
+package tuningconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// knob is a single tuning value that can be changed at runtime.
+type knob interface {
+	// validate parses the value and checks it is within the allowed range.
+	validate(value string) error
+	// set applies a previously validated value, an empty value resets to the default.
+	set(value string)
+	// String returns the current value.
+	String() string
+}
+
+// Knobs is a set of named tuning values which are safe to change while the operator runs.
+// Consumers read the values every time they need them, e.g. when computing the next resync.
+type Knobs struct {
+	lock  sync.RWMutex
+	knobs map[string]knob
+}
+
+func NewKnobs() *Knobs {
+	return &Knobs{knobs: map[string]knob{}}
+}
+
+func (k *Knobs) add(name string, value knob) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if _, exists := k.knobs[name]; exists {
+		panic(fmt.Sprintf("tuning knob %q registered twice", name))
+	}
+	k.knobs[name] = value
+}
+
+// Names returns the sorted names of all knobs.
+func (k *Knobs) Names() []string {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	names := make([]string, 0, len(k.knobs))
+	for name := range k.knobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply validates all values and, only when all of them are valid, applies them. Knobs missing in values are reset
+// to their defaults. It returns the applied changes as "name: old -> new" sorted by name.
+func (k *Knobs) Apply(values map[string]string) ([]string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	var errs []string
+	for name, value := range values {
+		knob, ok := k.knobs[name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown tuning knob %q", name))
+			continue
+		}
+		if err := knob.validate(value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid tuning configuration: %v", errs)
+	}
+
+	changes := []string{}
+	for name, knob := range k.knobs {
+		old := knob.String()
+		knob.set(values[name])
+		if current := knob.String(); current != old {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, old, current))
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// DurationKnob is a duration, like a resync interval, bounded by min and max.
+type DurationKnob struct {
+	defaultValue, min, max time.Duration
+
+	lock  sync.RWMutex
+	value time.Duration
+}
+
+// AddDuration registers a duration knob.
+func (k *Knobs) AddDuration(name string, defaultValue, min, max time.Duration) *DurationKnob {
+	ret := &DurationKnob{defaultValue: defaultValue, min: min, max: max, value: defaultValue}
+	k.add(name, ret)
+	return ret
+}
+
+// Get returns the current value.
+func (d *DurationKnob) Get() time.Duration {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.value
+}
+
+func (d *DurationKnob) String() string {
+	return d.Get().String()
+}
+
+func (d *DurationKnob) parse(value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return d.defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < d.min || duration > d.max {
+		return 0, fmt.Errorf("%v is not between %v and %v", duration, d.min, d.max)
+	}
+	return duration, nil
+}
+
+func (d *DurationKnob) validate(value string) error {
+	_, err := d.parse(value)
+	return err
+}
+
+func (d *DurationKnob) set(value string) {
+	duration, _ := d.parse(value)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.value = duration
+}
+
+// FloatKnob is a number, like the QPS of a rate limiter, bounded by min and max.
+type FloatKnob struct {
+	defaultValue, min, max float64
+
+	lock  sync.RWMutex
+	value float64
+}
+
+// AddFloat registers a float knob.
+func (k *Knobs) AddFloat(name string, defaultValue, min, max float64) *FloatKnob {
+	ret := &FloatKnob{defaultValue: defaultValue, min: min, max: max, value: defaultValue}
+	k.add(name, ret)
+	return ret
+}
+
+// Get returns the current value.
+func (f *FloatKnob) Get() float64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.value
+}
+
+func (f *FloatKnob) String() string {
+	return strconv.FormatFloat(f.Get(), 'g', -1, 64)
+}
+
+func (f *FloatKnob) parse(value string) (float64, error) {
+	if len(value) == 0 {
+		return f.defaultValue, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if number < f.min || number > f.max {
+		return 0, fmt.Errorf("%v is not between %v and %v", number, f.min, f.max)
+	}
+	return number, nil
+}
+
+func (f *FloatKnob) validate(value string) error {
+	_, err := f.parse(value)
+	return err
+}
+
+func (f *FloatKnob) set(value string) {
+	number, _ := f.parse(value)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.value = number
+}
+
+// BoolKnob is a feature switch.
+type BoolKnob struct {
+	defaultValue bool
+
+	lock  sync.RWMutex
+	value bool
+}
+
+// AddBool registers a bool knob.
+func (k *Knobs) AddBool(name string, defaultValue bool) *BoolKnob {
+	ret := &BoolKnob{defaultValue: defaultValue, value: defaultValue}
+	k.add(name, ret)
+	return ret
+}
+
+// Get returns the current value.
+func (b *BoolKnob) Get() bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.value
+}
+
+func (b *BoolKnob) String() string {
+	return strconv.FormatBool(b.Get())
+}
+
+func (b *BoolKnob) parse(value string) (bool, error) {
+	if len(value) == 0 {
+		return b.defaultValue, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func (b *BoolKnob) validate(value string) error {
+	_, err := b.parse(value)
+	return err
+}
+
+func (b *BoolKnob) set(value string) {
+	enabled, _ := b.parse(value)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.value = enabled
+}
@@ -0,0 +1,165 @@
+package upgradehooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// versionKey is the config map key of the operator version whose upgrade hooks completed.
+	versionKey = "version"
+	// completedHooksKey is the config map key of the comma separated hooks which completed for the upgrade in progress,
+	// so that they are not repeated after a restart of the operator.
+	completedHooksKey = "completedHooks"
+	// completedHooksVersionKey is the config map key of the version the completed hooks ran for. Hooks that completed
+	// for an upgrade to another version, e.g. before a rollback, are run again.
+	completedHooksVersionKey = "completedHooksVersion"
+)
+
+// HookFunc is run once when the operator starts with a new version, e.g. to invalidate caches, migrate data or force
+// certificate rotations. The previous version is empty on the first start after the hooks were introduced.
+type HookFunc func(ctx context.Context, fromVersion, toVersion string) error
+
+type hook struct {
+	name string
+	fn   HookFunc
+}
+
+// Coordinator runs the registered hooks when the operator version differs from the version of the last completed run,
+// which is stored in a config map. Operators should call RunUntilCompleted before starting their controllers, so that
+// normal reconciliation waits for the hooks.
+type Coordinator struct {
+	namespace      string
+	configMapName  string
+	currentVersion string
+	client         corev1client.ConfigMapsGetter
+	recorder       events.Recorder
+
+	hooks []hook
+
+	lock      sync.Mutex
+	completed bool
+}
+
+// NewCoordinator returns a coordinator storing the version in the named config map, e.g. for the version from
+// status.VersionForOperatorFromEnv().
+func NewCoordinator(namespace, configMapName, currentVersion string, client corev1client.ConfigMapsGetter, recorder events.Recorder) *Coordinator {
+	return &Coordinator{
+		namespace:      namespace,
+		configMapName:  configMapName,
+		currentVersion: currentVersion,
+		client:         client,
+		recorder:       recorder,
+	}
+}
+
+// Register adds a hook, hooks run in the order of registration. The name must be unique and stable across versions.
+func (c *Coordinator) Register(name string, fn HookFunc) *Coordinator {
+	c.hooks = append(c.hooks, hook{name: name, fn: fn})
+	return c
+}
+
+// HasCompleted returns true when the hooks of the current version completed.
+func (c *Coordinator) HasCompleted() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.completed
+}
+
+// RunUntilCompleted calls Run until it succeeds or the context is done.
+func (c *Coordinator) RunUntilCompleted(ctx context.Context, interval time.Duration) error {
+	return wait.PollImmediateUntilWithContext(ctx, interval, func(ctx context.Context) (bool, error) {
+		if err := c.Run(ctx); err != nil {
+			klog.Warningf("Upgrade hooks failed, retrying: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// Run runs the hooks which have not completed yet if the version changed. The first hook failing stops the run,
+// the next run continues with it.
+func (c *Coordinator) Run(ctx context.Context) error {
+	if c.HasCompleted() {
+		return nil
+	}
+
+	configMap, err := c.client.ConfigMaps(c.namespace).Get(ctx, c.configMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap, err = c.client.ConfigMaps(c.namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: c.namespace, Name: c.configMapName},
+		}, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	previousVersion := configMap.Data[versionKey]
+	if previousVersion == c.currentVersion {
+		if _, ok := configMap.Data[completedHooksKey]; ok {
+			// an upgrade was rolled back before its hooks completed, a later upgrade runs them again
+			if _, err := c.update(ctx, configMap, c.currentVersion, nil); err != nil {
+				return err
+			}
+		}
+		c.setCompleted()
+		return nil
+	}
+
+	completedHooks := sets.NewString()
+	if len(configMap.Data[completedHooksKey]) > 0 && configMap.Data[completedHooksVersionKey] == c.currentVersion {
+		completedHooks.Insert(strings.Split(configMap.Data[completedHooksKey], ",")...)
+	} else {
+		c.recorder.Eventf("OperatorVersionChanged", "Running upgrade hooks for the operator version change %q -> %q", previousVersion, c.currentVersion)
+	}
+
+	for _, h := range c.hooks {
+		if completedHooks.Has(h.name) {
+			continue
+		}
+		if err := h.fn(ctx, previousVersion, c.currentVersion); err != nil {
+			c.recorder.Warningf("UpgradeHookFailed", "Upgrade hook %q failed for the operator version change %q -> %q: %v", h.name, previousVersion, c.currentVersion, err)
+			return fmt.Errorf("upgrade hook %q failed: %w", h.name, err)
+		}
+		completedHooks.Insert(h.name)
+		if configMap, err = c.update(ctx, configMap, previousVersion, completedHooks); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.update(ctx, configMap, c.currentVersion, nil); err != nil {
+		return err
+	}
+	c.recorder.Eventf("UpgradeHooksCompleted", "Upgrade hooks completed for the operator version change %q -> %q", previousVersion, c.currentVersion)
+	c.setCompleted()
+	return nil
+}
+
+func (c *Coordinator) update(ctx context.Context, configMap *corev1.ConfigMap, version string, completedHooks sets.String) (*corev1.ConfigMap, error) {
+	configMap = configMap.DeepCopy()
+	configMap.Data = map[string]string{versionKey: version}
+	if completedHooks.Len() > 0 {
+		configMap.Data[completedHooksKey] = strings.Join(completedHooks.List(), ",")
+		configMap.Data[completedHooksVersionKey] = c.currentVersion
+	}
+	return c.client.ConfigMaps(c.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+}
+
+func (c *Coordinator) setCompleted() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.completed = true
+}
@@ -0,0 +1,134 @@
+package upgradehooks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestCoordinator(t *testing.T) {
+	tests := []struct {
+		name            string
+		existing        map[string]string
+		failing         string
+		expectedRuns    []string
+		expectedError   bool
+		expectedData    map[string]string
+		expectedEvents  []string
+		expectCompleted bool
+	}{
+		{
+			name:            "first start runs all hooks",
+			expectedRuns:    []string{"a:->2", "b:->2"},
+			expectedData:    map[string]string{"version": "2"},
+			expectedEvents:  []string{"OperatorVersionChanged", "UpgradeHooksCompleted"},
+			expectCompleted: true,
+		},
+		{
+			name:            "same version runs no hooks",
+			existing:        map[string]string{"version": "2"},
+			expectedData:    map[string]string{"version": "2"},
+			expectCompleted: true,
+		},
+		{
+			name:            "version change runs all hooks",
+			existing:        map[string]string{"version": "1"},
+			expectedRuns:    []string{"a:1->2", "b:1->2"},
+			expectedData:    map[string]string{"version": "2"},
+			expectedEvents:  []string{"OperatorVersionChanged", "UpgradeHooksCompleted"},
+			expectCompleted: true,
+		},
+		{
+			name:           "failing hook stops the run",
+			existing:       map[string]string{"version": "1"},
+			failing:        "b",
+			expectedRuns:   []string{"a:1->2", "b:1->2"},
+			expectedError:  true,
+			expectedData:   map[string]string{"version": "1", "completedHooks": "a", "completedHooksVersion": "2"},
+			expectedEvents: []string{"OperatorVersionChanged", "UpgradeHookFailed"},
+		},
+		{
+			name:            "completed hooks are not repeated",
+			existing:        map[string]string{"version": "1", "completedHooks": "a", "completedHooksVersion": "2"},
+			expectedRuns:    []string{"b:1->2"},
+			expectedData:    map[string]string{"version": "2"},
+			expectedEvents:  []string{"UpgradeHooksCompleted"},
+			expectCompleted: true,
+		},
+		{
+			name:            "hooks completed for another version are repeated",
+			existing:        map[string]string{"version": "1", "completedHooks": "a", "completedHooksVersion": "3"},
+			expectedRuns:    []string{"a:1->2", "b:1->2"},
+			expectedData:    map[string]string{"version": "2"},
+			expectedEvents:  []string{"OperatorVersionChanged", "UpgradeHooksCompleted"},
+			expectCompleted: true,
+		},
+		{
+			name:            "rollback clears the completed hooks",
+			existing:        map[string]string{"version": "2", "completedHooks": "a", "completedHooksVersion": "3"},
+			expectedData:    map[string]string{"version": "2"},
+			expectCompleted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if test.existing != nil {
+				client = fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "upgrade"},
+					Data:       test.existing,
+				})
+			}
+			recorder := events.NewInMemoryRecorder("test")
+
+			var runs []string
+			newHook := func(name string) HookFunc {
+				return func(ctx context.Context, fromVersion, toVersion string) error {
+					runs = append(runs, name+":"+fromVersion+"->"+toVersion)
+					if name == test.failing {
+						return errors.New("fail")
+					}
+					return nil
+				}
+			}
+			c := NewCoordinator("ns", "upgrade", "2", client.CoreV1(), recorder).
+				Register("a", newHook("a")).
+				Register("b", newHook("b"))
+
+			err := c.Run(context.TODO())
+			if (err != nil) != test.expectedError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(runs, test.expectedRuns) {
+				t.Errorf("expected runs %v, got %v", test.expectedRuns, runs)
+			}
+			if c.HasCompleted() != test.expectCompleted {
+				t.Errorf("expected completed %v, got %v", test.expectCompleted, c.HasCompleted())
+			}
+
+			configMap, err := client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "upgrade", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(configMap.Data, test.expectedData) {
+				t.Errorf("expected data %v, got %v", test.expectedData, configMap.Data)
+			}
+
+			var reasons []string
+			for _, e := range recorder.Events() {
+				reasons = append(reasons, e.Reason)
+			}
+			if !reflect.DeepEqual(reasons, test.expectedEvents) {
+				t.Errorf("expected events %v, got %v", test.expectedEvents, reasons)
+			}
+		})
+	}
+}
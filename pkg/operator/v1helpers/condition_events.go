@@ -0,0 +1,104 @@
+package v1helpers
+
+import (
+	"context"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// RecordConditionChanges emits an event for every condition whose status or reason differs between oldConditions and
+// newConditions, including added and removed conditions. Message-only changes are not reported to avoid noise.
+func RecordConditionChanges(recorder events.Recorder, oldConditions, newConditions []operatorv1.OperatorCondition) {
+	types := map[string]bool{}
+	for _, c := range oldConditions {
+		types[c.Type] = true
+	}
+	for _, c := range newConditions {
+		types[c.Type] = true
+	}
+	sortedTypes := make([]string, 0, len(types))
+	for t := range types {
+		sortedTypes = append(sortedTypes, t)
+	}
+	sort.Strings(sortedTypes)
+
+	for _, conditionType := range sortedTypes {
+		oldCondition := FindOperatorCondition(oldConditions, conditionType)
+		newCondition := FindOperatorCondition(newConditions, conditionType)
+		switch {
+		case oldCondition == nil:
+			recorder.Eventf("OperatorConditionChanged", "Condition %s set to %s (%s): %s", conditionType, newCondition.Status, newCondition.Reason, newCondition.Message)
+		case newCondition == nil:
+			recorder.Eventf("OperatorConditionChanged", "Condition %s removed, was %s (%s)", conditionType, oldCondition.Status, oldCondition.Reason)
+		case oldCondition.Status != newCondition.Status || oldCondition.Reason != newCondition.Reason:
+			recorder.Eventf("OperatorConditionChanged", "Condition %s changed from %s (%s) to %s (%s): %s", conditionType, oldCondition.Status, oldCondition.Reason, newCondition.Status, newCondition.Reason, newCondition.Message)
+		}
+	}
+}
+
+// NewConditionEventingOperatorClient wraps an OperatorClient to emit an event for every condition change written
+// through it (see RecordConditionChanges), so condition flaps are visible in the event stream without every
+// controller emitting its own events.
+func NewConditionEventingOperatorClient(delegate OperatorClient, recorder events.Recorder) OperatorClient {
+	return &conditionEventingOperatorClient{OperatorClient: delegate, recorder: recorder}
+}
+
+// NewConditionEventingStaticPodOperatorClient is NewConditionEventingOperatorClient for StaticPodOperatorClients.
+func NewConditionEventingStaticPodOperatorClient(delegate StaticPodOperatorClient, recorder events.Recorder) StaticPodOperatorClient {
+	return &conditionEventingStaticPodOperatorClient{StaticPodOperatorClient: delegate, recorder: recorder}
+}
+
+type conditionEventingOperatorClient struct {
+	OperatorClient
+	recorder events.Recorder
+}
+
+func (c *conditionEventingOperatorClient) UpdateOperatorStatus(ctx context.Context, oldResourceVersion string, in *operatorv1.OperatorStatus) (*operatorv1.OperatorStatus, error) {
+	oldConditions := currentConditions(c.OperatorClient)
+	out, err := c.OperatorClient.UpdateOperatorStatus(ctx, oldResourceVersion, in)
+	if err == nil && out != nil {
+		RecordConditionChanges(c.recorder, oldConditions, out.Conditions)
+	}
+	return out, err
+}
+
+type conditionEventingStaticPodOperatorClient struct {
+	StaticPodOperatorClient
+	recorder events.Recorder
+}
+
+func (c *conditionEventingStaticPodOperatorClient) UpdateOperatorStatus(ctx context.Context, oldResourceVersion string, in *operatorv1.OperatorStatus) (*operatorv1.OperatorStatus, error) {
+	oldConditions := currentConditions(c.StaticPodOperatorClient)
+	out, err := c.StaticPodOperatorClient.UpdateOperatorStatus(ctx, oldResourceVersion, in)
+	if err == nil && out != nil {
+		RecordConditionChanges(c.recorder, oldConditions, out.Conditions)
+	}
+	return out, err
+}
+
+func (c *conditionEventingStaticPodOperatorClient) UpdateStaticPodOperatorStatus(ctx context.Context, resourceVersion string, in *operatorv1.StaticPodOperatorStatus) (*operatorv1.StaticPodOperatorStatus, error) {
+	oldConditions := currentConditions(c.StaticPodOperatorClient)
+	out, err := c.StaticPodOperatorClient.UpdateStaticPodOperatorStatus(ctx, resourceVersion, in)
+	if err == nil && out != nil {
+		RecordConditionChanges(c.recorder, oldConditions, out.Conditions)
+	}
+	return out, err
+}
+
+// currentConditions returns a copy of the conditions in the client cache. The cached status may be shared with the
+// caller and the delegate, e.g. an informer store updated in place, which would make the old conditions equal the new
+// ones by the time they are compared.
+func currentConditions(client OperatorClient) []operatorv1.OperatorCondition {
+	_, status, _, err := client.GetOperatorState()
+	if err != nil || status == nil {
+		return nil
+	}
+	conditions := make([]operatorv1.OperatorCondition, len(status.Conditions))
+	for i := range status.Conditions {
+		status.Conditions[i].DeepCopyInto(&conditions[i])
+	}
+	return conditions
+}
@@ -0,0 +1,99 @@
+package v1helpers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRecordConditionChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      []operatorsv1.OperatorCondition
+		new      []operatorsv1.OperatorCondition
+		expected []string
+	}{
+		{
+			name: "no change",
+			old:  []operatorsv1.OperatorCondition{newOperatorCondition("Degraded", "False", "AsExpected", "", nil)},
+			new:  []operatorsv1.OperatorCondition{newOperatorCondition("Degraded", "False", "AsExpected", "", nil)},
+		},
+		{
+			name: "message only",
+			old:  []operatorsv1.OperatorCondition{newOperatorCondition("Degraded", "True", "Error", "a", nil)},
+			new:  []operatorsv1.OperatorCondition{newOperatorCondition("Degraded", "True", "Error", "b", nil)},
+		},
+		{
+			name: "added, changed and removed",
+			old: []operatorsv1.OperatorCondition{
+				newOperatorCondition("Degraded", "False", "AsExpected", "", nil),
+				newOperatorCondition("Stale", "True", "Old", "", nil),
+			},
+			new: []operatorsv1.OperatorCondition{
+				newOperatorCondition("Degraded", "True", "Error", "broken", nil),
+				newOperatorCondition("Available", "True", "AsExpected", "fine", nil),
+			},
+			expected: []string{
+				"Condition Available set to True (AsExpected): fine",
+				"Condition Degraded changed from False (AsExpected) to True (Error): broken",
+				"Condition Stale removed, was True (Old)",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := events.NewInMemoryRecorder("test")
+			RecordConditionChanges(recorder, test.old, test.new)
+			var messages []string
+			for _, e := range recorder.Events() {
+				messages = append(messages, e.Message)
+			}
+			if !reflect.DeepEqual(test.expected, messages) {
+				t.Errorf("expected %q, got %q", test.expected, messages)
+			}
+		})
+	}
+}
+
+func TestConditionEventingOperatorClient(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test")
+	client := NewConditionEventingOperatorClient(NewFakeOperatorClient(&operatorsv1.OperatorSpec{}, &operatorsv1.OperatorStatus{}, nil), recorder)
+
+	if _, _, err := UpdateStatus(context.TODO(), client, UpdateConditionFn(newOperatorCondition("Degraded", "True", "Error", "broken", nil))); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := UpdateStatus(context.TODO(), client, UpdateConditionFn(newOperatorCondition("Degraded", "True", "Error", "broken", nil))); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 1 || recorder.Events()[0].Reason != "OperatorConditionChanged" {
+		t.Errorf("expected one OperatorConditionChanged event, got %v", recorder.Events())
+	}
+}
+
+// inPlaceOperatorClient updates the cached status in place, like a shared informer store handing out its objects.
+type inPlaceOperatorClient struct {
+	OperatorClient
+}
+
+func (c *inPlaceOperatorClient) UpdateOperatorStatus(ctx context.Context, oldResourceVersion string, in *operatorsv1.OperatorStatus) (*operatorsv1.OperatorStatus, error) {
+	_, cached, _, _ := c.OperatorClient.GetOperatorState()
+	cached.Conditions = append(cached.Conditions[:0], in.Conditions...)
+	return c.OperatorClient.UpdateOperatorStatus(ctx, oldResourceVersion, cached)
+}
+
+func TestConditionEventingOperatorClientCopiesCachedConditions(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test")
+	status := &operatorsv1.OperatorStatus{Conditions: []operatorsv1.OperatorCondition{newOperatorCondition("Degraded", "False", "AsExpected", "", nil)}}
+	client := NewConditionEventingOperatorClient(&inPlaceOperatorClient{NewFakeOperatorClient(&operatorsv1.OperatorSpec{}, status, nil)}, recorder)
+
+	if _, _, err := UpdateStatus(context.TODO(), client, UpdateConditionFn(newOperatorCondition("Degraded", "True", "Error", "broken", nil))); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 1 || recorder.Events()[0].Message != "Condition Degraded changed from False (AsExpected) to True (Error): broken" {
+		t.Errorf("expected the Degraded change to be reported, got %v", recorder.Events())
+	}
+}
@@ -38,7 +38,7 @@ type combinedConfigMapInterface struct {
 func (g combinedConfigMapGetter) ConfigMaps(namespace string) corev1client.ConfigMapInterface {
 	return combinedConfigMapInterface{
 		ConfigMapInterface: g.client.ConfigMaps(namespace),
-		lister:             g.listers.InformersFor(namespace).Core().V1().ConfigMaps().Lister().ConfigMaps(namespace),
+		lister:             g.listers.ConfigMapLister().ConfigMaps(namespace),
 		namespace:          namespace,
 	}
 }
@@ -92,7 +92,7 @@ type combinedSecretInterface struct {
 func (g combinedSecretGetter) Secrets(namespace string) corev1client.SecretInterface {
 	return combinedSecretInterface{
 		SecretInterface: g.client.Secrets(namespace),
-		lister:          g.listers.InformersFor(namespace).Core().V1().Secrets().Lister().Secrets(namespace),
+		lister:          g.listers.SecretLister().Secrets(namespace),
 		namespace:       namespace,
 	}
 }
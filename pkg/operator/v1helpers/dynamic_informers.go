@@ -0,0 +1,425 @@
+package v1helpers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NamespaceChangeHandler is called when a namespace is added to or removed from DynamicKubeInformersForNamespaces,
+// e.g. to queue a sync of controllers listing in the namespace.
+type NamespaceChangeHandler func(namespace string, added bool)
+
+// DynamicKubeInformersForNamespaces is KubeInformersForNamespaces for a set of namespaces changing at runtime, e.g.
+// from the operator config. Informers of added namespaces start once Start was called, informers of removed
+// namespaces stop.
+//
+// Unlike for KubeInformersForNamespaces, the listers do not panic for unknown namespaces, because controllers can
+// race with the removal of a namespace. They find nothing in them instead. Listers of namespaces whose informers have
+// not synced yet, e.g. right after the namespace was added, return a NamespaceNotSyncedError rather than an
+// incomplete cache. Cross namespace lists aggregate all namespaces and fail until all of them synced.
+type DynamicKubeInformersForNamespaces interface {
+	KubeInformersForNamespaces
+
+	// HasSynced returns true once the informers of the namespace requested through the listers have synced, false
+	// for unknown namespaces.
+	HasSynced(namespace string) bool
+
+	// SetNamespaces adds and removes namespaces to match the given ones.
+	SetNamespaces(namespaces ...string)
+	AddNamespace(namespace string)
+	RemoveNamespace(namespace string)
+	AddNamespaceChangeHandler(handler NamespaceChangeHandler)
+}
+
+// NamespaceNotSyncedError is returned by the listers of DynamicKubeInformersForNamespaces for namespaces whose
+// informers have not synced yet.
+type NamespaceNotSyncedError struct {
+	Namespace string
+}
+
+func (e *NamespaceNotSyncedError) Error() string {
+	return fmt.Sprintf("informers of namespace %q have not synced yet", e.Namespace)
+}
+
+// informerGetter returns the informer of a resource from the factory of a namespace.
+type informerGetter func(factory informers.SharedInformerFactory) cache.SharedIndexInformer
+
+type namespacedInformerFactory struct {
+	informers.SharedInformerFactory
+	// removedCh is closed when the namespace is removed.
+	removedCh chan struct{}
+	// stopCh is closed when the namespace is removed or the informers stop, it is set on start.
+	stopCh chan struct{}
+}
+
+type dynamicKubeInformersForNamespaces struct {
+	kubeClient kubernetes.Interface
+
+	lock      sync.RWMutex
+	factories map[string]*namespacedInformerFactory
+	handlers  []NamespaceChangeHandler
+	// listed are the informers of the resources listers were requested for, HasSynced waits for them.
+	listed map[string]informerGetter
+	// stopCh is set by Start. Informers of all namespaces stop with it.
+	stopCh <-chan struct{}
+}
+
+var _ DynamicKubeInformersForNamespaces = &dynamicKubeInformersForNamespaces{}
+
+func NewDynamicKubeInformersForNamespaces(kubeClient kubernetes.Interface, namespaces ...string) DynamicKubeInformersForNamespaces {
+	ret := &dynamicKubeInformersForNamespaces{
+		kubeClient: kubeClient,
+		factories:  map[string]*namespacedInformerFactory{},
+		listed:     map[string]informerGetter{},
+	}
+	ret.SetNamespaces(namespaces...)
+	return ret
+}
+
+func (i *dynamicKubeInformersForNamespaces) Start(stopCh <-chan struct{}) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.stopCh = stopCh
+	for _, factory := range i.factories {
+		i.startLocked(factory)
+	}
+}
+
+// startLocked starts the informers requested from the factory so far. Informers requested later are started by the
+// listers, or by the next Start.
+func (i *dynamicKubeInformersForNamespaces) startLocked(factory *namespacedInformerFactory) {
+	if i.stopCh == nil {
+		return
+	}
+	if factory.stopCh == nil {
+		parentStopCh, stopCh := i.stopCh, make(chan struct{})
+		go func() {
+			select {
+			case <-parentStopCh:
+			case <-factory.removedCh:
+			}
+			close(stopCh)
+		}()
+		factory.stopCh = stopCh
+	}
+	factory.Start(factory.stopCh)
+}
+
+func (i *dynamicKubeInformersForNamespaces) Namespaces() sets.String {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return sets.StringKeySet(i.factories)
+}
+
+// InformersFor returns the informer factory of the namespace, or nil for unknown namespaces.
+func (i *dynamicKubeInformersForNamespaces) InformersFor(namespace string) informers.SharedInformerFactory {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	factory, ok := i.factories[namespace]
+	if !ok {
+		return nil
+	}
+	return factory
+}
+
+func (i *dynamicKubeInformersForNamespaces) SetNamespaces(namespaces ...string) {
+	desired := sets.NewString(namespaces...)
+	for _, namespace := range i.Namespaces().Difference(desired).List() {
+		i.RemoveNamespace(namespace)
+	}
+	for _, namespace := range desired.List() {
+		i.AddNamespace(namespace)
+	}
+}
+
+func (i *dynamicKubeInformersForNamespaces) AddNamespace(namespace string) {
+	i.lock.Lock()
+	if _, ok := i.factories[namespace]; ok {
+		i.lock.Unlock()
+		return
+	}
+	factory := &namespacedInformerFactory{removedCh: make(chan struct{})}
+	if len(namespace) == 0 {
+		factory.SharedInformerFactory = informers.NewSharedInformerFactory(i.kubeClient, 10*time.Minute)
+	} else {
+		factory.SharedInformerFactory = informers.NewSharedInformerFactoryWithOptions(i.kubeClient, 10*time.Minute, informers.WithNamespace(namespace))
+	}
+	i.factories[namespace] = factory
+	i.startLocked(factory)
+	handlers := i.handlers
+	i.lock.Unlock()
+
+	for _, handler := range handlers {
+		handler(namespace, true)
+	}
+}
+
+func (i *dynamicKubeInformersForNamespaces) RemoveNamespace(namespace string) {
+	i.lock.Lock()
+	factory, ok := i.factories[namespace]
+	if !ok {
+		i.lock.Unlock()
+		return
+	}
+	delete(i.factories, namespace)
+	close(factory.removedCh)
+	handlers := i.handlers
+	i.lock.Unlock()
+
+	for _, handler := range handlers {
+		handler(namespace, false)
+	}
+}
+
+func (i *dynamicKubeInformersForNamespaces) AddNamespaceChangeHandler(handler NamespaceChangeHandler) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.handlers = append(i.handlers, handler)
+}
+
+// factoriesFor returns the factories to list from: the one of the namespace, falling back to the cluster wide one.
+// For the empty namespace without cluster wide factory, these are all factories.
+func (i *dynamicKubeInformersForNamespaces) factoriesFor(namespace string) []informers.SharedInformerFactory {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if factory, ok := i.factories[namespace]; ok {
+		return []informers.SharedInformerFactory{factory}
+	}
+	if factory, ok := i.factories[""]; ok {
+		return []informers.SharedInformerFactory{factory}
+	}
+	if len(namespace) > 0 {
+		return nil
+	}
+	var ret []informers.SharedInformerFactory
+	for _, factory := range i.factories {
+		ret = append(ret, factory)
+	}
+	return ret
+}
+
+// ensureStarted starts informers requested from the factories after Start.
+func (i *dynamicKubeInformersForNamespaces) ensureStarted() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, factory := range i.factories {
+		i.startLocked(factory)
+	}
+}
+
+func (i *dynamicKubeInformersForNamespaces) HasSynced(namespace string) bool {
+	i.lock.RLock()
+	getters := make([]informerGetter, 0, len(i.listed))
+	for _, getter := range i.listed {
+		getters = append(getters, getter)
+	}
+	started := i.stopCh != nil
+	i.lock.RUnlock()
+	if !started || len(i.factoriesFor(namespace)) == 0 {
+		return false
+	}
+	for _, getter := range getters {
+		if _, err := i.syncedFactories(namespace, getter); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// syncedFactories returns the factories to list from, see factoriesFor, after the informer of the resource started
+// and synced in all of them, and a NamespaceNotSyncedError otherwise.
+func (i *dynamicKubeInformersForNamespaces) syncedFactories(namespace string, getter informerGetter) ([]informers.SharedInformerFactory, error) {
+	factories := i.factoriesFor(namespace)
+	for _, factory := range factories {
+		// requesting the informer registers it with the factory, it is started below
+		getter(factory)
+	}
+	i.ensureStarted()
+
+	i.lock.RLock()
+	started := i.stopCh != nil
+	i.lock.RUnlock()
+	for _, factory := range factories {
+		if !started || !getter(factory).HasSynced() {
+			return nil, &NamespaceNotSyncedError{Namespace: namespace}
+		}
+	}
+	return factories, nil
+}
+
+// listerFor records that a lister of the resource was requested, so that HasSynced waits for its informers.
+func (i *dynamicKubeInformersForNamespaces) listerFor(resource string, getter informerGetter) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.listed[resource] = getter
+}
+
+// emptyIndexer backs the listers of unknown namespaces.
+func emptyIndexer() cache.Indexer {
+	return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+func configMapsInformer(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	return factory.Core().V1().ConfigMaps().Informer()
+}
+
+type dynamicConfigMapLister struct {
+	informers *dynamicKubeInformersForNamespaces
+}
+
+func (i *dynamicKubeInformersForNamespaces) ConfigMapLister() corev1listers.ConfigMapLister {
+	i.listerFor("configmaps", configMapsInformer)
+	return dynamicConfigMapLister{informers: i}
+}
+
+func (l dynamicConfigMapLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	factories, err := l.informers.syncedFactories("", configMapsInformer)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*corev1.ConfigMap
+	for _, factory := range factories {
+		objs, err := factory.Core().V1().ConfigMaps().Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+func (l dynamicConfigMapLister) ConfigMaps(namespace string) corev1listers.ConfigMapNamespaceLister {
+	factories, err := l.informers.syncedFactories(namespace, configMapsInformer)
+	switch {
+	case err != nil:
+		return notSyncedConfigMapNamespaceLister{err: err}
+	case len(factories) != 1:
+		return corev1listers.NewConfigMapLister(emptyIndexer()).ConfigMaps(namespace)
+	}
+	return factories[0].Core().V1().ConfigMaps().Lister().ConfigMaps(namespace)
+}
+
+type notSyncedConfigMapNamespaceLister struct {
+	err error
+}
+
+func (l notSyncedConfigMapNamespaceLister) List(labels.Selector) ([]*corev1.ConfigMap, error) {
+	return nil, l.err
+}
+
+func (l notSyncedConfigMapNamespaceLister) Get(string) (*corev1.ConfigMap, error) {
+	return nil, l.err
+}
+
+func secretsInformer(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	return factory.Core().V1().Secrets().Informer()
+}
+
+type dynamicSecretLister struct {
+	informers *dynamicKubeInformersForNamespaces
+}
+
+func (i *dynamicKubeInformersForNamespaces) SecretLister() corev1listers.SecretLister {
+	i.listerFor("secrets", secretsInformer)
+	return dynamicSecretLister{informers: i}
+}
+
+func (l dynamicSecretLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	factories, err := l.informers.syncedFactories("", secretsInformer)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*corev1.Secret
+	for _, factory := range factories {
+		objs, err := factory.Core().V1().Secrets().Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+func (l dynamicSecretLister) Secrets(namespace string) corev1listers.SecretNamespaceLister {
+	factories, err := l.informers.syncedFactories(namespace, secretsInformer)
+	switch {
+	case err != nil:
+		return notSyncedSecretNamespaceLister{err: err}
+	case len(factories) != 1:
+		return corev1listers.NewSecretLister(emptyIndexer()).Secrets(namespace)
+	}
+	return factories[0].Core().V1().Secrets().Lister().Secrets(namespace)
+}
+
+type notSyncedSecretNamespaceLister struct {
+	err error
+}
+
+func (l notSyncedSecretNamespaceLister) List(labels.Selector) ([]*corev1.Secret, error) {
+	return nil, l.err
+}
+
+func (l notSyncedSecretNamespaceLister) Get(string) (*corev1.Secret, error) {
+	return nil, l.err
+}
+
+func podsInformer(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	return factory.Core().V1().Pods().Informer()
+}
+
+type dynamicPodLister struct {
+	informers *dynamicKubeInformersForNamespaces
+}
+
+func (i *dynamicKubeInformersForNamespaces) PodLister() corev1listers.PodLister {
+	i.listerFor("pods", podsInformer)
+	return dynamicPodLister{informers: i}
+}
+
+func (l dynamicPodLister) List(selector labels.Selector) ([]*corev1.Pod, error) {
+	factories, err := l.informers.syncedFactories("", podsInformer)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*corev1.Pod
+	for _, factory := range factories {
+		objs, err := factory.Core().V1().Pods().Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+func (l dynamicPodLister) Pods(namespace string) corev1listers.PodNamespaceLister {
+	factories, err := l.informers.syncedFactories(namespace, podsInformer)
+	switch {
+	case err != nil:
+		return notSyncedPodNamespaceLister{err: err}
+	case len(factories) != 1:
+		return corev1listers.NewPodLister(emptyIndexer()).Pods(namespace)
+	}
+	return factories[0].Core().V1().Pods().Lister().Pods(namespace)
+}
+
+type notSyncedPodNamespaceLister struct {
+	err error
+}
+
+func (l notSyncedPodNamespaceLister) List(labels.Selector) ([]*corev1.Pod, error) {
+	return nil, l.err
+}
+
+func (l notSyncedPodNamespaceLister) Get(string) (*corev1.Pod, error) {
+	return nil, l.err
+}
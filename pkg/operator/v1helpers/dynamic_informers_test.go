@@ -0,0 +1,94 @@
+package v1helpers
+
+import (
+	"context"
+	goerrors "errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDynamicKubeInformersForNamespaces(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "cm"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "cm"}},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var changes []string
+	informers := NewDynamicKubeInformersForNamespaces(client, "a")
+	informers.AddNamespaceChangeHandler(func(namespace string, added bool) {
+		if added {
+			changes = append(changes, "+"+namespace)
+		} else {
+			changes = append(changes, "-"+namespace)
+		}
+	})
+	lister := informers.ConfigMapLister()
+
+	// nothing is handed out before the informers synced
+	var notSynced *NamespaceNotSyncedError
+	if _, err := lister.ConfigMaps("a").Get("cm"); !goerrors.As(err, &notSynced) || notSynced.Namespace != "a" {
+		t.Fatalf("expected a not synced error before start, got %v", err)
+	}
+	if _, err := lister.List(labels.Everything()); !goerrors.As(err, &notSynced) {
+		t.Fatalf("expected a not synced error before start, got %v", err)
+	}
+	if informers.HasSynced("a") {
+		t.Fatal("expected namespace a not to be synced before start")
+	}
+	informers.Start(ctx.Done())
+
+	waitForConfigMap := func(namespace string) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			_, err := lister.ConfigMaps(namespace).Get("cm")
+			return err == nil, nil
+		}); err != nil {
+			t.Fatalf("config map in %q not found: %v", namespace, err)
+		}
+	}
+
+	waitForConfigMap("a")
+	if !informers.HasSynced("a") || informers.HasSynced("b") {
+		t.Errorf("expected only namespace a to be synced")
+	}
+	if _, err := lister.ConfigMaps("b").Get("cm"); !errors.IsNotFound(err) {
+		t.Fatalf("expected not found for unknown namespace, got %v", err)
+	}
+
+	informers.SetNamespaces("a", "b")
+	waitForConfigMap("b")
+	if !informers.HasSynced("b") {
+		t.Errorf("expected namespace b to be synced")
+	}
+	all, err := lister.List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected 2 config maps in all namespaces, got %d", len(all))
+	}
+
+	informers.SetNamespaces("b")
+	if _, err := lister.ConfigMaps("a").Get("cm"); !errors.IsNotFound(err) {
+		t.Errorf("expected not found for removed namespace, got %v", err)
+	}
+	if informers.InformersFor("a") != nil {
+		t.Errorf("expected no informers for removed namespace")
+	}
+	if got, expected := informers.Namespaces().List(), []string{"b"}; len(got) != 1 || got[0] != expected[0] {
+		t.Errorf("expected namespaces %v, got %v", expected, got)
+	}
+
+	if expected := []string{"+b", "-a"}; len(changes) != 2 || changes[0] != expected[0] || changes[1] != expected[1] {
+		t.Errorf("expected changes %v, got %v", expected, changes)
+	}
+}
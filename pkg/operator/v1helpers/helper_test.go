@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/library-go/pkg/operator/reasons"
 )
 
 func newOperatorCondition(name, status, reason, message string, lastTransition *metav1.Time) operatorsv1.OperatorCondition {
@@ -103,6 +105,24 @@ func TestSetOperatorCondition(t *testing.T) {
 	}
 }
 
+func TestSetOperatorConditionRunbook(t *testing.T) {
+	reasons.DefaultCatalog.MustRegister("RunbookTestFailure", "Test reason with a runbook.")
+	reasons.DefaultCatalog.MustRegisterRunbook("RunbookTestFailure", "https://docs.example.com/runbook-test-failure")
+
+	conditions := []operatorsv1.OperatorCondition{}
+	for i := 0; i < 2; i++ {
+		SetOperatorCondition(&conditions, newOperatorCondition("FooDegraded", "True", "RunbookTestFailure", "Foo failed.", nil))
+	}
+	if expected := "Foo failed. Runbook: https://docs.example.com/runbook-test-failure"; conditions[0].Message != expected {
+		t.Errorf("expected message %q, got %q", expected, conditions[0].Message)
+	}
+
+	SetOperatorCondition(&conditions, newOperatorCondition("FooDegraded", "False", "AsExpected", "", nil))
+	if len(conditions[0].Message) != 0 {
+		t.Errorf("expected no runbook for reason AsExpected, got %q", conditions[0].Message)
+	}
+}
+
 func TestRemoveOperatorCondition(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -21,6 +21,8 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/reasons"
 )
 
 // SetOperandVersion sets the new version and returns the previous value.
@@ -51,10 +53,13 @@ func FindOperandVersion(versions []configv1.OperandVersion, name string) *config
 	return nil
 }
 
+// SetOperatorCondition sets the condition in the conditions, the last transition time changes with the status. The
+// runbook registered for the reason in reasons.DefaultCatalog is appended to the message.
 func SetOperatorCondition(conditions *[]operatorv1.OperatorCondition, newCondition operatorv1.OperatorCondition) {
 	if conditions == nil {
 		conditions = &[]operatorv1.OperatorCondition{}
 	}
+	newCondition.Message = reasons.DefaultCatalog.AppendRunbook(newCondition.Reason, newCondition.Message)
 	existingCondition := FindOperatorCondition(*conditions, newCondition.Type)
 	if existingCondition == nil {
 		newCondition.LastTransitionTime = metav1.NewTime(time.Now())
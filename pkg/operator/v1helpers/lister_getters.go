@@ -0,0 +1,62 @@
+package v1helpers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// GetSecret returns the secret from the lister. If the lister does not know the secret, it is read from the server,
+// because the informer might not have observed a secret created just before yet. A not-found error is only returned
+// if the secret does not exist on the server either. The client can be nil to only read from the lister.
+// The returned secret can be shared with the informer cache and must not be mutated.
+func GetSecret(ctx context.Context, lister corev1listers.SecretLister, client corev1client.SecretsGetter, namespace, name string) (*corev1.Secret, error) {
+	var liveClient corev1client.SecretInterface
+	if client != nil {
+		liveClient = client.Secrets(namespace)
+	}
+	return getSecret(ctx, lister.Secrets(namespace), liveClient, namespace, name)
+}
+
+func getSecret(ctx context.Context, lister corev1listers.SecretNamespaceLister, client corev1client.SecretInterface, namespace, name string) (*corev1.Secret, error) {
+	secret, err := lister.Get(name)
+	if !apierrors.IsNotFound(err) || client == nil {
+		return secret, err
+	}
+	klog.V(4).Infof("secret %s/%s not found in the informer cache, reading it from the server", namespace, name)
+	secret, err = client.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		klog.V(2).Infof("Informer cache of secret %s/%s is stale, read resourceVersion %s from the server", namespace, name, secret.ResourceVersion)
+	}
+	return secret, err
+}
+
+// GetConfigMap returns the config map from the lister. If the lister does not know the config map, it is read from
+// the server, because the informer might not have observed a config map created just before yet. A not-found error
+// is only returned if the config map does not exist on the server either. The client can be nil to only read from
+// the lister. The returned config map can be shared with the informer cache and must not be mutated.
+func GetConfigMap(ctx context.Context, lister corev1listers.ConfigMapLister, client corev1client.ConfigMapsGetter, namespace, name string) (*corev1.ConfigMap, error) {
+	var liveClient corev1client.ConfigMapInterface
+	if client != nil {
+		liveClient = client.ConfigMaps(namespace)
+	}
+	return getConfigMap(ctx, lister.ConfigMaps(namespace), liveClient, namespace, name)
+}
+
+func getConfigMap(ctx context.Context, lister corev1listers.ConfigMapNamespaceLister, client corev1client.ConfigMapInterface, namespace, name string) (*corev1.ConfigMap, error) {
+	configMap, err := lister.Get(name)
+	if !apierrors.IsNotFound(err) || client == nil {
+		return configMap, err
+	}
+	klog.V(4).Infof("configmap %s/%s not found in the informer cache, reading it from the server", namespace, name)
+	configMap, err = client.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		klog.V(2).Infof("Informer cache of configmap %s/%s is stale, read resourceVersion %s from the server", namespace, name, configMap.ResourceVersion)
+	}
+	return configMap, err
+}
@@ -0,0 +1,52 @@
+package v1helpers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetSecret(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cached", ResourceVersion: "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	lister := corev1listers.NewSecretLister(indexer)
+	// just created, not observed by the informer yet
+	client := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "created", ResourceVersion: "2"}})
+
+	if secret, err := GetSecret(context.TODO(), lister, client.CoreV1(), "ns", "cached"); err != nil || secret.ResourceVersion != "1" {
+		t.Errorf("expected the cached secret, got %v, %v", secret, err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("expected no server reads for cached secrets, got %v", client.Actions())
+	}
+	if secret, err := GetSecret(context.TODO(), lister, client.CoreV1(), "ns", "created"); err != nil || secret.ResourceVersion != "2" {
+		t.Errorf("expected the secret from the server, got %v, %v", secret, err)
+	}
+	if _, err := GetSecret(context.TODO(), lister, client.CoreV1(), "ns", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := GetSecret(context.TODO(), lister, nil, "ns", "created"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found without client, got %v", err)
+	}
+}
+
+func TestGetConfigMap(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	lister := corev1listers.NewConfigMapLister(indexer)
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "created"}})
+
+	if _, err := GetConfigMap(context.TODO(), lister, client.CoreV1(), "ns", "created"); err != nil {
+		t.Errorf("expected the config map from the server, got %v", err)
+	}
+	if _, err := GetConfigMap(context.TODO(), lister, client.CoreV1(), "ns", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package workloadtier
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ManagementWorkloadAnnotation pins the pods to the management CPUs when workload partitioning is enabled, e.g. on
+	// single node clusters. It only takes effect in namespaces annotated with workload.openshift.io/allowed=management,
+	// see operandnamespace.WithWorkloadPinning.
+	ManagementWorkloadAnnotation = "target.workload.openshift.io/management"
+	// managementWorkloadAnnotationValue is the only value the workload partitioning admission accepts.
+	managementWorkloadAnnotationValue = `{"effect": "PreferredDuringScheduling"}`
+)
+
+// Tier declares how important the pods of an operand are.
+type Tier struct {
+	// PriorityClassName is the priority class of the pods.
+	PriorityClassName string
+	// PriorityClass is the priority class the operator manages itself, nil for built-in priority classes.
+	PriorityClass *schedulingv1.PriorityClass
+	// Management pods are platform pods that run on the management CPUs when workload partitioning is enabled.
+	Management bool
+}
+
+var (
+	// NodeCritical operands must run on every node for the node to work, e.g. networking.
+	NodeCritical = Tier{PriorityClassName: "system-node-critical", Management: true}
+	// ClusterCritical operands must run for the cluster to work, e.g. the control plane.
+	ClusterCritical = Tier{PriorityClassName: "system-cluster-critical", Management: true}
+	// UserCritical operands serve user workloads, e.g. monitoring of user workloads.
+	UserCritical = Tier{PriorityClassName: "openshift-user-critical", Management: true}
+	// Default operands do not need a priority, but still belong to the platform.
+	Default = Tier{Management: true}
+)
+
+// NewManagedTier returns a tier with a priority class managed by the operator. Apply the priority class with
+// resourceapply.ApplyPriorityClass before the operands that use it.
+func NewManagedTier(name string, value int32, description string) Tier {
+	return Tier{
+		PriorityClassName: name,
+		PriorityClass: &schedulingv1.PriorityClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Value:       value,
+			Description: description,
+		},
+		Management: true,
+	}
+}
+
+// ApplyTo sets the priority class of the pod template and adds the management workload annotation, or removes it
+// for non-management tiers.
+func (t Tier) ApplyTo(template *corev1.PodTemplateSpec) {
+	template.Spec.PriorityClassName = t.PriorityClassName
+	if !t.Management {
+		delete(template.Annotations, ManagementWorkloadAnnotation)
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[ManagementWorkloadAnnotation] = managementWorkloadAnnotationValue
+}
@@ -0,0 +1,56 @@
+package workloadtier
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyTo(t *testing.T) {
+	tests := []struct {
+		name                string
+		tier                Tier
+		annotations         map[string]string
+		expectedPriority    string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "cluster critical",
+			tier:                ClusterCritical,
+			expectedPriority:    "system-cluster-critical",
+			expectedAnnotations: map[string]string{ManagementWorkloadAnnotation: `{"effect": "PreferredDuringScheduling"}`},
+		},
+		{
+			name:                "managed priority class keeps other annotations",
+			tier:                NewManagedTier("openshift-foo-critical", 1000, "foo"),
+			annotations:         map[string]string{"other": "value"},
+			expectedPriority:    "openshift-foo-critical",
+			expectedAnnotations: map[string]string{"other": "value", ManagementWorkloadAnnotation: `{"effect": "PreferredDuringScheduling"}`},
+		},
+		{
+			name:                "non-management tier removes the annotation",
+			tier:                Tier{PriorityClassName: "openshift-user-critical"},
+			annotations:         map[string]string{"other": "value", ManagementWorkloadAnnotation: `{"effect": "PreferredDuringScheduling"}`},
+			expectedPriority:    "openshift-user-critical",
+			expectedAnnotations: map[string]string{"other": "value"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			test.tier.ApplyTo(template)
+			if template.Spec.PriorityClassName != test.expectedPriority {
+				t.Errorf("expected priority class %q, got %q", test.expectedPriority, template.Spec.PriorityClassName)
+			}
+			if len(template.Annotations) != len(test.expectedAnnotations) {
+				t.Fatalf("expected annotations %v, got %v", test.expectedAnnotations, template.Annotations)
+			}
+			for k, v := range test.expectedAnnotations {
+				if template.Annotations[k] != v {
+					t.Errorf("expected annotations %v, got %v", test.expectedAnnotations, template.Annotations)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package writebudget
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/librarymetrics"
+)
+
+// unknownController is the controller label of writes outside of controller syncs.
+const unknownController = "unknown"
+
+var (
+	writesMetric = librarymetrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "controller",
+		Name:           "writes_total",
+		Help:           "Number of API writes, by controller and verb",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller", "verb"})
+
+	budgetExceededMetric = librarymetrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "controller",
+		Name:           "write_budget_exceeded_total",
+		Help:           "Number of API writes over the write budget of the controller, by controller",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+)
+
+// Budget is the number of writes a controller may do within a period. A hotlooping controller, e.g. two controllers
+// fighting about a CA bundle, exceeds any sane budget within a few minutes.
+type Budget struct {
+	Writes int
+	Period time.Duration
+}
+
+func (b Budget) String() string {
+	return fmt.Sprintf("%d writes per %s", b.Writes, b.Period)
+}
+
+type window struct {
+	start    time.Time
+	writes   int
+	exceeded bool
+}
+
+// Tracker accounts the writes of controllers against their budgets. Exceeding a budget increments the
+// controller_write_budget_exceeded_total metric, which alerts can watch, and records a warning event once per period.
+// Only enforcing trackers fail the writes over budget.
+type Tracker struct {
+	defaultBudget Budget
+	budgets       map[string]Budget
+	enforce       bool
+	recorder      events.Recorder
+	clock         clock.PassiveClock
+
+	lock    sync.Mutex
+	windows map[string]*window
+}
+
+// NewTracker returns a tracker applying the default budget to all controllers. The recorder is optional.
+func NewTracker(defaultBudget Budget, recorder events.Recorder) *Tracker {
+	return &Tracker{
+		defaultBudget: defaultBudget,
+		budgets:       map[string]Budget{},
+		recorder:      recorder,
+		clock:         clock.RealClock{},
+		windows:       map[string]*window{},
+	}
+}
+
+// WithBudget sets the budget of the named controller, e.g. for controllers which legitimately write a lot.
+func (t *Tracker) WithBudget(controller string, budget Budget) *Tracker {
+	t.budgets[controller] = budget
+	return t
+}
+
+// Enforcing makes the tracker reject the writes over budget with a TooManyRequests error, instead of only reporting
+// them. Writes outside of controller syncs are never rejected.
+func (t *Tracker) Enforcing() *Tracker {
+	t.enforce = true
+	return t
+}
+
+// Record accounts a write of the controller and returns false if it must be rejected. An empty controller name
+// stands for writes outside of controller syncs, which are counted, but have no budget.
+func (t *Tracker) Record(controller, verb string) bool {
+	if len(controller) == 0 {
+		writesMetric.WithLabelValues(unknownController, verb).Inc()
+		return true
+	}
+	writesMetric.WithLabelValues(controller, verb).Inc()
+
+	budget, ok := t.budgets[controller]
+	if !ok {
+		budget = t.defaultBudget
+	}
+
+	t.lock.Lock()
+	now := t.clock.Now()
+	w, ok := t.windows[controller]
+	if !ok || now.Sub(w.start) >= budget.Period {
+		w = &window{start: now}
+		t.windows[controller] = w
+	}
+	w.writes++
+	if w.writes <= budget.Writes {
+		t.lock.Unlock()
+		return true
+	}
+	firstExceeded := !w.exceeded
+	w.exceeded = true
+	t.lock.Unlock()
+
+	budgetExceededMetric.WithLabelValues(controller).Inc()
+	if firstExceeded {
+		klog.Warningf("Controller %q exceeded its write budget of %s", controller, budget)
+		if t.recorder != nil {
+			t.recorder.Warningf("WriteBudgetExceeded", "Controller %q exceeded its write budget of %s, it might be hotlooping", controller, budget)
+		}
+	}
+	return !t.enforce
+}
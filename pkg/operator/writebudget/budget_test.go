@@ -0,0 +1,77 @@
+package writebudget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestTracker(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test")
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	tracker := NewTracker(Budget{Writes: 2, Period: time.Minute}, recorder).WithBudget("busy", Budget{Writes: 5, Period: time.Minute})
+	tracker.clock = clock
+
+	for i := 0; i < 5; i++ {
+		if !tracker.Record("busy", "put") {
+			t.Fatalf("expected write %d of busy to be allowed", i)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		tracker.Record("hotloop", "put")
+	}
+	if len(recorder.Events()) != 1 || recorder.Events()[0].Reason != "WriteBudgetExceeded" {
+		t.Errorf("expected one WriteBudgetExceeded event, got %v", recorder.Events())
+	}
+
+	// the next period has a new budget
+	clock.SetTime(clock.Now().Add(time.Minute))
+	tracker.Enforcing()
+	if !tracker.Record("hotloop", "put") || !tracker.Record("hotloop", "put") {
+		t.Errorf("expected the writes of the next period to be allowed")
+	}
+	if tracker.Record("hotloop", "put") {
+		t.Errorf("expected the write over budget to be rejected")
+	}
+	if !tracker.Record("", "put") {
+		t.Errorf("expected writes outside of controllers to be allowed")
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"ns"}}`))
+	}))
+	defer server.Close()
+
+	tracker := NewTracker(Budget{Writes: 1, Period: time.Hour}, nil).Enforcing()
+	client := kubernetes.NewForConfigOrDie(Wrap(&rest.Config{Host: server.URL}, tracker))
+	ctx := factory.WithControllerName(context.TODO(), "hotloop")
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+
+	if _, err := client.CoreV1().ConfigMaps("ns").Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "foo", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected reads not to be accounted, got %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Update(ctx, configMap, metav1.UpdateOptions{}); !apierrors.IsTooManyRequests(err) {
+		t.Errorf("expected TooManyRequests, got %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		t.Errorf("expected writes outside of controllers to be allowed, got %v", err)
+	}
+}
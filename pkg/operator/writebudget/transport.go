@@ -0,0 +1,73 @@
+package writebudget
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// Wrap returns a copy of the config whose clients account their writes in the tracker, attributed to the controller
+// whose sync issued them, e.g.
+//
+//	kubeClient := kubernetes.NewForConfigOrDie(writebudget.Wrap(config, tracker))
+func Wrap(config *rest.Config, tracker *Tracker) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(NewRoundTripper(tracker))
+	return config
+}
+
+// NewRoundTripper returns a middleware accounting the writes in the tracker.
+func NewRoundTripper(tracker *Tracker) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &budgetRT{baseRT: rt, tracker: tracker}
+	}
+}
+
+type budgetRT struct {
+	baseRT  http.RoundTripper
+	tracker *Tracker
+}
+
+func (rt *budgetRT) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return rt.baseRT.RoundTrip(req)
+	}
+
+	controller := factory.ControllerNameFrom(req.Context())
+	if rt.tracker.Record(controller, strings.ToLower(req.Method)) {
+		return rt.baseRT.RoundTrip(req)
+	}
+	// no Retry-After, the client must not retry, but fail the sync
+	return newStatusResponse(req, apierrors.NewTooManyRequestsError(fmt.Sprintf("controller %q exceeded its write budget", controller)))
+}
+
+func newStatusResponse(req *http.Request, err *apierrors.StatusError) (*http.Response, error) {
+	status := err.Status()
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	body, marshalErr := json.Marshal(status)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status.Code, http.StatusText(int(status.Code))),
+		StatusCode:    int(status.Code),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}